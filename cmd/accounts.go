@@ -0,0 +1,70 @@
+/*
+Copyright © 2025 Adharsh Manikandan <debugslayer@gmail.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"go_starter_api/infra"
+	"go_starter_api/internal/account"
+	"go_starter_api/pkg/domain"
+	"log"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// accountsCmd represents the accounts command
+var accountsCmd = &cobra.Command{
+	Use:   "accounts",
+	Short: "administer accounts",
+}
+
+var accountsUnlockCmd = &cobra.Command{
+	Use:   "unlock <email>",
+	Short: "lift the lockout of an account and forget its failed logins",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		repository := account.NewAccountRepository(infra.InitGormDB())
+
+		acc, err := repository.GetAccountByEmail(ctx, args[0])
+		if err != nil {
+			log.Fatalf("error getting account: %v", err)
+		}
+
+		if err := repository.UnlockAccount(ctx, acc.ID); err != nil {
+			log.Fatalf("error unlocking account: %v", err)
+		}
+
+		if err := repository.LogAccountActivity(ctx, acc.ID, domain.ActivityAccountUnlocked); err != nil {
+			log.Printf("error logging activity: %v", err)
+		}
+
+		fmt.Printf("unlocked %s\n", acc.Email)
+	},
+}
+
+var accountsPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "purge the deleted accounts whose grace period is over",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		purger := account.NewPurger(logrus.New(), account.NewAccountRepository(infra.InitGormDB()), infra.InitStorage())
+
+		purged, err := purger.PurgeDue(context.Background(), time.Now())
+		if err != nil {
+			log.Fatalf("error purging accounts: %v", err)
+		}
+
+		fmt.Printf("purged %d accounts\n", purged)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(accountsCmd)
+	accountsCmd.AddCommand(accountsUnlockCmd)
+	accountsCmd.AddCommand(accountsPurgeCmd)
+}
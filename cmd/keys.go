@@ -0,0 +1,151 @@
+/*
+Copyright © 2025 Adharsh Manikandan <debugslayer@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+	"go_starter_api/pkg/keyring"
+	"log"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// keysCmd represents the keys command
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "manage the jwt signing keys",
+	Long: `manage the jwt signing keys stored in JWT_KEYS_DIR.
+
+To rotate without invalidating tokens in flight, generate a key, wait until
+verifiers have refreshed the jwks, activate it, and remove the previous key
+once the tokens signed with it have expired. "keys rotate" does the first two
+steps at once for deployments where every verifier shares the key directory.`,
+}
+
+var keysGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "generate a new signing key, it is published but not used for signing until activated",
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := keysDir(cmd)
+
+		key, err := generateKey(cmd, dir)
+		if err != nil {
+			log.Fatalf("error generating key: %v", err)
+		}
+
+		// the first key is activated right away, there is nothing to rotate from
+		activeID, err := keyring.ActiveKeyID(dir)
+		if err != nil {
+			log.Fatalf("error reading active key: %v", err)
+		}
+		if activeID == "" {
+			if err := keyring.SetActiveKey(dir, key.ID); err != nil {
+				log.Fatalf("error activating key: %v", err)
+			}
+		}
+
+		fmt.Println(key.ID)
+	},
+}
+
+var keysRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "generate a new signing key and sign new tokens with it",
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := keysDir(cmd)
+
+		key, err := generateKey(cmd, dir)
+		if err != nil {
+			log.Fatalf("error generating key: %v", err)
+		}
+
+		if err := keyring.SetActiveKey(dir, key.ID); err != nil {
+			log.Fatalf("error activating key: %v", err)
+		}
+
+		fmt.Println(key.ID)
+	},
+}
+
+var keysActivateCmd = &cobra.Command{
+	Use:   "activate <kid>",
+	Short: "sign new tokens with an existing key",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := keyring.SetActiveKey(keysDir(cmd), args[0]); err != nil {
+			log.Fatalf("error activating key: %v", err)
+		}
+	},
+}
+
+var keysRemoveCmd = &cobra.Command{
+	Use:   "remove <kid>",
+	Short: "remove a retired key, tokens signed with it stop being accepted",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := keyring.RemoveKey(keysDir(cmd), args[0]); err != nil {
+			log.Fatalf("error removing key: %v", err)
+		}
+	},
+}
+
+var keysListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list the signing keys",
+	Run: func(cmd *cobra.Command, args []string) {
+		keyRing, err := keyring.LoadDir(keysDir(cmd), "")
+		if err != nil {
+			log.Fatalf("error loading keys: %v", err)
+		}
+
+		for _, key := range keyRing.Keys() {
+			status := ""
+			if key.ID == keyRing.ActiveKeyID() {
+				status = "active"
+			}
+			fmt.Printf("%s\t%s\t%s\n", key.ID, key.Algorithm, status)
+		}
+	},
+}
+
+func keysDir(cmd *cobra.Command) string {
+	dir, err := cmd.Flags().GetString("dir")
+	if err != nil {
+		log.Fatalf("error getting dir: %v", err)
+	}
+	if dir == "" {
+		dir = viper.GetString("JWT_KEYS_DIR")
+	}
+	if dir == "" {
+		log.Fatalf("no key directory, set JWT_KEYS_DIR or pass --dir")
+	}
+	return dir
+}
+
+func generateKey(cmd *cobra.Command, dir string) (*keyring.Key, error) {
+	algorithm, err := cmd.Flags().GetString("alg")
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := keyring.GenerateKey(algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	return key, keyring.SaveKey(dir, key)
+}
+
+func init() {
+	rootCmd.AddCommand(keysCmd)
+	keysCmd.AddCommand(keysGenerateCmd, keysRotateCmd, keysActivateCmd, keysRemoveCmd, keysListCmd)
+
+	// flag to override JWT_KEYS_DIR
+	keysCmd.PersistentFlags().String("dir", "", "directory holding the signing keys (default is JWT_KEYS_DIR)")
+
+	for _, cmd := range []*cobra.Command{keysGenerateCmd, keysRotateCmd} {
+		cmd.Flags().String("alg", keyring.AlgorithmES256, "signing algorithm, one of RS256, ES256, EdDSA")
+	}
+}
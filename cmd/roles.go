@@ -0,0 +1,120 @@
+/*
+Copyright © 2025 Adharsh Manikandan <debugslayer@gmail.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"go_starter_api/infra"
+	"go_starter_api/internal/account"
+	"go_starter_api/internal/role"
+	"go_starter_api/pkg/domain"
+	"log"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// rolesCmd represents the roles command
+var rolesCmd = &cobra.Command{
+	Use:   "roles",
+	Short: "manage the roles of accounts",
+}
+
+var rolesGrantCmd = &cobra.Command{
+	Use:   "grant <email> <role>",
+	Short: "give a role to an account",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		accountRepository, roleRepository, acc, r := loadAccountRole(ctx, args[0], args[1])
+
+		if err := roleRepository.AssignRole(ctx, acc.ID, r.ID); err != nil {
+			log.Fatalf("error granting role: %v", err)
+		}
+
+		if err := accountRepository.LogAccountActivity(ctx, acc.ID, domain.ActivityRoleGranted); err != nil {
+			log.Printf("error logging activity: %v", err)
+		}
+
+		fmt.Printf("granted %s to %s\n", r.Name, acc.Email)
+	},
+}
+
+var rolesRevokeCmd = &cobra.Command{
+	Use:   "revoke <email> <role>",
+	Short: "take a role from an account",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		accountRepository, roleRepository, acc, r := loadAccountRole(ctx, args[0], args[1])
+
+		if err := roleRepository.UnassignRole(ctx, acc.ID, r.ID); err != nil {
+			log.Fatalf("error revoking role: %v", err)
+		}
+
+		if err := accountRepository.LogAccountActivity(ctx, acc.ID, domain.ActivityRoleRevoked); err != nil {
+			log.Printf("error logging activity: %v", err)
+		}
+
+		fmt.Printf("revoked %s from %s\n", r.Name, acc.Email)
+	},
+}
+
+var rolesListCmd = &cobra.Command{
+	Use:   "list [email]",
+	Short: "list the roles, or the roles of an account",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+		db := infra.InitGormDB()
+		roleRepository := role.NewRoleRepository(db)
+
+		var roles []domain.Role
+		var err error
+		if len(args) == 0 {
+			roles, err = roleRepository.ListRoles(ctx)
+		} else {
+			acc, accErr := account.NewAccountRepository(db).GetAccountByEmail(ctx, args[0])
+			if accErr != nil {
+				log.Fatalf("error getting account: %v", accErr)
+			}
+			roles, err = roleRepository.ListAccountRoles(ctx, acc.ID)
+		}
+		if err != nil {
+			log.Fatalf("error listing roles: %v", err)
+		}
+
+		for _, r := range roles {
+			permissions := make([]string, 0, len(r.Permissions))
+			for _, p := range r.Permissions {
+				permissions = append(permissions, p.Permission)
+			}
+			fmt.Printf("%s\t%s\n", r.Name, strings.Join(permissions, ","))
+		}
+	},
+}
+
+func loadAccountRole(ctx context.Context, email string, roleName string) (domain.AccountRepository, domain.RoleRepository, *domain.Account, *domain.Role) {
+	db := infra.InitGormDB()
+	accountRepository := account.NewAccountRepository(db)
+	roleRepository := role.NewRoleRepository(db)
+
+	acc, err := accountRepository.GetAccountByEmail(ctx, email)
+	if err != nil {
+		log.Fatalf("error getting account: %v", err)
+	}
+
+	r, err := roleRepository.GetRoleByName(ctx, roleName)
+	if err != nil {
+		log.Fatalf("error getting role: %v", err)
+	}
+
+	return accountRepository, roleRepository, acc, r
+}
+
+func init() {
+	rootCmd.AddCommand(rolesCmd)
+	rolesCmd.AddCommand(rolesGrantCmd, rolesRevokeCmd, rolesListCmd)
+}
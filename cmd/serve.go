@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/signal"
 	"go_starter_api/infra"
+	"go_starter_api/internal/account"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -41,8 +42,13 @@ var serveCmd = &cobra.Command{
 		}
 
 		db := infra.InitGormDB()
+		keyRing := infra.InitKeyRing()
+		rateLimitStore := infra.InitRateLimitStore()
+		oauthProviders := infra.InitOAuthProviders()
+		relyingParty := infra.InitRelyingParty()
+		store := infra.InitStorage()
 
-		srv := infra.NewServer(db, logger, config)
+		srv := infra.NewServer(db, keyRing, rateLimitStore, oauthProviders, relyingParty, store, logger, config)
 
 		ch := make(chan os.Signal, 1)
 		signal.Notify(ch, os.Interrupt)
@@ -55,6 +61,16 @@ var serveCmd = &cobra.Command{
 
 		log.Println("api running on port", port)
 
+		// purges the accounts whose deletion grace period is over, until the server shuts down
+		purgeCtx, stopPurge := context.WithCancel(context.Background())
+		defer stopPurge()
+		go account.NewPurger(logger, account.NewAccountRepository(db), store).Run(purgeCtx)
+
+		// builds the requested data exports, until the server shuts down
+		exportCtx, stopExports := context.WithCancel(context.Background())
+		defer stopExports()
+		go infra.NewExportWorker(db, keyRing, logger).Run(exportCtx)
+
 		// block until the signal is received
 		<-ch
 		log.Println("shutting down server...")
@@ -1,8 +1,12 @@
 package infra
 
 import (
+	"context"
 	"fmt"
+	"go_starter_api/internal/role"
+	"go_starter_api/pkg/audit"
 	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/tenant"
 
 	"github.com/spf13/viper"
 	"gorm.io/driver/postgres"
@@ -29,14 +33,52 @@ func InitGormDB() *gorm.DB {
 
 	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s timezone=%s", host, port, user, password, dbname, sslmode, timezone)
 
-	db, err = gorm.Open(postgres.Open(connStr), &gorm.Config{})
+	// translates unique violations to gorm.ErrDuplicatedKey so repositories can map them to domain errors
+	db, err = gorm.Open(postgres.Open(connStr), &gorm.Config{TranslateError: true})
 
 	if err != nil {
 		panic("failed to connect database")
 	}
 
+	// scopes every statement on tenant owned records to the organization of its context
+	if err := db.Use(tenant.Plugin{}); err != nil {
+		panic("failed to register tenant plugin")
+	}
+
+	// the audit log is append only, its records are never changed nor deleted
+	if err := db.Use(audit.Plugin{}); err != nil {
+		panic("failed to register audit plugin")
+	}
+
 	db.AutoMigrate(&domain.Account{})
 	db.AutoMigrate(&domain.AccountActivity{})
+	db.AutoMigrate(&domain.RefreshToken{})
+	db.AutoMigrate(&domain.Session{})
+	db.AutoMigrate(&domain.RevokedToken{})
+	db.AutoMigrate(&domain.AccountTokenRevocation{})
+	db.AutoMigrate(&domain.RecoveryCode{})
+	db.AutoMigrate(&domain.OneTimeToken{})
+	db.AutoMigrate(&domain.LoginFailure{})
+	db.AutoMigrate(&domain.APIKey{})
+	db.AutoMigrate(&domain.Identity{})
+	db.AutoMigrate(&domain.OAuthState{})
+	db.AutoMigrate(&domain.OAuthClient{})
+	db.AutoMigrate(&domain.AuthorizationCode{})
+	db.AutoMigrate(&domain.OAuthConsent{})
+	db.AutoMigrate(&domain.WebAuthnCredential{})
+	db.AutoMigrate(&domain.WebAuthnChallenge{})
+	db.AutoMigrate(&domain.Role{})
+	db.AutoMigrate(&domain.RolePermission{})
+	db.AutoMigrate(&domain.AccountRole{})
+	db.AutoMigrate(&domain.Organization{})
+	db.AutoMigrate(&domain.Membership{})
+	db.AutoMigrate(&domain.Invitation{})
+	db.AutoMigrate(&domain.DataExport{})
+
+	_, err = role.NewRoleRepository(db).EnsureRole(context.Background(), domain.RoleAdmin, "every permission", []string{domain.PermissionAll})
+	if err != nil {
+		panic("failed to seed roles")
+	}
 
 	return db
 }
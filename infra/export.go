@@ -0,0 +1,33 @@
+package infra
+
+import (
+	"go_starter_api/internal/account"
+	"go_starter_api/internal/export"
+	"go_starter_api/internal/organization"
+	"go_starter_api/pkg/keyring"
+	"go_starter_api/pkg/mailer"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// InitExportRegistry registers the exporter of every module storing personal data,
+// a new module adds its exporter here to be part of the data exports
+func InitExportRegistry(db *gorm.DB) *export.Registry {
+	registry := export.NewRegistry()
+	registry.Register(account.NewExporter(account.NewAccountRepository(db)))
+	registry.Register(organization.NewExporter(organization.NewOrganizationRepository(db)))
+	return registry
+}
+
+// NewExportWorker returns the worker building the data exports, it emails the download
+// links signed like the other tokens
+func NewExportWorker(db *gorm.DB, keyRing keyring.KeyRing, logger *logrus.Logger) *export.Worker {
+	return export.NewWorker(
+		logger,
+		InitExportRegistry(db),
+		export.NewExportRepository(db),
+		account.NewAccountRepository(db),
+		account.NewAccountService(mailer.NewEmailService(), keyRing),
+	)
+}
@@ -0,0 +1,23 @@
+package infra
+
+import (
+	"go_starter_api/pkg/keyring"
+
+	"github.com/spf13/viper"
+)
+
+// InitKeyRing loads the jwt signing keys from JWT_KEYS_DIR,
+// without it tokens are signed with JWT_SECRET and no keys are published
+func InitKeyRing() keyring.KeyRing {
+	dir := viper.GetString("JWT_KEYS_DIR")
+	if dir == "" {
+		return nil
+	}
+
+	keyRing, err := keyring.LoadDir(dir, viper.GetString("JWT_SIGNING_KEY_ID"))
+	if err != nil {
+		panic("failed to load jwt signing keys: " + err.Error())
+	}
+
+	return keyRing
+}
@@ -0,0 +1,50 @@
+package infra
+
+import (
+	"go_starter_api/pkg/oauth"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// InitOAuthProviders returns the identity providers listed in OAUTH_PROVIDERS, each one is
+// configured with the OAUTH_<NAME>_* settings. Social login is off when none are listed.
+func InitOAuthProviders() []oauth.Provider {
+	var providers []oauth.Provider
+
+	for _, name := range strings.Split(viper.GetString("OAUTH_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OAUTH_" + strings.ToUpper(name) + "_"
+		config := oauth.Config{
+			Name:         name,
+			ClientID:     viper.GetString(prefix + "CLIENT_ID"),
+			ClientSecret: viper.GetString(prefix + "CLIENT_SECRET"),
+			RedirectURL:  viper.GetString(prefix + "REDIRECT_URL"),
+			Issuer:       viper.GetString(prefix + "ISSUER"),
+			Scopes:       strings.Fields(viper.GetString(prefix + "SCOPES")),
+		}
+		if config.ClientID == "" || config.ClientSecret == "" || config.RedirectURL == "" {
+			panic("invalid oauth provider " + name + ": " + prefix + "CLIENT_ID, " + prefix + "CLIENT_SECRET and " + prefix + "REDIRECT_URL are required")
+		}
+
+		providerType := viper.GetString(prefix + "TYPE")
+		if providerType == "" {
+			providerType = oauth.ProviderTypeOIDC
+		}
+		if providerType == oauth.ProviderTypeOIDC && config.Issuer == "" {
+			panic("invalid oauth provider " + name + ": " + prefix + "ISSUER is required")
+		}
+
+		provider, err := oauth.NewProvider(providerType, config)
+		if err != nil {
+			panic("invalid oauth provider " + name + ": " + err.Error())
+		}
+		providers = append(providers, provider)
+	}
+
+	return providers
+}
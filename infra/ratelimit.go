@@ -0,0 +1,48 @@
+package infra
+
+import (
+	"go_starter_api/pkg/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+)
+
+const (
+	defaultRateLimit        = "100/1m"
+	defaultAccountRateLimit = "300/1m"
+	defaultLoginRateLimit   = "10/1m"
+	defaultEmailRateLimit   = "5/15m"
+)
+
+// InitRateLimitStore returns the store of the rate limits, nil when RATE_LIMIT_ENABLED=false.
+// The counters are kept in memory, each instance of the api enforces the limits on its own.
+func InitRateLimitStore() ratelimit.Store {
+	if viper.IsSet("RATE_LIMIT_ENABLED") && !viper.GetBool("RATE_LIMIT_ENABLED") {
+		return nil
+	}
+	return ratelimit.NewMemoryStore()
+}
+
+// rateLimit limits the routes it is applied to with the limit set in envKey, or fallback when it is not set
+func rateLimit(store ratelimit.Store, name string, envKey string, fallback string, key ratelimit.KeyFunc) gin.HandlerFunc {
+	if store == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	spec := viper.GetString(envKey)
+	if spec == "" {
+		spec = fallback
+	}
+
+	limit, err := ratelimit.ParseLimit(spec)
+	if err != nil {
+		panic("invalid " + envKey + ": " + err.Error())
+	}
+
+	limiter, err := ratelimit.New(viper.GetString("RATE_LIMIT_ALGORITHM"), store, limit)
+	if err != nil {
+		panic("invalid RATE_LIMIT_ALGORITHM: " + err.Error())
+	}
+
+	return ratelimit.Middleware(name, limiter, key)
+}
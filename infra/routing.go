@@ -2,7 +2,17 @@ package infra
 
 import (
 	"go_starter_api/internal/account"
+	"go_starter_api/internal/admin"
+	"go_starter_api/internal/export"
+	"go_starter_api/internal/organization"
+	"go_starter_api/internal/role"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/keyring"
 	"go_starter_api/pkg/mailer"
+	"go_starter_api/pkg/oauth"
+	"go_starter_api/pkg/ratelimit"
+	"go_starter_api/pkg/storage"
+	"go_starter_api/pkg/webauthn"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -12,22 +22,155 @@ import (
 func SetupRoutes(
 	rg *gin.RouterGroup,
 	db *gorm.DB,
+	keyRing keyring.KeyRing,
+	rateLimitStore ratelimit.Store,
+	oauthProviders []oauth.Provider,
+	relyingParty *webauthn.RelyingParty,
+	store storage.Storage,
 	logger *logrus.Logger,
 ) {
 	emailService := mailer.NewEmailService()
 
 	accountRepository := account.NewAccountRepository(db)
-	accountService := account.NewAccountService(emailService)
-	accountHandler := account.NewAccountHandler(logger, accountService, accountRepository)
+	tokenRevocationRepository := account.NewTokenRevocationRepository(db)
+	accountService := account.NewAccountService(emailService, keyRing)
+	accountHandler := account.NewAccountHandler(logger, accountService, accountRepository, tokenRevocationRepository)
+	oauthHandler := account.NewOAuthHandler(logger, accountHandler, oauthProviders)
+	authorizationServerHandler := account.NewAuthorizationServerHandler(logger, accountService, accountRepository)
+	webauthnHandler := account.NewWebAuthnHandler(logger, accountHandler, relyingParty)
+	avatarHandler := account.NewAvatarHandler(logger, accountHandler, store)
+
+	roleRepository := role.NewRoleRepository(db)
+	roleHandler := role.NewRoleHandler(logger, roleRepository)
+
+	adminHandler := admin.NewAdminHandler(logger, accountService, accountRepository, tokenRevocationRepository)
+
+	organizationRepository := organization.NewOrganizationRepository(db)
+	organizationHandler := organization.NewOrganizationHandler(logger, accountService, accountRepository, organizationRepository, accountHandler)
+
+	exportHandler := export.NewExportHandler(logger, accountService, accountRepository, export.NewExportRepository(db))
+
+	// stricter limits on the routes open to credential guessing and on those sending emails
+	loginLimit := rateLimit(rateLimitStore, "login", "RATE_LIMIT_LOGIN", defaultLoginRateLimit, ratelimit.ByIP)
+	emailLimit := rateLimit(rateLimitStore, "email", "RATE_LIMIT_EMAIL", defaultEmailRateLimit, ratelimit.ByIP)
 
 	rg.POST("/account/register", accountHandler.RegisterAccount)
-	rg.POST("/account/login", accountHandler.LoginAccount)
-	rg.POST("/account/forgot-password", accountHandler.ForgotPassword)
+	rg.POST("/account/login", loginLimit, accountHandler.LoginAccount)
+	rg.POST("/account/login/mfa", loginLimit, accountHandler.LoginAccountMFA)
+	rg.POST("/account/magic-link", emailLimit, accountHandler.RequestMagicLink)
+	rg.POST("/account/magic-link/consume", accountHandler.ConsumeMagicLink)
+	rg.POST("/account/forgot-password", emailLimit, accountHandler.ForgotPassword)
 	rg.POST("/account/reset-password", accountHandler.ResetPassword)
+	rg.POST("/account/token/refresh", accountHandler.RefreshToken)
+	rg.POST("/account/verify-email", accountHandler.VerifyEmail)
+	rg.POST("/account/verify-email/resend", emailLimit, accountHandler.ResendVerificationEmail)
+	rg.POST("/account/change-email/confirm", accountHandler.ConfirmEmailChange)
+	rg.POST("/account/change-email/cancel", accountHandler.CancelEmailChange)
+	rg.POST("/account/restore", accountHandler.RestoreAccount)
+	rg.POST("/account/sign-in/report", accountHandler.ReportSignIn)
+	rg.GET("/account/export/download", exportHandler.DownloadExport)
+	rg.GET("/avatars/*path", avatarHandler.GetAvatar)
+	rg.GET("/account/oauth/providers", oauthHandler.ListOAuthProviders)
+	rg.POST("/account/oauth/:provider/authorize", oauthHandler.AuthorizeOAuth)
+	rg.POST("/account/oauth/:provider/callback", loginLimit, oauthHandler.CompleteOAuth)
+	rg.POST("/invitations/accept", organizationHandler.AcceptInvitation)
+	rg.POST("/invitations/decline", organizationHandler.DeclineInvitation)
 
-	rg.Use(account.AuthMiddleware(accountService))
+	// passkeys are off until a relying party is configured
+	if relyingParty != nil {
+		rg.POST("/account/webauthn/login/begin", loginLimit, webauthnHandler.BeginLogin)
+		rg.POST("/account/webauthn/login/finish", loginLimit, webauthnHandler.FinishLogin)
+	}
+
+	// the authorization server signs with the keyring, it is off without one
+	if keyRing != nil {
+		rg.POST("/oauth/token", loginLimit, authorizationServerHandler.Token)
+		rg.GET("/oauth/userinfo", authorizationServerHandler.UserInfo)
+		rg.POST("/oauth/userinfo", authorizationServerHandler.UserInfo)
+	}
+
+	rg.Use(account.AuthMiddleware(accountService, accountRepository, tokenRevocationRepository))
+	rg.Use(rateLimit(rateLimitStore, "account", "RATE_LIMIT_ACCOUNT", defaultAccountRateLimit, ratelimit.ByAccountID))
+
+	// routes managing the credentials, sessions and data of the account, a scoped api key could take it over
+	rejectAPIKeys := account.RejectAPIKeys()
+	// self-service routes, an api key limited to other scopes cannot use them
+	canWriteProfile := account.RequireScope(domain.ScopeProfileWrite)
+	canWriteOrganizations := account.RequireScope(domain.ScopeOrganizationsWrite)
 
 	rg.GET("/account/profile", accountHandler.GetProfile)
+	rg.PATCH("/account/profile", canWriteProfile, accountHandler.UpdateProfile)
+	rg.POST("/account/avatar", canWriteProfile, avatarHandler.UploadAvatar)
+	rg.POST("/account/avatar/upload-url", canWriteProfile, avatarHandler.CreateAvatarUploadURL)
+	rg.DELETE("/account/avatar", canWriteProfile, avatarHandler.DeleteAvatar)
 	rg.POST("/account/logout", accountHandler.LogoutAccount)
-	rg.POST("/account/change-password", accountHandler.ChangePassword)
+	rg.POST("/account/logout-all", rejectAPIKeys, accountHandler.LogoutAllAccount)
+	rg.POST("/account/change-password", rejectAPIKeys, accountHandler.ChangePassword)
+	rg.POST("/account/change-email", rejectAPIKeys, emailLimit, accountHandler.ChangeEmail)
+	rg.POST("/account/delete", rejectAPIKeys, accountHandler.DeleteAccount)
+	rg.POST("/account/export", rejectAPIKeys, emailLimit, exportHandler.RequestExport)
+	rg.GET("/account/export/:id", exportHandler.GetExport)
+	rg.GET("/account/activity", accountHandler.ListActivities)
+	rg.GET("/account/sessions", accountHandler.ListSessions)
+	rg.DELETE("/account/sessions/:id", rejectAPIKeys, accountHandler.RevokeSession)
+	rg.POST("/account/api-keys", rejectAPIKeys, accountHandler.CreateAPIKey)
+	rg.GET("/account/api-keys", accountHandler.ListAPIKeys)
+	rg.DELETE("/account/api-keys/:id", rejectAPIKeys, accountHandler.RevokeAPIKey)
+	rg.GET("/account/identities", oauthHandler.ListIdentities)
+	rg.POST("/account/identities/:provider/authorize", rejectAPIKeys, oauthHandler.AuthorizeLinkIdentity)
+	rg.POST("/account/identities/:provider/callback", rejectAPIKeys, oauthHandler.CompleteLinkIdentity)
+	rg.DELETE("/account/identities/:id", rejectAPIKeys, oauthHandler.UnlinkIdentity)
+	rg.GET("/account/roles", roleHandler.GetAccountRoles)
+
+	rg.GET("/roles", role.RequirePermission(roleRepository, domain.PermissionRolesRead), roleHandler.ListRoles)
+
+	canReadAccounts := role.RequirePermission(roleRepository, domain.PermissionAccountsRead)
+	canWriteAccounts := role.RequirePermission(roleRepository, domain.PermissionAccountsWrite)
+
+	rg.GET("/admin/accounts", canReadAccounts, adminHandler.ListAccounts)
+	rg.GET("/admin/accounts/:id", canReadAccounts, adminHandler.GetAccount)
+	rg.GET("/admin/activity", canReadAccounts, adminHandler.ListActivities)
+	rg.DELETE("/admin/accounts/:id", canWriteAccounts, adminHandler.DeleteAccount)
+	rg.POST("/admin/accounts/:id/disable", canWriteAccounts, adminHandler.DisableAccount)
+	rg.POST("/admin/accounts/:id/enable", canWriteAccounts, adminHandler.EnableAccount)
+	rg.POST("/admin/accounts/:id/unlock", canWriteAccounts, adminHandler.UnlockAccount)
+	rg.POST("/admin/accounts/:id/force-password-reset", canWriteAccounts, adminHandler.ForcePasswordReset)
+	rg.POST("/admin/accounts/:id/restore", canWriteAccounts, adminHandler.RestoreAccount)
+
+	if keyRing != nil {
+		rg.GET("/oauth/authorize", authorizationServerHandler.GetAuthorization)
+		rg.POST("/oauth/authorize", rejectAPIKeys, authorizationServerHandler.ApproveAuthorization)
+
+		rg.POST("/admin/oauth-clients", role.RequirePermission(roleRepository, domain.PermissionOAuthClientsWrite), adminHandler.CreateOAuthClient)
+		rg.GET("/admin/oauth-clients", role.RequirePermission(roleRepository, domain.PermissionOAuthClientsRead), adminHandler.ListOAuthClients)
+		rg.DELETE("/admin/oauth-clients/:clientId", role.RequirePermission(roleRepository, domain.PermissionOAuthClientsWrite), adminHandler.DeleteOAuthClient)
+	}
+
+	rg.POST("/organizations", canWriteOrganizations, organizationHandler.CreateOrganization)
+	rg.GET("/organizations", organizationHandler.ListOrganizations)
+	rg.POST("/organizations/:id/switch", rejectAPIKeys, organizationHandler.SwitchOrganization)
+
+	// routes working on the active organization, tenant owned records are scoped to it
+	member := rg.Group("", organization.RequireOrganization(organizationRepository))
+
+	member.GET("/organization/members", organizationHandler.ListMembers)
+	member.DELETE("/organization/members/:accountId", canWriteOrganizations, organizationHandler.RemoveMember)
+	member.POST("/organization/invitations", canWriteOrganizations, emailLimit, organizationHandler.SendInvitation)
+	member.GET("/organization/invitations", organizationHandler.ListInvitations)
+	member.DELETE("/organization/invitations/:id", canWriteOrganizations, organizationHandler.RevokeInvitation)
+
+	// routes refused to unverified accounts when EMAIL_VERIFICATION_POLICY=routes
+	verified := rg.Group("", account.RequireVerifiedEmail(accountRepository))
+
+	verified.POST("/account/mfa/enroll", rejectAPIKeys, accountHandler.EnrollMFA)
+	verified.POST("/account/mfa/confirm", rejectAPIKeys, accountHandler.ConfirmMFA)
+	verified.POST("/account/mfa/disable", rejectAPIKeys, accountHandler.DisableMFA)
+	verified.POST("/account/mfa/recovery-codes", rejectAPIKeys, accountHandler.RegenerateRecoveryCodes)
+
+	if relyingParty != nil {
+		verified.POST("/account/webauthn/register/begin", rejectAPIKeys, webauthnHandler.BeginRegistration)
+		verified.POST("/account/webauthn/register/finish", rejectAPIKeys, webauthnHandler.FinishRegistration)
+		verified.GET("/account/webauthn/credentials", webauthnHandler.ListCredentials)
+		verified.DELETE("/account/webauthn/credentials/:id", rejectAPIKeys, webauthnHandler.DeleteCredential)
+	}
 }
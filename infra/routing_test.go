@@ -0,0 +1,80 @@
+package infra
+
+import (
+	"context"
+	"go_starter_api/internal/account"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestSetupRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// setup serves the routes on an in-memory database holding an account with an api key
+	// limited to the scopes, it returns the key
+	setup := func(t *testing.T, scopes []string) (*gin.Engine, string) {
+		db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{TranslateError: true})
+		require.NoError(t, err)
+		require.NoError(t, db.AutoMigrate(&domain.Account{}, &domain.APIKey{}))
+
+		ctx := context.Background()
+		acc := &domain.Account{Email: "test@example.com", Password: "hashed_password", EmailVerified: true}
+		require.NoError(t, db.Create(acc).Error)
+
+		key, record, err := account.NewAccountService(nil, nil).GenerateAPIKey(ctx, acc)
+		require.NoError(t, err)
+		record.Scopes = scopes
+		require.NoError(t, db.Create(record).Error)
+
+		router := gin.New()
+		SetupRoutes(router.Group("/api/v1"), db, nil, ratelimit.NewMemoryStore(), nil, nil, nil, logrus.New())
+		return router, key
+	}
+
+	serve := func(router *gin.Engine, method string, path string, key string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		req.Header.Set(account.AuthHeaderKey, key)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("should refuse the self-service routes to an api key limited to other scopes", func(t *testing.T) {
+		router, key := setup(t, []string{domain.PermissionAccountsRead})
+
+		routes := []struct{ method, path string }{
+			{"POST", "/api/v1/account/delete"},
+			{"POST", "/api/v1/account/export"},
+			{"POST", "/api/v1/organizations/1/switch"},
+			{"PATCH", "/api/v1/account/profile"},
+			{"POST", "/api/v1/account/avatar"},
+			{"POST", "/api/v1/account/avatar/upload-url"},
+			{"DELETE", "/api/v1/account/avatar"},
+			{"POST", "/api/v1/organizations"},
+		}
+		for _, route := range routes {
+			w := serve(router, route.method, route.path, key)
+			assert.Equal(t, http.StatusForbidden, w.Code, route.method+" "+route.path)
+		}
+	})
+
+	t.Run("should refuse deletion, export and switching organization to any api key", func(t *testing.T) {
+		router, key := setup(t, nil)
+
+		for _, path := range []string{"/api/v1/account/delete", "/api/v1/account/export", "/api/v1/organizations/1/switch"} {
+			w := serve(router, "POST", path, key)
+			assert.Equal(t, http.StatusForbidden, w.Code, path)
+			assert.JSONEq(t, `{"error":"api keys cannot manage credentials"}`, w.Body.String(), path)
+		}
+	})
+}
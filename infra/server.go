@@ -2,7 +2,15 @@ package infra
 
 import (
 	"fmt"
+	"go_starter_api/internal/account"
+	"go_starter_api/pkg/audit"
+	"go_starter_api/pkg/keyring"
+	"go_starter_api/pkg/oauth"
+	"go_starter_api/pkg/ratelimit"
+	"go_starter_api/pkg/storage"
+	"go_starter_api/pkg/webauthn"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -25,25 +33,74 @@ func ginServerMode() string {
 	return gin.ReleaseMode
 }
 
+// trustedProxies returns the comma separated TRUSTED_PROXIES, ip addresses or cidr ranges
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(viper.GetString("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// newRouter returns the engine of the api. X-Forwarded-For is only read from TRUSTED_PROXIES,
+// none by default, so a client cannot choose the ip address that the rate limits, the login
+// failures, the new device check and the audit log count it under
+func newRouter() *gin.Engine {
+	router := gin.Default()
+	if err := router.SetTrustedProxies(trustedProxies()); err != nil {
+		panic("invalid TRUSTED_PROXIES: " + err.Error())
+	}
+	return router
+}
+
 func NewServer(
 	db *gorm.DB,
+	keyRing keyring.KeyRing,
+	rateLimitStore ratelimit.Store,
+	oauthProviders []oauth.Provider,
+	relyingParty *webauthn.RelyingParty,
+	store storage.Storage,
 	logger *logrus.Logger,
 	config Config,
 ) *http.Server {
 	gin.SetMode(ginServerMode())
 
-	router := gin.Default()
+	router := newRouter()
 	router.Use(otelgin.Middleware("go_starter-api"))
+	// every request is described in its context for the audit log, and answered with its id
+	router.Use(audit.Middleware())
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 
-	rg := router.Group("/api/v1")
+	// public keys for services verifying our tokens, rotated keys stay listed until removed
+	router.GET("/.well-known/jwks.json", func(c *gin.Context) {
+		jwks := keyring.JSONWebKeySet{Keys: []keyring.JSONWebKey{}}
+		if keyRing != nil {
+			jwks = keyRing.JWKS()
+		}
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, jwks)
+	})
+
+	// discovery of the authorization server, other apps delegate login to this api with it
+	if keyRing != nil {
+		router.GET("/.well-known/openid-configuration", account.OpenIDConfiguration(keyRing))
+	}
+
+	// the local disk storage serves its presigned urls itself, a bucket is reached directly
+	if fs, ok := store.(*storage.FileSystem); ok {
+		router.Any("/storage/*key", gin.WrapH(http.StripPrefix("/storage", fs)))
+	}
+
+	rg := router.Group("/api/v1", rateLimit(rateLimitStore, "default", "RATE_LIMIT_DEFAULT", defaultRateLimit, ratelimit.ByIP))
 
 	rg.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "ok"})
 	})
 
-	SetupRoutes(rg, db, logger)
+	SetupRoutes(rg, db, keyRing, rateLimitStore, oauthProviders, relyingParty, store, logger)
 
 	srv := &http.Server{
 		Addr:    fmt.Sprintf(":%d", config.Port),
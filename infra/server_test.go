@@ -0,0 +1,53 @@
+package infra
+
+import (
+	"go_starter_api/pkg/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRouter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// serve sends a request of the peer with the forwarded for header to a route limited to 2 requests per ip
+	setup := func() func(peer string, forwardedFor string) int {
+		router := newRouter()
+		router.GET("/limited", rateLimit(ratelimit.NewMemoryStore(), "test", "RATE_LIMIT_TEST", "2/1m", ratelimit.ByIP), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		return func(peer string, forwardedFor string) int {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/limited", nil)
+			req.RemoteAddr = peer + ":1234"
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+			router.ServeHTTP(w, req)
+			return w.Code
+		}
+	}
+
+	t.Run("should count a spoofed forwarded for against the peer", func(t *testing.T) {
+		viper.Reset()
+		serve := setup()
+
+		assert.Equal(t, http.StatusOK, serve("9.9.9.9", "1.1.1.1"))
+		assert.Equal(t, http.StatusOK, serve("9.9.9.9", "2.2.2.2"))
+		assert.Equal(t, http.StatusTooManyRequests, serve("9.9.9.9", "3.3.3.3"))
+	})
+
+	t.Run("should read the forwarded for of a trusted proxy", func(t *testing.T) {
+		viper.Set("TRUSTED_PROXIES", "10.0.0.0/8, 9.9.9.9")
+		defer viper.Reset()
+		serve := setup()
+
+		assert.Equal(t, http.StatusOK, serve("10.0.0.1", "1.1.1.1"))
+		assert.Equal(t, http.StatusOK, serve("9.9.9.9", "1.1.1.1"))
+		assert.Equal(t, http.StatusTooManyRequests, serve("10.0.0.1", "1.1.1.1"))
+		assert.Equal(t, http.StatusOK, serve("10.0.0.1", "2.2.2.2"))
+	})
+}
@@ -0,0 +1,63 @@
+package infra
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"go_starter_api/pkg/storage"
+
+	"github.com/spf13/viper"
+)
+
+const defaultStorageDir = "_storage"
+
+// InitStorage returns the storage of the uploads set with STORAGE_DRIVER, files are kept on
+// the local disk by default and in an S3 compatible bucket such as MinIO with STORAGE_DRIVER=s3
+func InitStorage() storage.Storage {
+	switch viper.GetString("STORAGE_DRIVER") {
+	case "", "fs":
+		dir := viper.GetString("STORAGE_DIR")
+		if dir == "" {
+			dir = defaultStorageDir
+		}
+
+		storageURL := viper.GetString("STORAGE_URL")
+		if storageURL == "" {
+			storageURL = viper.GetString("SERVER_URL") + "/storage"
+		}
+
+		// without a secret the presigned urls only work on this instance until it restarts
+		secret := viper.GetString("STORAGE_SECRET")
+		if secret == "" {
+			b := make([]byte, 32)
+			if _, err := rand.Read(b); err != nil {
+				panic("failed to generate storage secret")
+			}
+			secret = hex.EncodeToString(b)
+		}
+
+		fs, err := storage.NewFileSystem(storage.FileSystemConfig{
+			Dir:    dir,
+			URL:    storageURL,
+			Secret: secret,
+		})
+		if err != nil {
+			panic("invalid storage config: " + err.Error())
+		}
+		return fs
+	case "s3":
+		s3, err := storage.NewS3(storage.S3Config{
+			Endpoint:        viper.GetString("S3_ENDPOINT"),
+			Region:          viper.GetString("S3_REGION"),
+			Bucket:          viper.GetString("S3_BUCKET"),
+			AccessKeyID:     viper.GetString("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: viper.GetString("S3_SECRET_ACCESS_KEY"),
+			PathStyle:       viper.GetBool("S3_PATH_STYLE"),
+		})
+		if err != nil {
+			panic("invalid storage config: " + err.Error())
+		}
+		return s3
+	default:
+		panic("invalid STORAGE_DRIVER: must be fs or s3")
+	}
+}
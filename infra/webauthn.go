@@ -0,0 +1,39 @@
+package infra
+
+import (
+	"go_starter_api/pkg/webauthn"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// InitRelyingParty returns the passkey relying party configured with the WEBAUTHN_* settings,
+// passkeys are off when WEBAUTHN_RP_ID is not set
+func InitRelyingParty() *webauthn.RelyingParty {
+	rpID := viper.GetString("WEBAUTHN_RP_ID")
+	if rpID == "" {
+		return nil
+	}
+
+	var origins []string
+	for _, origin := range strings.Split(viper.GetString("WEBAUTHN_ORIGINS"), ",") {
+		origin = strings.TrimSuffix(strings.TrimSpace(origin), "/")
+		if origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	if len(origins) == 0 {
+		panic("invalid webauthn config: WEBAUTHN_ORIGINS is required with WEBAUTHN_RP_ID")
+	}
+
+	rpName := viper.GetString("WEBAUTHN_RP_NAME")
+	if rpName == "" {
+		rpName = rpID
+	}
+
+	return webauthn.NewRelyingParty(webauthn.Config{
+		RPID:    rpID,
+		RPName:  rpName,
+		Origins: origins,
+	})
+}
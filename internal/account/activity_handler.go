@@ -0,0 +1,118 @@
+package account
+
+import (
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultActivityPageSize = 20
+	maxActivityPageSize     = 100
+)
+
+type ActivityResponse struct {
+	ID       uint           `json:"id"`
+	Activity string         `json:"activity"`
+	Details  map[string]any `json:"details,omitempty"`
+	// ByAdministrator is set on the actions an administrator took on the account
+	ByAdministrator bool      `json:"by_administrator"`
+	IPAddress       string    `json:"ip_address,omitempty"`
+	UserAgent       string    `json:"user_agent,omitempty"`
+	RequestID       string    `json:"request_id,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type ListActivitiesResponse struct {
+	Activities []ActivityResponse `json:"activities"`
+	Total      int64              `json:"total"`
+	Page       int                `json:"page"`
+	PageSize   int                `json:"page_size"`
+}
+
+// @Summary		List Activity
+// @Description	List the history of the authenticated user page by page, the most recent first
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			page			query		int		false	"Page, starting at 1"
+// @Param			page_size		query		int		false	"Activities per page, at most 100"
+// @Param			type			query		string	false	"Activity, such as login"
+// @Param			created_after	query		string	false	"RFC 3339 time or date"
+// @Param			created_before	query		string	false	"RFC 3339 time or date"
+// @Success		200				{object}	ListActivitiesResponse
+// @Failure		400				{object}	map[string]string
+// @Failure		500				{object}	map[string]string
+// @Router			/api/v1/account/activity [get]
+func (h *AccountHandler) ListActivities(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "ListActivities")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	page, err := utils.QueryInt(c, "page", 1)
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page"})
+		return
+	}
+	pageSize, err := utils.QueryInt(c, "page_size", defaultActivityPageSize)
+	if err != nil || pageSize < 1 || pageSize > maxActivityPageSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page size"})
+		return
+	}
+
+	filter := domain.ActivityFilter{
+		AccountID: accountID,
+		Activity:  c.Query("type"),
+		Offset:    (page - 1) * pageSize,
+		Limit:     pageSize,
+	}
+
+	filter.CreatedAfter, err = utils.QueryTime(c, "created_after")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid created_after"})
+		return
+	}
+	filter.CreatedBefore, err = utils.QueryTime(c, "created_before")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid created_before"})
+		return
+	}
+
+	activities, total, err := h.accountRepository.ListActivities(ctx, filter)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to list activities: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	response := ListActivitiesResponse{
+		Activities: []ActivityResponse{},
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+	}
+	for _, activity := range activities {
+		response.Activities = append(response.Activities, ActivityResponse{
+			ID:              activity.ID,
+			Activity:        activity.Activity,
+			Details:         activity.Details,
+			ByAdministrator: activity.ActorID != nil,
+			IPAddress:       activity.IPAddress,
+			UserAgent:       activity.UserAgent,
+			RequestID:       activity.RequestID,
+			CreatedAt:       activity.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,96 @@
+package account_test
+
+import (
+	"context"
+	"errors"
+	"go_starter_api/internal/account"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestListActivities(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	setup := func(t *testing.T, repository *domain.MockAccountRepository) *HTTPTestHelper {
+		handler := account.NewAccountHandler(logrus.New(), domain.NewMockAccountService(t), repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.GET("/account/activity", func(c *gin.Context) {
+			c.Set(utils.AccountIdContextKey, uint(1))
+		}, handler.ListActivities)
+		return httpHelper
+	}
+
+	t.Run("should list a filtered page of the account's activities", func(t *testing.T) {
+		repository := domain.NewMockAccountRepository(t)
+		httpHelper := setup(t, repository)
+
+		createdAfter := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		actorID := uint(100)
+		repository.On("ListActivities", anyContext, mock.MatchedBy(func(filter domain.ActivityFilter) bool {
+			return filter.AccountID == 1 &&
+				filter.ActorID == 0 &&
+				filter.Activity == domain.ActivityLogin &&
+				filter.CreatedAfter != nil && filter.CreatedAfter.Equal(createdAfter) &&
+				filter.CreatedBefore == nil &&
+				filter.Offset == 10 && filter.Limit == 10
+		})).Return([]domain.AccountActivity{
+			{ID: 3, AccountID: 1, Activity: domain.ActivityLogin, IPAddress: "203.0.113.7", UserAgent: "curl/8.0", RequestID: "abc", TraceID: "def"},
+			{ID: 2, AccountID: 1, Activity: domain.ActivityLogin, ActorID: &actorID},
+		}, int64(12), nil)
+
+		w := httpHelper.MakeRequest("GET", "/account/activity?page=2&page_size=10&type=login&created_after=2025-01-01", nil, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response account.ListActivitiesResponse
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.Equal(t, int64(12), response.Total)
+		assert.Equal(t, 2, response.Page)
+		assert.Len(t, response.Activities, 2)
+		assert.Equal(t, "203.0.113.7", response.Activities[0].IPAddress)
+		assert.Equal(t, "curl/8.0", response.Activities[0].UserAgent)
+		assert.False(t, response.Activities[0].ByAdministrator)
+		assert.True(t, response.Activities[1].ByAdministrator)
+		assert.NotContains(t, w.Body.String(), "actor_id")
+	})
+
+	t.Run("should reject invalid filters", func(t *testing.T) {
+		httpHelper := setup(t, domain.NewMockAccountRepository(t))
+
+		for target, expected := range map[string]string{
+			"/account/activity?page=0":                   "invalid page",
+			"/account/activity?page_size=1000":           "invalid page size",
+			"/account/activity?created_after=yesterday":  "invalid created_after",
+			"/account/activity?created_before=yesterday": "invalid created_before",
+		} {
+			w := httpHelper.MakeRequest("GET", target, nil, nil)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.JSONEq(t, `{"error":"`+expected+`"}`, w.Body.String())
+		}
+	})
+
+	t.Run("should fail when the activities cannot be listed", func(t *testing.T) {
+		repository := domain.NewMockAccountRepository(t)
+		httpHelper := setup(t, repository)
+
+		repository.On("ListActivities", anyContext, mock.Anything).Return(nil, int64(0), errors.New("db error"))
+
+		w := httpHelper.MakeRequest("GET", "/account/activity", nil, nil)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -0,0 +1,253 @@
+package account
+
+import (
+	"errors"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const maxAPIKeyNameLength = 100
+
+type CreateAPIKeyRequest struct {
+	Name string `json:"name"`
+	// ExpiresAt is optional, the key does not expire without it
+	ExpiresAt *time.Time `json:"expires_at"`
+	// Scopes are optional permissions to limit the key to, such as accounts:read
+	Scopes []string `json:"scopes"`
+}
+
+type APIKeyResponse struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Expired    bool       `json:"expired"`
+}
+
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	// Key is only returned on creation, it is sent in the Authorization header
+	Key string `json:"key"`
+}
+
+func toAPIKeyResponse(key *domain.APIKey, now time.Time) APIKeyResponse {
+	scopes := key.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     scopes,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+		Expired:    !key.Active(now),
+	}
+}
+
+// @Summary		Create API Key
+// @Description	Create a personal access token for scripts and CI jobs, the key is only shown in this response. It can expire and be limited to scopes, which are permissions such as accounts:read, or profile:write and organizations:write for the self-service routes. API keys cannot create other keys, change credentials, delete or export the account, nor switch organization.
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			key	body		CreateAPIKeyRequest	true	"API Key"
+// @Success		200	{object}	CreateAPIKeyResponse
+// @Failure		400	{object}	map[string]string
+// @Failure		403	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/account/api-keys [post]
+func (h *AccountHandler) CreateAPIKey(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "CreateAPIKey")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+	if len(req.Name) > maxAPIKeyNameLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is too long"})
+		return
+	}
+
+	now := time.Now()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+		return
+	}
+
+	scopes, ok := normalizeScopes(req.Scopes)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid scope"})
+		return
+	}
+
+	acc, err := h.accountRepository.GetAccountByID(ctx, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to get account by id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	key, record, err := h.accountService.GenerateAPIKey(ctx, acc)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to generate api key: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate api key"})
+		return
+	}
+	record.Name = req.Name
+	record.Scopes = scopes
+	record.ExpiresAt = req.ExpiresAt
+
+	record, err = h.accountRepository.CreateAPIKey(ctx, record)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to store api key: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate api key"})
+		return
+	}
+
+	err = h.accountRepository.LogAccountActivity(ctx, accountID, domain.ActivityAPIKeyCreated)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to log activity: %v", err)
+	}
+
+	c.JSON(http.StatusOK, CreateAPIKeyResponse{
+		APIKeyResponse: toAPIKeyResponse(record, now),
+		Key:            key,
+	})
+}
+
+type ListAPIKeysResponse struct {
+	APIKeys []APIKeyResponse `json:"api_keys"`
+}
+
+// @Summary		List API Keys
+// @Description	List the api keys of the authenticated user that are not revoked, the keys themselves are never shown again
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Success		200	{object}	ListAPIKeysResponse
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/account/api-keys [get]
+func (h *AccountHandler) ListAPIKeys(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "ListAPIKeys")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	keys, err := h.accountRepository.ListAPIKeys(ctx, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to list api keys: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	now := time.Now()
+	response := ListAPIKeysResponse{APIKeys: []APIKeyResponse{}}
+	for i := range keys {
+		response.APIKeys = append(response.APIKeys, toAPIKeyResponse(&keys[i], now))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary		Revoke API Key
+// @Description	Revoke an api key of the authenticated user, it stops working immediately
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			id	path		int	true	"API Key ID"
+// @Success		200	{object}	map[string]string
+// @Failure		400	{object}	map[string]string
+// @Failure		404	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/account/api-keys/{id} [delete]
+func (h *AccountHandler) RevokeAPIKey(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "RevokeAPIKey")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	keyID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid api key id"})
+		return
+	}
+
+	err = h.accountRepository.RevokeAPIKey(ctx, accountID, uint(keyID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "api key not found"})
+			return
+		}
+		h.logger.WithField("userId", accountID).Errorf("failed to revoke api key: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	err = h.accountRepository.LogAccountActivity(ctx, accountID, domain.ActivityAPIKeyRevoked)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to log activity: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "api key revoked"})
+}
+
+// normalizeScopes trims and deduplicates the scopes, each must be a permission as
+// <resource>:<action>, <resource>:* or *
+func normalizeScopes(scopes []string) ([]string, bool) {
+	var normalized []string
+	seen := map[string]bool{}
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if scope != domain.PermissionAll {
+			resource, action, found := strings.Cut(scope, ":")
+			if !found || resource == "" || action == "" || strings.Contains(action, ":") {
+				return nil, false
+			}
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			normalized = append(normalized, scope)
+		}
+	}
+	return normalized, true
+}
@@ -0,0 +1,141 @@
+package account_test
+
+import (
+	"context"
+	"go_starter_api/internal/account"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm"
+)
+
+func TestAccountHandler_APIKeys(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	authenticate := func(c *gin.Context) {
+		c.Set(utils.AccountIdContextKey, uint(1))
+		c.Set(utils.AuthClaimsContextKey, &domain.AuthClaims{AccountID: 1, SessionID: 5})
+	}
+
+	t.Run("should create a key and return it once", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := &domain.Account{ID: 1}
+		expiresAt := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		service.On("GenerateAPIKey", anyContext, acc).Return("gsa_0123456789ab_secret", &domain.APIKey{AccountID: 1, Prefix: "gsa_0123456789ab", TokenHash: "hash"}, nil)
+		repository.On("CreateAPIKey", anyContext, mock.MatchedBy(func(k *domain.APIKey) bool {
+			return k.Name == "ci" && k.ExpiresAt.Equal(expiresAt) &&
+				assert.ObjectsAreEqual([]string{domain.PermissionAccountsRead}, k.Scopes)
+		})).Return(func(_ context.Context, k *domain.APIKey) *domain.APIKey {
+			k.ID = 3
+			return k
+		}, nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityAPIKeyCreated).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.POST("/account/api-keys", authenticate, handler.CreateAPIKey)
+
+		reqBody := account.CreateAPIKeyRequest{Name: " ci ", ExpiresAt: &expiresAt, Scopes: []string{"accounts:read", " accounts:read"}}
+		w := httpHelper.MakeRequest("POST", "/account/api-keys", reqBody, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response account.CreateAPIKeyResponse
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.Equal(t, uint(3), response.ID)
+		assert.Equal(t, "gsa_0123456789ab_secret", response.Key)
+		assert.Equal(t, "gsa_0123456789ab", response.Prefix)
+		assert.NotContains(t, w.Body.String(), "hash")
+	})
+
+	t.Run("should refuse invalid requests", func(t *testing.T) {
+		logger := logrus.New()
+		handler := account.NewAccountHandler(logger, domain.NewMockAccountService(t), domain.NewMockAccountRepository(t), account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.POST("/account/api-keys", authenticate, handler.CreateAPIKey)
+
+		past := time.Now().Add(-time.Hour)
+		for name, reqBody := range map[string]account.CreateAPIKeyRequest{
+			"missing name": {Name: " "},
+			"expired":      {Name: "ci", ExpiresAt: &past},
+			"bad scope":    {Name: "ci", Scopes: []string{"accounts"}},
+		} {
+			w := httpHelper.MakeRequest("POST", "/account/api-keys", reqBody, nil)
+			assert.Equal(t, http.StatusBadRequest, w.Code, name)
+		}
+	})
+
+	t.Run("should list the keys of the account", func(t *testing.T) {
+		logger := logrus.New()
+		repository := domain.NewMockAccountRepository(t)
+
+		past := time.Now().Add(-time.Hour)
+		repository.On("ListAPIKeys", anyContext, uint(1)).Return([]domain.APIKey{
+			{ID: 3, AccountID: 1, Name: "ci", Prefix: "gsa_0123456789ab"},
+			{ID: 4, AccountID: 1, Name: "old", Prefix: "gsa_ba9876543210", ExpiresAt: &past},
+		}, nil)
+
+		handler := account.NewAccountHandler(logger, domain.NewMockAccountService(t), repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.GET("/account/api-keys", authenticate, handler.ListAPIKeys)
+
+		w := httpHelper.MakeRequest("GET", "/account/api-keys", nil, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response account.ListAPIKeysResponse
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.Len(t, response.APIKeys, 2)
+		assert.False(t, response.APIKeys[0].Expired)
+		assert.Equal(t, []string{}, response.APIKeys[0].Scopes)
+		assert.True(t, response.APIKeys[1].Expired)
+	})
+
+	t.Run("should revoke a key of the account", func(t *testing.T) {
+		logger := logrus.New()
+		repository := domain.NewMockAccountRepository(t)
+
+		repository.On("RevokeAPIKey", anyContext, uint(1), uint(3)).Return(nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityAPIKeyRevoked).Return(nil)
+
+		handler := account.NewAccountHandler(logger, domain.NewMockAccountService(t), repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.DELETE("/account/api-keys/:id", authenticate, handler.RevokeAPIKey)
+
+		w := httpHelper.MakeRequest("DELETE", "/account/api-keys/3", nil, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should not revoke a key of another account", func(t *testing.T) {
+		logger := logrus.New()
+		repository := domain.NewMockAccountRepository(t)
+
+		repository.On("RevokeAPIKey", anyContext, uint(1), uint(9)).Return(gorm.ErrRecordNotFound)
+
+		handler := account.NewAccountHandler(logger, domain.NewMockAccountService(t), repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.DELETE("/account/api-keys/:id", authenticate, handler.RevokeAPIKey)
+
+		w := httpHelper.MakeRequest("DELETE", "/account/api-keys/9", nil, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,646 @@
+package account
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/keyring"
+	"go_starter_api/pkg/oauth"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// time the client has to redeem an authorization code
+const authorizationCodeTTL = 5 * time.Minute
+
+// standard scopes of OpenID Connect this server has no claims for, they are left out of the
+// grant instead of failing the clients that ask for them by default
+var unsupportedOIDCScopes = []string{"profile", "address", "phone", "offline_access"}
+
+// AuthorizationServerHandler lets other apps delegate login to this api with OpenID Connect.
+// The consent page is left to the frontend, which reads and approves the authorization
+// request of the client as json and sends the user back to the returned redirect uri.
+type AuthorizationServerHandler struct {
+	logger *logrus.Logger
+	tracer trace.Tracer
+
+	accountService    domain.AccountService
+	accountRepository domain.AccountRepository
+}
+
+func NewAuthorizationServerHandler(
+	logger *logrus.Logger,
+	accountService domain.AccountService,
+	accountRepository domain.AccountRepository,
+) *AuthorizationServerHandler {
+	tracer := otel.Tracer("authorizationServerHandler")
+	return &AuthorizationServerHandler{
+		logger:            logger,
+		tracer:            tracer,
+		accountService:    accountService,
+		accountRepository: accountRepository,
+	}
+}
+
+// AuthorizationRequest carries the parameters the client sent to the authorization endpoint
+type AuthorizationRequest struct {
+	ResponseType        string `json:"response_type" form:"response_type"`
+	ClientID            string `json:"client_id" form:"client_id"`
+	RedirectURI         string `json:"redirect_uri" form:"redirect_uri"`
+	Scope               string `json:"scope" form:"scope"`
+	State               string `json:"state" form:"state"`
+	Nonce               string `json:"nonce" form:"nonce"`
+	CodeChallenge       string `json:"code_challenge" form:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method" form:"code_challenge_method"`
+}
+
+type ApproveAuthorizationRequest struct {
+	AuthorizationRequest
+	// Approve is false when the user denied the request, the client is told so
+	Approve bool `json:"approve"`
+}
+
+type OAuthClientInfo struct {
+	ClientID string `json:"client_id"`
+	Name     string `json:"name"`
+}
+
+type AuthorizationDetailsResponse struct {
+	Client OAuthClientInfo `json:"client"`
+	Scopes []string        `json:"scopes"`
+	// ConsentRequired is false when the user already approved the scopes for the client,
+	// the request can then be approved without asking
+	ConsentRequired bool `json:"consent_required"`
+}
+
+type AuthorizationRedirectResponse struct {
+	// RedirectURI is where the user is sent back to the client, with the code or the error
+	RedirectURI string `json:"redirect_uri"`
+}
+
+type OAuthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope"`
+	IDToken     string `json:"id_token,omitempty"`
+}
+
+type UserInfoResponse struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email,omitempty"`
+	EmailVerified *bool  `json:"email_verified,omitempty"`
+}
+
+type OpenIDConfigurationResponse struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+// OpenIDConfiguration serves the discovery document of the authorization server, it is
+// published under the issuer next to the keys of the keyring
+func OpenIDConfiguration(keyRing keyring.KeyRing) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		issuer := oidcIssuer()
+
+		algorithms := []string{}
+		for _, key := range keyRing.JWKS().Keys {
+			if !slices.Contains(algorithms, key.Alg) {
+				algorithms = append(algorithms, key.Alg)
+			}
+		}
+
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, OpenIDConfigurationResponse{
+			Issuer:                            issuer,
+			AuthorizationEndpoint:             oidcAuthorizationURL(),
+			TokenEndpoint:                     issuer + "/api/v1/oauth/token",
+			UserinfoEndpoint:                  issuer + "/api/v1/oauth/userinfo",
+			JWKSURI:                           issuer + "/.well-known/jwks.json",
+			ResponseTypesSupported:            []string{"code"},
+			GrantTypesSupported:               []string{domain.GrantTypeAuthorizationCode, domain.GrantTypeClientCredentials},
+			SubjectTypesSupported:             []string{"public"},
+			IDTokenSigningAlgValuesSupported:  algorithms,
+			ScopesSupported:                   []string{domain.ScopeOpenID, domain.ScopeEmail},
+			TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+			CodeChallengeMethodsSupported:     []string{"S256"},
+			ClaimsSupported:                   []string{"iss", "sub", "aud", "exp", "iat", "nonce", "email", "email_verified"},
+		})
+	}
+}
+
+// @Summary		Get Authorization Request
+// @Description	Read the authorization request of a client for the consent page, the query is the one the client sent to the authorization endpoint
+// @Tags			oauth
+// @Produce		json
+// @Param			client_id				query		string	true	"Client ID"
+// @Param			redirect_uri			query		string	true	"Redirect URI"
+// @Param			response_type			query		string	true	"Response type, code"
+// @Param			scope					query		string	true	"Space separated scopes"
+// @Param			state					query		string	false	"State"
+// @Param			nonce					query		string	false	"Nonce"
+// @Param			code_challenge			query		string	true	"PKCE code challenge"
+// @Param			code_challenge_method	query		string	true	"PKCE code challenge method, S256"
+// @Success		200						{object}	AuthorizationDetailsResponse
+// @Failure		400						{object}	map[string]string
+// @Failure		500						{object}	map[string]string
+// @Router			/api/v1/oauth/authorize [get]
+func (h *AuthorizationServerHandler) GetAuthorization(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "GetAuthorization")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	var req AuthorizationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	client, scopes, ok := h.validateAuthorization(ctx, c, req)
+	if !ok {
+		return
+	}
+
+	consented, err := h.consented(ctx, accountID, client.ClientID, scopes)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to get oauth consent: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthorizationDetailsResponse{
+		Client:          OAuthClientInfo{ClientID: client.ClientID, Name: client.Name},
+		Scopes:          scopes,
+		ConsentRequired: !consented,
+	})
+}
+
+// @Summary		Approve Authorization Request
+// @Description	Approve or deny the authorization request of a client for the authenticated user, the user is then sent to the returned redirect uri
+// @Tags			oauth
+// @Accept			json
+// @Produce		json
+// @Param			authorization	body		ApproveAuthorizationRequest	true	"Authorization request"
+// @Success		200				{object}	AuthorizationRedirectResponse
+// @Failure		400				{object}	map[string]string
+// @Failure		403				{object}	map[string]string
+// @Failure		500				{object}	map[string]string
+// @Router			/api/v1/oauth/authorize [post]
+func (h *AuthorizationServerHandler) ApproveAuthorization(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "ApproveAuthorization")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	var req ApproveAuthorizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	client, scopes, ok := h.validateAuthorization(ctx, c, req.AuthorizationRequest)
+	if !ok {
+		return
+	}
+
+	if !req.Approve {
+		c.JSON(http.StatusOK, AuthorizationRedirectResponse{
+			RedirectURI: redirectWith(req.RedirectURI, req.State, url.Values{"error": {"access_denied"}}),
+		})
+		return
+	}
+
+	code, err := randomToken(32)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to generate authorization code: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	_, err = h.accountRepository.CreateAuthorizationCode(ctx, &domain.AuthorizationCode{
+		CodeHash:      h.accountService.HashToken(ctx, code),
+		ClientID:      client.ClientID,
+		AccountID:     accountID,
+		RedirectURI:   req.RedirectURI,
+		Scopes:        scopes,
+		Nonce:         req.Nonce,
+		CodeChallenge: req.CodeChallenge,
+		ExpiresAt:     time.Now().Add(authorizationCodeTTL),
+	})
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to create authorization code: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	consented, err := h.consented(ctx, accountID, client.ClientID, scopes)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to get oauth consent: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	if !consented {
+		err = h.accountRepository.SaveOAuthConsent(ctx, &domain.OAuthConsent{AccountID: accountID, ClientID: client.ClientID, Scopes: scopes})
+		if err != nil {
+			h.logger.WithField("userId", accountID).Errorf("failed to save oauth consent: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
+		err = h.accountRepository.LogAccountActivity(ctx, accountID, domain.ActivityOAuthClientApproved)
+		if err != nil {
+			h.logger.WithField("userId", accountID).Errorf("failed to log activity: %v", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, AuthorizationRedirectResponse{
+		RedirectURI: redirectWith(req.RedirectURI, req.State, url.Values{"code": {code}}),
+	})
+}
+
+// @Summary		Token
+// @Description	Exchange an authorization code, with its PKCE code verifier, or the client credentials for tokens. Clients authenticate with HTTP basic or the client_id and client_secret fields, public clients only send their client_id. Errors follow RFC 6749.
+// @Tags			oauth
+// @Accept			x-www-form-urlencoded
+// @Produce		json
+// @Param			grant_type		formData	string	true	"authorization_code or client_credentials"
+// @Param			code			formData	string	false	"Authorization code"
+// @Param			redirect_uri	formData	string	false	"Redirect URI the code was sent to"
+// @Param			code_verifier	formData	string	false	"PKCE code verifier"
+// @Param			scope			formData	string	false	"Space separated scopes of client credentials"
+// @Param			client_id		formData	string	false	"Client ID"
+// @Param			client_secret	formData	string	false	"Client secret"
+// @Success		200				{object}	OAuthTokenResponse
+// @Failure		400				{object}	map[string]string
+// @Failure		401				{object}	map[string]string
+// @Failure		500				{object}	map[string]string
+// @Router			/api/v1/oauth/token [post]
+func (h *AuthorizationServerHandler) Token(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "Token")
+	defer span.End()
+
+	c.Header("Cache-Control", "no-store")
+
+	client, ok := h.authenticateClient(ctx, c)
+	if !ok {
+		return
+	}
+
+	switch c.PostForm("grant_type") {
+	case domain.GrantTypeAuthorizationCode:
+		h.exchangeAuthorizationCode(ctx, c, client)
+	case domain.GrantTypeClientCredentials:
+		h.exchangeClientCredentials(ctx, c, client)
+	default:
+		oauthError(c, http.StatusBadRequest, "unsupported_grant_type")
+	}
+}
+
+// @Summary		User Info
+// @Description	Return the claims of the user an access token of the authorization server was issued for, the token is sent as a bearer token
+// @Tags			oauth
+// @Produce		json
+// @Success		200	{object}	UserInfoResponse
+// @Failure		401	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/oauth/userinfo [get]
+func (h *AuthorizationServerHandler) UserInfo(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "UserInfo")
+	defer span.End()
+
+	invalidToken := func() {
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		oauthError(c, http.StatusUnauthorized, "invalid_token")
+	}
+
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	claims, err := h.accountService.ParseOAuthAccessToken(ctx, token)
+	if err != nil || !slices.Contains(claims.Scopes, domain.ScopeOpenID) {
+		invalidToken()
+		return
+	}
+
+	// tokens of client credentials have the client as subject and never the openid scope
+	accountID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		invalidToken()
+		return
+	}
+
+	acc, err := h.accountRepository.GetAccountByID(ctx, uint(accountID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			invalidToken()
+			return
+		}
+		h.logger.WithField("userId", accountID).Errorf("failed to get account by id: %v", err)
+		oauthError(c, http.StatusInternalServerError, "server_error")
+		return
+	}
+	if !acceptsOAuthTokens(acc) {
+		invalidToken()
+		return
+	}
+
+	response := UserInfoResponse{Subject: claims.Subject}
+	if slices.Contains(claims.Scopes, domain.ScopeEmail) {
+		response.Email = acc.Email
+		response.EmailVerified = &acc.EmailVerified
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// validateAuthorization checks the request against the registered client and returns the
+// client with the requested scopes, the response is written when it is invalid. The user
+// is never sent to a redirect uri that is not registered.
+func (h *AuthorizationServerHandler) validateAuthorization(ctx context.Context, c *gin.Context, req AuthorizationRequest) (*domain.OAuthClient, []string, bool) {
+	client, err := h.accountRepository.GetOAuthClient(ctx, req.ClientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown client"})
+			return nil, nil, false
+		}
+		h.logger.Errorf("failed to get oauth client: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return nil, nil, false
+	}
+
+	if !client.AllowsRedirectURI(req.RedirectURI) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "redirect_uri is not registered for the client"})
+		return nil, nil, false
+	}
+
+	if req.ResponseType != "code" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "response_type must be code"})
+		return nil, nil, false
+	}
+
+	if !client.AllowsGrant(domain.GrantTypeAuthorizationCode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the client cannot use the authorization code grant"})
+		return nil, nil, false
+	}
+
+	scopes := slices.DeleteFunc(parseScope(req.Scope), func(scope string) bool {
+		return slices.Contains(unsupportedOIDCScopes, scope)
+	})
+	if len(scopes) == 0 || !client.AllowsScopes(scopes) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid scope"})
+		return nil, nil, false
+	}
+
+	// every client uses PKCE, an intercepted code is useless without the verifier
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != "S256" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a S256 code_challenge is required"})
+		return nil, nil, false
+	}
+
+	return client, scopes, true
+}
+
+// consented reports whether the account already approved every scope for the client
+func (h *AuthorizationServerHandler) consented(ctx context.Context, accountID uint, clientID string, scopes []string) (bool, error) {
+	consent, err := h.accountRepository.GetOAuthConsent(ctx, accountID, clientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	for _, scope := range scopes {
+		if !slices.Contains(consent.Scopes, scope) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// authenticateClient returns the client of the token request, confidential clients must
+// present their secret. The response is written when it fails.
+func (h *AuthorizationServerHandler) authenticateClient(ctx context.Context, c *gin.Context) (*domain.OAuthClient, bool) {
+	clientID, secret, basic := c.Request.BasicAuth()
+	if basic {
+		// basic credentials are form encoded before being joined, RFC 6749 section 2.3.1
+		clientID, _ = url.QueryUnescape(clientID)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		clientID = c.PostForm("client_id")
+		secret = c.PostForm("client_secret")
+	}
+
+	invalidClient := func() {
+		if basic {
+			c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+		oauthError(c, http.StatusUnauthorized, "invalid_client")
+	}
+
+	if clientID == "" {
+		invalidClient()
+		return nil, false
+	}
+
+	client, err := h.accountRepository.GetOAuthClient(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			invalidClient()
+			return nil, false
+		}
+		h.logger.Errorf("failed to get oauth client: %v", err)
+		oauthError(c, http.StatusInternalServerError, "server_error")
+		return nil, false
+	}
+
+	if !client.Public {
+		hash := h.accountService.HashToken(ctx, secret)
+		if secret == "" || subtle.ConstantTimeCompare([]byte(hash), []byte(client.SecretHash)) != 1 {
+			invalidClient()
+			return nil, false
+		}
+	}
+
+	return client, true
+}
+
+func (h *AuthorizationServerHandler) exchangeAuthorizationCode(ctx context.Context, c *gin.Context, client *domain.OAuthClient) {
+	if !client.AllowsGrant(domain.GrantTypeAuthorizationCode) {
+		oauthError(c, http.StatusBadRequest, "unauthorized_client")
+		return
+	}
+
+	code, err := h.accountRepository.ConsumeAuthorizationCode(ctx, h.accountService.HashToken(ctx, c.PostForm("code")))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidAuthorizationCode) {
+			oauthError(c, http.StatusBadRequest, "invalid_grant")
+			return
+		}
+		h.logger.Errorf("failed to consume authorization code: %v", err)
+		oauthError(c, http.StatusInternalServerError, "server_error")
+		return
+	}
+
+	// the code is bound to the client, the redirect uri and the PKCE challenge of its request
+	challenge := oauth.CodeChallenge(c.PostForm("code_verifier"))
+	if code.ClientID != client.ClientID || code.RedirectURI != c.PostForm("redirect_uri") ||
+		subtle.ConstantTimeCompare([]byte(challenge), []byte(code.CodeChallenge)) != 1 {
+		oauthError(c, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+
+	acc, err := h.accountRepository.GetAccountByID(ctx, code.AccountID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			oauthError(c, http.StatusBadRequest, "invalid_grant")
+			return
+		}
+		h.logger.WithField("userId", code.AccountID).Errorf("failed to get account by id: %v", err)
+		oauthError(c, http.StatusInternalServerError, "server_error")
+		return
+	}
+	if !acceptsOAuthTokens(acc) {
+		oauthError(c, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+
+	subject := strconv.FormatUint(uint64(acc.ID), 10)
+	accessToken, err := h.accountService.GenerateOAuthAccessToken(ctx, subject, client.ClientID, code.Scopes)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to generate access token: %v", err)
+		oauthError(c, http.StatusInternalServerError, "server_error")
+		return
+	}
+
+	response := OAuthTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(accessTokenTTL().Seconds()),
+		Scope:       strings.Join(code.Scopes, " "),
+	}
+
+	if slices.Contains(code.Scopes, domain.ScopeOpenID) {
+		response.IDToken, err = h.accountService.GenerateIDToken(ctx, acc, client.ClientID, code.Nonce, code.Scopes)
+		if err != nil {
+			h.logger.WithField("userId", acc.ID).Errorf("failed to generate id token: %v", err)
+			oauthError(c, http.StatusInternalServerError, "server_error")
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// exchangeClientCredentials issues a token to the client itself, for the apps it calls
+func (h *AuthorizationServerHandler) exchangeClientCredentials(ctx context.Context, c *gin.Context, client *domain.OAuthClient) {
+	if client.Public || !client.AllowsGrant(domain.GrantTypeClientCredentials) {
+		oauthError(c, http.StatusBadRequest, "unauthorized_client")
+		return
+	}
+
+	// the scopes about an account mean nothing without one
+	scopes := parseScope(c.PostForm("scope"))
+	if len(scopes) == 0 {
+		for _, scope := range client.Scopes {
+			if scope != domain.ScopeOpenID && scope != domain.ScopeEmail {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	if !client.AllowsScopes(scopes) || slices.Contains(scopes, domain.ScopeOpenID) || slices.Contains(scopes, domain.ScopeEmail) {
+		oauthError(c, http.StatusBadRequest, "invalid_scope")
+		return
+	}
+
+	accessToken, err := h.accountService.GenerateOAuthAccessToken(ctx, client.ClientID, client.ClientID, scopes)
+	if err != nil {
+		h.logger.Errorf("failed to generate access token: %v", err)
+		oauthError(c, http.StatusInternalServerError, "server_error")
+		return
+	}
+
+	c.JSON(http.StatusOK, OAuthTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(accessTokenTTL().Seconds()),
+		Scope:       strings.Join(scopes, " "),
+	})
+}
+
+// acceptsOAuthTokens reports whether tokens may be issued for the account and its claims read
+// with them, which is refused while it is disabled or must reset its password
+func acceptsOAuthTokens(acc *domain.Account) bool {
+	return acc.DisabledAt == nil && !acc.PasswordResetRequired
+}
+
+// oauthError writes an error of the token and userinfo endpoints, shaped as RFC 6749 asks
+func oauthError(c *gin.Context, status int, code string) {
+	c.JSON(status, gin.H{"error": code})
+}
+
+// parseScope splits a space separated scope, dropping duplicates
+func parseScope(scope string) []string {
+	scopes := []string{}
+	for _, s := range strings.Fields(scope) {
+		if !slices.Contains(scopes, s) {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
+
+// redirectWith adds the state and params to the query of the registered redirect uri
+func redirectWith(redirectURI string, state string, params url.Values) string {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+
+	query := u.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	if state != "" {
+		query.Set("state", state)
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
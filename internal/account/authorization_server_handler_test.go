@@ -0,0 +1,347 @@
+package account_test
+
+import (
+	"context"
+	"go_starter_api/internal/account"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/oauth"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm"
+)
+
+func TestAuthorizationServerHandler(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	authenticate := func(c *gin.Context) {
+		c.Set(utils.AccountIdContextKey, uint(1))
+		c.Set(utils.AuthClaimsContextKey, &domain.AuthClaims{AccountID: 1, SessionID: 5})
+	}
+
+	client := &domain.OAuthClient{
+		ClientID:     "client",
+		SecretHash:   "hash:secret",
+		Name:         "Wiki",
+		RedirectURIs: []string{"https://wiki.example.com/callback"},
+		GrantTypes:   []string{domain.GrantTypeAuthorizationCode, domain.GrantTypeClientCredentials},
+		Scopes:       []string{domain.ScopeOpenID, domain.ScopeEmail, "wiki:read"},
+	}
+
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	authorization := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {"client"},
+		"redirect_uri":          {"https://wiki.example.com/callback"},
+		"scope":                 {"openid email profile"},
+		"state":                 {"xyz"},
+		"nonce":                 {"n-0S6"},
+		"code_challenge":        {oauth.CodeChallenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+
+	setup := func(t *testing.T) (*domain.MockAccountService, *domain.MockAccountRepository, *HTTPTestHelper) {
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		service.On("HashToken", anyContext, mock.AnythingOfType("string")).Return(func(_ context.Context, token string) string {
+			return "hash:" + token
+		}).Maybe()
+		repository.On("GetOAuthClient", anyContext, "client").Return(client, nil).Maybe()
+		repository.On("GetOAuthClient", anyContext, mock.AnythingOfType("string")).Return(nil, gorm.ErrRecordNotFound).Maybe()
+
+		handler := account.NewAuthorizationServerHandler(logrus.New(), service, repository)
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.GET("/oauth/authorize", authenticate, handler.GetAuthorization)
+		httpHelper.router.POST("/oauth/authorize", authenticate, handler.ApproveAuthorization)
+		httpHelper.router.POST("/oauth/token", handler.Token)
+		httpHelper.router.GET("/oauth/userinfo", handler.UserInfo)
+
+		return service, repository, httpHelper
+	}
+
+	// token posts the form to the token endpoint, authenticated with basic when secret is set
+	token := func(httpHelper *HTTPTestHelper, form url.Values, secret string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/oauth/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if secret != "" {
+			req.SetBasicAuth("client", secret)
+		}
+		w := httptest.NewRecorder()
+		httpHelper.router.ServeHTTP(w, req)
+		return w
+	}
+
+	approval := func(approve bool) account.ApproveAuthorizationRequest {
+		return account.ApproveAuthorizationRequest{
+			AuthorizationRequest: account.AuthorizationRequest{
+				ResponseType:        authorization.Get("response_type"),
+				ClientID:            authorization.Get("client_id"),
+				RedirectURI:         authorization.Get("redirect_uri"),
+				Scope:               authorization.Get("scope"),
+				State:               authorization.Get("state"),
+				Nonce:               authorization.Get("nonce"),
+				CodeChallenge:       authorization.Get("code_challenge"),
+				CodeChallengeMethod: authorization.Get("code_challenge_method"),
+			},
+			Approve: approve,
+		}
+	}
+
+	t.Run("should describe the authorization request for the consent page", func(t *testing.T) {
+		_, repository, httpHelper := setup(t)
+
+		repository.On("GetOAuthConsent", anyContext, uint(1), "client").Return(&domain.OAuthConsent{Scopes: []string{domain.ScopeOpenID}}, nil)
+
+		w := httpHelper.MakeRequest("GET", "/oauth/authorize?"+authorization.Encode(), nil, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response account.AuthorizationDetailsResponse
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.Equal(t, "Wiki", response.Client.Name)
+		assert.Equal(t, []string{domain.ScopeOpenID, domain.ScopeEmail}, response.Scopes)
+		// the email scope was not approved yet
+		assert.True(t, response.ConsentRequired)
+	})
+
+	t.Run("should refuse invalid authorization requests", func(t *testing.T) {
+		_, _, httpHelper := setup(t)
+
+		for name, adjust := range map[string]func(url.Values){
+			"unknown client":     func(q url.Values) { q.Set("client_id", "other") },
+			"other redirect uri": func(q url.Values) { q.Set("redirect_uri", "https://attacker.example.com/callback") },
+			"implicit flow":      func(q url.Values) { q.Set("response_type", "token") },
+			"unregistered scope": func(q url.Values) { q.Set("scope", "openid wiki:write") },
+			"no pkce":            func(q url.Values) { q.Del("code_challenge") },
+			"plain pkce":         func(q url.Values) { q.Set("code_challenge_method", "plain") },
+		} {
+			query := url.Values{}
+			for key, values := range authorization {
+				query[key] = values
+			}
+			adjust(query)
+
+			w := httpHelper.MakeRequest("GET", "/oauth/authorize?"+query.Encode(), nil, nil)
+			assert.Equal(t, http.StatusBadRequest, w.Code, name)
+		}
+	})
+
+	t.Run("should send the code back to the client once approved", func(t *testing.T) {
+		service, repository, httpHelper := setup(t)
+
+		var code string
+		repository.On("CreateAuthorizationCode", anyContext, mock.MatchedBy(func(c *domain.AuthorizationCode) bool {
+			return c.ClientID == "client" && c.AccountID == 1 && c.Nonce == "n-0S6" &&
+				c.CodeChallenge == oauth.CodeChallenge(verifier) && c.RedirectURI == "https://wiki.example.com/callback"
+		})).Return(func(_ context.Context, c *domain.AuthorizationCode) *domain.AuthorizationCode {
+			code = strings.TrimPrefix(c.CodeHash, "hash:")
+			return c
+		}, nil)
+		repository.On("GetOAuthConsent", anyContext, uint(1), "client").Return(nil, gorm.ErrRecordNotFound)
+		repository.On("SaveOAuthConsent", anyContext, mock.MatchedBy(func(c *domain.OAuthConsent) bool {
+			return c.AccountID == 1 && c.ClientID == "client" && len(c.Scopes) == 2
+		})).Return(nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityOAuthClientApproved).Return(nil)
+
+		w := httpHelper.MakeRequest("POST", "/oauth/authorize", approval(true), nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response account.AuthorizationRedirectResponse
+		httpHelper.AssertJSONResponse(t, w, &response)
+		redirect, err := url.Parse(response.RedirectURI)
+		require.NoError(t, err)
+		assert.Equal(t, "wiki.example.com", redirect.Host)
+		assert.Equal(t, code, redirect.Query().Get("code"))
+		assert.Equal(t, "xyz", redirect.Query().Get("state"))
+		assert.NotEmpty(t, code)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("should tell the client the request was denied", func(t *testing.T) {
+		_, _, httpHelper := setup(t)
+
+		w := httpHelper.MakeRequest("POST", "/oauth/authorize", approval(false), nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response account.AuthorizationRedirectResponse
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.Equal(t, "https://wiki.example.com/callback?error=access_denied&state=xyz", response.RedirectURI)
+	})
+
+	t.Run("should exchange the code for tokens", func(t *testing.T) {
+		service, repository, httpHelper := setup(t)
+
+		acc := &domain.Account{ID: 1, Email: "test@example.com"}
+		scopes := []string{domain.ScopeOpenID, domain.ScopeEmail}
+		repository.On("ConsumeAuthorizationCode", anyContext, "hash:code").Return(&domain.AuthorizationCode{
+			ClientID:      "client",
+			AccountID:     1,
+			RedirectURI:   "https://wiki.example.com/callback",
+			Scopes:        scopes,
+			Nonce:         "n-0S6",
+			CodeChallenge: oauth.CodeChallenge(verifier),
+		}, nil)
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		service.On("GenerateOAuthAccessToken", anyContext, "1", "client", scopes).Return("access_token", nil)
+		service.On("GenerateIDToken", anyContext, acc, "client", "n-0S6", scopes).Return("id_token", nil)
+
+		w := token(httpHelper, url.Values{
+			"grant_type":    {domain.GrantTypeAuthorizationCode},
+			"code":          {"code"},
+			"redirect_uri":  {"https://wiki.example.com/callback"},
+			"code_verifier": {verifier},
+		}, "secret")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+		var response account.OAuthTokenResponse
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.Equal(t, "access_token", response.AccessToken)
+		assert.Equal(t, "id_token", response.IDToken)
+		assert.Equal(t, "Bearer", response.TokenType)
+		assert.Equal(t, "openid email", response.Scope)
+	})
+
+	t.Run("should refuse a code of a disabled account or one that must reset its password", func(t *testing.T) {
+		disabledAt := time.Now()
+		for name, acc := range map[string]*domain.Account{
+			"disabled":       {ID: 1, Email: "test@example.com", DisabledAt: &disabledAt},
+			"password reset": {ID: 1, Email: "test@example.com", PasswordResetRequired: true},
+		} {
+			_, repository, httpHelper := setup(t)
+
+			repository.On("ConsumeAuthorizationCode", anyContext, "hash:code").Return(&domain.AuthorizationCode{
+				ClientID:      "client",
+				AccountID:     1,
+				RedirectURI:   "https://wiki.example.com/callback",
+				Scopes:        []string{domain.ScopeOpenID},
+				CodeChallenge: oauth.CodeChallenge(verifier),
+			}, nil)
+			repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+
+			w := token(httpHelper, url.Values{
+				"grant_type":    {domain.GrantTypeAuthorizationCode},
+				"code":          {"code"},
+				"redirect_uri":  {"https://wiki.example.com/callback"},
+				"code_verifier": {verifier},
+			}, "secret")
+			assert.Equal(t, http.StatusBadRequest, w.Code, name)
+			assert.JSONEq(t, `{"error":"invalid_grant"}`, w.Body.String(), name)
+		}
+	})
+
+	t.Run("should refuse a code without its verifier", func(t *testing.T) {
+		_, repository, httpHelper := setup(t)
+
+		repository.On("ConsumeAuthorizationCode", anyContext, "hash:code").Return(&domain.AuthorizationCode{
+			ClientID:      "client",
+			AccountID:     1,
+			RedirectURI:   "https://wiki.example.com/callback",
+			CodeChallenge: oauth.CodeChallenge(verifier),
+		}, nil)
+
+		w := token(httpHelper, url.Values{
+			"grant_type":    {domain.GrantTypeAuthorizationCode},
+			"code":          {"code"},
+			"redirect_uri":  {"https://wiki.example.com/callback"},
+			"code_verifier": {"other"},
+		}, "secret")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"invalid_grant"}`, w.Body.String())
+	})
+
+	t.Run("should refuse a client with the wrong secret", func(t *testing.T) {
+		_, _, httpHelper := setup(t)
+
+		w := token(httpHelper, url.Values{"grant_type": {domain.GrantTypeClientCredentials}}, "wrong")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.JSONEq(t, `{"error":"invalid_client"}`, w.Body.String())
+		assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("should issue client credentials tokens", func(t *testing.T) {
+		service, _, httpHelper := setup(t)
+
+		service.On("GenerateOAuthAccessToken", anyContext, "client", "client", []string{"wiki:read"}).Return("access_token", nil)
+
+		w := token(httpHelper, url.Values{"grant_type": {domain.GrantTypeClientCredentials}}, "secret")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response account.OAuthTokenResponse
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.Equal(t, "access_token", response.AccessToken)
+		assert.Equal(t, "wiki:read", response.Scope)
+		assert.Empty(t, response.IDToken)
+
+		// the scopes about an account need one
+		w = token(httpHelper, url.Values{"grant_type": {domain.GrantTypeClientCredentials}, "scope": {"openid"}}, "secret")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"invalid_scope"}`, w.Body.String())
+	})
+
+	t.Run("should return the claims of the user", func(t *testing.T) {
+		service, repository, httpHelper := setup(t)
+
+		service.On("ParseOAuthAccessToken", anyContext, "access_token").Return(&domain.OAuthAccessClaims{
+			Subject:  "1",
+			ClientID: "client",
+			Scopes:   []string{domain.ScopeOpenID, domain.ScopeEmail},
+		}, nil)
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(&domain.Account{ID: 1, Email: "test@example.com", EmailVerified: true}, nil)
+
+		w := httpHelper.MakeRequest("GET", "/oauth/userinfo", nil, map[string]string{"Authorization": "Bearer access_token"})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"sub":"1","email":"test@example.com","email_verified":true}`, w.Body.String())
+	})
+
+	t.Run("should refuse the tokens of a disabled account or one that must reset its password at userinfo", func(t *testing.T) {
+		disabledAt := time.Now()
+		for name, acc := range map[string]*domain.Account{
+			"disabled":       {ID: 1, Email: "test@example.com", DisabledAt: &disabledAt},
+			"password reset": {ID: 1, Email: "test@example.com", PasswordResetRequired: true},
+		} {
+			service, repository, httpHelper := setup(t)
+
+			service.On("ParseOAuthAccessToken", anyContext, "access_token").Return(&domain.OAuthAccessClaims{
+				Subject:  "1",
+				ClientID: "client",
+				Scopes:   []string{domain.ScopeOpenID, domain.ScopeEmail},
+			}, nil)
+			repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+
+			w := httpHelper.MakeRequest("GET", "/oauth/userinfo", nil, map[string]string{"Authorization": "Bearer access_token"})
+			assert.Equal(t, http.StatusUnauthorized, w.Code, name)
+			assert.Equal(t, `Bearer error="invalid_token"`, w.Header().Get("WWW-Authenticate"), name)
+		}
+	})
+
+	t.Run("should refuse tokens of client credentials at userinfo", func(t *testing.T) {
+		service, _, httpHelper := setup(t)
+
+		service.On("ParseOAuthAccessToken", anyContext, "access_token").Return(&domain.OAuthAccessClaims{
+			Subject:  "client",
+			ClientID: "client",
+			Scopes:   []string{"wiki:read"},
+		}, nil)
+
+		w := httpHelper.MakeRequest("GET", "/oauth/userinfo", nil, map[string]string{"Authorization": "Bearer access_token"})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, `Bearer error="invalid_token"`, w.Header().Get("WWW-Authenticate"))
+	})
+}
@@ -0,0 +1,487 @@
+package account
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/storage"
+	"go_starter_api/pkg/utils"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultAvatarMaxSize = 5 << 20
+	// side of the square thumbnail stored as the avatar
+	avatarThumbnailSize = 256
+	// larger images are refused before being decoded, a small file can decode to a huge image
+	maxAvatarPixels = 4096 * 4096
+
+	avatarKeyPrefix       = "avatars/"
+	avatarUploadKeyPrefix = "uploads/avatars/"
+
+	avatarUploadURLTTL = 15 * time.Minute
+	// the redirect to an avatar is cached for half the time its download url lives
+	avatarDownloadURLTTL = time.Hour
+)
+
+// the formats decoded by the standard library, the avatar is always stored as png
+var avatarContentTypes = []string{"image/jpeg", "image/png", "image/gif"}
+
+var errInvalidAvatar = errors.New("avatar must be a jpeg, png or gif image")
+
+// AvatarHandler uploads the avatars of the accounts to the storage as resized thumbnails,
+// either sent to the api or uploaded beforehand with a presigned url
+type AvatarHandler struct {
+	logger *logrus.Logger
+	tracer trace.Tracer
+
+	accountHandler *AccountHandler
+	storage        storage.Storage
+}
+
+func NewAvatarHandler(
+	logger *logrus.Logger,
+	accountHandler *AccountHandler,
+	storage storage.Storage,
+) *AvatarHandler {
+	tracer := otel.Tracer("avatarHandler")
+	return &AvatarHandler{
+		logger:         logger,
+		tracer:         tracer,
+		accountHandler: accountHandler,
+		storage:        storage,
+	}
+}
+
+type CreateAvatarUploadURLRequest struct {
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+type CreateAvatarUploadURLResponse struct {
+	UploadURL string `json:"upload_url"`
+	// UploadKey is given to the avatar upload once the image is uploaded
+	UploadKey string `json:"upload_key"`
+	Method    string `json:"method"`
+	// Headers are sent with the upload, the url is only valid for them
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// UploadAvatarRequest completes an upload made with a presigned url
+type UploadAvatarRequest struct {
+	UploadKey string `json:"upload_key"`
+}
+
+// @Summary		Create Avatar Upload URL
+// @Description	Create a presigned url the avatar image is uploaded to directly, the upload key is then given to the avatar upload
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			account	body		CreateAvatarUploadURLRequest	true	"Image to upload"
+// @Success		200		{object}	CreateAvatarUploadURLResponse
+// @Failure		400		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/avatar/upload-url [post]
+func (h *AvatarHandler) CreateAvatarUploadURL(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "CreateAvatarUploadURL")
+	defer span.End()
+
+	var req CreateAvatarUploadURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if !slices.Contains(avatarContentTypes, req.ContentType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidAvatar.Error()})
+		return
+	}
+
+	if req.Size <= 0 || req.Size > avatarMaxSize() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("avatar must be at most %d bytes", avatarMaxSize())})
+		return
+	}
+
+	name, err := randomToken(16)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to generate upload key: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	key := avatarUploadKeyPrefix + strconv.FormatUint(uint64(accountID), 10) + "/" + name
+
+	uploadURL, err := h.storage.PresignPut(ctx, key, req.ContentType, req.Size, avatarUploadURLTTL)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to presign avatar upload: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, CreateAvatarUploadURLResponse{
+		UploadURL: uploadURL,
+		UploadKey: key,
+		Method:    http.MethodPut,
+		Headers:   map[string]string{"Content-Type": req.ContentType},
+		ExpiresAt: time.Now().Add(avatarUploadURLTTL),
+	})
+}
+
+// @Summary		Upload Avatar
+// @Description	Set the avatar of the authenticated account, sent as the avatar field of a multipart form or uploaded beforehand with a presigned url. It is stored as a square thumbnail and served from avatar_url.
+// @Tags			account
+// @Accept			multipart/form-data
+// @Accept			json
+// @Produce		json
+// @Param			avatar	formData	file				false	"Image"
+// @Param			account	body		UploadAvatarRequest	false	"Presigned upload"
+// @Success		200		{object}	GetProfileResponse
+// @Failure		400		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/avatar [post]
+func (h *AvatarHandler) UploadAvatar(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "UploadAvatar")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	var data []byte
+	var uploadKey string
+	if c.ContentType() == "multipart/form-data" {
+		// room for the rest of the form around the image
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, avatarMaxSize()+64<<10)
+		file, err := c.FormFile("avatar")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "avatar file is required"})
+			return
+		}
+		if file.Size > avatarMaxSize() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("avatar must be at most %d bytes", avatarMaxSize())})
+			return
+		}
+		content, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "avatar file is required"})
+			return
+		}
+		defer content.Close()
+		data, err = readAvatar(content)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	} else {
+		var req UploadAvatarRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// an account only completes its own uploads
+		prefix := avatarUploadKeyPrefix + strconv.FormatUint(uint64(accountID), 10) + "/"
+		if !strings.HasPrefix(req.UploadKey, prefix) || !storage.ValidKey(req.UploadKey) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid upload key"})
+			return
+		}
+
+		content, err := h.storage.Get(ctx, req.UploadKey)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid upload key"})
+				return
+			}
+			h.logger.WithField("userId", accountID).Errorf("failed to get avatar upload: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		defer content.Close()
+		data, err = readAvatar(content)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		uploadKey = req.UploadKey
+	}
+
+	thumbnail, err := avatarThumbnailPNG(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	acc, err := h.saveAvatar(ctx, accountID, thumbnail)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to save avatar: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// the upload is only the source of the thumbnail
+	if uploadKey != "" {
+		err = h.storage.Delete(ctx, uploadKey)
+		if err != nil {
+			h.logger.WithField("userId", accountID).Errorf("failed to delete avatar upload: %v", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, newGetProfileResponse(acc))
+}
+
+// saveAvatar stores the thumbnail as the avatar of the account and deletes the one it replaces
+func (h *AvatarHandler) saveAvatar(ctx context.Context, accountID uint, thumbnail []byte) (*domain.Account, error) {
+	accountRepository := h.accountHandler.accountRepository
+
+	acc, err := accountRepository.GetAccountByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	serverUrl := viper.GetString("SERVER_URL")
+	if serverUrl == "" {
+		return nil, domain.ErrServerURLNotSet
+	}
+
+	// a new key per avatar, so caches never serve the replaced one
+	name, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	key := avatarKeyPrefix + strconv.FormatUint(uint64(accountID), 10) + "/" + name + ".png"
+	avatarURL := serverUrl + "/api/v1/" + key
+
+	err = h.storage.Put(ctx, key, bytes.NewReader(thumbnail), int64(len(thumbnail)), "image/png")
+	if err != nil {
+		return nil, err
+	}
+
+	err = accountRepository.SetAccountAvatar(ctx, accountID, key, avatarURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if acc.AvatarKey != "" {
+		err = h.storage.Delete(ctx, acc.AvatarKey)
+		if err != nil {
+			h.logger.WithField("userId", accountID).Errorf("failed to delete replaced avatar: %v", err)
+		}
+	}
+
+	err = accountRepository.LogAccountActivityDetails(ctx, accountID, domain.ActivityUpdate, map[string]any{
+		"fields": []string{"avatar_url"},
+	})
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to log activity: %v", err)
+	}
+
+	acc.AvatarKey = key
+	acc.AvatarURL = avatarURL
+	return acc, nil
+}
+
+// @Summary		Delete Avatar
+// @Description	Remove the avatar of the authenticated account
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Success		200		{object}	GetProfileResponse
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/avatar [delete]
+func (h *AvatarHandler) DeleteAvatar(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "DeleteAvatar")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	accountRepository := h.accountHandler.accountRepository
+
+	acc, err := accountRepository.GetAccountByID(ctx, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to get account by id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if acc.AvatarKey == "" && acc.AvatarURL == "" {
+		c.JSON(http.StatusOK, newGetProfileResponse(acc))
+		return
+	}
+
+	err = accountRepository.SetAccountAvatar(ctx, accountID, "", "")
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to delete avatar: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if acc.AvatarKey != "" {
+		err = h.storage.Delete(ctx, acc.AvatarKey)
+		if err != nil {
+			h.logger.WithField("userId", accountID).Errorf("failed to delete avatar object: %v", err)
+		}
+	}
+
+	err = accountRepository.LogAccountActivityDetails(ctx, accountID, domain.ActivityUpdate, map[string]any{
+		"fields": []string{"avatar_url"},
+	})
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to log activity: %v", err)
+	}
+
+	acc.AvatarKey = ""
+	acc.AvatarURL = ""
+	c.JSON(http.StatusOK, newGetProfileResponse(acc))
+}
+
+// @Summary		Get Avatar
+// @Description	Redirect to a short lived download url of an uploaded avatar
+// @Tags			account
+// @Param			path	path	string	true	"Avatar path"
+// @Success		302
+// @Failure		404		{object}	map[string]string
+// @Router			/api/v1/avatars/{path} [get]
+func (h *AvatarHandler) GetAvatar(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "GetAvatar")
+	defer span.End()
+
+	// only the thumbnails are served, never the uploads they were made from
+	key := avatarKeyPrefix + strings.TrimPrefix(c.Param("path"), "/")
+	if !storage.ValidKey(key) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "avatar not found"})
+		return
+	}
+
+	downloadURL, err := h.storage.PresignGet(ctx, key, avatarDownloadURLTTL)
+	if err != nil {
+		h.logger.Errorf("failed to presign avatar download: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(int(avatarDownloadURLTTL/2/time.Second)))
+	c.Redirect(http.StatusFound, downloadURL)
+}
+
+// readAvatar reads the image, refusing it once it is larger than AVATAR_MAX_SIZE
+func readAvatar(r io.Reader) ([]byte, error) {
+	maxSize := avatarMaxSize()
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("avatar must be at most %d bytes", maxSize)
+	}
+	return data, nil
+}
+
+// avatarThumbnailPNG decodes the image after checking its content, whatever type it was
+// declared with, and returns its thumbnail encoded as png
+func avatarThumbnailPNG(data []byte) ([]byte, error) {
+	if !slices.Contains(avatarContentTypes, http.DetectContentType(data)) {
+		return nil, errInvalidAvatar
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errInvalidAvatar
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxAvatarPixels {
+		return nil, fmt.Errorf("avatar must be at most %d pixels", maxAvatarPixels)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errInvalidAvatar
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, avatarThumbnail(img, avatarThumbnailSize)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// avatarThumbnail crops the center square of the image and scales it to size x size, each
+// pixel of the thumbnail averages the pixels of the image it covers
+func avatarThumbnail(img image.Image, size int) *image.RGBA {
+	bounds := img.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	x0 := bounds.Min.X + (bounds.Dx()-side)/2
+	y0 := bounds.Min.Y + (bounds.Dy()-side)/2
+
+	thumbnail := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		sy0 := y0 + y*side/size
+		sy1 := max(y0+(y+1)*side/size, sy0+1)
+		for x := 0; x < size; x++ {
+			sx0 := x0 + x*side/size
+			sx1 := max(x0+(x+1)*side/size, sx0+1)
+
+			var r, g, b, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					b += uint64(pb)
+					a += uint64(pa)
+					n++
+				}
+			}
+			thumbnail.SetRGBA64(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(b / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+	return thumbnail
+}
+
+func avatarMaxSize() int64 {
+	size := viper.GetInt64("AVATAR_MAX_SIZE")
+	if size <= 0 {
+		return defaultAvatarMaxSize
+	}
+	return size
+}
@@ -0,0 +1,265 @@
+package account_test
+
+import (
+	"bytes"
+	"context"
+	"go_starter_api/internal/account"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/storage"
+	"go_starter_api/pkg/utils"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestAvatarHandler(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	viper.Set("SERVER_URL", "http://localhost:8080")
+	defer viper.Reset()
+
+	authenticate := func(c *gin.Context) {
+		c.Set(utils.AccountIdContextKey, uint(1))
+	}
+
+	// setup returns the handler storing to the local disk, its presigned urls are served by a test server
+	setup := func(t *testing.T, repository *domain.MockAccountRepository) (*account.AvatarHandler, *storage.FileSystem) {
+		mux := http.NewServeMux()
+		server := httptest.NewServer(mux)
+		t.Cleanup(server.Close)
+
+		store, err := storage.NewFileSystem(storage.FileSystemConfig{Dir: t.TempDir(), URL: server.URL + "/storage", Secret: "secret"})
+		require.NoError(t, err)
+		mux.Handle("/storage/", http.StripPrefix("/storage", store))
+
+		accountHandler := account.NewAccountHandler(logrus.New(), domain.NewMockAccountService(t), repository, account.NewInMemoryTokenRevocationRepository())
+		return account.NewAvatarHandler(logrus.New(), accountHandler, store), store
+	}
+
+	// landscape returns a png wider than high, red on the left third, blue on the middle and green on the right
+	landscape := func() []byte {
+		img := image.NewRGBA(image.Rect(0, 0, 600, 200))
+		for y := 0; y < 200; y++ {
+			for x := 0; x < 600; x++ {
+				c := color.RGBA{R: 255, A: 255}
+				if x >= 200 && x < 400 {
+					c = color.RGBA{B: 255, A: 255}
+				} else if x >= 400 {
+					c = color.RGBA{G: 255, A: 255}
+				}
+				img.Set(x, y, c)
+			}
+		}
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, img))
+		return buf.Bytes()
+	}
+
+	multipartBody := func(content []byte) (*bytes.Buffer, string) {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		part, err := writer.CreateFormFile("avatar", "avatar.png")
+		require.NoError(t, err)
+		part.Write(content)
+		writer.Close()
+		return &body, writer.FormDataContentType()
+	}
+
+	// storedAvatar decodes the stored thumbnail
+	storedAvatar := func(t *testing.T, store *storage.FileSystem, key string) image.Image {
+		body, err := store.Get(context.Background(), key)
+		require.NoError(t, err)
+		defer body.Close()
+		img, err := png.Decode(body)
+		require.NoError(t, err)
+		return img
+	}
+
+	avatarKey := mock.MatchedBy(func(key string) bool { return strings.HasPrefix(key, "avatars/1/") && strings.HasSuffix(key, ".png") })
+	avatarURL := mock.MatchedBy(func(url string) bool { return strings.HasPrefix(url, "http://localhost:8080/api/v1/avatars/1/") })
+
+	t.Run("should store the center square of an uploaded image as a thumbnail", func(t *testing.T) {
+		repository := domain.NewMockAccountRepository(t)
+		handler, store := setup(t, repository)
+
+		require.NoError(t, store.Put(context.Background(), "avatars/1/old.png", strings.NewReader("old"), 3, "image/png"))
+
+		var key string
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(&domain.Account{ID: 1, AvatarKey: "avatars/1/old.png"}, nil)
+		repository.On("SetAccountAvatar", anyContext, uint(1), avatarKey, avatarURL).
+			Run(func(args mock.Arguments) { key = args.String(2) }).
+			Return(nil)
+		repository.On("LogAccountActivityDetails", anyContext, uint(1), domain.ActivityUpdate, map[string]any{"fields": []string{"avatar_url"}}).Return(nil)
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.POST("/account/avatar", authenticate, handler.UploadAvatar)
+
+		body, contentType := multipartBody(landscape())
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/account/avatar", body)
+		req.Header.Set("Content-Type", contentType)
+		httpHelper.router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response account.GetProfileResponse
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.Equal(t, "http://localhost:8080/api/v1/"+key, response.AvatarURL)
+
+		thumbnail := storedAvatar(t, store, key)
+		assert.Equal(t, image.Rect(0, 0, 256, 256), thumbnail.Bounds())
+		r, g, b, _ := thumbnail.At(128, 128).RGBA()
+		assert.Equal(t, []uint32{0, 0, 0xffff}, []uint32{r, g, b})
+
+		_, err := store.Get(context.Background(), "avatars/1/old.png")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("should complete an upload made with a presigned url", func(t *testing.T) {
+		repository := domain.NewMockAccountRepository(t)
+		handler, store := setup(t, repository)
+
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(&domain.Account{ID: 1}, nil)
+		repository.On("SetAccountAvatar", anyContext, uint(1), avatarKey, avatarURL).Return(nil)
+		repository.On("LogAccountActivityDetails", anyContext, uint(1), domain.ActivityUpdate, mock.Anything).Return(nil)
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.POST("/account/avatar/upload-url", authenticate, handler.CreateAvatarUploadURL)
+		httpHelper.router.POST("/account/avatar", authenticate, handler.UploadAvatar)
+
+		content := landscape()
+		w := httpHelper.MakeRequest("POST", "/account/avatar/upload-url", account.CreateAvatarUploadURLRequest{
+			ContentType: "image/png",
+			Size:        int64(len(content)),
+		}, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		var upload account.CreateAvatarUploadURLResponse
+		httpHelper.AssertJSONResponse(t, w, &upload)
+
+		req, _ := http.NewRequest(upload.Method, upload.UploadURL, bytes.NewReader(content))
+		req.Header.Set("Content-Type", upload.Headers["Content-Type"])
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		w = httpHelper.MakeRequest("POST", "/account/avatar", account.UploadAvatarRequest{UploadKey: upload.UploadKey}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		_, err = store.Get(context.Background(), upload.UploadKey)
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("should refuse to presign an upload of another type or size", func(t *testing.T) {
+		handler, _ := setup(t, domain.NewMockAccountRepository(t))
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.POST("/account/avatar/upload-url", authenticate, handler.CreateAvatarUploadURL)
+
+		for request, expected := range map[account.CreateAvatarUploadURLRequest]string{
+			{ContentType: "image/svg+xml", Size: 100}: "avatar must be a jpeg, png or gif image",
+			{ContentType: "image/png", Size: 6 << 20}: "avatar must be at most 5242880 bytes",
+			{ContentType: "image/png", Size: 0}:       "avatar must be at most 5242880 bytes",
+		} {
+			w := httpHelper.MakeRequest("POST", "/account/avatar/upload-url", request, nil)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.JSONEq(t, `{"error":"`+expected+`"}`, w.Body.String())
+		}
+	})
+
+	t.Run("should refuse a file that is not an image whatever its declared type", func(t *testing.T) {
+		handler, _ := setup(t, domain.NewMockAccountRepository(t))
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.POST("/account/avatar", authenticate, handler.UploadAvatar)
+
+		body, contentType := multipartBody([]byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"))
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/account/avatar", body)
+		req.Header.Set("Content-Type", contentType)
+		httpHelper.router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"avatar must be a jpeg, png or gif image"}`, w.Body.String())
+	})
+
+	t.Run("should refuse the upload of another account", func(t *testing.T) {
+		handler, store := setup(t, domain.NewMockAccountRepository(t))
+
+		content := landscape()
+		require.NoError(t, store.Put(context.Background(), "uploads/avatars/2/x", bytes.NewReader(content), int64(len(content)), "image/png"))
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.POST("/account/avatar", authenticate, handler.UploadAvatar)
+
+		for _, key := range []string{"uploads/avatars/2/x", "uploads/avatars/1/../2/x"} {
+			w := httpHelper.MakeRequest("POST", "/account/avatar", account.UploadAvatarRequest{UploadKey: key}, nil)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.JSONEq(t, `{"error":"invalid upload key"}`, w.Body.String())
+		}
+	})
+
+	t.Run("should delete the avatar", func(t *testing.T) {
+		repository := domain.NewMockAccountRepository(t)
+		handler, store := setup(t, repository)
+
+		require.NoError(t, store.Put(context.Background(), "avatars/1/a.png", strings.NewReader("image"), 5, "image/png"))
+
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(&domain.Account{
+			ID:             1,
+			AvatarKey:      "avatars/1/a.png",
+			AccountProfile: domain.AccountProfile{AvatarURL: "http://localhost:8080/api/v1/avatars/1/a.png"},
+		}, nil)
+		repository.On("SetAccountAvatar", anyContext, uint(1), "", "").Return(nil)
+		repository.On("LogAccountActivityDetails", anyContext, uint(1), domain.ActivityUpdate, mock.Anything).Return(nil)
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.DELETE("/account/avatar", authenticate, handler.DeleteAvatar)
+
+		w := httpHelper.MakeRequest("DELETE", "/account/avatar", nil, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		_, err := store.Get(context.Background(), "avatars/1/a.png")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("should redirect to a download url of the avatar", func(t *testing.T) {
+		handler, store := setup(t, domain.NewMockAccountRepository(t))
+
+		require.NoError(t, store.Put(context.Background(), "avatars/1/a.png", strings.NewReader("image"), 5, "image/png"))
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("GET", "/avatars/*path", handler.GetAvatar)
+
+		w := httpHelper.MakeRequest("GET", "/avatars/1/a.png", nil, nil)
+
+		assert.Equal(t, http.StatusFound, w.Code)
+		resp, err := http.Get(w.Header().Get("Location"))
+		require.NoError(t, err)
+		content, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, "image", string(content))
+
+		w = httpHelper.MakeRequest("GET", "/avatars/../uploads/avatars/1/x", nil, nil)
+		assert.NotEqual(t, http.StatusFound, w.Code)
+	})
+}
@@ -0,0 +1,201 @@
+package account
+
+import (
+	"errors"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+type DeleteAccountResponse struct {
+	Message string    `json:"message"`
+	PurgeAt time.Time `json:"purge_at"`
+}
+
+// @Summary		Delete Account
+// @Description	Delete the authenticated account. It is deactivated and logged out everywhere at once, then purged with its data after the grace period unless restored with the link sent by email. The organizations it is the last owner of then pass to another member, or are deleted when it is their only member.
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			account	body		DeleteAccountRequest	true	"Current password"
+// @Success		200		{object}	DeleteAccountResponse
+// @Failure		400		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/delete [post]
+func (h *AccountHandler) DeleteAccount(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "DeleteAccount")
+	defer span.End()
+
+	var req DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	acc, err := h.accountRepository.GetAccountByID(ctx, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to get account by id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// accounts created by a social login have no password to confirm the deletion with
+	if acc.Password == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "set a password before deleting the account"})
+		return
+	}
+
+	ok, err := h.accountService.ComparePassword(ctx, req.Password, acc.Password)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to compare password: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if !ok {
+		h.logger.WithField("userId", accountID).Errorf("invalid password")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid password"})
+		return
+	}
+
+	// the restore link expires with the grace period, so the purge is scheduled at its expiry
+	token, record, err := h.accountService.GenerateOneTimeToken(ctx, acc, domain.TokenPurposeAccountRestore)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to generate token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+		return
+	}
+
+	_, err = h.accountRepository.CreateOneTimeToken(ctx, record)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to store account restore token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+		return
+	}
+
+	err = h.tokenRevocationRepository.RevokeAllTokens(ctx, accountID, time.Now())
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to revoke tokens: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	err = h.accountRepository.RevokeAllSessions(ctx, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to revoke sessions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	err = h.accountRepository.ScheduleAccountDeletion(ctx, accountID, record.ExpiresAt)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to delete account: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// the account is already deleted, a lost email must not fail the request
+	err = h.accountService.SendAccountDeletedEmail(ctx, acc.Email, record.ExpiresAt, token)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to send account deleted email: %v", err)
+	}
+
+	err = h.accountRepository.LogAccountActivity(ctx, accountID, domain.ActivityDelete)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to log activity: %v", err)
+	}
+
+	c.JSON(http.StatusOK, DeleteAccountResponse{
+		Message: "account deleted",
+		PurgeAt: record.ExpiresAt,
+	})
+}
+
+type RestoreAccountRequest struct {
+	Token string `json:"token"`
+}
+
+// @Summary		Restore Account
+// @Description	Restore a deleted account with the token sent by email, before it is purged. The account then logs in again.
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			account	body		RestoreAccountRequest	true	"Token"
+// @Success		200		{object}	map[string]string
+// @Failure		400		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/restore [post]
+func (h *AccountHandler) RestoreAccount(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "RestoreAccount")
+	defer span.End()
+
+	var req RestoreAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+		return
+	}
+
+	record, err := h.accountRepository.ConsumeOneTimeToken(ctx, domain.TokenPurposeAccountRestore, h.accountService.HashToken(ctx, req.Token))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidOneTimeToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+			return
+		}
+		h.logger.Errorf("failed to consume account restore token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	acc, err := h.accountRepository.GetAccountByIDWithDeleted(ctx, record.AccountID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+			return
+		}
+		h.logger.WithField("userId", record.AccountID).Errorf("failed to get account by id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// an account deleted by an administrator has no purge scheduled, only they can restore it
+	if !acc.DeletedAt.Valid || acc.PurgeAt == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "account is not deleted"})
+		return
+	}
+
+	err = h.accountRepository.RestoreAccount(ctx, acc.ID)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to restore account: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	err = h.accountRepository.LogAccountActivity(ctx, acc.ID, domain.ActivityAccountRestored)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to log activity: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "account restored"})
+}
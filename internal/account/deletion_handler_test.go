@@ -0,0 +1,166 @@
+package account_test
+
+import (
+	"context"
+	"go_starter_api/internal/account"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm"
+)
+
+func TestAccountHandler_DeleteAccount(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	authenticate := func(c *gin.Context) {
+		c.Set(utils.AccountIdContextKey, uint(1))
+	}
+
+	t.Run("should deactivate the account and schedule its purge", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+		revocations := account.NewInMemoryTokenRevocationRepository()
+
+		acc := &domain.Account{ID: 1, Email: "test@example.com", Password: "hashed"}
+		purgeAt := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Second)
+		record := &domain.OneTimeToken{AccountID: 1, Purpose: domain.TokenPurposeAccountRestore, TokenHash: "restore_hash", ExpiresAt: purgeAt}
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		service.On("ComparePassword", anyContext, "password", "hashed").Return(true, nil)
+		service.On("GenerateOneTimeToken", anyContext, acc, domain.TokenPurposeAccountRestore).Return("restore_token", record, nil)
+		repository.On("CreateOneTimeToken", anyContext, record).Return(record, nil)
+		repository.On("RevokeAllSessions", anyContext, uint(1)).Return(nil)
+		repository.On("ScheduleAccountDeletion", anyContext, uint(1), purgeAt).Return(nil)
+		service.On("SendAccountDeletedEmail", anyContext, "test@example.com", purgeAt, "restore_token").Return(nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityDelete).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, revocations)
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.POST("/account/delete", authenticate, handler.DeleteAccount)
+
+		w := httpHelper.MakeRequest("POST", "/account/delete", account.DeleteAccountRequest{Password: "password"}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response account.DeleteAccountResponse
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.True(t, purgeAt.Equal(response.PurgeAt))
+
+		claims := &domain.AuthClaims{AccountID: 1, TokenID: "older", IssuedAt: time.Now().Add(-time.Minute)}
+		revoked, err := revocations.IsTokenRevoked(context.Background(), claims)
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("should refuse an invalid password", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(&domain.Account{ID: 1, Password: "hashed"}, nil)
+		service.On("ComparePassword", anyContext, "wrong", "hashed").Return(false, nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.POST("/account/delete", authenticate, handler.DeleteAccount)
+
+		w := httpHelper.MakeRequest("POST", "/account/delete", account.DeleteAccountRequest{Password: "wrong"}, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"invalid password"}`, w.Body.String())
+		repository.AssertNotCalled(t, "ScheduleAccountDeletion", anyContext, mock.Anything, mock.Anything)
+	})
+}
+
+func TestAccountHandler_RestoreAccount(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	deleted := func(purgeAt *time.Time) *domain.Account {
+		return &domain.Account{
+			ID:        1,
+			DeletedAt: gorm.DeletedAt{Time: time.Now().Add(-time.Hour), Valid: true},
+			PurgeAt:   purgeAt,
+		}
+	}
+
+	t.Run("should restore an account deleted by its owner", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		purgeAt := time.Now().Add(24 * time.Hour)
+		service.On("HashToken", anyContext, "restore_token").Return("restore_hash")
+		repository.On("ConsumeOneTimeToken", anyContext, domain.TokenPurposeAccountRestore, "restore_hash").
+			Return(&domain.OneTimeToken{AccountID: 1, Purpose: domain.TokenPurposeAccountRestore}, nil)
+		repository.On("GetAccountByIDWithDeleted", anyContext, uint(1)).Return(deleted(&purgeAt), nil)
+		repository.On("RestoreAccount", anyContext, uint(1)).Return(nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityAccountRestored).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/restore", handler.RestoreAccount)
+
+		w := httpHelper.MakeRequest("POST", "/account/restore", account.RestoreAccountRequest{Token: "restore_token"}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should not restore an account deleted by an administrator", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		service.On("HashToken", anyContext, "restore_token").Return("restore_hash")
+		repository.On("ConsumeOneTimeToken", anyContext, domain.TokenPurposeAccountRestore, "restore_hash").
+			Return(&domain.OneTimeToken{AccountID: 1, Purpose: domain.TokenPurposeAccountRestore}, nil)
+		repository.On("GetAccountByIDWithDeleted", anyContext, uint(1)).Return(deleted(nil), nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/restore", handler.RestoreAccount)
+
+		w := httpHelper.MakeRequest("POST", "/account/restore", account.RestoreAccountRequest{Token: "restore_token"}, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		repository.AssertNotCalled(t, "RestoreAccount", anyContext, mock.Anything)
+	})
+
+	t.Run("should refuse the token of a purged account", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		service.On("HashToken", anyContext, "restore_token").Return("restore_hash")
+		repository.On("ConsumeOneTimeToken", anyContext, domain.TokenPurposeAccountRestore, "restore_hash").
+			Return(&domain.OneTimeToken{AccountID: 1, Purpose: domain.TokenPurposeAccountRestore}, nil)
+		repository.On("GetAccountByIDWithDeleted", anyContext, uint(1)).Return(nil, gorm.ErrRecordNotFound)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/restore", handler.RestoreAccount)
+
+		w := httpHelper.MakeRequest("POST", "/account/restore", account.RestoreAccountRequest{Token: "restore_token"}, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"invalid or expired token"}`, w.Body.String())
+	})
+}
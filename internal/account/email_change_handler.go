@@ -0,0 +1,258 @@
+package account
+
+import (
+	"errors"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email"`
+	Password string `json:"password"`
+}
+
+// @Summary		Change Email
+// @Description	Request a change of the account email. A confirmation link is sent to the new address and a notice with a cancel link to the current one, the email only changes once the new address is confirmed.
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			account	body		ChangeEmailRequest	true	"New email and current password"
+// @Success		200		{object}	map[string]string
+// @Failure		400		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/change-email [post]
+func (h *AccountHandler) ChangeEmail(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "ChangeEmail")
+	defer span.End()
+
+	var req ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	newEmail := strings.TrimSpace(req.NewEmail)
+	if address, err := mail.ParseAddress(newEmail); err != nil || address.Address != newEmail {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid email"})
+		return
+	}
+
+	acc, err := h.accountRepository.GetAccountByID(ctx, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to get account by id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if strings.EqualFold(acc.Email, newEmail) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "new email is the current email"})
+		return
+	}
+
+	// accounts created by a social login have no password to confirm the change with
+	if acc.Password == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "set a password before changing the email"})
+		return
+	}
+
+	ok, err := h.accountService.ComparePassword(ctx, req.Password, acc.Password)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to compare password: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if !ok {
+		h.logger.WithField("userId", accountID).Errorf("invalid password")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid password"})
+		return
+	}
+
+	_, err = h.accountRepository.GetAccountByEmail(ctx, newEmail)
+	if err == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "email already in use"})
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		h.logger.WithField("userId", accountID).Errorf("failed to get account by email: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// a new request replaces the pending one, only the latest links stay valid
+	for _, purpose := range []string{domain.TokenPurposeEmailChange, domain.TokenPurposeEmailChangeCancel} {
+		err = h.accountRepository.RevokeOneTimeTokens(ctx, accountID, purpose)
+		if err != nil {
+			h.logger.WithField("userId", accountID).Errorf("failed to revoke email change tokens: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+	}
+
+	tokens := make(map[string]string, 2)
+	for _, purpose := range []string{domain.TokenPurposeEmailChange, domain.TokenPurposeEmailChangeCancel} {
+		token, record, err := h.accountService.GenerateOneTimeToken(ctx, acc, purpose)
+		if err != nil {
+			h.logger.WithField("userId", accountID).Errorf("failed to generate token: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+			return
+		}
+		record.Email = newEmail
+
+		_, err = h.accountRepository.CreateOneTimeToken(ctx, record)
+		if err != nil {
+			h.logger.WithField("userId", accountID).Errorf("failed to store email change token: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+			return
+		}
+		tokens[purpose] = token
+	}
+
+	err = h.accountService.SendEmailChangeEmail(ctx, newEmail, tokens[domain.TokenPurposeEmailChange])
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to send email change email: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send email change email"})
+		return
+	}
+
+	// the confirmation already went out, a lost notice must not fail the request
+	err = h.accountService.SendEmailChangeNoticeEmail(ctx, acc.Email, newEmail, tokens[domain.TokenPurposeEmailChangeCancel])
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to send email change notice email: %v", err)
+	}
+
+	err = h.accountRepository.LogAccountActivity(ctx, accountID, domain.ActivityEmailChangeRequest)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to log activity: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "a confirmation link has been sent to the new email"})
+}
+
+type EmailChangeTokenRequest struct {
+	Token string `json:"token"`
+}
+
+// @Summary		Confirm Email Change
+// @Description	Confirm an email change with the token sent to the new address. The new address becomes the verified email of the account.
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			account	body		EmailChangeTokenRequest	true	"Token"
+// @Success		200		{object}	map[string]string
+// @Failure		400		{object}	map[string]string
+// @Failure		409		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/change-email/confirm [post]
+func (h *AccountHandler) ConfirmEmailChange(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "ConfirmEmailChange")
+	defer span.End()
+
+	var req EmailChangeTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+		return
+	}
+
+	record, err := h.accountRepository.ConsumeOneTimeToken(ctx, domain.TokenPurposeEmailChange, h.accountService.HashToken(ctx, req.Token))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidOneTimeToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+			return
+		}
+		h.logger.Errorf("failed to consume email change token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// the address is checked again, another account may have taken it since the request
+	err = h.accountRepository.ChangeAccountEmail(ctx, record.AccountID, record.Email)
+	if err != nil {
+		if errors.Is(err, domain.ErrEmailTaken) {
+			c.JSON(http.StatusConflict, gin.H{"error": "email already in use"})
+			return
+		}
+		h.logger.WithField("userId", record.AccountID).Errorf("failed to change email: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	err = h.accountRepository.LogAccountActivity(ctx, record.AccountID, domain.ActivityEmailChanged)
+	if err != nil {
+		h.logger.WithField("userId", record.AccountID).Errorf("failed to log activity: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "email changed successfully"})
+}
+
+// @Summary		Cancel Email Change
+// @Description	Cancel a pending email change with the token sent to the current address.
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			account	body		EmailChangeTokenRequest	true	"Token"
+// @Success		200		{object}	map[string]string
+// @Failure		400		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/change-email/cancel [post]
+func (h *AccountHandler) CancelEmailChange(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "CancelEmailChange")
+	defer span.End()
+
+	var req EmailChangeTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+		return
+	}
+
+	record, err := h.accountRepository.ConsumeOneTimeToken(ctx, domain.TokenPurposeEmailChangeCancel, h.accountService.HashToken(ctx, req.Token))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidOneTimeToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+			return
+		}
+		h.logger.Errorf("failed to consume email change cancel token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	err = h.accountRepository.RevokeOneTimeTokens(ctx, record.AccountID, domain.TokenPurposeEmailChange)
+	if err != nil {
+		h.logger.WithField("userId", record.AccountID).Errorf("failed to revoke email change tokens: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	err = h.accountRepository.LogAccountActivity(ctx, record.AccountID, domain.ActivityEmailChangeCanceled)
+	if err != nil {
+		h.logger.WithField("userId", record.AccountID).Errorf("failed to log activity: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "email change canceled"})
+}
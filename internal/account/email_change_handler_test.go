@@ -0,0 +1,208 @@
+package account_test
+
+import (
+	"context"
+	"errors"
+	"go_starter_api/internal/account"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm"
+)
+
+func TestAccountHandler_EmailChange(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	authenticate := func(c *gin.Context) {
+		c.Set(utils.AccountIdContextKey, uint(1))
+	}
+
+	t.Run("should send a confirmation to the new email and a notice to the current one", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := &domain.Account{ID: 1, Email: "old@example.com", Password: "hashed"}
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		service.On("ComparePassword", anyContext, "password", "hashed").Return(true, nil)
+		repository.On("GetAccountByEmail", anyContext, "new@example.com").Return(nil, gorm.ErrRecordNotFound)
+		repository.On("RevokeOneTimeTokens", anyContext, uint(1), domain.TokenPurposeEmailChange).Return(nil)
+		repository.On("RevokeOneTimeTokens", anyContext, uint(1), domain.TokenPurposeEmailChangeCancel).Return(nil)
+		service.On("GenerateOneTimeToken", anyContext, acc, domain.TokenPurposeEmailChange).
+			Return("confirm_token", &domain.OneTimeToken{AccountID: 1, Purpose: domain.TokenPurposeEmailChange}, nil)
+		service.On("GenerateOneTimeToken", anyContext, acc, domain.TokenPurposeEmailChangeCancel).
+			Return("cancel_token", &domain.OneTimeToken{AccountID: 1, Purpose: domain.TokenPurposeEmailChangeCancel}, nil)
+		repository.On("CreateOneTimeToken", anyContext, mock.MatchedBy(func(token *domain.OneTimeToken) bool {
+			return token.Email == "new@example.com"
+		})).Return(func(ctx context.Context, token *domain.OneTimeToken) *domain.OneTimeToken { return token }, nil).Twice()
+		service.On("SendEmailChangeEmail", anyContext, "new@example.com", "confirm_token").Return(nil)
+		service.On("SendEmailChangeNoticeEmail", anyContext, "old@example.com", "new@example.com", "cancel_token").Return(nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityEmailChangeRequest).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.POST("/account/change-email", authenticate, handler.ChangeEmail)
+
+		w := httpHelper.MakeRequest("POST", "/account/change-email", account.ChangeEmailRequest{NewEmail: "new@example.com", Password: "password"}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should refuse an invalid password", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := &domain.Account{ID: 1, Email: "old@example.com", Password: "hashed"}
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		service.On("ComparePassword", anyContext, "wrong", "hashed").Return(false, nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.POST("/account/change-email", authenticate, handler.ChangeEmail)
+
+		w := httpHelper.MakeRequest("POST", "/account/change-email", account.ChangeEmailRequest{NewEmail: "new@example.com", Password: "wrong"}, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"invalid password"}`, w.Body.String())
+		service.AssertNotCalled(t, "SendEmailChangeEmail", anyContext, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should refuse an email used by another account", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := &domain.Account{ID: 1, Email: "old@example.com", Password: "hashed"}
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		service.On("ComparePassword", anyContext, "password", "hashed").Return(true, nil)
+		repository.On("GetAccountByEmail", anyContext, "taken@example.com").Return(&domain.Account{ID: 2, Email: "taken@example.com"}, nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.POST("/account/change-email", authenticate, handler.ChangeEmail)
+
+		w := httpHelper.MakeRequest("POST", "/account/change-email", account.ChangeEmailRequest{NewEmail: "taken@example.com", Password: "password"}, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"email already in use"}`, w.Body.String())
+	})
+
+	t.Run("should refuse a malformed or unchanged email", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(&domain.Account{ID: 1, Email: "old@example.com", Password: "hashed"}, nil).Once()
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.POST("/account/change-email", authenticate, handler.ChangeEmail)
+
+		w := httpHelper.MakeRequest("POST", "/account/change-email", account.ChangeEmailRequest{NewEmail: "Someone <new@example.com>", Password: "password"}, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"invalid email"}`, w.Body.String())
+
+		w = httpHelper.MakeRequest("POST", "/account/change-email", account.ChangeEmailRequest{NewEmail: "OLD@example.com", Password: "password"}, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"new email is the current email"}`, w.Body.String())
+	})
+
+	t.Run("should change the email with the confirmation token", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		service.On("HashToken", anyContext, "confirm_token").Return("confirm_hash")
+		repository.On("ConsumeOneTimeToken", anyContext, domain.TokenPurposeEmailChange, "confirm_hash").
+			Return(&domain.OneTimeToken{AccountID: 1, Purpose: domain.TokenPurposeEmailChange, Email: "new@example.com"}, nil)
+		repository.On("ChangeAccountEmail", anyContext, uint(1), "new@example.com").Return(nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityEmailChanged).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/change-email/confirm", handler.ConfirmEmailChange)
+
+		w := httpHelper.MakeRequest("POST", "/account/change-email/confirm", account.EmailChangeTokenRequest{Token: "confirm_token"}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should answer a conflict when the email was taken since the request", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		service.On("HashToken", anyContext, "confirm_token").Return("confirm_hash")
+		repository.On("ConsumeOneTimeToken", anyContext, domain.TokenPurposeEmailChange, "confirm_hash").
+			Return(&domain.OneTimeToken{AccountID: 1, Purpose: domain.TokenPurposeEmailChange, Email: "new@example.com"}, nil)
+		repository.On("ChangeAccountEmail", anyContext, uint(1), "new@example.com").Return(domain.ErrEmailTaken)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/change-email/confirm", handler.ConfirmEmailChange)
+
+		w := httpHelper.MakeRequest("POST", "/account/change-email/confirm", account.EmailChangeTokenRequest{Token: "confirm_token"}, nil)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.JSONEq(t, `{"error":"email already in use"}`, w.Body.String())
+	})
+
+	t.Run("should refuse an invalid confirmation token", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		service.On("HashToken", anyContext, "used_token").Return("used_hash")
+		repository.On("ConsumeOneTimeToken", anyContext, domain.TokenPurposeEmailChange, "used_hash").Return(nil, domain.ErrInvalidOneTimeToken)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/change-email/confirm", handler.ConfirmEmailChange)
+
+		w := httpHelper.MakeRequest("POST", "/account/change-email/confirm", account.EmailChangeTokenRequest{Token: "used_token"}, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"invalid or expired token"}`, w.Body.String())
+		repository.AssertNotCalled(t, "ChangeAccountEmail", anyContext, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should revoke the pending change with the cancel token", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		service.On("HashToken", anyContext, "cancel_token").Return("cancel_hash")
+		repository.On("ConsumeOneTimeToken", anyContext, domain.TokenPurposeEmailChangeCancel, "cancel_hash").
+			Return(&domain.OneTimeToken{AccountID: 1, Purpose: domain.TokenPurposeEmailChangeCancel, Email: "new@example.com"}, nil)
+		repository.On("RevokeOneTimeTokens", anyContext, uint(1), domain.TokenPurposeEmailChange).Return(nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityEmailChangeCanceled).Return(errors.New("db error"))
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/change-email/cancel", handler.CancelEmailChange)
+
+		w := httpHelper.MakeRequest("POST", "/account/change-email/cancel", account.EmailChangeTokenRequest{Token: "cancel_token"}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
@@ -0,0 +1,81 @@
+package account
+
+import (
+	"context"
+	"go_starter_api/pkg/domain"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AccountExport is what the account module stores about an account, secrets such as password
+// and token hashes are never exported
+type AccountExport struct {
+	Profile    GetProfileResponse          `json:"profile"`
+	MFAEnabled bool                        `json:"mfa_enabled"`
+	Activities []domain.AccountActivity    `json:"activities"`
+	Sessions   []domain.Session            `json:"sessions"`
+	APIKeys    []domain.APIKey             `json:"api_keys"`
+	Identities []domain.Identity           `json:"identities"`
+	Passkeys   []domain.WebAuthnCredential `json:"passkeys"`
+}
+
+// Exporter adds the account, its history and its credentials to the data export
+type Exporter struct {
+	tracer trace.Tracer
+
+	accountRepository domain.AccountRepository
+}
+
+func NewExporter(accountRepository domain.AccountRepository) *Exporter {
+	return &Exporter{
+		tracer:            otel.Tracer("accountExporter"),
+		accountRepository: accountRepository,
+	}
+}
+
+func (e *Exporter) Name() string {
+	return "account"
+}
+
+func (e *Exporter) Export(ctx context.Context, accountID uint) (any, error) {
+	ctx, span := e.tracer.Start(ctx, "Export")
+	defer span.End()
+
+	acc, err := e.accountRepository.GetAccountByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := AccountExport{
+		Profile:    newGetProfileResponse(acc),
+		MFAEnabled: acc.MFAEnabled,
+	}
+
+	export.Activities, err = e.accountRepository.ListAccountActivities(ctx, accountID, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	export.Sessions, err = e.accountRepository.ListSessions(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	export.APIKeys, err = e.accountRepository.ListAPIKeys(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	export.Identities, err = e.accountRepository.ListIdentities(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	export.Passkeys, err = e.accountRepository.ListWebAuthnCredentials(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
@@ -1,10 +1,12 @@
 package account
 
 import (
+	"context"
 	"errors"
 	"go_starter_api/pkg/domain"
 	"go_starter_api/pkg/utils"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -20,8 +22,9 @@ type AccountHandler struct {
 	tracer trace.Tracer
 	meter  metric.Meter
 
-	accountService    domain.AccountService
-	accountRepository domain.AccountRepository
+	accountService            domain.AccountService
+	accountRepository         domain.AccountRepository
+	tokenRevocationRepository domain.TokenRevocationRepository
 }
 
 const (
@@ -32,15 +35,17 @@ func NewAccountHandler(
 	logger *logrus.Logger,
 	accountService domain.AccountService,
 	accountRepository domain.AccountRepository,
+	tokenRevocationRepository domain.TokenRevocationRepository,
 ) *AccountHandler {
 	tracer := otel.Tracer(name)
 	meter := otel.Meter(name)
 	return &AccountHandler{
-		logger:            logger,
-		tracer:            tracer,
-		meter:             meter,
-		accountService:    accountService,
-		accountRepository: accountRepository,
+		logger:                    logger,
+		tracer:                    tracer,
+		meter:                     meter,
+		accountService:            accountService,
+		accountRepository:         accountRepository,
+		tokenRevocationRepository: tokenRevocationRepository,
 	}
 }
 
@@ -50,13 +55,16 @@ type RegisterAccountRequest struct {
 }
 
 type RegisterAccountResponse struct {
-	ID    uint   `json:"id"`
-	Email string `json:"email"`
-	Token string `json:"token"`
+	ID           uint   `json:"id"`
+	Email        string `json:"email"`
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	// set instead of the tokens when the email has to be verified before logging in
+	EmailVerificationRequired bool `json:"email_verification_required,omitempty"`
 }
 
 // @Summary		Register a new account
-// @Description	Register a new account
+// @Description	Register a new account and send a verification link to its email. No tokens are returned when the email verification policy requires a verified email to log in.
 // @Tags			account
 // @Accept			json
 // @Produce		json
@@ -76,6 +84,24 @@ func (h *AccountHandler) RegisterAccount(c *gin.Context) {
 		return
 	}
 
+	h.Register(ctx, c, req, RegisterOptions{})
+}
+
+// RegisterOptions adapt the registration of accounts created by other flows
+type RegisterOptions struct {
+	// EmailVerified is set when the flow already proved the email, no verification email is sent
+	EmailVerified bool
+	// WithoutPassword creates the account without a password, it logs in through the flow
+	// creating it until a password is set with a reset
+	WithoutPassword bool
+	// AfterCreate runs once the account exists and before its tokens are issued,
+	// the registration fails when it does
+	AfterCreate func(ctx context.Context, acc *domain.Account) error
+}
+
+// Register creates the account and writes the registration response, it is shared by the
+// register endpoint and the flows creating accounts, such as accepting an invitation
+func (h *AccountHandler) Register(ctx context.Context, c *gin.Context, req RegisterAccountRequest, opts RegisterOptions) {
 	// Check if account already exists
 	existingAcc, err := h.accountRepository.GetAccountByEmail(ctx, req.Email)
 	if err == nil && existingAcc != nil {
@@ -92,17 +118,25 @@ func (h *AccountHandler) RegisterAccount(c *gin.Context) {
 	}
 
 	// Hash the password before storing
-	hashedPassword, err := h.accountService.HashPassword(ctx, req.Password)
-	if err != nil {
-		h.logger.Errorf("failed to hash password: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
-		return
+	var hashedPassword string
+	if !opts.WithoutPassword {
+		hashedPassword, err = h.accountService.HashPassword(ctx, req.Password)
+		if err != nil {
+			h.logger.Errorf("failed to hash password: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
 	}
 
 	acc := &domain.Account{
 		Email:    req.Email,
 		Password: hashedPassword,
 	}
+	if opts.EmailVerified {
+		now := time.Now()
+		acc.EmailVerified = true
+		acc.VerifiedAt = &now
+	}
 
 	acc, err = h.accountRepository.CreateAccount(ctx, acc)
 	if err != nil {
@@ -111,11 +145,12 @@ func (h *AccountHandler) RegisterAccount(c *gin.Context) {
 		return
 	}
 
-	token, err := h.accountService.GenerateAuthToken(ctx, acc)
-	if err != nil {
-		h.logger.WithField("userId", acc.ID).Errorf("failed to generate token: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+	if !opts.EmailVerified {
+		// the account exists at this point, a failed email can be sent again from the resend endpoint
+		err = h.sendVerificationEmail(ctx, acc)
+		if err != nil {
+			h.logger.WithField("userId", acc.ID).Errorf("failed to send verification email: %v", err)
+		}
 	}
 
 	err = h.accountRepository.LogAccountActivity(ctx, acc.ID, domain.ActivityRegister)
@@ -123,30 +158,72 @@ func (h *AccountHandler) RegisterAccount(c *gin.Context) {
 		h.logger.WithField("userId", acc.ID).Errorf("failed to log activity: %v", err)
 	}
 
+	if opts.AfterCreate != nil {
+		if err := opts.AfterCreate(ctx, acc); err != nil {
+			h.logger.WithField("userId", acc.ID).Errorf("failed to complete registration: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+	}
+
+	if !opts.EmailVerified && emailVerificationPolicy() == EmailVerificationPolicyLogin {
+		c.JSON(http.StatusOK, RegisterAccountResponse{
+			ID:                        acc.ID,
+			Email:                     acc.Email,
+			EmailVerificationRequired: true,
+		})
+		return
+	}
+
+	token, refreshToken, _, err := h.issueTokens(ctx, c, acc, "")
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to generate token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, RegisterAccountResponse{
-		ID:    acc.ID,
-		Email: acc.Email,
-		Token: token,
+		ID:           acc.ID,
+		Email:        acc.Email,
+		Token:        token,
+		RefreshToken: refreshToken,
 	})
 }
 
 type LoginAccountRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email      string `json:"email"`
+	Password   string `json:"password"`
+	DeviceName string `json:"device_name"`
 }
 
 type LoginAccountResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	// set instead of the tokens when the account has mfa enabled or a passkey, the mfa token is
+	// exchanged for the tokens at /account/login/mfa or /account/webauthn/login/finish
+	MFARequired bool   `json:"mfa_required,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"`
+	// MFAMethods are the second factors of the account, totp and webauthn
+	MFAMethods []string `json:"mfa_methods,omitempty"`
 }
 
+// second factors listed in LoginAccountResponse
+const (
+	MFAMethodTOTP     = "totp"
+	MFAMethodWebAuthn = "webauthn"
+)
+
 // @Summary		Login a user
-// @Description	Login a user
+// @Description	Login a user. Accounts with mfa enabled get an mfa token instead, to be completed at /api/v1/account/login/mfa.
+// @Description	Repeated failures delay further attempts and temporarily lock the account, the wait is given in the Retry-After header.
 // @Tags			account
 // @Accept			json
 // @Produce		json
 // @Param			account	body		LoginAccountRequest	true	"Account"
 // @Success		200		{object}	LoginAccountResponse
 // @Failure		400		{object}	map[string]string
+// @Failure		403		{object}	map[string]string
+// @Failure		429		{object}	map[string]string
 // @Failure		500		{object}	map[string]string
 // @Router			/api/v1/account/login [post]
 func (h *AccountHandler) LoginAccount(c *gin.Context) {
@@ -160,17 +237,41 @@ func (h *AccountHandler) LoginAccount(c *gin.Context) {
 		return
 	}
 
+	limit, window := loginIPFailureLimit()
+	failures, err := h.accountRepository.CountLoginFailuresByIP(ctx, c.ClientIP(), time.Now().Add(-window))
+	if err != nil {
+		h.logger.Errorf("failed to count login failures: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	if failures >= limit {
+		h.logger.WithField("ip", c.ClientIP()).Warnf("login failure limit reached")
+		tooManyAttempts(c, window, "too many login attempts")
+		return
+	}
+
 	acc, err := h.accountRepository.GetAccountByEmail(ctx, req.Email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			h.logger.WithField("email", req.Email).Errorf("account not found")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid credentials"})
+			h.recordFailedLogin(ctx, c, nil, req.Email)
+			return
 		}
 		h.logger.Errorf("failed to get account by email: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
 
+	now := time.Now()
+	if retryAfter := loginRetryAfter(acc, now); retryAfter > 0 {
+		if isLocked(acc, now) {
+			tooManyAttempts(c, retryAfter, "account temporarily locked")
+			return
+		}
+		tooManyAttempts(c, retryAfter, "too many login attempts")
+		return
+	}
+
 	ok, err := h.accountService.ComparePassword(ctx, req.Password, acc.Password)
 	if err != nil {
 		h.logger.WithField("userId", acc.ID).Errorf("failed to compare password: %v", err)
@@ -179,35 +280,314 @@ func (h *AccountHandler) LoginAccount(c *gin.Context) {
 	}
 	if !ok {
 		h.logger.WithField("userId", acc.ID).Errorf("invalid password")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid credentials"})
+		h.recordFailedLogin(ctx, c, acc, req.Email)
+		return
+	}
+
+	h.continueLogin(ctx, c, acc, req.DeviceName, domain.ActivityLogin)
+}
+
+// continueLogin runs the checks shared by every login method once the first factor passed,
+// it either refuses the login, asks for the second factor or completes the login
+func (h *AccountHandler) continueLogin(ctx context.Context, c *gin.Context, acc *domain.Account, deviceName string, activity string) {
+	if loginRefused(c, acc) {
 		return
 	}
 
-	token, err := h.accountService.GenerateAuthToken(ctx, acc)
+	if acc.MFAEnabled || acc.WebAuthnEnabled {
+		mfaToken, err := h.accountService.GenerateMFAToken(ctx, acc)
+		if err != nil {
+			h.logger.WithField("userId", acc.ID).Errorf("failed to generate mfa token: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+			return
+		}
+
+		var methods []string
+		if acc.MFAEnabled {
+			methods = append(methods, MFAMethodTOTP)
+		}
+		if acc.WebAuthnEnabled {
+			methods = append(methods, MFAMethodWebAuthn)
+		}
+
+		c.JSON(
+			http.StatusOK,
+			LoginAccountResponse{
+				MFARequired: true,
+				MFAToken:    mfaToken,
+				MFAMethods:  methods,
+			},
+		)
+		return
+	}
+
+	h.completeLogin(ctx, c, acc, deviceName, activity)
+}
+
+// loginRefused writes the refusal of accounts that cannot log in whatever the factors
+func loginRefused(c *gin.Context, acc *domain.Account) bool {
+	if acc.DisabledAt != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "account disabled"})
+		return true
+	}
+
+	// a forced reset closes every way in, not only the password that may be compromised
+	if acc.PasswordResetRequired {
+		c.JSON(http.StatusForbidden, gin.H{"error": "password reset required"})
+		return true
+	}
+
+	if !acc.EmailVerified && emailVerificationPolicy() == EmailVerificationPolicyLogin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "email not verified"})
+		return true
+	}
+
+	return false
+}
+
+// completeLogin issues the tokens of an account that passed every login check, its login
+// failures are reset and the owner is told of logins from a new device
+func (h *AccountHandler) completeLogin(ctx context.Context, c *gin.Context, acc *domain.Account, deviceName string, activity string) {
+	h.resetLoginFailures(ctx, acc)
+
+	// checked before the session of this login is created, it would make the device known
+	newDevice, err := h.accountRepository.IsNewDevice(ctx, acc.ID, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to check device: %v", err)
+	}
+
+	token, refreshToken, session, err := h.issueTokens(ctx, c, acc, deviceName)
 	if err != nil {
 		h.logger.WithField("userId", acc.ID).Errorf("failed to generate token: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
 		return
 	}
 
-	err = h.accountRepository.LogAccountActivity(ctx, acc.ID, domain.ActivityLogin)
+	err = h.accountRepository.LogAccountActivity(ctx, acc.ID, activity)
 	if err != nil {
 		h.logger.WithField("userId", acc.ID).Errorf("failed to log activity: %v", err)
 	}
 
+	if newDevice {
+		h.notifyNewDevice(ctx, acc, session)
+	}
+
 	c.JSON(
 		http.StatusOK,
 		LoginAccountResponse{
-			Token: token,
+			Token:        token,
+			RefreshToken: refreshToken,
+		},
+	)
+}
+
+// issueTokens starts a new session for the request's device and issues its access token
+// and the first refresh token of a new family
+func (h *AccountHandler) issueTokens(ctx context.Context, c *gin.Context, acc *domain.Account, deviceName string) (string, string, *domain.Session, error) {
+	if deviceName == "" {
+		deviceName = deviceLabel(c.Request.UserAgent())
+	}
+
+	refreshToken, record, err := h.accountService.GenerateRefreshToken(ctx, acc, "")
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	now := time.Now()
+	session, err := h.accountRepository.CreateSession(ctx, &domain.Session{
+		AccountID:   acc.ID,
+		IPAddress:   c.ClientIP(),
+		UserAgent:   c.Request.UserAgent(),
+		DeviceLabel: deviceName,
+		LastSeenAt:  now,
+		ExpiresAt:   record.ExpiresAt,
+	})
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	token, err := h.accountService.GenerateAuthToken(ctx, acc, session.ID, session.OrganizationID)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	record.SessionID = session.ID
+	_, err = h.accountRepository.CreateRefreshToken(ctx, record)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	return token, refreshToken, session, nil
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type RefreshTokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// @Summary		Refresh Token
+// @Description	Exchange a refresh token for a new access token. The refresh token is rotated on every use, reusing an old one revokes the whole token family.
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			account	body		RefreshTokenRequest	true	"Refresh Token"
+// @Success		200		{object}	RefreshTokenResponse
+// @Failure		400		{object}	map[string]string
+// @Failure		401		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/token/refresh [post]
+func (h *AccountHandler) RefreshToken(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "RefreshToken")
+	defer span.End()
+
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "refresh token is required"})
+		return
+	}
+
+	hash := h.accountService.HashToken(ctx, req.RefreshToken)
+
+	current, err := h.accountRepository.GetRefreshTokenByHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+			return
+		}
+		h.logger.Errorf("failed to get refresh token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if current.RotatedAt != nil {
+		h.revokeReusedFamily(ctx, current)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+		return
+	}
+
+	// revoked by a logout or a session revocation
+	if current.RevokedAt != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+		return
+	}
+
+	if time.Now().After(current.ExpiresAt) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token expired"})
+		return
+	}
+
+	// the new access token stays in the organization the session switched to
+	var organizationID uint
+	if current.SessionID != 0 {
+		session, err := h.accountRepository.GetSessionByID(ctx, current.SessionID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			h.logger.WithField("userId", current.AccountID).Errorf("failed to get session: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if err != nil || session.RevokedAt != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+			return
+		}
+		organizationID = session.OrganizationID
+	}
+
+	acc, err := h.accountRepository.GetAccountByID(ctx, current.AccountID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+			return
+		}
+		h.logger.WithField("userId", current.AccountID).Errorf("failed to get account by id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// disabling revokes the refresh tokens, this covers a refresh racing with it
+	if acc.DisabledAt != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+		return
+	}
+
+	refreshToken, next, err := h.accountService.GenerateRefreshToken(ctx, acc, current.FamilyID)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to generate refresh token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+		return
+	}
+
+	next.SessionID = current.SessionID
+	_, err = h.accountRepository.RotateRefreshToken(ctx, current, next)
+	if err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenReused) {
+			h.revokeReusedFamily(ctx, current)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+			return
+		}
+		h.logger.WithField("userId", acc.ID).Errorf("failed to rotate refresh token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	token, err := h.accountService.GenerateAuthToken(ctx, acc, current.SessionID, organizationID)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to generate token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		RefreshTokenResponse{
+			Token:        token,
+			RefreshToken: refreshToken,
 		},
 	)
 }
 
+// a rotated refresh token being presented again means it leaked,
+// so every token descending from the same login is revoked along with its session
+func (h *AccountHandler) revokeReusedFamily(ctx context.Context, token *domain.RefreshToken) {
+	h.logger.WithField("userId", token.AccountID).Warnf("refresh token reuse detected, revoking family %s", token.FamilyID)
+
+	err := h.accountRepository.RevokeRefreshTokenFamily(ctx, token.FamilyID)
+	if err != nil {
+		h.logger.WithField("userId", token.AccountID).Errorf("failed to revoke refresh token family: %v", err)
+	}
+
+	if token.SessionID != 0 {
+		err = h.accountRepository.RevokeSession(ctx, token.SessionID)
+		if err != nil {
+			h.logger.WithField("userId", token.AccountID).Errorf("failed to revoke session: %v", err)
+		}
+	}
+
+	err = h.accountRepository.LogAccountActivity(ctx, token.AccountID, domain.ActivityRefreshTokenReuse)
+	if err != nil {
+		h.logger.WithField("userId", token.AccountID).Errorf("failed to log activity: %v", err)
+	}
+}
+
+type LogoutAccountRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
 // @Summary		Logout a user
-// @Description	Logout a user
+// @Description	End the current session and revoke its access token, and the refresh token family if a refresh token is given
 // @Tags			account
 // @Accept			json
 // @Produce		json
+// @Param			account	body		LogoutAccountRequest	false	"Logout"
 // @Success		200		{object}	map[string]string
 // @Failure		400		{object}	map[string]string
 // @Failure		500		{object}	map[string]string
@@ -217,6 +597,14 @@ func (h *AccountHandler) LogoutAccount(c *gin.Context) {
 	ctx, span := h.tracer.Start(ctx, "LogoutAccount")
 	defer span.End()
 
+	var req LogoutAccountRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	accountID := c.GetUint(utils.AccountIdContextKey)
 	if accountID == 0 {
 		h.logger.Errorf("accountID not found")
@@ -224,6 +612,51 @@ func (h *AccountHandler) LogoutAccount(c *gin.Context) {
 		return
 	}
 
+	claims, ok := utils.AuthClaims(c)
+	if !ok {
+		h.logger.WithField("userId", accountID).Errorf("auth claims not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if claims.TokenID != "" {
+		err := h.tokenRevocationRepository.RevokeToken(ctx, claims.TokenID, accountID, claims.ExpiresAt)
+		if err != nil {
+			h.logger.WithField("userId", accountID).Errorf("failed to revoke token: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+	}
+
+	if claims.SessionID != 0 {
+		err := h.accountRepository.RevokeSession(ctx, claims.SessionID)
+		if err != nil {
+			h.logger.WithField("userId", accountID).Errorf("failed to revoke session: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+	}
+
+	if req.RefreshToken != "" {
+		hash := h.accountService.HashToken(ctx, req.RefreshToken)
+		refreshToken, err := h.accountRepository.GetRefreshTokenByHash(ctx, hash)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			h.logger.WithField("userId", accountID).Errorf("failed to get refresh token: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
+		// only the owner can revoke a refresh token family
+		if err == nil && refreshToken.AccountID == accountID {
+			err = h.accountRepository.RevokeRefreshTokenFamily(ctx, refreshToken.FamilyID)
+			if err != nil {
+				h.logger.WithField("userId", accountID).Errorf("failed to revoke refresh token family: %v", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+				return
+			}
+		}
+	}
+
 	err := h.accountRepository.LogAccountActivity(ctx, accountID, domain.ActivityLogout)
 	if err != nil {
 		h.logger.WithField("userId", accountID).Errorf("failed to log activity: %v", err)
@@ -237,11 +670,207 @@ func (h *AccountHandler) LogoutAccount(c *gin.Context) {
 	)
 }
 
+// @Summary		Logout everywhere
+// @Description	End every session and revoke every access and refresh token issued to the authenticated user so far
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Success		200		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/logout-all [post]
+func (h *AccountHandler) LogoutAllAccount(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "LogoutAllAccount")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	err := h.tokenRevocationRepository.RevokeAllTokens(ctx, accountID, time.Now())
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to revoke tokens: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	err = h.accountRepository.RevokeAllSessions(ctx, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to revoke sessions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	err = h.accountRepository.LogAccountActivity(ctx, accountID, domain.ActivityLogoutAll)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to log activity: %v", err)
+	}
+
+	c.JSON(
+		http.StatusOK,
+		gin.H{
+			"message": "logged out from all devices",
+		},
+	)
+}
+
+type SessionResponse struct {
+	ID          uint      `json:"id"`
+	DeviceLabel string    `json:"device_label"`
+	IPAddress   string    `json:"ip_address"`
+	UserAgent   string    `json:"user_agent"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+	Current     bool      `json:"current"`
+}
+
+type ListSessionsResponse struct {
+	Sessions []SessionResponse `json:"sessions"`
+}
+
+// @Summary		List Sessions
+// @Description	List the active sessions of the authenticated user
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Success		200		{object}	ListSessionsResponse
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/sessions [get]
+func (h *AccountHandler) ListSessions(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "ListSessions")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	var currentSessionID uint
+	if claims, ok := utils.AuthClaims(c); ok {
+		currentSessionID = claims.SessionID
+	}
+
+	sessions, err := h.accountRepository.ListActiveSessions(ctx, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to list sessions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	response := ListSessionsResponse{Sessions: []SessionResponse{}}
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, SessionResponse{
+			ID:          session.ID,
+			DeviceLabel: session.DeviceLabel,
+			IPAddress:   session.IPAddress,
+			UserAgent:   session.UserAgent,
+			CreatedAt:   session.CreatedAt,
+			LastSeenAt:  session.LastSeenAt,
+			Current:     session.ID == currentSessionID,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary		Revoke Session
+// @Description	Revoke a session of the authenticated user, its tokens stop working immediately
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			id	path		int	true	"Session ID"
+// @Success		200	{object}	map[string]string
+// @Failure		400	{object}	map[string]string
+// @Failure		404	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/account/sessions/{id} [delete]
+func (h *AccountHandler) RevokeSession(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "RevokeSession")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session id"})
+		return
+	}
+
+	session, err := h.accountRepository.GetSessionByID(ctx, uint(sessionID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+			return
+		}
+		h.logger.WithField("userId", accountID).Errorf("failed to get session: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// sessions of other accounts are reported as missing
+	if session.AccountID != accountID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		return
+	}
+
+	err = h.accountRepository.RevokeSession(ctx, session.ID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to revoke session: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	err = h.accountRepository.LogAccountActivity(ctx, accountID, domain.ActivitySessionRevoked)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to log activity: %v", err)
+	}
+
+	c.JSON(
+		http.StatusOK,
+		gin.H{
+			"message": "session revoked",
+		},
+	)
+}
+
 type GetProfileResponse struct {
-	ID        uint      `json:"id"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID            uint           `json:"id"`
+	Email         string         `json:"email"`
+	EmailVerified bool           `json:"email_verified"`
+	DisplayName   string         `json:"display_name"`
+	AvatarURL     string         `json:"avatar_url"`
+	Locale        string         `json:"locale"`
+	Timezone      string         `json:"timezone"`
+	Metadata      map[string]any `json:"metadata"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+func newGetProfileResponse(acc *domain.Account) GetProfileResponse {
+	return GetProfileResponse{
+		ID:            acc.ID,
+		Email:         acc.Email,
+		EmailVerified: acc.EmailVerified,
+		DisplayName:   acc.DisplayName,
+		AvatarURL:     acc.AvatarURL,
+		Locale:        acc.Locale,
+		Timezone:      acc.Timezone,
+		Metadata:      acc.Metadata,
+		CreatedAt:     acc.CreatedAt,
+		UpdatedAt:     acc.UpdatedAt,
+	}
 }
 
 // @Summary		Get Profile
@@ -272,12 +901,7 @@ func (h *AccountHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, GetProfileResponse{
-		ID:        acc.ID,
-		Email:     acc.Email,
-		CreatedAt: acc.CreatedAt,
-		UpdatedAt: acc.UpdatedAt,
-	})
+	c.JSON(http.StatusOK, newGetProfileResponse(acc))
 }
 
 type ForgotPasswordRequest struct {
@@ -322,13 +946,28 @@ func (h *AccountHandler) ForgotPassword(c *gin.Context) {
 		return
 	}
 
-	token, err := h.accountService.GeneratePasswordResetToken(ctx, acc)
+	// only the most recently requested link works
+	err = h.accountRepository.RevokeOneTimeTokens(ctx, acc.ID, domain.TokenPurposePasswordReset)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to revoke password reset tokens: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	token, record, err := h.accountService.GenerateOneTimeToken(ctx, acc, domain.TokenPurposePasswordReset)
 	if err != nil {
 		h.logger.Errorf("failed to generate token: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
 		return
 	}
 
+	_, err = h.accountRepository.CreateOneTimeToken(ctx, record)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to store password reset token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+		return
+	}
+
 	err = h.accountService.SendPasswordResetEmail(ctx, acc.Email, token)
 	if err != nil {
 		h.logger.Errorf("failed to send password reset email: %v", err)
@@ -359,7 +998,7 @@ type ResetPasswordResponse struct {
 }
 
 // @Summary		Reset Password
-// @Description	Reset Password
+// @Description	Reset the password with the single use token from the password reset email
 // @Tags			account
 // @Accept			json
 // @Produce		json
@@ -379,40 +1018,42 @@ func (h *AccountHandler) ResetPassword(c *gin.Context) {
 		return
 	}
 
-	token := req.Token
-	password := req.Password
-
-	accountID, err := h.accountService.ValidatePasswordResetToken(ctx, token)
-	if err != nil {
-		h.logger.Errorf("failed to validate token: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	// checked before the token is consumed so a typo does not burn the link
+	if req.Password == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrPasswordEmpty.Error()})
 		return
 	}
 
-	acc, err := h.accountRepository.GetAccountByID(ctx, accountID)
+	hashedPassword, err := h.accountService.HashPassword(ctx, req.Password)
 	if err != nil {
-		h.logger.WithField("userId", accountID).Errorf("failed to get account by id: %v", err)
+		h.logger.Errorf("failed to hash password: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
 
-	hashedPassword, err := h.accountService.HashPassword(ctx, password)
+	hash := h.accountService.HashToken(ctx, req.Token)
+
+	token, err := h.accountRepository.ConsumeOneTimeToken(ctx, domain.TokenPurposePasswordReset, hash)
 	if err != nil {
-		h.logger.WithField("userId", accountID).Errorf("failed to hash password: %v", err)
+		if errors.Is(err, domain.ErrInvalidOneTimeToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+			return
+		}
+		h.logger.Errorf("failed to consume password reset token: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
 
-	acc.Password = hashedPassword
+	accountID := token.AccountID
 
-	acc, err = h.accountRepository.UpdateAccount(ctx, acc)
+	err = h.accountRepository.UpdateAccountPassword(ctx, accountID, hashedPassword)
 	if err != nil {
-		h.logger.WithField("userId", accountID).Errorf("failed to update account: %v", err)
+		h.logger.WithField("userId", accountID).Errorf("failed to update password: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
 
-	err = h.accountRepository.LogAccountActivity(ctx, acc.ID, domain.ActivityResetPassword)
+	err = h.accountRepository.LogAccountActivity(ctx, accountID, domain.ActivityResetPassword)
 	if err != nil {
 		h.logger.WithField("userId", accountID).Errorf("failed to log activity: %v", err)
 	}
@@ -489,11 +1130,9 @@ func (h *AccountHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	acc.Password = hashedPassword
-
-	acc, err = h.accountRepository.UpdateAccount(ctx, acc)
+	err = h.accountRepository.UpdateAccountPassword(ctx, acc.ID, hashedPassword)
 	if err != nil {
-		h.logger.WithField("userId", accountID).Errorf("failed to update account: %v", err)
+		h.logger.WithField("userId", accountID).Errorf("failed to update password: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 		return
 	}
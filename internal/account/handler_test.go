@@ -8,7 +8,9 @@ import (
 	"net/http/httptest"
 	"go_starter_api/internal/account"
 	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -56,8 +58,11 @@ type HTTPTestHelper struct {
 // NewHTTPTestHelper creates a new HTTP test helper with Gin router in test mode
 func NewHTTPTestHelper() *HTTPTestHelper {
 	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	// like the server without TRUSTED_PROXIES, the client ip is the peer address
+	_ = router.SetTrustedProxies(nil)
 	return &HTTPTestHelper{
-		router: gin.Default(),
+		router: router,
 	}
 }
 
@@ -150,12 +155,17 @@ func TestAccountHandler_RegisterAccount(t *testing.T) {
 		repository.On("GetAccountByEmail", anyContext, "test@example.com").Return(nil, gorm.ErrRecordNotFound)
 		repository.On("CreateAccount", anyContext, mock.AnythingOfType("*domain.Account")).Return(&domain.Account{ID: 1, Email: "test@example.com"}, nil)
 		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityRegister).Return(nil)
+		repository.On("CreateSession", anyContext, mock.AnythingOfType("*domain.Session")).Return(&domain.Session{ID: 5, AccountID: 1}, nil)
+		repository.On("CreateRefreshToken", anyContext, mock.AnythingOfType("*domain.RefreshToken")).Return(&domain.RefreshToken{ID: 1, AccountID: 1, SessionID: 5}, nil)
 
 		// Mock service methods
 		service.On("HashPassword", anyContext, "password").Return("hashed_password", nil)
-		service.On("GenerateAuthToken", anyContext, mock.AnythingOfType("*domain.Account")).Return("auth_token", nil)
+		service.On("GenerateAuthToken", anyContext, mock.AnythingOfType("*domain.Account"), uint(5), uint(0)).Return("auth_token", nil)
+		service.On("GenerateRefreshToken", anyContext, mock.AnythingOfType("*domain.Account"), "").Return("refresh_token", &domain.RefreshToken{AccountID: 1}, nil)
+		service.On("GenerateEmailVerificationToken", anyContext, mock.AnythingOfType("*domain.Account")).Return("verification_token", nil)
+		service.On("SendVerificationEmail", anyContext, "test@example.com", "verification_token").Return(nil)
 
-		handler := account.NewAccountHandler(logger, service, repository)
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
 
 		// Setup HTTP test helper
 		httpHelper := NewHTTPTestHelper()
@@ -177,6 +187,7 @@ func TestAccountHandler_RegisterAccount(t *testing.T) {
 		assert.Equal(t, "test@example.com", response.Email)
 		assert.Equal(t, uint(1), response.ID)
 		assert.Equal(t, "auth_token", response.Token)
+		assert.Equal(t, "refresh_token", response.RefreshToken)
 	})
 
 	t.Run("should return error when account already exists", func(t *testing.T) {
@@ -188,7 +199,7 @@ func TestAccountHandler_RegisterAccount(t *testing.T) {
 		existingAccount := &domain.Account{ID: 1, Email: "test@example.com"}
 		repository.On("GetAccountByEmail", anyContext, "test@example.com").Return(existingAccount, nil)
 
-		handler := account.NewAccountHandler(logger, service, repository)
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
 
 		// Setup HTTP test helper
 		httpHelper := NewHTTPTestHelper()
@@ -209,3 +220,596 @@ func TestAccountHandler_RegisterAccount(t *testing.T) {
 	})
 
 }
+
+func TestAccountHandler_RefreshToken(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	t.Run("should rotate refresh token and return a new token pair", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		current := &domain.RefreshToken{ID: 1, AccountID: 1, SessionID: 5, FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour)}
+		next := &domain.RefreshToken{AccountID: 1, FamilyID: "family"}
+		acc := &domain.Account{ID: 1, Email: "test@example.com"}
+
+		service.On("HashToken", anyContext, "old_refresh_token").Return("old_hash")
+		repository.On("GetRefreshTokenByHash", anyContext, "old_hash").Return(current, nil)
+		// the new access token stays in the organization of the session
+		repository.On("GetSessionByID", anyContext, uint(5)).Return(&domain.Session{ID: 5, AccountID: 1, OrganizationID: 3}, nil)
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		service.On("GenerateRefreshToken", anyContext, acc, "family").Return("new_refresh_token", next, nil)
+		repository.On("RotateRefreshToken", anyContext, current, next).Return(next, nil)
+		service.On("GenerateAuthToken", anyContext, acc, uint(5), uint(3)).Return("auth_token", nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/token/refresh", handler.RefreshToken)
+
+		reqBody := account.RefreshTokenRequest{RefreshToken: "old_refresh_token"}
+		w := httpHelper.MakeRequest("POST", "/account/token/refresh", reqBody, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response account.RefreshTokenResponse
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.Equal(t, "auth_token", response.Token)
+		assert.Equal(t, "new_refresh_token", response.RefreshToken)
+		assert.Equal(t, uint(5), next.SessionID)
+	})
+
+	t.Run("should revoke the token family when a used refresh token is presented", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		revokedAt := time.Now().Add(-time.Minute)
+		current := &domain.RefreshToken{ID: 1, AccountID: 1, SessionID: 5, FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt, RotatedAt: &revokedAt}
+
+		service.On("HashToken", anyContext, "old_refresh_token").Return("old_hash")
+		repository.On("GetRefreshTokenByHash", anyContext, "old_hash").Return(current, nil)
+		repository.On("RevokeRefreshTokenFamily", anyContext, "family").Return(nil)
+		repository.On("RevokeSession", anyContext, uint(5)).Return(nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityRefreshTokenReuse).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/token/refresh", handler.RefreshToken)
+
+		reqBody := account.RefreshTokenRequest{RefreshToken: "old_refresh_token"}
+		w := httpHelper.MakeRequest("POST", "/account/token/refresh", reqBody, nil)
+
+		var response map[string]string
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, "invalid refresh token", response["error"])
+	})
+
+	t.Run("should reject a refresh token of a revoked session without flagging reuse", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		revokedAt := time.Now().Add(-time.Minute)
+		current := &domain.RefreshToken{ID: 1, AccountID: 1, SessionID: 5, FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}
+
+		service.On("HashToken", anyContext, "old_refresh_token").Return("old_hash")
+		repository.On("GetRefreshTokenByHash", anyContext, "old_hash").Return(current, nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/token/refresh", handler.RefreshToken)
+
+		reqBody := account.RefreshTokenRequest{RefreshToken: "old_refresh_token"}
+		w := httpHelper.MakeRequest("POST", "/account/token/refresh", reqBody, nil)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("should reject an unknown refresh token", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		service.On("HashToken", anyContext, "unknown").Return("unknown_hash")
+		repository.On("GetRefreshTokenByHash", anyContext, "unknown_hash").Return(nil, gorm.ErrRecordNotFound)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/token/refresh", handler.RefreshToken)
+
+		reqBody := account.RefreshTokenRequest{RefreshToken: "unknown"}
+		w := httpHelper.MakeRequest("POST", "/account/token/refresh", reqBody, nil)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestAccountHandler_LogoutAccount(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	claims := &domain.AuthClaims{AccountID: 1, SessionID: 5, TokenID: "jti", IssuedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)}
+	authenticate := func(c *gin.Context) {
+		c.Set(utils.AccountIdContextKey, claims.AccountID)
+		c.Set(utils.AuthClaimsContextKey, claims)
+	}
+
+	t.Run("should revoke the access token and refresh token family", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+		revocations := account.NewInMemoryTokenRevocationRepository()
+
+		repository.On("RevokeSession", anyContext, uint(5)).Return(nil)
+		service.On("HashToken", anyContext, "refresh_token").Return("refresh_hash")
+		repository.On("GetRefreshTokenByHash", anyContext, "refresh_hash").Return(&domain.RefreshToken{ID: 1, AccountID: 1, FamilyID: "family"}, nil)
+		repository.On("RevokeRefreshTokenFamily", anyContext, "family").Return(nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityLogout).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, revocations)
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.POST("/account/logout", authenticate, handler.LogoutAccount)
+
+		reqBody := account.LogoutAccountRequest{RefreshToken: "refresh_token"}
+		w := httpHelper.MakeRequest("POST", "/account/logout", reqBody, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		revoked, err := revocations.IsTokenRevoked(context.Background(), claims)
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("should not revoke a refresh token family owned by another account", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		repository.On("RevokeSession", anyContext, uint(5)).Return(nil)
+		service.On("HashToken", anyContext, "refresh_token").Return("refresh_hash")
+		repository.On("GetRefreshTokenByHash", anyContext, "refresh_hash").Return(&domain.RefreshToken{ID: 1, AccountID: 2, FamilyID: "family"}, nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityLogout).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.POST("/account/logout", authenticate, handler.LogoutAccount)
+
+		reqBody := account.LogoutAccountRequest{RefreshToken: "refresh_token"}
+		w := httpHelper.MakeRequest("POST", "/account/logout", reqBody, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestAccountHandler_LogoutAllAccount(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	t.Run("should revoke every token of the account", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+		revocations := account.NewInMemoryTokenRevocationRepository()
+
+		repository.On("RevokeAllSessions", anyContext, uint(1)).Return(nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityLogoutAll).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, revocations)
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.POST("/account/logout-all", func(c *gin.Context) {
+			c.Set(utils.AccountIdContextKey, uint(1))
+		}, handler.LogoutAllAccount)
+
+		w := httpHelper.MakeRequest("POST", "/account/logout-all", nil, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		claims := &domain.AuthClaims{AccountID: 1, TokenID: "older", IssuedAt: time.Now().Add(-time.Minute)}
+		revoked, err := revocations.IsTokenRevoked(context.Background(), claims)
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+}
+
+func TestAccountHandler_Sessions(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	authenticate := func(c *gin.Context) {
+		c.Set(utils.AccountIdContextKey, uint(1))
+		c.Set(utils.AuthClaimsContextKey, &domain.AuthClaims{AccountID: 1, SessionID: 5})
+	}
+
+	t.Run("should list sessions and flag the current one", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		repository.On("ListActiveSessions", anyContext, uint(1)).Return([]domain.Session{
+			{ID: 5, AccountID: 1, DeviceLabel: "Chrome on macOS"},
+			{ID: 6, AccountID: 1, DeviceLabel: "Firefox on Linux"},
+		}, nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.GET("/account/sessions", authenticate, handler.ListSessions)
+
+		w := httpHelper.MakeRequest("GET", "/account/sessions", nil, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response account.ListSessionsResponse
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.Len(t, response.Sessions, 2)
+		assert.True(t, response.Sessions[0].Current)
+		assert.False(t, response.Sessions[1].Current)
+	})
+
+	t.Run("should revoke a session of the account", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		repository.On("GetSessionByID", anyContext, uint(6)).Return(&domain.Session{ID: 6, AccountID: 1}, nil)
+		repository.On("RevokeSession", anyContext, uint(6)).Return(nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivitySessionRevoked).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.DELETE("/account/sessions/:id", authenticate, handler.RevokeSession)
+
+		w := httpHelper.MakeRequest("DELETE", "/account/sessions/6", nil, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should not revoke a session of another account", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		repository.On("GetSessionByID", anyContext, uint(9)).Return(&domain.Session{ID: 9, AccountID: 2}, nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.DELETE("/account/sessions/:id", authenticate, handler.RevokeSession)
+
+		w := httpHelper.MakeRequest("DELETE", "/account/sessions/9", nil, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestAccountHandler_ForgotPassword(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	t.Run("should replace outstanding reset tokens and email a new one", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := &domain.Account{ID: 1, Email: "test@example.com"}
+		record := &domain.OneTimeToken{AccountID: 1, Purpose: domain.TokenPurposePasswordReset, TokenHash: "reset_hash"}
+
+		repository.On("GetAccountByEmail", anyContext, "test@example.com").Return(acc, nil)
+		repository.On("RevokeOneTimeTokens", anyContext, uint(1), domain.TokenPurposePasswordReset).Return(nil)
+		service.On("GenerateOneTimeToken", anyContext, acc, domain.TokenPurposePasswordReset).Return("reset_token", record, nil)
+		repository.On("CreateOneTimeToken", anyContext, record).Return(record, nil)
+		service.On("SendPasswordResetEmail", anyContext, "test@example.com", "reset_token").Return(nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityForgotPassword).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/forgot-password", handler.ForgotPassword)
+
+		reqBody := account.ForgotPasswordRequest{Email: "test@example.com"}
+		w := httpHelper.MakeRequest("POST", "/account/forgot-password", reqBody, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestAccountHandler_ResetPassword(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	t.Run("should consume the token and update the password", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		service.On("HashPassword", anyContext, "new_password").Return("hashed_password", nil)
+		service.On("HashToken", anyContext, "reset_token").Return("reset_hash")
+		repository.On("ConsumeOneTimeToken", anyContext, domain.TokenPurposePasswordReset, "reset_hash").Return(&domain.OneTimeToken{ID: 1, AccountID: 1}, nil)
+		repository.On("UpdateAccountPassword", anyContext, uint(1), "hashed_password").Return(nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityResetPassword).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/reset-password", handler.ResetPassword)
+
+		reqBody := account.ResetPasswordRequest{Token: "reset_token", Password: "new_password"}
+		w := httpHelper.MakeRequest("POST", "/account/reset-password", reqBody, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should return bad request for a used, revoked or expired token", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		service.On("HashPassword", anyContext, "new_password").Return("hashed_password", nil)
+		service.On("HashToken", anyContext, "reset_token").Return("reset_hash")
+		repository.On("ConsumeOneTimeToken", anyContext, domain.TokenPurposePasswordReset, "reset_hash").Return(nil, domain.ErrInvalidOneTimeToken)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/reset-password", handler.ResetPassword)
+
+		reqBody := account.ResetPasswordRequest{Token: "reset_token", Password: "new_password"}
+		w := httpHelper.MakeRequest("POST", "/account/reset-password", reqBody, nil)
+
+		var response map[string]string
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "invalid or expired token", response["error"])
+	})
+
+	t.Run("should not consume the token when the password is empty", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/reset-password", handler.ResetPassword)
+
+		reqBody := account.ResetPasswordRequest{Token: "reset_token", Password: ""}
+		w := httpHelper.MakeRequest("POST", "/account/reset-password", reqBody, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestAccountHandler_ChangePassword(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	t.Run("should update the password through the repository so reset tokens are revoked", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(&domain.Account{ID: 1, Password: "old_hash"}, nil)
+		service.On("ComparePassword", anyContext, "old_password", "old_hash").Return(true, nil)
+		service.On("HashPassword", anyContext, "new_password").Return("new_hash", nil)
+		repository.On("UpdateAccountPassword", anyContext, uint(1), "new_hash").Return(nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityChangePassword).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.POST("/account/change-password", func(c *gin.Context) {
+			c.Set(utils.AccountIdContextKey, uint(1))
+		}, handler.ChangePassword)
+
+		reqBody := account.ChangePasswordRequest{OldPassword: "old_password", NewPassword: "new_password"}
+		w := httpHelper.MakeRequest("POST", "/account/change-password", reqBody, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestAccountHandler_LoginLockout(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+	anyTime := mock.AnythingOfType("time.Time")
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	login := func(handler *account.AccountHandler) *httptest.ResponseRecorder {
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/login", handler.LoginAccount)
+
+		reqBody := account.LoginAccountRequest{Email: "test@example.com", Password: "wrong_password"}
+		return httpHelper.MakeRequest("POST", "/account/login", reqBody, nil)
+	}
+
+	t.Run("should record a failed login", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := &domain.Account{ID: 1, Email: "test@example.com", Password: "hashed_password"}
+		repository.On("CountLoginFailuresByIP", anyContext, mock.Anything, anyTime).Return(int64(0), nil)
+		repository.On("GetAccountByEmail", anyContext, "test@example.com").Return(acc, nil)
+		service.On("ComparePassword", anyContext, "wrong_password", "hashed_password").Return(false, nil)
+		repository.On("RecordLoginFailure", anyContext, mock.MatchedBy(func(failure *domain.LoginFailure) bool {
+			return failure.AccountID == 1 && failure.Email == "test@example.com"
+		})).Return(1, nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityLoginFailed).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		w := login(handler)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should count a forged forwarded for against the peer address", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := &domain.Account{ID: 1, Email: "test@example.com", Password: "hashed_password"}
+		repository.On("CountLoginFailuresByIP", anyContext, "203.0.113.7", anyTime).Return(int64(0), nil)
+		repository.On("GetAccountByEmail", anyContext, "test@example.com").Return(acc, nil)
+		service.On("ComparePassword", anyContext, "wrong_password", "hashed_password").Return(false, nil)
+		repository.On("RecordLoginFailure", anyContext, mock.MatchedBy(func(failure *domain.LoginFailure) bool {
+			return failure.IPAddress == "203.0.113.7"
+		})).Return(1, nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityLoginFailed).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/login", handler.LoginAccount)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/account/login", bytes.NewBufferString(`{"email":"test@example.com","password":"wrong_password"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", "1.1.1.1")
+		req.RemoteAddr = "203.0.113.7:1234"
+		httpHelper.router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should lock the account and notify it once the threshold is reached", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := &domain.Account{ID: 1, Email: "test@example.com", Password: "hashed_password"}
+		repository.On("CountLoginFailuresByIP", anyContext, mock.Anything, anyTime).Return(int64(0), nil)
+		repository.On("GetAccountByEmail", anyContext, "test@example.com").Return(acc, nil)
+		service.On("ComparePassword", anyContext, "wrong_password", "hashed_password").Return(false, nil)
+		repository.On("RecordLoginFailure", anyContext, mock.AnythingOfType("*domain.LoginFailure")).Return(10, nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityLoginFailed).Return(nil)
+		repository.On("LockAccount", anyContext, uint(1), anyTime).Return(nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityAccountLocked).Return(nil)
+		service.On("SendAccountLockedEmail", anyContext, "test@example.com", anyTime).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		w := login(handler)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "900", w.Header().Get("Retry-After"))
+	})
+
+	t.Run("should refuse a locked account without checking the password", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		lockedUntil := time.Now().Add(5 * time.Minute)
+		acc := &domain.Account{ID: 1, Email: "test@example.com", Password: "hashed_password", LockedUntil: &lockedUntil}
+		repository.On("CountLoginFailuresByIP", anyContext, mock.Anything, anyTime).Return(int64(0), nil)
+		repository.On("GetAccountByEmail", anyContext, "test@example.com").Return(acc, nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		w := login(handler)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "300", w.Header().Get("Retry-After"))
+	})
+
+	t.Run("should refuse an ip address with too many failures", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		repository.On("CountLoginFailuresByIP", anyContext, mock.Anything, anyTime).Return(int64(50), nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		w := login(handler)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	})
+
+	t.Run("should reset the failures on a successful login", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := &domain.Account{ID: 1, Email: "test@example.com", Password: "hashed_password", FailedLoginAttempts: 2}
+		repository.On("CountLoginFailuresByIP", anyContext, mock.Anything, anyTime).Return(int64(0), nil)
+		repository.On("GetAccountByEmail", anyContext, "test@example.com").Return(acc, nil)
+		service.On("ComparePassword", anyContext, "wrong_password", "hashed_password").Return(true, nil)
+		repository.On("UnlockAccount", anyContext, uint(1)).Return(nil)
+		service.On("GenerateRefreshToken", anyContext, acc, "").Return("refresh_token", &domain.RefreshToken{AccountID: 1}, nil)
+		repository.On("IsNewDevice", anyContext, uint(1), mock.Anything, mock.Anything).Return(false, nil)
+		repository.On("CreateSession", anyContext, mock.AnythingOfType("*domain.Session")).Return(&domain.Session{ID: 5, AccountID: 1}, nil)
+		service.On("GenerateAuthToken", anyContext, acc, uint(5), uint(0)).Return("auth_token", nil)
+		repository.On("CreateRefreshToken", anyContext, mock.AnythingOfType("*domain.RefreshToken")).Return(&domain.RefreshToken{ID: 1}, nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityLogin).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		w := login(handler)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestAccountHandler_LoginAdminRestrictions(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	login := func(t *testing.T, acc *domain.Account) *httptest.ResponseRecorder {
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		repository.On("CountLoginFailuresByIP", anyContext, mock.Anything, mock.AnythingOfType("time.Time")).Return(int64(0), nil)
+		repository.On("GetAccountByEmail", anyContext, "test@example.com").Return(acc, nil)
+		service.On("ComparePassword", anyContext, "password", "hashed_password").Return(true, nil)
+
+		handler := account.NewAccountHandler(logrus.New(), service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/login", handler.LoginAccount)
+
+		reqBody := account.LoginAccountRequest{Email: "test@example.com", Password: "password"}
+		return httpHelper.MakeRequest("POST", "/account/login", reqBody, nil)
+	}
+
+	t.Run("should refuse a disabled account", func(t *testing.T) {
+		disabledAt := time.Now()
+		w := login(t, &domain.Account{ID: 1, Email: "test@example.com", Password: "hashed_password", DisabledAt: &disabledAt})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "account disabled")
+	})
+
+	t.Run("should refuse the password of an account that has to reset it", func(t *testing.T) {
+		w := login(t, &domain.Account{ID: 1, Email: "test@example.com", Password: "hashed_password", PasswordResetRequired: true})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "password reset required")
+	})
+}
@@ -0,0 +1,192 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"go_starter_api/pkg/domain"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+)
+
+const (
+	// consecutive failures before each attempt is delayed, the delay starts at
+	// defaultLoginDelayBase and doubles with every further failure
+	defaultLoginDelayAfter = 3
+	defaultLoginDelayBase  = time.Second
+
+	defaultLoginLockoutThreshold = 10
+	defaultLoginLockoutDuration  = 15 * time.Minute
+
+	// at most defaultLoginIPFailureLimit failures are accepted from an ip address per defaultLoginIPFailureWindow
+	defaultLoginIPFailureLimit  = 50
+	defaultLoginIPFailureWindow = 15 * time.Minute
+)
+
+func loginDelayAfter() int {
+	after := viper.GetInt("LOGIN_DELAY_AFTER")
+	if after <= 0 {
+		return defaultLoginDelayAfter
+	}
+	return after
+}
+
+func loginDelayBase() time.Duration {
+	base := viper.GetDuration("LOGIN_DELAY_BASE")
+	if base <= 0 {
+		return defaultLoginDelayBase
+	}
+	return base
+}
+
+func loginLockoutThreshold() int {
+	threshold := viper.GetInt("LOGIN_LOCKOUT_THRESHOLD")
+	if threshold <= 0 {
+		return defaultLoginLockoutThreshold
+	}
+	return threshold
+}
+
+func loginLockoutDuration() time.Duration {
+	duration := viper.GetDuration("LOGIN_LOCKOUT_DURATION")
+	if duration <= 0 {
+		return defaultLoginLockoutDuration
+	}
+	return duration
+}
+
+func loginIPFailureLimit() (int64, time.Duration) {
+	limit := viper.GetInt64("LOGIN_IP_FAILURE_LIMIT")
+	if limit <= 0 {
+		limit = defaultLoginIPFailureLimit
+	}
+	window := viper.GetDuration("LOGIN_IP_FAILURE_WINDOW")
+	if window <= 0 {
+		window = defaultLoginIPFailureWindow
+	}
+	return limit, window
+}
+
+// loginDelay returns how long an account has to wait after its last failure
+// before the next attempt is accepted
+func loginDelay(attempts int) time.Duration {
+	after := loginDelayAfter()
+	if attempts < after {
+		return 0
+	}
+
+	// the lockout duration caps the delay, and the shift is bounded so it cannot overflow
+	limit := loginLockoutDuration()
+	delay := loginDelayBase() << min(attempts-after, 30)
+	if delay <= 0 || delay > limit {
+		return limit
+	}
+	return delay
+}
+
+// loginRetryAfter returns how long before the account accepts a password attempt, zero when it does now
+func loginRetryAfter(acc *domain.Account, now time.Time) time.Duration {
+	if isLocked(acc, now) {
+		return acc.LockedUntil.Sub(now)
+	}
+	if acc.LastFailedLoginAt == nil {
+		return 0
+	}
+	next := acc.LastFailedLoginAt.Add(loginDelay(acc.FailedLoginAttempts))
+	if next.After(now) {
+		return next.Sub(now)
+	}
+	return 0
+}
+
+func isLocked(acc *domain.Account, now time.Time) bool {
+	return acc.LockedUntil != nil && acc.LockedUntil.After(now)
+}
+
+// tooManyAttempts answers 429 with a Retry-After header rounded up to the second
+func tooManyAttempts(c *gin.Context, retryAfter time.Duration, message string) {
+	seconds := int64(math.Ceil(retryAfter.Seconds()))
+	c.Header("Retry-After", strconv.FormatInt(seconds, 10))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": message})
+}
+
+// recordFailedLogin stores a failed password login and answers it, acc is nil when the
+// email does not belong to an account
+func (h *AccountHandler) recordFailedLogin(ctx context.Context, c *gin.Context, acc *domain.Account, email string) {
+	if h.recordLoginFailure(ctx, c, acc, email) {
+		tooManyAttempts(c, loginLockoutDuration(), "account temporarily locked")
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "invalid credentials"})
+}
+
+// recordLoginFailure stores a failed password or mfa code and locks the account once it
+// reaches the threshold, it reports whether the account is locked
+func (h *AccountHandler) recordLoginFailure(ctx context.Context, c *gin.Context, acc *domain.Account, email string) bool {
+	failure := &domain.LoginFailure{
+		Email:     email,
+		IPAddress: c.ClientIP(),
+		CreatedAt: time.Now(),
+	}
+	if acc != nil {
+		failure.AccountID = acc.ID
+	}
+
+	attempts, err := h.accountRepository.RecordLoginFailure(ctx, failure)
+	if err != nil {
+		h.logger.WithField("email", email).Errorf("failed to record login failure: %v", err)
+	}
+
+	if acc == nil {
+		return false
+	}
+
+	err = h.accountRepository.LogAccountActivity(ctx, acc.ID, domain.ActivityLoginFailed)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to log activity: %v", err)
+	}
+
+	if attempts < loginLockoutThreshold() {
+		return false
+	}
+
+	until := time.Now().Add(loginLockoutDuration())
+	err = h.accountRepository.LockAccount(ctx, acc.ID, until)
+	if err != nil {
+		if !errors.Is(err, domain.ErrAccountAlreadyLocked) {
+			h.logger.WithField("userId", acc.ID).Errorf("failed to lock account: %v", err)
+		}
+		return true
+	}
+
+	h.logger.WithField("userId", acc.ID).Warnf("account locked after %d failed logins", attempts)
+
+	err = h.accountRepository.LogAccountActivity(ctx, acc.ID, domain.ActivityAccountLocked)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to log activity: %v", err)
+	}
+
+	err = h.accountService.SendAccountLockedEmail(ctx, acc.Email, until)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to send account locked email: %v", err)
+	}
+
+	return true
+}
+
+// resetLoginFailures clears the failures of an account that completed a login, a correct
+// password alone does not clear them while the second factor is still being guessed
+func (h *AccountHandler) resetLoginFailures(ctx context.Context, acc *domain.Account) {
+	if acc.FailedLoginAttempts == 0 && acc.LockedUntil == nil {
+		return
+	}
+
+	err := h.accountRepository.UnlockAccount(ctx, acc.ID)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to reset login failures: %v", err)
+	}
+}
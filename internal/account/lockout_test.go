@@ -0,0 +1,45 @@
+package account
+
+import (
+	"go_starter_api/pkg/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginDelay(t *testing.T) {
+	assert.Equal(t, time.Duration(0), loginDelay(0))
+	assert.Equal(t, time.Duration(0), loginDelay(defaultLoginDelayAfter-1))
+	assert.Equal(t, time.Second, loginDelay(defaultLoginDelayAfter))
+	assert.Equal(t, 2*time.Second, loginDelay(defaultLoginDelayAfter+1))
+	assert.Equal(t, 4*time.Second, loginDelay(defaultLoginDelayAfter+2))
+
+	// the delay never exceeds a lockout, however many failures there are
+	assert.Equal(t, defaultLoginLockoutDuration, loginDelay(defaultLoginDelayAfter+20))
+	assert.Equal(t, defaultLoginLockoutDuration, loginDelay(1000))
+}
+
+func TestLoginRetryAfter(t *testing.T) {
+	now := time.Now()
+
+	t.Run("should accept an account without failures", func(t *testing.T) {
+		assert.Equal(t, time.Duration(0), loginRetryAfter(&domain.Account{}, now))
+	})
+
+	t.Run("should delay an account after repeated failures", func(t *testing.T) {
+		lastFailure := now.Add(-500 * time.Millisecond)
+		acc := &domain.Account{FailedLoginAttempts: defaultLoginDelayAfter + 1, LastFailedLoginAt: &lastFailure}
+
+		assert.Equal(t, 1500*time.Millisecond, loginRetryAfter(acc, now))
+		assert.Equal(t, time.Duration(0), loginRetryAfter(acc, now.Add(2*time.Second)))
+	})
+
+	t.Run("should refuse a locked account until the lock expires", func(t *testing.T) {
+		lockedUntil := now.Add(10 * time.Minute)
+		acc := &domain.Account{LockedUntil: &lockedUntil}
+
+		assert.Equal(t, 10*time.Minute, loginRetryAfter(acc, now))
+		assert.Equal(t, time.Duration(0), loginRetryAfter(acc, now.Add(11*time.Minute)))
+	})
+}
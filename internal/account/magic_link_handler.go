@@ -0,0 +1,168 @@
+package account
+
+import (
+	"errors"
+	"go_starter_api/pkg/domain"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RequestMagicLinkRequest struct {
+	Email string `json:"email"`
+}
+
+// @Summary		Request Magic Link
+// @Description	Email a single use login link. The response is the same whether or not the email belongs to an account, links are rate limited per email.
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			account	body		RequestMagicLinkRequest	true	"Email"
+// @Success		200		{object}	map[string]string
+// @Failure		400		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/magic-link [post]
+func (h *AccountHandler) RequestMagicLink(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "RequestMagicLink")
+	defer span.End()
+
+	var req RequestMagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{"message": "if the account exists a login link has been sent"}
+
+	acc, err := h.accountRepository.GetAccountByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusOK, response)
+			return
+		}
+		h.logger.Errorf("failed to get account by email: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	limit, window := magicLinkRateLimit()
+	count, err := h.accountRepository.CountOneTimeTokensSince(ctx, acc.ID, domain.TokenPurposeMagicLink, time.Now().Add(-window))
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to count magic link tokens: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	// the limit is enforced silently, a distinct response would reveal that the email has an account
+	if count >= limit {
+		h.logger.WithField("userId", acc.ID).Warnf("magic link rate limit reached")
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	token, record, err := h.accountService.GenerateOneTimeToken(ctx, acc, domain.TokenPurposeMagicLink)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to generate token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+		return
+	}
+
+	_, err = h.accountRepository.CreateOneTimeToken(ctx, record)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to store magic link token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+		return
+	}
+
+	err = h.accountService.SendMagicLinkEmail(ctx, acc.Email, token)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to send magic link email: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send magic link email"})
+		return
+	}
+
+	err = h.accountRepository.LogAccountActivity(ctx, acc.ID, domain.ActivityMagicLinkRequest)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to log activity: %v", err)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type ConsumeMagicLinkRequest struct {
+	Token      string `json:"token"`
+	DeviceName string `json:"device_name"`
+}
+
+// @Summary		Consume Magic Link
+// @Description	Log in with the token from a magic link. When mfa is enabled an mfa token is returned instead, to be completed on /api/v1/account/login/mfa.
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			account	body		ConsumeMagicLinkRequest	true	"Token"
+// @Success		200		{object}	LoginAccountResponse
+// @Failure		400		{object}	map[string]string
+// @Failure		403		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/magic-link/consume [post]
+func (h *AccountHandler) ConsumeMagicLink(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "ConsumeMagicLink")
+	defer span.End()
+
+	var req ConsumeMagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+		return
+	}
+
+	record, err := h.accountRepository.ConsumeOneTimeToken(ctx, domain.TokenPurposeMagicLink, h.accountService.HashToken(ctx, req.Token))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidOneTimeToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+			return
+		}
+		h.logger.Errorf("failed to consume magic link token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	acc, err := h.accountRepository.GetAccountByID(ctx, record.AccountID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+			return
+		}
+		h.logger.WithField("userId", record.AccountID).Errorf("failed to get account by id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// the link was delivered to the inbox, which proves the address as well as a verification link would
+	if !acc.EmailVerified {
+		now := time.Now()
+		acc.EmailVerified = true
+		acc.VerifiedAt = &now
+
+		acc, err = h.accountRepository.UpdateAccount(ctx, acc)
+		if err != nil {
+			h.logger.WithField("userId", record.AccountID).Errorf("failed to update account: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
+		err = h.accountRepository.LogAccountActivity(ctx, acc.ID, domain.ActivityEmailVerified)
+		if err != nil {
+			h.logger.WithField("userId", acc.ID).Errorf("failed to log activity: %v", err)
+		}
+	}
+
+	h.continueLogin(ctx, c, acc, req.DeviceName, domain.ActivityMagicLinkLogin)
+}
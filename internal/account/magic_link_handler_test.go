@@ -0,0 +1,192 @@
+package account_test
+
+import (
+	"context"
+	"go_starter_api/internal/account"
+	"go_starter_api/pkg/domain"
+	"net/http"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm"
+)
+
+func TestAccountHandler_RequestMagicLink(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+	anyTime := mock.AnythingOfType("time.Time")
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	t.Run("should email a login link", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := &domain.Account{ID: 1, Email: "test@example.com"}
+		record := &domain.OneTimeToken{AccountID: 1, Purpose: domain.TokenPurposeMagicLink, TokenHash: "token_hash"}
+		repository.On("GetAccountByEmail", anyContext, "test@example.com").Return(acc, nil)
+		repository.On("CountOneTimeTokensSince", anyContext, uint(1), domain.TokenPurposeMagicLink, anyTime).Return(int64(0), nil)
+		service.On("GenerateOneTimeToken", anyContext, acc, domain.TokenPurposeMagicLink).Return("login_token", record, nil)
+		repository.On("CreateOneTimeToken", anyContext, record).Return(record, nil)
+		service.On("SendMagicLinkEmail", anyContext, "test@example.com", "login_token").Return(nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityMagicLinkRequest).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/magic-link", handler.RequestMagicLink)
+
+		w := httpHelper.MakeRequest("POST", "/account/magic-link", account.RequestMagicLinkRequest{Email: "test@example.com"}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should not send a link once the rate limit is reached", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := &domain.Account{ID: 1, Email: "test@example.com"}
+		repository.On("GetAccountByEmail", anyContext, "test@example.com").Return(acc, nil)
+		repository.On("CountOneTimeTokensSince", anyContext, uint(1), domain.TokenPurposeMagicLink, anyTime).Return(int64(3), nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/magic-link", handler.RequestMagicLink)
+
+		w := httpHelper.MakeRequest("POST", "/account/magic-link", account.RequestMagicLinkRequest{Email: "test@example.com"}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		service.AssertNotCalled(t, "SendMagicLinkEmail", anyContext, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should answer the same for an unknown email", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		repository.On("GetAccountByEmail", anyContext, "unknown@example.com").Return(nil, gorm.ErrRecordNotFound)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/magic-link", handler.RequestMagicLink)
+
+		w := httpHelper.MakeRequest("POST", "/account/magic-link", account.RequestMagicLinkRequest{Email: "unknown@example.com"}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestAccountHandler_ConsumeMagicLink(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	t.Run("should log in and verify the email", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := &domain.Account{ID: 1, Email: "test@example.com"}
+		service.On("HashToken", anyContext, "login_token").Return("token_hash")
+		repository.On("ConsumeOneTimeToken", anyContext, domain.TokenPurposeMagicLink, "token_hash").Return(&domain.OneTimeToken{AccountID: 1}, nil)
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		repository.On("UpdateAccount", anyContext, acc).Return(acc, nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityEmailVerified).Return(nil)
+		service.On("GenerateRefreshToken", anyContext, acc, "").Return("refresh_token", &domain.RefreshToken{AccountID: 1}, nil)
+		repository.On("IsNewDevice", anyContext, uint(1), mock.Anything, mock.Anything).Return(false, nil)
+		repository.On("CreateSession", anyContext, mock.AnythingOfType("*domain.Session")).Return(&domain.Session{ID: 5, AccountID: 1}, nil)
+		service.On("GenerateAuthToken", anyContext, acc, uint(5), uint(0)).Return("auth_token", nil)
+		repository.On("CreateRefreshToken", anyContext, mock.AnythingOfType("*domain.RefreshToken")).Return(&domain.RefreshToken{ID: 1}, nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityMagicLinkLogin).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/magic-link/consume", handler.ConsumeMagicLink)
+
+		w := httpHelper.MakeRequest("POST", "/account/magic-link/consume", account.ConsumeMagicLinkRequest{Token: "login_token"}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response account.LoginAccountResponse
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.Equal(t, "auth_token", response.Token)
+		assert.Equal(t, "refresh_token", response.RefreshToken)
+		assert.True(t, acc.EmailVerified)
+	})
+
+	t.Run("should ask for the second factor when mfa is enabled", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := &domain.Account{ID: 1, Email: "test@example.com", EmailVerified: true, MFAEnabled: true, MFASecret: "SECRET"}
+		service.On("HashToken", anyContext, "login_token").Return("token_hash")
+		repository.On("ConsumeOneTimeToken", anyContext, domain.TokenPurposeMagicLink, "token_hash").Return(&domain.OneTimeToken{AccountID: 1}, nil)
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		service.On("GenerateMFAToken", anyContext, acc).Return("mfa_token", nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/magic-link/consume", handler.ConsumeMagicLink)
+
+		w := httpHelper.MakeRequest("POST", "/account/magic-link/consume", account.ConsumeMagicLinkRequest{Token: "login_token"}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response account.LoginAccountResponse
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.True(t, response.MFARequired)
+		assert.Equal(t, "mfa_token", response.MFAToken)
+		assert.Empty(t, response.Token)
+	})
+
+	t.Run("should refuse an account that has to reset its password", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := &domain.Account{ID: 1, Email: "test@example.com", EmailVerified: true, PasswordResetRequired: true}
+		service.On("HashToken", anyContext, "login_token").Return("token_hash")
+		repository.On("ConsumeOneTimeToken", anyContext, domain.TokenPurposeMagicLink, "token_hash").Return(&domain.OneTimeToken{AccountID: 1}, nil)
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/magic-link/consume", handler.ConsumeMagicLink)
+
+		w := httpHelper.MakeRequest("POST", "/account/magic-link/consume", account.ConsumeMagicLinkRequest{Token: "login_token"}, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "password reset required")
+	})
+
+	t.Run("should reject a used or expired link", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		service.On("HashToken", anyContext, "login_token").Return("token_hash")
+		repository.On("ConsumeOneTimeToken", anyContext, domain.TokenPurposeMagicLink, "token_hash").Return(nil, domain.ErrInvalidOneTimeToken)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/magic-link/consume", handler.ConsumeMagicLink)
+
+		w := httpHelper.MakeRequest("POST", "/account/magic-link/consume", account.ConsumeMagicLinkRequest{Token: "login_token"}, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,540 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type EnrollMFARequest struct {
+	Password string `json:"password"`
+}
+
+type EnrollMFAResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// @Summary		Enroll MFA
+// @Description	Start totp enrollment with the current password, the returned secret or otpauth uri is added to an authenticator app and confirmed with a code
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			account	body		EnrollMFARequest	true	"Current password"
+// @Success		200		{object}	EnrollMFAResponse
+// @Failure		400		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/mfa/enroll [post]
+func (h *AccountHandler) EnrollMFA(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "EnrollMFA")
+	defer span.End()
+
+	var req EnrollMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	acc, err := h.accountRepository.GetAccountByID(ctx, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to get account by id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if acc.MFAEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mfa already enabled"})
+		return
+	}
+
+	// the second factor must not be chosen by whoever holds a stolen access token
+	if acc.Password == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "set a password before enrolling mfa"})
+		return
+	}
+
+	ok, err := h.accountService.ComparePassword(ctx, req.Password, acc.Password)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to compare password: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid credentials"})
+		return
+	}
+
+	secret, err := h.accountService.GenerateTOTPSecret(ctx)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to generate totp secret: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// the secret is stored right away but only trusted once a code generated from it is confirmed
+	acc.MFASecret = secret
+
+	acc, err = h.accountRepository.UpdateAccount(ctx, acc)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to update account: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, EnrollMFAResponse{
+		Secret:     secret,
+		OTPAuthURI: h.accountService.TOTPURI(ctx, acc, secret),
+	})
+}
+
+type ConfirmMFARequest struct {
+	Code string `json:"code"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// @Summary		Confirm MFA
+// @Description	Enable mfa with a code from the enrolled authenticator. The returned recovery codes are only shown once.
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			account	body		ConfirmMFARequest	true	"Code"
+// @Success		200		{object}	RecoveryCodesResponse
+// @Failure		400		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/mfa/confirm [post]
+func (h *AccountHandler) ConfirmMFA(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "ConfirmMFA")
+	defer span.End()
+
+	var req ConfirmMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	acc, err := h.accountRepository.GetAccountByID(ctx, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to get account by id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if acc.MFAEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mfa already enabled"})
+		return
+	}
+
+	if acc.MFASecret == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mfa enrollment not started"})
+		return
+	}
+
+	step, err := h.accountService.VerifyTOTPCode(ctx, acc.MFASecret, req.Code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mfa code"})
+		return
+	}
+
+	err = h.accountRepository.ConsumeTOTPStep(ctx, acc.ID, step)
+	if err != nil {
+		if errors.Is(err, domain.ErrMFACodeReused) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mfa code"})
+			return
+		}
+		h.logger.WithField("userId", accountID).Errorf("failed to consume totp step: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	acc.MFAEnabled = true
+	acc.MFALastStep = step
+
+	acc, err = h.accountRepository.UpdateAccount(ctx, acc)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to update account: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	codes, err := h.replaceRecoveryCodes(ctx, acc.ID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to store recovery codes: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	err = h.accountRepository.LogAccountActivity(ctx, acc.ID, domain.ActivityMFAEnabled)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to log activity: %v", err)
+	}
+
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+type DisableMFARequest struct {
+	Password string `json:"password"`
+	// a totp or recovery code
+	Code string `json:"code"`
+}
+
+// @Summary		Disable MFA
+// @Description	Disable mfa, requires the password and a totp or recovery code
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			account	body		DisableMFARequest	true	"Credentials"
+// @Success		200		{object}	map[string]string
+// @Failure		400		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/mfa/disable [post]
+func (h *AccountHandler) DisableMFA(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "DisableMFA")
+	defer span.End()
+
+	var req DisableMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	acc, err := h.accountRepository.GetAccountByID(ctx, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to get account by id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if !acc.MFAEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mfa not enabled"})
+		return
+	}
+
+	ok, err := h.accountService.ComparePassword(ctx, req.Password, acc.Password)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to compare password: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid credentials"})
+		return
+	}
+
+	err = h.verifyMFACode(ctx, acc, req.Code)
+	if err != nil {
+		if isInvalidMFACode(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mfa code"})
+			return
+		}
+		h.logger.WithField("userId", accountID).Errorf("failed to verify mfa code: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	acc.MFAEnabled = false
+	acc.MFASecret = ""
+
+	_, err = h.accountRepository.UpdateAccount(ctx, acc)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to update account: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	err = h.accountRepository.DeleteRecoveryCodes(ctx, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to delete recovery codes: %v", err)
+	}
+
+	err = h.accountRepository.LogAccountActivity(ctx, accountID, domain.ActivityMFADisabled)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to log activity: %v", err)
+	}
+
+	c.JSON(
+		http.StatusOK,
+		gin.H{
+			"message": "mfa disabled",
+		},
+	)
+}
+
+type RegenerateRecoveryCodesRequest struct {
+	Code string `json:"code"`
+}
+
+// @Summary		Regenerate Recovery Codes
+// @Description	Replace every recovery code, requires a totp code. The returned codes are only shown once.
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			account	body		RegenerateRecoveryCodesRequest	true	"Code"
+// @Success		200		{object}	RecoveryCodesResponse
+// @Failure		400		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/mfa/recovery-codes [post]
+func (h *AccountHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "RegenerateRecoveryCodes")
+	defer span.End()
+
+	var req RegenerateRecoveryCodesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	acc, err := h.accountRepository.GetAccountByID(ctx, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to get account by id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if !acc.MFAEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mfa not enabled"})
+		return
+	}
+
+	// only a totp code, a recovery code cannot be used to mint new ones
+	err = h.verifyTOTPCode(ctx, acc, req.Code)
+	if err != nil {
+		if isInvalidMFACode(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mfa code"})
+			return
+		}
+		h.logger.WithField("userId", accountID).Errorf("failed to verify mfa code: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	codes, err := h.replaceRecoveryCodes(ctx, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to store recovery codes: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+type LoginMFARequest struct {
+	MFAToken string `json:"mfa_token"`
+	// a totp or recovery code
+	Code       string `json:"code"`
+	DeviceName string `json:"device_name"`
+}
+
+// @Summary		Login MFA
+// @Description	Complete the login of an account with mfa by exchanging the mfa token and a totp or recovery code for the tokens, an mfa token allows a single code
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			account	body		LoginMFARequest	true	"MFA"
+// @Success		200		{object}	LoginAccountResponse
+// @Failure		400		{object}	map[string]string
+// @Failure		401		{object}	map[string]string
+// @Failure		403		{object}	map[string]string
+// @Failure		429		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/login/mfa [post]
+func (h *AccountHandler) LoginAccountMFA(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "LoginAccountMFA")
+	defer span.End()
+
+	var req LoginMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims, err := h.validateMFAToken(ctx, req.MFAToken)
+	if err != nil {
+		if errors.Is(err, errInvalidMFAToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid mfa token"})
+			return
+		}
+		h.logger.Errorf("failed to check mfa token revocation: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	accountID := claims.AccountID
+
+	acc, err := h.accountRepository.GetAccountByID(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid mfa token"})
+			return
+		}
+		h.logger.WithField("userId", accountID).Errorf("failed to get account by id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// the account may have been disabled or required to reset its password since the mfa token was issued
+	if loginRefused(c, acc) {
+		return
+	}
+
+	// mfa was disabled since the password check, the login has to start over
+	if !acc.MFAEnabled {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid mfa token"})
+		return
+	}
+
+	// the locks reached with the codes of earlier mfa tokens hold until they end
+	if retryAfter := loginRetryAfter(acc, time.Now()); retryAfter > 0 {
+		tooManyAttempts(c, retryAfter, "too many login attempts")
+		return
+	}
+
+	// the token allows a single code, after a wrong one the login starts over with the password
+	err = h.tokenRevocationRepository.RevokeToken(ctx, claims.TokenID, accountID, claims.ExpiresAt)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to revoke mfa token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	err = h.verifyMFACode(ctx, acc, req.Code)
+	if err != nil {
+		if isInvalidMFACode(err) {
+			h.logger.WithField("userId", accountID).Errorf("invalid mfa code")
+			if h.recordLoginFailure(ctx, c, acc, acc.Email) {
+				tooManyAttempts(c, loginLockoutDuration(), "account temporarily locked")
+				return
+			}
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid mfa code"})
+			return
+		}
+		h.logger.WithField("userId", accountID).Errorf("failed to verify mfa code: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.completeLogin(ctx, c, acc, req.DeviceName, domain.ActivityLogin)
+}
+
+var errInvalidMFAToken = errors.New("invalid mfa token")
+
+// validateMFAToken returns the claims of an mfa token that was not used yet, it fails with
+// errInvalidMFAToken for tokens that are invalid, expired or already used
+func (h *AccountHandler) validateMFAToken(ctx context.Context, token string) (*domain.AuthClaims, error) {
+	claims, err := h.accountService.ValidateMFAToken(ctx, token)
+	if err != nil || claims.TokenID == "" {
+		return nil, errInvalidMFAToken
+	}
+
+	revoked, err := h.tokenRevocationRepository.IsTokenRevoked(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, errInvalidMFAToken
+	}
+
+	return claims, nil
+}
+
+// verifyMFACode accepts a totp code or an unused recovery code, which is used up
+func (h *AccountHandler) verifyMFACode(ctx context.Context, acc *domain.Account, code string) error {
+	if len(code) == totpDigits {
+		return h.verifyTOTPCode(ctx, acc, code)
+	}
+
+	err := h.accountRepository.UseRecoveryCode(ctx, acc.ID, h.accountService.HashToken(ctx, normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+
+	err = h.accountRepository.LogAccountActivity(ctx, acc.ID, domain.ActivityRecoveryCodeUsed)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to log activity: %v", err)
+	}
+
+	return nil
+}
+
+func (h *AccountHandler) verifyTOTPCode(ctx context.Context, acc *domain.Account, code string) error {
+	step, err := h.accountService.VerifyTOTPCode(ctx, acc.MFASecret, code)
+	if err != nil {
+		return err
+	}
+
+	err = h.accountRepository.ConsumeTOTPStep(ctx, acc.ID, step)
+	if err != nil {
+		return err
+	}
+
+	acc.MFALastStep = step
+	return nil
+}
+
+func isInvalidMFACode(err error) bool {
+	return errors.Is(err, domain.ErrInvalidMFACode) ||
+		errors.Is(err, domain.ErrMFACodeReused) ||
+		errors.Is(err, domain.ErrInvalidRecoveryCode)
+}
+
+// replaceRecoveryCodes stores the hashes of a new set of recovery codes and returns the codes
+func (h *AccountHandler) replaceRecoveryCodes(ctx context.Context, accountID uint) ([]string, error) {
+	codes, err := h.accountService.GenerateRecoveryCodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]domain.RecoveryCode, 0, len(codes))
+	for _, code := range codes {
+		records = append(records, domain.RecoveryCode{
+			CodeHash: h.accountService.HashToken(ctx, normalizeRecoveryCode(code)),
+		})
+	}
+
+	err = h.accountRepository.ReplaceRecoveryCodes(ctx, accountID, records)
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
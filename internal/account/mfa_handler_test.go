@@ -0,0 +1,412 @@
+package account_test
+
+import (
+	"context"
+	"go_starter_api/internal/account"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// mfaTokenClaims returns the claims of an unused mfa token of the account
+func mfaTokenClaims(accountID uint) *domain.AuthClaims {
+	return &domain.AuthClaims{AccountID: accountID, TokenID: "mfa_token_id", IssuedAt: time.Now(), ExpiresAt: time.Now().Add(5 * time.Minute)}
+}
+
+func TestAccountHandler_LoginAccountMFA(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	mfaAccount := func() *domain.Account {
+		return &domain.Account{ID: 1, Email: "test@example.com", Password: "hashed_password", MFAEnabled: true, MFASecret: "SECRET"}
+	}
+
+	t.Run("should return an mfa token instead of tokens when mfa is enabled", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := mfaAccount()
+		repository.On("CountLoginFailuresByIP", anyContext, mock.Anything, mock.AnythingOfType("time.Time")).Return(int64(0), nil)
+		repository.On("GetAccountByEmail", anyContext, "test@example.com").Return(acc, nil)
+		service.On("ComparePassword", anyContext, "password", "hashed_password").Return(true, nil)
+		service.On("GenerateMFAToken", anyContext, acc).Return("mfa_token", nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/login", handler.LoginAccount)
+
+		reqBody := account.LoginAccountRequest{Email: "test@example.com", Password: "password"}
+		w := httpHelper.MakeRequest("POST", "/account/login", reqBody, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response account.LoginAccountResponse
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.True(t, response.MFARequired)
+		assert.Equal(t, "mfa_token", response.MFAToken)
+		assert.Empty(t, response.Token)
+		assert.Empty(t, response.RefreshToken)
+	})
+
+	t.Run("should complete the login with a totp code", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := mfaAccount()
+		service.On("ValidateMFAToken", anyContext, "mfa_token").Return(mfaTokenClaims(1), nil)
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		service.On("VerifyTOTPCode", anyContext, "SECRET", "123456").Return(int64(42), nil)
+		repository.On("ConsumeTOTPStep", anyContext, uint(1), int64(42)).Return(nil)
+		service.On("GenerateRefreshToken", anyContext, acc, "").Return("refresh_token", &domain.RefreshToken{AccountID: 1}, nil)
+		repository.On("IsNewDevice", anyContext, uint(1), mock.Anything, mock.Anything).Return(false, nil)
+		repository.On("CreateSession", anyContext, mock.AnythingOfType("*domain.Session")).Return(&domain.Session{ID: 5, AccountID: 1}, nil)
+		service.On("GenerateAuthToken", anyContext, acc, uint(5), uint(0)).Return("auth_token", nil)
+		repository.On("CreateRefreshToken", anyContext, mock.AnythingOfType("*domain.RefreshToken")).Return(&domain.RefreshToken{ID: 1}, nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityLogin).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/login/mfa", handler.LoginAccountMFA)
+
+		reqBody := account.LoginMFARequest{MFAToken: "mfa_token", Code: "123456"}
+		w := httpHelper.MakeRequest("POST", "/account/login/mfa", reqBody, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response account.LoginAccountResponse
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.Equal(t, "auth_token", response.Token)
+		assert.Equal(t, "refresh_token", response.RefreshToken)
+	})
+
+	t.Run("should complete the login with a recovery code", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := mfaAccount()
+		service.On("ValidateMFAToken", anyContext, "mfa_token").Return(mfaTokenClaims(1), nil)
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		service.On("HashToken", anyContext, "abcdefghijklmnop").Return("code_hash")
+		repository.On("UseRecoveryCode", anyContext, uint(1), "code_hash").Return(nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityRecoveryCodeUsed).Return(nil)
+		service.On("GenerateRefreshToken", anyContext, acc, "").Return("refresh_token", &domain.RefreshToken{AccountID: 1}, nil)
+		repository.On("IsNewDevice", anyContext, uint(1), mock.Anything, mock.Anything).Return(false, nil)
+		repository.On("CreateSession", anyContext, mock.AnythingOfType("*domain.Session")).Return(&domain.Session{ID: 5, AccountID: 1}, nil)
+		service.On("GenerateAuthToken", anyContext, acc, uint(5), uint(0)).Return("auth_token", nil)
+		repository.On("CreateRefreshToken", anyContext, mock.AnythingOfType("*domain.RefreshToken")).Return(&domain.RefreshToken{ID: 1}, nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityLogin).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/login/mfa", handler.LoginAccountMFA)
+
+		reqBody := account.LoginMFARequest{MFAToken: "mfa_token", Code: "ABCD-EFGH-IJKL-MNOP"}
+		w := httpHelper.MakeRequest("POST", "/account/login/mfa", reqBody, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should reject a replayed totp code", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := mfaAccount()
+		service.On("ValidateMFAToken", anyContext, "mfa_token").Return(mfaTokenClaims(1), nil)
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		service.On("VerifyTOTPCode", anyContext, "SECRET", "123456").Return(int64(42), nil)
+		repository.On("ConsumeTOTPStep", anyContext, uint(1), int64(42)).Return(domain.ErrMFACodeReused)
+		repository.On("RecordLoginFailure", anyContext, mock.AnythingOfType("*domain.LoginFailure")).Return(1, nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityLoginFailed).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/login/mfa", handler.LoginAccountMFA)
+
+		reqBody := account.LoginMFARequest{MFAToken: "mfa_token", Code: "123456"}
+		w := httpHelper.MakeRequest("POST", "/account/login/mfa", reqBody, nil)
+
+		var response map[string]string
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, "invalid mfa code", response["error"])
+	})
+
+	t.Run("should record a wrong code as a login failure and use up the mfa token", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := mfaAccount()
+		service.On("ValidateMFAToken", anyContext, "mfa_token").Return(mfaTokenClaims(1), nil)
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil).Once()
+		service.On("VerifyTOTPCode", anyContext, "SECRET", "000000").Return(int64(0), domain.ErrInvalidMFACode)
+		repository.On("RecordLoginFailure", anyContext, mock.MatchedBy(func(failure *domain.LoginFailure) bool {
+			return failure.AccountID == 1 && failure.Email == "test@example.com"
+		})).Return(1, nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityLoginFailed).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/login/mfa", handler.LoginAccountMFA)
+
+		w := httpHelper.MakeRequest("POST", "/account/login/mfa", account.LoginMFARequest{MFAToken: "mfa_token", Code: "000000"}, nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid mfa code")
+
+		// the next guess needs a new mfa token, so a new correct password
+		w = httpHelper.MakeRequest("POST", "/account/login/mfa", account.LoginMFARequest{MFAToken: "mfa_token", Code: "123456"}, nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid mfa token")
+	})
+
+	t.Run("should lock the account once wrong codes reach the threshold", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := mfaAccount()
+		service.On("ValidateMFAToken", anyContext, "mfa_token").Return(mfaTokenClaims(1), nil)
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		service.On("VerifyTOTPCode", anyContext, "SECRET", "000000").Return(int64(0), domain.ErrInvalidMFACode)
+		repository.On("RecordLoginFailure", anyContext, mock.AnythingOfType("*domain.LoginFailure")).Return(10, nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityLoginFailed).Return(nil)
+		repository.On("LockAccount", anyContext, uint(1), mock.AnythingOfType("time.Time")).Return(nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityAccountLocked).Return(nil)
+		service.On("SendAccountLockedEmail", anyContext, "test@example.com", mock.AnythingOfType("time.Time")).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/login/mfa", handler.LoginAccountMFA)
+
+		w := httpHelper.MakeRequest("POST", "/account/login/mfa", account.LoginMFARequest{MFAToken: "mfa_token", Code: "000000"}, nil)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	})
+
+	t.Run("should refuse a code while the account is locked", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := mfaAccount()
+		lockedUntil := time.Now().Add(10 * time.Minute)
+		acc.FailedLoginAttempts = 10
+		acc.LockedUntil = &lockedUntil
+		service.On("ValidateMFAToken", anyContext, "mfa_token").Return(mfaTokenClaims(1), nil)
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/login/mfa", handler.LoginAccountMFA)
+
+		w := httpHelper.MakeRequest("POST", "/account/login/mfa", account.LoginMFARequest{MFAToken: "mfa_token", Code: "123456"}, nil)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	})
+
+	t.Run("should keep the login failures on a correct password until the code is verified", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := mfaAccount()
+		lastFailure := time.Now().Add(-time.Hour)
+		acc.FailedLoginAttempts = 5
+		acc.LastFailedLoginAt = &lastFailure
+		repository.On("CountLoginFailuresByIP", anyContext, mock.Anything, mock.AnythingOfType("time.Time")).Return(int64(0), nil)
+		repository.On("GetAccountByEmail", anyContext, "test@example.com").Return(acc, nil)
+		service.On("ComparePassword", anyContext, "password", "hashed_password").Return(true, nil)
+		service.On("GenerateMFAToken", anyContext, acc).Return("mfa_token", nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/login", handler.LoginAccount)
+
+		w := httpHelper.MakeRequest("POST", "/account/login", account.LoginAccountRequest{Email: "test@example.com", Password: "password"}, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		repository.AssertNotCalled(t, "UnlockAccount", anyContext, uint(1))
+	})
+
+	t.Run("should refuse an mfa token once a password reset is required", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := mfaAccount()
+		acc.PasswordResetRequired = true
+		service.On("ValidateMFAToken", anyContext, "mfa_token").Return(mfaTokenClaims(1), nil)
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/login/mfa", handler.LoginAccountMFA)
+
+		reqBody := account.LoginMFARequest{MFAToken: "mfa_token", Code: "123456"}
+		w := httpHelper.MakeRequest("POST", "/account/login/mfa", reqBody, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "password reset required")
+	})
+
+	t.Run("should reject an invalid mfa token", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		service.On("ValidateMFAToken", anyContext, "auth_token").Return(nil, account.ErrInvalidSubjectClaim)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/login/mfa", handler.LoginAccountMFA)
+
+		reqBody := account.LoginMFARequest{MFAToken: "auth_token", Code: "123456"}
+		w := httpHelper.MakeRequest("POST", "/account/login/mfa", reqBody, nil)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestAccountHandler_MFAEnrollment(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	authenticate := func(c *gin.Context) {
+		c.Set(utils.AccountIdContextKey, uint(1))
+	}
+
+	t.Run("should enroll and confirm mfa", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := &domain.Account{ID: 1, Email: "test@example.com", Password: "hashed_password"}
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		service.On("ComparePassword", anyContext, "password", "hashed_password").Return(true, nil)
+		service.On("GenerateTOTPSecret", anyContext).Return("SECRET", nil)
+		repository.On("UpdateAccount", anyContext, acc).Return(acc, nil)
+		service.On("TOTPURI", anyContext, acc, "SECRET").Return("otpauth://totp/go_starter_api:test@example.com?secret=SECRET")
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.POST("/account/mfa/enroll", authenticate, handler.EnrollMFA)
+		httpHelper.router.POST("/account/mfa/confirm", authenticate, handler.ConfirmMFA)
+
+		w := httpHelper.MakeRequest("POST", "/account/mfa/enroll", account.EnrollMFARequest{Password: "password"}, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var enrollResponse account.EnrollMFAResponse
+		httpHelper.AssertJSONResponse(t, w, &enrollResponse)
+		assert.Equal(t, "SECRET", enrollResponse.Secret)
+		assert.Equal(t, "SECRET", acc.MFASecret)
+		assert.False(t, acc.MFAEnabled)
+
+		service.On("VerifyTOTPCode", anyContext, "SECRET", "123456").Return(int64(42), nil)
+		repository.On("ConsumeTOTPStep", anyContext, uint(1), int64(42)).Return(nil)
+		service.On("GenerateRecoveryCodes", anyContext).Return([]string{"aaaa-bbbb-cccc-dddd", "eeee-ffff-gggg-hhhh"}, nil)
+		service.On("HashToken", anyContext, "aaaabbbbccccdddd").Return("hash_1")
+		service.On("HashToken", anyContext, "eeeeffffgggghhhh").Return("hash_2")
+		repository.On("ReplaceRecoveryCodes", anyContext, uint(1), []domain.RecoveryCode{{CodeHash: "hash_1"}, {CodeHash: "hash_2"}}).Return(nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityMFAEnabled).Return(nil)
+
+		w = httpHelper.MakeRequest("POST", "/account/mfa/confirm", account.ConfirmMFARequest{Code: "123456"}, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var confirmResponse account.RecoveryCodesResponse
+		httpHelper.AssertJSONResponse(t, w, &confirmResponse)
+		assert.Len(t, confirmResponse.RecoveryCodes, 2)
+		assert.True(t, acc.MFAEnabled)
+		assert.Equal(t, int64(42), acc.MFALastStep)
+	})
+
+	t.Run("should not enroll mfa without the password", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := &domain.Account{ID: 1, Email: "test@example.com", Password: "hashed_password"}
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		service.On("ComparePassword", anyContext, "wrong_password", "hashed_password").Return(false, nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.POST("/account/mfa/enroll", authenticate, handler.EnrollMFA)
+
+		w := httpHelper.MakeRequest("POST", "/account/mfa/enroll", account.EnrollMFARequest{Password: "wrong_password"}, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Empty(t, acc.MFASecret)
+	})
+
+	t.Run("should not confirm mfa with an invalid code", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := &domain.Account{ID: 1, Email: "test@example.com", MFASecret: "SECRET"}
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		service.On("VerifyTOTPCode", anyContext, "SECRET", "000000").Return(int64(0), domain.ErrInvalidMFACode)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.POST("/account/mfa/confirm", authenticate, handler.ConfirmMFA)
+
+		w := httpHelper.MakeRequest("POST", "/account/mfa/confirm", account.ConfirmMFARequest{Code: "000000"}, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.False(t, acc.MFAEnabled)
+	})
+
+	t.Run("should disable mfa with the password and a totp code", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := &domain.Account{ID: 1, Email: "test@example.com", Password: "hashed_password", MFAEnabled: true, MFASecret: "SECRET"}
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		service.On("ComparePassword", anyContext, "password", "hashed_password").Return(true, nil)
+		service.On("VerifyTOTPCode", anyContext, "SECRET", "123456").Return(int64(42), nil)
+		repository.On("ConsumeTOTPStep", anyContext, uint(1), int64(42)).Return(nil)
+		repository.On("UpdateAccount", anyContext, acc).Return(acc, nil)
+		repository.On("DeleteRecoveryCodes", anyContext, uint(1)).Return(nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityMFADisabled).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.POST("/account/mfa/disable", authenticate, handler.DisableMFA)
+
+		reqBody := account.DisableMFARequest{Password: "password", Code: "123456"}
+		w := httpHelper.MakeRequest("POST", "/account/mfa/disable", reqBody, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.False(t, acc.MFAEnabled)
+		assert.Empty(t, acc.MFASecret)
+	})
+}
@@ -1,16 +1,32 @@
 package account
 
 import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"go_starter_api/pkg/audit"
 	"go_starter_api/pkg/domain"
 	"go_starter_api/pkg/utils"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 const AuthHeaderKey = "Authorization"
 
-func AuthMiddleware(accountService domain.AccountService) gin.HandlerFunc {
+// last seen and last used are only written when older than this, to avoid a write on every request
+const sessionTouchInterval = time.Minute
+
+// AuthMiddleware accepts an access token or an api key, either way the account id and
+// the claims of the credential are put on the request, and the account is the actor of its activities
+func AuthMiddleware(
+	accountService domain.AccountService,
+	accountRepository domain.AccountRepository,
+	tokenRevocationRepository domain.TokenRevocationRepository,
+) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token := c.GetHeader(AuthHeaderKey)
 		if token == "" {
@@ -19,14 +35,175 @@ func AuthMiddleware(accountService domain.AccountService) gin.HandlerFunc {
 			return
 		}
 
-		accountID, err := accountService.ValidateAuthToken(c.Request.Context(), token)
+		if strings.HasPrefix(token, domain.APIKeyPrefix) {
+			claims, err := apiKeyClaims(c.Request.Context(), accountService, accountRepository, token)
+			if err != nil {
+				if errors.Is(err, domain.ErrInvalidAPIKey) {
+					c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+				} else {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+				}
+				c.Abort()
+				return
+			}
+
+			c.Set(utils.AccountIdContextKey, claims.AccountID)
+			c.Set(utils.AuthClaimsContextKey, claims)
+			c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), claims.AccountID))
+
+			c.Next()
+			return
+		}
+
+		claims, err := accountService.ParseAuthToken(c.Request.Context(), token)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		revoked, err := tokenRevocationRepository.IsTokenRevoked(c.Request.Context(), claims)
 		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			c.Abort()
+			return
+		}
+		if revoked {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 			c.Abort()
 			return
 		}
 
-		c.Set(utils.AccountIdContextKey, accountID)
+		if claims.SessionID != 0 {
+			session, err := accountRepository.GetSessionByID(c.Request.Context(), claims.SessionID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+				c.Abort()
+				return
+			}
+			if err != nil || session.RevokedAt != nil || session.AccountID != claims.AccountID {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+				c.Abort()
+				return
+			}
+
+			now := time.Now()
+			if now.Sub(session.LastSeenAt) > sessionTouchInterval {
+				// failing to record last seen should not fail the request
+				_ = accountRepository.TouchSession(c.Request.Context(), session.ID, now)
+			}
+		}
+
+		c.Set(utils.AccountIdContextKey, claims.AccountID)
+		c.Set(utils.AuthClaimsContextKey, claims)
+		c.Request = c.Request.WithContext(audit.WithActor(c.Request.Context(), claims.AccountID))
+
+		c.Next()
+	}
+}
+
+// apiKeyClaims authenticates an api key, it fails with ErrInvalidAPIKey for unknown, revoked
+// and expired keys and for keys of accounts that are deleted, disabled or must reset their password
+func apiKeyClaims(
+	ctx context.Context,
+	accountService domain.AccountService,
+	accountRepository domain.AccountRepository,
+	token string,
+) (*domain.AuthClaims, error) {
+	prefix, ok := apiKeyLookupPrefix(token)
+	if !ok {
+		return nil, domain.ErrInvalidAPIKey
+	}
+
+	key, err := accountRepository.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	hash := accountService.HashToken(ctx, token)
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(key.TokenHash)) != 1 {
+		return nil, domain.ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if !key.Active(now) || key.Account == nil || key.Account.DisabledAt != nil || key.Account.PasswordResetRequired {
+		return nil, domain.ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > sessionTouchInterval {
+		// failing to record last use should not fail the request
+		_ = accountRepository.TouchAPIKey(ctx, key.ID, now)
+	}
+
+	return &domain.AuthClaims{
+		AccountID: key.AccountID,
+		APIKeyID:  key.ID,
+		Scopes:    key.Scopes,
+		IssuedAt:  key.CreatedAt,
+	}, nil
+}
+
+// RejectAPIKeys refuses requests authenticated with an api key, it guards the routes managing
+// the credentials, sessions, data and organization of the account, which a scoped key must not take over.
+// It must run after AuthMiddleware
+func RejectAPIKeys() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if claims, ok := utils.AuthClaims(c); ok && claims.APIKeyID != 0 {
+			c.JSON(http.StatusForbidden, gin.H{"error": "api keys cannot manage credentials"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireScope refuses api keys limited to scopes that do not allow the scope, it guards the
+// self-service routes that no role permission covers. Access tokens and unlimited keys pass.
+// It must run after AuthMiddleware
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if claims, ok := utils.AuthClaims(c); ok && !claims.Allows(scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireVerifiedEmail refuses accounts without a verified email when the
+// EMAIL_VERIFICATION_POLICY enforces verification, it must run after AuthMiddleware
+func RequireVerifiedEmail(accountRepository domain.AccountRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if emailVerificationPolicy() == EmailVerificationPolicyNone {
+			c.Next()
+			return
+		}
+
+		accountID := c.GetUint(utils.AccountIdContextKey)
+		if accountID == 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		acc, err := accountRepository.GetAccountByID(c.Request.Context(), accountID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			c.Abort()
+			return
+		}
+
+		if !acc.EmailVerified {
+			c.JSON(http.StatusForbidden, gin.H{"error": "email not verified"})
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
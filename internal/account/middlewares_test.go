@@ -0,0 +1,327 @@
+package account_test
+
+import (
+	"context"
+	"go_starter_api/internal/account"
+	"go_starter_api/pkg/audit"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/mailer"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	viper.Set("JWT_SECRET", "test_secret_key_for_jwt_validation")
+	defer viper.Reset()
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	service := account.NewAccountService(mailer.NewMockEmailService(t), nil)
+	acc := &domain.Account{ID: 42, Email: "test@example.com"}
+
+	setup := func(repository domain.AccountRepository, revocations domain.TokenRevocationRepository) *HTTPTestHelper {
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.GET("/protected", account.AuthMiddleware(service, repository, revocations), func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"account_id": c.GetUint(utils.AccountIdContextKey)})
+		})
+		return httpHelper
+	}
+
+	t.Run("should allow a valid token", func(t *testing.T) {
+		httpHelper := setup(domain.NewMockAccountRepository(t), account.NewInMemoryTokenRevocationRepository())
+
+		token, err := service.GenerateAuthToken(context.Background(), acc, 0, 0)
+		assert.NoError(t, err)
+
+		w := httpHelper.MakeAuthenticatedRequest("GET", "/protected", nil, token)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should make the account the actor of the request", func(t *testing.T) {
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.GET("/protected", account.AuthMiddleware(service, domain.NewMockAccountRepository(t), account.NewInMemoryTokenRevocationRepository()), func(c *gin.Context) {
+			request, _ := audit.RequestFromContext(c.Request.Context())
+			c.JSON(http.StatusOK, gin.H{"actor_id": request.ActorID})
+		})
+
+		token, err := service.GenerateAuthToken(context.Background(), acc, 0, 0)
+		assert.NoError(t, err)
+
+		w := httpHelper.MakeAuthenticatedRequest("GET", "/protected", nil, token)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"actor_id":42}`, w.Body.String())
+	})
+
+	t.Run("should reject a request without token", func(t *testing.T) {
+		httpHelper := setup(domain.NewMockAccountRepository(t), account.NewInMemoryTokenRevocationRepository())
+
+		w := httpHelper.MakeRequest("GET", "/protected", nil, nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("should reject a revoked token", func(t *testing.T) {
+		revocations := account.NewInMemoryTokenRevocationRepository()
+		httpHelper := setup(domain.NewMockAccountRepository(t), revocations)
+
+		token, err := service.GenerateAuthToken(context.Background(), acc, 0, 0)
+		assert.NoError(t, err)
+
+		claims, err := service.ParseAuthToken(context.Background(), token)
+		assert.NoError(t, err)
+
+		err = revocations.RevokeToken(context.Background(), claims.TokenID, acc.ID, claims.ExpiresAt)
+		assert.NoError(t, err)
+
+		w := httpHelper.MakeAuthenticatedRequest("GET", "/protected", nil, token)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("should reject tokens issued before logout everywhere", func(t *testing.T) {
+		revocations := account.NewInMemoryTokenRevocationRepository()
+		httpHelper := setup(domain.NewMockAccountRepository(t), revocations)
+
+		token, err := service.GenerateAuthToken(context.Background(), acc, 0, 0)
+		assert.NoError(t, err)
+
+		err = revocations.RevokeAllTokens(context.Background(), acc.ID, time.Now())
+		assert.NoError(t, err)
+
+		w := httpHelper.MakeAuthenticatedRequest("GET", "/protected", nil, token)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("should allow tokens issued after logout everywhere", func(t *testing.T) {
+		revocations := account.NewInMemoryTokenRevocationRepository()
+		httpHelper := setup(domain.NewMockAccountRepository(t), revocations)
+
+		err := revocations.RevokeAllTokens(context.Background(), acc.ID, time.Now().Add(-2*time.Second))
+		assert.NoError(t, err)
+
+		token, err := service.GenerateAuthToken(context.Background(), acc, 0, 0)
+		assert.NoError(t, err)
+
+		w := httpHelper.MakeAuthenticatedRequest("GET", "/protected", nil, token)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should reject a token of a revoked session", func(t *testing.T) {
+		repository := domain.NewMockAccountRepository(t)
+		httpHelper := setup(repository, account.NewInMemoryTokenRevocationRepository())
+
+		revokedAt := time.Now()
+		repository.On("GetSessionByID", mock.Anything, uint(7)).Return(&domain.Session{ID: 7, AccountID: acc.ID, RevokedAt: &revokedAt}, nil)
+
+		token, err := service.GenerateAuthToken(context.Background(), acc, 7, 0)
+		assert.NoError(t, err)
+
+		w := httpHelper.MakeAuthenticatedRequest("GET", "/protected", nil, token)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("should update last seen of an active session", func(t *testing.T) {
+		repository := domain.NewMockAccountRepository(t)
+		httpHelper := setup(repository, account.NewInMemoryTokenRevocationRepository())
+
+		repository.On("GetSessionByID", mock.Anything, uint(7)).Return(&domain.Session{ID: 7, AccountID: acc.ID, LastSeenAt: time.Now().Add(-time.Hour)}, nil)
+		repository.On("TouchSession", mock.Anything, uint(7), mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+		token, err := service.GenerateAuthToken(context.Background(), acc, 7, 0)
+		assert.NoError(t, err)
+
+		w := httpHelper.MakeAuthenticatedRequest("GET", "/protected", nil, token)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should not update last seen of a recently seen session", func(t *testing.T) {
+		repository := domain.NewMockAccountRepository(t)
+		httpHelper := setup(repository, account.NewInMemoryTokenRevocationRepository())
+
+		repository.On("GetSessionByID", mock.Anything, uint(7)).Return(&domain.Session{ID: 7, AccountID: acc.ID, LastSeenAt: time.Now()}, nil)
+
+		token, err := service.GenerateAuthToken(context.Background(), acc, 7, 0)
+		assert.NoError(t, err)
+
+		w := httpHelper.MakeAuthenticatedRequest("GET", "/protected", nil, token)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestAuthMiddleware_APIKey(t *testing.T) {
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	service := account.NewAccountService(mailer.NewMockEmailService(t), nil)
+	acc := &domain.Account{ID: 42, Email: "test@example.com"}
+
+	setup := func(repository domain.AccountRepository) *HTTPTestHelper {
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.GET("/protected", account.AuthMiddleware(service, repository, account.NewInMemoryTokenRevocationRepository()), func(c *gin.Context) {
+			claims, _ := c.Get(utils.AuthClaimsContextKey)
+			c.JSON(http.StatusOK, gin.H{
+				"account_id": c.GetUint(utils.AccountIdContextKey),
+				"api_key_id": claims.(*domain.AuthClaims).APIKeyID,
+			})
+		})
+		return httpHelper
+	}
+
+	newKey := func(t *testing.T) (string, *domain.APIKey) {
+		key, record, err := service.GenerateAPIKey(context.Background(), acc)
+		assert.NoError(t, err)
+		record.ID = 3
+		record.CreatedAt = time.Now()
+		record.Account = acc
+		return key, record
+	}
+
+	t.Run("should authenticate the account of the key and record its use", func(t *testing.T) {
+		repository := domain.NewMockAccountRepository(t)
+		httpHelper := setup(repository)
+
+		key, record := newKey(t)
+		repository.On("GetAPIKeyByPrefix", mock.Anything, record.Prefix).Return(record, nil)
+		repository.On("TouchAPIKey", mock.Anything, uint(3), mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+		w := httpHelper.MakeAuthenticatedRequest("GET", "/protected", nil, key)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"account_id":42,"api_key_id":3}`, w.Body.String())
+	})
+
+	t.Run("should not record the use of a recently used key", func(t *testing.T) {
+		repository := domain.NewMockAccountRepository(t)
+		httpHelper := setup(repository)
+
+		key, record := newKey(t)
+		usedAt := time.Now()
+		record.LastUsedAt = &usedAt
+		repository.On("GetAPIKeyByPrefix", mock.Anything, record.Prefix).Return(record, nil)
+
+		w := httpHelper.MakeAuthenticatedRequest("GET", "/protected", nil, key)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should reject a key with the wrong secret", func(t *testing.T) {
+		repository := domain.NewMockAccountRepository(t)
+		httpHelper := setup(repository)
+
+		key, record := newKey(t)
+		repository.On("GetAPIKeyByPrefix", mock.Anything, record.Prefix).Return(record, nil)
+
+		w := httpHelper.MakeAuthenticatedRequest("GET", "/protected", nil, key[:len(key)-1]+"x")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("should reject revoked and expired keys", func(t *testing.T) {
+		past := time.Now().Add(-time.Minute)
+		for name, update := range map[string]func(*domain.APIKey){
+			"revoked": func(k *domain.APIKey) { k.RevokedAt = &past },
+			"expired": func(k *domain.APIKey) { k.ExpiresAt = &past },
+		} {
+			repository := domain.NewMockAccountRepository(t)
+			httpHelper := setup(repository)
+
+			key, record := newKey(t)
+			update(record)
+			repository.On("GetAPIKeyByPrefix", mock.Anything, record.Prefix).Return(record, nil)
+
+			w := httpHelper.MakeAuthenticatedRequest("GET", "/protected", nil, key)
+			assert.Equal(t, http.StatusUnauthorized, w.Code, name)
+		}
+	})
+
+	t.Run("should reject keys of deleted and disabled accounts", func(t *testing.T) {
+		disabledAt := time.Now()
+		for name, owner := range map[string]*domain.Account{
+			"deleted":  nil,
+			"disabled": {ID: acc.ID, DisabledAt: &disabledAt},
+		} {
+			repository := domain.NewMockAccountRepository(t)
+			httpHelper := setup(repository)
+
+			key, record := newKey(t)
+			record.Account = owner
+			repository.On("GetAPIKeyByPrefix", mock.Anything, record.Prefix).Return(record, nil)
+
+			w := httpHelper.MakeAuthenticatedRequest("GET", "/protected", nil, key)
+			assert.Equal(t, http.StatusUnauthorized, w.Code, name)
+		}
+	})
+
+	t.Run("should reject an unknown or malformed key", func(t *testing.T) {
+		repository := domain.NewMockAccountRepository(t)
+		httpHelper := setup(repository)
+
+		repository.On("GetAPIKeyByPrefix", mock.Anything, "gsa_000000000000").Return(nil, gorm.ErrRecordNotFound)
+
+		w := httpHelper.MakeAuthenticatedRequest("GET", "/protected", nil, "gsa_000000000000_secret")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w = httpHelper.MakeAuthenticatedRequest("GET", "/protected", nil, "gsa_short")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestRejectAPIKeys(t *testing.T) {
+	setup := func(claims *domain.AuthClaims) *HTTPTestHelper {
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.POST("/account/mfa/enroll", func(c *gin.Context) {
+			c.Set(utils.AccountIdContextKey, claims.AccountID)
+			c.Set(utils.AuthClaimsContextKey, claims)
+		}, account.RejectAPIKeys(), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		return httpHelper
+	}
+
+	t.Run("should let an access token through", func(t *testing.T) {
+		w := setup(&domain.AuthClaims{AccountID: 1, SessionID: 5}).MakeRequest("POST", "/account/mfa/enroll", nil, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should refuse an api key", func(t *testing.T) {
+		w := setup(&domain.AuthClaims{AccountID: 1, APIKeyID: 3}).MakeRequest("POST", "/account/mfa/enroll", nil, nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.JSONEq(t, `{"error":"api keys cannot manage credentials"}`, w.Body.String())
+	})
+}
+
+func TestRequireScope(t *testing.T) {
+	setup := func(claims *domain.AuthClaims) *HTTPTestHelper {
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.PATCH("/account/profile", func(c *gin.Context) {
+			c.Set(utils.AccountIdContextKey, claims.AccountID)
+			c.Set(utils.AuthClaimsContextKey, claims)
+		}, account.RequireScope(domain.ScopeProfileWrite), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		return httpHelper
+	}
+
+	t.Run("should let access tokens and unlimited api keys through", func(t *testing.T) {
+		w := setup(&domain.AuthClaims{AccountID: 1, SessionID: 5}).MakeRequest("PATCH", "/account/profile", nil, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = setup(&domain.AuthClaims{AccountID: 1, APIKeyID: 3}).MakeRequest("PATCH", "/account/profile", nil, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should let an api key with the scope through", func(t *testing.T) {
+		w := setup(&domain.AuthClaims{AccountID: 1, APIKeyID: 3, Scopes: []string{"profile:*"}}).MakeRequest("PATCH", "/account/profile", nil, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should refuse an api key limited to other scopes", func(t *testing.T) {
+		w := setup(&domain.AuthClaims{AccountID: 1, APIKeyID: 3, Scopes: []string{domain.PermissionAccountsRead}}).MakeRequest("PATCH", "/account/profile", nil, nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.JSONEq(t, `{"error":"forbidden"}`, w.Body.String())
+	})
+}
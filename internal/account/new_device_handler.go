@@ -0,0 +1,161 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"go_starter_api/pkg/domain"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// notifyNewDevice records the login from a new device and emails the owner a link to report
+// it, the login already succeeded so failures are only logged
+func (h *AccountHandler) notifyNewDevice(ctx context.Context, acc *domain.Account, session *domain.Session) {
+	err := h.accountRepository.LogAccountActivityDetails(ctx, acc.ID, domain.ActivityNewDeviceLogin, map[string]any{
+		"session_id": session.ID,
+		"device":     session.DeviceLabel,
+	})
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to log activity: %v", err)
+	}
+
+	token, record, err := h.accountService.GenerateOneTimeToken(ctx, acc, domain.TokenPurposeSignInReport)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to generate token: %v", err)
+		return
+	}
+	record.SessionID = session.ID
+
+	_, err = h.accountRepository.CreateOneTimeToken(ctx, record)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to store sign-in report token: %v", err)
+		return
+	}
+
+	err = h.accountService.SendNewSignInEmail(ctx, acc.Email, session, token)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to send new sign-in email: %v", err)
+	}
+}
+
+type ReportSignInRequest struct {
+	Token string `json:"token"`
+}
+
+// @Summary		Report Sign-In
+// @Description	Report a sign-in from a new device as not made by the owner, with the token of the new sign-in email.
+// @Description	The account is logged out everywhere and must reset its password, a reset link is sent to its email.
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			account	body		ReportSignInRequest	true	"Token"
+// @Success		200		{object}	map[string]string
+// @Failure		400		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/sign-in/report [post]
+func (h *AccountHandler) ReportSignIn(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "ReportSignIn")
+	defer span.End()
+
+	var req ReportSignInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+		return
+	}
+
+	record, err := h.accountRepository.ConsumeOneTimeToken(ctx, domain.TokenPurposeSignInReport, h.accountService.HashToken(ctx, req.Token))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidOneTimeToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+			return
+		}
+		h.logger.Errorf("failed to consume sign-in report token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	acc, err := h.accountRepository.GetAccountByID(ctx, record.AccountID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+			return
+		}
+		h.logger.WithField("userId", record.AccountID).Errorf("failed to get account by id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	err = h.accountRepository.RequirePasswordReset(ctx, acc.ID)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to require password reset: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	err = h.tokenRevocationRepository.RevokeAllTokens(ctx, acc.ID, time.Now())
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to revoke tokens: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	err = h.accountRepository.RevokeAllSessions(ctx, acc.ID)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to revoke sessions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// the reported device is not the owner's, its next sign-in must be reported again
+	err = h.accountRepository.ForgetSession(ctx, record.SessionID)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to forget session: %v", err)
+	}
+
+	err = h.accountRepository.LogAccountActivityDetails(ctx, acc.ID, domain.ActivitySignInReported, map[string]any{
+		"session_id": record.SessionID,
+	})
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to log activity: %v", err)
+	}
+
+	// the reset is required from here on, a failed email can be sent again from forgot password
+	err = h.accountRepository.RevokeOneTimeTokens(ctx, acc.ID, domain.TokenPurposePasswordReset)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to revoke password reset tokens: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send password reset email"})
+		return
+	}
+
+	token, resetRecord, err := h.accountService.GenerateOneTimeToken(ctx, acc, domain.TokenPurposePasswordReset)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to generate token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send password reset email"})
+		return
+	}
+
+	_, err = h.accountRepository.CreateOneTimeToken(ctx, resetRecord)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to store password reset token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send password reset email"})
+		return
+	}
+
+	err = h.accountService.SendPasswordResetEmail(ctx, acc.Email, token)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to send password reset email: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send password reset email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "logged out from all devices, a password reset link has been sent to your email"})
+}
@@ -0,0 +1,265 @@
+package account_test
+
+import (
+	"context"
+	"errors"
+	"go_starter_api/internal/account"
+	"go_starter_api/pkg/domain"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm"
+)
+
+func TestAccountHandler_NewDeviceLogin(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	const userAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36"
+
+	// expectLogin mocks a password login of the account from a device, new or not
+	expectLogin := func(service *domain.MockAccountService, repository *domain.MockAccountRepository, acc *domain.Account, newDevice bool) *domain.Session {
+		session := &domain.Session{ID: 5, AccountID: acc.ID, IPAddress: "203.0.113.7", UserAgent: userAgent, DeviceLabel: "Chrome on macOS"}
+
+		repository.On("CountLoginFailuresByIP", anyContext, mock.Anything, mock.AnythingOfType("time.Time")).Return(int64(0), nil)
+		repository.On("GetAccountByEmail", anyContext, acc.Email).Return(acc, nil)
+		service.On("ComparePassword", anyContext, "password", "hashed_password").Return(true, nil)
+		repository.On("IsNewDevice", anyContext, acc.ID, "203.0.113.7", userAgent).Return(newDevice, nil)
+		service.On("GenerateRefreshToken", anyContext, acc, "").Return("refresh_token", &domain.RefreshToken{AccountID: acc.ID}, nil)
+		repository.On("CreateSession", anyContext, mock.AnythingOfType("*domain.Session")).Return(session, nil)
+		service.On("GenerateAuthToken", anyContext, acc, uint(5), uint(0)).Return("auth_token", nil)
+		repository.On("CreateRefreshToken", anyContext, mock.AnythingOfType("*domain.RefreshToken")).Return(&domain.RefreshToken{ID: 1}, nil)
+		repository.On("LogAccountActivity", anyContext, acc.ID, domain.ActivityLogin).Return(nil)
+		return session
+	}
+
+	// login sends the password login from the peer 203.0.113.7, with the forwarded for header when set
+	login := func(handler *account.AccountHandler, forwardedFor string) *httptest.ResponseRecorder {
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/login", handler.LoginAccount)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/account/login", strings.NewReader(`{"email":"test@example.com","password":"password"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", userAgent)
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		req.RemoteAddr = "203.0.113.7:1234"
+		httpHelper.router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("should tell the owner of a login from a new device", func(t *testing.T) {
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := &domain.Account{ID: 1, Email: "test@example.com", Password: "hashed_password", EmailVerified: true}
+		session := expectLogin(service, repository, acc, true)
+		repository.On("LogAccountActivityDetails", anyContext, uint(1), domain.ActivityNewDeviceLogin, map[string]any{
+			"session_id": uint(5),
+			"device":     "Chrome on macOS",
+		}).Return(nil)
+		service.On("GenerateOneTimeToken", anyContext, acc, domain.TokenPurposeSignInReport).
+			Return("report_token", &domain.OneTimeToken{AccountID: 1, Purpose: domain.TokenPurposeSignInReport}, nil)
+		repository.On("CreateOneTimeToken", anyContext, mock.MatchedBy(func(token *domain.OneTimeToken) bool {
+			return token.SessionID == 5
+		})).Return(func(ctx context.Context, token *domain.OneTimeToken) *domain.OneTimeToken { return token }, nil)
+		service.On("SendNewSignInEmail", anyContext, "test@example.com", session, "report_token").Return(nil)
+
+		handler := account.NewAccountHandler(logrus.New(), service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		w := login(handler, "")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should not email a login from a known device", func(t *testing.T) {
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := &domain.Account{ID: 1, Email: "test@example.com", Password: "hashed_password", EmailVerified: true}
+		expectLogin(service, repository, acc, false)
+
+		handler := account.NewAccountHandler(logrus.New(), service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		w := login(handler, "")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should recognize the device by the peer address, not a forged forwarded for", func(t *testing.T) {
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		// IsNewDevice is only expected with the peer address, a forged one would fail the mock
+		acc := &domain.Account{ID: 1, Email: "test@example.com", Password: "hashed_password", EmailVerified: true}
+		expectLogin(service, repository, acc, false)
+
+		handler := account.NewAccountHandler(logrus.New(), service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		w := login(handler, "198.51.100.1")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		repository.AssertCalled(t, "CreateSession", anyContext, mock.MatchedBy(func(session *domain.Session) bool {
+			return session.IPAddress == "203.0.113.7"
+		}))
+	})
+
+	t.Run("should log in even when the email cannot be sent", func(t *testing.T) {
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := &domain.Account{ID: 1, Email: "test@example.com", Password: "hashed_password", EmailVerified: true}
+		expectLogin(service, repository, acc, true)
+		repository.On("LogAccountActivityDetails", anyContext, uint(1), domain.ActivityNewDeviceLogin, mock.Anything).Return(nil)
+		service.On("GenerateOneTimeToken", anyContext, acc, domain.TokenPurposeSignInReport).
+			Return("report_token", &domain.OneTimeToken{AccountID: 1, Purpose: domain.TokenPurposeSignInReport}, nil)
+		repository.On("CreateOneTimeToken", anyContext, mock.Anything).Return(&domain.OneTimeToken{ID: 1}, nil)
+		service.On("SendNewSignInEmail", anyContext, "test@example.com", mock.Anything, "report_token").Return(errors.New("smtp error"))
+
+		handler := account.NewAccountHandler(logrus.New(), service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		w := login(handler, "")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestAccountHandler_ReportSignIn(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	t.Run("should log the account out everywhere and require a password reset", func(t *testing.T) {
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+		revocations := account.NewInMemoryTokenRevocationRepository()
+
+		acc := &domain.Account{ID: 1, Email: "test@example.com"}
+		service.On("HashToken", anyContext, "report_token").Return("report_hash")
+		repository.On("ConsumeOneTimeToken", anyContext, domain.TokenPurposeSignInReport, "report_hash").
+			Return(&domain.OneTimeToken{AccountID: 1, Purpose: domain.TokenPurposeSignInReport, SessionID: 5}, nil)
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		repository.On("RequirePasswordReset", anyContext, uint(1)).Return(nil)
+		repository.On("RevokeAllSessions", anyContext, uint(1)).Return(nil)
+		repository.On("ForgetSession", anyContext, uint(5)).Return(nil)
+		repository.On("LogAccountActivityDetails", anyContext, uint(1), domain.ActivitySignInReported, map[string]any{"session_id": uint(5)}).Return(nil)
+		repository.On("RevokeOneTimeTokens", anyContext, uint(1), domain.TokenPurposePasswordReset).Return(nil)
+		service.On("GenerateOneTimeToken", anyContext, acc, domain.TokenPurposePasswordReset).
+			Return("reset_token", &domain.OneTimeToken{AccountID: 1, Purpose: domain.TokenPurposePasswordReset}, nil)
+		repository.On("CreateOneTimeToken", anyContext, mock.AnythingOfType("*domain.OneTimeToken")).Return(&domain.OneTimeToken{ID: 2}, nil)
+		service.On("SendPasswordResetEmail", anyContext, "test@example.com", "reset_token").Return(nil)
+
+		handler := account.NewAccountHandler(logrus.New(), service, repository, revocations)
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/sign-in/report", handler.ReportSignIn)
+
+		w := httpHelper.MakeRequest("POST", "/account/sign-in/report", account.ReportSignInRequest{Token: "report_token"}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		revoked, err := revocations.IsTokenRevoked(context.Background(), &domain.AuthClaims{AccountID: 1, TokenID: "a", IssuedAt: time.Now().Add(-time.Minute)})
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("should refuse magic link logins after a report", func(t *testing.T) {
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := &domain.Account{ID: 1, Email: "test@example.com", EmailVerified: true}
+		service.On("HashToken", anyContext, mock.AnythingOfType("string")).Return(func(_ context.Context, token string) string {
+			return "hash:" + token
+		})
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		expectSignInReport(service, repository, acc)
+		repository.On("ConsumeOneTimeToken", anyContext, domain.TokenPurposeMagicLink, "hash:login_token").Return(&domain.OneTimeToken{AccountID: 1}, nil)
+
+		handler := account.NewAccountHandler(logrus.New(), service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/sign-in/report", handler.ReportSignIn)
+		httpHelper.SetupHandler("POST", "/account/magic-link/consume", handler.ConsumeMagicLink)
+
+		reportSignIn(t, httpHelper)
+
+		w := httpHelper.MakeRequest("POST", "/account/magic-link/consume", account.ConsumeMagicLinkRequest{Token: "login_token"}, nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "password reset required")
+	})
+
+	t.Run("should refuse an invalid token", func(t *testing.T) {
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		service.On("HashToken", anyContext, "report_token").Return("report_hash")
+		repository.On("ConsumeOneTimeToken", anyContext, domain.TokenPurposeSignInReport, "report_hash").Return(nil, domain.ErrInvalidOneTimeToken)
+
+		handler := account.NewAccountHandler(logrus.New(), service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/sign-in/report", handler.ReportSignIn)
+
+		w := httpHelper.MakeRequest("POST", "/account/sign-in/report", account.ReportSignInRequest{Token: "report_token"}, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"invalid or expired token"}`, w.Body.String())
+	})
+
+	t.Run("should refuse the token of a deleted account", func(t *testing.T) {
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		service.On("HashToken", anyContext, "report_token").Return("report_hash")
+		repository.On("ConsumeOneTimeToken", anyContext, domain.TokenPurposeSignInReport, "report_hash").
+			Return(&domain.OneTimeToken{AccountID: 1, Purpose: domain.TokenPurposeSignInReport, SessionID: 5}, nil)
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(nil, gorm.ErrRecordNotFound)
+
+		handler := account.NewAccountHandler(logrus.New(), service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/sign-in/report", handler.ReportSignIn)
+
+		w := httpHelper.MakeRequest("POST", "/account/sign-in/report", account.ReportSignInRequest{Token: "report_token"}, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+// expectSignInReport mocks the report of a sign-in of acc, the account is required to reset its
+// password like the database would so the logins that follow the report see it
+func expectSignInReport(service *domain.MockAccountService, repository *domain.MockAccountRepository, acc *domain.Account) {
+	repository.On("ConsumeOneTimeToken", mock.Anything, domain.TokenPurposeSignInReport, mock.AnythingOfType("string")).
+		Return(&domain.OneTimeToken{AccountID: acc.ID, Purpose: domain.TokenPurposeSignInReport, SessionID: 5}, nil).Once()
+	repository.On("RequirePasswordReset", mock.Anything, acc.ID).Run(func(mock.Arguments) {
+		acc.PasswordResetRequired = true
+	}).Return(nil)
+	repository.On("RevokeAllSessions", mock.Anything, acc.ID).Return(nil)
+	repository.On("ForgetSession", mock.Anything, uint(5)).Return(nil)
+	repository.On("LogAccountActivityDetails", mock.Anything, acc.ID, domain.ActivitySignInReported, mock.Anything).Return(nil)
+	repository.On("RevokeOneTimeTokens", mock.Anything, acc.ID, domain.TokenPurposePasswordReset).Return(nil)
+	service.On("GenerateOneTimeToken", mock.Anything, acc, domain.TokenPurposePasswordReset).
+		Return("reset_token", &domain.OneTimeToken{AccountID: acc.ID, Purpose: domain.TokenPurposePasswordReset}, nil)
+	repository.On("CreateOneTimeToken", mock.Anything, mock.AnythingOfType("*domain.OneTimeToken")).Return(&domain.OneTimeToken{ID: 2}, nil)
+	service.On("SendPasswordResetEmail", mock.Anything, acc.Email, "reset_token").Return(nil)
+}
+
+// reportSignIn reports a sign-in with the link of the new sign-in email
+func reportSignIn(t *testing.T, httpHelper *HTTPTestHelper) {
+	w := httpHelper.MakeRequest("POST", "/account/sign-in/report", account.ReportSignInRequest{Token: "report_token"}, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,527 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/oauth"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// time the user has to go through the consent page of the provider
+const oauthStateTTL = 10 * time.Minute
+
+// OAuthHandler signs users in with external identity providers and links them to accounts,
+// the logins themselves go through the AccountHandler
+type OAuthHandler struct {
+	logger *logrus.Logger
+	tracer trace.Tracer
+
+	accountHandler *AccountHandler
+	providers      map[string]oauth.Provider
+}
+
+func NewOAuthHandler(
+	logger *logrus.Logger,
+	accountHandler *AccountHandler,
+	providers []oauth.Provider,
+) *OAuthHandler {
+	tracer := otel.Tracer("oauthHandler")
+	byName := map[string]oauth.Provider{}
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+	return &OAuthHandler{
+		logger:         logger,
+		tracer:         tracer,
+		accountHandler: accountHandler,
+		providers:      byName,
+	}
+}
+
+type ListOAuthProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
+
+type AuthorizeOAuthResponse struct {
+	// AuthorizationURL is the consent page of the provider the user is sent to, it comes
+	// back to the redirect url of the provider with a code and the state
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+type OAuthCallbackRequest struct {
+	Code       string `json:"code"`
+	State      string `json:"state"`
+	DeviceName string `json:"device_name"`
+}
+
+type IdentityResponse struct {
+	ID          uint       `json:"id"`
+	Provider    string     `json:"provider"`
+	Email       string     `json:"email"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+}
+
+type ListIdentitiesResponse struct {
+	Identities []IdentityResponse `json:"identities"`
+}
+
+func toIdentityResponse(identity *domain.Identity) IdentityResponse {
+	return IdentityResponse{
+		ID:          identity.ID,
+		Provider:    identity.Provider,
+		Email:       identity.Email,
+		CreatedAt:   identity.CreatedAt,
+		LastLoginAt: identity.LastLoginAt,
+	}
+}
+
+// @Summary		List Identity Providers
+// @Description	List the identity providers users can sign in with
+// @Tags			account
+// @Produce		json
+// @Success		200	{object}	ListOAuthProvidersResponse
+// @Router			/api/v1/account/oauth/providers [get]
+func (h *OAuthHandler) ListOAuthProviders(c *gin.Context) {
+	_, span := h.tracer.Start(c.Request.Context(), "ListOAuthProviders")
+	defer span.End()
+
+	response := ListOAuthProvidersResponse{Providers: []string{}}
+	for name := range h.providers {
+		response.Providers = append(response.Providers, name)
+	}
+	sort.Strings(response.Providers)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary		Sign In With Provider
+// @Description	Start signing in with an identity provider, the user is sent to the returned authorization url. The code and state the provider sends back are completed at /api/v1/account/oauth/{provider}/callback.
+// @Tags			account
+// @Produce		json
+// @Param			provider	path		string	true	"Provider"
+// @Success		200			{object}	AuthorizeOAuthResponse
+// @Failure		404			{object}	map[string]string
+// @Failure		500			{object}	map[string]string
+// @Failure		502			{object}	map[string]string
+// @Router			/api/v1/account/oauth/{provider}/authorize [post]
+func (h *OAuthHandler) AuthorizeOAuth(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "AuthorizeOAuth")
+	defer span.End()
+
+	h.authorize(ctx, c, 0)
+}
+
+// @Summary		Complete Sign In With Provider
+// @Description	Complete signing in with the code and state sent back by the identity provider. A linked account is logged in, an mfa token is returned instead when it has mfa enabled. Without one an account is registered for the email of the provider, unless the email already has an account which must link the provider first.
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			provider	path		string					true	"Provider"
+// @Param			callback	body		OAuthCallbackRequest	true	"Code and state"
+// @Success		200			{object}	LoginAccountResponse
+// @Failure		400			{object}	map[string]string
+// @Failure		401			{object}	map[string]string
+// @Failure		403			{object}	map[string]string
+// @Failure		404			{object}	map[string]string
+// @Failure		409			{object}	map[string]string
+// @Failure		500			{object}	map[string]string
+// @Router			/api/v1/account/oauth/{provider}/callback [post]
+func (h *OAuthHandler) CompleteOAuth(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "CompleteOAuth")
+	defer span.End()
+
+	var req OAuthCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	provider, user, ok := h.callback(ctx, c, req, 0)
+	if !ok {
+		return
+	}
+
+	accountRepository := h.accountHandler.accountRepository
+
+	identity, err := accountRepository.GetIdentity(ctx, provider.Name(), user.Subject)
+	if err == nil {
+		acc, err := accountRepository.GetAccountByID(ctx, identity.AccountID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "account not found"})
+				return
+			}
+			h.logger.WithField("userId", identity.AccountID).Errorf("failed to get account by id: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
+		err = accountRepository.RecordIdentityLogin(ctx, identity.ID, time.Now())
+		if err != nil {
+			h.logger.WithField("userId", acc.ID).Errorf("failed to record identity login: %v", err)
+		}
+
+		h.accountHandler.continueLogin(ctx, c, acc, req.DeviceName, domain.ActivityOAuthLogin)
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		h.logger.Errorf("failed to get identity: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if user.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the identity provider did not share an email"})
+		return
+	}
+
+	// linking to the account of the email would hand it to whoever controls the provider account
+	_, err = accountRepository.GetAccountByEmail(ctx, user.Email)
+	if err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "an account with this email already exists, log in and link the provider"})
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		h.logger.Errorf("failed to get account by email: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.accountHandler.Register(ctx, c, RegisterAccountRequest{Email: user.Email}, RegisterOptions{
+		EmailVerified:   user.EmailVerified,
+		WithoutPassword: true,
+		AfterCreate: func(ctx context.Context, acc *domain.Account) error {
+			return h.link(ctx, acc.ID, provider, user)
+		},
+	})
+}
+
+// @Summary		Link Provider
+// @Description	Start linking an identity provider to the authenticated user, the user is sent to the returned authorization url. The code and state the provider sends back are completed at /api/v1/account/identities/{provider}/callback.
+// @Tags			account
+// @Produce		json
+// @Param			provider	path		string	true	"Provider"
+// @Success		200			{object}	AuthorizeOAuthResponse
+// @Failure		403			{object}	map[string]string
+// @Failure		404			{object}	map[string]string
+// @Failure		500			{object}	map[string]string
+// @Failure		502			{object}	map[string]string
+// @Router			/api/v1/account/identities/{provider}/authorize [post]
+func (h *OAuthHandler) AuthorizeLinkIdentity(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "AuthorizeLinkIdentity")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.authorize(ctx, c, accountID)
+}
+
+// @Summary		Complete Link Provider
+// @Description	Link the identity provider to the authenticated user with the code and state sent back by the provider
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			provider	path		string					true	"Provider"
+// @Param			callback	body		OAuthCallbackRequest	true	"Code and state"
+// @Success		200			{object}	IdentityResponse
+// @Failure		400			{object}	map[string]string
+// @Failure		401			{object}	map[string]string
+// @Failure		404			{object}	map[string]string
+// @Failure		409			{object}	map[string]string
+// @Failure		500			{object}	map[string]string
+// @Router			/api/v1/account/identities/{provider}/callback [post]
+func (h *OAuthHandler) CompleteLinkIdentity(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "CompleteLinkIdentity")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	var req OAuthCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	provider, user, ok := h.callback(ctx, c, req, accountID)
+	if !ok {
+		return
+	}
+
+	accountRepository := h.accountHandler.accountRepository
+
+	identity, err := accountRepository.GetIdentity(ctx, provider.Name(), user.Subject)
+	if err == nil {
+		if identity.AccountID != accountID {
+			c.JSON(http.StatusConflict, gin.H{"error": "the provider account is linked to another account"})
+			return
+		}
+		c.JSON(http.StatusOK, toIdentityResponse(identity))
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		h.logger.WithField("userId", accountID).Errorf("failed to get identity: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	err = h.link(ctx, accountID, provider, user)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to link identity: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	identity, err = accountRepository.GetIdentity(ctx, provider.Name(), user.Subject)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to get identity: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toIdentityResponse(identity))
+}
+
+// @Summary		List Identities
+// @Description	List the identity providers linked to the authenticated user
+// @Tags			account
+// @Produce		json
+// @Success		200	{object}	ListIdentitiesResponse
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/account/identities [get]
+func (h *OAuthHandler) ListIdentities(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "ListIdentities")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	identities, err := h.accountHandler.accountRepository.ListIdentities(ctx, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to list identities: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	response := ListIdentitiesResponse{Identities: []IdentityResponse{}}
+	for i := range identities {
+		response.Identities = append(response.Identities, toIdentityResponse(&identities[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary		Unlink Identity
+// @Description	Unlink an identity provider from the authenticated user. The last one of an account without a password cannot be unlinked, a password must be set first.
+// @Tags			account
+// @Produce		json
+// @Param			id	path		int	true	"Identity ID"
+// @Success		200	{object}	map[string]string
+// @Failure		400	{object}	map[string]string
+// @Failure		404	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/account/identities/{id} [delete]
+func (h *OAuthHandler) UnlinkIdentity(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "UnlinkIdentity")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	identityID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid identity id"})
+		return
+	}
+
+	accountRepository := h.accountHandler.accountRepository
+
+	acc, err := accountRepository.GetAccountByID(ctx, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to get account by id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if acc.Password == "" {
+		identities, err := accountRepository.ListIdentities(ctx, accountID)
+		if err != nil {
+			h.logger.WithField("userId", accountID).Errorf("failed to list identities: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if len(identities) == 1 && identities[0].ID == uint(identityID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "set a password before unlinking the last identity provider"})
+			return
+		}
+	}
+
+	err = accountRepository.DeleteIdentity(ctx, accountID, uint(identityID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "identity not found"})
+			return
+		}
+		h.logger.WithField("userId", accountID).Errorf("failed to delete identity: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	err = accountRepository.LogAccountActivity(ctx, accountID, domain.ActivityIdentityUnlinked)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to log activity: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "identity unlinked"})
+}
+
+// authorize starts an authorization request with the provider of the route and writes its
+// consent page url, accountID is the account linking the provider and zero when signing in
+func (h *OAuthHandler) authorize(ctx context.Context, c *gin.Context, accountID uint) {
+	provider, ok := h.providers[c.Param("provider")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown identity provider"})
+		return
+	}
+
+	state, err := randomToken(32)
+	if err != nil {
+		h.logger.Errorf("failed to generate state: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	nonce, err := randomToken(16)
+	if err != nil {
+		h.logger.Errorf("failed to generate nonce: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	codeVerifier, err := oauth.GenerateCodeVerifier()
+	if err != nil {
+		h.logger.Errorf("failed to generate code verifier: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	authorizationURL, err := provider.AuthCodeURL(ctx, state, nonce, oauth.CodeChallenge(codeVerifier))
+	if err != nil {
+		h.logger.WithField("provider", provider.Name()).Errorf("failed to build authorization url: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "identity provider unavailable"})
+		return
+	}
+
+	_, err = h.accountHandler.accountRepository.CreateOAuthState(ctx, &domain.OAuthState{
+		StateHash:    h.accountHandler.accountService.HashToken(ctx, state),
+		Provider:     provider.Name(),
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		AccountID:    accountID,
+		ExpiresAt:    time.Now().Add(oauthStateTTL),
+	})
+	if err != nil {
+		h.logger.Errorf("failed to store oauth state: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, AuthorizeOAuthResponse{AuthorizationURL: authorizationURL})
+}
+
+// callback consumes the state of the request and exchanges its code for the provider user,
+// the state must have been issued to accountID. The response is written when it fails.
+func (h *OAuthHandler) callback(ctx context.Context, c *gin.Context, req OAuthCallbackRequest, accountID uint) (oauth.Provider, *oauth.UserInfo, bool) {
+	provider, ok := h.providers[c.Param("provider")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown identity provider"})
+		return nil, nil, false
+	}
+
+	if req.Code == "" || req.State == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code and state are required"})
+		return nil, nil, false
+	}
+
+	hash := h.accountHandler.accountService.HashToken(ctx, req.State)
+	state, err := h.accountHandler.accountRepository.ConsumeOAuthState(ctx, provider.Name(), hash)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidOAuthState) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired state"})
+			return nil, nil, false
+		}
+		h.logger.Errorf("failed to consume oauth state: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return nil, nil, false
+	}
+
+	// a state is only completed by the flow that started it, a link started by another
+	// account would otherwise link that account to the user's provider account
+	if state.AccountID != accountID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired state"})
+		return nil, nil, false
+	}
+
+	user, err := provider.Exchange(ctx, req.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		h.logger.WithField("provider", provider.Name()).Warnf("failed to exchange authorization code: %v", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "identity provider login failed"})
+		return nil, nil, false
+	}
+
+	return provider, user, true
+}
+
+// link records the provider user as an identity of the account
+func (h *OAuthHandler) link(ctx context.Context, accountID uint, provider oauth.Provider, user *oauth.UserInfo) error {
+	now := time.Now()
+	_, err := h.accountHandler.accountRepository.CreateIdentity(ctx, &domain.Identity{
+		AccountID:   accountID,
+		Provider:    provider.Name(),
+		Subject:     user.Subject,
+		Email:       user.Email,
+		LastLoginAt: &now,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = h.accountHandler.accountRepository.LogAccountActivity(ctx, accountID, domain.ActivityIdentityLinked)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to log activity: %v", err)
+	}
+	return nil
+}
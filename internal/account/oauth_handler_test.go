@@ -0,0 +1,266 @@
+package account_test
+
+import (
+	"context"
+	"go_starter_api/internal/account"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/oauth"
+	"go_starter_api/pkg/oauth/oauthtest"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm"
+)
+
+func TestOAuthHandler(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	authenticate := func(c *gin.Context) {
+		c.Set(utils.AccountIdContextKey, uint(1))
+		c.Set(utils.AuthClaimsContextKey, &domain.AuthClaims{AccountID: 1, SessionID: 5})
+	}
+
+	user := oauth.UserInfo{Subject: "user-1", Email: "user@example.com", EmailVerified: true, Name: "User"}
+
+	// setup returns a handler signing in with a provider of the fake issuer, the states it
+	// stores are handed back once by the repository like the database would
+	setup := func(t *testing.T) (*oauthtest.Issuer, *domain.MockAccountService, *domain.MockAccountRepository, *HTTPTestHelper) {
+		issuer := oauthtest.NewIssuer(t)
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		states := map[string]*domain.OAuthState{}
+		service.On("HashToken", anyContext, mock.AnythingOfType("string")).Return(func(_ context.Context, token string) string {
+			return "hash:" + token
+		}).Maybe()
+		repository.On("CreateOAuthState", anyContext, mock.AnythingOfType("*domain.OAuthState")).Return(func(_ context.Context, state *domain.OAuthState) *domain.OAuthState {
+			states[state.StateHash] = state
+			return state
+		}, nil).Maybe()
+		repository.On("ConsumeOAuthState", anyContext, "test", mock.AnythingOfType("string")).Return(func(_ context.Context, _ string, hash string) (*domain.OAuthState, error) {
+			state, ok := states[hash]
+			if !ok {
+				return nil, domain.ErrInvalidOAuthState
+			}
+			delete(states, hash)
+			return state, nil
+		}).Maybe()
+
+		accountHandler := account.NewAccountHandler(logrus.New(), service, repository, account.NewInMemoryTokenRevocationRepository())
+		handler := account.NewOAuthHandler(logrus.New(), accountHandler, []oauth.Provider{oauth.NewOIDCProvider(issuer.Config("test"))})
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.GET("/account/oauth/providers", handler.ListOAuthProviders)
+		httpHelper.router.POST("/account/oauth/:provider/authorize", handler.AuthorizeOAuth)
+		httpHelper.router.POST("/account/oauth/:provider/callback", handler.CompleteOAuth)
+		httpHelper.router.POST("/account/sign-in/report", accountHandler.ReportSignIn)
+		httpHelper.router.POST("/account/identities/:provider/authorize", authenticate, handler.AuthorizeLinkIdentity)
+		httpHelper.router.POST("/account/identities/:provider/callback", authenticate, handler.CompleteLinkIdentity)
+		httpHelper.router.DELETE("/account/identities/:id", authenticate, handler.UnlinkIdentity)
+
+		return issuer, service, repository, httpHelper
+	}
+
+	// authorize starts the flow at path and approves its consent page for the user
+	authorize := func(t *testing.T, issuer *oauthtest.Issuer, httpHelper *HTTPTestHelper, path string) account.OAuthCallbackRequest {
+		w := httpHelper.MakeRequest("POST", path, nil, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response account.AuthorizeOAuthResponse
+		httpHelper.AssertJSONResponse(t, w, &response)
+
+		code, state := issuer.Authorize(t, response.AuthorizationURL, user)
+		return account.OAuthCallbackRequest{Code: code, State: state}
+	}
+
+	// expectLogin mocks the tokens issued to the account
+	expectLogin := func(service *domain.MockAccountService, repository *domain.MockAccountRepository, acc *domain.Account, activity string) {
+		service.On("GenerateRefreshToken", anyContext, acc, "").Return("refresh_token", &domain.RefreshToken{AccountID: acc.ID}, nil)
+		repository.On("CreateSession", anyContext, mock.AnythingOfType("*domain.Session")).Return(&domain.Session{ID: 5, AccountID: acc.ID}, nil)
+		service.On("GenerateAuthToken", anyContext, acc, uint(5), uint(0)).Return("auth_token", nil)
+		repository.On("CreateRefreshToken", anyContext, mock.AnythingOfType("*domain.RefreshToken")).Return(&domain.RefreshToken{ID: 1}, nil)
+		// a registration is no login, the device of its session is the first
+		if activity != "" {
+			repository.On("IsNewDevice", anyContext, acc.ID, mock.Anything, mock.Anything).Return(false, nil)
+			repository.On("LogAccountActivity", anyContext, acc.ID, activity).Return(nil)
+		}
+	}
+
+	t.Run("should list the providers", func(t *testing.T) {
+		_, _, _, httpHelper := setup(t)
+
+		w := httpHelper.MakeRequest("GET", "/account/oauth/providers", nil, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response account.ListOAuthProvidersResponse
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.Equal(t, []string{"test"}, response.Providers)
+	})
+
+	t.Run("should log in the account linked to the provider user", func(t *testing.T) {
+		issuer, service, repository, httpHelper := setup(t)
+
+		acc := &domain.Account{ID: 1, Email: "user@example.com", EmailVerified: true}
+		repository.On("GetIdentity", anyContext, "test", "user-1").Return(&domain.Identity{ID: 2, AccountID: 1, Provider: "test", Subject: "user-1"}, nil)
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		repository.On("RecordIdentityLogin", anyContext, uint(2), mock.AnythingOfType("time.Time")).Return(nil)
+		expectLogin(service, repository, acc, domain.ActivityOAuthLogin)
+
+		req := authorize(t, issuer, httpHelper, "/account/oauth/test/authorize")
+		w := httpHelper.MakeRequest("POST", "/account/oauth/test/callback", req, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response account.LoginAccountResponse
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.Equal(t, "auth_token", response.Token)
+		assert.Equal(t, "refresh_token", response.RefreshToken)
+
+		// the state is single use
+		w = httpHelper.MakeRequest("POST", "/account/oauth/test/callback", req, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should refuse logins after a reported sign-in", func(t *testing.T) {
+		issuer, service, repository, httpHelper := setup(t)
+
+		acc := &domain.Account{ID: 1, Email: "user@example.com", EmailVerified: true}
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		expectSignInReport(service, repository, acc)
+		repository.On("GetIdentity", anyContext, "test", "user-1").Return(&domain.Identity{ID: 2, AccountID: 1, Provider: "test", Subject: "user-1"}, nil)
+		repository.On("RecordIdentityLogin", anyContext, uint(2), mock.AnythingOfType("time.Time")).Return(nil)
+
+		reportSignIn(t, httpHelper)
+
+		req := authorize(t, issuer, httpHelper, "/account/oauth/test/authorize")
+		w := httpHelper.MakeRequest("POST", "/account/oauth/test/callback", req, nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "password reset required")
+	})
+
+	t.Run("should register a new user without a password", func(t *testing.T) {
+		issuer, service, repository, httpHelper := setup(t)
+
+		acc := &domain.Account{ID: 1, Email: "user@example.com"}
+		repository.On("GetIdentity", anyContext, "test", "user-1").Return(nil, gorm.ErrRecordNotFound)
+		repository.On("GetAccountByEmail", anyContext, "user@example.com").Return(nil, gorm.ErrRecordNotFound)
+		repository.On("CreateAccount", anyContext, mock.MatchedBy(func(a *domain.Account) bool {
+			return a.Email == "user@example.com" && a.Password == "" && a.EmailVerified
+		})).Return(acc, nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityRegister).Return(nil)
+		repository.On("CreateIdentity", anyContext, mock.MatchedBy(func(i *domain.Identity) bool {
+			return i.AccountID == 1 && i.Provider == "test" && i.Subject == "user-1" && i.Email == "user@example.com"
+		})).Return(&domain.Identity{ID: 2}, nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityIdentityLinked).Return(nil)
+		expectLogin(service, repository, acc, "")
+
+		req := authorize(t, issuer, httpHelper, "/account/oauth/test/authorize")
+		w := httpHelper.MakeRequest("POST", "/account/oauth/test/callback", req, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response account.RegisterAccountResponse
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.Equal(t, uint(1), response.ID)
+		assert.Equal(t, "auth_token", response.Token)
+	})
+
+	t.Run("should not sign in to an existing account of the email", func(t *testing.T) {
+		issuer, _, repository, httpHelper := setup(t)
+
+		repository.On("GetIdentity", anyContext, "test", "user-1").Return(nil, gorm.ErrRecordNotFound)
+		repository.On("GetAccountByEmail", anyContext, "user@example.com").Return(&domain.Account{ID: 1, Email: "user@example.com"}, nil)
+
+		req := authorize(t, issuer, httpHelper, "/account/oauth/test/authorize")
+		w := httpHelper.MakeRequest("POST", "/account/oauth/test/callback", req, nil)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("should refuse invalid callbacks", func(t *testing.T) {
+		issuer, _, _, httpHelper := setup(t)
+
+		w := httpHelper.MakeRequest("POST", "/account/oauth/other/callback", account.OAuthCallbackRequest{Code: "code", State: "state"}, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = httpHelper.MakeRequest("POST", "/account/oauth/test/callback", account.OAuthCallbackRequest{Code: "code", State: "unknown"}, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		// a state issued to link an account cannot sign in
+		req := authorize(t, issuer, httpHelper, "/account/identities/test/authorize")
+		w = httpHelper.MakeRequest("POST", "/account/oauth/test/callback", req, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		req = authorize(t, issuer, httpHelper, "/account/oauth/test/authorize")
+		req.Code = "forged"
+		w = httpHelper.MakeRequest("POST", "/account/oauth/test/callback", req, nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("should link the provider to the authenticated account", func(t *testing.T) {
+		issuer, _, repository, httpHelper := setup(t)
+
+		repository.On("GetIdentity", anyContext, "test", "user-1").Return(nil, gorm.ErrRecordNotFound).Once()
+		repository.On("CreateIdentity", anyContext, mock.MatchedBy(func(i *domain.Identity) bool {
+			return i.AccountID == 1 && i.Subject == "user-1"
+		})).Return(&domain.Identity{ID: 2}, nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityIdentityLinked).Return(nil)
+		repository.On("GetIdentity", anyContext, "test", "user-1").Return(&domain.Identity{ID: 2, AccountID: 1, Provider: "test", Email: "user@example.com"}, nil)
+
+		req := authorize(t, issuer, httpHelper, "/account/identities/test/authorize")
+		w := httpHelper.MakeRequest("POST", "/account/identities/test/callback", req, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response account.IdentityResponse
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.Equal(t, uint(2), response.ID)
+		assert.Equal(t, "test", response.Provider)
+	})
+
+	t.Run("should not link a provider user linked to another account", func(t *testing.T) {
+		issuer, _, repository, httpHelper := setup(t)
+
+		repository.On("GetIdentity", anyContext, "test", "user-1").Return(&domain.Identity{ID: 2, AccountID: 7}, nil)
+
+		req := authorize(t, issuer, httpHelper, "/account/identities/test/authorize")
+		w := httpHelper.MakeRequest("POST", "/account/identities/test/callback", req, nil)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("should not complete a link with a sign in state", func(t *testing.T) {
+		issuer, _, _, httpHelper := setup(t)
+
+		req := authorize(t, issuer, httpHelper, "/account/oauth/test/authorize")
+		w := httpHelper.MakeRequest("POST", "/account/identities/test/callback", req, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should not unlink the last identity of an account without a password", func(t *testing.T) {
+		_, _, repository, httpHelper := setup(t)
+
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(&domain.Account{ID: 1}, nil)
+		repository.On("ListIdentities", anyContext, uint(1)).Return([]domain.Identity{{ID: 2, AccountID: 1}}, nil)
+
+		w := httpHelper.MakeRequest("DELETE", "/account/identities/2", nil, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should unlink an identity", func(t *testing.T) {
+		_, _, repository, httpHelper := setup(t)
+
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(&domain.Account{ID: 1, Password: "hash"}, nil)
+		repository.On("DeleteIdentity", anyContext, uint(1), uint(2)).Return(nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityIdentityUnlinked).Return(nil)
+
+		w := httpHelper.MakeRequest("DELETE", "/account/identities/2", nil, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
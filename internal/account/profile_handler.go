@@ -0,0 +1,282 @@
+package account
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"maps"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+	// the runtime image has no zoneinfo, time zones are validated against the embedded database
+	_ "time/tzdata"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+const (
+	maxDisplayNameLength = 64
+	maxAvatarURLLength   = 2048
+	// size of the encoded metadata once the patch is applied
+	maxProfileMetadataSize = 4096
+)
+
+// UpdateProfileRequest documents the fields of a profile merge patch, a field left out is unchanged
+// and a null field is reset, members of metadata are merged the same way
+type UpdateProfileRequest struct {
+	DisplayName *string        `json:"display_name"`
+	AvatarURL   *string        `json:"avatar_url"`
+	Locale      *string        `json:"locale"`
+	Timezone    *string        `json:"timezone"`
+	Metadata    map[string]any `json:"metadata"`
+}
+
+// @Summary		Update Profile
+// @Description	Update the profile of the authenticated user with a JSON merge patch (RFC 7396). Fields left out are unchanged, null fields are reset and metadata members are merged the same way.
+// @Description	The patch must be an object. An uploaded avatar is replaced or deleted with the avatar endpoints, not through avatar_url.
+// @Tags			account
+// @Accept			json
+// @Accept			application/merge-patch+json
+// @Produce		json
+// @Param			account	body		UpdateProfileRequest	true	"Merge patch"
+// @Success		200		{object}	GetProfileResponse
+// @Failure		400		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/profile [patch]
+func (h *AccountHandler) UpdateProfile(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "UpdateProfile")
+	defer span.End()
+
+	// a patch that is not an object would replace the whole profile, which is not supported
+	var patch map[string]json.RawMessage
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "patch must be an object"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if patch == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "patch must be an object"})
+		return
+	}
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	acc, err := h.accountRepository.GetAccountByID(ctx, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to get account by id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	profile, err := applyProfilePatch(acc.AccountProfile, patch)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	changed := changedProfileFields(acc.AccountProfile, profile)
+	if len(changed) == 0 {
+		c.JSON(http.StatusOK, newGetProfileResponse(acc))
+		return
+	}
+
+	// the uploaded object would be left behind, it is removed with the avatar endpoint first
+	if acc.AvatarKey != "" && slices.Contains(changed, "avatar_url") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "delete the uploaded avatar before setting an avatar url"})
+		return
+	}
+
+	err = h.accountRepository.UpdateAccountProfile(ctx, accountID, profile)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to update profile: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	acc.AccountProfile = profile
+	acc.UpdatedAt = time.Now()
+
+	err = h.accountRepository.LogAccountActivityDetails(ctx, accountID, domain.ActivityUpdate, map[string]any{"fields": changed})
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to log activity: %v", err)
+	}
+
+	c.JSON(http.StatusOK, newGetProfileResponse(acc))
+}
+
+// applyProfilePatch returns the profile with the merge patch applied, fields are validated
+// and normalized so that equivalent values compare equal
+func applyProfilePatch(profile domain.AccountProfile, patch map[string]json.RawMessage) (domain.AccountProfile, error) {
+	for _, field := range slices.Sorted(maps.Keys(patch)) {
+		raw := patch[field]
+		var err error
+		switch field {
+		case "display_name":
+			profile.DisplayName, err = patchString(field, raw, normalizeDisplayName)
+		case "avatar_url":
+			profile.AvatarURL, err = patchString(field, raw, normalizeAvatarURL)
+		case "locale":
+			profile.Locale, err = patchString(field, raw, normalizeLocale)
+		case "timezone":
+			profile.Timezone, err = patchString(field, raw, normalizeTimezone)
+		case "metadata":
+			profile.Metadata, err = patchMetadata(profile.Metadata, raw)
+		default:
+			return profile, fmt.Errorf("%s cannot be updated", field)
+		}
+		if err != nil {
+			return profile, err
+		}
+	}
+	return profile, nil
+}
+
+// patchString decodes a string field of the patch, null resets it
+func patchString(field string, raw json.RawMessage, normalize func(string) (string, error)) (string, error) {
+	var value *string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", fmt.Errorf("%s must be a string", field)
+	}
+	if value == nil {
+		return "", nil
+	}
+	normalized, err := normalize(strings.TrimSpace(*value))
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", field, err)
+	}
+	return normalized, nil
+}
+
+func normalizeDisplayName(name string) (string, error) {
+	if utf8.RuneCountInString(name) > maxDisplayNameLength {
+		return "", fmt.Errorf("at most %d characters", maxDisplayNameLength)
+	}
+	if strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return "", errors.New("control characters are not allowed")
+	}
+	return name, nil
+}
+
+func normalizeAvatarURL(rawURL string) (string, error) {
+	if rawURL == "" {
+		return "", nil
+	}
+	if len(rawURL) > maxAvatarURLLength {
+		return "", fmt.Errorf("at most %d characters", maxAvatarURLLength)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", errors.New("must be an absolute http or https url")
+	}
+	return u.String(), nil
+}
+
+func normalizeLocale(locale string) (string, error) {
+	if locale == "" {
+		return "", nil
+	}
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return "", errors.New("must be a BCP 47 language tag")
+	}
+	return tag.String(), nil
+}
+
+func normalizeTimezone(timezone string) (string, error) {
+	if timezone == "" {
+		return "", nil
+	}
+	// Local is the zone of the server, not a zone of the user
+	if timezone == "Local" {
+		return "", errors.New("must be an IANA time zone name")
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return "", errors.New("must be an IANA time zone name")
+	}
+	return location.String(), nil
+}
+
+// patchMetadata merges the metadata patch into the current metadata, null resets it
+func patchMetadata(metadata map[string]any, raw json.RawMessage) (map[string]any, error) {
+	var patch map[string]any
+	if err := json.Unmarshal(raw, &patch); err != nil {
+		return nil, errors.New("metadata must be an object")
+	}
+	if patch == nil {
+		return nil, nil
+	}
+	merged := mergePatch(metadata, patch)
+	if len(merged) == 0 {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(merged)
+	if err != nil {
+		return nil, errors.New("invalid metadata")
+	}
+	if len(encoded) > maxProfileMetadataSize {
+		return nil, fmt.Errorf("metadata must be at most %d bytes", maxProfileMetadataSize)
+	}
+	return merged, nil
+}
+
+// mergePatch applies a JSON merge patch (RFC 7396) to a copy of the target,
+// null members remove keys and objects are merged recursively
+func mergePatch(target map[string]any, patch map[string]any) map[string]any {
+	result := maps.Clone(target)
+	if result == nil {
+		result = make(map[string]any, len(patch))
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		if object, ok := value.(map[string]any); ok {
+			current, _ := result[key].(map[string]any)
+			result[key] = mergePatch(current, object)
+			continue
+		}
+		result[key] = value
+	}
+	return result
+}
+
+// changedProfileFields returns the json names of the fields that differ, in field order
+func changedProfileFields(current domain.AccountProfile, next domain.AccountProfile) []string {
+	var changed []string
+	if current.DisplayName != next.DisplayName {
+		changed = append(changed, "display_name")
+	}
+	if current.AvatarURL != next.AvatarURL {
+		changed = append(changed, "avatar_url")
+	}
+	if current.Locale != next.Locale {
+		changed = append(changed, "locale")
+	}
+	if current.Timezone != next.Timezone {
+		changed = append(changed, "timezone")
+	}
+	// an empty object and no metadata are the same
+	if (len(current.Metadata) > 0 || len(next.Metadata) > 0) && !reflect.DeepEqual(current.Metadata, next.Metadata) {
+		changed = append(changed, "metadata")
+	}
+	return changed
+}
@@ -0,0 +1,187 @@
+package account_test
+
+import (
+	"context"
+	"encoding/json"
+	"go_starter_api/internal/account"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestAccountHandler_UpdateProfile(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	authenticate := func(c *gin.Context) {
+		c.Set(utils.AccountIdContextKey, uint(1))
+	}
+
+	current := func() *domain.Account {
+		return &domain.Account{
+			ID:    1,
+			Email: "test@example.com",
+			AccountProfile: domain.AccountProfile{
+				DisplayName: "Test",
+				Locale:      "en",
+				Metadata:    map[string]any{"theme": "dark", "editor": map[string]any{"tabs": float64(4), "wrap": true}},
+			},
+		}
+	}
+
+	t.Run("should merge the patch into the profile", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		expected := domain.AccountProfile{
+			DisplayName: "Test",
+			Locale:      "pt-BR",
+			Timezone:    "Europe/Paris",
+			Metadata:    map[string]any{"editor": map[string]any{"tabs": float64(2), "wrap": true}, "beta": true},
+		}
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(current(), nil)
+		repository.On("UpdateAccountProfile", anyContext, uint(1), expected).Return(nil)
+		repository.On("LogAccountActivityDetails", anyContext, uint(1), domain.ActivityUpdate, map[string]any{
+			"fields": []string{"locale", "timezone", "metadata"},
+		}).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.PATCH("/account/profile", authenticate, handler.UpdateProfile)
+
+		w := httpHelper.MakeRequest("PATCH", "/account/profile", map[string]any{
+			"display_name": " Test ",
+			"locale":       "pt-br",
+			"timezone":     "Europe/Paris",
+			"metadata":     map[string]any{"theme": nil, "editor": map[string]any{"tabs": 2}, "beta": true},
+		}, map[string]string{"Content-Type": "application/merge-patch+json"})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response account.GetProfileResponse
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.Equal(t, "pt-BR", response.Locale)
+		assert.Equal(t, expected.Metadata, response.Metadata)
+	})
+
+	t.Run("should reset null fields", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(current(), nil)
+		repository.On("UpdateAccountProfile", anyContext, uint(1), domain.AccountProfile{Locale: "en"}).Return(nil)
+		repository.On("LogAccountActivityDetails", anyContext, uint(1), domain.ActivityUpdate, map[string]any{
+			"fields": []string{"display_name", "metadata"},
+		}).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.PATCH("/account/profile", authenticate, handler.UpdateProfile)
+
+		w := httpHelper.MakeRequest("PATCH", "/account/profile", map[string]any{"display_name": nil, "metadata": nil}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should not update an unchanged profile", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(current(), nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.PATCH("/account/profile", authenticate, handler.UpdateProfile)
+
+		w := httpHelper.MakeRequest("PATCH", "/account/profile", map[string]any{
+			"locale":   "EN",
+			"metadata": map[string]any{"theme": "dark", "unknown": nil},
+		}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		repository.AssertNotCalled(t, "UpdateAccountProfile", anyContext, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should refuse invalid patches", func(t *testing.T) {
+		for patch, expected := range map[string]string{
+			`{"email":"other@example.com"}`:       "email cannot be updated",
+			`{"display_name":42}`:                 "display_name must be a string",
+			`{"display_name":"a\u0000b"}`:         "invalid display_name: control characters are not allowed",
+			`{"avatar_url":"javascript:alert()"}`: "invalid avatar_url: must be an absolute http or https url",
+			`{"locale":"not a locale"}`:           "invalid locale: must be a BCP 47 language tag",
+			`{"timezone":"Mars/Olympus_Mons"}`:    "invalid timezone: must be an IANA time zone name",
+			`{"timezone":"Local"}`:                "invalid timezone: must be an IANA time zone name",
+			`{"metadata":[1,2]}`:                  "metadata must be an object",
+		} {
+			logger := logrus.New()
+			service := domain.NewMockAccountService(t)
+			repository := domain.NewMockAccountRepository(t)
+
+			repository.On("GetAccountByID", anyContext, uint(1)).Return(current(), nil)
+
+			handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+			httpHelper := NewHTTPTestHelper()
+			httpHelper.router.PATCH("/account/profile", authenticate, handler.UpdateProfile)
+
+			w := httpHelper.MakeRequest("PATCH", "/account/profile", json.RawMessage(patch), nil)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, patch)
+			assert.JSONEq(t, `{"error":"`+expected+`"}`, w.Body.String(), patch)
+		}
+	})
+
+	t.Run("should refuse patches that are not an object", func(t *testing.T) {
+		for _, patch := range []string{`null`, `[{"locale":"fr"}]`, `"fr"`, `42`} {
+			logger := logrus.New()
+			service := domain.NewMockAccountService(t)
+			repository := domain.NewMockAccountRepository(t)
+
+			handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+			httpHelper := NewHTTPTestHelper()
+			httpHelper.router.PATCH("/account/profile", authenticate, handler.UpdateProfile)
+
+			w := httpHelper.MakeRequest("PATCH", "/account/profile", json.RawMessage(patch), nil)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, patch)
+			assert.JSONEq(t, `{"error":"patch must be an object"}`, w.Body.String(), patch)
+		}
+	})
+
+	t.Run("should refuse an avatar url over an uploaded avatar", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := current()
+		acc.AvatarKey = "avatars/1/avatar.png"
+		acc.AvatarURL = "http://localhost:8080/api/v1/avatars/1/avatar.png"
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.PATCH("/account/profile", authenticate, handler.UpdateProfile)
+
+		w := httpHelper.MakeRequest("PATCH", "/account/profile", map[string]any{"avatar_url": "https://example.com/me.png"}, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"delete the uploaded avatar before setting an avatar url"}`, w.Body.String())
+	})
+}
@@ -0,0 +1,117 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/storage"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultAccountPurgeInterval = time.Hour
+	// accounts listed per query while purging
+	accountPurgeBatchSize = 100
+)
+
+// Purger hard deletes the accounts whose deletion grace period is over, with their uploads
+type Purger struct {
+	logger *logrus.Logger
+	tracer trace.Tracer
+
+	accountRepository domain.AccountRepository
+	storage           storage.Storage
+}
+
+func NewPurger(logger *logrus.Logger, accountRepository domain.AccountRepository, storage storage.Storage) *Purger {
+	return &Purger{
+		logger:            logger,
+		tracer:            otel.Tracer("accountPurger"),
+		accountRepository: accountRepository,
+		storage:           storage,
+	}
+}
+
+// Run purges the due accounts every ACCOUNT_PURGE_INTERVAL until the context is done
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(accountPurgeInterval())
+	defer ticker.Stop()
+
+	for {
+		purged, err := p.PurgeDue(ctx, time.Now())
+		if err != nil && ctx.Err() == nil {
+			p.logger.Errorf("failed to purge accounts: %v", err)
+		}
+		if purged > 0 {
+			p.logger.Infof("purged %d accounts", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeDue purges every account whose purge is due at the given time and returns how many were purged
+func (p *Purger) PurgeDue(ctx context.Context, now time.Time) (int, error) {
+	ctx, span := p.tracer.Start(ctx, "PurgeDue")
+	defer span.End()
+
+	purged := 0
+	for {
+		ids, err := p.accountRepository.ListAccountsDueForPurge(ctx, now, accountPurgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+
+		for _, id := range ids {
+			acc, err := p.accountRepository.GetAccountByIDWithDeleted(ctx, id)
+			// purged by another instance since it was listed
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				return purged, err
+			}
+
+			err = p.accountRepository.PurgeAccount(ctx, id, now)
+			// restored since it was listed
+			if errors.Is(err, domain.ErrAccountNotPurgeable) {
+				continue
+			}
+			if err != nil {
+				return purged, err
+			}
+			p.logger.WithField("userId", id).Infof("account purged")
+			purged++
+
+			// the account is gone, a failed delete only leaves an object nothing refers to
+			if acc.AvatarKey != "" {
+				err = p.storage.Delete(ctx, acc.AvatarKey)
+				if err != nil {
+					p.logger.WithField("userId", id).Errorf("failed to delete avatar: %v", err)
+				}
+			}
+		}
+
+		if len(ids) < accountPurgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+func accountPurgeInterval() time.Duration {
+	interval := viper.GetDuration("ACCOUNT_PURGE_INTERVAL")
+	if interval <= 0 {
+		return defaultAccountPurgeInterval
+	}
+	return interval
+}
@@ -0,0 +1,70 @@
+package account_test
+
+import (
+	"context"
+	"errors"
+	"go_starter_api/internal/account"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/storage"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestPurger_PurgeDue(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	now := time.Now()
+
+	newStorage := func(t *testing.T) storage.Storage {
+		store, err := storage.NewFileSystem(storage.FileSystemConfig{Dir: t.TempDir(), Secret: "secret"})
+		require.NoError(t, err)
+		return store
+	}
+
+	t.Run("should purge the due accounts and skip the restored ones", func(t *testing.T) {
+		repository := domain.NewMockAccountRepository(t)
+		store := newStorage(t)
+
+		require.NoError(t, store.Put(context.Background(), "avatars/1/a.png", strings.NewReader("image"), 5, "image/png"))
+
+		repository.On("ListAccountsDueForPurge", anyContext, now, mock.AnythingOfType("int")).Return([]uint{1, 2, 3}, nil)
+		repository.On("GetAccountByIDWithDeleted", anyContext, uint(1)).Return(&domain.Account{ID: 1, AvatarKey: "avatars/1/a.png"}, nil)
+		repository.On("GetAccountByIDWithDeleted", anyContext, uint(2)).Return(&domain.Account{ID: 2}, nil)
+		repository.On("GetAccountByIDWithDeleted", anyContext, uint(3)).Return(&domain.Account{ID: 3}, nil)
+		repository.On("PurgeAccount", anyContext, uint(1), now).Return(nil)
+		repository.On("PurgeAccount", anyContext, uint(2), now).Return(domain.ErrAccountNotPurgeable)
+		repository.On("PurgeAccount", anyContext, uint(3), now).Return(nil)
+
+		purged, err := account.NewPurger(logrus.New(), repository, store).PurgeDue(context.Background(), now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, purged)
+		_, err = store.Get(context.Background(), "avatars/1/a.png")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("should stop at the first failure", func(t *testing.T) {
+		repository := domain.NewMockAccountRepository(t)
+
+		repository.On("ListAccountsDueForPurge", anyContext, now, mock.AnythingOfType("int")).Return([]uint{1, 2}, nil)
+		repository.On("GetAccountByIDWithDeleted", anyContext, uint(1)).Return(&domain.Account{ID: 1}, nil)
+		repository.On("PurgeAccount", anyContext, uint(1), now).Return(errors.New("db error"))
+
+		purged, err := account.NewPurger(logrus.New(), repository, newStorage(t)).PurgeDue(context.Background(), now)
+
+		assert.Error(t, err)
+		assert.Equal(t, 0, purged)
+		repository.AssertNotCalled(t, "PurgeAccount", anyContext, uint(2), now)
+	})
+}
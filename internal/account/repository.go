@@ -2,12 +2,18 @@ package account
 
 import (
 	"context"
+	"errors"
+	"go_starter_api/pkg/audit"
 	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/tenant"
+	"strings"
+	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type AccountRepo struct {
@@ -65,14 +71,1179 @@ func (r *AccountRepo) UpdateAccount(ctx context.Context, account *domain.Account
 	return account, nil
 }
 
+// UpdateAccountPassword changes the password and revokes every outstanding password reset token,
+// every route changing a password must go through it so an old reset link cannot undo the change
+func (r *AccountRepo) UpdateAccountPassword(ctx context.Context, accountID uint, passwordHash string) error {
+	_, span := r.trace.Start(ctx, "UpdateAccountPassword")
+	defer span.End()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&domain.Account{}).
+			Where("id = ?", accountID).
+			Updates(map[string]interface{}{
+				"password":                passwordHash,
+				"password_reset_required": false,
+			}).Error
+		if err != nil {
+			return err
+		}
+		return revokeOneTimeTokens(tx, accountID, domain.TokenPurposePasswordReset)
+	})
+}
+
+// UpdateAccountProfile replaces the profile fields of the account, leaving every other column untouched
+func (r *AccountRepo) UpdateAccountProfile(ctx context.Context, accountID uint, profile domain.AccountProfile) error {
+	_, span := r.trace.Start(ctx, "UpdateAccountProfile")
+	defer span.End()
+	return r.db.Model(&domain.Account{ID: accountID}).
+		Select("display_name", "avatar_url", "locale", "timezone", "metadata").
+		Updates(&domain.Account{AccountProfile: profile}).Error
+}
+
+func (r *AccountRepo) SetAccountAvatar(ctx context.Context, accountID uint, key string, avatarURL string) error {
+	_, span := r.trace.Start(ctx, "SetAccountAvatar")
+	defer span.End()
+	return r.db.Model(&domain.Account{ID: accountID}).
+		Select("avatar_key", "avatar_url").
+		Updates(&domain.Account{AvatarKey: key, AccountProfile: domain.AccountProfile{AvatarURL: avatarURL}}).Error
+}
+
 func (r *AccountRepo) DeleteAccount(ctx context.Context, id uint) error {
 	_, span := r.trace.Start(ctx, "DeleteAccount")
 	defer span.End()
 	return r.db.Delete(&domain.Account{}, id).Error
 }
 
+func (r *AccountRepo) ListAccounts(ctx context.Context, filter domain.AccountFilter) ([]domain.Account, int64, error) {
+	_, span := r.trace.Start(ctx, "ListAccounts")
+	defer span.End()
+
+	query := r.db.Model(&domain.Account{})
+	if filter.Email != "" {
+		query = query.Where("LOWER(email) LIKE ?", "%"+strings.ToLower(filter.Email)+"%")
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *filter.CreatedBefore)
+	}
+
+	now := time.Now()
+	switch filter.Status {
+	case domain.AccountStatusActive:
+		query = query.Where("disabled_at IS NULL AND (locked_until IS NULL OR locked_until <= ?)", now)
+	case domain.AccountStatusLocked:
+		query = query.Where("disabled_at IS NULL AND locked_until > ?", now)
+	case domain.AccountStatusDisabled:
+		query = query.Where("disabled_at IS NOT NULL")
+	case domain.AccountStatusDeleted:
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var accounts []domain.Account
+	err := query.Order("id").Offset(filter.Offset).Limit(filter.Limit).Find(&accounts).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return accounts, total, nil
+}
+
+func (r *AccountRepo) GetAccountByIDWithDeleted(ctx context.Context, id uint) (*domain.Account, error) {
+	_, span := r.trace.Start(ctx, "GetAccountByIDWithDeleted")
+	defer span.End()
+	var account domain.Account
+	err := r.db.Unscoped().First(&account, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// RestoreAccount undeletes the account, cancels its purge and revokes its restore links,
+// an old link must not undo a later deletion by an administrator
+func (r *AccountRepo) RestoreAccount(ctx context.Context, id uint) error {
+	_, span := r.trace.Start(ctx, "RestoreAccount")
+	defer span.End()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&domain.Account{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"deleted_at": nil,
+				"purge_at":   nil,
+			}).Error
+		if err != nil {
+			return err
+		}
+		return revokeOneTimeTokens(tx, id, domain.TokenPurposeAccountRestore)
+	})
+}
+
+func (r *AccountRepo) ScheduleAccountDeletion(ctx context.Context, id uint, purgeAt time.Time) error {
+	_, span := r.trace.Start(ctx, "ScheduleAccountDeletion")
+	defer span.End()
+	return r.db.Model(&domain.Account{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"deleted_at": time.Now(),
+			"purge_at":   purgeAt,
+		}).Error
+}
+
+func (r *AccountRepo) ListAccountsDueForPurge(ctx context.Context, now time.Time, limit int) ([]uint, error) {
+	_, span := r.trace.Start(ctx, "ListAccountsDueForPurge")
+	defer span.End()
+	var ids []uint
+	err := r.db.Unscoped().Model(&domain.Account{}).
+		Where("deleted_at IS NOT NULL AND purge_at <= ?", now).
+		Order("purge_at").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// PurgeAccount hard deletes the account with every record it owns and anonymizes its activities,
+// its organizations keep an owner. The account row is locked first so a concurrent restore either
+// wins or finds nothing to restore
+func (r *AccountRepo) PurgeAccount(ctx context.Context, id uint, now time.Time) error {
+	_, span := r.trace.Start(ctx, "PurgeAccount")
+	defer span.End()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var account domain.Account
+		err := tx.Unscoped().
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND deleted_at IS NOT NULL AND purge_at <= ?", id, now).
+			First(&account).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrAccountNotPurgeable
+		}
+		if err != nil {
+			return err
+		}
+
+		// the organizations it is the last owner of are handed over before its memberships go
+		err = handOverOrganizations(tx, id)
+		if err != nil {
+			return err
+		}
+
+		owned := []interface{}{
+			&domain.RefreshToken{},
+			&domain.Session{},
+			&domain.RecoveryCode{},
+			&domain.LoginFailure{},
+			&domain.OneTimeToken{},
+			&domain.APIKey{},
+			&domain.Identity{},
+			&domain.OAuthState{},
+			&domain.AuthorizationCode{},
+			&domain.OAuthConsent{},
+			&domain.WebAuthnCredential{},
+			&domain.WebAuthnChallenge{},
+			&domain.RevokedToken{},
+			&domain.AccountTokenRevocation{},
+			&domain.Membership{},
+			&domain.AccountRole{},
+			&domain.DataExport{},
+		}
+		for _, model := range owned {
+			err = tx.Unscoped().Where("account_id = ?", id).Delete(model).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		// invitations stay with their organization, only the link to the account goes
+		err = tenant.WithoutTenant(tx).Unscoped().Model(&domain.Invitation{}).
+			Where("account_id = ?", id).
+			Update("account_id", nil).Error
+		if err != nil {
+			return err
+		}
+
+		// the history is kept for the records of the application, without the account it belonged
+		// to nor where it connected from, the only change ever made to the append only history
+		err = audit.Rewrite(tx).Unscoped().Model(&domain.AccountActivity{}).
+			Where("account_id = ?", id).
+			Updates(map[string]interface{}{
+				"account_id": 0,
+				"details":    nil,
+				"ip_address": "",
+				"user_agent": "",
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		// nor is it named as the administrator behind the actions on other accounts
+		err = audit.Rewrite(tx).Unscoped().Model(&domain.AccountActivity{}).
+			Where("actor_id = ?", id).
+			Update("actor_id", nil).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Delete(&domain.Account{}, id).Error
+	})
+}
+
+// handOverOrganizations keeps an owner in every organization the account is the last owner of,
+// an admin or else the longest standing member is promoted, and an organization without any
+// other member is deleted with its invitations
+func handOverOrganizations(tx *gorm.DB, accountID uint) error {
+	var owned []domain.Membership
+	err := tx.Where("account_id = ? AND role = ?", accountID, domain.OrganizationRoleOwner).
+		Find(&owned).Error
+	if err != nil {
+		return err
+	}
+
+	for _, membership := range owned {
+		// locked like on member removal, so a concurrent removal cannot take the successor away
+		var others []domain.Membership
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("organization_id = ? AND account_id <> ?", membership.OrganizationID, accountID).
+			Order("id").
+			Find(&others).Error
+		if err != nil {
+			return err
+		}
+
+		if len(others) == 0 {
+			err = tenant.WithoutTenant(tx).Unscoped().
+				Where("organization_id = ?", membership.OrganizationID).
+				Delete(&domain.Invitation{}).Error
+			if err != nil {
+				return err
+			}
+			err = tx.Unscoped().Delete(&domain.Organization{}, membership.OrganizationID).Error
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		successor := others[0]
+		for _, other := range others {
+			if other.Role == domain.OrganizationRoleOwner {
+				successor = other
+				break
+			}
+			if other.Role == domain.OrganizationRoleAdmin && successor.Role != domain.OrganizationRoleAdmin {
+				successor = other
+			}
+		}
+		if successor.Role == domain.OrganizationRoleOwner {
+			continue
+		}
+
+		err = tx.Model(&domain.Membership{}).
+			Where("id = ?", successor.ID).
+			Update("role", domain.OrganizationRoleOwner).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *AccountRepo) SetAccountDisabled(ctx context.Context, id uint, disabledAt *time.Time) error {
+	_, span := r.trace.Start(ctx, "SetAccountDisabled")
+	defer span.End()
+	return r.db.Model(&domain.Account{}).
+		Where("id = ?", id).
+		Update("disabled_at", disabledAt).Error
+}
+
+func (r *AccountRepo) RequirePasswordReset(ctx context.Context, id uint) error {
+	_, span := r.trace.Start(ctx, "RequirePasswordReset")
+	defer span.End()
+	return r.db.Model(&domain.Account{}).
+		Where("id = ?", id).
+		Update("password_reset_required", true).Error
+}
+
 func (r *AccountRepo) LogAccountActivity(ctx context.Context, accountID uint, activity string) error {
-	_, span := r.trace.Start(ctx, "LogAccountActivity")
+	ctx, span := r.trace.Start(ctx, "LogAccountActivity")
+	defer span.End()
+	return r.db.Create(newAccountActivity(ctx, accountID, activity, nil)).Error
+}
+
+func (r *AccountRepo) LogAccountActivityDetails(ctx context.Context, accountID uint, activity string, details map[string]any) error {
+	ctx, span := r.trace.Start(ctx, "LogAccountActivityDetails")
+	defer span.End()
+	return r.db.Create(newAccountActivity(ctx, accountID, activity, details)).Error
+}
+
+// newAccountActivity records the activity with the request and trace it happened in, the
+// actor is only kept when someone else than the account acted on it
+func newAccountActivity(ctx context.Context, accountID uint, activity string, details map[string]any) *domain.AccountActivity {
+	record := &domain.AccountActivity{AccountID: accountID, Activity: activity, Details: details}
+
+	if request, ok := audit.RequestFromContext(ctx); ok {
+		record.IPAddress = request.IPAddress
+		record.UserAgent = request.UserAgent
+		record.RequestID = request.RequestID
+		if request.ActorID != 0 && request.ActorID != accountID {
+			actorID := request.ActorID
+			record.ActorID = &actorID
+		}
+	}
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		record.TraceID = spanContext.TraceID().String()
+	}
+
+	return record
+}
+
+// ListAccountActivities returns the most recent activities of the account first
+func (r *AccountRepo) ListAccountActivities(ctx context.Context, accountID uint, limit int) ([]domain.AccountActivity, error) {
+	_, span := r.trace.Start(ctx, "ListAccountActivities")
+	defer span.End()
+	var activities []domain.AccountActivity
+	err := r.db.Where("account_id = ?", accountID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&activities).Error
+	if err != nil {
+		return nil, err
+	}
+	return activities, nil
+}
+
+func (r *AccountRepo) ListActivities(ctx context.Context, filter domain.ActivityFilter) ([]domain.AccountActivity, int64, error) {
+	_, span := r.trace.Start(ctx, "ListActivities")
+	defer span.End()
+
+	query := r.db.Model(&domain.AccountActivity{})
+	if filter.AccountID != 0 {
+		query = query.Where("account_id = ?", filter.AccountID)
+	}
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Activity != "" {
+		query = query.Where("activity = ?", filter.Activity)
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *filter.CreatedBefore)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var activities []domain.AccountActivity
+	err := query.Order("created_at DESC, id DESC").Offset(filter.Offset).Limit(filter.Limit).Find(&activities).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return activities, total, nil
+}
+
+func (r *AccountRepo) CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) (*domain.RefreshToken, error) {
+	_, span := r.trace.Start(ctx, "CreateRefreshToken")
+	defer span.End()
+	err := r.db.Create(token).Error
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (r *AccountRepo) GetRefreshTokenByHash(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	_, span := r.trace.Start(ctx, "GetRefreshTokenByHash")
+	defer span.End()
+	var token domain.RefreshToken
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RotateRefreshToken revokes the current token and stores its replacement in one transaction.
+// The revoke only succeeds if the token is still active, so two concurrent refreshes with the
+// same token cannot both win: the loser gets ErrRefreshTokenReused.
+// The session of the token is kept alive for as long as its refresh token.
+func (r *AccountRepo) RotateRefreshToken(ctx context.Context, current *domain.RefreshToken, next *domain.RefreshToken) (*domain.RefreshToken, error) {
+	_, span := r.trace.Start(ctx, "RotateRefreshToken")
+	defer span.End()
+	now := time.Now()
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", current.ID).
+			Updates(map[string]interface{}{"revoked_at": now, "rotated_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrRefreshTokenReused
+		}
+
+		if next.SessionID != 0 {
+			err := tx.Model(&domain.Session{}).
+				Where("id = ? AND revoked_at IS NULL", next.SessionID).
+				Updates(map[string]interface{}{"last_seen_at": now, "expires_at": next.ExpiresAt}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(next).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+func (r *AccountRepo) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	_, span := r.trace.Start(ctx, "RevokeRefreshTokenFamily")
+	defer span.End()
+	return r.db.Model(&domain.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// CreateSession starts the session in the first organization the account joined,
+// unless it is given one
+func (r *AccountRepo) CreateSession(ctx context.Context, session *domain.Session) (*domain.Session, error) {
+	_, span := r.trace.Start(ctx, "CreateSession")
+	defer span.End()
+	if session.OrganizationID == 0 {
+		var organizationIDs []uint
+		err := r.db.Model(&domain.Membership{}).
+			Where("account_id = ?", session.AccountID).
+			Order("id").
+			Limit(1).
+			Pluck("organization_id", &organizationIDs).Error
+		if err != nil {
+			return nil, err
+		}
+		if len(organizationIDs) > 0 {
+			session.OrganizationID = organizationIDs[0]
+		}
+	}
+	err := r.db.Create(session).Error
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (r *AccountRepo) GetSessionByID(ctx context.Context, id uint) (*domain.Session, error) {
+	_, span := r.trace.Start(ctx, "GetSessionByID")
+	defer span.End()
+	var session domain.Session
+	err := r.db.Where("id = ?", id).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *AccountRepo) SetSessionOrganization(ctx context.Context, id uint, organizationID uint) error {
+	_, span := r.trace.Start(ctx, "SetSessionOrganization")
+	defer span.End()
+	return r.db.Model(&domain.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("organization_id", organizationID).Error
+}
+
+func (r *AccountRepo) ListActiveSessions(ctx context.Context, accountID uint) ([]domain.Session, error) {
+	_, span := r.trace.Start(ctx, "ListActiveSessions")
+	defer span.End()
+	var sessions []domain.Session
+	err := r.db.
+		Where("account_id = ? AND revoked_at IS NULL AND expires_at > ?", accountID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *AccountRepo) ListSessions(ctx context.Context, accountID uint) ([]domain.Session, error) {
+	_, span := r.trace.Start(ctx, "ListSessions")
+	defer span.End()
+	var sessions []domain.Session
+	err := r.db.Where("account_id = ?", accountID).Order("id").Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *AccountRepo) IsNewDevice(ctx context.Context, accountID uint, ipAddress string, userAgent string) (bool, error) {
+	_, span := r.trace.Start(ctx, "IsNewDevice")
+	defer span.End()
+	var counts struct {
+		Sessions int64
+		Device   int64
+	}
+	err := r.db.Model(&domain.Session{}).
+		Select("COUNT(*) AS sessions, COUNT(CASE WHEN ip_address = ? AND user_agent = ? THEN 1 END) AS device", ipAddress, userAgent).
+		Where("account_id = ?", accountID).
+		Scan(&counts).Error
+	if err != nil {
+		return false, err
+	}
+	return counts.Sessions > 0 && counts.Device == 0, nil
+}
+
+func (r *AccountRepo) ForgetSession(ctx context.Context, id uint) error {
+	_, span := r.trace.Start(ctx, "ForgetSession")
+	defer span.End()
+	return r.db.Delete(&domain.Session{}, id).Error
+}
+
+// TouchSession only updates the last seen time, so it can never resurrect a session
+// that was revoked after it was loaded
+func (r *AccountRepo) TouchSession(ctx context.Context, id uint, seenAt time.Time) error {
+	_, span := r.trace.Start(ctx, "TouchSession")
+	defer span.End()
+	return r.db.Model(&domain.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("last_seen_at", seenAt).Error
+}
+
+// RevokeSession revokes the session together with the refresh tokens issued for it
+func (r *AccountRepo) RevokeSession(ctx context.Context, id uint) error {
+	_, span := r.trace.Start(ctx, "RevokeSession")
+	defer span.End()
+	now := time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&domain.Session{}).
+			Where("id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", now).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&domain.RefreshToken{}).
+			Where("session_id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", now).Error
+	})
+}
+
+// RevokeAllSessions revokes every session and refresh token of the account
+func (r *AccountRepo) RevokeAllSessions(ctx context.Context, accountID uint) error {
+	_, span := r.trace.Start(ctx, "RevokeAllSessions")
+	defer span.End()
+	now := time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&domain.Session{}).
+			Where("account_id = ? AND revoked_at IS NULL", accountID).
+			Update("revoked_at", now).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&domain.RefreshToken{}).
+			Where("account_id = ? AND revoked_at IS NULL", accountID).
+			Update("revoked_at", now).Error
+	})
+}
+
+// ConsumeTOTPStep records step as the last accepted totp step, failing with
+// ErrMFACodeReused when a code of the same or a later step was already accepted
+func (r *AccountRepo) ConsumeTOTPStep(ctx context.Context, accountID uint, step int64) error {
+	_, span := r.trace.Start(ctx, "ConsumeTOTPStep")
+	defer span.End()
+	result := r.db.Model(&domain.Account{}).
+		Where("id = ? AND mfa_last_step < ?", accountID, step).
+		Update("mfa_last_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrMFACodeReused
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes deletes every recovery code of the account and stores the new ones
+func (r *AccountRepo) ReplaceRecoveryCodes(ctx context.Context, accountID uint, codes []domain.RecoveryCode) error {
+	_, span := r.trace.Start(ctx, "ReplaceRecoveryCodes")
+	defer span.End()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Where("account_id = ?", accountID).Delete(&domain.RecoveryCode{}).Error
+		if err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		for i := range codes {
+			codes[i].AccountID = accountID
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode marks the code as used, failing with ErrInvalidRecoveryCode
+// when the account has no unused code with that hash
+func (r *AccountRepo) UseRecoveryCode(ctx context.Context, accountID uint, hash string) error {
+	_, span := r.trace.Start(ctx, "UseRecoveryCode")
+	defer span.End()
+	result := r.db.Model(&domain.RecoveryCode{}).
+		Where("account_id = ? AND code_hash = ? AND used_at IS NULL", accountID, hash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrInvalidRecoveryCode
+	}
+	return nil
+}
+
+func (r *AccountRepo) DeleteRecoveryCodes(ctx context.Context, accountID uint) error {
+	_, span := r.trace.Start(ctx, "DeleteRecoveryCodes")
+	defer span.End()
+	return r.db.Unscoped().Where("account_id = ?", accountID).Delete(&domain.RecoveryCode{}).Error
+}
+
+func (r *AccountRepo) CreateOneTimeToken(ctx context.Context, token *domain.OneTimeToken) (*domain.OneTimeToken, error) {
+	_, span := r.trace.Start(ctx, "CreateOneTimeToken")
+	defer span.End()
+	err := r.db.Create(token).Error
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// ConsumeOneTimeToken marks the token as used and returns it. Only an unused, unrevoked and
+// unexpired token of the purpose is consumed, so of two concurrent uses only one succeeds,
+// every other case fails with ErrInvalidOneTimeToken.
+func (r *AccountRepo) ConsumeOneTimeToken(ctx context.Context, purpose string, hash string) (*domain.OneTimeToken, error) {
+	_, span := r.trace.Start(ctx, "ConsumeOneTimeToken")
+	defer span.End()
+	now := time.Now()
+	result := r.db.Model(&domain.OneTimeToken{}).
+		Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND revoked_at IS NULL AND expires_at > ?", hash, purpose, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, domain.ErrInvalidOneTimeToken
+	}
+	var token domain.OneTimeToken
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RevokeOneTimeTokens revokes every outstanding token of the account for the purpose
+func (r *AccountRepo) RevokeOneTimeTokens(ctx context.Context, accountID uint, purpose string) error {
+	_, span := r.trace.Start(ctx, "RevokeOneTimeTokens")
+	defer span.End()
+	return revokeOneTimeTokens(r.db, accountID, purpose)
+}
+
+// CountOneTimeTokensSince counts the tokens of the purpose issued to the account since the given time
+func (r *AccountRepo) CountOneTimeTokensSince(ctx context.Context, accountID uint, purpose string, since time.Time) (int64, error) {
+	_, span := r.trace.Start(ctx, "CountOneTimeTokensSince")
+	defer span.End()
+	var count int64
+	err := r.db.Model(&domain.OneTimeToken{}).
+		Where("account_id = ? AND purpose = ? AND created_at > ?", accountID, purpose, since).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ChangeAccountEmail sets the confirmed email and revokes the cancel links of the change,
+// deleted accounts still hold their email in the unique index so they are counted as well
+func (r *AccountRepo) ChangeAccountEmail(ctx context.Context, accountID uint, email string) error {
+	_, span := r.trace.Start(ctx, "ChangeAccountEmail")
+	defer span.End()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		err := tx.Unscoped().Model(&domain.Account{}).
+			Where("email = ? AND id <> ?", email, accountID).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return domain.ErrEmailTaken
+		}
+		now := time.Now()
+		err = tx.Model(&domain.Account{}).
+			Where("id = ?", accountID).
+			Updates(map[string]interface{}{
+				"email":          email,
+				"email_verified": true,
+				"verified_at":    now,
+			}).Error
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.ErrEmailTaken
+		}
+		if err != nil {
+			return err
+		}
+		return revokeOneTimeTokens(tx, accountID, domain.TokenPurposeEmailChangeCancel)
+	})
+}
+
+func revokeOneTimeTokens(db *gorm.DB, accountID uint, purpose string) error {
+	return db.Model(&domain.OneTimeToken{}).
+		Where("account_id = ? AND purpose = ? AND used_at IS NULL AND revoked_at IS NULL", accountID, purpose).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *AccountRepo) CreateAPIKey(ctx context.Context, key *domain.APIKey) (*domain.APIKey, error) {
+	_, span := r.trace.Start(ctx, "CreateAPIKey")
+	defer span.End()
+	err := r.db.Create(key).Error
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (r *AccountRepo) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	_, span := r.trace.Start(ctx, "GetAPIKeyByPrefix")
+	defer span.End()
+	var key domain.APIKey
+	err := r.db.Preload("Account").Where("prefix = ?", prefix).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// ListAPIKeys returns the keys of the account that are not revoked, expired ones included
+func (r *AccountRepo) ListAPIKeys(ctx context.Context, accountID uint) ([]domain.APIKey, error) {
+	_, span := r.trace.Start(ctx, "ListAPIKeys")
+	defer span.End()
+	var keys []domain.APIKey
+	err := r.db.
+		Where("account_id = ? AND revoked_at IS NULL", accountID).
+		Order("id").
+		Find(&keys).Error
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *AccountRepo) TouchAPIKey(ctx context.Context, id uint, usedAt time.Time) error {
+	_, span := r.trace.Start(ctx, "TouchAPIKey")
+	defer span.End()
+	return r.db.Model(&domain.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("last_used_at", usedAt).Error
+}
+
+// RevokeAPIKey revokes a key of the account, keys of other accounts are reported as missing
+func (r *AccountRepo) RevokeAPIKey(ctx context.Context, accountID uint, id uint) error {
+	_, span := r.trace.Start(ctx, "RevokeAPIKey")
+	defer span.End()
+	result := r.db.Model(&domain.APIKey{}).
+		Where("id = ? AND account_id = ? AND revoked_at IS NULL", id, accountID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *AccountRepo) CreateOAuthState(ctx context.Context, state *domain.OAuthState) (*domain.OAuthState, error) {
+	_, span := r.trace.Start(ctx, "CreateOAuthState")
+	defer span.End()
+	err := r.db.Create(state).Error
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// ConsumeOAuthState only consumes an unused and unexpired state, so a callback is completed once
+func (r *AccountRepo) ConsumeOAuthState(ctx context.Context, provider string, hash string) (*domain.OAuthState, error) {
+	_, span := r.trace.Start(ctx, "ConsumeOAuthState")
+	defer span.End()
+	now := time.Now()
+	result := r.db.Model(&domain.OAuthState{}).
+		Where("state_hash = ? AND provider = ? AND used_at IS NULL AND expires_at > ?", hash, provider, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, domain.ErrInvalidOAuthState
+	}
+	var state domain.OAuthState
+	err := r.db.Where("state_hash = ?", hash).First(&state).Error
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (r *AccountRepo) CreateIdentity(ctx context.Context, identity *domain.Identity) (*domain.Identity, error) {
+	_, span := r.trace.Start(ctx, "CreateIdentity")
+	defer span.End()
+	err := r.db.Create(identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+func (r *AccountRepo) GetIdentity(ctx context.Context, provider string, subject string) (*domain.Identity, error) {
+	_, span := r.trace.Start(ctx, "GetIdentity")
+	defer span.End()
+	var identity domain.Identity
+	err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *AccountRepo) ListIdentities(ctx context.Context, accountID uint) ([]domain.Identity, error) {
+	_, span := r.trace.Start(ctx, "ListIdentities")
+	defer span.End()
+	var identities []domain.Identity
+	err := r.db.Where("account_id = ?", accountID).Order("id").Find(&identities).Error
+	if err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+func (r *AccountRepo) RecordIdentityLogin(ctx context.Context, id uint, loginAt time.Time) error {
+	_, span := r.trace.Start(ctx, "RecordIdentityLogin")
+	defer span.End()
+	return r.db.Model(&domain.Identity{}).Where("id = ?", id).Update("last_login_at", loginAt).Error
+}
+
+// DeleteIdentity deletes the row so the provider user can be linked again, identities of
+// other accounts are reported as missing
+func (r *AccountRepo) DeleteIdentity(ctx context.Context, accountID uint, id uint) error {
+	_, span := r.trace.Start(ctx, "DeleteIdentity")
+	defer span.End()
+	result := r.db.Unscoped().Where("id = ? AND account_id = ?", id, accountID).Delete(&domain.Identity{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *AccountRepo) CreateOAuthClient(ctx context.Context, client *domain.OAuthClient) (*domain.OAuthClient, error) {
+	_, span := r.trace.Start(ctx, "CreateOAuthClient")
+	defer span.End()
+	err := r.db.Create(client).Error
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func (r *AccountRepo) GetOAuthClient(ctx context.Context, clientID string) (*domain.OAuthClient, error) {
+	_, span := r.trace.Start(ctx, "GetOAuthClient")
+	defer span.End()
+	var client domain.OAuthClient
+	err := r.db.Where("client_id = ?", clientID).First(&client).Error
+	if err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
+func (r *AccountRepo) ListOAuthClients(ctx context.Context) ([]domain.OAuthClient, error) {
+	_, span := r.trace.Start(ctx, "ListOAuthClients")
+	defer span.End()
+	var clients []domain.OAuthClient
+	err := r.db.Order("id").Find(&clients).Error
+	if err != nil {
+		return nil, err
+	}
+	return clients, nil
+}
+
+// DeleteOAuthClient soft deletes the client, its pending codes and consents stop working with it
+func (r *AccountRepo) DeleteOAuthClient(ctx context.Context, clientID string) error {
+	_, span := r.trace.Start(ctx, "DeleteOAuthClient")
+	defer span.End()
+	result := r.db.Where("client_id = ?", clientID).Delete(&domain.OAuthClient{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *AccountRepo) CreateAuthorizationCode(ctx context.Context, code *domain.AuthorizationCode) (*domain.AuthorizationCode, error) {
+	_, span := r.trace.Start(ctx, "CreateAuthorizationCode")
+	defer span.End()
+	err := r.db.Create(code).Error
+	if err != nil {
+		return nil, err
+	}
+	return code, nil
+}
+
+// ConsumeAuthorizationCode only consumes an unused and unexpired code, so it is redeemed once
+func (r *AccountRepo) ConsumeAuthorizationCode(ctx context.Context, hash string) (*domain.AuthorizationCode, error) {
+	_, span := r.trace.Start(ctx, "ConsumeAuthorizationCode")
+	defer span.End()
+	now := time.Now()
+	result := r.db.Model(&domain.AuthorizationCode{}).
+		Where("code_hash = ? AND used_at IS NULL AND expires_at > ?", hash, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, domain.ErrInvalidAuthorizationCode
+	}
+	var code domain.AuthorizationCode
+	err := r.db.Where("code_hash = ?", hash).First(&code).Error
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+func (r *AccountRepo) GetOAuthConsent(ctx context.Context, accountID uint, clientID string) (*domain.OAuthConsent, error) {
+	_, span := r.trace.Start(ctx, "GetOAuthConsent")
+	defer span.End()
+	var consent domain.OAuthConsent
+	err := r.db.Where("account_id = ? AND client_id = ?", accountID, clientID).First(&consent).Error
+	if err != nil {
+		return nil, err
+	}
+	return &consent, nil
+}
+
+// SaveOAuthConsent replaces the scopes approved by the account for the client
+func (r *AccountRepo) SaveOAuthConsent(ctx context.Context, consent *domain.OAuthConsent) error {
+	_, span := r.trace.Start(ctx, "SaveOAuthConsent")
+	defer span.End()
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "account_id"}, {Name: "client_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"scopes", "updated_at"}),
+	}).Create(consent).Error
+}
+
+func (r *AccountRepo) CreateWebAuthnChallenge(ctx context.Context, challenge *domain.WebAuthnChallenge) (*domain.WebAuthnChallenge, error) {
+	_, span := r.trace.Start(ctx, "CreateWebAuthnChallenge")
+	defer span.End()
+	err := r.db.Create(challenge).Error
+	if err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+func (r *AccountRepo) ConsumeWebAuthnChallenge(ctx context.Context, purpose string, hash string) (*domain.WebAuthnChallenge, error) {
+	_, span := r.trace.Start(ctx, "ConsumeWebAuthnChallenge")
+	defer span.End()
+	now := time.Now()
+	result := r.db.Model(&domain.WebAuthnChallenge{}).
+		Where("challenge_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", hash, purpose, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, domain.ErrInvalidWebAuthnChallenge
+	}
+	var challenge domain.WebAuthnChallenge
+	err := r.db.Where("challenge_hash = ?", hash).First(&challenge).Error
+	if err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+func (r *AccountRepo) CreateWebAuthnCredential(ctx context.Context, credential *domain.WebAuthnCredential) (*domain.WebAuthnCredential, error) {
+	_, span := r.trace.Start(ctx, "CreateWebAuthnCredential")
+	defer span.End()
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(credential).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.Account{}).
+			Where("id = ?", credential.AccountID).
+			Update("web_authn_enabled", true).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return credential, nil
+}
+
+func (r *AccountRepo) GetWebAuthnCredential(ctx context.Context, credentialID string) (*domain.WebAuthnCredential, error) {
+	_, span := r.trace.Start(ctx, "GetWebAuthnCredential")
+	defer span.End()
+	var credential domain.WebAuthnCredential
+	err := r.db.Where("credential_id = ?", credentialID).First(&credential).Error
+	if err != nil {
+		return nil, err
+	}
+	return &credential, nil
+}
+
+func (r *AccountRepo) ListWebAuthnCredentials(ctx context.Context, accountID uint) ([]domain.WebAuthnCredential, error) {
+	_, span := r.trace.Start(ctx, "ListWebAuthnCredentials")
+	defer span.End()
+	var credentials []domain.WebAuthnCredential
+	err := r.db.Where("account_id = ?", accountID).Order("created_at desc").Find(&credentials).Error
+	if err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
+func (r *AccountRepo) RecordWebAuthnCredentialUse(ctx context.Context, id uint, signCount uint32, usedAt time.Time) error {
+	_, span := r.trace.Start(ctx, "RecordWebAuthnCredentialUse")
+	defer span.End()
+	query := r.db.Model(&domain.WebAuthnCredential{}).Where("id = ?", id)
+	if signCount != 0 {
+		query = query.Where("sign_count < ?", signCount)
+	}
+	result := query.Updates(map[string]interface{}{
+		"sign_count":   signCount,
+		"last_used_at": usedAt,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrWebAuthnSignCount
+	}
+	return nil
+}
+
+// DeleteWebAuthnCredential deletes the row so the authenticator can be registered again,
+// passkeys of other accounts are reported as missing
+func (r *AccountRepo) DeleteWebAuthnCredential(ctx context.Context, accountID uint, id uint) error {
+	_, span := r.trace.Start(ctx, "DeleteWebAuthnCredential")
+	defer span.End()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where("id = ? AND account_id = ?", id, accountID).Delete(&domain.WebAuthnCredential{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		var remaining int64
+		err := tx.Model(&domain.WebAuthnCredential{}).Where("account_id = ?", accountID).Count(&remaining).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&domain.Account{}).
+			Where("id = ?", accountID).
+			Update("web_authn_enabled", remaining > 0).Error
+	})
+}
+
+// RecordLoginFailure stores the failure and, when it belongs to an account, returns the
+// number of consecutive failures of the account including this one
+func (r *AccountRepo) RecordLoginFailure(ctx context.Context, failure *domain.LoginFailure) (int, error) {
+	_, span := r.trace.Start(ctx, "RecordLoginFailure")
+	defer span.End()
+	var attempts int
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(failure).Error; err != nil {
+			return err
+		}
+		if failure.AccountID == 0 {
+			return nil
+		}
+		err := tx.Model(&domain.Account{}).
+			Where("id = ?", failure.AccountID).
+			Updates(map[string]interface{}{
+				"failed_login_attempts": gorm.Expr("failed_login_attempts + 1"),
+				"last_failed_login_at":  failure.CreatedAt,
+			}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&domain.Account{}).
+			Where("id = ?", failure.AccountID).
+			Pluck("failed_login_attempts", &attempts).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return attempts, nil
+}
+
+func (r *AccountRepo) CountLoginFailuresByIP(ctx context.Context, ipAddress string, since time.Time) (int64, error) {
+	_, span := r.trace.Start(ctx, "CountLoginFailuresByIP")
+	defer span.End()
+	var count int64
+	err := r.db.Model(&domain.LoginFailure{}).
+		Where("ip_address = ? AND created_at > ?", ipAddress, since).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// LockAccount refuses password logins until the given time and restarts the failure count,
+// it fails with ErrAccountAlreadyLocked when a concurrent request locked the account first
+func (r *AccountRepo) LockAccount(ctx context.Context, accountID uint, until time.Time) error {
+	_, span := r.trace.Start(ctx, "LockAccount")
+	defer span.End()
+	result := r.db.Model(&domain.Account{}).
+		Where("id = ? AND (locked_until IS NULL OR locked_until < ?)", accountID, time.Now()).
+		Updates(map[string]interface{}{
+			"locked_until":          until,
+			"failed_login_attempts": 0,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrAccountAlreadyLocked
+	}
+	return nil
+}
+
+// UnlockAccount lifts a lockout and forgets the failed logins of the account
+func (r *AccountRepo) UnlockAccount(ctx context.Context, accountID uint) error {
+	_, span := r.trace.Start(ctx, "UnlockAccount")
 	defer span.End()
-	return r.db.Create(&domain.AccountActivity{AccountID: accountID, Activity: activity}).Error
+	return r.db.Model(&domain.Account{}).
+		Where("id = ?", accountID).
+		Updates(map[string]interface{}{
+			"locked_until":          nil,
+			"failed_login_attempts": 0,
+			"last_failed_login_at":  nil,
+		}).Error
 }
@@ -0,0 +1,160 @@
+package account_test
+
+import (
+	"context"
+	"go_starter_api/internal/account"
+	"go_starter_api/pkg/audit"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/tenant"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestAccountRepo_PurgeAccount(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	setup := func(t *testing.T) *gorm.DB {
+		db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{TranslateError: true, Logger: logger.Discard})
+		require.NoError(t, err)
+		require.NoError(t, db.Use(tenant.Plugin{}))
+		require.NoError(t, db.Use(audit.Plugin{}))
+		require.NoError(t, db.AutoMigrate(
+			&domain.Account{},
+			&domain.AccountActivity{},
+			&domain.RefreshToken{},
+			&domain.Session{},
+			&domain.RevokedToken{},
+			&domain.AccountTokenRevocation{},
+			&domain.RecoveryCode{},
+			&domain.OneTimeToken{},
+			&domain.LoginFailure{},
+			&domain.APIKey{},
+			&domain.Identity{},
+			&domain.OAuthState{},
+			&domain.AuthorizationCode{},
+			&domain.OAuthConsent{},
+			&domain.WebAuthnCredential{},
+			&domain.WebAuthnChallenge{},
+			&domain.AccountRole{},
+			&domain.Organization{},
+			&domain.Membership{},
+			&domain.Invitation{},
+			&domain.DataExport{},
+		))
+		return db
+	}
+
+	// createAccount creates an account, deleted with a due purge when purged is set
+	createAccount := func(t *testing.T, db *gorm.DB, email string, purged bool) uint {
+		acc := &domain.Account{Email: email, Password: "hashed_password"}
+		require.NoError(t, db.Create(acc).Error)
+		if purged {
+			purgeAt := now.Add(-time.Minute)
+			require.NoError(t, db.Model(acc).Update("purge_at", purgeAt).Error)
+			require.NoError(t, db.Delete(acc).Error)
+		}
+		return acc.ID
+	}
+
+	createOrganization := func(t *testing.T, db *gorm.DB, members map[uint]string) uint {
+		organization := &domain.Organization{Name: "Acme"}
+		require.NoError(t, db.Create(organization).Error)
+		for accountID, role := range members {
+			require.NoError(t, db.Create(&domain.Membership{OrganizationID: organization.ID, AccountID: accountID, Role: role}).Error)
+		}
+		return organization.ID
+	}
+
+	roleOf := func(t *testing.T, db *gorm.DB, organizationID uint, accountID uint) string {
+		var membership domain.Membership
+		require.NoError(t, db.Where("organization_id = ? AND account_id = ?", organizationID, accountID).First(&membership).Error)
+		return membership.Role
+	}
+
+	t.Run("should promote an admin of an organization the account was the last owner of", func(t *testing.T) {
+		db := setup(t)
+		owner := createAccount(t, db, "owner@example.com", true)
+		member := createAccount(t, db, "member@example.com", false)
+		admin := createAccount(t, db, "admin@example.com", false)
+		organizationID := createOrganization(t, db, map[uint]string{
+			owner:  domain.OrganizationRoleOwner,
+			member: domain.OrganizationRoleMember,
+			admin:  domain.OrganizationRoleAdmin,
+		})
+
+		require.NoError(t, account.NewAccountRepository(db).PurgeAccount(ctx, owner, now))
+
+		assert.Equal(t, domain.OrganizationRoleOwner, roleOf(t, db, organizationID, admin))
+		assert.Equal(t, domain.OrganizationRoleMember, roleOf(t, db, organizationID, member))
+
+		var memberships int64
+		require.NoError(t, db.Unscoped().Model(&domain.Membership{}).Where("account_id = ?", owner).Count(&memberships).Error)
+		assert.Zero(t, memberships)
+	})
+
+	t.Run("should promote the longest standing member without an admin", func(t *testing.T) {
+		db := setup(t)
+		owner := createAccount(t, db, "owner@example.com", true)
+		first := createAccount(t, db, "first@example.com", false)
+		second := createAccount(t, db, "second@example.com", false)
+		organizationID := createOrganization(t, db, map[uint]string{owner: domain.OrganizationRoleOwner})
+		require.NoError(t, db.Create(&domain.Membership{OrganizationID: organizationID, AccountID: first, Role: domain.OrganizationRoleMember}).Error)
+		require.NoError(t, db.Create(&domain.Membership{OrganizationID: organizationID, AccountID: second, Role: domain.OrganizationRoleMember}).Error)
+
+		require.NoError(t, account.NewAccountRepository(db).PurgeAccount(ctx, owner, now))
+
+		assert.Equal(t, domain.OrganizationRoleOwner, roleOf(t, db, organizationID, first))
+		assert.Equal(t, domain.OrganizationRoleMember, roleOf(t, db, organizationID, second))
+	})
+
+	t.Run("should leave an organization with another owner as it is", func(t *testing.T) {
+		db := setup(t)
+		owner := createAccount(t, db, "owner@example.com", true)
+		coOwner := createAccount(t, db, "co-owner@example.com", false)
+		admin := createAccount(t, db, "admin@example.com", false)
+		organizationID := createOrganization(t, db, map[uint]string{
+			owner:   domain.OrganizationRoleOwner,
+			coOwner: domain.OrganizationRoleOwner,
+			admin:   domain.OrganizationRoleAdmin,
+		})
+
+		require.NoError(t, account.NewAccountRepository(db).PurgeAccount(ctx, owner, now))
+
+		assert.Equal(t, domain.OrganizationRoleOwner, roleOf(t, db, organizationID, coOwner))
+		assert.Equal(t, domain.OrganizationRoleAdmin, roleOf(t, db, organizationID, admin))
+	})
+
+	t.Run("should delete an organization the account was the only member of", func(t *testing.T) {
+		db := setup(t)
+		owner := createAccount(t, db, "owner@example.com", true)
+		organizationID := createOrganization(t, db, map[uint]string{owner: domain.OrganizationRoleOwner})
+		invitation := &domain.Invitation{Email: "invited@example.com", Role: domain.OrganizationRoleMember, InviterID: owner, Status: domain.InvitationStatusPending, ExpiresAt: now.Add(time.Hour)}
+		require.NoError(t, db.WithContext(tenant.WithOrganizationID(ctx, organizationID)).Create(invitation).Error)
+
+		require.NoError(t, account.NewAccountRepository(db).PurgeAccount(ctx, owner, now))
+
+		var organizations, invitations int64
+		require.NoError(t, db.Unscoped().Model(&domain.Organization{}).Where("id = ?", organizationID).Count(&organizations).Error)
+		require.NoError(t, tenant.WithoutTenant(db).Unscoped().Model(&domain.Invitation{}).Count(&invitations).Error)
+		assert.Zero(t, organizations)
+		assert.Zero(t, invitations)
+	})
+
+	t.Run("should keep the organizations of an account that is not purgeable", func(t *testing.T) {
+		db := setup(t)
+		owner := createAccount(t, db, "owner@example.com", false)
+		organizationID := createOrganization(t, db, map[uint]string{owner: domain.OrganizationRoleOwner})
+
+		err := account.NewAccountRepository(db).PurgeAccount(ctx, owner, now)
+
+		assert.ErrorIs(t, err, domain.ErrAccountNotPurgeable)
+		assert.Equal(t, domain.OrganizationRoleOwner, roleOf(t, db, organizationID, owner))
+	})
+}
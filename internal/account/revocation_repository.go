@@ -0,0 +1,82 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"go_starter_api/pkg/domain"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TokenRevocationRepo struct {
+	db    *gorm.DB
+	trace trace.Tracer
+}
+
+func NewTokenRevocationRepository(db *gorm.DB) domain.TokenRevocationRepository {
+	trace := otel.Tracer("tokenRevocationRepository")
+	return &TokenRevocationRepo{
+		db:    db,
+		trace: trace,
+	}
+}
+
+func (r *TokenRevocationRepo) RevokeToken(ctx context.Context, tokenID string, accountID uint, expiresAt time.Time) error {
+	_, span := r.trace.Start(ctx, "RevokeToken")
+	defer span.End()
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&domain.RevokedToken{
+		TokenID:   tokenID,
+		AccountID: accountID,
+		ExpiresAt: expiresAt,
+	}).Error
+}
+
+func (r *TokenRevocationRepo) RevokeAllTokens(ctx context.Context, accountID uint, before time.Time) error {
+	_, span := r.trace.Start(ctx, "RevokeAllTokens")
+	defer span.End()
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "account_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"revoked_before", "updated_at"}),
+	}).Create(&domain.AccountTokenRevocation{
+		AccountID:     accountID,
+		RevokedBefore: before,
+	}).Error
+}
+
+func (r *TokenRevocationRepo) IsTokenRevoked(ctx context.Context, claims *domain.AuthClaims) (bool, error) {
+	_, span := r.trace.Start(ctx, "IsTokenRevoked")
+	defer span.End()
+
+	if claims.TokenID != "" {
+		var count int64
+		err := r.db.Model(&domain.RevokedToken{}).Where("token_id = ?", claims.TokenID).Count(&count).Error
+		if err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+
+	var revocation domain.AccountTokenRevocation
+	err := r.db.Where("account_id = ?", claims.AccountID).First(&revocation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return issuedBefore(claims, revocation.RevokedBefore), nil
+}
+
+// iat only has second precision, a token issued in the same second as the
+// revocation is treated as issued before it
+func issuedBefore(claims *domain.AuthClaims, cutoff time.Time) bool {
+	return !claims.IssuedAt.After(cutoff.Truncate(time.Second))
+}
@@ -0,0 +1,64 @@
+package account
+
+import (
+	"context"
+	"go_starter_api/pkg/domain"
+	"sync"
+	"time"
+)
+
+// InMemoryTokenRevocationRepo keeps revocations in process memory.
+// It is meant for tests and single instance development setups.
+type InMemoryTokenRevocationRepo struct {
+	mu            sync.RWMutex
+	revokedTokens map[string]time.Time
+	revokedBefore map[uint]time.Time
+}
+
+func NewInMemoryTokenRevocationRepository() domain.TokenRevocationRepository {
+	return &InMemoryTokenRevocationRepo{
+		revokedTokens: map[string]time.Time{},
+		revokedBefore: map[uint]time.Time{},
+	}
+}
+
+func (r *InMemoryTokenRevocationRepo) RevokeToken(ctx context.Context, tokenID string, accountID uint, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// drop entries for tokens that have expired on their own
+	now := time.Now()
+	for id, exp := range r.revokedTokens {
+		if exp.Before(now) {
+			delete(r.revokedTokens, id)
+		}
+	}
+
+	r.revokedTokens[tokenID] = expiresAt
+	return nil
+}
+
+func (r *InMemoryTokenRevocationRepo) RevokeAllTokens(ctx context.Context, accountID uint, before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.revokedBefore[accountID] = before
+	return nil
+}
+
+func (r *InMemoryTokenRevocationRepo) IsTokenRevoked(ctx context.Context, claims *domain.AuthClaims) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if claims.TokenID != "" {
+		if _, ok := r.revokedTokens[claims.TokenID]; ok {
+			return true, nil
+		}
+	}
+
+	cutoff, ok := r.revokedBefore[claims.AccountID]
+	if !ok {
+		return false, nil
+	}
+
+	return issuedBefore(claims, cutoff), nil
+}
@@ -4,11 +4,17 @@ import (
 	"context"
 	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/keyring"
 	"go_starter_api/pkg/mailer"
+	"html"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -25,18 +31,66 @@ var (
 	ErrJWTSecretNotSet      = errors.New("jwt secret is not set")
 	ErrSubjectClaimNotFound = errors.New("subject claim not found in token")
 	ErrInvalidSubjectClaim  = errors.New("invalid subject claim type")
+	ErrKeyIDNotFound        = errors.New("key id not found in token header")
+	ErrEmailClaimNotFound   = errors.New("email claim not found in token")
+	ErrKeyRingNotSet        = errors.New("the authorization server signs with the keyring, JWT_KEYS_DIR is not set")
+	ErrInvalidAccessToken   = errors.New("invalid access token")
+)
+
+const (
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+	// time a user has to enter the totp code after a successful password check
+	mfaTokenTTL       = 5 * time.Minute
+	recoveryCodeCount = 10
+	defaultMFAIssuer  = "go_starter_api"
+
+	defaultEmailVerificationTTL = 48 * time.Hour
+	defaultOneTimeTokenTTL      = 30 * time.Minute
+	defaultMagicLinkTTL         = 15 * time.Minute
+	defaultEmailChangeTTL       = 24 * time.Hour
+	// a sign-in from a new device can be reported as long as the owner may take to read the email
+	defaultSignInReportTTL = 7 * 24 * time.Hour
+	// a deleted account can be restored until it is purged, the restore link lives as long
+	defaultAccountDeletionGracePeriod = 30 * 24 * time.Hour
+
+	// at most defaultMagicLinkRateLimit links are sent to an account per defaultMagicLinkRateWindow
+	defaultMagicLinkRateLimit  = 3
+	defaultMagicLinkRateWindow = 15 * time.Minute
+
+	// random bytes in the lookup prefix of an api key, hex encoded after domain.APIKeyPrefix
+	apiKeyPrefixBytes = 6
+
+	// the access tokens of the authorization server are typed as in RFC 9068, so they are
+	// never taken for id tokens. This api refuses them as they have a string subject.
+	oauthAccessTokenType = "at+jwt"
+)
+
+// EMAIL_VERIFICATION_POLICY values, deciding what unverified accounts are kept from
+const (
+	// unverified accounts can do everything
+	EmailVerificationPolicyNone = "none"
+	// routes behind RequireVerifiedEmail are refused to unverified accounts
+	EmailVerificationPolicyRoutes = "routes"
+	// unverified accounts cannot log in at all, registering no longer returns tokens
+	EmailVerificationPolicyLogin = "login"
 )
 
 type AccountService struct {
 	tracer       trace.Tracer
 	emailService mailer.EmailService
+	keyRing      keyring.KeyRing
 }
 
-func NewAccountService(emailService mailer.EmailService) domain.AccountService {
+// NewAccountService signs tokens with the active key of keyRing,
+// a nil keyRing falls back to HS256 with JWT_SECRET
+func NewAccountService(emailService mailer.EmailService, keyRing keyring.KeyRing) domain.AccountService {
 	tracer := otel.Tracer("accountService")
 	return &AccountService{
 		tracer:       tracer,
 		emailService: emailService,
+		keyRing:      keyRing,
 	}
 }
 
@@ -83,6 +137,11 @@ func (s *AccountService) ComparePassword(ctx context.Context, password, hash str
 	ctx, span := s.tracer.Start(ctx, "ComparePassword")
 	defer span.End()
 
+	// accounts created with an identity provider have no password until one is set by a reset
+	if hash == "" {
+		return false, nil
+	}
+
 	// Split the hash into its components
 	// Expected format: $argon2id$v=19$m=65536,t=1,p=4$<salt>$<hash>
 	parts := strings.Split(hash, "$")
@@ -143,111 +202,572 @@ func (s *AccountService) ComparePassword(ctx context.Context, password, hash str
 	return hmac.Equal(hashBytes, computedHash), nil
 }
 
-func (s *AccountService) GenerateAuthToken(ctx context.Context, account *domain.Account) (string, error) {
+func (s *AccountService) GenerateAuthToken(ctx context.Context, account *domain.Account, sessionID uint, organizationID uint) (string, error) {
 	ctx, span := s.tracer.Start(ctx, "GenerateAuthToken")
 	defer span.End()
 
-	jwtSecret := viper.GetString("JWT_SECRET")
-	if jwtSecret == "" {
-		return "", ErrJWTSecretNotSet
+	// jti identifies the token so it can be revoked before it expires
+	tokenID, err := randomToken(16)
+	if err != nil {
+		return "", err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	claims := jwt.MapClaims{
 		"sub": account.ID,
+		"sid": sessionID,
+		"jti": tokenID,
 		"iss": "go_starter_api",
 		"iat": time.Now().Unix(),
-		"exp": time.Now().Add(time.Hour * 24).Unix(),
+		"exp": time.Now().Add(accessTokenTTL()).Unix(),
+	}
+
+	// org is the active organization, left out for accounts working outside one
+	if organizationID != 0 {
+		claims["org"] = organizationID
+	}
+
+	return s.signToken(claims)
+}
+
+func (s *AccountService) signToken(claims jwt.MapClaims) (string, error) {
+	if s.keyRing == nil {
+		jwtSecret := viper.GetString("JWT_SECRET")
+		if jwtSecret == "" {
+			return "", ErrJWTSecretNotSet
+		}
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(jwtSecret))
+	}
+
+	return s.signKeyRingToken(claims, "JWT")
+}
+
+// signKeyRingToken signs with the active key of the keyring, tokens read by other apps are
+// never signed with the shared secret
+func (s *AccountService) signKeyRingToken(claims jwt.MapClaims, tokenType string) (string, error) {
+	if s.keyRing == nil {
+		return "", ErrKeyRingNotSet
+	}
+
+	key, err := s.keyRing.SigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	// kid tells verifiers which of the published keys to use
+	token := jwt.NewWithClaims(key.SigningMethod(), claims)
+	token.Header["kid"] = key.ID
+	token.Header["typ"] = tokenType
+
+	return token.SignedString(key.Private)
+}
+
+// parseToken only accepts the algorithms of the configured keys, so a keyring
+// deployment never falls back to accepting tokens signed with the shared secret
+func (s *AccountService) parseToken(token string) (*jwt.Token, error) {
+	if s.keyRing == nil {
+		jwtSecret := viper.GetString("JWT_SECRET")
+		if jwtSecret == "" {
+			return nil, ErrJWTSecretNotSet
+		}
+		return jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+			return []byte(jwtSecret), nil
+		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	}
+
+	return jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+		kid, ok := token.Header["kid"].(string)
+		if !ok {
+			return nil, ErrKeyIDNotFound
+		}
+
+		key, err := s.keyRing.VerificationKey(kid)
+		if err != nil {
+			return nil, err
+		}
+
+		if token.Method.Alg() != key.Algorithm {
+			return nil, jwt.ErrTokenSignatureInvalid
+		}
+
+		return key.Public(), nil
+	}, jwt.WithValidMethods([]string{keyring.AlgorithmRS256, keyring.AlgorithmES256, keyring.AlgorithmEdDSA}))
+}
+
+// access tokens are short lived, clients renew them using the refresh token
+func accessTokenTTL() time.Duration {
+	ttl := viper.GetDuration("ACCESS_TOKEN_TTL")
+	if ttl <= 0 {
+		return defaultAccessTokenTTL
+	}
+	return ttl
+}
+
+func refreshTokenTTL() time.Duration {
+	ttl := viper.GetDuration("REFRESH_TOKEN_TTL")
+	if ttl <= 0 {
+		return defaultRefreshTokenTTL
+	}
+	return ttl
+}
+
+func oneTimeTokenTTL(purpose string) time.Duration {
+	var ttl time.Duration
+	fallback := defaultOneTimeTokenTTL
+	switch purpose {
+	case domain.TokenPurposePasswordReset:
+		ttl = viper.GetDuration("PASSWORD_RESET_TOKEN_TTL")
+	case domain.TokenPurposeMagicLink:
+		ttl = viper.GetDuration("MAGIC_LINK_TTL")
+		fallback = defaultMagicLinkTTL
+	case domain.TokenPurposeEmailChange, domain.TokenPurposeEmailChangeCancel:
+		ttl = viper.GetDuration("EMAIL_CHANGE_TTL")
+		fallback = defaultEmailChangeTTL
+	case domain.TokenPurposeAccountRestore:
+		ttl = viper.GetDuration("ACCOUNT_DELETION_GRACE_PERIOD")
+		fallback = defaultAccountDeletionGracePeriod
+	case domain.TokenPurposeSignInReport:
+		ttl = viper.GetDuration("SIGN_IN_REPORT_TTL")
+		fallback = defaultSignInReportTTL
+	}
+	if ttl <= 0 {
+		return fallback
+	}
+	return ttl
+}
+
+func magicLinkRateLimit() (int64, time.Duration) {
+	limit := viper.GetInt64("MAGIC_LINK_RATE_LIMIT")
+	if limit <= 0 {
+		limit = defaultMagicLinkRateLimit
+	}
+	window := viper.GetDuration("MAGIC_LINK_RATE_WINDOW")
+	if window <= 0 {
+		window = defaultMagicLinkRateWindow
+	}
+	return limit, window
+}
+
+func emailVerificationTTL() time.Duration {
+	ttl := viper.GetDuration("EMAIL_VERIFICATION_TTL")
+	if ttl <= 0 {
+		return defaultEmailVerificationTTL
+	}
+	return ttl
+}
+
+// the issuer of the authorization server, the url its discovery document is served under
+func oidcIssuer() string {
+	issuer := viper.GetString("OIDC_ISSUER")
+	if issuer == "" {
+		issuer = viper.GetString("SERVER_URL")
+	}
+	return strings.TrimSuffix(issuer, "/")
+}
+
+// the consent page of the frontend, clients send the user there to log in
+func oidcAuthorizationURL() string {
+	authorizationURL := viper.GetString("OIDC_AUTHORIZATION_URL")
+	if authorizationURL == "" {
+		return oidcIssuer() + "/oauth/authorize"
+	}
+	return authorizationURL
+}
+
+func emailVerificationPolicy() string {
+	switch policy := viper.GetString("EMAIL_VERIFICATION_POLICY"); policy {
+	case EmailVerificationPolicyRoutes, EmailVerificationPolicyLogin:
+		return policy
+	default:
+		return EmailVerificationPolicyNone
+	}
+}
+
+// generates a random url safe token of n bytes
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// GenerateRefreshToken returns an opaque refresh token and the record to persist for it.
+// An empty familyID starts a new token family, rotated tokens keep the family of their parent.
+func (s *AccountService) GenerateRefreshToken(ctx context.Context, account *domain.Account, familyID string) (string, *domain.RefreshToken, error) {
+	ctx, span := s.tracer.Start(ctx, "GenerateRefreshToken")
+	defer span.End()
+
+	token, err := randomToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if familyID == "" {
+		familyID, err = randomToken(16)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	refreshToken := &domain.RefreshToken{
+		AccountID: account.ID,
+		FamilyID:  familyID,
+		TokenHash: s.HashToken(ctx, token),
+		ExpiresAt: time.Now().Add(refreshTokenTTL()),
+	}
+
+	return token, refreshToken, nil
+}
+
+// opaque tokens are high entropy, a plain sha256 is enough to store them
+func (s *AccountService) HashToken(ctx context.Context, token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateTOTPSecret returns a new random 160 bit secret, base32 encoded as authenticator apps expect
+func (s *AccountService) GenerateTOTPSecret(ctx context.Context) (string, error) {
+	ctx, span := s.tracer.Start(ctx, "GenerateTOTPSecret")
+	defer span.End()
+
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// TOTPURI returns the otpauth uri that authenticator apps import, usually shown as a qr code
+func (s *AccountService) TOTPURI(ctx context.Context, account *domain.Account, secret string) string {
+	issuer := viper.GetString("MFA_ISSUER")
+	if issuer == "" {
+		issuer = defaultMFAIssuer
+	}
+
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", strconv.Itoa(totpDigits))
+	query.Set("period", strconv.Itoa(totpPeriod))
+
+	uri := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account.Email,
+		RawQuery: query.Encode(),
+	}
+
+	return uri.String()
+}
+
+// VerifyTOTPCode returns the time step the code matches, callers must reject steps
+// that are not newer than the last accepted one to prevent replays
+func (s *AccountService) VerifyTOTPCode(ctx context.Context, secret string, code string) (int64, error) {
+	ctx, span := s.tracer.Start(ctx, "VerifyTOTPCode")
+	defer span.End()
+
+	key, err := decodeTOTPSecret(secret)
+	if err != nil || len(key) == 0 {
+		return 0, domain.ErrInvalidMFACode
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return 0, domain.ErrInvalidMFACode
+	}
+
+	current := totpStep(time.Now())
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if hmac.Equal([]byte(hotpCode(key, step)), []byte(code)) {
+			return step, nil
+		}
+	}
+
+	return 0, domain.ErrInvalidMFACode
+}
+
+// GenerateRecoveryCodes returns new codes formatted as xxxx-xxxx-xxxx-xxxx, hash them
+// with HashToken after normalizeRecoveryCode before storing
+func (s *AccountService) GenerateRecoveryCodes(ctx context.Context) ([]string, error) {
+	ctx, span := s.tracer.Start(ctx, "GenerateRecoveryCodes")
+	defer span.End()
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 10)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+
+		encoded := strings.ToLower(totpEncoding.EncodeToString(b))
+		codes = append(codes, encoded[0:4]+"-"+encoded[4:8]+"-"+encoded[8:12]+"-"+encoded[12:16])
+	}
+
+	return codes, nil
+}
+
+// GenerateMFAToken issues the short lived token returned by a password login of an account with mfa,
+// it is only good for completing the login with a totp or recovery code
+func (s *AccountService) GenerateMFAToken(ctx context.Context, account *domain.Account) (string, error) {
+	ctx, span := s.tracer.Start(ctx, "GenerateMFAToken")
+	defer span.End()
+
+	// jti lets the token be revoked once it is used, it allows a single mfa code attempt
+	tokenID, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	return s.signToken(jwt.MapClaims{
+		"sub": strconv.FormatUint(uint64(account.ID), 10) + ":mfa-pending",
+		"jti": tokenID,
+		"iss": "go_starter_api",
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(mfaTokenTTL).Unix(),
 	})
+}
+
+// ValidateMFAToken returns the claims of an mfa token, whether it was revoked is left to the caller
+func (s *AccountService) ValidateMFAToken(ctx context.Context, token string) (*domain.AuthClaims, error) {
+	ctx, span := s.tracer.Start(ctx, "ValidateMFAToken")
+	defer span.End()
+
+	parsed, err := s.parseToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	accountID, err := purposeSubject(parsed, "mfa-pending")
+	if err != nil {
+		return nil, err
+	}
+
+	mapClaims := parsed.Claims.(jwt.MapClaims)
+	claims := &domain.AuthClaims{AccountID: accountID}
+
+	if jti, ok := mapClaims["jti"].(string); ok {
+		claims.TokenID = jti
+	}
+
+	if iat, err := mapClaims.GetIssuedAt(); err == nil && iat != nil {
+		claims.IssuedAt = iat.Time
+	}
+
+	if exp, err := mapClaims.GetExpirationTime(); err == nil && exp != nil {
+		claims.ExpiresAt = exp.Time
+	}
+
+	return claims, nil
+}
+
+// purposeSubject extracts the account id of a "<id>:<purpose>" subject
+func purposeSubject(token *jwt.Token, purpose string) (uint, error) {
+	subClaim, ok := token.Claims.(jwt.MapClaims)["sub"]
+	if !ok {
+		return 0, ErrSubjectClaimNotFound
+	}
+
+	sub, ok := subClaim.(string)
+	if !ok {
+		return 0, ErrInvalidSubjectClaim
+	}
+
+	parts := strings.Split(sub, ":")
+	if len(parts) != 2 || parts[1] != purpose {
+		return 0, ErrInvalidSubjectClaim
+	}
 
-	return token.SignedString([]byte(jwtSecret))
+	accountID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint(accountID), nil
 }
 
 func (s *AccountService) ValidateAuthToken(ctx context.Context, token string) (uint, error) {
 	ctx, span := s.tracer.Start(ctx, "ValidateAuthToken")
 	defer span.End()
 
-	jwtSecret := viper.GetString("JWT_SECRET")
-	if jwtSecret == "" {
-		return 0, ErrJWTSecretNotSet
+	claims, err := s.ParseAuthToken(ctx, token)
+	if err != nil {
+		return 0, err
 	}
 
-	claims, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
-		return []byte(jwtSecret), nil
-	})
+	return claims.AccountID, nil
+}
+
+func (s *AccountService) ParseAuthToken(ctx context.Context, token string) (*domain.AuthClaims, error) {
+	ctx, span := s.tracer.Start(ctx, "ParseAuthToken")
+	defer span.End()
+
+	parsed, err := s.parseToken(token)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
+	mapClaims := parsed.Claims.(jwt.MapClaims)
+
 	// Extract the subject claim and convert from float64 (JSON number) to uint
-	subClaim, ok := claims.Claims.(jwt.MapClaims)["sub"]
+	subClaim, ok := mapClaims["sub"]
 	if !ok {
-		return 0, ErrSubjectClaimNotFound
+		return nil, ErrSubjectClaimNotFound
 	}
 
 	// Convert float64 to uint (JWT library returns JSON numbers as float64)
 	accountIDFloat, ok := subClaim.(float64)
 	if !ok {
-		return 0, ErrInvalidSubjectClaim
+		return nil, ErrInvalidSubjectClaim
+	}
+
+	claims := &domain.AuthClaims{
+		AccountID: uint(accountIDFloat),
+	}
+
+	if sid, ok := mapClaims["sid"].(float64); ok {
+		claims.SessionID = uint(sid)
+	}
+
+	if org, ok := mapClaims["org"].(float64); ok {
+		claims.OrganizationID = uint(org)
+	}
+
+	// tokens issued before jti was introduced have none, they can only be revoked in bulk
+	if jti, ok := mapClaims["jti"].(string); ok {
+		claims.TokenID = jti
 	}
 
-	return uint(accountIDFloat), nil
+	if iat, err := mapClaims.GetIssuedAt(); err == nil && iat != nil {
+		claims.IssuedAt = iat.Time
+	}
+
+	if exp, err := mapClaims.GetExpirationTime(); err == nil && exp != nil {
+		claims.ExpiresAt = exp.Time
+	}
+
+	return claims, nil
 }
 
-func (s *AccountService) GeneratePasswordResetToken(ctx context.Context, account *domain.Account) (string, error) {
-	ctx, span := s.tracer.Start(ctx, "GeneratePasswordResetToken")
+// GenerateEmailVerificationToken signs the account's current email into the token,
+// so a link sent to a previous address stops working once the email changes
+func (s *AccountService) GenerateEmailVerificationToken(ctx context.Context, account *domain.Account) (string, error) {
+	ctx, span := s.tracer.Start(ctx, "GenerateEmailVerificationToken")
 	defer span.End()
 
-	jwtSecret := viper.GetString("JWT_SECRET")
-	if jwtSecret == "" {
-		return "", ErrJWTSecretNotSet
+	return s.signToken(jwt.MapClaims{
+		"sub":   strconv.FormatUint(uint64(account.ID), 10) + ":verify-email",
+		"email": account.Email,
+		"iss":   "go_starter_api",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(emailVerificationTTL()).Unix(),
+	})
+}
+
+// ValidateEmailVerificationToken returns the account id and the email the token was issued for
+func (s *AccountService) ValidateEmailVerificationToken(ctx context.Context, token string) (uint, string, error) {
+	ctx, span := s.tracer.Start(ctx, "ValidateEmailVerificationToken")
+	defer span.End()
+
+	parsed, err := s.parseToken(token)
+	if err != nil {
+		return 0, "", err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": strconv.FormatUint(uint64(account.ID), 10) + ":password-reset",
-		"iss": "go_starter_api",
-		"iat": time.Now().Unix(),
-		"exp": time.Now().Add(time.Hour * 24).Unix(),
-	})
+	accountID, err := purposeSubject(parsed, "verify-email")
+	if err != nil {
+		return 0, "", err
+	}
+
+	email, ok := parsed.Claims.(jwt.MapClaims)["email"].(string)
+	if !ok || email == "" {
+		return 0, "", ErrEmailClaimNotFound
+	}
 
-	return token.SignedString([]byte(jwtSecret))
+	return accountID, email, nil
 }
 
-func (s *AccountService) ValidatePasswordResetToken(ctx context.Context, token string) (uint, error) {
-	ctx, span := s.tracer.Start(ctx, "ValidatePasswordResetToken")
+func (s *AccountService) SendVerificationEmail(ctx context.Context, email string, token string) error {
+	ctx, span := s.tracer.Start(ctx, "SendVerificationEmail")
 	defer span.End()
 
-	jwtSecret := viper.GetString("JWT_SECRET")
-	if jwtSecret == "" {
-		return 0, ErrJWTSecretNotSet
+	serverUrl := viper.GetString("SERVER_URL")
+	if serverUrl == "" {
+		return domain.ErrServerURLNotSet
 	}
+	link := serverUrl + "/api/v1/account/verify-email?token=" + token
 
-	claims, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
-		return []byte(jwtSecret), nil
-	})
+	verifyEmailTemplate := `
+		<html>
+		<body>
+			<h1>Verify your email</h1>
+			<p><a href="` + link + `">Click here to verify your email address</a></p>
+			<p>If you did not create an account, please ignore this email.</p>
+			<p>Thank you for using our service.</p>
+		</body>
+		</html>
+	`
+
+	return s.emailService.SendEmail(email, "Verify your email", verifyEmailTemplate)
+}
+
+// GenerateOneTimeToken returns an opaque single use token and the record to persist for it,
+// it expires after the ttl configured for its purpose
+func (s *AccountService) GenerateOneTimeToken(ctx context.Context, account *domain.Account, purpose string) (string, *domain.OneTimeToken, error) {
+	ctx, span := s.tracer.Start(ctx, "GenerateOneTimeToken")
+	defer span.End()
+
+	token, err := randomToken(32)
 	if err != nil {
-		return 0, err
+		return "", nil, err
 	}
 
-	subClaim, ok := claims.Claims.(jwt.MapClaims)["sub"]
-	if !ok {
-		return 0, ErrSubjectClaimNotFound
+	oneTimeToken := &domain.OneTimeToken{
+		AccountID: account.ID,
+		Purpose:   purpose,
+		TokenHash: s.HashToken(ctx, token),
+		ExpiresAt: time.Now().Add(oneTimeTokenTTL(purpose)),
 	}
 
-	parts := strings.Split(subClaim.(string), ":")
-	if len(parts) != 2 {
-		return 0, ErrInvalidSubjectClaim
+	return token, oneTimeToken, nil
+}
+
+// GenerateAPIKey returns a new api key and the record to persist for it. The key is
+// <lookup prefix>_<secret>, the lookup prefix is stored as is to find the record and
+// the whole key only as a hash.
+func (s *AccountService) GenerateAPIKey(ctx context.Context, account *domain.Account) (string, *domain.APIKey, error) {
+	ctx, span := s.tracer.Start(ctx, "GenerateAPIKey")
+	defer span.End()
+
+	lookup := make([]byte, apiKeyPrefixBytes)
+	if _, err := rand.Read(lookup); err != nil {
+		return "", nil, err
 	}
+	prefix := domain.APIKeyPrefix + hex.EncodeToString(lookup)
 
-	accountID, err := strconv.ParseUint(parts[0], 10, 64)
+	secret, err := randomToken(32)
 	if err != nil {
-		return 0, err
+		return "", nil, err
 	}
+	key := prefix + "_" + secret
 
-	if parts[1] != "password-reset" {
-		return 0, ErrInvalidSubjectClaim
+	apiKey := &domain.APIKey{
+		AccountID: account.ID,
+		Prefix:    prefix,
+		TokenHash: s.HashToken(ctx, key),
 	}
 
-	return uint(accountID), nil
+	return key, apiKey, nil
+}
+
+// apiKeyLookupPrefix returns the lookup prefix of a key generated by GenerateAPIKey
+func apiKeyLookupPrefix(key string) (string, bool) {
+	length := len(domain.APIKeyPrefix) + hex.EncodedLen(apiKeyPrefixBytes)
+	if len(key) <= length+1 || !strings.HasPrefix(key, domain.APIKeyPrefix) || key[length] != '_' {
+		return "", false
+	}
+	return key[:length], true
 }
 
 func (s *AccountService) SendPasswordResetEmail(ctx context.Context, email string, token string) error {
@@ -273,3 +793,382 @@ func (s *AccountService) SendPasswordResetEmail(ctx context.Context, email strin
 
 	return s.emailService.SendEmail(email, "Password Reset", resetPasswordTemplate)
 }
+
+func (s *AccountService) SendMagicLinkEmail(ctx context.Context, email string, token string) error {
+	ctx, span := s.tracer.Start(ctx, "SendMagicLinkEmail")
+	defer span.End()
+
+	serverUrl := viper.GetString("SERVER_URL")
+	if serverUrl == "" {
+		return domain.ErrServerURLNotSet
+	}
+	link := serverUrl + "/api/v1/account/magic-link/consume?token=" + token
+
+	magicLinkTemplate := `
+		<html>
+		<body>
+			<h1>Your login link</h1>
+			<p><a href="` + link + `">Click here to log in</a></p>
+			<p>The link can only be used once and expires shortly.</p>
+			<p>If you did not request this link, please ignore this email.</p>
+		</body>
+		</html>
+	`
+
+	return s.emailService.SendEmail(email, "Your login link", magicLinkTemplate)
+}
+
+func (s *AccountService) SendAccountLockedEmail(ctx context.Context, email string, until time.Time) error {
+	ctx, span := s.tracer.Start(ctx, "SendAccountLockedEmail")
+	defer span.End()
+
+	lockedTemplate := `
+		<html>
+		<body>
+			<h1>Your account was temporarily locked</h1>
+			<p>We locked your account after too many failed login attempts. You can log in again after ` + until.UTC().Format(time.RFC1123) + `.</p>
+			<p>If these attempts were not yours, we recommend resetting your password.</p>
+		</body>
+		</html>
+	`
+
+	return s.emailService.SendEmail(email, "Your account was temporarily locked", lockedTemplate)
+}
+
+func (s *AccountService) SendAccountDeletedEmail(ctx context.Context, email string, purgeAt time.Time, token string) error {
+	ctx, span := s.tracer.Start(ctx, "SendAccountDeletedEmail")
+	defer span.End()
+
+	serverUrl := viper.GetString("SERVER_URL")
+	if serverUrl == "" {
+		return domain.ErrServerURLNotSet
+	}
+	link := serverUrl + "/api/v1/account/restore?token=" + token
+
+	deletedTemplate := `
+		<html>
+		<body>
+			<h1>Your account was deleted</h1>
+			<p>Your account and its data will be permanently erased after ` + purgeAt.UTC().Format(time.RFC1123) + `.</p>
+			<p>Changed your mind? <a href="` + link + `">Click here to restore your account</a> before then.</p>
+		</body>
+		</html>
+	`
+
+	return s.emailService.SendEmail(email, "Your account was deleted", deletedTemplate)
+}
+
+func (s *AccountService) SendEmailChangeEmail(ctx context.Context, email string, token string) error {
+	ctx, span := s.tracer.Start(ctx, "SendEmailChangeEmail")
+	defer span.End()
+
+	serverUrl := viper.GetString("SERVER_URL")
+	if serverUrl == "" {
+		return domain.ErrServerURLNotSet
+	}
+	link := serverUrl + "/api/v1/account/change-email/confirm?token=" + token
+
+	emailChangeTemplate := `
+		<html>
+		<body>
+			<h1>Confirm your new email</h1>
+			<p><a href="` + link + `">Click here to use this address for your account</a></p>
+			<p>If you did not ask to change the email of your account, please ignore this email.</p>
+		</body>
+		</html>
+	`
+
+	return s.emailService.SendEmail(email, "Confirm your new email", emailChangeTemplate)
+}
+
+func (s *AccountService) SendEmailChangeNoticeEmail(ctx context.Context, email string, newEmail string, token string) error {
+	ctx, span := s.tracer.Start(ctx, "SendEmailChangeNoticeEmail")
+	defer span.End()
+
+	serverUrl := viper.GetString("SERVER_URL")
+	if serverUrl == "" {
+		return domain.ErrServerURLNotSet
+	}
+	link := serverUrl + "/api/v1/account/change-email/cancel?token=" + token
+
+	noticeTemplate := `
+		<html>
+		<body>
+			<h1>Your email is about to change</h1>
+			<p>A change of the email of your account to ` + html.EscapeString(newEmail) + ` was requested. It takes effect once the new address is confirmed.</p>
+			<p>If you did not request it, <a href="` + link + `">click here to cancel the change</a> and reset your password.</p>
+		</body>
+		</html>
+	`
+
+	return s.emailService.SendEmail(email, "Your email is about to change", noticeTemplate)
+}
+
+func (s *AccountService) SendNewSignInEmail(ctx context.Context, email string, session *domain.Session, token string) error {
+	ctx, span := s.tracer.Start(ctx, "SendNewSignInEmail")
+	defer span.End()
+
+	serverUrl := viper.GetString("SERVER_URL")
+	if serverUrl == "" {
+		return domain.ErrServerURLNotSet
+	}
+	link := serverUrl + "/api/v1/account/sign-in/report?token=" + token
+
+	signInTemplate := `
+		<html>
+		<body>
+			<h1>New sign-in to your account</h1>
+			<p>Your account was signed in to from ` + html.EscapeString(session.DeviceLabel) + ` at ` + html.EscapeString(session.IPAddress) + ` on ` + session.CreatedAt.UTC().Format(time.RFC1123) + `.</p>
+			<p>If this was you, you can ignore this email.</p>
+			<p>If this wasn't you, <a href="` + link + `">click here to sign out everywhere</a>, you will then have to reset your password.</p>
+		</body>
+		</html>
+	`
+
+	return s.emailService.SendEmail(email, "New sign-in to your account", signInTemplate)
+}
+
+// GenerateInvitationToken signs the invitation and its email into a token expiring with the invitation,
+// the invitation record still decides whether it can be answered
+func (s *AccountService) GenerateInvitationToken(ctx context.Context, invitation *domain.Invitation) (string, error) {
+	ctx, span := s.tracer.Start(ctx, "GenerateInvitationToken")
+	defer span.End()
+
+	return s.signToken(jwt.MapClaims{
+		"sub":   strconv.FormatUint(uint64(invitation.ID), 10) + ":invitation",
+		"email": invitation.Email,
+		"iss":   "go_starter_api",
+		"iat":   time.Now().Unix(),
+		"exp":   invitation.ExpiresAt.Unix(),
+	})
+}
+
+// ValidateInvitationToken returns the invitation id and the email the token was issued for
+func (s *AccountService) ValidateInvitationToken(ctx context.Context, token string) (uint, string, error) {
+	ctx, span := s.tracer.Start(ctx, "ValidateInvitationToken")
+	defer span.End()
+
+	parsed, err := s.parseToken(token)
+	if err != nil {
+		return 0, "", err
+	}
+
+	invitationID, err := purposeSubject(parsed, "invitation")
+	if err != nil {
+		return 0, "", err
+	}
+
+	email, ok := parsed.Claims.(jwt.MapClaims)["email"].(string)
+	if !ok || email == "" {
+		return 0, "", ErrEmailClaimNotFound
+	}
+
+	return invitationID, email, nil
+}
+
+func (s *AccountService) SendInvitationEmail(ctx context.Context, email string, organizationName string, token string) error {
+	ctx, span := s.tracer.Start(ctx, "SendInvitationEmail")
+	defer span.End()
+
+	serverUrl := viper.GetString("SERVER_URL")
+	if serverUrl == "" {
+		return domain.ErrServerURLNotSet
+	}
+	link := serverUrl + "/api/v1/invitations/accept?token=" + token
+
+	// the name is chosen by the organization, it must not inject markup or headers
+	organizationName = strings.Join(strings.Fields(organizationName), " ")
+	name := html.EscapeString(organizationName)
+
+	invitationTemplate := `
+		<html>
+		<body>
+			<h1>You are invited to join ` + name + `</h1>
+			<p><a href="` + link + `">Click here to accept the invitation</a></p>
+			<p>If you do not want to join, you can ignore this email or decline the invitation.</p>
+		</body>
+		</html>
+	`
+
+	return s.emailService.SendEmail(email, "You are invited to join "+organizationName, invitationTemplate)
+}
+
+// GenerateDataExportToken signs the export into the token of its download link, expiring with the export
+func (s *AccountService) GenerateDataExportToken(ctx context.Context, export *domain.DataExport) (string, error) {
+	ctx, span := s.tracer.Start(ctx, "GenerateDataExportToken")
+	defer span.End()
+
+	if export.Status != domain.DataExportStatusReady || export.ExpiresAt == nil {
+		return "", domain.ErrDataExportNotReady
+	}
+
+	return s.signToken(jwt.MapClaims{
+		"sub": strconv.FormatUint(uint64(export.ID), 10) + ":data-export",
+		"iss": "go_starter_api",
+		"iat": time.Now().Unix(),
+		"exp": export.ExpiresAt.Unix(),
+	})
+}
+
+// ValidateDataExportToken returns the id of the export the token was issued for
+func (s *AccountService) ValidateDataExportToken(ctx context.Context, token string) (uint, error) {
+	ctx, span := s.tracer.Start(ctx, "ValidateDataExportToken")
+	defer span.End()
+
+	parsed, err := s.parseToken(token)
+	if err != nil {
+		return 0, err
+	}
+
+	return purposeSubject(parsed, "data-export")
+}
+
+func (s *AccountService) SendDataExportReadyEmail(ctx context.Context, email string, expiresAt time.Time, token string) error {
+	ctx, span := s.tracer.Start(ctx, "SendDataExportReadyEmail")
+	defer span.End()
+
+	serverUrl := viper.GetString("SERVER_URL")
+	if serverUrl == "" {
+		return domain.ErrServerURLNotSet
+	}
+	link := serverUrl + "/api/v1/account/export/download?token=" + token
+
+	readyTemplate := `
+		<html>
+		<body>
+			<h1>Your data export is ready</h1>
+			<p><a href="` + link + `">Click here to download the archive of your data</a></p>
+			<p>The link expires on ` + expiresAt.UTC().Format(time.RFC1123) + `. If you did not request the export, change your password.</p>
+		</body>
+		</html>
+	`
+
+	return s.emailService.SendEmail(email, "Your data export is ready", readyTemplate)
+}
+
+// GenerateOAuthClient returns the secret of a new client and the record to persist for it,
+// public clients get no secret
+func (s *AccountService) GenerateOAuthClient(ctx context.Context, public bool) (string, *domain.OAuthClient, error) {
+	ctx, span := s.tracer.Start(ctx, "GenerateOAuthClient")
+	defer span.End()
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", nil, err
+	}
+
+	client := &domain.OAuthClient{
+		ClientID: hex.EncodeToString(id),
+		Public:   public,
+	}
+	if public {
+		return "", client, nil
+	}
+
+	secret, err := randomToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+	client.SecretHash = s.HashToken(ctx, secret)
+
+	return secret, client, nil
+}
+
+// GenerateIDToken returns the id token of the account for the client, the email is only
+// shared when the email scope was approved
+func (s *AccountService) GenerateIDToken(ctx context.Context, account *domain.Account, clientID string, nonce string, scopes []string) (string, error) {
+	ctx, span := s.tracer.Start(ctx, "GenerateIDToken")
+	defer span.End()
+
+	issuer := oidcIssuer()
+	if issuer == "" {
+		return "", domain.ErrServerURLNotSet
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss": issuer,
+		"sub": strconv.FormatUint(uint64(account.ID), 10),
+		"aud": clientID,
+		"iat": now.Unix(),
+		"exp": now.Add(accessTokenTTL()).Unix(),
+	}
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+	if slices.Contains(scopes, domain.ScopeEmail) {
+		claims["email"] = account.Email
+		claims["email_verified"] = account.EmailVerified
+	}
+
+	return s.signKeyRingToken(claims, "JWT")
+}
+
+// GenerateOAuthAccessToken returns an access token of the authorization server, the
+// subject is an account id or, for client credentials, the client id
+func (s *AccountService) GenerateOAuthAccessToken(ctx context.Context, subject string, clientID string, scopes []string) (string, error) {
+	ctx, span := s.tracer.Start(ctx, "GenerateOAuthAccessToken")
+	defer span.End()
+
+	issuer := oidcIssuer()
+	if issuer == "" {
+		return "", domain.ErrServerURLNotSet
+	}
+
+	tokenID, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	return s.signKeyRingToken(jwt.MapClaims{
+		"iss":       issuer,
+		"sub":       subject,
+		"aud":       issuer,
+		"client_id": clientID,
+		"scope":     strings.Join(scopes, " "),
+		"jti":       tokenID,
+		"iat":       now.Unix(),
+		"exp":       now.Add(accessTokenTTL()).Unix(),
+	}, oauthAccessTokenType)
+}
+
+func (s *AccountService) ParseOAuthAccessToken(ctx context.Context, token string) (*domain.OAuthAccessClaims, error) {
+	ctx, span := s.tracer.Start(ctx, "ParseOAuthAccessToken")
+	defer span.End()
+
+	if s.keyRing == nil {
+		return nil, ErrKeyRingNotSet
+	}
+
+	parsed, err := s.parseToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if tokenType, _ := parsed.Header["typ"].(string); tokenType != oauthAccessTokenType {
+		return nil, ErrInvalidAccessToken
+	}
+
+	mapClaims := parsed.Claims.(jwt.MapClaims)
+
+	if issuer, err := mapClaims.GetIssuer(); err != nil || issuer != oidcIssuer() {
+		return nil, ErrInvalidAccessToken
+	}
+
+	subject, err := mapClaims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, ErrSubjectClaimNotFound
+	}
+
+	claims := &domain.OAuthAccessClaims{Subject: subject}
+	claims.ClientID, _ = mapClaims["client_id"].(string)
+	if scope, ok := mapClaims["scope"].(string); ok {
+		claims.Scopes = strings.Fields(scope)
+	}
+	if exp, err := mapClaims.GetExpirationTime(); err == nil && exp != nil {
+		claims.ExpiresAt = exp.Time
+	}
+
+	return claims, nil
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"go_starter_api/internal/account"
 	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/keyring"
 	"go_starter_api/pkg/mailer"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -20,7 +24,7 @@ func TestAccountService_HashPassword(t *testing.T) {
 
 	emailService := mailer.NewMockEmailService(t)
 	t.Run("should hash and compare password correctly", func(t *testing.T) {
-		service := account.NewAccountService(emailService)
+		service := account.NewAccountService(emailService, nil)
 
 		password := "password"
 		hash, err := service.HashPassword(context.Background(), password)
@@ -37,13 +41,21 @@ func TestAccountService_HashPassword(t *testing.T) {
 	})
 
 	t.Run("should return error if password is empty", func(t *testing.T) {
-		service := account.NewAccountService(nil)
+		service := account.NewAccountService(nil, nil)
 
 		password := ""
 		hash, err := service.HashPassword(context.Background(), password)
 		assert.ErrorIs(t, err, domain.ErrPasswordEmpty)
 		assert.Empty(t, hash)
 	})
+
+	t.Run("should not match any password of an account without one", func(t *testing.T) {
+		service := account.NewAccountService(nil, nil)
+
+		ok, err := service.ComparePassword(context.Background(), "", "")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
 }
 
 func TestAccountService_GenerateAndValidateToken(t *testing.T) {
@@ -52,13 +64,13 @@ func TestAccountService_GenerateAndValidateToken(t *testing.T) {
 	defer viper.Reset()
 
 	emailService := mailer.NewMockEmailService(t)
-	service := account.NewAccountService(emailService)
+	service := account.NewAccountService(emailService, nil)
 
 	t.Run("should generate and validate token correctly", func(t *testing.T) {
 		account := &domain.Account{ID: 123, Email: "test@example.com"}
 
 		// Generate token
-		token, err := service.GenerateAuthToken(context.Background(), account)
+		token, err := service.GenerateAuthToken(context.Background(), account, 7, 0)
 		assert.NoError(t, err)
 		assert.NotEmpty(t, token)
 
@@ -68,12 +80,44 @@ func TestAccountService_GenerateAndValidateToken(t *testing.T) {
 		assert.Equal(t, uint(123), accountID)
 	})
 
+	t.Run("should give every token a unique id", func(t *testing.T) {
+		account := &domain.Account{ID: 123, Email: "test@example.com"}
+
+		first, err := service.GenerateAuthToken(context.Background(), account, 7, 0)
+		assert.NoError(t, err)
+		second, err := service.GenerateAuthToken(context.Background(), account, 7, 0)
+		assert.NoError(t, err)
+
+		firstClaims, err := service.ParseAuthToken(context.Background(), first)
+		assert.NoError(t, err)
+		secondClaims, err := service.ParseAuthToken(context.Background(), second)
+		assert.NoError(t, err)
+
+		assert.NotEmpty(t, firstClaims.TokenID)
+		assert.NotEqual(t, firstClaims.TokenID, secondClaims.TokenID)
+		assert.Equal(t, uint(123), firstClaims.AccountID)
+		assert.Equal(t, uint(7), firstClaims.SessionID)
+		assert.True(t, firstClaims.ExpiresAt.After(firstClaims.IssuedAt))
+		assert.Equal(t, uint(0), firstClaims.OrganizationID)
+	})
+
+	t.Run("should carry the active organization", func(t *testing.T) {
+		account := &domain.Account{ID: 123, Email: "test@example.com"}
+
+		token, err := service.GenerateAuthToken(context.Background(), account, 7, 3)
+		assert.NoError(t, err)
+
+		claims, err := service.ParseAuthToken(context.Background(), token)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(3), claims.OrganizationID)
+	})
+
 	t.Run("should return error if JWT secret is not set", func(t *testing.T) {
 		// Temporarily unset JWT secret
 		viper.Set("JWT_SECRET", "")
 
 		account := &domain.Account{ID: 1, Email: "test@test.com"}
-		token, err := service.GenerateAuthToken(context.Background(), account)
+		token, err := service.GenerateAuthToken(context.Background(), account, 7, 0)
 		assert.Error(t, err)
 		assert.Empty(t, token)
 	})
@@ -93,49 +137,410 @@ func TestAccountService_GenerateAndValidateToken(t *testing.T) {
 	})
 }
 
-func TestAccountService_GenerateAndValidatePasswordResetToken(t *testing.T) {
+func TestAccountService_GenerateAndValidateTokenWithKeyRing(t *testing.T) {
 	viper.Set("JWT_SECRET", "test_secret_key_for_jwt_validation")
 	defer viper.Reset()
 
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	acc := &domain.Account{ID: 123, Email: "test@example.com"}
+
+	for _, algorithm := range []string{keyring.AlgorithmRS256, keyring.AlgorithmES256, keyring.AlgorithmEdDSA} {
+		t.Run("should sign and validate "+algorithm+" tokens", func(t *testing.T) {
+			dir := t.TempDir()
+			key, err := keyring.GenerateKey(algorithm)
+			assert.NoError(t, err)
+			assert.NoError(t, keyring.SaveKey(dir, key))
+
+			keyRing, err := keyring.LoadDir(dir, "")
+			assert.NoError(t, err)
+
+			service := account.NewAccountService(nil, keyRing)
+
+			token, err := service.GenerateAuthToken(context.Background(), acc, 7, 0)
+			assert.NoError(t, err)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+			assert.NoError(t, err)
+			assert.Equal(t, key.ID, parsed.Header["kid"])
+			assert.Equal(t, algorithm, parsed.Header["alg"])
+
+			claims, err := service.ParseAuthToken(context.Background(), token)
+			assert.NoError(t, err)
+			assert.Equal(t, uint(123), claims.AccountID)
+		})
+	}
+
+	t.Run("should keep accepting tokens signed with a rotated out key", func(t *testing.T) {
+		dir := t.TempDir()
+		oldKey, _ := keyring.GenerateKey(keyring.AlgorithmES256)
+		assert.NoError(t, keyring.SaveKey(dir, oldKey))
+
+		oldRing, err := keyring.LoadDir(dir, oldKey.ID)
+		assert.NoError(t, err)
+		token, err := account.NewAccountService(nil, oldRing).GenerateAuthToken(context.Background(), acc, 7, 0)
+		assert.NoError(t, err)
+
+		newKey, _ := keyring.GenerateKey(keyring.AlgorithmEdDSA)
+		assert.NoError(t, keyring.SaveKey(dir, newKey))
+
+		newRing, err := keyring.LoadDir(dir, newKey.ID)
+		assert.NoError(t, err)
+		service := account.NewAccountService(nil, newRing)
+
+		accountID, err := service.ValidateAuthToken(context.Background(), token)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(123), accountID)
+
+		// once the old key is removed its tokens are rejected
+		assert.NoError(t, keyring.RemoveKey(dir, oldKey.ID))
+		prunedRing, err := keyring.LoadDir(dir, newKey.ID)
+		assert.NoError(t, err)
+
+		_, err = account.NewAccountService(nil, prunedRing).ValidateAuthToken(context.Background(), token)
+		assert.Error(t, err)
+	})
+
+	t.Run("should reject tokens signed with the shared secret", func(t *testing.T) {
+		dir := t.TempDir()
+		key, _ := keyring.GenerateKey(keyring.AlgorithmES256)
+		assert.NoError(t, keyring.SaveKey(dir, key))
+		keyRing, err := keyring.LoadDir(dir, "")
+		assert.NoError(t, err)
+
+		token, err := account.NewAccountService(nil, nil).GenerateAuthToken(context.Background(), acc, 7, 0)
+		assert.NoError(t, err)
+
+		_, err = account.NewAccountService(nil, keyRing).ValidateAuthToken(context.Background(), token)
+		assert.Error(t, err)
+	})
+}
+
+func TestAccountService_GenerateRefreshToken(t *testing.T) {
 	emailService := mailer.NewMockEmailService(t)
-	service := account.NewAccountService(emailService)
+	service := account.NewAccountService(emailService, nil)
 
-	t.Run("should generate and validate password reset token correctly", func(t *testing.T) {
+	t.Run("should generate a refresh token in a new family", func(t *testing.T) {
 		account := &domain.Account{ID: 123, Email: "test@example.com"}
 
-		// Generate token
-		token, err := service.GeneratePasswordResetToken(context.Background(), account)
+		token, record, err := service.GenerateRefreshToken(context.Background(), account, "")
 		assert.NoError(t, err)
 		assert.NotEmpty(t, token)
+		assert.NotEmpty(t, record.FamilyID)
+		assert.Equal(t, uint(123), record.AccountID)
+		assert.Equal(t, service.HashToken(context.Background(), token), record.TokenHash)
+		assert.NotEqual(t, token, record.TokenHash)
+		assert.True(t, record.ExpiresAt.After(time.Now()))
+	})
 
-		// Validate token
-		accountID, err := service.ValidatePasswordResetToken(context.Background(), token)
+	t.Run("should keep the family of a rotated token", func(t *testing.T) {
+		account := &domain.Account{ID: 123, Email: "test@example.com"}
+
+		first, _, err := service.GenerateRefreshToken(context.Background(), account, "family")
+		assert.NoError(t, err)
+
+		second, record, err := service.GenerateRefreshToken(context.Background(), account, "family")
+		assert.NoError(t, err)
+		assert.Equal(t, "family", record.FamilyID)
+		assert.NotEqual(t, first, second)
+	})
+}
+
+func TestAccountService_GenerateOneTimeToken(t *testing.T) {
+	viper.Set("PASSWORD_RESET_TOKEN_TTL", "10m")
+	defer viper.Reset()
+
+	emailService := mailer.NewMockEmailService(t)
+	service := account.NewAccountService(emailService, nil)
+
+	t.Run("should generate a token and the hashed record to store", func(t *testing.T) {
+		account := &domain.Account{ID: 123, Email: "test@example.com"}
+
+		token, record, err := service.GenerateOneTimeToken(context.Background(), account, domain.TokenPurposePasswordReset)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, token)
+		assert.Equal(t, uint(123), record.AccountID)
+		assert.Equal(t, domain.TokenPurposePasswordReset, record.Purpose)
+		assert.Equal(t, service.HashToken(context.Background(), token), record.TokenHash)
+		assert.NotEqual(t, token, record.TokenHash)
+		assert.WithinDuration(t, time.Now().Add(10*time.Minute), record.ExpiresAt, time.Minute)
+		assert.Nil(t, record.UsedAt)
+	})
+
+	t.Run("should generate a unique token every time", func(t *testing.T) {
+		account := &domain.Account{ID: 123, Email: "test@example.com"}
+
+		first, _, err := service.GenerateOneTimeToken(context.Background(), account, domain.TokenPurposePasswordReset)
+		assert.NoError(t, err)
+		second, _, err := service.GenerateOneTimeToken(context.Background(), account, domain.TokenPurposePasswordReset)
+		assert.NoError(t, err)
+		assert.NotEqual(t, first, second)
+	})
+}
+
+func TestAccountService_GenerateAPIKey(t *testing.T) {
+	service := account.NewAccountService(mailer.NewMockEmailService(t), nil)
+
+	t.Run("should generate a key starting with its lookup prefix", func(t *testing.T) {
+		account := &domain.Account{ID: 123, Email: "test@example.com"}
+
+		key, record, err := service.GenerateAPIKey(context.Background(), account)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(123), record.AccountID)
+		assert.True(t, strings.HasPrefix(record.Prefix, domain.APIKeyPrefix))
+		assert.True(t, strings.HasPrefix(key, record.Prefix+"_"))
+		assert.Equal(t, service.HashToken(context.Background(), key), record.TokenHash)
+
+		other, otherRecord, err := service.GenerateAPIKey(context.Background(), account)
+		assert.NoError(t, err)
+		assert.NotEqual(t, key, other)
+		assert.NotEqual(t, record.Prefix, otherRecord.Prefix)
+	})
+}
+
+func TestAccountService_AuthorizationServerTokens(t *testing.T) {
+	viper.Set("SERVER_URL", "http://localhost:8080")
+	defer viper.Reset()
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	dir := t.TempDir()
+	key, _ := keyring.GenerateKey(keyring.AlgorithmES256)
+	assert.NoError(t, keyring.SaveKey(dir, key))
+	keyRing, err := keyring.LoadDir(dir, "")
+	assert.NoError(t, err)
+
+	service := account.NewAccountService(nil, keyRing)
+	acc := &domain.Account{ID: 123, Email: "test@example.com", EmailVerified: true}
+
+	t.Run("should sign an id token for the client", func(t *testing.T) {
+		token, err := service.GenerateIDToken(context.Background(), acc, "client", "nonce", []string{domain.ScopeOpenID, domain.ScopeEmail})
+		assert.NoError(t, err)
+
+		parsed, err := jwt.Parse(token, func(*jwt.Token) (interface{}, error) { return key.Public(), nil })
+		assert.NoError(t, err)
+		claims := parsed.Claims.(jwt.MapClaims)
+		assert.Equal(t, key.ID, parsed.Header["kid"])
+		assert.Equal(t, "http://localhost:8080", claims["iss"])
+		assert.Equal(t, "123", claims["sub"])
+		assert.Equal(t, "client", claims["aud"])
+		assert.Equal(t, "nonce", claims["nonce"])
+		assert.Equal(t, "test@example.com", claims["email"])
+		assert.Equal(t, true, claims["email_verified"])
+
+		// without the email scope the email is not shared
+		token, err = service.GenerateIDToken(context.Background(), acc, "client", "", []string{domain.ScopeOpenID})
+		assert.NoError(t, err)
+		parsed, _, err = jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+		assert.NoError(t, err)
+		assert.NotContains(t, parsed.Claims.(jwt.MapClaims), "email")
+		assert.NotContains(t, parsed.Claims.(jwt.MapClaims), "nonce")
+	})
+
+	t.Run("should parse its access tokens", func(t *testing.T) {
+		token, err := service.GenerateOAuthAccessToken(context.Background(), "123", "client", []string{domain.ScopeOpenID, domain.ScopeEmail})
+		assert.NoError(t, err)
+
+		claims, err := service.ParseOAuthAccessToken(context.Background(), token)
+		assert.NoError(t, err)
+		assert.Equal(t, "123", claims.Subject)
+		assert.Equal(t, "client", claims.ClientID)
+		assert.Equal(t, []string{domain.ScopeOpenID, domain.ScopeEmail}, claims.Scopes)
+		assert.True(t, claims.ExpiresAt.After(time.Now()))
+	})
+
+	t.Run("should not mistake one token for another", func(t *testing.T) {
+		accessToken, err := service.GenerateOAuthAccessToken(context.Background(), "123", "client", []string{domain.ScopeOpenID})
+		assert.NoError(t, err)
+		idToken, err := service.GenerateIDToken(context.Background(), acc, "client", "", []string{domain.ScopeOpenID})
+		assert.NoError(t, err)
+		authToken, err := service.GenerateAuthToken(context.Background(), acc, 7, 0)
+		assert.NoError(t, err)
+
+		// the access tokens of clients are no logins to this api
+		_, err = service.ParseAuthToken(context.Background(), accessToken)
+		assert.Error(t, err)
+		_, err = service.ParseAuthToken(context.Background(), idToken)
+		assert.Error(t, err)
+
+		_, err = service.ParseOAuthAccessToken(context.Background(), idToken)
+		assert.Error(t, err)
+		_, err = service.ParseOAuthAccessToken(context.Background(), authToken)
+		assert.Error(t, err)
+	})
+
+	t.Run("should refuse to sign without a keyring", func(t *testing.T) {
+		viper.Set("JWT_SECRET", "test_secret_key_for_jwt_validation")
+
+		_, err := account.NewAccountService(nil, nil).GenerateIDToken(context.Background(), acc, "client", "", []string{domain.ScopeOpenID})
+		assert.ErrorIs(t, err, account.ErrKeyRingNotSet)
+	})
+
+	t.Run("should generate client credentials", func(t *testing.T) {
+		secret, client, err := service.GenerateOAuthClient(context.Background(), false)
+		assert.NoError(t, err)
+		assert.Len(t, client.ClientID, 32)
+		assert.NotEmpty(t, secret)
+		assert.Equal(t, service.HashToken(context.Background(), secret), client.SecretHash)
+
+		secret, client, err = service.GenerateOAuthClient(context.Background(), true)
+		assert.NoError(t, err)
+		assert.Empty(t, secret)
+		assert.Empty(t, client.SecretHash)
+		assert.True(t, client.Public)
+	})
+}
+
+func TestAccountService_GenerateAndValidateMFAToken(t *testing.T) {
+	viper.Set("JWT_SECRET", "test_secret_key_for_jwt_validation")
+	defer viper.Reset()
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	service := account.NewAccountService(nil, nil)
+	acc := &domain.Account{ID: 123, Email: "test@example.com"}
+
+	t.Run("should generate and validate mfa token correctly", func(t *testing.T) {
+		token, err := service.GenerateMFAToken(context.Background(), acc)
+		assert.NoError(t, err)
+
+		claims, err := service.ValidateMFAToken(context.Background(), token)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(123), claims.AccountID)
+		assert.NotEmpty(t, claims.TokenID)
+		assert.WithinDuration(t, time.Now().Add(5*time.Minute), claims.ExpiresAt, 2*time.Second)
+
+		// every token can be revoked on its own
+		other, err := service.GenerateMFAToken(context.Background(), acc)
+		assert.NoError(t, err)
+		otherClaims, err := service.ValidateMFAToken(context.Background(), other)
+		assert.NoError(t, err)
+		assert.NotEqual(t, claims.TokenID, otherClaims.TokenID)
+	})
+
+	t.Run("should not accept an mfa token as an auth or email verification token", func(t *testing.T) {
+		token, err := service.GenerateMFAToken(context.Background(), acc)
+		assert.NoError(t, err)
+
+		_, err = service.ValidateAuthToken(context.Background(), token)
+		assert.Error(t, err)
+
+		_, _, err = service.ValidateEmailVerificationToken(context.Background(), token)
+		assert.Error(t, err)
+	})
+
+	t.Run("should not accept an auth token as an mfa token", func(t *testing.T) {
+		token, err := service.GenerateAuthToken(context.Background(), acc, 7, 0)
+		assert.NoError(t, err)
+
+		_, err = service.ValidateMFAToken(context.Background(), token)
+		assert.Error(t, err)
+	})
+}
+
+func TestAccountService_GenerateAndValidateEmailVerificationToken(t *testing.T) {
+	viper.Set("JWT_SECRET", "test_secret_key_for_jwt_validation")
+	defer viper.Reset()
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	service := account.NewAccountService(nil, nil)
+	acc := &domain.Account{ID: 123, Email: "test@example.com"}
+
+	t.Run("should carry the account id and email", func(t *testing.T) {
+		token, err := service.GenerateEmailVerificationToken(context.Background(), acc)
+		assert.NoError(t, err)
+
+		accountID, email, err := service.ValidateEmailVerificationToken(context.Background(), token)
 		assert.NoError(t, err)
 		assert.Equal(t, uint(123), accountID)
+		assert.Equal(t, "test@example.com", email)
 	})
 
-	t.Run("should return error if JWT secret is not set", func(t *testing.T) {
-		viper.Set("JWT_SECRET", "")
-		defer viper.Reset()
+	t.Run("should not accept an mfa token", func(t *testing.T) {
+		token, err := service.GenerateMFAToken(context.Background(), acc)
+		assert.NoError(t, err)
 
-		account := &domain.Account{ID: 1, Email: "test@test.com"}
-		token, err := service.GeneratePasswordResetToken(context.Background(), account)
+		_, _, err = service.ValidateEmailVerificationToken(context.Background(), token)
 		assert.Error(t, err)
-		assert.Empty(t, token)
 	})
+}
 
-	t.Run("should return error if token is invalid", func(t *testing.T) {
-		invalidToken := "invalid_token"
-		accountID, err := service.ValidatePasswordResetToken(context.Background(), invalidToken)
+func TestAccountService_GenerateAndValidateInvitationToken(t *testing.T) {
+	viper.Set("JWT_SECRET", "test_secret_key_for_jwt_validation")
+	defer viper.Reset()
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	service := account.NewAccountService(nil, nil)
+
+	t.Run("should carry the invitation id and email", func(t *testing.T) {
+		token, err := service.GenerateInvitationToken(context.Background(), &domain.Invitation{ID: 9, Email: "new@example.com", ExpiresAt: time.Now().Add(time.Hour)})
+		assert.NoError(t, err)
+
+		invitationID, email, err := service.ValidateInvitationToken(context.Background(), token)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(9), invitationID)
+		assert.Equal(t, "new@example.com", email)
+	})
+
+	t.Run("should expire with the invitation", func(t *testing.T) {
+		token, err := service.GenerateInvitationToken(context.Background(), &domain.Invitation{ID: 9, Email: "new@example.com", ExpiresAt: time.Now().Add(-time.Minute)})
+		assert.NoError(t, err)
+
+		_, _, err = service.ValidateInvitationToken(context.Background(), token)
 		assert.Error(t, err)
-		assert.Equal(t, uint(0), accountID)
 	})
 
-	t.Run("should return error if token is malformed", func(t *testing.T) {
-		malformedToken := "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.invalid"
-		accountID, err := service.ValidatePasswordResetToken(context.Background(), malformedToken)
+	t.Run("should not accept an email verification token", func(t *testing.T) {
+		token, err := service.GenerateEmailVerificationToken(context.Background(), &domain.Account{ID: 9, Email: "new@example.com"})
+		assert.NoError(t, err)
+
+		_, _, err = service.ValidateInvitationToken(context.Background(), token)
+		assert.Error(t, err)
+	})
+}
+
+func TestAccountService_GenerateAndValidateDataExportToken(t *testing.T) {
+	viper.Set("JWT_SECRET", "test_secret_key_for_jwt_validation")
+	defer viper.Reset()
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	service := account.NewAccountService(nil, nil)
+
+	ready := func(expiresAt time.Time) *domain.DataExport {
+		return &domain.DataExport{ID: 4, Status: domain.DataExportStatusReady, ExpiresAt: &expiresAt}
+	}
+
+	t.Run("should carry the export id", func(t *testing.T) {
+		token, err := service.GenerateDataExportToken(context.Background(), ready(time.Now().Add(time.Hour)))
+		assert.NoError(t, err)
+
+		exportID, err := service.ValidateDataExportToken(context.Background(), token)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(4), exportID)
+	})
+
+	t.Run("should expire with the export", func(t *testing.T) {
+		token, err := service.GenerateDataExportToken(context.Background(), ready(time.Now().Add(-time.Minute)))
+		assert.NoError(t, err)
+
+		_, err = service.ValidateDataExportToken(context.Background(), token)
+		assert.Error(t, err)
+	})
+
+	t.Run("should not sign an export that is not ready", func(t *testing.T) {
+		_, err := service.GenerateDataExportToken(context.Background(), &domain.DataExport{ID: 4, Status: domain.DataExportStatusProcessing})
+		assert.ErrorIs(t, err, domain.ErrDataExportNotReady)
+	})
+
+	t.Run("should not accept an mfa token", func(t *testing.T) {
+		token, err := service.GenerateMFAToken(context.Background(), &domain.Account{ID: 4})
+		assert.NoError(t, err)
+
+		_, err = service.ValidateDataExportToken(context.Background(), token)
 		assert.Error(t, err)
-		assert.Equal(t, uint(0), accountID)
 	})
 }
 
@@ -157,7 +562,7 @@ func TestAccountService_SendPasswordResetEmail(t *testing.T) {
 			Return(nil).
 			Once()
 
-		service := account.NewAccountService(emailService)
+		service := account.NewAccountService(emailService, nil)
 
 		email := "test@example.com"
 		token := "test_token"
@@ -170,7 +575,7 @@ func TestAccountService_SendPasswordResetEmail(t *testing.T) {
 		defer viper.Reset()
 
 		emailService := mailer.NewMockEmailService(t)
-		service := account.NewAccountService(emailService)
+		service := account.NewAccountService(emailService, nil)
 
 		email := "test@example.com"
 		token := "test_token"
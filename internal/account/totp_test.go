@@ -0,0 +1,76 @@
+package account
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestHOTPCode(t *testing.T) {
+	// test vectors from RFC 4226 appendix D
+	secret := []byte("12345678901234567890")
+	expected := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+
+	for counter, code := range expected {
+		assert.Equal(t, code, hotpCode(secret, int64(counter)))
+	}
+}
+
+func TestAccountService_VerifyTOTPCode(t *testing.T) {
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	service := NewAccountService(nil, nil)
+	ctx := context.Background()
+
+	secret, err := service.GenerateTOTPSecret(ctx)
+	assert.NoError(t, err)
+
+	key, err := decodeTOTPSecret(secret)
+	assert.NoError(t, err)
+	assert.Len(t, key, 20)
+
+	t.Run("should accept the current code and its neighbours", func(t *testing.T) {
+		current := totpStep(time.Now())
+		for _, step := range []int64{current - 1, current, current + 1} {
+			matched, err := service.VerifyTOTPCode(ctx, secret, hotpCode(key, step))
+			assert.NoError(t, err)
+			assert.Equal(t, step, matched)
+		}
+	})
+
+	t.Run("should reject codes outside of the window", func(t *testing.T) {
+		_, err := service.VerifyTOTPCode(ctx, secret, hotpCode(key, totpStep(time.Now())-5))
+		assert.Error(t, err)
+	})
+
+	t.Run("should reject malformed codes", func(t *testing.T) {
+		for _, code := range []string{"", "12345", "1234567", "abcdef"} {
+			_, err := service.VerifyTOTPCode(ctx, secret, code)
+			assert.Error(t, err)
+		}
+	})
+}
+
+func TestAccountService_GenerateRecoveryCodes(t *testing.T) {
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	service := NewAccountService(nil, nil)
+
+	codes, err := service.GenerateRecoveryCodes(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, codes, recoveryCodeCount)
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		assert.Len(t, code, 19)
+		assert.Len(t, normalizeRecoveryCode(code), 16)
+		assert.Equal(t, normalizeRecoveryCode(code), normalizeRecoveryCode(strings.ToUpper(code)))
+		assert.False(t, seen[code])
+		seen[code] = true
+	}
+}
@@ -0,0 +1,151 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"go_starter_api/pkg/domain"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// @Summary		Verify Email
+// @Description	Mark the email of an account as verified with the token from the verification link
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			account	body		VerifyEmailRequest	true	"Token"
+// @Success		200		{object}	map[string]string
+// @Failure		400		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/verify-email [post]
+func (h *AccountHandler) VerifyEmail(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "VerifyEmail")
+	defer span.End()
+
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountID, email, err := h.accountService.ValidateEmailVerificationToken(ctx, req.Token)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+		return
+	}
+
+	acc, err := h.accountRepository.GetAccountByID(ctx, accountID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+			return
+		}
+		h.logger.WithField("userId", accountID).Errorf("failed to get account by id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// the token was sent to an address the account no longer uses
+	if acc.Email != email {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+		return
+	}
+
+	if acc.EmailVerified {
+		c.JSON(http.StatusOK, gin.H{"message": "email already verified"})
+		return
+	}
+
+	now := time.Now()
+	acc.EmailVerified = true
+	acc.VerifiedAt = &now
+
+	_, err = h.accountRepository.UpdateAccount(ctx, acc)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to update account: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	err = h.accountRepository.LogAccountActivity(ctx, accountID, domain.ActivityEmailVerified)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to log activity: %v", err)
+	}
+
+	c.JSON(
+		http.StatusOK,
+		gin.H{
+			"message": "email verified",
+		},
+	)
+}
+
+type ResendVerificationEmailRequest struct {
+	Email string `json:"email"`
+}
+
+// @Summary		Resend Verification Email
+// @Description	Send a new verification link. The response is the same whether or not the email belongs to an unverified account.
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			account	body		ResendVerificationEmailRequest	true	"Email"
+// @Success		200		{object}	map[string]string
+// @Failure		400		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/verify-email/resend [post]
+func (h *AccountHandler) ResendVerificationEmail(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "ResendVerificationEmail")
+	defer span.End()
+
+	var req ResendVerificationEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{"message": "if the account exists and is not verified a verification email has been sent"}
+
+	acc, err := h.accountRepository.GetAccountByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusOK, response)
+			return
+		}
+		h.logger.Errorf("failed to get account by email: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if acc.EmailVerified {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	err = h.sendVerificationEmail(ctx, acc)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to send verification email: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send verification email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AccountHandler) sendVerificationEmail(ctx context.Context, acc *domain.Account) error {
+	token, err := h.accountService.GenerateEmailVerificationToken(ctx, acc)
+	if err != nil {
+		return err
+	}
+
+	return h.accountService.SendVerificationEmail(ctx, acc.Email, token)
+}
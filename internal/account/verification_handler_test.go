@@ -0,0 +1,243 @@
+package account_test
+
+import (
+	"context"
+	"go_starter_api/internal/account"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm"
+)
+
+func TestAccountHandler_VerifyEmail(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	t.Run("should verify the email of the account", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := &domain.Account{ID: 1, Email: "test@example.com"}
+		service.On("ValidateEmailVerificationToken", anyContext, "verification_token").Return(uint(1), "test@example.com", nil)
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		repository.On("UpdateAccount", anyContext, acc).Return(acc, nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityEmailVerified).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/verify-email", handler.VerifyEmail)
+
+		w := httpHelper.MakeRequest("POST", "/account/verify-email", account.VerifyEmailRequest{Token: "verification_token"}, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, acc.EmailVerified)
+		assert.NotNil(t, acc.VerifiedAt)
+	})
+
+	t.Run("should reject a token issued for a previous email", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := &domain.Account{ID: 1, Email: "new@example.com"}
+		service.On("ValidateEmailVerificationToken", anyContext, "verification_token").Return(uint(1), "old@example.com", nil)
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/verify-email", handler.VerifyEmail)
+
+		w := httpHelper.MakeRequest("POST", "/account/verify-email", account.VerifyEmailRequest{Token: "verification_token"}, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.False(t, acc.EmailVerified)
+	})
+
+	t.Run("should reject an invalid token", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		service.On("ValidateEmailVerificationToken", anyContext, "invalid").Return(uint(0), "", account.ErrInvalidSubjectClaim)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/verify-email", handler.VerifyEmail)
+
+		w := httpHelper.MakeRequest("POST", "/account/verify-email", account.VerifyEmailRequest{Token: "invalid"}, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestAccountHandler_ResendVerificationEmail(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	t.Run("should send a new verification email to an unverified account", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		acc := &domain.Account{ID: 1, Email: "test@example.com"}
+		repository.On("GetAccountByEmail", anyContext, "test@example.com").Return(acc, nil)
+		service.On("GenerateEmailVerificationToken", anyContext, acc).Return("verification_token", nil)
+		service.On("SendVerificationEmail", anyContext, "test@example.com", "verification_token").Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/verify-email/resend", handler.ResendVerificationEmail)
+
+		reqBody := account.ResendVerificationEmailRequest{Email: "test@example.com"}
+		w := httpHelper.MakeRequest("POST", "/account/verify-email/resend", reqBody, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should not reveal unknown or verified accounts", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		repository.On("GetAccountByEmail", anyContext, "unknown@example.com").Return(nil, gorm.ErrRecordNotFound)
+		repository.On("GetAccountByEmail", anyContext, "verified@example.com").Return(&domain.Account{ID: 2, Email: "verified@example.com", EmailVerified: true}, nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/verify-email/resend", handler.ResendVerificationEmail)
+
+		unknown := httpHelper.MakeRequest("POST", "/account/verify-email/resend", account.ResendVerificationEmailRequest{Email: "unknown@example.com"}, nil)
+		verified := httpHelper.MakeRequest("POST", "/account/verify-email/resend", account.ResendVerificationEmailRequest{Email: "verified@example.com"}, nil)
+
+		assert.Equal(t, http.StatusOK, unknown.Code)
+		assert.Equal(t, unknown.Code, verified.Code)
+		assert.Equal(t, unknown.Body.String(), verified.Body.String())
+	})
+}
+
+func TestAccountHandler_EmailVerificationPolicy(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	viper.Set("EMAIL_VERIFICATION_POLICY", account.EmailVerificationPolicyLogin)
+	defer viper.Reset()
+
+	t.Run("should not return tokens on registration", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		repository.On("GetAccountByEmail", anyContext, "test@example.com").Return(nil, gorm.ErrRecordNotFound)
+		service.On("HashPassword", anyContext, "password").Return("hashed_password", nil)
+		repository.On("CreateAccount", anyContext, mock.AnythingOfType("*domain.Account")).Return(&domain.Account{ID: 1, Email: "test@example.com"}, nil)
+		service.On("GenerateEmailVerificationToken", anyContext, mock.AnythingOfType("*domain.Account")).Return("verification_token", nil)
+		service.On("SendVerificationEmail", anyContext, "test@example.com", "verification_token").Return(nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityRegister).Return(nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/register", handler.RegisterAccount)
+
+		reqBody := account.RegisterAccountRequest{Email: "test@example.com", Password: "password"}
+		w := httpHelper.MakeRequest("POST", "/account/register", reqBody, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response account.RegisterAccountResponse
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.True(t, response.EmailVerificationRequired)
+		assert.Empty(t, response.Token)
+		assert.Empty(t, response.RefreshToken)
+	})
+
+	t.Run("should refuse to log in an unverified account", func(t *testing.T) {
+		logger := logrus.New()
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		repository.On("CountLoginFailuresByIP", anyContext, mock.Anything, mock.AnythingOfType("time.Time")).Return(int64(0), nil)
+		repository.On("GetAccountByEmail", anyContext, "test@example.com").Return(&domain.Account{ID: 1, Email: "test@example.com", Password: "hashed_password"}, nil)
+		service.On("ComparePassword", anyContext, "password", "hashed_password").Return(true, nil)
+
+		handler := account.NewAccountHandler(logger, service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.SetupHandler("POST", "/account/login", handler.LoginAccount)
+
+		reqBody := account.LoginAccountRequest{Email: "test@example.com", Password: "password"}
+		w := httpHelper.MakeRequest("POST", "/account/login", reqBody, nil)
+
+		var response map[string]string
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, "email not verified", response["error"])
+	})
+}
+
+func TestRequireVerifiedEmail(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	authenticate := func(accountID uint) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set(utils.AccountIdContextKey, accountID)
+		}
+	}
+
+	ok := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "ok"})
+	}
+
+	t.Run("should let every account through without a policy", func(t *testing.T) {
+		repository := domain.NewMockAccountRepository(t)
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.GET("/protected", authenticate(1), account.RequireVerifiedEmail(repository), ok)
+
+		w := httpHelper.MakeRequest("GET", "/protected", nil, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should refuse unverified accounts when routes are protected", func(t *testing.T) {
+		viper.Set("EMAIL_VERIFICATION_POLICY", account.EmailVerificationPolicyRoutes)
+		defer viper.Reset()
+
+		repository := domain.NewMockAccountRepository(t)
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(&domain.Account{ID: 1}, nil)
+		repository.On("GetAccountByID", anyContext, uint(2)).Return(&domain.Account{ID: 2, EmailVerified: true}, nil)
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.GET("/unverified", authenticate(1), account.RequireVerifiedEmail(repository), ok)
+		httpHelper.router.GET("/verified", authenticate(2), account.RequireVerifiedEmail(repository), ok)
+
+		w := httpHelper.MakeRequest("GET", "/unverified", nil, nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = httpHelper.MakeRequest("GET", "/verified", nil, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
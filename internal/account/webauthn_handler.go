@@ -0,0 +1,567 @@
+package account
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"go_starter_api/pkg/webauthn"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// time the user has to answer the browser prompt, the options carry the same timeout
+const webauthnChallengeTTL = 5 * time.Minute
+
+// WebAuthnHandler registers passkeys and logs in with them, either as the second factor of
+// another login or without a password, the logins themselves go through the AccountHandler
+type WebAuthnHandler struct {
+	logger *logrus.Logger
+	tracer trace.Tracer
+
+	accountHandler *AccountHandler
+	relyingParty   *webauthn.RelyingParty
+}
+
+func NewWebAuthnHandler(
+	logger *logrus.Logger,
+	accountHandler *AccountHandler,
+	relyingParty *webauthn.RelyingParty,
+) *WebAuthnHandler {
+	tracer := otel.Tracer("webauthnHandler")
+	return &WebAuthnHandler{
+		logger:         logger,
+		tracer:         tracer,
+		accountHandler: accountHandler,
+		relyingParty:   relyingParty,
+	}
+}
+
+// WebAuthnCreationResponse is given to navigator.credentials.create
+type WebAuthnCreationResponse struct {
+	PublicKey *webauthn.CreationOptions `json:"publicKey"`
+}
+
+// WebAuthnRequestResponse is given to navigator.credentials.get
+type WebAuthnRequestResponse struct {
+	PublicKey *webauthn.RequestOptions `json:"publicKey"`
+}
+
+type FinishWebAuthnRegistrationRequest struct {
+	Name       string                        `json:"name"`
+	Credential webauthn.RegistrationResponse `json:"credential"`
+}
+
+type BeginWebAuthnLoginRequest struct {
+	// MFAToken of a login asking for a second factor, without it any passkey logs in
+	MFAToken string `json:"mfa_token"`
+}
+
+type FinishWebAuthnLoginRequest struct {
+	Credential webauthn.AssertionResponse `json:"credential"`
+	DeviceName string                     `json:"device_name"`
+}
+
+type WebAuthnCredentialResponse struct {
+	ID             uint       `json:"id"`
+	Name           string     `json:"name"`
+	BackupEligible bool       `json:"backup_eligible"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
+}
+
+type ListWebAuthnCredentialsResponse struct {
+	Credentials []WebAuthnCredentialResponse `json:"credentials"`
+}
+
+func toWebAuthnCredentialResponse(credential *domain.WebAuthnCredential) WebAuthnCredentialResponse {
+	return WebAuthnCredentialResponse{
+		ID:             credential.ID,
+		Name:           credential.Name,
+		BackupEligible: credential.BackupEligible,
+		CreatedAt:      credential.CreatedAt,
+		LastUsedAt:     credential.LastUsedAt,
+	}
+}
+
+// webauthnUserHandle is the user id stored with the passkeys of an account, the account id
+// is not personal information
+func webauthnUserHandle(accountID uint) []byte {
+	return []byte(strconv.FormatUint(uint64(accountID), 10))
+}
+
+// @Summary		Begin Passkey Registration
+// @Description	Start creating a passkey for the authenticated user, the options are given to navigator.credentials.create
+// @Tags			account
+// @Produce		json
+// @Success		200	{object}	WebAuthnCreationResponse
+// @Failure		403	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/account/webauthn/register/begin [post]
+func (h *WebAuthnHandler) BeginRegistration(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "BeginWebAuthnRegistration")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	accountRepository := h.accountHandler.accountRepository
+
+	acc, err := accountRepository.GetAccountByID(ctx, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to get account by id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	registered, err := h.credentials(ctx, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to list passkeys: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	challenge, err := h.createChallenge(ctx, domain.WebAuthnPurposeRegistration, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to create webauthn challenge: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	user := webauthn.User{ID: webauthnUserHandle(acc.ID), Name: acc.Email, DisplayName: acc.Email}
+
+	c.JSON(http.StatusOK, WebAuthnCreationResponse{
+		PublicKey: h.relyingParty.BeginRegistration(challenge, user, registered, webauthn.UserVerificationPreferred),
+	})
+}
+
+// @Summary		Finish Passkey Registration
+// @Description	Register the passkey created by navigator.credentials.create, the credential is its json serialization
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			credential	body		FinishWebAuthnRegistrationRequest	true	"Credential"
+// @Success		200			{object}	WebAuthnCredentialResponse
+// @Failure		400			{object}	map[string]string
+// @Failure		403			{object}	map[string]string
+// @Failure		409			{object}	map[string]string
+// @Failure		500			{object}	map[string]string
+// @Router			/api/v1/account/webauthn/register/finish [post]
+func (h *WebAuthnHandler) FinishRegistration(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "FinishWebAuthnRegistration")
+	defer span.End()
+
+	var req FinishWebAuthnRegistrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	challenge, err := h.consumeChallenge(ctx, domain.WebAuthnPurposeRegistration, req.Credential.Challenge)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidWebAuthnChallenge) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired challenge"})
+			return
+		}
+		h.logger.WithField("userId", accountID).Errorf("failed to consume webauthn challenge: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	if challenge.AccountID != accountID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired challenge"})
+		return
+	}
+
+	credential, err := h.relyingParty.FinishRegistration(challenge.challenge, req.Credential, false)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Warnf("invalid passkey registration: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid passkey"})
+		return
+	}
+
+	accountRepository := h.accountHandler.accountRepository
+	credentialID := base64.RawURLEncoding.EncodeToString(credential.ID)
+
+	_, err = accountRepository.GetWebAuthnCredential(ctx, credentialID)
+	if err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "passkey already registered"})
+		return
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		h.logger.WithField("userId", accountID).Errorf("failed to get passkey: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = deviceLabel(c.Request.UserAgent())
+	}
+
+	record, err := accountRepository.CreateWebAuthnCredential(ctx, &domain.WebAuthnCredential{
+		AccountID:      accountID,
+		Name:           name,
+		CredentialID:   credentialID,
+		PublicKey:      credential.PublicKey,
+		SignCount:      credential.SignCount,
+		AAGUID:         hex.EncodeToString(credential.AAGUID),
+		Transports:     credential.Transports,
+		BackupEligible: credential.BackupEligible,
+	})
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to create passkey: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	err = accountRepository.LogAccountActivity(ctx, accountID, domain.ActivityPasskeyRegistered)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to log activity: %v", err)
+	}
+
+	c.JSON(http.StatusOK, toWebAuthnCredentialResponse(record))
+}
+
+// @Summary		List Passkeys
+// @Description	List the passkeys of the authenticated user
+// @Tags			account
+// @Produce		json
+// @Success		200	{object}	ListWebAuthnCredentialsResponse
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/account/webauthn/credentials [get]
+func (h *WebAuthnHandler) ListCredentials(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "ListWebAuthnCredentials")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	credentials, err := h.accountHandler.accountRepository.ListWebAuthnCredentials(ctx, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to list passkeys: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	response := ListWebAuthnCredentialsResponse{Credentials: []WebAuthnCredentialResponse{}}
+	for i := range credentials {
+		response.Credentials = append(response.Credentials, toWebAuthnCredentialResponse(&credentials[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary		Delete Passkey
+// @Description	Delete a passkey of the authenticated user, logins no longer ask for a second factor once the last one is deleted unless mfa is enabled
+// @Tags			account
+// @Produce		json
+// @Param			id	path		int	true	"Passkey ID"
+// @Success		200	{object}	map[string]string
+// @Failure		400	{object}	map[string]string
+// @Failure		404	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/account/webauthn/credentials/{id} [delete]
+func (h *WebAuthnHandler) DeleteCredential(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "DeleteWebAuthnCredential")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	credentialID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid passkey id"})
+		return
+	}
+
+	accountRepository := h.accountHandler.accountRepository
+
+	err = accountRepository.DeleteWebAuthnCredential(ctx, accountID, uint(credentialID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "passkey not found"})
+			return
+		}
+		h.logger.WithField("userId", accountID).Errorf("failed to delete passkey: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	err = accountRepository.LogAccountActivity(ctx, accountID, domain.ActivityPasskeyDeleted)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to log activity: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "passkey deleted"})
+}
+
+// @Summary		Begin Passkey Login
+// @Description	Start a login with a passkey, the options are given to navigator.credentials.get.
+// @Description	With the mfa token of a login asking for a second factor only the passkeys of that account are allowed, without it the user picks any passkey and logs in without a password.
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			login	body		BeginWebAuthnLoginRequest	false	"MFA token"
+// @Success		200		{object}	WebAuthnRequestResponse
+// @Failure		400		{object}	map[string]string
+// @Failure		401		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/webauthn/login/begin [post]
+func (h *WebAuthnHandler) BeginLogin(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "BeginWebAuthnLogin")
+	defer span.End()
+
+	var req BeginWebAuthnLoginRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var accountID uint
+	var allow []webauthn.Credential
+	userVerification := webauthn.UserVerificationRequired
+
+	if req.MFAToken != "" {
+		claims, err := h.accountHandler.validateMFAToken(ctx, req.MFAToken)
+		if err != nil {
+			if errors.Is(err, errInvalidMFAToken) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid mfa token"})
+				return
+			}
+			h.logger.Errorf("failed to check mfa token revocation: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		accountID = claims.AccountID
+
+		allow, err = h.credentials(ctx, accountID)
+		if err != nil {
+			h.logger.WithField("userId", accountID).Errorf("failed to list passkeys: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if len(allow) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "no passkey registered"})
+			return
+		}
+
+		// the password was the first factor, the passkey only has to be present
+		userVerification = webauthn.UserVerificationPreferred
+	}
+
+	challenge, err := h.createChallenge(ctx, domain.WebAuthnPurposeLogin, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to create webauthn challenge: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, WebAuthnRequestResponse{
+		PublicKey: h.relyingParty.BeginLogin(challenge, allow, userVerification),
+	})
+}
+
+// @Summary		Finish Passkey Login
+// @Description	Complete a passkey login with the assertion returned by navigator.credentials.get, the credential is its json serialization.
+// @Description	A passwordless login requires the authenticator to verify the user and asks for no other factor.
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			login	body		FinishWebAuthnLoginRequest	true	"Assertion"
+// @Success		200		{object}	LoginAccountResponse
+// @Failure		400		{object}	map[string]string
+// @Failure		401		{object}	map[string]string
+// @Failure		403		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/webauthn/login/finish [post]
+func (h *WebAuthnHandler) FinishLogin(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "FinishWebAuthnLogin")
+	defer span.End()
+
+	var req FinishWebAuthnLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	challenge, err := h.consumeChallenge(ctx, domain.WebAuthnPurposeLogin, req.Credential.Challenge)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidWebAuthnChallenge) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired challenge"})
+			return
+		}
+		h.logger.Errorf("failed to consume webauthn challenge: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	rawID, err := req.Credential.CredentialID()
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid passkey"})
+		return
+	}
+
+	accountRepository := h.accountHandler.accountRepository
+
+	record, err := accountRepository.GetWebAuthnCredential(ctx, base64.RawURLEncoding.EncodeToString(rawID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid passkey"})
+			return
+		}
+		h.logger.Errorf("failed to get passkey: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// the second factor must be a passkey of the account that passed the first one
+	secondFactor := challenge.AccountID != 0
+	if secondFactor && record.AccountID != challenge.AccountID {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid passkey"})
+		return
+	}
+
+	handle, err := req.Credential.UserHandle()
+	if err != nil || (handle != nil && !bytes.Equal(handle, webauthnUserHandle(record.AccountID))) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid passkey"})
+		return
+	}
+
+	assertion, err := h.relyingParty.FinishLogin(challenge.challenge, webauthn.Credential{
+		ID:        rawID,
+		PublicKey: record.PublicKey,
+		SignCount: record.SignCount,
+	}, req.Credential, !secondFactor)
+	if err == nil {
+		err = accountRepository.RecordWebAuthnCredentialUse(ctx, record.ID, assertion.SignCount, time.Now())
+	}
+	if err != nil {
+		if errors.Is(err, webauthn.ErrSignCountNotIncreased) || errors.Is(err, domain.ErrWebAuthnSignCount) {
+			h.logger.WithField("userId", record.AccountID).WithField("passkeyId", record.ID).
+				Warnf("passkey sign count did not increase, the authenticator may be cloned")
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid passkey"})
+			return
+		}
+		h.logger.WithField("userId", record.AccountID).Warnf("invalid passkey assertion: %v", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid passkey"})
+		return
+	}
+
+	acc, err := accountRepository.GetAccountByID(ctx, record.AccountID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid passkey"})
+			return
+		}
+		h.logger.WithField("userId", record.AccountID).Errorf("failed to get account by id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// lockouts throttle password guessing, a passkey cannot be guessed and is not refused
+	if loginRefused(c, acc) {
+		return
+	}
+
+	if secondFactor {
+		h.accountHandler.completeLogin(ctx, c, acc, req.DeviceName, domain.ActivityLogin)
+		return
+	}
+	h.accountHandler.completeLogin(ctx, c, acc, req.DeviceName, domain.ActivityPasskeyLogin)
+}
+
+// credentials returns the passkeys of the account as known to the relying party
+func (h *WebAuthnHandler) credentials(ctx context.Context, accountID uint) ([]webauthn.Credential, error) {
+	records, err := h.accountHandler.accountRepository.ListWebAuthnCredentials(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := make([]webauthn.Credential, 0, len(records))
+	for _, record := range records {
+		id, err := base64.RawURLEncoding.DecodeString(record.CredentialID)
+		if err != nil {
+			return nil, err
+		}
+		credentials = append(credentials, webauthn.Credential{ID: id, Transports: record.Transports})
+	}
+	return credentials, nil
+}
+
+// createChallenge stores the hash of a new challenge and returns the challenge
+func (h *WebAuthnHandler) createChallenge(ctx context.Context, purpose string, accountID uint) (string, error) {
+	challenge, err := webauthn.GenerateChallenge()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = h.accountHandler.accountRepository.CreateWebAuthnChallenge(ctx, &domain.WebAuthnChallenge{
+		ChallengeHash: h.accountHandler.accountService.HashToken(ctx, challenge),
+		Purpose:       purpose,
+		AccountID:     accountID,
+		ExpiresAt:     time.Now().Add(webauthnChallengeTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+	return challenge, nil
+}
+
+// consumedChallenge is a used challenge together with its plain value
+type consumedChallenge struct {
+	*domain.WebAuthnChallenge
+	challenge string
+}
+
+// consumeChallenge uses up the challenge answered by a response, the challenge is read from
+// the client data and only verified against it by the relying party
+func (h *WebAuthnHandler) consumeChallenge(ctx context.Context, purpose string, responseChallenge func() (string, error)) (*consumedChallenge, error) {
+	challenge, err := responseChallenge()
+	if err != nil || challenge == "" {
+		return nil, domain.ErrInvalidWebAuthnChallenge
+	}
+
+	record, err := h.accountHandler.accountRepository.ConsumeWebAuthnChallenge(ctx, purpose, h.accountHandler.accountService.HashToken(ctx, challenge))
+	if err != nil {
+		return nil, err
+	}
+	return &consumedChallenge{WebAuthnChallenge: record, challenge: challenge}, nil
+}
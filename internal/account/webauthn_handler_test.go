@@ -0,0 +1,327 @@
+package account_test
+
+import (
+	"context"
+	"go_starter_api/internal/account"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"go_starter_api/pkg/webauthn"
+	"go_starter_api/pkg/webauthn/webauthntest"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm"
+)
+
+func TestWebAuthnHandler(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	const origin = "http://localhost:3000"
+
+	authenticate := func(c *gin.Context) {
+		c.Set(utils.AccountIdContextKey, uint(1))
+		c.Set(utils.AuthClaimsContextKey, &domain.AuthClaims{AccountID: 1, SessionID: 5})
+	}
+
+	// setup returns a handler whose repository keeps the challenges and passkeys like the database would
+	setup := func(t *testing.T) (*domain.MockAccountService, *domain.MockAccountRepository, *HTTPTestHelper, map[string]*domain.WebAuthnCredential) {
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		challenges := map[string]*domain.WebAuthnChallenge{}
+		credentials := map[string]*domain.WebAuthnCredential{}
+
+		service.On("HashToken", anyContext, mock.AnythingOfType("string")).Return(func(_ context.Context, token string) string {
+			return "hash:" + token
+		}).Maybe()
+		repository.On("CreateWebAuthnChallenge", anyContext, mock.AnythingOfType("*domain.WebAuthnChallenge")).Return(func(_ context.Context, challenge *domain.WebAuthnChallenge) *domain.WebAuthnChallenge {
+			challenges[challenge.ChallengeHash] = challenge
+			return challenge
+		}, nil).Maybe()
+		repository.On("ConsumeWebAuthnChallenge", anyContext, mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(func(_ context.Context, purpose string, hash string) (*domain.WebAuthnChallenge, error) {
+			challenge, ok := challenges[hash]
+			if !ok || challenge.Purpose != purpose {
+				return nil, domain.ErrInvalidWebAuthnChallenge
+			}
+			delete(challenges, hash)
+			return challenge, nil
+		}).Maybe()
+		repository.On("CreateWebAuthnCredential", anyContext, mock.AnythingOfType("*domain.WebAuthnCredential")).Return(func(_ context.Context, credential *domain.WebAuthnCredential) *domain.WebAuthnCredential {
+			credential.ID = uint(len(credentials) + 1)
+			credentials[credential.CredentialID] = credential
+			return credential
+		}, nil).Maybe()
+		repository.On("GetWebAuthnCredential", anyContext, mock.AnythingOfType("string")).Return(func(_ context.Context, id string) (*domain.WebAuthnCredential, error) {
+			credential, ok := credentials[id]
+			if !ok {
+				return nil, gorm.ErrRecordNotFound
+			}
+			return credential, nil
+		}).Maybe()
+		repository.On("ListWebAuthnCredentials", anyContext, mock.AnythingOfType("uint")).Return(func(_ context.Context, accountID uint) []domain.WebAuthnCredential {
+			var result []domain.WebAuthnCredential
+			for _, credential := range credentials {
+				if credential.AccountID == accountID {
+					result = append(result, *credential)
+				}
+			}
+			return result
+		}, nil).Maybe()
+		repository.On("RecordWebAuthnCredentialUse", anyContext, mock.AnythingOfType("uint"), mock.AnythingOfType("uint32"), mock.AnythingOfType("time.Time")).Return(func(_ context.Context, id uint, signCount uint32, usedAt time.Time) error {
+			for _, credential := range credentials {
+				if credential.ID == id {
+					credential.SignCount = signCount
+					credential.LastUsedAt = &usedAt
+				}
+			}
+			return nil
+		}).Maybe()
+
+		relyingParty := webauthn.NewRelyingParty(webauthn.Config{RPID: "localhost", RPName: "go_starter_api", Origins: []string{origin}})
+		accountHandler := account.NewAccountHandler(logrus.New(), service, repository, account.NewInMemoryTokenRevocationRepository())
+		handler := account.NewWebAuthnHandler(logrus.New(), accountHandler, relyingParty)
+
+		httpHelper := NewHTTPTestHelper()
+		httpHelper.router.POST("/account/login", accountHandler.LoginAccount)
+		httpHelper.router.POST("/account/webauthn/login/begin", handler.BeginLogin)
+		httpHelper.router.POST("/account/webauthn/login/finish", handler.FinishLogin)
+		httpHelper.router.POST("/account/sign-in/report", accountHandler.ReportSignIn)
+		httpHelper.router.POST("/account/webauthn/register/begin", authenticate, handler.BeginRegistration)
+		httpHelper.router.POST("/account/webauthn/register/finish", authenticate, handler.FinishRegistration)
+		httpHelper.router.GET("/account/webauthn/credentials", authenticate, handler.ListCredentials)
+		httpHelper.router.DELETE("/account/webauthn/credentials/:id", authenticate, handler.DeleteCredential)
+
+		return service, repository, httpHelper, credentials
+	}
+
+	// register creates a passkey of account 1 on the authenticator
+	register := func(t *testing.T, repository *domain.MockAccountRepository, httpHelper *HTTPTestHelper, authenticator *webauthntest.Authenticator) {
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(&domain.Account{ID: 1, Email: "user@example.com"}, nil).Once()
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityPasskeyRegistered).Return(nil).Once()
+
+		w := httpHelper.MakeRequest("POST", "/account/webauthn/register/begin", nil, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var options account.WebAuthnCreationResponse
+		httpHelper.AssertJSONResponse(t, w, &options)
+		assert.Equal(t, "localhost", options.PublicKey.RP.ID)
+		assert.Equal(t, "user@example.com", options.PublicKey.User.Name)
+
+		w = httpHelper.MakeRequest("POST", "/account/webauthn/register/finish", account.FinishWebAuthnRegistrationRequest{
+			Name:       "Laptop",
+			Credential: authenticator.Register(t, options.PublicKey),
+		}, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	// login runs a passkey login, with the mfa token of a password login when it is set
+	login := func(t *testing.T, httpHelper *HTTPTestHelper, authenticator *webauthntest.Authenticator, mfaToken string) int {
+		w := httpHelper.MakeRequest("POST", "/account/webauthn/login/begin", account.BeginWebAuthnLoginRequest{MFAToken: mfaToken}, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var options account.WebAuthnRequestResponse
+		httpHelper.AssertJSONResponse(t, w, &options)
+
+		w = httpHelper.MakeRequest("POST", "/account/webauthn/login/finish", account.FinishWebAuthnLoginRequest{
+			Credential: authenticator.Login(t, options.PublicKey),
+		}, nil)
+		return w.Code
+	}
+
+	// expectLogin mocks the tokens issued to the account
+	expectLogin := func(service *domain.MockAccountService, repository *domain.MockAccountRepository, acc *domain.Account, activity string) {
+		service.On("GenerateRefreshToken", anyContext, acc, "").Return("refresh_token", &domain.RefreshToken{AccountID: acc.ID}, nil)
+		repository.On("IsNewDevice", anyContext, acc.ID, mock.Anything, mock.Anything).Return(false, nil)
+		repository.On("CreateSession", anyContext, mock.AnythingOfType("*domain.Session")).Return(&domain.Session{ID: 5, AccountID: acc.ID}, nil)
+		service.On("GenerateAuthToken", anyContext, acc, uint(5), uint(0)).Return("auth_token", nil)
+		repository.On("CreateRefreshToken", anyContext, mock.AnythingOfType("*domain.RefreshToken")).Return(&domain.RefreshToken{ID: 1}, nil)
+		repository.On("LogAccountActivity", anyContext, acc.ID, activity).Return(nil)
+	}
+
+	t.Run("should register a passkey and log in without a password", func(t *testing.T) {
+		service, repository, httpHelper, credentials := setup(t)
+		authenticator := webauthntest.NewAuthenticator(origin)
+
+		register(t, repository, httpHelper, authenticator)
+		require.Len(t, credentials, 1)
+		for _, credential := range credentials {
+			assert.Equal(t, uint(1), credential.AccountID)
+			assert.Equal(t, "Laptop", credential.Name)
+			assert.True(t, credential.BackupEligible)
+		}
+
+		w := httpHelper.MakeRequest("GET", "/account/webauthn/credentials", nil, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var list account.ListWebAuthnCredentialsResponse
+		httpHelper.AssertJSONResponse(t, w, &list)
+		require.Len(t, list.Credentials, 1)
+		assert.Equal(t, "Laptop", list.Credentials[0].Name)
+		assert.NotContains(t, w.Body.String(), "public_key")
+
+		acc := &domain.Account{ID: 1, Email: "user@example.com", WebAuthnEnabled: true}
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		expectLogin(service, repository, acc, domain.ActivityPasskeyLogin)
+
+		assert.Equal(t, http.StatusOK, login(t, httpHelper, authenticator, ""))
+		for _, credential := range credentials {
+			assert.Equal(t, uint32(1), credential.SignCount)
+			assert.NotNil(t, credential.LastUsedAt)
+		}
+	})
+
+	t.Run("should ask a password login for the passkey as second factor", func(t *testing.T) {
+		service, repository, httpHelper, _ := setup(t)
+		authenticator := webauthntest.NewAuthenticator(origin)
+		register(t, repository, httpHelper, authenticator)
+
+		acc := &domain.Account{ID: 1, Email: "user@example.com", Password: "hash", WebAuthnEnabled: true}
+		repository.On("CountLoginFailuresByIP", anyContext, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(int64(0), nil)
+		repository.On("GetAccountByEmail", anyContext, "user@example.com").Return(acc, nil)
+		service.On("ComparePassword", anyContext, "password", "hash").Return(true, nil)
+		service.On("GenerateMFAToken", anyContext, acc).Return("mfa_token", nil)
+
+		w := httpHelper.MakeRequest("POST", "/account/login", account.LoginAccountRequest{Email: "user@example.com", Password: "password"}, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response account.LoginAccountResponse
+		httpHelper.AssertJSONResponse(t, w, &response)
+		assert.True(t, response.MFARequired)
+		assert.Equal(t, []string{account.MFAMethodWebAuthn}, response.MFAMethods)
+		assert.Empty(t, response.Token)
+
+		service.On("ValidateMFAToken", anyContext, "mfa_token").Return(mfaTokenClaims(1), nil)
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		expectLogin(service, repository, acc, domain.ActivityLogin)
+
+		// the password already passed, an authenticator without user verification is enough
+		authenticator.SkipUserVerification = true
+		assert.Equal(t, http.StatusOK, login(t, httpHelper, authenticator, "mfa_token"))
+	})
+
+	t.Run("should refuse the passkey of another account as second factor", func(t *testing.T) {
+		service, repository, httpHelper, credentials := setup(t)
+		authenticator := webauthntest.NewAuthenticator(origin)
+		register(t, repository, httpHelper, authenticator)
+		for _, credential := range credentials {
+			credential.AccountID = 2
+		}
+		credentials["other"] = &domain.WebAuthnCredential{ID: 9, AccountID: 1, CredentialID: "b3RoZXI"}
+
+		service.On("ValidateMFAToken", anyContext, "mfa_token").Return(mfaTokenClaims(1), nil)
+
+		w := httpHelper.MakeRequest("POST", "/account/webauthn/login/begin", account.BeginWebAuthnLoginRequest{MFAToken: "mfa_token"}, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		var options account.WebAuthnRequestResponse
+		httpHelper.AssertJSONResponse(t, w, &options)
+
+		// the authenticator answers with its own passkey as a browser ignoring the allowed list would
+		options.PublicKey.AllowCredentials = nil
+		w = httpHelper.MakeRequest("POST", "/account/webauthn/login/finish", account.FinishWebAuthnLoginRequest{
+			Credential: authenticator.Login(t, options.PublicKey),
+		}, nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("should require user verification without a password", func(t *testing.T) {
+		_, repository, httpHelper, _ := setup(t)
+		authenticator := webauthntest.NewAuthenticator(origin)
+		register(t, repository, httpHelper, authenticator)
+
+		authenticator.SkipUserVerification = true
+		assert.Equal(t, http.StatusUnauthorized, login(t, httpHelper, authenticator, ""))
+	})
+
+	t.Run("should refuse a replayed assertion", func(t *testing.T) {
+		service, repository, httpHelper, _ := setup(t)
+		authenticator := webauthntest.NewAuthenticator(origin)
+		register(t, repository, httpHelper, authenticator)
+
+		acc := &domain.Account{ID: 1, Email: "user@example.com", WebAuthnEnabled: true}
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		expectLogin(service, repository, acc, domain.ActivityPasskeyLogin)
+
+		w := httpHelper.MakeRequest("POST", "/account/webauthn/login/begin", nil, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		var options account.WebAuthnRequestResponse
+		httpHelper.AssertJSONResponse(t, w, &options)
+
+		req := account.FinishWebAuthnLoginRequest{Credential: authenticator.Login(t, options.PublicKey)}
+		w = httpHelper.MakeRequest("POST", "/account/webauthn/login/finish", req, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = httpHelper.MakeRequest("POST", "/account/webauthn/login/finish", req, nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("should refuse a cloned authenticator", func(t *testing.T) {
+		service, repository, httpHelper, _ := setup(t)
+		authenticator := webauthntest.NewAuthenticator(origin)
+		register(t, repository, httpHelper, authenticator)
+		clone := authenticator.Clone()
+
+		acc := &domain.Account{ID: 1, Email: "user@example.com", WebAuthnEnabled: true}
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		expectLogin(service, repository, acc, domain.ActivityPasskeyLogin)
+
+		assert.Equal(t, http.StatusOK, login(t, httpHelper, authenticator, ""))
+		assert.Equal(t, http.StatusUnauthorized, login(t, httpHelper, clone, ""))
+	})
+
+	t.Run("should refuse disabled accounts", func(t *testing.T) {
+		_, repository, httpHelper, _ := setup(t)
+		authenticator := webauthntest.NewAuthenticator(origin)
+		register(t, repository, httpHelper, authenticator)
+
+		now := time.Now()
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(&domain.Account{ID: 1, DisabledAt: &now}, nil)
+
+		assert.Equal(t, http.StatusForbidden, login(t, httpHelper, authenticator, ""))
+	})
+
+	t.Run("should refuse accounts that have to reset their password", func(t *testing.T) {
+		_, repository, httpHelper, _ := setup(t)
+		authenticator := webauthntest.NewAuthenticator(origin)
+		register(t, repository, httpHelper, authenticator)
+
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(&domain.Account{ID: 1, WebAuthnEnabled: true, PasswordResetRequired: true}, nil)
+
+		assert.Equal(t, http.StatusForbidden, login(t, httpHelper, authenticator, ""))
+	})
+
+	t.Run("should refuse passkey logins after a reported sign-in", func(t *testing.T) {
+		service, repository, httpHelper, _ := setup(t)
+		authenticator := webauthntest.NewAuthenticator(origin)
+		register(t, repository, httpHelper, authenticator)
+
+		acc := &domain.Account{ID: 1, Email: "user@example.com", EmailVerified: true, WebAuthnEnabled: true}
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		expectSignInReport(service, repository, acc)
+
+		reportSignIn(t, httpHelper)
+
+		assert.Equal(t, http.StatusForbidden, login(t, httpHelper, authenticator, ""))
+	})
+
+	t.Run("should delete a passkey", func(t *testing.T) {
+		_, repository, httpHelper, _ := setup(t)
+		repository.On("DeleteWebAuthnCredential", anyContext, uint(1), uint(2)).Return(nil)
+		repository.On("DeleteWebAuthnCredential", anyContext, uint(1), uint(3)).Return(gorm.ErrRecordNotFound)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityPasskeyDeleted).Return(nil)
+
+		w := httpHelper.MakeRequest("DELETE", "/account/webauthn/credentials/2", nil, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = httpHelper.MakeRequest("DELETE", "/account/webauthn/credentials/3", nil, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,649 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	name = "adminHandler"
+
+	defaultPageSize = 20
+	maxPageSize     = 100
+	// number of activities returned with an account
+	recentActivityLimit = 50
+)
+
+type AdminHandler struct {
+	logger *logrus.Logger
+	tracer trace.Tracer
+
+	accountService            domain.AccountService
+	accountRepository         domain.AccountRepository
+	tokenRevocationRepository domain.TokenRevocationRepository
+}
+
+func NewAdminHandler(
+	logger *logrus.Logger,
+	accountService domain.AccountService,
+	accountRepository domain.AccountRepository,
+	tokenRevocationRepository domain.TokenRevocationRepository,
+) *AdminHandler {
+	tracer := otel.Tracer(name)
+	return &AdminHandler{
+		logger:                    logger,
+		tracer:                    tracer,
+		accountService:            accountService,
+		accountRepository:         accountRepository,
+		tokenRevocationRepository: tokenRevocationRepository,
+	}
+}
+
+type AccountResponse struct {
+	ID                    uint       `json:"id"`
+	Email                 string     `json:"email"`
+	Status                string     `json:"status"`
+	EmailVerified         bool       `json:"email_verified"`
+	MFAEnabled            bool       `json:"mfa_enabled"`
+	WebAuthnEnabled       bool       `json:"webauthn_enabled"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
+	CreatedAt             time.Time  `json:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at"`
+	LockedUntil           *time.Time `json:"locked_until,omitempty"`
+	DisabledAt            *time.Time `json:"disabled_at,omitempty"`
+	DeletedAt             *time.Time `json:"deleted_at,omitempty"`
+	PurgeAt               *time.Time `json:"purge_at,omitempty"`
+}
+
+func toAccountResponse(acc *domain.Account, now time.Time) AccountResponse {
+	response := AccountResponse{
+		ID:                    acc.ID,
+		Email:                 acc.Email,
+		Status:                acc.Status(now),
+		EmailVerified:         acc.EmailVerified,
+		MFAEnabled:            acc.MFAEnabled,
+		WebAuthnEnabled:       acc.WebAuthnEnabled,
+		PasswordResetRequired: acc.PasswordResetRequired,
+		CreatedAt:             acc.CreatedAt,
+		UpdatedAt:             acc.UpdatedAt,
+		LockedUntil:           acc.LockedUntil,
+		DisabledAt:            acc.DisabledAt,
+		PurgeAt:               acc.PurgeAt,
+	}
+	if acc.DeletedAt.Valid {
+		response.DeletedAt = &acc.DeletedAt.Time
+	}
+	return response
+}
+
+type ListAccountsResponse struct {
+	Accounts []AccountResponse `json:"accounts"`
+	Total    int64             `json:"total"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+}
+
+// @Summary		List Accounts
+// @Description	List accounts page by page, requires accounts:read. Deleted accounts are only listed with status=deleted.
+// @Tags			admin
+// @Produce		json
+// @Param			page			query		int		false	"Page, starting at 1"
+// @Param			page_size		query		int		false	"Accounts per page, at most 100"
+// @Param			email			query		string	false	"Part of the email"
+// @Param			created_after	query		string	false	"RFC 3339 time or date"
+// @Param			created_before	query		string	false	"RFC 3339 time or date"
+// @Param			status			query		string	false	"active, locked, disabled or deleted"
+// @Success		200				{object}	ListAccountsResponse
+// @Failure		400				{object}	map[string]string
+// @Failure		403				{object}	map[string]string
+// @Failure		500				{object}	map[string]string
+// @Router			/api/v1/admin/accounts [get]
+func (h *AdminHandler) ListAccounts(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "ListAccounts")
+	defer span.End()
+
+	page, err := utils.QueryInt(c, "page", 1)
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page"})
+		return
+	}
+	pageSize, err := utils.QueryInt(c, "page_size", defaultPageSize)
+	if err != nil || pageSize < 1 || pageSize > maxPageSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page size"})
+		return
+	}
+
+	filter := domain.AccountFilter{
+		Email:  c.Query("email"),
+		Status: c.Query("status"),
+		Offset: (page - 1) * pageSize,
+		Limit:  pageSize,
+	}
+
+	switch filter.Status {
+	case "", domain.AccountStatusActive, domain.AccountStatusLocked, domain.AccountStatusDisabled, domain.AccountStatusDeleted:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+
+	filter.CreatedAfter, err = utils.QueryTime(c, "created_after")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid created_after"})
+		return
+	}
+	filter.CreatedBefore, err = utils.QueryTime(c, "created_before")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid created_before"})
+		return
+	}
+
+	accounts, total, err := h.accountRepository.ListAccounts(ctx, filter)
+	if err != nil {
+		h.logger.Errorf("failed to list accounts: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	now := time.Now()
+	response := ListAccountsResponse{
+		Accounts: []AccountResponse{},
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}
+	for i := range accounts {
+		response.Accounts = append(response.Accounts, toAccountResponse(&accounts[i], now))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type ActivityResponse struct {
+	ID        uint           `json:"id"`
+	AccountID uint           `json:"account_id"`
+	Activity  string         `json:"activity"`
+	Details   map[string]any `json:"details,omitempty"`
+	ActorID   *uint          `json:"actor_id,omitempty"`
+	IPAddress string         `json:"ip_address,omitempty"`
+	UserAgent string         `json:"user_agent,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
+	TraceID   string         `json:"trace_id,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+func toActivityResponse(activity *domain.AccountActivity) ActivityResponse {
+	return ActivityResponse{
+		ID:        activity.ID,
+		AccountID: activity.AccountID,
+		Activity:  activity.Activity,
+		Details:   activity.Details,
+		ActorID:   activity.ActorID,
+		IPAddress: activity.IPAddress,
+		UserAgent: activity.UserAgent,
+		RequestID: activity.RequestID,
+		TraceID:   activity.TraceID,
+		CreatedAt: activity.CreatedAt,
+	}
+}
+
+type AccountDetailsResponse struct {
+	Account    AccountResponse    `json:"account"`
+	Activities []ActivityResponse `json:"activities"`
+}
+
+// @Summary		Get Account
+// @Description	Get an account, deleted or not, with its most recent activity. Requires accounts:read.
+// @Tags			admin
+// @Produce		json
+// @Param			id	path		int	true	"Account ID"
+// @Success		200	{object}	AccountDetailsResponse
+// @Failure		400	{object}	map[string]string
+// @Failure		403	{object}	map[string]string
+// @Failure		404	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/admin/accounts/{id} [get]
+func (h *AdminHandler) GetAccount(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "GetAccount")
+	defer span.End()
+
+	acc, ok := h.accountFromParam(ctx, c, true)
+	if !ok {
+		return
+	}
+
+	activities, err := h.accountRepository.ListAccountActivities(ctx, acc.ID, recentActivityLimit)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to list activities: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	response := AccountDetailsResponse{
+		Account:    toAccountResponse(acc, time.Now()),
+		Activities: []ActivityResponse{},
+	}
+	for i := range activities {
+		response.Activities = append(response.Activities, toActivityResponse(&activities[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type ListActivitiesResponse struct {
+	Activities []ActivityResponse `json:"activities"`
+	Total      int64              `json:"total"`
+	Page       int                `json:"page"`
+	PageSize   int                `json:"page_size"`
+}
+
+// @Summary		List Activities
+// @Description	List the audit log of every account page by page, the most recent first. Requires accounts:read.
+// @Tags			admin
+// @Produce		json
+// @Param			page			query		int		false	"Page, starting at 1"
+// @Param			page_size		query		int		false	"Activities per page, at most 100"
+// @Param			account_id		query		int		false	"Account the activity belongs to"
+// @Param			actor_id		query		int		false	"Administrator who acted on the account"
+// @Param			type			query		string	false	"Activity, such as login"
+// @Param			created_after	query		string	false	"RFC 3339 time or date"
+// @Param			created_before	query		string	false	"RFC 3339 time or date"
+// @Success		200				{object}	ListActivitiesResponse
+// @Failure		400				{object}	map[string]string
+// @Failure		403				{object}	map[string]string
+// @Failure		500				{object}	map[string]string
+// @Router			/api/v1/admin/activity [get]
+func (h *AdminHandler) ListActivities(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "ListActivities")
+	defer span.End()
+
+	page, err := utils.QueryInt(c, "page", 1)
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page"})
+		return
+	}
+	pageSize, err := utils.QueryInt(c, "page_size", defaultPageSize)
+	if err != nil || pageSize < 1 || pageSize > maxPageSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid page size"})
+		return
+	}
+	accountID, err := utils.QueryInt(c, "account_id", 0)
+	if err != nil || accountID < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account_id"})
+		return
+	}
+	actorID, err := utils.QueryInt(c, "actor_id", 0)
+	if err != nil || actorID < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid actor_id"})
+		return
+	}
+
+	filter := domain.ActivityFilter{
+		AccountID: uint(accountID),
+		ActorID:   uint(actorID),
+		Activity:  c.Query("type"),
+		Offset:    (page - 1) * pageSize,
+		Limit:     pageSize,
+	}
+
+	filter.CreatedAfter, err = utils.QueryTime(c, "created_after")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid created_after"})
+		return
+	}
+	filter.CreatedBefore, err = utils.QueryTime(c, "created_before")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid created_before"})
+		return
+	}
+
+	activities, total, err := h.accountRepository.ListActivities(ctx, filter)
+	if err != nil {
+		h.logger.Errorf("failed to list activities: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	response := ListActivitiesResponse{
+		Activities: []ActivityResponse{},
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+	}
+	for i := range activities {
+		response.Activities = append(response.Activities, toActivityResponse(&activities[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary		Disable Account
+// @Description	Disable an account, it is logged out everywhere and cannot log in until enabled. Requires accounts:write.
+// @Tags			admin
+// @Produce		json
+// @Param			id	path		int	true	"Account ID"
+// @Success		200	{object}	map[string]string
+// @Failure		400	{object}	map[string]string
+// @Failure		403	{object}	map[string]string
+// @Failure		404	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/admin/accounts/{id}/disable [post]
+func (h *AdminHandler) DisableAccount(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "DisableAccount")
+	defer span.End()
+
+	acc, ok := h.accountFromParam(ctx, c, false)
+	if !ok {
+		return
+	}
+
+	if acc.ID == c.GetUint(utils.AccountIdContextKey) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot disable your own account"})
+		return
+	}
+
+	if acc.DisabledAt == nil {
+		now := time.Now()
+		err := h.accountRepository.SetAccountDisabled(ctx, acc.ID, &now)
+		if err != nil {
+			h.logger.WithField("userId", acc.ID).Errorf("failed to disable account: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+	}
+
+	if err := h.revokeAccess(ctx, acc.ID); err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to revoke access: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.logActivity(ctx, c, acc.ID, domain.ActivityAccountDisabled)
+
+	c.JSON(http.StatusOK, gin.H{"message": "account disabled"})
+}
+
+// @Summary		Enable Account
+// @Description	Enable a disabled account. Requires accounts:write.
+// @Tags			admin
+// @Produce		json
+// @Param			id	path		int	true	"Account ID"
+// @Success		200	{object}	map[string]string
+// @Failure		400	{object}	map[string]string
+// @Failure		403	{object}	map[string]string
+// @Failure		404	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/admin/accounts/{id}/enable [post]
+func (h *AdminHandler) EnableAccount(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "EnableAccount")
+	defer span.End()
+
+	acc, ok := h.accountFromParam(ctx, c, false)
+	if !ok {
+		return
+	}
+
+	if acc.DisabledAt == nil {
+		c.JSON(http.StatusOK, gin.H{"message": "account already enabled"})
+		return
+	}
+
+	err := h.accountRepository.SetAccountDisabled(ctx, acc.ID, nil)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to enable account: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.logActivity(ctx, c, acc.ID, domain.ActivityAccountEnabled)
+
+	c.JSON(http.StatusOK, gin.H{"message": "account enabled"})
+}
+
+// @Summary		Unlock Account
+// @Description	Lift the lockout of an account after repeated failed logins. Requires accounts:write.
+// @Tags			admin
+// @Produce		json
+// @Param			id	path		int	true	"Account ID"
+// @Success		200	{object}	map[string]string
+// @Failure		400	{object}	map[string]string
+// @Failure		403	{object}	map[string]string
+// @Failure		404	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/admin/accounts/{id}/unlock [post]
+func (h *AdminHandler) UnlockAccount(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "UnlockAccount")
+	defer span.End()
+
+	acc, ok := h.accountFromParam(ctx, c, false)
+	if !ok {
+		return
+	}
+
+	err := h.accountRepository.UnlockAccount(ctx, acc.ID)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to unlock account: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.logActivity(ctx, c, acc.ID, domain.ActivityAccountUnlocked)
+
+	c.JSON(http.StatusOK, gin.H{"message": "account unlocked"})
+}
+
+// @Summary		Force Password Reset
+// @Description	Log an account out everywhere, refuse its password until it is reset and email it a reset link. Requires accounts:write.
+// @Tags			admin
+// @Produce		json
+// @Param			id	path		int	true	"Account ID"
+// @Success		200	{object}	map[string]string
+// @Failure		400	{object}	map[string]string
+// @Failure		403	{object}	map[string]string
+// @Failure		404	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/admin/accounts/{id}/force-password-reset [post]
+func (h *AdminHandler) ForcePasswordReset(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "ForcePasswordReset")
+	defer span.End()
+
+	acc, ok := h.accountFromParam(ctx, c, false)
+	if !ok {
+		return
+	}
+
+	err := h.accountRepository.RequirePasswordReset(ctx, acc.ID)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to require password reset: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if err := h.revokeAccess(ctx, acc.ID); err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to revoke access: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.logActivity(ctx, c, acc.ID, domain.ActivityPasswordResetForced)
+
+	// the reset is required from here on, a failed email can be sent again from forgot password
+	err = h.accountRepository.RevokeOneTimeTokens(ctx, acc.ID, domain.TokenPurposePasswordReset)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to revoke password reset tokens: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send password reset email"})
+		return
+	}
+
+	token, record, err := h.accountService.GenerateOneTimeToken(ctx, acc, domain.TokenPurposePasswordReset)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to generate token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send password reset email"})
+		return
+	}
+
+	_, err = h.accountRepository.CreateOneTimeToken(ctx, record)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to store password reset token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send password reset email"})
+		return
+	}
+
+	err = h.accountService.SendPasswordResetEmail(ctx, acc.Email, token)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to send password reset email: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send password reset email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "password reset required, reset email sent"})
+}
+
+// @Summary		Delete Account
+// @Description	Soft delete an account, it is logged out everywhere and can be restored. Requires accounts:write.
+// @Tags			admin
+// @Produce		json
+// @Param			id	path		int	true	"Account ID"
+// @Success		200	{object}	map[string]string
+// @Failure		400	{object}	map[string]string
+// @Failure		403	{object}	map[string]string
+// @Failure		404	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/admin/accounts/{id} [delete]
+func (h *AdminHandler) DeleteAccount(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "DeleteAccount")
+	defer span.End()
+
+	acc, ok := h.accountFromParam(ctx, c, false)
+	if !ok {
+		return
+	}
+
+	if acc.ID == c.GetUint(utils.AccountIdContextKey) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot delete your own account"})
+		return
+	}
+
+	if err := h.revokeAccess(ctx, acc.ID); err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to revoke access: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	err := h.accountRepository.DeleteAccount(ctx, acc.ID)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to delete account: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.logActivity(ctx, c, acc.ID, domain.ActivityDelete)
+
+	c.JSON(http.StatusOK, gin.H{"message": "account deleted"})
+}
+
+// @Summary		Restore Account
+// @Description	Restore a soft deleted account. Requires accounts:write.
+// @Tags			admin
+// @Produce		json
+// @Param			id	path		int	true	"Account ID"
+// @Success		200	{object}	map[string]string
+// @Failure		400	{object}	map[string]string
+// @Failure		403	{object}	map[string]string
+// @Failure		404	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/admin/accounts/{id}/restore [post]
+func (h *AdminHandler) RestoreAccount(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "RestoreAccount")
+	defer span.End()
+
+	acc, ok := h.accountFromParam(ctx, c, true)
+	if !ok {
+		return
+	}
+
+	if !acc.DeletedAt.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "account is not deleted"})
+		return
+	}
+
+	err := h.accountRepository.RestoreAccount(ctx, acc.ID)
+	if err != nil {
+		h.logger.WithField("userId", acc.ID).Errorf("failed to restore account: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.logActivity(ctx, c, acc.ID, domain.ActivityAccountRestored)
+
+	c.JSON(http.StatusOK, gin.H{"message": "account restored"})
+}
+
+// accountFromParam loads the account of the id path parameter and answers
+// the request itself when it cannot
+func (h *AdminHandler) accountFromParam(ctx context.Context, c *gin.Context, withDeleted bool) (*domain.Account, bool) {
+	accountID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return nil, false
+	}
+
+	var acc *domain.Account
+	if withDeleted {
+		acc, err = h.accountRepository.GetAccountByIDWithDeleted(ctx, uint(accountID))
+	} else {
+		acc, err = h.accountRepository.GetAccountByID(ctx, uint(accountID))
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+			return nil, false
+		}
+		h.logger.WithField("userId", accountID).Errorf("failed to get account by id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return nil, false
+	}
+
+	return acc, true
+}
+
+// revokeAccess logs the account out everywhere, its access and refresh tokens stop working
+func (h *AdminHandler) revokeAccess(ctx context.Context, accountID uint) error {
+	err := h.tokenRevocationRepository.RevokeAllTokens(ctx, accountID, time.Now())
+	if err != nil {
+		return err
+	}
+	return h.accountRepository.RevokeAllSessions(ctx, accountID)
+}
+
+// logActivity records an action taken on the account, the administrator is its actor
+func (h *AdminHandler) logActivity(ctx context.Context, c *gin.Context, accountID uint, activity string) {
+	h.logger.WithField("userId", accountID).
+		WithField("adminId", c.GetUint(utils.AccountIdContextKey)).
+		Infof("admin action: %s", activity)
+
+	err := h.accountRepository.LogAccountActivity(ctx, accountID, activity)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to log activity: %v", err)
+	}
+}
@@ -0,0 +1,292 @@
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"go_starter_api/internal/account"
+	"go_starter_api/internal/admin"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm"
+)
+
+// adminID is the account making the requests
+const adminID = uint(100)
+
+func serve(method string, route string, target string, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Handle(method, route, func(c *gin.Context) {
+		c.Set(utils.AccountIdContextKey, adminID)
+	}, handler)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, target, nil)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAdminHandler_ListAccounts(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	t.Run("should list a filtered page of accounts", func(t *testing.T) {
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		createdAfter := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		repository.On("ListAccounts", anyContext, mock.MatchedBy(func(filter domain.AccountFilter) bool {
+			return filter.Email == "example.com" &&
+				filter.Status == domain.AccountStatusDisabled &&
+				filter.CreatedAfter != nil && filter.CreatedAfter.Equal(createdAfter) &&
+				filter.CreatedBefore == nil &&
+				filter.Offset == 10 && filter.Limit == 10
+		})).Return([]domain.Account{{ID: 1, Email: "test@example.com", DisabledAt: &createdAfter}}, int64(11), nil)
+
+		handler := admin.NewAdminHandler(logrus.New(), service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		w := serve("GET", "/admin/accounts", "/admin/accounts?page=2&page_size=10&email=example.com&status=disabled&created_after=2025-01-01", handler.ListAccounts)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response admin.ListAccountsResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, int64(11), response.Total)
+		assert.Equal(t, 2, response.Page)
+		assert.Len(t, response.Accounts, 1)
+		assert.Equal(t, domain.AccountStatusDisabled, response.Accounts[0].Status)
+		assert.NotContains(t, w.Body.String(), "password\":\"")
+	})
+
+	t.Run("should reject an unknown status", func(t *testing.T) {
+		handler := admin.NewAdminHandler(logrus.New(), domain.NewMockAccountService(t), domain.NewMockAccountRepository(t), account.NewInMemoryTokenRevocationRepository())
+
+		w := serve("GET", "/admin/accounts", "/admin/accounts?status=sleeping", handler.ListAccounts)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should reject a page size over the maximum", func(t *testing.T) {
+		handler := admin.NewAdminHandler(logrus.New(), domain.NewMockAccountService(t), domain.NewMockAccountRepository(t), account.NewInMemoryTokenRevocationRepository())
+
+		w := serve("GET", "/admin/accounts", "/admin/accounts?page_size=1000", handler.ListAccounts)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestAdminHandler_GetAccount(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	t.Run("should return the account with its recent activity", func(t *testing.T) {
+		repository := domain.NewMockAccountRepository(t)
+
+		repository.On("GetAccountByIDWithDeleted", anyContext, uint(1)).Return(&domain.Account{ID: 1, Email: "test@example.com"}, nil)
+		repository.On("ListAccountActivities", anyContext, uint(1), 50).Return([]domain.AccountActivity{
+			{AccountID: 1, Activity: domain.ActivityLogin},
+		}, nil)
+
+		handler := admin.NewAdminHandler(logrus.New(), domain.NewMockAccountService(t), repository, account.NewInMemoryTokenRevocationRepository())
+
+		w := serve("GET", "/admin/accounts/:id", "/admin/accounts/1", handler.GetAccount)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response admin.AccountDetailsResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "test@example.com", response.Account.Email)
+		assert.Equal(t, domain.AccountStatusActive, response.Account.Status)
+		assert.Len(t, response.Activities, 1)
+	})
+
+	t.Run("should return 404 for an unknown account", func(t *testing.T) {
+		repository := domain.NewMockAccountRepository(t)
+
+		repository.On("GetAccountByIDWithDeleted", anyContext, uint(1)).Return(nil, gorm.ErrRecordNotFound)
+
+		handler := admin.NewAdminHandler(logrus.New(), domain.NewMockAccountService(t), repository, account.NewInMemoryTokenRevocationRepository())
+
+		w := serve("GET", "/admin/accounts/:id", "/admin/accounts/1", handler.GetAccount)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestAdminHandler_ListActivities(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	t.Run("should list a filtered page of the activities of every account", func(t *testing.T) {
+		repository := domain.NewMockAccountRepository(t)
+
+		createdBefore := time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC)
+		actorID := adminID
+		repository.On("ListActivities", anyContext, mock.MatchedBy(func(filter domain.ActivityFilter) bool {
+			return filter.AccountID == 0 &&
+				filter.ActorID == adminID &&
+				filter.Activity == domain.ActivityAccountDisabled &&
+				filter.CreatedAfter == nil &&
+				filter.CreatedBefore != nil && filter.CreatedBefore.Equal(createdBefore) &&
+				filter.Offset == 0 && filter.Limit == 20
+		})).Return([]domain.AccountActivity{
+			{ID: 5, AccountID: 1, Activity: domain.ActivityAccountDisabled, ActorID: &actorID, IPAddress: "203.0.113.7", RequestID: "abc"},
+		}, int64(1), nil)
+
+		handler := admin.NewAdminHandler(logrus.New(), domain.NewMockAccountService(t), repository, account.NewInMemoryTokenRevocationRepository())
+
+		w := serve("GET", "/admin/activity", "/admin/activity?actor_id=100&type=account_disabled&created_before=2025-02-01T12:00:00Z", handler.ListActivities)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response admin.ListActivitiesResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, int64(1), response.Total)
+		assert.Equal(t, 20, response.PageSize)
+		assert.Len(t, response.Activities, 1)
+		assert.Equal(t, uint(1), response.Activities[0].AccountID)
+		assert.Equal(t, &actorID, response.Activities[0].ActorID)
+		assert.Equal(t, "203.0.113.7", response.Activities[0].IPAddress)
+	})
+
+	t.Run("should reject an invalid account id", func(t *testing.T) {
+		handler := admin.NewAdminHandler(logrus.New(), domain.NewMockAccountService(t), domain.NewMockAccountRepository(t), account.NewInMemoryTokenRevocationRepository())
+
+		w := serve("GET", "/admin/activity", "/admin/activity?account_id=-1", handler.ListActivities)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"invalid account_id"}`, w.Body.String())
+	})
+}
+
+func TestAdminHandler_DisableAccount(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	t.Run("should disable the account and revoke its access", func(t *testing.T) {
+		repository := domain.NewMockAccountRepository(t)
+		revocations := account.NewInMemoryTokenRevocationRepository()
+
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(&domain.Account{ID: 1}, nil)
+		repository.On("SetAccountDisabled", anyContext, uint(1), mock.AnythingOfType("*time.Time")).Return(nil)
+		repository.On("RevokeAllSessions", anyContext, uint(1)).Return(nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityAccountDisabled).Return(nil)
+
+		handler := admin.NewAdminHandler(logrus.New(), domain.NewMockAccountService(t), repository, revocations)
+
+		w := serve("POST", "/admin/accounts/:id/disable", "/admin/accounts/1/disable", handler.DisableAccount)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		revoked, err := revocations.IsTokenRevoked(context.Background(), &domain.AuthClaims{AccountID: 1, IssuedAt: time.Now().Add(-time.Minute)})
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	t.Run("should refuse to disable the account making the request", func(t *testing.T) {
+		repository := domain.NewMockAccountRepository(t)
+
+		repository.On("GetAccountByID", anyContext, adminID).Return(&domain.Account{ID: adminID}, nil)
+
+		handler := admin.NewAdminHandler(logrus.New(), domain.NewMockAccountService(t), repository, account.NewInMemoryTokenRevocationRepository())
+
+		w := serve("POST", "/admin/accounts/:id/disable", "/admin/accounts/100/disable", handler.DisableAccount)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestAdminHandler_ForcePasswordReset(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	service := domain.NewMockAccountService(t)
+	repository := domain.NewMockAccountRepository(t)
+
+	acc := &domain.Account{ID: 1, Email: "test@example.com"}
+	record := &domain.OneTimeToken{AccountID: 1, Purpose: domain.TokenPurposePasswordReset}
+	repository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+	repository.On("RequirePasswordReset", anyContext, uint(1)).Return(nil)
+	repository.On("RevokeAllSessions", anyContext, uint(1)).Return(nil)
+	repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityPasswordResetForced).Return(nil)
+	repository.On("RevokeOneTimeTokens", anyContext, uint(1), domain.TokenPurposePasswordReset).Return(nil)
+	service.On("GenerateOneTimeToken", anyContext, acc, domain.TokenPurposePasswordReset).Return("reset_token", record, nil)
+	repository.On("CreateOneTimeToken", anyContext, record).Return(record, nil)
+	service.On("SendPasswordResetEmail", anyContext, "test@example.com", "reset_token").Return(nil)
+
+	handler := admin.NewAdminHandler(logrus.New(), service, repository, account.NewInMemoryTokenRevocationRepository())
+
+	w := serve("POST", "/admin/accounts/:id/force-password-reset", "/admin/accounts/1/force-password-reset", handler.ForcePasswordReset)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAdminHandler_DeleteAndRestoreAccount(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	t.Run("should soft delete the account", func(t *testing.T) {
+		repository := domain.NewMockAccountRepository(t)
+
+		repository.On("GetAccountByID", anyContext, uint(1)).Return(&domain.Account{ID: 1}, nil)
+		repository.On("RevokeAllSessions", anyContext, uint(1)).Return(nil)
+		repository.On("DeleteAccount", anyContext, uint(1)).Return(nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityDelete).Return(nil)
+
+		handler := admin.NewAdminHandler(logrus.New(), domain.NewMockAccountService(t), repository, account.NewInMemoryTokenRevocationRepository())
+
+		w := serve("DELETE", "/admin/accounts/:id", "/admin/accounts/1", handler.DeleteAccount)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should restore a deleted account", func(t *testing.T) {
+		repository := domain.NewMockAccountRepository(t)
+
+		deleted := &domain.Account{ID: 1, DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}}
+		repository.On("GetAccountByIDWithDeleted", anyContext, uint(1)).Return(deleted, nil)
+		repository.On("RestoreAccount", anyContext, uint(1)).Return(nil)
+		repository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityAccountRestored).Return(nil)
+
+		handler := admin.NewAdminHandler(logrus.New(), domain.NewMockAccountService(t), repository, account.NewInMemoryTokenRevocationRepository())
+
+		w := serve("POST", "/admin/accounts/:id/restore", "/admin/accounts/1/restore", handler.RestoreAccount)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should refuse to restore an account that is not deleted", func(t *testing.T) {
+		repository := domain.NewMockAccountRepository(t)
+
+		repository.On("GetAccountByIDWithDeleted", anyContext, uint(1)).Return(&domain.Account{ID: 1}, nil)
+
+		handler := admin.NewAdminHandler(logrus.New(), domain.NewMockAccountService(t), repository, account.NewInMemoryTokenRevocationRepository())
+
+		w := serve("POST", "/admin/accounts/:id/restore", "/admin/accounts/1/restore", handler.RestoreAccount)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,214 @@
+package admin
+
+import (
+	"errors"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CreateOAuthClientRequest struct {
+	Name         string   `json:"name"`
+	RedirectURIs []string `json:"redirect_uris"`
+	// GrantTypes default to authorization_code
+	GrantTypes []string `json:"grant_types"`
+	// Scopes default to openid and email
+	Scopes []string `json:"scopes"`
+	// Public clients, such as single page and mobile apps, get no secret
+	Public bool `json:"public"`
+}
+
+type OAuthClientResponse struct {
+	ClientID     string    `json:"client_id"`
+	Name         string    `json:"name"`
+	Public       bool      `json:"public"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	GrantTypes   []string  `json:"grant_types"`
+	Scopes       []string  `json:"scopes"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type CreateOAuthClientResponse struct {
+	OAuthClientResponse
+	// ClientSecret is only returned once, public clients have none
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+type ListOAuthClientsResponse struct {
+	Clients []OAuthClientResponse `json:"clients"`
+}
+
+func toOAuthClientResponse(client *domain.OAuthClient) OAuthClientResponse {
+	return OAuthClientResponse{
+		ClientID:     client.ClientID,
+		Name:         client.Name,
+		Public:       client.Public,
+		RedirectURIs: client.RedirectURIs,
+		GrantTypes:   client.GrantTypes,
+		Scopes:       client.Scopes,
+		CreatedAt:    client.CreatedAt,
+	}
+}
+
+// @Summary		Register OAuth Client
+// @Description	Register an app delegating login to this api, requires oauth_clients:write. The secret of a confidential client is only returned here.
+// @Tags			admin
+// @Accept			json
+// @Produce		json
+// @Param			client	body		CreateOAuthClientRequest	true	"Client"
+// @Success		200		{object}	CreateOAuthClientResponse
+// @Failure		400		{object}	map[string]string
+// @Failure		403		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/admin/oauth-clients [post]
+func (h *AdminHandler) CreateOAuthClient(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "CreateOAuthClient")
+	defer span.End()
+
+	var req CreateOAuthClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	if len(req.GrantTypes) == 0 {
+		req.GrantTypes = []string{domain.GrantTypeAuthorizationCode}
+	}
+	for _, grantType := range req.GrantTypes {
+		if grantType != domain.GrantTypeAuthorizationCode && grantType != domain.GrantTypeClientCredentials {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported grant type " + grantType})
+			return
+		}
+	}
+	if req.Public && slices.Contains(req.GrantTypes, domain.GrantTypeClientCredentials) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "public clients cannot use client credentials"})
+		return
+	}
+
+	if slices.Contains(req.GrantTypes, domain.GrantTypeAuthorizationCode) && len(req.RedirectURIs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "redirect_uris are required for the authorization code grant"})
+		return
+	}
+	for _, redirectURI := range req.RedirectURIs {
+		// codes are sent to the uri as is, it must be absolute and leave the query to us
+		u, err := url.Parse(redirectURI)
+		if err != nil || u.Scheme == "" || u.Fragment != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid redirect uri " + redirectURI})
+			return
+		}
+	}
+
+	if len(req.Scopes) == 0 {
+		req.Scopes = []string{domain.ScopeOpenID, domain.ScopeEmail}
+	}
+	for _, scope := range req.Scopes {
+		if scope == "" || strings.ContainsAny(scope, " \t\n\"\\") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid scope " + scope})
+			return
+		}
+	}
+
+	secret, client, err := h.accountService.GenerateOAuthClient(ctx, req.Public)
+	if err != nil {
+		h.logger.Errorf("failed to generate oauth client: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	client.Name = req.Name
+	client.RedirectURIs = req.RedirectURIs
+	client.GrantTypes = req.GrantTypes
+	client.Scopes = req.Scopes
+
+	client, err = h.accountRepository.CreateOAuthClient(ctx, client)
+	if err != nil {
+		h.logger.Errorf("failed to create oauth client: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.logger.WithField("adminId", c.GetUint(utils.AccountIdContextKey)).
+		WithField("clientId", client.ClientID).
+		Infof("admin action: oauth client registered")
+
+	c.JSON(http.StatusOK, CreateOAuthClientResponse{
+		OAuthClientResponse: toOAuthClientResponse(client),
+		ClientSecret:        secret,
+	})
+}
+
+// @Summary		List OAuth Clients
+// @Description	List the registered apps, requires oauth_clients:read
+// @Tags			admin
+// @Produce		json
+// @Success		200	{object}	ListOAuthClientsResponse
+// @Failure		403	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/admin/oauth-clients [get]
+func (h *AdminHandler) ListOAuthClients(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "ListOAuthClients")
+	defer span.End()
+
+	clients, err := h.accountRepository.ListOAuthClients(ctx)
+	if err != nil {
+		h.logger.Errorf("failed to list oauth clients: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	response := ListOAuthClientsResponse{Clients: []OAuthClientResponse{}}
+	for i := range clients {
+		response.Clients = append(response.Clients, toOAuthClientResponse(&clients[i]))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary		Delete OAuth Client
+// @Description	Delete a registered app, its codes and secret stop working. Tokens already issued stay valid until they expire. Requires oauth_clients:write.
+// @Tags			admin
+// @Produce		json
+// @Param			clientId	path		string	true	"Client ID"
+// @Success		200			{object}	map[string]string
+// @Failure		403			{object}	map[string]string
+// @Failure		404			{object}	map[string]string
+// @Failure		500			{object}	map[string]string
+// @Router			/api/v1/admin/oauth-clients/{clientId} [delete]
+func (h *AdminHandler) DeleteOAuthClient(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "DeleteOAuthClient")
+	defer span.End()
+
+	clientID := c.Param("clientId")
+
+	err := h.accountRepository.DeleteOAuthClient(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "oauth client not found"})
+			return
+		}
+		h.logger.WithField("clientId", clientID).Errorf("failed to delete oauth client: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.logger.WithField("adminId", c.GetUint(utils.AccountIdContextKey)).
+		WithField("clientId", clientID).
+		Infof("admin action: oauth client deleted")
+
+	c.JSON(http.StatusOK, gin.H{"message": "oauth client deleted"})
+}
@@ -0,0 +1,97 @@
+package admin_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"go_starter_api/internal/account"
+	"go_starter_api/internal/admin"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm"
+)
+
+func TestAdminHandler_OAuthClients(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	create := func(handler gin.HandlerFunc, body admin.CreateOAuthClientRequest) *httptest.ResponseRecorder {
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.POST("/admin/oauth-clients", func(c *gin.Context) {
+			c.Set(utils.AccountIdContextKey, adminID)
+		}, handler)
+
+		payload, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/admin/oauth-clients", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("should register a client and return its secret once", func(t *testing.T) {
+		service := domain.NewMockAccountService(t)
+		repository := domain.NewMockAccountRepository(t)
+
+		service.On("GenerateOAuthClient", anyContext, false).Return("secret", &domain.OAuthClient{ClientID: "client", SecretHash: "hash"}, nil)
+		repository.On("CreateOAuthClient", anyContext, mock.MatchedBy(func(c *domain.OAuthClient) bool {
+			return c.Name == "Wiki" && c.SecretHash == "hash" &&
+				assert.ObjectsAreEqual([]string{domain.GrantTypeAuthorizationCode}, c.GrantTypes) &&
+				assert.ObjectsAreEqual([]string{domain.ScopeOpenID, domain.ScopeEmail}, c.Scopes)
+		})).Return(func(_ context.Context, c *domain.OAuthClient) *domain.OAuthClient { return c }, nil)
+
+		handler := admin.NewAdminHandler(logrus.New(), service, repository, account.NewInMemoryTokenRevocationRepository())
+
+		w := create(handler.CreateOAuthClient, admin.CreateOAuthClientRequest{Name: " Wiki ", RedirectURIs: []string{"https://wiki.example.com/callback"}})
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response admin.CreateOAuthClientResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "client", response.ClientID)
+		assert.Equal(t, "secret", response.ClientSecret)
+		assert.NotContains(t, w.Body.String(), "hash")
+	})
+
+	t.Run("should refuse invalid clients", func(t *testing.T) {
+		handler := admin.NewAdminHandler(logrus.New(), domain.NewMockAccountService(t), domain.NewMockAccountRepository(t), account.NewInMemoryTokenRevocationRepository())
+
+		for name, body := range map[string]admin.CreateOAuthClientRequest{
+			"missing name":             {RedirectURIs: []string{"https://wiki.example.com/callback"}},
+			"missing redirect uri":     {Name: "Wiki"},
+			"relative redirect uri":    {Name: "Wiki", RedirectURIs: []string{"/callback"}},
+			"unknown grant":            {Name: "Wiki", GrantTypes: []string{"password"}},
+			"public client credential": {Name: "Wiki", Public: true, GrantTypes: []string{domain.GrantTypeClientCredentials}},
+			"scope with a space":       {Name: "Wiki", GrantTypes: []string{domain.GrantTypeClientCredentials}, Scopes: []string{"wiki read"}},
+		} {
+			w := create(handler.CreateOAuthClient, body)
+			assert.Equal(t, http.StatusBadRequest, w.Code, name)
+		}
+	})
+
+	t.Run("should delete a client", func(t *testing.T) {
+		repository := domain.NewMockAccountRepository(t)
+		repository.On("DeleteOAuthClient", anyContext, "client").Return(nil)
+		repository.On("DeleteOAuthClient", anyContext, "other").Return(gorm.ErrRecordNotFound)
+
+		handler := admin.NewAdminHandler(logrus.New(), domain.NewMockAccountService(t), repository, account.NewInMemoryTokenRevocationRepository())
+
+		w := serve("DELETE", "/admin/oauth-clients/:clientId", "/admin/oauth-clients/client", handler.DeleteOAuthClient)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = serve("DELETE", "/admin/oauth-clients/:clientId", "/admin/oauth-clients/other", handler.DeleteOAuthClient)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,204 @@
+package export
+
+import (
+	"errors"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const name = "exportHandler"
+
+type ExportHandler struct {
+	logger *logrus.Logger
+	tracer trace.Tracer
+
+	accountService    domain.AccountService
+	accountRepository domain.AccountRepository
+	exportRepository  domain.ExportRepository
+}
+
+func NewExportHandler(
+	logger *logrus.Logger,
+	accountService domain.AccountService,
+	accountRepository domain.AccountRepository,
+	exportRepository domain.ExportRepository,
+) *ExportHandler {
+	tracer := otel.Tracer(name)
+	return &ExportHandler{
+		logger:            logger,
+		tracer:            tracer,
+		accountService:    accountService,
+		accountRepository: accountRepository,
+		exportRepository:  exportRepository,
+	}
+}
+
+type DataExportResponse struct {
+	ID          uint       `json:"id"`
+	Status      string     `json:"status"`
+	Size        int64      `json:"size"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+func newDataExportResponse(export *domain.DataExport) DataExportResponse {
+	return DataExportResponse{
+		ID:          export.ID,
+		Status:      export.Status,
+		Size:        export.Size,
+		CreatedAt:   export.CreatedAt,
+		CompletedAt: export.CompletedAt,
+		ExpiresAt:   export.ExpiresAt,
+	}
+}
+
+// @Summary		Request Data Export
+// @Description	Request an archive of everything stored about the authenticated account. It is built in the background and a download link is sent by email once it is ready.
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Success		202		{object}	DataExportResponse
+// @Failure		409		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/export [post]
+func (h *ExportHandler) RequestExport(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "RequestExport")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	export, err := h.exportRepository.CreateDataExport(ctx, &domain.DataExport{AccountID: accountID})
+	if err != nil {
+		if errors.Is(err, domain.ErrDataExportInProgress) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.WithField("userId", accountID).Errorf("failed to create data export: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	err = h.accountRepository.LogAccountActivity(ctx, accountID, domain.ActivityDataExportRequested)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to log activity: %v", err)
+	}
+
+	c.JSON(http.StatusAccepted, newDataExportResponse(export))
+}
+
+// @Summary		Get Data Export
+// @Description	Get the status of a data export of the authenticated account
+// @Tags			account
+// @Accept			json
+// @Produce		json
+// @Param			id	path		int	true	"Export ID"
+// @Success		200	{object}	DataExportResponse
+// @Failure		400	{object}	map[string]string
+// @Failure		404	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/account/export/{id} [get]
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "GetExport")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid export id"})
+		return
+	}
+
+	export, err := h.exportRepository.GetAccountDataExport(ctx, accountID, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "export not found"})
+			return
+		}
+		h.logger.WithField("userId", accountID).Errorf("failed to get data export: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newDataExportResponse(export))
+}
+
+// @Summary		Download Data Export
+// @Description	Download a data export with the link sent by email, the link expires with the export
+// @Tags			account
+// @Produce		application/zip
+// @Param			token	query		string	true	"Token"
+// @Success		200		{file}		file
+// @Failure		400		{object}	map[string]string
+// @Failure		500		{object}	map[string]string
+// @Router			/api/v1/account/export/download [get]
+func (h *ExportHandler) DownloadExport(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "DownloadExport")
+	defer span.End()
+
+	exportID, err := h.accountService.ValidateDataExportToken(ctx, c.Query("token"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+		return
+	}
+
+	export, err := h.exportRepository.GetDataExport(ctx, exportID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+			return
+		}
+		h.logger.Errorf("failed to get data export: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if export.Status != domain.DataExportStatusReady || export.ExpiresAt == nil || !time.Now().Before(*export.ExpiresAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+		return
+	}
+
+	// the link outlives a deletion of the account, its data is no longer handed out
+	_, err = h.accountRepository.GetAccountByID(ctx, export.AccountID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired token"})
+			return
+		}
+		h.logger.WithField("userId", export.AccountID).Errorf("failed to get account by id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	err = h.accountRepository.LogAccountActivity(ctx, export.AccountID, domain.ActivityDataExportDownloaded)
+	if err != nil {
+		h.logger.WithField("userId", export.AccountID).Errorf("failed to log activity: %v", err)
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="data-export-`+strconv.FormatUint(uint64(export.ID), 10)+`.zip"`)
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/zip", export.Archive)
+}
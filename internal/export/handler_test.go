@@ -0,0 +1,173 @@
+package export_test
+
+import (
+	"context"
+	"errors"
+	"go_starter_api/internal/export"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm"
+)
+
+// serve runs the handler for the account, an account id of 0 leaves the request anonymous
+func serve(accountID uint, method string, route string, target string, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Handle(method, route, func(c *gin.Context) {
+		if accountID != 0 {
+			c.Set(utils.AccountIdContextKey, accountID)
+		}
+	}, handler)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, target, nil)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestExportHandler_RequestExport(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	t.Run("should accept the export request", func(t *testing.T) {
+		accountRepository := domain.NewMockAccountRepository(t)
+		exportRepository := domain.NewMockExportRepository(t)
+
+		exportRepository.On("CreateDataExport", anyContext, &domain.DataExport{AccountID: 1}).
+			Return(&domain.DataExport{ID: 5, AccountID: 1, Status: domain.DataExportStatusPending}, nil)
+		accountRepository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityDataExportRequested).Return(nil)
+
+		handler := export.NewExportHandler(logrus.New(), domain.NewMockAccountService(t), accountRepository, exportRepository)
+
+		w := serve(1, "POST", "/account/export", "/account/export", handler.RequestExport)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"pending"`)
+	})
+
+	t.Run("should refuse a second export in progress", func(t *testing.T) {
+		exportRepository := domain.NewMockExportRepository(t)
+
+		exportRepository.On("CreateDataExport", anyContext, mock.Anything).Return(nil, domain.ErrDataExportInProgress)
+
+		handler := export.NewExportHandler(logrus.New(), domain.NewMockAccountService(t), domain.NewMockAccountRepository(t), exportRepository)
+
+		w := serve(1, "POST", "/account/export", "/account/export", handler.RequestExport)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.JSONEq(t, `{"error":"a data export is already in progress"}`, w.Body.String())
+	})
+}
+
+func TestExportHandler_GetExport(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	t.Run("should only find the exports of the account", func(t *testing.T) {
+		exportRepository := domain.NewMockExportRepository(t)
+
+		exportRepository.On("GetAccountDataExport", anyContext, uint(1), uint(5)).Return(nil, gorm.ErrRecordNotFound)
+
+		handler := export.NewExportHandler(logrus.New(), domain.NewMockAccountService(t), domain.NewMockAccountRepository(t), exportRepository)
+
+		w := serve(1, "GET", "/account/export/:id", "/account/export/5", handler.GetExport)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestExportHandler_DownloadExport(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	ready := func(expiresAt time.Time) *domain.DataExport {
+		return &domain.DataExport{
+			ID:        5,
+			AccountID: 1,
+			Status:    domain.DataExportStatusReady,
+			Archive:   []byte("archive"),
+			ExpiresAt: &expiresAt,
+		}
+	}
+
+	t.Run("should serve the archive", func(t *testing.T) {
+		service := domain.NewMockAccountService(t)
+		accountRepository := domain.NewMockAccountRepository(t)
+		exportRepository := domain.NewMockExportRepository(t)
+
+		service.On("ValidateDataExportToken", anyContext, "export_token").Return(uint(5), nil)
+		exportRepository.On("GetDataExport", anyContext, uint(5)).Return(ready(time.Now().Add(time.Hour)), nil)
+		accountRepository.On("GetAccountByID", anyContext, uint(1)).Return(&domain.Account{ID: 1}, nil)
+		accountRepository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityDataExportDownloaded).Return(nil)
+
+		handler := export.NewExportHandler(logrus.New(), service, accountRepository, exportRepository)
+
+		w := serve(0, "GET", "/account/export/download", "/account/export/download?token=export_token", handler.DownloadExport)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="data-export-5.zip"`, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "archive", w.Body.String())
+	})
+
+	t.Run("should refuse an invalid token", func(t *testing.T) {
+		service := domain.NewMockAccountService(t)
+
+		service.On("ValidateDataExportToken", anyContext, "bad_token").Return(uint(0), errors.New("token is expired"))
+
+		handler := export.NewExportHandler(logrus.New(), service, domain.NewMockAccountRepository(t), domain.NewMockExportRepository(t))
+
+		w := serve(0, "GET", "/account/export/download", "/account/export/download?token=bad_token", handler.DownloadExport)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"error":"invalid or expired token"}`, w.Body.String())
+	})
+
+	t.Run("should refuse an expired export", func(t *testing.T) {
+		service := domain.NewMockAccountService(t)
+		exportRepository := domain.NewMockExportRepository(t)
+
+		service.On("ValidateDataExportToken", anyContext, "export_token").Return(uint(5), nil)
+		exportRepository.On("GetDataExport", anyContext, uint(5)).Return(ready(time.Now().Add(-time.Minute)), nil)
+
+		handler := export.NewExportHandler(logrus.New(), service, domain.NewMockAccountRepository(t), exportRepository)
+
+		w := serve(0, "GET", "/account/export/download", "/account/export/download?token=export_token", handler.DownloadExport)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should not hand out the data of a deleted account", func(t *testing.T) {
+		service := domain.NewMockAccountService(t)
+		accountRepository := domain.NewMockAccountRepository(t)
+		exportRepository := domain.NewMockExportRepository(t)
+
+		service.On("ValidateDataExportToken", anyContext, "export_token").Return(uint(5), nil)
+		exportRepository.On("GetDataExport", anyContext, uint(5)).Return(ready(time.Now().Add(time.Hour)), nil)
+		accountRepository.On("GetAccountByID", anyContext, uint(1)).Return(nil, gorm.ErrRecordNotFound)
+
+		handler := export.NewExportHandler(logrus.New(), service, accountRepository, exportRepository)
+
+		w := serve(0, "GET", "/account/export/download", "/account/export/download?token=export_token", handler.DownloadExport)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		accountRepository.AssertNotCalled(t, "LogAccountActivity", anyContext, mock.Anything, mock.Anything)
+	})
+}
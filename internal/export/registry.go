@@ -0,0 +1,68 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go_starter_api/pkg/domain"
+	"time"
+)
+
+// Registry holds the exporters of the modules, each adds a json file to the archive
+type Registry struct {
+	exporters []domain.Exporter
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds the exporter of a module, it panics when another exporter has the same name
+// as a mistake in the wiring of the modules
+func (r *Registry) Register(exporter domain.Exporter) {
+	for _, registered := range r.exporters {
+		if registered.Name() == exporter.Name() {
+			panic(fmt.Sprintf("exporter %q is already registered", exporter.Name()))
+		}
+	}
+	r.exporters = append(r.exporters, exporter)
+}
+
+// Archive returns the zip archive of the account's data, an exporter failing fails the whole
+// archive as a partial export would look complete to its owner
+func (r *Registry) Archive(ctx context.Context, accountID uint, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	for _, exporter := range r.exporters {
+		data, err := exporter.Export(ctx, accountID)
+		if err != nil {
+			return nil, fmt.Errorf("exporter %s: %w", exporter.Name(), err)
+		}
+
+		encoded, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("exporter %s: %w", exporter.Name(), err)
+		}
+
+		file, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     exporter.Name() + ".json",
+			Method:   zip.Deflate,
+			Modified: now,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := file.Write(encoded); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,164 @@
+package export
+
+import (
+	"context"
+	"go_starter_api/pkg/domain"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ExportRepo struct {
+	db    *gorm.DB
+	trace trace.Tracer
+}
+
+func NewExportRepository(db *gorm.DB) domain.ExportRepository {
+	trace := otel.Tracer("exportRepository")
+	return &ExportRepo{
+		db:    db,
+		trace: trace,
+	}
+}
+
+func (r *ExportRepo) CreateDataExport(ctx context.Context, export *domain.DataExport) (*domain.DataExport, error) {
+	_, span := r.trace.Start(ctx, "CreateDataExport")
+	defer span.End()
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// the account row serializes the requests of the account, so two of them cannot both
+		// find no export in progress
+		err := tx.Model(&domain.Account{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", export.AccountID).
+			Select("id").
+			Take(&domain.Account{}).Error
+		if err != nil {
+			return err
+		}
+
+		var inProgress int64
+		err = tx.Model(&domain.DataExport{}).
+			Where("account_id = ? AND status IN ?", export.AccountID, []string{domain.DataExportStatusPending, domain.DataExportStatusProcessing}).
+			Count(&inProgress).Error
+		if err != nil {
+			return err
+		}
+		if inProgress > 0 {
+			return domain.ErrDataExportInProgress
+		}
+
+		export.Status = domain.DataExportStatusPending
+		return tx.Create(export).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+func (r *ExportRepo) GetDataExport(ctx context.Context, id uint) (*domain.DataExport, error) {
+	_, span := r.trace.Start(ctx, "GetDataExport")
+	defer span.End()
+	var export domain.DataExport
+	err := r.db.First(&export, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *ExportRepo) GetAccountDataExport(ctx context.Context, accountID uint, id uint) (*domain.DataExport, error) {
+	_, span := r.trace.Start(ctx, "GetAccountDataExport")
+	defer span.End()
+	var export domain.DataExport
+	err := r.db.Omit("archive").
+		Where("id = ? AND account_id = ?", id, accountID).
+		First(&export).Error
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *ExportRepo) ListClaimableDataExports(ctx context.Context, staleBefore time.Time, limit int) ([]uint, error) {
+	_, span := r.trace.Start(ctx, "ListClaimableDataExports")
+	defer span.End()
+	var ids []uint
+	err := claimable(r.db.Model(&domain.DataExport{}), staleBefore).
+		Order("id").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *ExportRepo) ClaimDataExport(ctx context.Context, id uint, staleBefore time.Time) (*domain.DataExport, error) {
+	_, span := r.trace.Start(ctx, "ClaimDataExport")
+	defer span.End()
+	var export domain.DataExport
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// the conditional update is the claim, a concurrent worker updates no row
+		result := claimable(tx.Model(&domain.DataExport{}).Where("id = ?", id), staleBefore).
+			Updates(map[string]interface{}{
+				"status":     domain.DataExportStatusProcessing,
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrDataExportNotClaimable
+		}
+		return tx.Omit("archive").First(&export, id).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// claimable scopes the query to the pending exports and the processing ones left by a lost worker
+func claimable(db *gorm.DB, staleBefore time.Time) *gorm.DB {
+	return db.Where("status = ? OR (status = ? AND updated_at < ?)",
+		domain.DataExportStatusPending, domain.DataExportStatusProcessing, staleBefore)
+}
+
+func (r *ExportRepo) CompleteDataExport(ctx context.Context, id uint, archive []byte, completedAt time.Time, expiresAt time.Time) error {
+	_, span := r.trace.Start(ctx, "CompleteDataExport")
+	defer span.End()
+	return r.db.Model(&domain.DataExport{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       domain.DataExportStatusReady,
+			"archive":      archive,
+			"size":         len(archive),
+			"completed_at": completedAt,
+			"expires_at":   expiresAt,
+		}).Error
+}
+
+func (r *ExportRepo) FailDataExport(ctx context.Context, id uint, completedAt time.Time, expiresAt time.Time) error {
+	_, span := r.trace.Start(ctx, "FailDataExport")
+	defer span.End()
+	return r.db.Model(&domain.DataExport{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       domain.DataExportStatusFailed,
+			"completed_at": completedAt,
+			"expires_at":   expiresAt,
+		}).Error
+}
+
+func (r *ExportRepo) DeleteExpiredDataExports(ctx context.Context, now time.Time) (int64, error) {
+	_, span := r.trace.Start(ctx, "DeleteExpiredDataExports")
+	defer span.End()
+	result := r.db.Unscoped().
+		Where("expires_at <= ?", now).
+		Delete(&domain.DataExport{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,176 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"go_starter_api/pkg/domain"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultDataExportPollInterval = 30 * time.Second
+	defaultDataExportTTL          = 7 * 24 * time.Hour
+	// a processing export untouched for this long lost its worker and is claimed again
+	dataExportClaimTimeout = 15 * time.Minute
+	// exports claimed per query while processing
+	dataExportBatchSize = 10
+)
+
+// Worker builds the requested exports in the background and deletes the expired ones
+type Worker struct {
+	logger *logrus.Logger
+	tracer trace.Tracer
+
+	registry          *Registry
+	exportRepository  domain.ExportRepository
+	accountRepository domain.AccountRepository
+	accountService    domain.AccountService
+}
+
+func NewWorker(
+	logger *logrus.Logger,
+	registry *Registry,
+	exportRepository domain.ExportRepository,
+	accountRepository domain.AccountRepository,
+	accountService domain.AccountService,
+) *Worker {
+	return &Worker{
+		logger:            logger,
+		tracer:            otel.Tracer("exportWorker"),
+		registry:          registry,
+		exportRepository:  exportRepository,
+		accountRepository: accountRepository,
+		accountService:    accountService,
+	}
+}
+
+// Run processes the pending exports every DATA_EXPORT_POLL_INTERVAL until the context is done
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(dataExportPollInterval())
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+
+		built, err := w.ProcessPending(ctx, now)
+		if err != nil && ctx.Err() == nil {
+			w.logger.Errorf("failed to process data exports: %v", err)
+		}
+		if built > 0 {
+			w.logger.Infof("built %d data exports", built)
+		}
+
+		_, err = w.exportRepository.DeleteExpiredDataExports(ctx, now)
+		if err != nil && ctx.Err() == nil {
+			w.logger.Errorf("failed to delete expired data exports: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessPending builds every claimable export and returns how many were built, an export
+// that cannot be built is marked failed without stopping the others
+func (w *Worker) ProcessPending(ctx context.Context, now time.Time) (int, error) {
+	ctx, span := w.tracer.Start(ctx, "ProcessPending")
+	defer span.End()
+
+	staleBefore := now.Add(-dataExportClaimTimeout)
+
+	built := 0
+	for {
+		ids, err := w.exportRepository.ListClaimableDataExports(ctx, staleBefore, dataExportBatchSize)
+		if err != nil {
+			return built, err
+		}
+
+		for _, id := range ids {
+			export, err := w.exportRepository.ClaimDataExport(ctx, id, staleBefore)
+			// claimed by another worker since it was listed
+			if errors.Is(err, domain.ErrDataExportNotClaimable) {
+				continue
+			}
+			if err != nil {
+				return built, err
+			}
+
+			ok, err := w.build(ctx, export, now)
+			if err != nil {
+				return built, err
+			}
+			if ok {
+				built++
+			}
+		}
+
+		if len(ids) < dataExportBatchSize {
+			return built, nil
+		}
+	}
+}
+
+// build archives the data of the export's account and emails its download link, it only
+// returns the errors of the repository, the others fail the export
+func (w *Worker) build(ctx context.Context, export *domain.DataExport, now time.Time) (bool, error) {
+	expiresAt := now.Add(dataExportTTL())
+
+	acc, err := w.accountRepository.GetAccountByID(ctx, export.AccountID)
+	if err != nil {
+		w.logger.WithField("userId", export.AccountID).Errorf("failed to get account of data export %d: %v", export.ID, err)
+		return false, w.exportRepository.FailDataExport(ctx, export.ID, now, expiresAt)
+	}
+
+	archive, err := w.registry.Archive(ctx, export.AccountID, now)
+	if err != nil {
+		w.logger.WithField("userId", export.AccountID).Errorf("failed to build data export %d: %v", export.ID, err)
+		return false, w.exportRepository.FailDataExport(ctx, export.ID, now, expiresAt)
+	}
+
+	err = w.exportRepository.CompleteDataExport(ctx, export.ID, archive, now, expiresAt)
+	if err != nil {
+		return false, err
+	}
+
+	export.Status = domain.DataExportStatusReady
+	export.ExpiresAt = &expiresAt
+
+	// the export is ready, a lost email only means the owner requests another one
+	token, err := w.accountService.GenerateDataExportToken(ctx, export)
+	if err != nil {
+		w.logger.WithField("userId", export.AccountID).Errorf("failed to generate data export token: %v", err)
+		return true, nil
+	}
+
+	err = w.accountService.SendDataExportReadyEmail(ctx, acc.Email, expiresAt, token)
+	if err != nil {
+		w.logger.WithField("userId", export.AccountID).Errorf("failed to send data export ready email: %v", err)
+	}
+
+	return true, nil
+}
+
+func dataExportPollInterval() time.Duration {
+	interval := viper.GetDuration("DATA_EXPORT_POLL_INTERVAL")
+	if interval <= 0 {
+		return defaultDataExportPollInterval
+	}
+	return interval
+}
+
+// dataExportTTL is how long a built export can be downloaded
+func dataExportTTL() time.Duration {
+	ttl := viper.GetDuration("DATA_EXPORT_TTL")
+	if ttl <= 0 {
+		return defaultDataExportTTL
+	}
+	return ttl
+}
@@ -0,0 +1,135 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"go_starter_api/internal/export"
+	"go_starter_api/pkg/domain"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// archiveFiles returns the files of a zip archive by name
+func archiveFiles(t *testing.T, archive []byte) map[string]string {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	assert.NoError(t, err)
+
+	files := map[string]string{}
+	for _, file := range reader.File {
+		content, err := file.Open()
+		assert.NoError(t, err)
+		data, err := io.ReadAll(content)
+		assert.NoError(t, err)
+		files[file.Name] = string(data)
+	}
+	return files
+}
+
+func exporter(t *testing.T, name string, data any, err error) *domain.MockExporter {
+	e := domain.NewMockExporter(t)
+	e.On("Name").Return(name).Maybe()
+	e.On("Export", mock.Anything, uint(1)).Return(data, err).Maybe()
+	return e
+}
+
+func TestRegistry_Register(t *testing.T) {
+
+	t.Run("should refuse two exporters with the same name", func(t *testing.T) {
+		registry := export.NewRegistry()
+		registry.Register(exporter(t, "account", nil, nil))
+
+		assert.Panics(t, func() {
+			registry.Register(exporter(t, "account", nil, nil))
+		})
+	})
+}
+
+func TestWorker_ProcessPending(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	now := time.Now()
+	staleBefore := mock.MatchedBy(func(before time.Time) bool { return before.Before(now) })
+
+	t.Run("should archive the data of every exporter and email the link", func(t *testing.T) {
+		service := domain.NewMockAccountService(t)
+		accountRepository := domain.NewMockAccountRepository(t)
+		exportRepository := domain.NewMockExportRepository(t)
+
+		registry := export.NewRegistry()
+		registry.Register(exporter(t, "account", map[string]string{"email": "test@example.com"}, nil))
+		registry.Register(exporter(t, "organizations", []string{}, nil))
+
+		exportRepository.On("ListClaimableDataExports", anyContext, staleBefore, mock.AnythingOfType("int")).Return([]uint{5}, nil)
+		exportRepository.On("ClaimDataExport", anyContext, uint(5), staleBefore).
+			Return(&domain.DataExport{ID: 5, AccountID: 1, Status: domain.DataExportStatusProcessing}, nil)
+		accountRepository.On("GetAccountByID", anyContext, uint(1)).Return(&domain.Account{ID: 1, Email: "test@example.com"}, nil)
+
+		var archive []byte
+		exportRepository.On("CompleteDataExport", anyContext, uint(5), mock.Anything, now, mock.AnythingOfType("time.Time")).
+			Run(func(args mock.Arguments) { archive = args.Get(2).([]byte) }).
+			Return(nil)
+		service.On("GenerateDataExportToken", anyContext, mock.MatchedBy(func(e *domain.DataExport) bool {
+			return e.ID == 5 && e.Status == domain.DataExportStatusReady && e.ExpiresAt != nil
+		})).Return("export_token", nil)
+		service.On("SendDataExportReadyEmail", anyContext, "test@example.com", mock.AnythingOfType("time.Time"), "export_token").Return(nil)
+
+		worker := export.NewWorker(logrus.New(), registry, exportRepository, accountRepository, service)
+
+		built, err := worker.ProcessPending(context.Background(), now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, built)
+		files := archiveFiles(t, archive)
+		assert.JSONEq(t, `{"email":"test@example.com"}`, files["account.json"])
+		assert.JSONEq(t, `[]`, files["organizations.json"])
+	})
+
+	t.Run("should fail the export when an exporter fails", func(t *testing.T) {
+		service := domain.NewMockAccountService(t)
+		accountRepository := domain.NewMockAccountRepository(t)
+		exportRepository := domain.NewMockExportRepository(t)
+
+		registry := export.NewRegistry()
+		registry.Register(exporter(t, "account", nil, errors.New("db error")))
+
+		exportRepository.On("ListClaimableDataExports", anyContext, staleBefore, mock.AnythingOfType("int")).Return([]uint{5}, nil)
+		exportRepository.On("ClaimDataExport", anyContext, uint(5), staleBefore).
+			Return(&domain.DataExport{ID: 5, AccountID: 1, Status: domain.DataExportStatusProcessing}, nil)
+		accountRepository.On("GetAccountByID", anyContext, uint(1)).Return(&domain.Account{ID: 1, Email: "test@example.com"}, nil)
+		exportRepository.On("FailDataExport", anyContext, uint(5), now, mock.AnythingOfType("time.Time")).Return(nil)
+
+		worker := export.NewWorker(logrus.New(), registry, exportRepository, accountRepository, service)
+
+		built, err := worker.ProcessPending(context.Background(), now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, built)
+		service.AssertNotCalled(t, "SendDataExportReadyEmail", anyContext, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should skip the exports claimed by another worker", func(t *testing.T) {
+		exportRepository := domain.NewMockExportRepository(t)
+
+		exportRepository.On("ListClaimableDataExports", anyContext, staleBefore, mock.AnythingOfType("int")).Return([]uint{5}, nil)
+		exportRepository.On("ClaimDataExport", anyContext, uint(5), staleBefore).Return(nil, domain.ErrDataExportNotClaimable)
+
+		worker := export.NewWorker(logrus.New(), export.NewRegistry(), exportRepository, domain.NewMockAccountRepository(t), domain.NewMockAccountService(t))
+
+		built, err := worker.ProcessPending(context.Background(), now)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, built)
+	})
+}
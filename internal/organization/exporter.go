@@ -0,0 +1,60 @@
+package organization
+
+import (
+	"context"
+	"go_starter_api/pkg/domain"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type MembershipExport struct {
+	OrganizationID   uint      `json:"organization_id"`
+	OrganizationName string    `json:"organization_name"`
+	Role             string    `json:"role"`
+	JoinedAt         time.Time `json:"joined_at"`
+}
+
+// Exporter adds the organizations the account is a member of to the data export
+type Exporter struct {
+	tracer trace.Tracer
+
+	organizationRepository domain.OrganizationRepository
+}
+
+func NewExporter(organizationRepository domain.OrganizationRepository) *Exporter {
+	return &Exporter{
+		tracer:                 otel.Tracer("organizationExporter"),
+		organizationRepository: organizationRepository,
+	}
+}
+
+func (e *Exporter) Name() string {
+	return "organizations"
+}
+
+func (e *Exporter) Export(ctx context.Context, accountID uint) (any, error) {
+	ctx, span := e.tracer.Start(ctx, "Export")
+	defer span.End()
+
+	memberships, err := e.organizationRepository.ListAccountMemberships(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := make([]MembershipExport, 0, len(memberships))
+	for _, membership := range memberships {
+		item := MembershipExport{
+			OrganizationID: membership.OrganizationID,
+			Role:           membership.Role,
+			JoinedAt:       membership.CreatedAt,
+		}
+		if membership.Organization != nil {
+			item.OrganizationName = membership.Organization.Name
+		}
+		export = append(export, item)
+	}
+
+	return export, nil
+}
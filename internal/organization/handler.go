@@ -0,0 +1,391 @@
+package organization
+
+import (
+	"context"
+	"errors"
+	"go_starter_api/internal/account"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	name = "organizationHandler"
+
+	maxNameLength = 100
+)
+
+type OrganizationHandler struct {
+	logger *logrus.Logger
+	tracer trace.Tracer
+
+	accountService         domain.AccountService
+	accountRepository      domain.AccountRepository
+	organizationRepository domain.OrganizationRepository
+	// accountHandler registers the accounts created by accepting an invitation
+	accountHandler *account.AccountHandler
+}
+
+func NewOrganizationHandler(
+	logger *logrus.Logger,
+	accountService domain.AccountService,
+	accountRepository domain.AccountRepository,
+	organizationRepository domain.OrganizationRepository,
+	accountHandler *account.AccountHandler,
+) *OrganizationHandler {
+	tracer := otel.Tracer(name)
+	return &OrganizationHandler{
+		logger:                 logger,
+		tracer:                 tracer,
+		accountService:         accountService,
+		accountRepository:      accountRepository,
+		organizationRepository: organizationRepository,
+		accountHandler:         accountHandler,
+	}
+}
+
+type OrganizationResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateOrganizationRequest struct {
+	Name string `json:"name"`
+}
+
+// @Summary		Create Organization
+// @Description	Create an organization with the authenticated user as its owner
+// @Tags			organization
+// @Accept			json
+// @Produce		json
+// @Param			organization	body		CreateOrganizationRequest	true	"Organization"
+// @Success		200				{object}	OrganizationResponse
+// @Failure		400				{object}	map[string]string
+// @Failure		500				{object}	map[string]string
+// @Router			/api/v1/organizations [post]
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "CreateOrganization")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	var req CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+	if len(req.Name) > maxNameLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is too long"})
+		return
+	}
+
+	org, err := h.organizationRepository.CreateOrganization(ctx, &domain.Organization{Name: req.Name}, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to create organization: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.logActivity(ctx, accountID, domain.ActivityOrganizationCreated)
+
+	c.JSON(http.StatusOK, OrganizationResponse{
+		ID:        org.ID,
+		Name:      org.Name,
+		Role:      domain.OrganizationRoleOwner,
+		Active:    activeOrganizationID(c) == org.ID,
+		CreatedAt: org.CreatedAt,
+	})
+}
+
+type ListOrganizationsResponse struct {
+	Organizations []OrganizationResponse `json:"organizations"`
+}
+
+// @Summary		List Organizations
+// @Description	List the organizations the authenticated user is a member of, with the active one flagged
+// @Tags			organization
+// @Produce		json
+// @Success		200	{object}	ListOrganizationsResponse
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/organizations [get]
+func (h *OrganizationHandler) ListOrganizations(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "ListOrganizations")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	memberships, err := h.organizationRepository.ListAccountMemberships(ctx, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to list memberships: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	active := activeOrganizationID(c)
+	response := ListOrganizationsResponse{Organizations: []OrganizationResponse{}}
+	for _, membership := range memberships {
+		if membership.Organization == nil {
+			continue
+		}
+		response.Organizations = append(response.Organizations, OrganizationResponse{
+			ID:        membership.OrganizationID,
+			Name:      membership.Organization.Name,
+			Role:      membership.Role,
+			Active:    membership.OrganizationID == active,
+			CreatedAt: membership.Organization.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type SwitchOrganizationResponse struct {
+	Token string `json:"token"`
+}
+
+// @Summary		Switch Organization
+// @Description	Make an organization the active one of the session. The returned access token carries it, and refreshing the session keeps it.
+// @Tags			organization
+// @Produce		json
+// @Param			id	path		int	true	"Organization ID"
+// @Success		200	{object}	SwitchOrganizationResponse
+// @Failure		400	{object}	map[string]string
+// @Failure		404	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/organizations/{id}/switch [post]
+func (h *OrganizationHandler) SwitchOrganization(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "SwitchOrganization")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	organizationID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization id"})
+		return
+	}
+
+	// organizations the account is not in are reported as missing so their ids are not revealed
+	_, err = h.organizationRepository.GetMembership(ctx, uint(organizationID), accountID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "organization not found"})
+			return
+		}
+		h.logger.WithField("userId", accountID).Errorf("failed to get membership: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	acc, err := h.accountRepository.GetAccountByID(ctx, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to get account by id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	var sessionID uint
+	if claims, ok := utils.AuthClaims(c); ok {
+		sessionID = claims.SessionID
+	}
+
+	// tokens issued before sessions existed have none to remember the organization
+	if sessionID != 0 {
+		err = h.accountRepository.SetSessionOrganization(ctx, sessionID, uint(organizationID))
+		if err != nil {
+			h.logger.WithField("userId", accountID).Errorf("failed to set session organization: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+	}
+
+	token, err := h.accountService.GenerateAuthToken(ctx, acc, sessionID, uint(organizationID))
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to generate token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+		return
+	}
+
+	h.logActivity(ctx, accountID, domain.ActivityOrganizationSwitched)
+
+	c.JSON(http.StatusOK, SwitchOrganizationResponse{Token: token})
+}
+
+type MemberResponse struct {
+	AccountID uint      `json:"account_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	JoinedAt  time.Time `json:"joined_at"`
+}
+
+type ListMembersResponse struct {
+	Members []MemberResponse `json:"members"`
+}
+
+// @Summary		List Members
+// @Description	List the members of the active organization
+// @Tags			organization
+// @Produce		json
+// @Success		200	{object}	ListMembersResponse
+// @Failure		403	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/organization/members [get]
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "ListMembers")
+	defer span.End()
+
+	organizationID := c.GetUint(utils.OrganizationIdContextKey)
+	if organizationID == 0 {
+		h.logger.Errorf("organizationID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	memberships, err := h.organizationRepository.ListMembers(ctx, organizationID)
+	if err != nil {
+		h.logger.WithField("organizationId", organizationID).Errorf("failed to list members: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	response := ListMembersResponse{Members: []MemberResponse{}}
+	for _, membership := range memberships {
+		member := MemberResponse{
+			AccountID: membership.AccountID,
+			Role:      membership.Role,
+			JoinedAt:  membership.CreatedAt,
+		}
+		if membership.Account != nil {
+			member.Email = membership.Account.Email
+		}
+		response.Members = append(response.Members, member)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary		Remove Member
+// @Description	Remove a member from the active organization. Owners and admins can remove members, admins cannot remove owners, and every member can remove themselves. The last owner cannot be removed.
+// @Tags			organization
+// @Produce		json
+// @Param			accountId	path		int	true	"Account ID"
+// @Success		200			{object}	map[string]string
+// @Failure		400			{object}	map[string]string
+// @Failure		403			{object}	map[string]string
+// @Failure		404			{object}	map[string]string
+// @Failure		500			{object}	map[string]string
+// @Router			/api/v1/organization/members/{accountId} [delete]
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "RemoveMember")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	organizationID := c.GetUint(utils.OrganizationIdContextKey)
+	if accountID == 0 || organizationID == 0 {
+		h.logger.Errorf("accountID or organizationID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	memberID, err := strconv.ParseUint(c.Param("accountId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	member, err := h.organizationRepository.GetMembership(ctx, organizationID, uint(memberID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "member not found"})
+			return
+		}
+		h.logger.WithField("userId", accountID).Errorf("failed to get membership: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if member.AccountID != accountID {
+		requester := &domain.Membership{Role: c.GetString(utils.OrganizationRoleContextKey)}
+		if !requester.CanManageMembers() ||
+			(member.Role == domain.OrganizationRoleOwner && requester.Role != domain.OrganizationRoleOwner) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			return
+		}
+	}
+
+	err = h.organizationRepository.RemoveMember(ctx, organizationID, member.AccountID)
+	if err != nil {
+		if errors.Is(err, domain.ErrLastOrganizationOwner) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "cannot remove the last owner"})
+			return
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "member not found"})
+			return
+		}
+		h.logger.WithField("userId", accountID).Errorf("failed to remove member: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.logger.WithField("userId", member.AccountID).
+		WithField("organizationId", organizationID).
+		WithField("removedBy", accountID).
+		Infof("member removed")
+	h.logActivity(ctx, member.AccountID, domain.ActivityOrganizationMemberRemoved)
+
+	c.JSON(http.StatusOK, gin.H{"message": "member removed"})
+}
+
+func (h *OrganizationHandler) logActivity(ctx context.Context, accountID uint, activity string) {
+	err := h.accountRepository.LogAccountActivity(ctx, accountID, activity)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to log activity: %v", err)
+	}
+}
+
+// activeOrganizationID is the organization carried by the request's access token
+func activeOrganizationID(c *gin.Context) uint {
+	if claims, ok := utils.AuthClaims(c); ok {
+		return claims.OrganizationID
+	}
+	return 0
+}
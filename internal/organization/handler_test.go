@@ -0,0 +1,263 @@
+package organization_test
+
+import (
+	"context"
+	"encoding/json"
+	"go_starter_api/internal/organization"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm"
+)
+
+// member describes the account making the request and its place in the active organization
+type member struct {
+	accountID      uint
+	sessionID      uint
+	organizationID uint
+	role           string
+}
+
+func serve(caller member, method string, route string, target string, body string, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Handle(method, route, func(c *gin.Context) {
+		c.Set(utils.AccountIdContextKey, caller.accountID)
+		c.Set(utils.AuthClaimsContextKey, &domain.AuthClaims{
+			AccountID:      caller.accountID,
+			SessionID:      caller.sessionID,
+			OrganizationID: caller.organizationID,
+		})
+		if caller.role != "" {
+			c.Set(utils.OrganizationIdContextKey, caller.organizationID)
+			c.Set(utils.OrganizationRoleContextKey, caller.role)
+		}
+	}, handler)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestOrganizationHandler_CreateOrganization(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	t.Run("should create the organization with the account as owner", func(t *testing.T) {
+		accountRepository := domain.NewMockAccountRepository(t)
+		organizationRepository := domain.NewMockOrganizationRepository(t)
+
+		organizationRepository.On("CreateOrganization", anyContext, mock.MatchedBy(func(org *domain.Organization) bool {
+			return org.Name == "Acme"
+		}), uint(1)).Return(&domain.Organization{ID: 3, Name: "Acme"}, nil)
+		accountRepository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityOrganizationCreated).Return(nil)
+
+		handler := organization.NewOrganizationHandler(logrus.New(), domain.NewMockAccountService(t), accountRepository, organizationRepository, nil)
+
+		w := serve(member{accountID: 1}, "POST", "/organizations", "/organizations", `{"name":"  Acme "}`, handler.CreateOrganization)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response organization.OrganizationResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, uint(3), response.ID)
+		assert.Equal(t, domain.OrganizationRoleOwner, response.Role)
+		assert.False(t, response.Active)
+	})
+
+	t.Run("should require a name", func(t *testing.T) {
+		handler := organization.NewOrganizationHandler(logrus.New(), domain.NewMockAccountService(t), domain.NewMockAccountRepository(t), domain.NewMockOrganizationRepository(t), nil)
+
+		w := serve(member{accountID: 1}, "POST", "/organizations", "/organizations", `{"name":" "}`, handler.CreateOrganization)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestOrganizationHandler_ListOrganizations(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	organizationRepository := domain.NewMockOrganizationRepository(t)
+	organizationRepository.On("ListAccountMemberships", anyContext, uint(1)).Return([]domain.Membership{
+		{OrganizationID: 3, Role: domain.OrganizationRoleOwner, Organization: &domain.Organization{ID: 3, Name: "Acme"}},
+		{OrganizationID: 4, Role: domain.OrganizationRoleMember, Organization: &domain.Organization{ID: 4, Name: "Globex"}},
+	}, nil)
+
+	handler := organization.NewOrganizationHandler(logrus.New(), domain.NewMockAccountService(t), domain.NewMockAccountRepository(t), organizationRepository, nil)
+
+	w := serve(member{accountID: 1, organizationID: 4}, "GET", "/organizations", "/organizations", "", handler.ListOrganizations)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response organization.ListOrganizationsResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response.Organizations, 2)
+	assert.False(t, response.Organizations[0].Active)
+	assert.True(t, response.Organizations[1].Active)
+	assert.Equal(t, domain.OrganizationRoleMember, response.Organizations[1].Role)
+}
+
+func TestOrganizationHandler_SwitchOrganization(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	t.Run("should move the session to the organization and issue a token for it", func(t *testing.T) {
+		service := domain.NewMockAccountService(t)
+		accountRepository := domain.NewMockAccountRepository(t)
+		organizationRepository := domain.NewMockOrganizationRepository(t)
+
+		acc := &domain.Account{ID: 1}
+		organizationRepository.On("GetMembership", anyContext, uint(4), uint(1)).Return(&domain.Membership{OrganizationID: 4, AccountID: 1}, nil)
+		accountRepository.On("GetAccountByID", anyContext, uint(1)).Return(acc, nil)
+		accountRepository.On("SetSessionOrganization", anyContext, uint(5), uint(4)).Return(nil)
+		service.On("GenerateAuthToken", anyContext, acc, uint(5), uint(4)).Return("org_token", nil)
+		accountRepository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityOrganizationSwitched).Return(nil)
+
+		handler := organization.NewOrganizationHandler(logrus.New(), service, accountRepository, organizationRepository, nil)
+
+		w := serve(member{accountID: 1, sessionID: 5, organizationID: 3}, "POST", "/organizations/:id/switch", "/organizations/4/switch", "", handler.SwitchOrganization)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"token":"org_token"}`, w.Body.String())
+	})
+
+	t.Run("should not switch to an organization the account is not in", func(t *testing.T) {
+		organizationRepository := domain.NewMockOrganizationRepository(t)
+		organizationRepository.On("GetMembership", anyContext, uint(4), uint(1)).Return(nil, gorm.ErrRecordNotFound)
+
+		handler := organization.NewOrganizationHandler(logrus.New(), domain.NewMockAccountService(t), domain.NewMockAccountRepository(t), organizationRepository, nil)
+
+		w := serve(member{accountID: 1, sessionID: 5}, "POST", "/organizations/:id/switch", "/organizations/4/switch", "", handler.SwitchOrganization)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestOrganizationHandler_ListMembers(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	organizationRepository := domain.NewMockOrganizationRepository(t)
+	organizationRepository.On("ListMembers", anyContext, uint(3)).Return([]domain.Membership{
+		{AccountID: 1, Role: domain.OrganizationRoleOwner, Account: &domain.Account{ID: 1, Email: "owner@example.com"}},
+		{AccountID: 2, Role: domain.OrganizationRoleMember, Account: &domain.Account{ID: 2, Email: "member@example.com"}},
+	}, nil)
+
+	handler := organization.NewOrganizationHandler(logrus.New(), domain.NewMockAccountService(t), domain.NewMockAccountRepository(t), organizationRepository, nil)
+
+	w := serve(member{accountID: 1, organizationID: 3, role: domain.OrganizationRoleOwner}, "GET", "/organization/members", "/organization/members", "", handler.ListMembers)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response organization.ListMembersResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response.Members, 2)
+	assert.Equal(t, "member@example.com", response.Members[1].Email)
+}
+
+func TestOrganizationHandler_RemoveMember(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	remove := func(caller member, target string, handler *organization.OrganizationHandler) *httptest.ResponseRecorder {
+		return serve(caller, "DELETE", "/organization/members/:accountId", "/organization/members/"+target, "", handler.RemoveMember)
+	}
+
+	t.Run("should let an admin remove a member", func(t *testing.T) {
+		accountRepository := domain.NewMockAccountRepository(t)
+		organizationRepository := domain.NewMockOrganizationRepository(t)
+
+		organizationRepository.On("GetMembership", anyContext, uint(3), uint(2)).Return(&domain.Membership{OrganizationID: 3, AccountID: 2, Role: domain.OrganizationRoleMember}, nil)
+		organizationRepository.On("RemoveMember", anyContext, uint(3), uint(2)).Return(nil)
+		accountRepository.On("LogAccountActivity", anyContext, uint(2), domain.ActivityOrganizationMemberRemoved).Return(nil)
+
+		handler := organization.NewOrganizationHandler(logrus.New(), domain.NewMockAccountService(t), accountRepository, organizationRepository, nil)
+
+		w := remove(member{accountID: 1, organizationID: 3, role: domain.OrganizationRoleAdmin}, "2", handler)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should let a member leave", func(t *testing.T) {
+		accountRepository := domain.NewMockAccountRepository(t)
+		organizationRepository := domain.NewMockOrganizationRepository(t)
+
+		organizationRepository.On("GetMembership", anyContext, uint(3), uint(2)).Return(&domain.Membership{OrganizationID: 3, AccountID: 2, Role: domain.OrganizationRoleMember}, nil)
+		organizationRepository.On("RemoveMember", anyContext, uint(3), uint(2)).Return(nil)
+		accountRepository.On("LogAccountActivity", anyContext, uint(2), domain.ActivityOrganizationMemberRemoved).Return(nil)
+
+		handler := organization.NewOrganizationHandler(logrus.New(), domain.NewMockAccountService(t), accountRepository, organizationRepository, nil)
+
+		w := remove(member{accountID: 2, organizationID: 3, role: domain.OrganizationRoleMember}, "2", handler)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should refuse a member removing someone else", func(t *testing.T) {
+		organizationRepository := domain.NewMockOrganizationRepository(t)
+		organizationRepository.On("GetMembership", anyContext, uint(3), uint(1)).Return(&domain.Membership{OrganizationID: 3, AccountID: 1, Role: domain.OrganizationRoleMember}, nil)
+
+		handler := organization.NewOrganizationHandler(logrus.New(), domain.NewMockAccountService(t), domain.NewMockAccountRepository(t), organizationRepository, nil)
+
+		w := remove(member{accountID: 2, organizationID: 3, role: domain.OrganizationRoleMember}, "1", handler)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("should refuse an admin removing an owner", func(t *testing.T) {
+		organizationRepository := domain.NewMockOrganizationRepository(t)
+		organizationRepository.On("GetMembership", anyContext, uint(3), uint(1)).Return(&domain.Membership{OrganizationID: 3, AccountID: 1, Role: domain.OrganizationRoleOwner}, nil)
+
+		handler := organization.NewOrganizationHandler(logrus.New(), domain.NewMockAccountService(t), domain.NewMockAccountRepository(t), organizationRepository, nil)
+
+		w := remove(member{accountID: 2, organizationID: 3, role: domain.OrganizationRoleAdmin}, "1", handler)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("should keep the last owner", func(t *testing.T) {
+		organizationRepository := domain.NewMockOrganizationRepository(t)
+		organizationRepository.On("GetMembership", anyContext, uint(3), uint(1)).Return(&domain.Membership{OrganizationID: 3, AccountID: 1, Role: domain.OrganizationRoleOwner}, nil)
+		organizationRepository.On("RemoveMember", anyContext, uint(3), uint(1)).Return(domain.ErrLastOrganizationOwner)
+
+		handler := organization.NewOrganizationHandler(logrus.New(), domain.NewMockAccountService(t), domain.NewMockAccountRepository(t), organizationRepository, nil)
+
+		w := remove(member{accountID: 1, organizationID: 3, role: domain.OrganizationRoleOwner}, "1", handler)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should return 404 for an account outside the organization", func(t *testing.T) {
+		organizationRepository := domain.NewMockOrganizationRepository(t)
+		organizationRepository.On("GetMembership", anyContext, uint(3), uint(9)).Return(nil, gorm.ErrRecordNotFound)
+
+		handler := organization.NewOrganizationHandler(logrus.New(), domain.NewMockAccountService(t), domain.NewMockAccountRepository(t), organizationRepository, nil)
+
+		w := remove(member{accountID: 1, organizationID: 3, role: domain.OrganizationRoleOwner}, "9", handler)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,435 @@
+package organization
+
+import (
+	"context"
+	"errors"
+	"go_starter_api/internal/account"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+const defaultInvitationTTL = 7 * 24 * time.Hour
+
+func invitationTTL() time.Duration {
+	ttl := viper.GetDuration("INVITATION_TTL")
+	if ttl <= 0 {
+		return defaultInvitationTTL
+	}
+	return ttl
+}
+
+type InvitationResponse struct {
+	ID        uint      `json:"id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	InviterID uint      `json:"inviter_id"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func toInvitationResponse(invitation *domain.Invitation, now time.Time) InvitationResponse {
+	return InvitationResponse{
+		ID:        invitation.ID,
+		Email:     invitation.Email,
+		Role:      invitation.Role,
+		InviterID: invitation.InviterID,
+		Status:    invitation.StatusAt(now),
+		ExpiresAt: invitation.ExpiresAt,
+		CreatedAt: invitation.CreatedAt,
+	}
+}
+
+type SendInvitationRequest struct {
+	Email string `json:"email"`
+	// member when empty
+	Role string `json:"role"`
+}
+
+// @Summary		Send Invitation
+// @Description	Invite an email to the active organization, the link in the email accepts the invitation. A new invitation replaces the pending one of the same email. Owners and admins can invite, only owners can invite owners.
+// @Tags			organization
+// @Accept			json
+// @Produce		json
+// @Param			invitation	body		SendInvitationRequest	true	"Invitation"
+// @Success		200			{object}	InvitationResponse
+// @Failure		400			{object}	map[string]string
+// @Failure		403			{object}	map[string]string
+// @Failure		500			{object}	map[string]string
+// @Router			/api/v1/organization/invitations [post]
+func (h *OrganizationHandler) SendInvitation(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "SendInvitation")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	organizationID := c.GetUint(utils.OrganizationIdContextKey)
+	if accountID == 0 || organizationID == 0 {
+		h.logger.Errorf("accountID or organizationID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	var req SendInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "email is required"})
+		return
+	}
+
+	if req.Role == "" {
+		req.Role = domain.OrganizationRoleMember
+	}
+	switch req.Role {
+	case domain.OrganizationRoleMember, domain.OrganizationRoleAdmin, domain.OrganizationRoleOwner:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid role"})
+		return
+	}
+
+	inviter := &domain.Membership{Role: c.GetString(utils.OrganizationRoleContextKey)}
+	if !inviter.CanManageMembers() ||
+		(req.Role == domain.OrganizationRoleOwner && inviter.Role != domain.OrganizationRoleOwner) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return
+	}
+
+	existing, err := h.accountRepository.GetAccountByEmail(ctx, req.Email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		h.logger.WithField("userId", accountID).Errorf("failed to get account by email: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	if err == nil {
+		_, err = h.organizationRepository.GetMembership(ctx, organizationID, existing.ID)
+		if err == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "already a member"})
+			return
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			h.logger.WithField("userId", accountID).Errorf("failed to get membership: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+	}
+
+	org, err := h.organizationRepository.GetOrganizationByID(ctx, organizationID)
+	if err != nil {
+		h.logger.WithField("organizationId", organizationID).Errorf("failed to get organization: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// the request context carries the organization, the invitation is created in it
+	invitation, err := h.organizationRepository.CreateInvitation(ctx, &domain.Invitation{
+		Email:     req.Email,
+		Role:      req.Role,
+		InviterID: accountID,
+		Status:    domain.InvitationStatusPending,
+		ExpiresAt: time.Now().Add(invitationTTL()),
+	})
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to create invitation: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	token, err := h.accountService.GenerateInvitationToken(ctx, invitation)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to generate invitation token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+		return
+	}
+
+	// sending again replaces the invitation, so a failed email is reported instead of retried
+	err = h.accountService.SendInvitationEmail(ctx, invitation.Email, org.Name, token)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to send invitation email: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send invitation"})
+		return
+	}
+
+	h.logActivity(ctx, accountID, domain.ActivityInvitationSent)
+
+	c.JSON(http.StatusOK, toInvitationResponse(invitation, time.Now()))
+}
+
+type ListInvitationsResponse struct {
+	Invitations []InvitationResponse `json:"invitations"`
+}
+
+// @Summary		List Invitations
+// @Description	List the pending invitations of the active organization. Owners and admins only.
+// @Tags			organization
+// @Produce		json
+// @Success		200	{object}	ListInvitationsResponse
+// @Failure		403	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/organization/invitations [get]
+func (h *OrganizationHandler) ListInvitations(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "ListInvitations")
+	defer span.End()
+
+	requester := &domain.Membership{Role: c.GetString(utils.OrganizationRoleContextKey)}
+	if !requester.CanManageMembers() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return
+	}
+
+	invitations, err := h.organizationRepository.ListPendingInvitations(ctx)
+	if err != nil {
+		h.logger.WithField("organizationId", c.GetUint(utils.OrganizationIdContextKey)).Errorf("failed to list invitations: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	now := time.Now()
+	response := ListInvitationsResponse{Invitations: []InvitationResponse{}}
+	for i := range invitations {
+		response.Invitations = append(response.Invitations, toInvitationResponse(&invitations[i], now))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary		Revoke Invitation
+// @Description	Revoke a pending invitation of the active organization, its link stops working. Owners and admins only.
+// @Tags			organization
+// @Produce		json
+// @Param			id	path		int	true	"Invitation ID"
+// @Success		200	{object}	map[string]string
+// @Failure		400	{object}	map[string]string
+// @Failure		403	{object}	map[string]string
+// @Failure		404	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/organization/invitations/{id} [delete]
+func (h *OrganizationHandler) RevokeInvitation(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "RevokeInvitation")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	requester := &domain.Membership{Role: c.GetString(utils.OrganizationRoleContextKey)}
+	if !requester.CanManageMembers() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+		return
+	}
+
+	invitationID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invitation id"})
+		return
+	}
+
+	// scoped to the active organization, invitations of other organizations are not found
+	err = h.organizationRepository.RevokeInvitation(ctx, uint(invitationID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "invitation not found"})
+			return
+		}
+		h.logger.WithField("userId", accountID).Errorf("failed to revoke invitation: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	h.logActivity(ctx, accountID, domain.ActivityInvitationRevoked)
+
+	c.JSON(http.StatusOK, gin.H{"message": "invitation revoked"})
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token"`
+	// required when no account exists for the invited email, it is registered with this password
+	Password string `json:"password"`
+}
+
+type AcceptInvitationResponse struct {
+	OrganizationID uint   `json:"organization_id"`
+	Role           string `json:"role"`
+}
+
+// @Summary		Accept Invitation
+// @Description	Accept an invitation with the token from its email. An existing account with the invited email joins the organization and logs in as usual.
+// @Description	Otherwise a password is required, the account is registered with the email verified and the returned tokens start in the organization.
+// @Tags			organization
+// @Accept			json
+// @Produce		json
+// @Param			invitation	body		AcceptInvitationRequest	true	"Invitation"
+// @Success		200			{object}	AcceptInvitationResponse
+// @Failure		400			{object}	map[string]string
+// @Failure		500			{object}	map[string]string
+// @Router			/api/v1/invitations/accept [post]
+func (h *OrganizationHandler) AcceptInvitation(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "AcceptInvitation")
+	defer span.End()
+
+	var req AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invitation, ok := h.pendingInvitation(c, req.Token)
+	if !ok {
+		return
+	}
+
+	acc, err := h.accountRepository.GetAccountByEmail(ctx, invitation.Email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		h.logger.Errorf("failed to get account by email: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	if err == nil {
+		if !h.acceptInvitation(c, invitation, acc.ID) {
+			return
+		}
+		c.JSON(http.StatusOK, AcceptInvitationResponse{
+			OrganizationID: invitation.OrganizationID,
+			Role:           invitation.Role,
+		})
+		return
+	}
+
+	if req.Password == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "password is required"})
+		return
+	}
+
+	// the membership is created before the tokens, so the new session starts in the organization
+	h.accountHandler.Register(ctx, c, account.RegisterAccountRequest{
+		Email:    invitation.Email,
+		Password: req.Password,
+	}, account.RegisterOptions{
+		EmailVerified: true,
+		AfterCreate: func(ctx context.Context, acc *domain.Account) error {
+			err := h.organizationRepository.AcceptInvitation(ctx, invitation, acc.ID)
+			if err != nil {
+				return err
+			}
+			h.logActivity(ctx, acc.ID, domain.ActivityInvitationAccepted)
+			return nil
+		},
+	})
+}
+
+type DeclineInvitationRequest struct {
+	Token string `json:"token"`
+}
+
+// @Summary		Decline Invitation
+// @Description	Decline an invitation with the token from its email
+// @Tags			organization
+// @Accept			json
+// @Produce		json
+// @Param			invitation	body		DeclineInvitationRequest	true	"Invitation"
+// @Success		200			{object}	map[string]string
+// @Failure		400			{object}	map[string]string
+// @Failure		500			{object}	map[string]string
+// @Router			/api/v1/invitations/decline [post]
+func (h *OrganizationHandler) DeclineInvitation(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "DeclineInvitation")
+	defer span.End()
+
+	var req DeclineInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invitation, ok := h.pendingInvitation(c, req.Token)
+	if !ok {
+		return
+	}
+
+	err := h.organizationRepository.DeclineInvitation(ctx, invitation.ID)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvitationNotPending) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired invitation"})
+			return
+		}
+		h.logger.Errorf("failed to decline invitation: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "invitation declined"})
+}
+
+// pendingInvitation loads the invitation of the token, writing the error response when it
+// cannot be answered
+func (h *OrganizationHandler) pendingInvitation(c *gin.Context, token string) (*domain.Invitation, bool) {
+	ctx := c.Request.Context()
+
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return nil, false
+	}
+
+	invitationID, email, err := h.accountService.ValidateInvitationToken(ctx, token)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired invitation"})
+		return nil, false
+	}
+
+	invitation, err := h.organizationRepository.GetInvitationByID(ctx, invitationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired invitation"})
+			return nil, false
+		}
+		h.logger.Errorf("failed to get invitation: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return nil, false
+	}
+
+	if invitation.Email != email || invitation.StatusAt(time.Now()) != domain.InvitationStatusPending {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired invitation"})
+		return nil, false
+	}
+
+	return invitation, true
+}
+
+// acceptInvitation adds the account to the organization, writing the error response when it fails
+func (h *OrganizationHandler) acceptInvitation(c *gin.Context, invitation *domain.Invitation, accountID uint) bool {
+	ctx := c.Request.Context()
+
+	err := h.organizationRepository.AcceptInvitation(ctx, invitation, accountID)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvitationNotPending) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired invitation"})
+			return false
+		}
+		h.logger.WithField("userId", accountID).Errorf("failed to accept invitation: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return false
+	}
+
+	h.logActivity(ctx, accountID, domain.ActivityInvitationAccepted)
+	return true
+}
@@ -0,0 +1,272 @@
+package organization_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go_starter_api/internal/account"
+	"go_starter_api/internal/organization"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/tenant"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+	"gorm.io/gorm"
+)
+
+func TestOrganizationHandler_SendInvitation(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	owner := member{accountID: 1, organizationID: 3, role: domain.OrganizationRoleOwner}
+
+	t.Run("should create the invitation and email its link", func(t *testing.T) {
+		service := domain.NewMockAccountService(t)
+		accountRepository := domain.NewMockAccountRepository(t)
+		organizationRepository := domain.NewMockOrganizationRepository(t)
+
+		invitation := &domain.Invitation{ID: 9, Email: "new@example.com", Role: domain.OrganizationRoleAdmin, Status: domain.InvitationStatusPending, ExpiresAt: time.Now().Add(time.Hour)}
+		accountRepository.On("GetAccountByEmail", anyContext, "new@example.com").Return(nil, gorm.ErrRecordNotFound)
+		organizationRepository.On("GetOrganizationByID", anyContext, uint(3)).Return(&domain.Organization{ID: 3, Name: "Acme"}, nil)
+		organizationRepository.On("CreateInvitation", anyContext, mock.MatchedBy(func(i *domain.Invitation) bool {
+			return i.Email == "new@example.com" && i.Role == domain.OrganizationRoleAdmin && i.InviterID == 1 &&
+				i.Status == domain.InvitationStatusPending && i.ExpiresAt.After(time.Now())
+		})).Return(invitation, nil)
+		service.On("GenerateInvitationToken", anyContext, invitation).Return("invitation_token", nil)
+		service.On("SendInvitationEmail", anyContext, "new@example.com", "Acme", "invitation_token").Return(nil)
+		accountRepository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityInvitationSent).Return(nil)
+
+		handler := organization.NewOrganizationHandler(logrus.New(), service, accountRepository, organizationRepository, nil)
+
+		w := serve(owner, "POST", "/organization/invitations", "/organization/invitations", `{"email":"new@example.com","role":"admin"}`, handler.SendInvitation)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response organization.InvitationResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, uint(9), response.ID)
+		assert.Equal(t, domain.InvitationStatusPending, response.Status)
+	})
+
+	t.Run("should refuse an email that is already a member", func(t *testing.T) {
+		accountRepository := domain.NewMockAccountRepository(t)
+		organizationRepository := domain.NewMockOrganizationRepository(t)
+
+		accountRepository.On("GetAccountByEmail", anyContext, "member@example.com").Return(&domain.Account{ID: 2}, nil)
+		organizationRepository.On("GetMembership", anyContext, uint(3), uint(2)).Return(&domain.Membership{OrganizationID: 3, AccountID: 2}, nil)
+
+		handler := organization.NewOrganizationHandler(logrus.New(), domain.NewMockAccountService(t), accountRepository, organizationRepository, nil)
+
+		w := serve(owner, "POST", "/organization/invitations", "/organization/invitations", `{"email":"member@example.com"}`, handler.SendInvitation)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should refuse members and admins inviting owners", func(t *testing.T) {
+		handler := organization.NewOrganizationHandler(logrus.New(), domain.NewMockAccountService(t), domain.NewMockAccountRepository(t), domain.NewMockOrganizationRepository(t), nil)
+
+		w := serve(member{accountID: 2, organizationID: 3, role: domain.OrganizationRoleMember}, "POST", "/organization/invitations", "/organization/invitations", `{"email":"new@example.com"}`, handler.SendInvitation)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = serve(member{accountID: 2, organizationID: 3, role: domain.OrganizationRoleAdmin}, "POST", "/organization/invitations", "/organization/invitations", `{"email":"new@example.com","role":"owner"}`, handler.SendInvitation)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestOrganizationHandler_ListAndRevokeInvitations(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	admin := member{accountID: 1, organizationID: 3, role: domain.OrganizationRoleAdmin}
+
+	t.Run("should list the pending invitations", func(t *testing.T) {
+		organizationRepository := domain.NewMockOrganizationRepository(t)
+		organizationRepository.On("ListPendingInvitations", anyContext).Return([]domain.Invitation{
+			{ID: 9, Email: "new@example.com", Status: domain.InvitationStatusPending, ExpiresAt: time.Now().Add(time.Hour)},
+		}, nil)
+
+		handler := organization.NewOrganizationHandler(logrus.New(), domain.NewMockAccountService(t), domain.NewMockAccountRepository(t), organizationRepository, nil)
+
+		w := serve(admin, "GET", "/organization/invitations", "/organization/invitations", "", handler.ListInvitations)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response organization.ListInvitationsResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response.Invitations, 1)
+	})
+
+	t.Run("should revoke a pending invitation", func(t *testing.T) {
+		accountRepository := domain.NewMockAccountRepository(t)
+		organizationRepository := domain.NewMockOrganizationRepository(t)
+
+		organizationRepository.On("RevokeInvitation", anyContext, uint(9)).Return(nil)
+		accountRepository.On("LogAccountActivity", anyContext, uint(1), domain.ActivityInvitationRevoked).Return(nil)
+
+		handler := organization.NewOrganizationHandler(logrus.New(), domain.NewMockAccountService(t), accountRepository, organizationRepository, nil)
+
+		w := serve(admin, "DELETE", "/organization/invitations/:id", "/organization/invitations/9", "", handler.RevokeInvitation)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should return 404 for an invitation outside the organization", func(t *testing.T) {
+		organizationRepository := domain.NewMockOrganizationRepository(t)
+		organizationRepository.On("RevokeInvitation", anyContext, uint(9)).Return(gorm.ErrRecordNotFound)
+
+		handler := organization.NewOrganizationHandler(logrus.New(), domain.NewMockAccountService(t), domain.NewMockAccountRepository(t), organizationRepository, nil)
+
+		w := serve(admin, "DELETE", "/organization/invitations/:id", "/organization/invitations/9", "", handler.RevokeInvitation)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestOrganizationHandler_AcceptInvitation(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	pending := func() *domain.Invitation {
+		return &domain.Invitation{
+			ID:        9,
+			Model:     tenant.Model{OrganizationID: 3},
+			Email:     "new@example.com",
+			Role:      domain.OrganizationRoleMember,
+			Status:    domain.InvitationStatusPending,
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+	}
+
+	t.Run("should add an existing account to the organization", func(t *testing.T) {
+		service := domain.NewMockAccountService(t)
+		accountRepository := domain.NewMockAccountRepository(t)
+		organizationRepository := domain.NewMockOrganizationRepository(t)
+
+		invitation := pending()
+		service.On("ValidateInvitationToken", anyContext, "invitation_token").Return(uint(9), "new@example.com", nil)
+		organizationRepository.On("GetInvitationByID", anyContext, uint(9)).Return(invitation, nil)
+		accountRepository.On("GetAccountByEmail", anyContext, "new@example.com").Return(&domain.Account{ID: 2, Email: "new@example.com"}, nil)
+		organizationRepository.On("AcceptInvitation", anyContext, invitation, uint(2)).Return(nil)
+		accountRepository.On("LogAccountActivity", anyContext, uint(2), domain.ActivityInvitationAccepted).Return(nil)
+
+		handler := organization.NewOrganizationHandler(logrus.New(), service, accountRepository, organizationRepository, nil)
+
+		w := serve(member{}, "POST", "/invitations/accept", "/invitations/accept", `{"token":"invitation_token"}`, handler.AcceptInvitation)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"organization_id":3,"role":"member"}`, w.Body.String())
+	})
+
+	t.Run("should register a new account in the organization", func(t *testing.T) {
+		service := domain.NewMockAccountService(t)
+		accountRepository := domain.NewMockAccountRepository(t)
+		organizationRepository := domain.NewMockOrganizationRepository(t)
+
+		invitation := pending()
+		acc := &domain.Account{ID: 2, Email: "new@example.com", EmailVerified: true}
+		service.On("ValidateInvitationToken", anyContext, "invitation_token").Return(uint(9), "new@example.com", nil)
+		organizationRepository.On("GetInvitationByID", anyContext, uint(9)).Return(invitation, nil)
+		accountRepository.On("GetAccountByEmail", anyContext, "new@example.com").Return(nil, gorm.ErrRecordNotFound)
+		service.On("HashPassword", anyContext, "password").Return("hashed_password", nil)
+		// the invitation proves the email, no verification email is sent
+		accountRepository.On("CreateAccount", anyContext, mock.MatchedBy(func(a *domain.Account) bool {
+			return a.Email == "new@example.com" && a.EmailVerified && a.VerifiedAt != nil
+		})).Return(acc, nil)
+		accountRepository.On("LogAccountActivity", anyContext, uint(2), domain.ActivityRegister).Return(nil)
+		organizationRepository.On("AcceptInvitation", anyContext, invitation, uint(2)).Return(nil)
+		accountRepository.On("LogAccountActivity", anyContext, uint(2), domain.ActivityInvitationAccepted).Return(nil)
+		service.On("GenerateRefreshToken", anyContext, acc, "").Return("refresh_token", &domain.RefreshToken{AccountID: 2}, nil)
+		accountRepository.On("CreateSession", anyContext, mock.AnythingOfType("*domain.Session")).Return(&domain.Session{ID: 5, AccountID: 2, OrganizationID: 3}, nil)
+		service.On("GenerateAuthToken", anyContext, acc, uint(5), uint(3)).Return("auth_token", nil)
+		accountRepository.On("CreateRefreshToken", anyContext, mock.AnythingOfType("*domain.RefreshToken")).Return(&domain.RefreshToken{ID: 1}, nil)
+
+		accountHandler := account.NewAccountHandler(logrus.New(), service, accountRepository, account.NewInMemoryTokenRevocationRepository())
+		handler := organization.NewOrganizationHandler(logrus.New(), service, accountRepository, organizationRepository, accountHandler)
+
+		w := serve(member{}, "POST", "/invitations/accept", "/invitations/accept", `{"token":"invitation_token","password":"password"}`, handler.AcceptInvitation)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response account.RegisterAccountResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "auth_token", response.Token)
+		assert.Equal(t, "refresh_token", response.RefreshToken)
+	})
+
+	t.Run("should require a password to register", func(t *testing.T) {
+		service := domain.NewMockAccountService(t)
+		accountRepository := domain.NewMockAccountRepository(t)
+		organizationRepository := domain.NewMockOrganizationRepository(t)
+
+		service.On("ValidateInvitationToken", anyContext, "invitation_token").Return(uint(9), "new@example.com", nil)
+		organizationRepository.On("GetInvitationByID", anyContext, uint(9)).Return(pending(), nil)
+		accountRepository.On("GetAccountByEmail", anyContext, "new@example.com").Return(nil, gorm.ErrRecordNotFound)
+
+		handler := organization.NewOrganizationHandler(logrus.New(), service, accountRepository, organizationRepository, nil)
+
+		w := serve(member{}, "POST", "/invitations/accept", "/invitations/accept", `{"token":"invitation_token"}`, handler.AcceptInvitation)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should refuse an invitation that is no longer pending", func(t *testing.T) {
+		service := domain.NewMockAccountService(t)
+		organizationRepository := domain.NewMockOrganizationRepository(t)
+
+		revoked := pending()
+		revoked.Status = domain.InvitationStatusRevoked
+		service.On("ValidateInvitationToken", anyContext, "invitation_token").Return(uint(9), "new@example.com", nil)
+		organizationRepository.On("GetInvitationByID", anyContext, uint(9)).Return(revoked, nil)
+
+		handler := organization.NewOrganizationHandler(logrus.New(), service, domain.NewMockAccountRepository(t), organizationRepository, nil)
+
+		w := serve(member{}, "POST", "/invitations/accept", "/invitations/accept", `{"token":"invitation_token"}`, handler.AcceptInvitation)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should refuse an invalid token", func(t *testing.T) {
+		service := domain.NewMockAccountService(t)
+		service.On("ValidateInvitationToken", anyContext, "invalid").Return(uint(0), "", errors.New("invalid token"))
+
+		handler := organization.NewOrganizationHandler(logrus.New(), service, domain.NewMockAccountRepository(t), domain.NewMockOrganizationRepository(t), nil)
+
+		w := serve(member{}, "POST", "/invitations/accept", "/invitations/accept", `{"token":"invalid"}`, handler.AcceptInvitation)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestOrganizationHandler_DeclineInvitation(t *testing.T) {
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	service := domain.NewMockAccountService(t)
+	organizationRepository := domain.NewMockOrganizationRepository(t)
+
+	service.On("ValidateInvitationToken", anyContext, "invitation_token").Return(uint(9), "new@example.com", nil)
+	organizationRepository.On("GetInvitationByID", anyContext, uint(9)).Return(&domain.Invitation{
+		ID: 9, Email: "new@example.com", Status: domain.InvitationStatusPending, ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	organizationRepository.On("DeclineInvitation", anyContext, uint(9)).Return(nil)
+
+	handler := organization.NewOrganizationHandler(logrus.New(), service, domain.NewMockAccountRepository(t), organizationRepository, nil)
+
+	w := serve(member{}, "POST", "/invitations/decline", "/invitations/decline", `{"token":"invitation_token"}`, handler.DeclineInvitation)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,52 @@
+package organization
+
+import (
+	"errors"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/tenant"
+	"go_starter_api/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RequireOrganization refuses requests without an active organization the account is still
+// a member of, it must run after AuthMiddleware. The organization is stored on the request
+// and its context, so repositories of tenant owned records only see that organization.
+func RequireOrganization(organizationRepository domain.OrganizationRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID := c.GetUint(utils.AccountIdContextKey)
+		if accountID == 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		claims, ok := utils.AuthClaims(c)
+		if !ok || claims.OrganizationID == 0 {
+			c.JSON(http.StatusForbidden, gin.H{"error": "no active organization"})
+			c.Abort()
+			return
+		}
+
+		// the token outlives a removal, membership is checked on every request
+		membership, err := organizationRepository.GetMembership(c.Request.Context(), claims.OrganizationID, accountID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "not a member of the organization"})
+				c.Abort()
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			c.Abort()
+			return
+		}
+
+		c.Set(utils.OrganizationIdContextKey, membership.OrganizationID)
+		c.Set(utils.OrganizationRoleContextKey, membership.Role)
+		c.Request = c.Request.WithContext(tenant.WithOrganizationID(c.Request.Context(), membership.OrganizationID))
+
+		c.Next()
+	}
+}
@@ -0,0 +1,87 @@
+package organization_test
+
+import (
+	"context"
+	"errors"
+	"go_starter_api/internal/organization"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/tenant"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestRequireOrganization(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	setup := func(repository domain.OrganizationRepository, claims *domain.AuthClaims) *gin.Engine {
+		router := gin.New()
+		router.GET("/protected", func(c *gin.Context) {
+			c.Set(utils.AccountIdContextKey, claims.AccountID)
+			c.Set(utils.AuthClaimsContextKey, claims)
+		}, organization.RequireOrganization(repository), func(c *gin.Context) {
+			organizationID, _ := tenant.OrganizationID(c.Request.Context())
+			c.JSON(http.StatusOK, gin.H{
+				"organization": c.GetUint(utils.OrganizationIdContextKey),
+				"role":         c.GetString(utils.OrganizationRoleContextKey),
+				"tenant":       organizationID,
+			})
+		})
+		return router
+	}
+
+	request := func(router *gin.Engine) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/protected", nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("should scope the request to the active organization", func(t *testing.T) {
+		repository := domain.NewMockOrganizationRepository(t)
+		repository.On("GetMembership", anyContext, uint(3), uint(1)).Return(&domain.Membership{OrganizationID: 3, AccountID: 1, Role: domain.OrganizationRoleAdmin}, nil)
+
+		w := request(setup(repository, &domain.AuthClaims{AccountID: 1, OrganizationID: 3}))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"organization":3,"role":"admin","tenant":3}`, w.Body.String())
+	})
+
+	t.Run("should refuse a token without an active organization", func(t *testing.T) {
+		w := request(setup(domain.NewMockOrganizationRepository(t), &domain.AuthClaims{AccountID: 1}))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("should refuse an account removed from the organization", func(t *testing.T) {
+		repository := domain.NewMockOrganizationRepository(t)
+		repository.On("GetMembership", anyContext, uint(3), uint(1)).Return(nil, gorm.ErrRecordNotFound)
+
+		w := request(setup(repository, &domain.AuthClaims{AccountID: 1, OrganizationID: 3}))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("should fail when the membership cannot be loaded", func(t *testing.T) {
+		repository := domain.NewMockOrganizationRepository(t)
+		repository.On("GetMembership", anyContext, uint(3), uint(1)).Return(nil, errors.New("db error"))
+
+		w := request(setup(repository, &domain.AuthClaims{AccountID: 1, OrganizationID: 3}))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("should refuse an unauthenticated request", func(t *testing.T) {
+		w := request(setup(domain.NewMockOrganizationRepository(t), &domain.AuthClaims{}))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
@@ -0,0 +1,234 @@
+package organization
+
+import (
+	"context"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/tenant"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OrganizationRepo struct {
+	db    *gorm.DB
+	trace trace.Tracer
+}
+
+func NewOrganizationRepository(db *gorm.DB) domain.OrganizationRepository {
+	trace := otel.Tracer("organizationRepository")
+	return &OrganizationRepo{
+		db:    db,
+		trace: trace,
+	}
+}
+
+func (r *OrganizationRepo) CreateOrganization(ctx context.Context, organization *domain.Organization, ownerID uint) (*domain.Organization, error) {
+	_, span := r.trace.Start(ctx, "CreateOrganization")
+	defer span.End()
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(organization).Error; err != nil {
+			return err
+		}
+		return tx.Create(&domain.Membership{
+			OrganizationID: organization.ID,
+			AccountID:      ownerID,
+			Role:           domain.OrganizationRoleOwner,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return organization, nil
+}
+
+func (r *OrganizationRepo) GetOrganizationByID(ctx context.Context, id uint) (*domain.Organization, error) {
+	_, span := r.trace.Start(ctx, "GetOrganizationByID")
+	defer span.End()
+	var organization domain.Organization
+	err := r.db.Where("id = ?", id).First(&organization).Error
+	if err != nil {
+		return nil, err
+	}
+	return &organization, nil
+}
+
+func (r *OrganizationRepo) GetMembership(ctx context.Context, organizationID uint, accountID uint) (*domain.Membership, error) {
+	_, span := r.trace.Start(ctx, "GetMembership")
+	defer span.End()
+	var membership domain.Membership
+	err := r.db.Preload("Organization").
+		Where("organization_id = ? AND account_id = ?", organizationID, accountID).
+		First(&membership).Error
+	if err != nil {
+		return nil, err
+	}
+	return &membership, nil
+}
+
+func (r *OrganizationRepo) ListAccountMemberships(ctx context.Context, accountID uint) ([]domain.Membership, error) {
+	_, span := r.trace.Start(ctx, "ListAccountMemberships")
+	defer span.End()
+	var memberships []domain.Membership
+	err := r.db.Preload("Organization").
+		Joins("JOIN organizations ON organizations.id = memberships.organization_id AND organizations.deleted_at IS NULL").
+		Where("memberships.account_id = ?", accountID).
+		Order("memberships.id").
+		Find(&memberships).Error
+	if err != nil {
+		return nil, err
+	}
+	return memberships, nil
+}
+
+func (r *OrganizationRepo) ListMembers(ctx context.Context, organizationID uint) ([]domain.Membership, error) {
+	_, span := r.trace.Start(ctx, "ListMembers")
+	defer span.End()
+	var memberships []domain.Membership
+	err := r.db.Preload("Account").
+		Where("organization_id = ?", organizationID).
+		Order("id").
+		Find(&memberships).Error
+	if err != nil {
+		return nil, err
+	}
+	return memberships, nil
+}
+
+// RemoveMember deletes the row so the account can join again later, an organization always
+// keeps an owner
+func (r *OrganizationRepo) RemoveMember(ctx context.Context, organizationID uint, accountID uint) error {
+	_, span := r.trace.Start(ctx, "RemoveMember")
+	defer span.End()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var membership domain.Membership
+		err := tx.Where("organization_id = ? AND account_id = ?", organizationID, accountID).
+			First(&membership).Error
+		if err != nil {
+			return err
+		}
+
+		if membership.Role == domain.OrganizationRoleOwner {
+			// the owner rows are locked so two owners removing each other cannot both see the other one
+			var owners []domain.Membership
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("organization_id = ? AND role = ?", organizationID, domain.OrganizationRoleOwner).
+				Order("id").
+				Find(&owners).Error
+			if err != nil {
+				return err
+			}
+			if len(owners) <= 1 {
+				return domain.ErrLastOrganizationOwner
+			}
+		}
+
+		if err := tx.Unscoped().Delete(&membership).Error; err != nil {
+			return err
+		}
+
+		// refreshing a session still in the organization would otherwise put it back in the token
+		return tx.Model(&domain.Session{}).
+			Where("account_id = ? AND organization_id = ?", accountID, organizationID).
+			Update("organization_id", 0).Error
+	})
+}
+
+// invitations are tenant owned, the statements carry the context so they are scoped to its organization
+
+func (r *OrganizationRepo) CreateInvitation(ctx context.Context, invitation *domain.Invitation) (*domain.Invitation, error) {
+	_, span := r.trace.Start(ctx, "CreateInvitation")
+	defer span.End()
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&domain.Invitation{}).
+			Where("email = ? AND status = ?", invitation.Email, domain.InvitationStatusPending).
+			Updates(map[string]any{"status": domain.InvitationStatusRevoked, "responded_at": time.Now()}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(invitation).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (r *OrganizationRepo) ListPendingInvitations(ctx context.Context) ([]domain.Invitation, error) {
+	_, span := r.trace.Start(ctx, "ListPendingInvitations")
+	defer span.End()
+	var invitations []domain.Invitation
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND expires_at > ?", domain.InvitationStatusPending, time.Now()).
+		Order("id").
+		Find(&invitations).Error
+	if err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+func (r *OrganizationRepo) RevokeInvitation(ctx context.Context, id uint) error {
+	_, span := r.trace.Start(ctx, "RevokeInvitation")
+	defer span.End()
+	result := r.db.WithContext(ctx).Model(&domain.Invitation{}).
+		Where("id = ? AND status = ?", id, domain.InvitationStatusPending).
+		Updates(map[string]any{"status": domain.InvitationStatusRevoked, "responded_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *OrganizationRepo) GetInvitationByID(ctx context.Context, id uint) (*domain.Invitation, error) {
+	_, span := r.trace.Start(ctx, "GetInvitationByID")
+	defer span.End()
+	var invitation domain.Invitation
+	err := tenant.WithoutTenant(r.db.WithContext(ctx)).Where("id = ?", id).First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *OrganizationRepo) AcceptInvitation(ctx context.Context, invitation *domain.Invitation, accountID uint) error {
+	_, span := r.trace.Start(ctx, "AcceptInvitation")
+	defer span.End()
+	return tenant.WithoutTenant(r.db.WithContext(ctx)).Transaction(func(tx *gorm.DB) error {
+		if err := respond(tx, invitation.ID, domain.InvitationStatusAccepted, &accountID); err != nil {
+			return err
+		}
+		// an account that already joined keeps its current role
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&domain.Membership{
+			OrganizationID: invitation.OrganizationID,
+			AccountID:      accountID,
+			Role:           invitation.Role,
+		}).Error
+	})
+}
+
+func (r *OrganizationRepo) DeclineInvitation(ctx context.Context, id uint) error {
+	_, span := r.trace.Start(ctx, "DeclineInvitation")
+	defer span.End()
+	return respond(tenant.WithoutTenant(r.db.WithContext(ctx)), id, domain.InvitationStatusDeclined, nil)
+}
+
+// respond answers the invitation only while it is pending and unexpired, so it is answered once
+func respond(db *gorm.DB, id uint, status string, accountID *uint) error {
+	now := time.Now()
+	result := db.Model(&domain.Invitation{}).
+		Where("id = ? AND status = ? AND expires_at > ?", id, domain.InvitationStatusPending, now).
+		Updates(map[string]any{"status": status, "responded_at": now, "account_id": accountID})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrInvitationNotPending
+	}
+	return nil
+}
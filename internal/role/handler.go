@@ -0,0 +1,128 @@
+package role
+
+import (
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type RoleHandler struct {
+	logger *logrus.Logger
+	tracer trace.Tracer
+
+	roleRepository domain.RoleRepository
+}
+
+const (
+	name = "roleHandler"
+)
+
+func NewRoleHandler(logger *logrus.Logger, roleRepository domain.RoleRepository) *RoleHandler {
+	tracer := otel.Tracer(name)
+	return &RoleHandler{
+		logger:         logger,
+		tracer:         tracer,
+		roleRepository: roleRepository,
+	}
+}
+
+type RoleResponse struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type ListRolesResponse struct {
+	Roles []RoleResponse `json:"roles"`
+}
+
+func toRoleResponses(roles []domain.Role) []RoleResponse {
+	responses := []RoleResponse{}
+	for _, role := range roles {
+		permissions := []string{}
+		for _, permission := range role.Permissions {
+			permissions = append(permissions, permission.Permission)
+		}
+		responses = append(responses, RoleResponse{
+			Name:        role.Name,
+			Description: role.Description,
+			Permissions: permissions,
+		})
+	}
+	return responses
+}
+
+// @Summary		List Roles
+// @Description	List every role with its permissions, requires roles:read
+// @Tags			roles
+// @Produce		json
+// @Success		200	{object}	ListRolesResponse
+// @Failure		403	{object}	map[string]string
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/roles [get]
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "ListRoles")
+	defer span.End()
+
+	roles, err := h.roleRepository.ListRoles(ctx)
+	if err != nil {
+		h.logger.Errorf("failed to list roles: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListRolesResponse{Roles: toRoleResponses(roles)})
+}
+
+type AccountRolesResponse struct {
+	Roles       []RoleResponse `json:"roles"`
+	Permissions []string       `json:"permissions"`
+}
+
+// @Summary		Get Account Roles
+// @Description	Get the roles of the account and the permissions they grant
+// @Tags			roles
+// @Produce		json
+// @Success		200	{object}	AccountRolesResponse
+// @Failure		500	{object}	map[string]string
+// @Router			/api/v1/account/roles [get]
+func (h *RoleHandler) GetAccountRoles(c *gin.Context) {
+	ctx := c.Request.Context()
+	ctx, span := h.tracer.Start(ctx, "GetAccountRoles")
+	defer span.End()
+
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		h.logger.Errorf("accountID not found")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	roles, err := h.roleRepository.ListAccountRoles(ctx, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to list account roles: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	permissions, err := h.roleRepository.GetAccountPermissions(ctx, accountID)
+	if err != nil {
+		h.logger.WithField("userId", accountID).Errorf("failed to get account permissions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	c.JSON(http.StatusOK, AccountRolesResponse{
+		Roles:       toRoleResponses(roles),
+		Permissions: permissions,
+	})
+}
@@ -0,0 +1,75 @@
+package role_test
+
+import (
+	"context"
+	"encoding/json"
+	"go_starter_api/internal/role"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestRoleHandler_GetAccountRoles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	otel.SetTracerProvider(noop.NewTracerProvider())
+
+	t.Run("should return the roles and permissions of the account", func(t *testing.T) {
+		repository := domain.NewMockRoleRepository(t)
+		repository.On("ListAccountRoles", anyContext, uint(1)).Return([]domain.Role{
+			{Name: domain.RoleAdmin, Permissions: []domain.RolePermission{{Permission: domain.PermissionAll}}},
+		}, nil)
+		repository.On("GetAccountPermissions", anyContext, uint(1)).Return([]string{domain.PermissionAll}, nil)
+
+		handler := role.NewRoleHandler(logrus.New(), repository)
+
+		router := gin.New()
+		router.GET("/account/roles", func(c *gin.Context) {
+			c.Set(utils.AccountIdContextKey, uint(1))
+		}, handler.GetAccountRoles)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/account/roles", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response role.AccountRolesResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, []string{domain.PermissionAll}, response.Permissions)
+		assert.Len(t, response.Roles, 1)
+		assert.Equal(t, domain.RoleAdmin, response.Roles[0].Name)
+		assert.Equal(t, []string{domain.PermissionAll}, response.Roles[0].Permissions)
+	})
+
+	t.Run("should return empty lists for an account without roles", func(t *testing.T) {
+		repository := domain.NewMockRoleRepository(t)
+		repository.On("ListAccountRoles", anyContext, uint(1)).Return(nil, nil)
+		repository.On("GetAccountPermissions", anyContext, uint(1)).Return(nil, nil)
+
+		handler := role.NewRoleHandler(logrus.New(), repository)
+
+		router := gin.New()
+		router.GET("/account/roles", func(c *gin.Context) {
+			c.Set(utils.AccountIdContextKey, uint(1))
+		}, handler.GetAccountRoles)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/account/roles", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"roles":[],"permissions":[]}`, w.Body.String())
+	})
+}
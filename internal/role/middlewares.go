@@ -0,0 +1,50 @@
+package role
+
+import (
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission refuses accounts without the permission, it must run after AuthMiddleware.
+// Permissions are resolved on every request so a revoked role takes effect immediately,
+// and kept on the request for the checks that follow. A request made with a scoped api key
+// also needs the permission among the scopes of the key.
+func RequirePermission(roleRepository domain.RoleRepository, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accountID := c.GetUint(utils.AccountIdContextKey)
+		if accountID == 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		permissions, ok := c.Get(utils.PermissionsContextKey)
+		if !ok {
+			granted, err := roleRepository.GetAccountPermissions(c.Request.Context(), accountID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+				c.Abort()
+				return
+			}
+			c.Set(utils.PermissionsContextKey, granted)
+			permissions = granted
+		}
+
+		if !domain.HasPermission(permissions.([]string), permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			c.Abort()
+			return
+		}
+
+		if claims, ok := utils.AuthClaims(c); ok && !claims.Allows(permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
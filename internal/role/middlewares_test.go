@@ -0,0 +1,108 @@
+package role_test
+
+import (
+	"context"
+	"errors"
+	"go_starter_api/internal/role"
+	"go_starter_api/pkg/domain"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHasPermission(t *testing.T) {
+	assert.True(t, domain.HasPermission([]string{domain.PermissionAll}, domain.PermissionAccountsWrite))
+	assert.True(t, domain.HasPermission([]string{"accounts:*"}, domain.PermissionAccountsWrite))
+	assert.True(t, domain.HasPermission([]string{domain.PermissionAccountsRead}, domain.PermissionAccountsRead))
+	assert.False(t, domain.HasPermission([]string{domain.PermissionAccountsRead}, domain.PermissionAccountsWrite))
+	assert.False(t, domain.HasPermission([]string{"roles:*"}, domain.PermissionAccountsRead))
+	assert.False(t, domain.HasPermission(nil, domain.PermissionAccountsRead))
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	anyContext := mock.MatchedBy(func(ctx context.Context) bool { return true })
+
+	setup := func(repository domain.RoleRepository, accountID uint, permissions ...string) *gin.Engine {
+		router := gin.New()
+		handlers := []gin.HandlerFunc{func(c *gin.Context) {
+			c.Set(utils.AccountIdContextKey, accountID)
+		}}
+		for _, permission := range permissions {
+			handlers = append(handlers, role.RequirePermission(repository, permission))
+		}
+		handlers = append(handlers, func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"message": "ok"})
+		})
+		router.GET("/protected", handlers...)
+		return router
+	}
+
+	request := func(router *gin.Engine) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/protected", nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("should allow an account with the permission", func(t *testing.T) {
+		repository := domain.NewMockRoleRepository(t)
+		repository.On("GetAccountPermissions", anyContext, uint(1)).Return([]string{domain.PermissionAccountsRead}, nil).Once()
+
+		// the permissions are resolved once for every check of the request
+		router := setup(repository, 1, domain.PermissionAccountsRead, domain.PermissionAccountsRead)
+
+		assert.Equal(t, http.StatusOK, request(router).Code)
+	})
+
+	t.Run("should refuse an account without the permission", func(t *testing.T) {
+		repository := domain.NewMockRoleRepository(t)
+		repository.On("GetAccountPermissions", anyContext, uint(1)).Return([]string{domain.PermissionAccountsRead}, nil)
+
+		router := setup(repository, 1, domain.PermissionAccountsWrite)
+
+		assert.Equal(t, http.StatusForbidden, request(router).Code)
+	})
+
+	t.Run("should refuse an unauthenticated request", func(t *testing.T) {
+		router := setup(domain.NewMockRoleRepository(t), 0, domain.PermissionAccountsRead)
+
+		assert.Equal(t, http.StatusUnauthorized, request(router).Code)
+	})
+
+	t.Run("should limit an api key to its scopes", func(t *testing.T) {
+		repository := domain.NewMockRoleRepository(t)
+		repository.On("GetAccountPermissions", anyContext, uint(1)).Return([]string{domain.PermissionAll}, nil)
+
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set(utils.AccountIdContextKey, uint(1))
+			c.Set(utils.AuthClaimsContextKey, &domain.AuthClaims{AccountID: 1, APIKeyID: 2, Scopes: []string{domain.PermissionAccountsRead}})
+		})
+		ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"message": "ok"}) }
+		router.GET("/read", role.RequirePermission(repository, domain.PermissionAccountsRead), ok)
+		router.GET("/write", role.RequirePermission(repository, domain.PermissionAccountsWrite), ok)
+
+		for path, code := range map[string]int{"/read": http.StatusOK, "/write": http.StatusForbidden} {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", path, nil)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, code, w.Code, path)
+		}
+	})
+
+	t.Run("should fail when the permissions cannot be resolved", func(t *testing.T) {
+		repository := domain.NewMockRoleRepository(t)
+		repository.On("GetAccountPermissions", anyContext, uint(1)).Return(nil, errors.New("db error"))
+
+		router := setup(repository, 1, domain.PermissionAccountsRead)
+
+		assert.Equal(t, http.StatusInternalServerError, request(router).Code)
+	})
+}
@@ -0,0 +1,121 @@
+package role
+
+import (
+	"context"
+	"go_starter_api/pkg/domain"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RoleRepo struct {
+	db    *gorm.DB
+	trace trace.Tracer
+}
+
+func NewRoleRepository(db *gorm.DB) domain.RoleRepository {
+	trace := otel.Tracer("roleRepository")
+	return &RoleRepo{
+		db:    db,
+		trace: trace,
+	}
+}
+
+func (r *RoleRepo) EnsureRole(ctx context.Context, name string, description string, permissions []string) (*domain.Role, error) {
+	_, span := r.trace.Start(ctx, "EnsureRole")
+	defer span.End()
+	var role domain.Role
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where(domain.Role{Name: name}).
+			Attrs(domain.Role{Description: description}).
+			FirstOrCreate(&role).Error
+		if err != nil {
+			return err
+		}
+		for _, permission := range permissions {
+			err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&domain.RolePermission{RoleID: role.ID, Permission: permission}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Preload("Permissions").First(&role, role.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *RoleRepo) GetRoleByName(ctx context.Context, name string) (*domain.Role, error) {
+	_, span := r.trace.Start(ctx, "GetRoleByName")
+	defer span.End()
+	var role domain.Role
+	err := r.db.Preload("Permissions").Where("name = ?", name).First(&role).Error
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *RoleRepo) ListRoles(ctx context.Context) ([]domain.Role, error) {
+	_, span := r.trace.Start(ctx, "ListRoles")
+	defer span.End()
+	var roles []domain.Role
+	err := r.db.Preload("Permissions").Order("name").Find(&roles).Error
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// AssignRole gives the role to the account, assigning a role the account already has is a no-op
+func (r *RoleRepo) AssignRole(ctx context.Context, accountID uint, roleID uint) error {
+	_, span := r.trace.Start(ctx, "AssignRole")
+	defer span.End()
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&domain.AccountRole{AccountID: accountID, RoleID: roleID}).Error
+}
+
+// UnassignRole takes the role from the account, the row is deleted so the role can be assigned again
+func (r *RoleRepo) UnassignRole(ctx context.Context, accountID uint, roleID uint) error {
+	_, span := r.trace.Start(ctx, "UnassignRole")
+	defer span.End()
+	return r.db.Unscoped().
+		Where("account_id = ? AND role_id = ?", accountID, roleID).
+		Delete(&domain.AccountRole{}).Error
+}
+
+func (r *RoleRepo) ListAccountRoles(ctx context.Context, accountID uint) ([]domain.Role, error) {
+	_, span := r.trace.Start(ctx, "ListAccountRoles")
+	defer span.End()
+	var roles []domain.Role
+	err := r.db.Preload("Permissions").
+		Joins("JOIN account_roles ON account_roles.role_id = roles.id").
+		Where("account_roles.account_id = ?", accountID).
+		Order("roles.name").
+		Find(&roles).Error
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// GetAccountPermissions returns the permissions of every role assigned to the account
+func (r *RoleRepo) GetAccountPermissions(ctx context.Context, accountID uint) ([]string, error) {
+	_, span := r.trace.Start(ctx, "GetAccountPermissions")
+	defer span.End()
+	var permissions []string
+	err := r.db.Model(&domain.RolePermission{}).
+		Distinct("role_permissions.permission").
+		Joins("JOIN account_roles ON account_roles.role_id = role_permissions.role_id").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id AND roles.deleted_at IS NULL").
+		Where("account_roles.account_id = ?", accountID).
+		Pluck("role_permissions.permission", &permissions).Error
+	if err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
@@ -0,0 +1,87 @@
+// Package audit keeps the trail of who did what, from where.
+//
+// Middleware puts the client address, user agent and id of every request in its context, the
+// activity log reads them from there. Records embedding AppendOnly are never changed once written:
+// with the Plugin registered on the database their updates and deletes fail with ErrAppendOnly,
+// unless the statement is explicitly allowed to rewrite them with Rewrite. Raw SQL is not checked.
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the id of a request, it is taken from a proxy that already set one
+// and returned with every response
+const RequestIDHeader = "X-Request-ID"
+
+// longer user agents are cut, they are kept for the record and not parsed
+const maxUserAgentLength = 512
+
+// ids set by a client are kept when they look like one, anything else is replaced
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// Request describes the request an action was taken in
+type Request struct {
+	IPAddress string
+	UserAgent string
+	RequestID string
+	// ActorID is the account authenticated for the request, it differs from the account
+	// an activity belongs to when an administrator acts on another account
+	ActorID uint
+}
+
+type contextKey struct{}
+
+// WithRequest returns a context carrying the request
+func WithRequest(ctx context.Context, request Request) context.Context {
+	return context.WithValue(ctx, contextKey{}, request)
+}
+
+// RequestFromContext returns the request the context was created for
+func RequestFromContext(ctx context.Context) (Request, bool) {
+	request, ok := ctx.Value(contextKey{}).(Request)
+	return request, ok
+}
+
+// WithActor returns a context whose request is made by the account
+func WithActor(ctx context.Context, actorID uint) context.Context {
+	request, _ := RequestFromContext(ctx)
+	request.ActorID = actorID
+	return WithRequest(ctx, request)
+}
+
+// Middleware describes every request in its context and sends its id back in RequestIDHeader
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+		c.Header(RequestIDHeader, requestID)
+
+		userAgent := c.Request.UserAgent()
+		if len(userAgent) > maxUserAgentLength {
+			userAgent = strings.ToValidUTF8(userAgent[:maxUserAgentLength], "")
+		}
+
+		c.Request = c.Request.WithContext(WithRequest(c.Request.Context(), Request{
+			IPAddress: c.ClientIP(),
+			UserAgent: userAgent,
+			RequestID: requestID,
+		}))
+
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,77 @@
+package audit_test
+
+import (
+	"context"
+	"go_starter_api/pkg/audit"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// serve returns the request the handler found in its context
+	serve := func(t *testing.T, header http.Header) (audit.Request, *httptest.ResponseRecorder) {
+		var request audit.Request
+		router := gin.New()
+		router.GET("/", audit.Middleware(), func(c *gin.Context) {
+			var ok bool
+			request, ok = audit.RequestFromContext(c.Request.Context())
+			require.True(t, ok)
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "203.0.113.7:1234"
+		for name, values := range header {
+			req.Header.Set(name, values[0])
+		}
+		router.ServeHTTP(w, req)
+		return request, w
+	}
+
+	t.Run("should describe the request in its context", func(t *testing.T) {
+		request, w := serve(t, http.Header{"User-Agent": {"curl/8.0"}})
+
+		assert.Equal(t, "203.0.113.7", request.IPAddress)
+		assert.Equal(t, "curl/8.0", request.UserAgent)
+		assert.Len(t, request.RequestID, 32)
+		assert.Equal(t, request.RequestID, w.Header().Get(audit.RequestIDHeader))
+		assert.Zero(t, request.ActorID)
+	})
+
+	t.Run("should keep the request id set by a proxy", func(t *testing.T) {
+		request, w := serve(t, http.Header{audit.RequestIDHeader: {"edge-42"}})
+
+		assert.Equal(t, "edge-42", request.RequestID)
+		assert.Equal(t, "edge-42", w.Header().Get(audit.RequestIDHeader))
+	})
+
+	t.Run("should replace a request id that does not look like one", func(t *testing.T) {
+		request, _ := serve(t, http.Header{audit.RequestIDHeader: {"<script>"}})
+
+		assert.Len(t, request.RequestID, 32)
+	})
+
+	t.Run("should cut long user agents", func(t *testing.T) {
+		request, _ := serve(t, http.Header{"User-Agent": {strings.Repeat("a", 2000)}})
+
+		assert.Len(t, request.UserAgent, 512)
+	})
+}
+
+func TestWithActor(t *testing.T) {
+	ctx := audit.WithRequest(context.Background(), audit.Request{IPAddress: "203.0.113.7"})
+
+	request, ok := audit.RequestFromContext(audit.WithActor(ctx, 3))
+
+	assert.True(t, ok)
+	assert.Equal(t, audit.Request{IPAddress: "203.0.113.7", ActorID: 3}, request)
+}
@@ -0,0 +1,54 @@
+package audit
+
+import (
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+var ErrAppendOnly = errors.New("record is append only")
+
+const rewriteKey = "audit:rewrite"
+
+// AppendOnly is embedded by records that are only ever created
+type AppendOnly struct{}
+
+func (AppendOnly) appendOnly() {}
+
+type appendOnly interface {
+	appendOnly()
+}
+
+// Rewrite allows the statement to change append only records, it is kept for the few
+// changes the law asks of a history, such as forgetting a purged account
+func Rewrite(db *gorm.DB) *gorm.DB {
+	return db.Set(rewriteKey, true)
+}
+
+// Plugin refuses the updates and deletes of append only records, register it with db.Use
+type Plugin struct{}
+
+func (Plugin) Name() string {
+	return "audit"
+}
+
+func (Plugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Update().Before("gorm:update").Register("audit:update", refuse); err != nil {
+		return err
+	}
+	return callbacks.Delete().Before("gorm:delete").Register("audit:delete", refuse)
+}
+
+func refuse(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return
+	}
+	if rewrite, ok := db.Get(rewriteKey); ok && rewrite.(bool) {
+		return
+	}
+	if _, ok := reflect.New(db.Statement.Schema.ModelType).Interface().(appendOnly); ok {
+		_ = db.AddError(ErrAppendOnly)
+	}
+}
@@ -0,0 +1,84 @@
+package audit_test
+
+import (
+	"go_starter_api/pkg/audit"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type entry struct {
+	ID uint `gorm:"primarykey"`
+	audit.AppendOnly
+
+	Text      string
+	DeletedAt gorm.DeletedAt
+}
+
+type draft struct {
+	ID   uint `gorm:"primarykey"`
+	Text string
+}
+
+func setup(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(audit.Plugin{}))
+	require.NoError(t, db.AutoMigrate(&entry{}, &draft{}))
+	return db
+}
+
+func TestPlugin(t *testing.T) {
+
+	t.Run("should create and read append only records", func(t *testing.T) {
+		db := setup(t)
+
+		require.NoError(t, db.Create(&entry{Text: "one"}).Error)
+
+		var entries []entry
+		require.NoError(t, db.Find(&entries).Error)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("should refuse to change or delete append only records", func(t *testing.T) {
+		db := setup(t)
+		record := entry{Text: "one"}
+		require.NoError(t, db.Create(&record).Error)
+
+		assert.ErrorIs(t, db.Model(&entry{}).Where("id = ?", record.ID).Update("text", "changed").Error, audit.ErrAppendOnly)
+		record.Text = "changed"
+		assert.ErrorIs(t, db.Save(&record).Error, audit.ErrAppendOnly)
+		assert.ErrorIs(t, db.Delete(&entry{}, record.ID).Error, audit.ErrAppendOnly)
+		assert.ErrorIs(t, db.Unscoped().Delete(&entry{}, record.ID).Error, audit.ErrAppendOnly)
+
+		var stored entry
+		require.NoError(t, db.First(&stored, record.ID).Error)
+		assert.Equal(t, "one", stored.Text)
+	})
+
+	t.Run("should change append only records when rewriting is allowed", func(t *testing.T) {
+		db := setup(t)
+		record := entry{Text: "one"}
+		require.NoError(t, db.Create(&record).Error)
+
+		require.NoError(t, audit.Rewrite(db).Model(&entry{}).Where("id = ?", record.ID).Update("text", "changed").Error)
+
+		var stored entry
+		require.NoError(t, db.First(&stored, record.ID).Error)
+		assert.Equal(t, "changed", stored.Text)
+
+		assert.ErrorIs(t, db.Delete(&entry{}, record.ID).Error, audit.ErrAppendOnly)
+	})
+
+	t.Run("should leave other records alone", func(t *testing.T) {
+		db := setup(t)
+		record := draft{Text: "one"}
+		require.NoError(t, db.Create(&record).Error)
+
+		require.NoError(t, db.Model(&record).Update("text", "changed").Error)
+		require.NoError(t, db.Delete(&record).Error)
+	})
+}
@@ -3,6 +3,8 @@ package domain
 import (
 	"context"
 	"errors"
+	"go_starter_api/pkg/audit"
+	"slices"
 	"time"
 
 	"gorm.io/gorm"
@@ -15,44 +17,567 @@ type Account struct {
 	DeletedAt gorm.DeletedAt `gorm:"index"`
 	Email     string         `json:"email" gorm:"unique"`
 	Password  string         `json:"password"`
+
+	EmailVerified bool       `json:"email_verified"`
+	VerifiedAt    *time.Time `json:"verified_at"`
+
+	MFAEnabled bool `json:"mfa_enabled"`
+	// base32 totp secret, set on enrollment and only trusted once MFAEnabled is true
+	MFASecret string `json:"-"`
+	// last accepted totp time step, a code is never accepted twice
+	MFALastStep int64 `json:"-"`
+
+	// consecutive failed password logins since the last successful login or lockout
+	FailedLoginAttempts int        `json:"-"`
+	LastFailedLoginAt   *time.Time `json:"-"`
+	// password logins are refused until this time
+	LockedUntil *time.Time `json:"locked_until"`
+
+	// set while an administrator has disabled the account, it cannot log in
+	DisabledAt *time.Time `json:"disabled_at"`
+	// set when an administrator forced a password reset, password logins are refused until the password is changed
+	PasswordResetRequired bool `json:"password_reset_required"`
+
+	// set while the account has a passkey, logins with another first factor then ask for a second factor
+	WebAuthnEnabled bool `json:"webauthn_enabled"`
+
+	// set when the owner deleted the account, it is purged at this time unless restored before
+	PurgeAt *time.Time `json:"purge_at" gorm:"index"`
+
+	AccountProfile `gorm:"embedded"`
+	// storage key of the uploaded avatar, kept until the avatar is replaced or deleted even
+	// when avatar_url is edited to point elsewhere
+	AvatarKey string `json:"-"`
+}
+
+// AccountProfile holds the fields an account edits on its own profile, zero values are unset
+type AccountProfile struct {
+	DisplayName string `json:"display_name"`
+	AvatarURL   string `json:"avatar_url"`
+	// BCP 47 language tag
+	Locale string `json:"locale"`
+	// IANA time zone name
+	Timezone string `json:"timezone"`
+	// free-form values of the client, stored as given
+	Metadata map[string]any `json:"metadata" gorm:"serializer:json"`
+}
+
+// account statuses, as filtered on by administrators
+const (
+	AccountStatusActive   = "active"
+	AccountStatusLocked   = "locked"
+	AccountStatusDisabled = "disabled"
+	AccountStatusDeleted  = "deleted"
+)
+
+// Status returns the status of the account, the first that applies of deleted, disabled, locked and active
+func (a *Account) Status(now time.Time) string {
+	switch {
+	case a.DeletedAt.Valid:
+		return AccountStatusDeleted
+	case a.DisabledAt != nil:
+		return AccountStatusDisabled
+	case a.LockedUntil != nil && a.LockedUntil.After(now):
+		return AccountStatusLocked
+	default:
+		return AccountStatusActive
+	}
+}
+
+// AccountFilter selects the accounts returned by ListAccounts, zero fields do not filter.
+// Deleted accounts are only returned when filtering on AccountStatusDeleted.
+type AccountFilter struct {
+	// matches part of the email, case insensitively
+	Email         string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Status        string
+
+	Offset int
+	Limit  int
 }
 
 var (
-	ActivityLogin          = "login"
-	ActivityLogout         = "logout"
-	ActivityRegister       = "register"
-	ActivityUpdate         = "update"
-	ActivityDelete         = "delete"
-	ActivityResetPassword  = "reset_password"
-	ActivityForgotPassword = "forgot_password"
-	ActivityChangePassword = "change_password"
+	ActivityLogin               = "login"
+	ActivityLogout              = "logout"
+	ActivityRegister            = "register"
+	ActivityUpdate              = "update"
+	ActivityDelete              = "delete"
+	ActivityResetPassword       = "reset_password"
+	ActivityForgotPassword      = "forgot_password"
+	ActivityChangePassword      = "change_password"
+	ActivityRefreshTokenReuse   = "refresh_token_reuse"
+	ActivityLogoutAll           = "logout_all"
+	ActivitySessionRevoked      = "session_revoked"
+	ActivityMFAEnabled          = "mfa_enabled"
+	ActivityMFADisabled         = "mfa_disabled"
+	ActivityRecoveryCodeUsed    = "recovery_code_used"
+	ActivityEmailVerified       = "email_verified"
+	ActivityMagicLinkRequest    = "magic_link_request"
+	ActivityMagicLinkLogin      = "magic_link_login"
+	ActivityLoginFailed         = "login_failed"
+	ActivityAccountLocked       = "account_locked"
+	ActivityAccountUnlocked     = "account_unlocked"
+	ActivityAccountDisabled     = "account_disabled"
+	ActivityAccountEnabled      = "account_enabled"
+	ActivityAccountRestored     = "account_restored"
+	ActivityPasswordResetForced = "password_reset_forced"
+	ActivityAPIKeyCreated       = "api_key_created"
+	ActivityAPIKeyRevoked       = "api_key_revoked"
+	ActivityOAuthLogin          = "oauth_login"
+	ActivityIdentityLinked      = "identity_linked"
+	ActivityIdentityUnlinked    = "identity_unlinked"
+	ActivityOAuthClientApproved = "oauth_client_approved"
+	ActivityPasskeyRegistered   = "passkey_registered"
+	ActivityPasskeyDeleted      = "passkey_deleted"
+	ActivityPasskeyLogin        = "passkey_login"
+	ActivityEmailChangeRequest  = "email_change_request"
+	ActivityEmailChanged        = "email_changed"
+	ActivityEmailChangeCanceled = "email_change_canceled"
+	ActivityNewDeviceLogin      = "new_device_login"
+	ActivitySignInReported      = "sign_in_reported"
 )
 
+// AccountActivity is the audit log of the accounts, activities are only ever created
 type AccountActivity struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime;index"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	audit.AppendOnly
+
+	AccountID uint   `json:"account_id" gorm:"index"`
+	Activity  string `json:"activity" gorm:"index"`
+	// optional context of the activity, such as the fields of an update
+	Details map[string]any `json:"details,omitempty" gorm:"serializer:json"`
+
+	// the administrator who acted on the account, nil when the account acted itself
+	ActorID   *uint  `json:"actor_id,omitempty" gorm:"index"`
+	IPAddress string `json:"ip_address,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	TraceID   string `json:"trace_id,omitempty"`
+}
+
+// ActivityFilter selects the activities returned by ListActivities, zero fields do not filter
+type ActivityFilter struct {
+	AccountID     uint
+	ActorID       uint
+	Activity      string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+
+	Offset int
+	Limit  int
+}
+
+type RefreshToken struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	AccountID uint       `json:"account_id" gorm:"index"`
+	SessionID uint       `json:"session_id" gorm:"index"`
+	FamilyID  string     `json:"family_id" gorm:"index"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	// set when the token was exchanged for a new one, presenting it again is a reuse
+	RotatedAt *time.Time `json:"rotated_at"`
+}
+
+// Session is a single login of an account on a device
+type Session struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	AccountID   uint       `json:"account_id" gorm:"index"`
+	IPAddress   string     `json:"ip_address"`
+	UserAgent   string     `json:"user_agent"`
+	DeviceLabel string     `json:"device_label"`
+	LastSeenAt  time.Time  `json:"last_seen_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+
+	// OrganizationID is the organization the session is working in, 0 when it has none
+	OrganizationID uint `json:"organization_id"`
+}
+
+// RecoveryCode is a single use code that replaces a totp code when the authenticator is lost
+type RecoveryCode struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	AccountID uint       `json:"account_id" gorm:"index"`
+	CodeHash  string     `json:"-" gorm:"uniqueIndex"`
+	UsedAt    *time.Time `json:"used_at"`
+}
+
+// LoginFailure is a failed password login, kept to throttle the ip address it came from
+type LoginFailure struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime;index"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	// zero when the email does not belong to an account
+	AccountID uint   `json:"account_id" gorm:"index"`
+	Email     string `json:"email"`
+	IPAddress string `json:"ip_address" gorm:"index"`
+}
+
+// one time token purposes, a token is only accepted for the purpose it was issued for
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeMagicLink         = "magic_link"
+	TokenPurposeEmailChange       = "email_change"
+	TokenPurposeEmailChangeCancel = "email_change_cancel"
+	TokenPurposeAccountRestore    = "account_restore"
+	TokenPurposeSignInReport      = "sign_in_report"
+)
+
+// OneTimeToken is a single use token sent by email, only its hash is stored
+type OneTimeToken struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	AccountID uint   `json:"account_id" gorm:"index"`
+	Purpose   string `json:"purpose" gorm:"index"`
+	TokenHash string `json:"-" gorm:"uniqueIndex"`
+	// Email is the new address of an email change
+	Email string `json:"-"`
+	// SessionID is the session of the sign-in a report is about
+	SessionID uint       `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+}
+
+// api keys start with APIKeyPrefix, followed by the lookup prefix and the secret
+const APIKeyPrefix = "gsa_"
+
+// scopes of the self-service routes, an api key limited to other scopes cannot use them
+const (
+	ScopeProfileWrite       = "profile:write"
+	ScopeOrganizationsWrite = "organizations:write"
+)
+
+// APIKey is a long lived personal access token, only its hash is stored
+type APIKey struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	AccountID uint   `json:"account_id" gorm:"index"`
+	Name      string `json:"name"`
+	// Prefix is the start of the key, it finds the key without knowing the secret and identifies it in listings
+	Prefix    string `json:"prefix" gorm:"uniqueIndex"`
+	TokenHash string `json:"-"`
+	// Scopes are the permissions the key is limited to, an empty list leaves every permission of the account
+	Scopes     []string   `json:"scopes" gorm:"serializer:json"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+
+	Account *Account `json:"-"`
+}
+
+// Active reports whether the key is neither revoked nor expired
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || k.ExpiresAt.After(now))
+}
+
+// Identity links an account to a user of an external identity provider, the subject is the
+// id of the user at the provider
+type Identity struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	AccountID   uint       `json:"account_id" gorm:"index"`
+	Provider    string     `json:"provider" gorm:"uniqueIndex:idx_identity"`
+	Subject     string     `json:"subject" gorm:"uniqueIndex:idx_identity"`
+	Email       string     `json:"email"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+}
+
+// OAuthState is a sign in with an identity provider in progress, only the hash of the state
+// sent through the browser is stored, the nonce and code verifier never leave the server
+type OAuthState struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	StateHash    string `json:"-" gorm:"uniqueIndex"`
+	Provider     string `json:"provider"`
+	Nonce        string `json:"-"`
+	CodeVerifier string `json:"-"`
+	// AccountID is the account linking the provider, zero when signing in
+	AccountID uint       `json:"account_id"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+}
+
+// grant types of the authorization server, a client is only issued tokens for the grants it was registered with
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeClientCredentials = "client_credentials"
+)
+
+// scopes of the authorization server about the account, the other scopes a client is registered
+// with are only meaningful to the apps verifying its tokens
+const (
+	ScopeOpenID = "openid"
+	ScopeEmail  = "email"
+)
+
+// OAuthClient is an app delegating login to this api, only the hash of its secret is stored.
+// Public clients, such as single page and mobile apps, cannot keep a secret and have none.
+type OAuthClient struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	ClientID   string `json:"client_id" gorm:"uniqueIndex"`
+	SecretHash string `json:"-"`
+	Name       string `json:"name"`
+	Public     bool   `json:"public"`
+	// RedirectURIs are matched exactly, codes are only sent to one of them
+	RedirectURIs []string `json:"redirect_uris" gorm:"serializer:json"`
+	GrantTypes   []string `json:"grant_types" gorm:"serializer:json"`
+	// Scopes are the scopes the client may request
+	Scopes []string `json:"scopes" gorm:"serializer:json"`
+}
+
+func (c *OAuthClient) AllowsGrant(grantType string) bool {
+	return slices.Contains(c.GrantTypes, grantType)
+}
+
+func (c *OAuthClient) AllowsRedirectURI(uri string) bool {
+	return slices.Contains(c.RedirectURIs, uri)
+}
+
+// AllowsScopes reports whether every scope was registered for the client
+func (c *OAuthClient) AllowsScopes(scopes []string) bool {
+	for _, scope := range scopes {
+		if !slices.Contains(c.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// AuthorizationCode is the single use code a client exchanges for the tokens of the account
+// that approved it, only its hash is stored
+type AuthorizationCode struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	CodeHash    string   `json:"-" gorm:"uniqueIndex"`
+	ClientID    string   `json:"client_id" gorm:"index"`
+	AccountID   uint     `json:"account_id" gorm:"index"`
+	RedirectURI string   `json:"redirect_uri"`
+	Scopes      []string `json:"scopes" gorm:"serializer:json"`
+	Nonce       string   `json:"-"`
+	// CodeChallenge is the S256 PKCE challenge the verifier sent with the code must match
+	CodeChallenge string     `json:"-"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	UsedAt        *time.Time `json:"used_at"`
+}
+
+// OAuthConsent records the scopes an account approved for a client, they are not asked again
+type OAuthConsent struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	AccountID uint     `json:"account_id" gorm:"uniqueIndex:idx_oauth_consent"`
+	ClientID  string   `json:"client_id" gorm:"uniqueIndex:idx_oauth_consent"`
+	Scopes    []string `json:"scopes" gorm:"serializer:json"`
+}
+
+// OAuthAccessClaims are the claims of an access token issued to a client, the subject is the
+// account id for the authorization code grant and the client id for client credentials
+type OAuthAccessClaims struct {
+	Subject   string
+	ClientID  string
+	Scopes    []string
+	ExpiresAt time.Time
+}
+
+// purposes of a webauthn challenge
+const (
+	WebAuthnPurposeRegistration = "registration"
+	WebAuthnPurposeLogin        = "login"
+)
+
+// WebAuthnCredential is a passkey registered to an account, the credential id is base64url encoded
+type WebAuthnCredential struct {
 	ID        uint           `json:"id" gorm:"primarykey"`
 	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
 	DeletedAt gorm.DeletedAt `gorm:"index"`
 
-	AccountID uint   `json:"account_id"`
-	Activity  string `json:"activity"`
+	AccountID    uint   `json:"account_id" gorm:"index"`
+	Name         string `json:"name"`
+	CredentialID string `json:"credential_id" gorm:"uniqueIndex"`
+	// PublicKey is the cose encoded key of the passkey
+	PublicKey []byte `json:"-"`
+	// SignCount is the last counter reported by the authenticator, it only ever increases
+	// unless the authenticator does not count and always reports zero
+	SignCount  uint32   `json:"-"`
+	AAGUID     string   `json:"aaguid"`
+	Transports []string `json:"transports" gorm:"serializer:json"`
+	// BackupEligible is set for passkeys synced between devices
+	BackupEligible bool       `json:"backup_eligible"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
+}
+
+// WebAuthnChallenge is a passkey ceremony in progress, only the hash of the challenge is stored
+type WebAuthnChallenge struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	ChallengeHash string `json:"-" gorm:"uniqueIndex"`
+	Purpose       string `json:"purpose"`
+	// AccountID is the account registering a passkey or passing its second factor,
+	// zero for a passwordless login
+	AccountID uint       `json:"account_id"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+}
+
+// AuthClaims are the claims carried by an access token or api key
+type AuthClaims struct {
+	AccountID uint
+	SessionID uint
+	// OrganizationID is the active organization, membership must still be checked
+	OrganizationID uint
+	TokenID        string
+	IssuedAt       time.Time
+	ExpiresAt      time.Time
+
+	// APIKeyID is set when the request was authenticated with an api key
+	APIKeyID uint
+	// Scopes limit the permissions of an api key, empty when it is not limited
+	Scopes []string
+}
+
+// Allows reports whether the scopes of the credential allow the permission, the account must still hold it
+func (c *AuthClaims) Allows(permission string) bool {
+	return len(c.Scopes) == 0 || HasPermission(c.Scopes, permission)
+}
+
+// RevokedToken blocks a single access token, identified by its jti, until it expires
+type RevokedToken struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	TokenID   string    `json:"token_id" gorm:"uniqueIndex"`
+	AccountID uint      `json:"account_id" gorm:"index"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index"`
+}
+
+// AccountTokenRevocation blocks every access token issued to the account before RevokedBefore
+type AccountTokenRevocation struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	AccountID     uint      `json:"account_id" gorm:"uniqueIndex"`
+	RevokedBefore time.Time `json:"revoked_before"`
 }
 
 type AccountService interface {
-	GenerateAuthToken(ctx context.Context, account *Account) (string, error)
+	GenerateAuthToken(ctx context.Context, account *Account, sessionID uint, organizationID uint) (string, error)
 	ValidateAuthToken(ctx context.Context, token string) (uint, error)
+	ParseAuthToken(ctx context.Context, token string) (*AuthClaims, error)
 	HashPassword(ctx context.Context, password string) (string, error)
 	ComparePassword(ctx context.Context, password, hash string) (bool, error)
 
-	GeneratePasswordResetToken(ctx context.Context, account *Account) (string, error)
-	ValidatePasswordResetToken(ctx context.Context, token string) (uint, error)
+	GenerateRefreshToken(ctx context.Context, account *Account, familyID string) (string, *RefreshToken, error)
+	HashToken(ctx context.Context, token string) string
+
+	GenerateTOTPSecret(ctx context.Context) (string, error)
+	TOTPURI(ctx context.Context, account *Account, secret string) string
+	VerifyTOTPCode(ctx context.Context, secret string, code string) (int64, error)
+	GenerateRecoveryCodes(ctx context.Context) ([]string, error)
+	GenerateMFAToken(ctx context.Context, account *Account) (string, error)
+	ValidateMFAToken(ctx context.Context, token string) (*AuthClaims, error)
+
+	GenerateEmailVerificationToken(ctx context.Context, account *Account) (string, error)
+	ValidateEmailVerificationToken(ctx context.Context, token string) (uint, string, error)
+	SendVerificationEmail(ctx context.Context, email string, token string) error
+
+	GenerateOneTimeToken(ctx context.Context, account *Account, purpose string) (string, *OneTimeToken, error)
+	GenerateAPIKey(ctx context.Context, account *Account) (string, *APIKey, error)
 	SendPasswordResetEmail(ctx context.Context, email string, token string) error
+	SendMagicLinkEmail(ctx context.Context, email string, token string) error
+	SendAccountLockedEmail(ctx context.Context, email string, until time.Time) error
+	SendAccountDeletedEmail(ctx context.Context, email string, purgeAt time.Time, token string) error
+	SendEmailChangeEmail(ctx context.Context, email string, token string) error
+	// SendEmailChangeNoticeEmail warns the current address of a requested change, the token cancels it
+	SendEmailChangeNoticeEmail(ctx context.Context, email string, newEmail string, token string) error
+	// SendNewSignInEmail tells the owner of a sign-in from a new device, the token reports it
+	SendNewSignInEmail(ctx context.Context, email string, session *Session, token string) error
+
+	GenerateInvitationToken(ctx context.Context, invitation *Invitation) (string, error)
+	ValidateInvitationToken(ctx context.Context, token string) (uint, string, error)
+	SendInvitationEmail(ctx context.Context, email string, organizationName string, token string) error
+
+	GenerateDataExportToken(ctx context.Context, export *DataExport) (string, error)
+	ValidateDataExportToken(ctx context.Context, token string) (uint, error)
+	SendDataExportReadyEmail(ctx context.Context, email string, expiresAt time.Time, token string) error
+
+	GenerateOAuthClient(ctx context.Context, public bool) (string, *OAuthClient, error)
+	// the tokens of the authorization server are always signed with the keyring
+	GenerateIDToken(ctx context.Context, account *Account, clientID string, nonce string, scopes []string) (string, error)
+	GenerateOAuthAccessToken(ctx context.Context, subject string, clientID string, scopes []string) (string, error)
+	ParseOAuthAccessToken(ctx context.Context, token string) (*OAuthAccessClaims, error)
 }
 
 var (
 	ErrPasswordEmpty     = errors.New("password cannot be empty")
 	ErrInvalidHashFormat = errors.New("invalid hash format")
 	ErrServerURLNotSet   = errors.New("server url is not set")
+
+	ErrRefreshTokenReused = errors.New("refresh token has already been used")
+
+	ErrInvalidMFACode      = errors.New("invalid mfa code")
+	ErrMFACodeReused       = errors.New("mfa code has already been used")
+	ErrInvalidRecoveryCode = errors.New("invalid recovery code")
+
+	ErrInvalidOneTimeToken = errors.New("invalid or expired token")
+	ErrEmailTaken          = errors.New("email is already in use")
+	ErrInvalidAPIKey       = errors.New("invalid api key")
+	ErrInvalidOAuthState   = errors.New("invalid or expired state")
+
+	ErrInvalidAuthorizationCode = errors.New("invalid or expired authorization code")
+
+	ErrInvalidWebAuthnChallenge = errors.New("invalid or expired webauthn challenge")
+	ErrWebAuthnSignCount        = errors.New("passkey sign count did not increase")
+
+	ErrAccountAlreadyLocked = errors.New("account is already locked")
+	ErrAccountNotPurgeable  = errors.New("account is not due for purge")
 )
 
 type AccountRepository interface {
@@ -60,7 +585,121 @@ type AccountRepository interface {
 	GetAccountByEmail(ctx context.Context, email string) (*Account, error)
 	GetAccountByID(ctx context.Context, id uint) (*Account, error)
 	UpdateAccount(ctx context.Context, account *Account) (*Account, error)
+	UpdateAccountPassword(ctx context.Context, accountID uint, passwordHash string) error
+	// UpdateAccountProfile replaces the profile fields of the account, leaving every other column untouched
+	UpdateAccountProfile(ctx context.Context, accountID uint, profile AccountProfile) error
+	// SetAccountAvatar sets the uploaded avatar and the url it is served at, empty values remove it
+	SetAccountAvatar(ctx context.Context, accountID uint, key string, avatarURL string) error
 	DeleteAccount(ctx context.Context, id uint) error
+	ListAccounts(ctx context.Context, filter AccountFilter) ([]Account, int64, error)
+	// GetAccountByIDWithDeleted also returns soft deleted accounts
+	GetAccountByIDWithDeleted(ctx context.Context, id uint) (*Account, error)
+	// RestoreAccount undeletes the account and cancels its purge
+	RestoreAccount(ctx context.Context, id uint) error
+	// ScheduleAccountDeletion soft deletes the account and schedules its purge
+	ScheduleAccountDeletion(ctx context.Context, id uint, purgeAt time.Time) error
+	// ListAccountsDueForPurge returns the ids of deleted accounts whose purge is due
+	ListAccountsDueForPurge(ctx context.Context, now time.Time, limit int) ([]uint, error)
+	// PurgeAccount hard deletes the account with every record it owns and anonymizes its activities,
+	// the organizations it was the last owner of are handed over to another member or deleted when
+	// it was their only member. It fails with ErrAccountNotPurgeable when it was restored or its
+	// purge is not due
+	PurgeAccount(ctx context.Context, id uint, now time.Time) error
+	SetAccountDisabled(ctx context.Context, id uint, disabledAt *time.Time) error
+	RequirePasswordReset(ctx context.Context, id uint) error
 
 	LogAccountActivity(ctx context.Context, accountID uint, activity string) error
+	// LogAccountActivityDetails logs an activity with its details
+	LogAccountActivityDetails(ctx context.Context, accountID uint, activity string, details map[string]any) error
+	// ListAccountActivities returns the most recent activities first, a negative limit returns all of them
+	ListAccountActivities(ctx context.Context, accountID uint, limit int) ([]AccountActivity, error)
+	// ListActivities returns a page of the activities matching the filter, the most recent first,
+	// with the number of matching activities
+	ListActivities(ctx context.Context, filter ActivityFilter) ([]AccountActivity, int64, error)
+
+	CreateRefreshToken(ctx context.Context, token *RefreshToken) (*RefreshToken, error)
+	GetRefreshTokenByHash(ctx context.Context, hash string) (*RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, current *RefreshToken, next *RefreshToken) (*RefreshToken, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+
+	CreateSession(ctx context.Context, session *Session) (*Session, error)
+	GetSessionByID(ctx context.Context, id uint) (*Session, error)
+	SetSessionOrganization(ctx context.Context, id uint, organizationID uint) error
+	ListActiveSessions(ctx context.Context, accountID uint) ([]Session, error)
+	// ListSessions returns every session of the account, revoked and expired ones included
+	ListSessions(ctx context.Context, accountID uint) ([]Session, error)
+	// IsNewDevice reports whether the account has sessions, none of them from the ip address
+	// with the user agent. The first device of an account is not new.
+	IsNewDevice(ctx context.Context, accountID uint, ipAddress string, userAgent string) (bool, error)
+	// ForgetSession deletes the session, its device is no longer known
+	ForgetSession(ctx context.Context, id uint) error
+	TouchSession(ctx context.Context, id uint, seenAt time.Time) error
+	RevokeSession(ctx context.Context, id uint) error
+	RevokeAllSessions(ctx context.Context, accountID uint) error
+
+	ConsumeTOTPStep(ctx context.Context, accountID uint, step int64) error
+	ReplaceRecoveryCodes(ctx context.Context, accountID uint, codes []RecoveryCode) error
+	UseRecoveryCode(ctx context.Context, accountID uint, hash string) error
+	DeleteRecoveryCodes(ctx context.Context, accountID uint) error
+
+	CreateOneTimeToken(ctx context.Context, token *OneTimeToken) (*OneTimeToken, error)
+	ConsumeOneTimeToken(ctx context.Context, purpose string, hash string) (*OneTimeToken, error)
+	RevokeOneTimeTokens(ctx context.Context, accountID uint, purpose string) error
+	CountOneTimeTokensSince(ctx context.Context, accountID uint, purpose string, since time.Time) (int64, error)
+	// ChangeAccountEmail sets the confirmed email and revokes the cancel links of the change,
+	// failing with ErrEmailTaken when another account, deleted or not, holds the email
+	ChangeAccountEmail(ctx context.Context, accountID uint, email string) error
+
+	CreateAPIKey(ctx context.Context, key *APIKey) (*APIKey, error)
+	// GetAPIKeyByPrefix returns the key with its account, the account is nil once deleted
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*APIKey, error)
+	ListAPIKeys(ctx context.Context, accountID uint) ([]APIKey, error)
+	TouchAPIKey(ctx context.Context, id uint, usedAt time.Time) error
+	RevokeAPIKey(ctx context.Context, accountID uint, id uint) error
+
+	CreateOAuthState(ctx context.Context, state *OAuthState) (*OAuthState, error)
+	// ConsumeOAuthState marks the state as used and returns it, failing with ErrInvalidOAuthState
+	// when it is unknown, used, expired or issued for another provider
+	ConsumeOAuthState(ctx context.Context, provider string, hash string) (*OAuthState, error)
+	CreateIdentity(ctx context.Context, identity *Identity) (*Identity, error)
+	GetIdentity(ctx context.Context, provider string, subject string) (*Identity, error)
+	ListIdentities(ctx context.Context, accountID uint) ([]Identity, error)
+	RecordIdentityLogin(ctx context.Context, id uint, loginAt time.Time) error
+	DeleteIdentity(ctx context.Context, accountID uint, id uint) error
+
+	CreateOAuthClient(ctx context.Context, client *OAuthClient) (*OAuthClient, error)
+	GetOAuthClient(ctx context.Context, clientID string) (*OAuthClient, error)
+	ListOAuthClients(ctx context.Context) ([]OAuthClient, error)
+	DeleteOAuthClient(ctx context.Context, clientID string) error
+	CreateAuthorizationCode(ctx context.Context, code *AuthorizationCode) (*AuthorizationCode, error)
+	// ConsumeAuthorizationCode marks the code as used and returns it, failing with
+	// ErrInvalidAuthorizationCode when it is unknown, used or expired
+	ConsumeAuthorizationCode(ctx context.Context, hash string) (*AuthorizationCode, error)
+	GetOAuthConsent(ctx context.Context, accountID uint, clientID string) (*OAuthConsent, error)
+	SaveOAuthConsent(ctx context.Context, consent *OAuthConsent) error
+
+	CreateWebAuthnChallenge(ctx context.Context, challenge *WebAuthnChallenge) (*WebAuthnChallenge, error)
+	// ConsumeWebAuthnChallenge marks the challenge as used and returns it, failing with
+	// ErrInvalidWebAuthnChallenge when it is unknown, used, expired or issued for another purpose
+	ConsumeWebAuthnChallenge(ctx context.Context, purpose string, hash string) (*WebAuthnChallenge, error)
+	// CreateWebAuthnCredential stores the passkey and sets WebAuthnEnabled on its account
+	CreateWebAuthnCredential(ctx context.Context, credential *WebAuthnCredential) (*WebAuthnCredential, error)
+	GetWebAuthnCredential(ctx context.Context, credentialID string) (*WebAuthnCredential, error)
+	ListWebAuthnCredentials(ctx context.Context, accountID uint) ([]WebAuthnCredential, error)
+	// RecordWebAuthnCredentialUse stores the sign count of a login, failing with ErrWebAuthnSignCount
+	// when a concurrent login already stored a count at least as high
+	RecordWebAuthnCredentialUse(ctx context.Context, id uint, signCount uint32, usedAt time.Time) error
+	// DeleteWebAuthnCredential deletes the passkey and clears WebAuthnEnabled with the last one
+	DeleteWebAuthnCredential(ctx context.Context, accountID uint, id uint) error
+
+	RecordLoginFailure(ctx context.Context, failure *LoginFailure) (int, error)
+	CountLoginFailuresByIP(ctx context.Context, ipAddress string, since time.Time) (int64, error)
+	LockAccount(ctx context.Context, accountID uint, until time.Time) error
+	UnlockAccount(ctx context.Context, accountID uint) error
+}
+
+type TokenRevocationRepository interface {
+	RevokeToken(ctx context.Context, tokenID string, accountID uint, expiresAt time.Time) error
+	RevokeAllTokens(ctx context.Context, accountID uint, before time.Time) error
+	IsTokenRevoked(ctx context.Context, claims *AuthClaims) (bool, error)
 }
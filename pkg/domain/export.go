@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// an export is built once in the background, then downloadable until it expires
+const (
+	DataExportStatusPending    = "pending"
+	DataExportStatusProcessing = "processing"
+	DataExportStatusReady      = "ready"
+	DataExportStatusFailed     = "failed"
+)
+
+var (
+	ActivityDataExportRequested  = "data_export_requested"
+	ActivityDataExportDownloaded = "data_export_downloaded"
+)
+
+var (
+	ErrDataExportInProgress   = errors.New("a data export is already in progress")
+	ErrDataExportNotClaimable = errors.New("data export is not claimable")
+	ErrDataExportNotReady     = errors.New("data export is not ready")
+)
+
+// DataExport is an archive of everything stored about an account, requested by its owner
+type DataExport struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	AccountID uint   `json:"account_id" gorm:"index"`
+	Status    string `json:"status" gorm:"index"`
+	// Archive is the zip archive with a json file per exporter, it is only loaded to be downloaded
+	Archive     []byte     `json:"-"`
+	Size        int64      `json:"size"`
+	CompletedAt *time.Time `json:"completed_at"`
+	// ExpiresAt is when the export and its download link expire, set once it is built or failed
+	ExpiresAt *time.Time `json:"expires_at" gorm:"index"`
+}
+
+// Exporter returns the data a module stores about an account, every module storing personal
+// data registers one so its data is part of the account's export
+type Exporter interface {
+	// Name names the file of the data in the archive, it is unique among the exporters
+	Name() string
+	// Export returns the data of the account, it is encoded as json
+	Export(ctx context.Context, accountID uint) (any, error)
+}
+
+type ExportRepository interface {
+	// CreateDataExport creates a pending export, failing with ErrDataExportInProgress while
+	// another export of the account is pending or processing
+	CreateDataExport(ctx context.Context, export *DataExport) (*DataExport, error)
+	// GetDataExport returns the export with its archive
+	GetDataExport(ctx context.Context, id uint) (*DataExport, error)
+	// GetAccountDataExport returns an export of the account without its archive
+	GetAccountDataExport(ctx context.Context, accountID uint, id uint) (*DataExport, error)
+	// ListClaimableDataExports returns the pending exports, and the processing ones not
+	// touched since staleBefore whose worker is assumed lost, oldest first
+	ListClaimableDataExports(ctx context.Context, staleBefore time.Time, limit int) ([]uint, error)
+	// ClaimDataExport marks a claimable export processing for the caller, failing with
+	// ErrDataExportNotClaimable when another worker claimed it in the meantime
+	ClaimDataExport(ctx context.Context, id uint, staleBefore time.Time) (*DataExport, error)
+	CompleteDataExport(ctx context.Context, id uint, archive []byte, completedAt time.Time, expiresAt time.Time) error
+	FailDataExport(ctx context.Context, id uint, completedAt time.Time, expiresAt time.Time) error
+	// DeleteExpiredDataExports hard deletes the expired exports with their archive
+	DeleteExpiredDataExports(ctx context.Context, now time.Time) (int64, error)
+}
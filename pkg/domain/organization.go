@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"go_starter_api/pkg/tenant"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// the role of an account within an organization, separate from its global roles
+const (
+	OrganizationRoleOwner  = "owner"
+	OrganizationRoleAdmin  = "admin"
+	OrganizationRoleMember = "member"
+)
+
+var (
+	ActivityOrganizationCreated       = "organization_created"
+	ActivityOrganizationSwitched      = "organization_switched"
+	ActivityOrganizationMemberRemoved = "organization_member_removed"
+	ActivityInvitationSent            = "invitation_sent"
+	ActivityInvitationRevoked         = "invitation_revoked"
+	ActivityInvitationAccepted        = "invitation_accepted"
+)
+
+// only pending invitations can be answered, expired is derived from ExpiresAt and never stored
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusDeclined = "declined"
+	InvitationStatusRevoked  = "revoked"
+	InvitationStatusExpired  = "expired"
+)
+
+var (
+	ErrLastOrganizationOwner = errors.New("organization must keep an owner")
+	ErrInvitationNotPending  = errors.New("invitation is not pending")
+)
+
+// Organization is a workspace shared by its members
+type Organization struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	Name string `json:"name"`
+}
+
+// Membership gives an account a role in an organization
+type Membership struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	OrganizationID uint   `json:"organization_id" gorm:"uniqueIndex:idx_membership"`
+	AccountID      uint   `json:"account_id" gorm:"uniqueIndex:idx_membership;index"`
+	Role           string `json:"role"`
+
+	Organization *Organization `json:"organization,omitempty"`
+	Account      *Account      `json:"-"`
+}
+
+// CanManageMembers reports whether the member may remove other members and manage invitations
+func (m *Membership) CanManageMembers() bool {
+	return m.Role == OrganizationRoleOwner || m.Role == OrganizationRoleAdmin
+}
+
+// Invitation asks the owner of an email to join an organization, it is answered through a signed link
+type Invitation struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	tenant.Model
+
+	Email       string     `json:"email" gorm:"index"`
+	Role        string     `json:"role"`
+	InviterID   uint       `json:"inviter_id"`
+	Status      string     `json:"status" gorm:"index"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	RespondedAt *time.Time `json:"responded_at"`
+	// AccountID is the account that accepted the invitation
+	AccountID *uint `json:"account_id"`
+}
+
+// StatusAt returns the status of the invitation at the given time
+func (i *Invitation) StatusAt(now time.Time) string {
+	if i.Status == InvitationStatusPending && !now.Before(i.ExpiresAt) {
+		return InvitationStatusExpired
+	}
+	return i.Status
+}
+
+type OrganizationRepository interface {
+	// CreateOrganization creates the organization with the account as its owner
+	CreateOrganization(ctx context.Context, organization *Organization, ownerID uint) (*Organization, error)
+	GetOrganizationByID(ctx context.Context, id uint) (*Organization, error)
+	GetMembership(ctx context.Context, organizationID uint, accountID uint) (*Membership, error)
+	// ListAccountMemberships returns the memberships of the account with their organization
+	ListAccountMemberships(ctx context.Context, accountID uint) ([]Membership, error)
+	// ListMembers returns the memberships of the organization with their account
+	ListMembers(ctx context.Context, organizationID uint) ([]Membership, error)
+	// RemoveMember deletes the membership and moves the account's sessions out of the organization,
+	// removing the last owner fails with ErrLastOrganizationOwner
+	RemoveMember(ctx context.Context, organizationID uint, accountID uint) error
+
+	// CreateInvitation creates the invitation in the organization of the context,
+	// revoking the pending invitation of the same email
+	CreateInvitation(ctx context.Context, invitation *Invitation) (*Invitation, error)
+	// ListPendingInvitations returns the unexpired pending invitations of the organization of the context
+	ListPendingInvitations(ctx context.Context) ([]Invitation, error)
+	// RevokeInvitation revokes a pending invitation of the organization of the context
+	RevokeInvitation(ctx context.Context, id uint) error
+	// GetInvitationByID looks across organizations, the caller must hold a token for the invitation
+	GetInvitationByID(ctx context.Context, id uint) (*Invitation, error)
+	// AcceptInvitation marks the pending invitation accepted and adds the account to the organization,
+	// fails with ErrInvitationNotPending when it was answered or revoked in the meantime
+	AcceptInvitation(ctx context.Context, invitation *Invitation, accountID uint) error
+	DeclineInvitation(ctx context.Context, id uint) error
+}
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// permissions are named <resource>:<action>, "<resource>:*" grants every action on the
+// resource and PermissionAll grants everything
+const (
+	PermissionAll = "*"
+
+	PermissionAccountsRead  = "accounts:read"
+	PermissionAccountsWrite = "accounts:write"
+	PermissionRolesRead     = "roles:read"
+	PermissionRolesWrite    = "roles:write"
+
+	PermissionOAuthClientsRead  = "oauth_clients:read"
+	PermissionOAuthClientsWrite = "oauth_clients:write"
+)
+
+// RoleAdmin is seeded on startup with every permission
+const RoleAdmin = "admin"
+
+var (
+	ActivityRoleGranted = "role_granted"
+	ActivityRoleRevoked = "role_revoked"
+)
+
+type Role struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	Name        string           `json:"name" gorm:"uniqueIndex"`
+	Description string           `json:"description"`
+	Permissions []RolePermission `json:"permissions"`
+}
+
+type RolePermission struct {
+	ID        uint           `json:"-" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"-" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"-" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	RoleID     uint   `json:"-" gorm:"uniqueIndex:idx_role_permission"`
+	Permission string `json:"permission" gorm:"uniqueIndex:idx_role_permission"`
+}
+
+// AccountRole assigns a role to an account
+type AccountRole struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	AccountID uint `json:"account_id" gorm:"uniqueIndex:idx_account_role"`
+	RoleID    uint `json:"role_id" gorm:"uniqueIndex:idx_account_role"`
+}
+
+// HasPermission reports whether the granted permissions include the permission, directly or by wildcard
+func HasPermission(granted []string, permission string) bool {
+	resource, _, _ := strings.Cut(permission, ":")
+	for _, p := range granted {
+		if p == PermissionAll || p == permission || p == resource+":*" {
+			return true
+		}
+	}
+	return false
+}
+
+type RoleRepository interface {
+	// EnsureRole creates the role when it does not exist and adds the permissions it is missing
+	EnsureRole(ctx context.Context, name string, description string, permissions []string) (*Role, error)
+	GetRoleByName(ctx context.Context, name string) (*Role, error)
+	ListRoles(ctx context.Context) ([]Role, error)
+
+	AssignRole(ctx context.Context, accountID uint, roleID uint) error
+	UnassignRole(ctx context.Context, accountID uint, roleID uint) error
+	ListAccountRoles(ctx context.Context, accountID uint) ([]Role, error)
+	GetAccountPermissions(ctx context.Context, accountID uint) ([]string, error)
+}
@@ -6,6 +6,7 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -109,17 +110,18 @@ func (_c *MockAccountService_ComparePassword_Call) RunAndReturn(run func(ctx con
 	return _c
 }
 
-// GenerateAuthToken provides a mock function for the type MockAccountService
-func (_mock *MockAccountService) GenerateAuthToken(ctx context.Context, account *Account) (string, error) {
+// GenerateAPIKey provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) GenerateAPIKey(ctx context.Context, account *Account) (string, *APIKey, error) {
 	ret := _mock.Called(ctx, account)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GenerateAuthToken")
+		panic("no return value specified for GenerateAPIKey")
 	}
 
 	var r0 string
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account) (string, error)); ok {
+	var r1 *APIKey
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account) (string, *APIKey, error)); ok {
 		return returnFunc(ctx, account)
 	}
 	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account) string); ok {
@@ -127,27 +129,34 @@ func (_mock *MockAccountService) GenerateAuthToken(ctx context.Context, account
 	} else {
 		r0 = ret.Get(0).(string)
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, *Account) error); ok {
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *Account) *APIKey); ok {
 		r1 = returnFunc(ctx, account)
 	} else {
-		r1 = ret.Error(1)
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*APIKey)
+		}
 	}
-	return r0, r1
+	if returnFunc, ok := ret.Get(2).(func(context.Context, *Account) error); ok {
+		r2 = returnFunc(ctx, account)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
 }
 
-// MockAccountService_GenerateAuthToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GenerateAuthToken'
-type MockAccountService_GenerateAuthToken_Call struct {
+// MockAccountService_GenerateAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GenerateAPIKey'
+type MockAccountService_GenerateAPIKey_Call struct {
 	*mock.Call
 }
 
-// GenerateAuthToken is a helper method to define mock.On call
+// GenerateAPIKey is a helper method to define mock.On call
 //   - ctx context.Context
 //   - account *Account
-func (_e *MockAccountService_Expecter) GenerateAuthToken(ctx interface{}, account interface{}) *MockAccountService_GenerateAuthToken_Call {
-	return &MockAccountService_GenerateAuthToken_Call{Call: _e.mock.On("GenerateAuthToken", ctx, account)}
+func (_e *MockAccountService_Expecter) GenerateAPIKey(ctx interface{}, account interface{}) *MockAccountService_GenerateAPIKey_Call {
+	return &MockAccountService_GenerateAPIKey_Call{Call: _e.mock.On("GenerateAPIKey", ctx, account)}
 }
 
-func (_c *MockAccountService_GenerateAuthToken_Call) Run(run func(ctx context.Context, account *Account)) *MockAccountService_GenerateAuthToken_Call {
+func (_c *MockAccountService_GenerateAPIKey_Call) Run(run func(ctx context.Context, account *Account)) *MockAccountService_GenerateAPIKey_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -165,55 +174,57 @@ func (_c *MockAccountService_GenerateAuthToken_Call) Run(run func(ctx context.Co
 	return _c
 }
 
-func (_c *MockAccountService_GenerateAuthToken_Call) Return(s string, err error) *MockAccountService_GenerateAuthToken_Call {
-	_c.Call.Return(s, err)
+func (_c *MockAccountService_GenerateAPIKey_Call) Return(s string, aPIKey *APIKey, err error) *MockAccountService_GenerateAPIKey_Call {
+	_c.Call.Return(s, aPIKey, err)
 	return _c
 }
 
-func (_c *MockAccountService_GenerateAuthToken_Call) RunAndReturn(run func(ctx context.Context, account *Account) (string, error)) *MockAccountService_GenerateAuthToken_Call {
+func (_c *MockAccountService_GenerateAPIKey_Call) RunAndReturn(run func(ctx context.Context, account *Account) (string, *APIKey, error)) *MockAccountService_GenerateAPIKey_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GeneratePasswordResetToken provides a mock function for the type MockAccountService
-func (_mock *MockAccountService) GeneratePasswordResetToken(ctx context.Context, account *Account) (string, error) {
-	ret := _mock.Called(ctx, account)
+// GenerateAuthToken provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) GenerateAuthToken(ctx context.Context, account *Account, sessionID uint, organizationID uint) (string, error) {
+	ret := _mock.Called(ctx, account, sessionID, organizationID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GeneratePasswordResetToken")
+		panic("no return value specified for GenerateAuthToken")
 	}
 
 	var r0 string
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account) (string, error)); ok {
-		return returnFunc(ctx, account)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account, uint, uint) (string, error)); ok {
+		return returnFunc(ctx, account, sessionID, organizationID)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account) string); ok {
-		r0 = returnFunc(ctx, account)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account, uint, uint) string); ok {
+		r0 = returnFunc(ctx, account, sessionID, organizationID)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, *Account) error); ok {
-		r1 = returnFunc(ctx, account)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *Account, uint, uint) error); ok {
+		r1 = returnFunc(ctx, account, sessionID, organizationID)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockAccountService_GeneratePasswordResetToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GeneratePasswordResetToken'
-type MockAccountService_GeneratePasswordResetToken_Call struct {
+// MockAccountService_GenerateAuthToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GenerateAuthToken'
+type MockAccountService_GenerateAuthToken_Call struct {
 	*mock.Call
 }
 
-// GeneratePasswordResetToken is a helper method to define mock.On call
+// GenerateAuthToken is a helper method to define mock.On call
 //   - ctx context.Context
 //   - account *Account
-func (_e *MockAccountService_Expecter) GeneratePasswordResetToken(ctx interface{}, account interface{}) *MockAccountService_GeneratePasswordResetToken_Call {
-	return &MockAccountService_GeneratePasswordResetToken_Call{Call: _e.mock.On("GeneratePasswordResetToken", ctx, account)}
+//   - sessionID uint
+//   - organizationID uint
+func (_e *MockAccountService_Expecter) GenerateAuthToken(ctx interface{}, account interface{}, sessionID interface{}, organizationID interface{}) *MockAccountService_GenerateAuthToken_Call {
+	return &MockAccountService_GenerateAuthToken_Call{Call: _e.mock.On("GenerateAuthToken", ctx, account, sessionID, organizationID)}
 }
 
-func (_c *MockAccountService_GeneratePasswordResetToken_Call) Run(run func(ctx context.Context, account *Account)) *MockAccountService_GeneratePasswordResetToken_Call {
+func (_c *MockAccountService_GenerateAuthToken_Call) Run(run func(ctx context.Context, account *Account, sessionID uint, organizationID uint)) *MockAccountService_GenerateAuthToken_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -223,71 +234,81 @@ func (_c *MockAccountService_GeneratePasswordResetToken_Call) Run(run func(ctx c
 		if args[1] != nil {
 			arg1 = args[1].(*Account)
 		}
+		var arg2 uint
+		if args[2] != nil {
+			arg2 = args[2].(uint)
+		}
+		var arg3 uint
+		if args[3] != nil {
+			arg3 = args[3].(uint)
+		}
 		run(
 			arg0,
 			arg1,
+			arg2,
+			arg3,
 		)
 	})
 	return _c
 }
 
-func (_c *MockAccountService_GeneratePasswordResetToken_Call) Return(s string, err error) *MockAccountService_GeneratePasswordResetToken_Call {
+func (_c *MockAccountService_GenerateAuthToken_Call) Return(s string, err error) *MockAccountService_GenerateAuthToken_Call {
 	_c.Call.Return(s, err)
 	return _c
 }
 
-func (_c *MockAccountService_GeneratePasswordResetToken_Call) RunAndReturn(run func(ctx context.Context, account *Account) (string, error)) *MockAccountService_GeneratePasswordResetToken_Call {
+func (_c *MockAccountService_GenerateAuthToken_Call) RunAndReturn(run func(ctx context.Context, account *Account, sessionID uint, organizationID uint) (string, error)) *MockAccountService_GenerateAuthToken_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// HashPassword provides a mock function for the type MockAccountService
-func (_mock *MockAccountService) HashPassword(ctx context.Context, password string) (string, error) {
-	ret := _mock.Called(ctx, password)
+// GenerateDataExportToken provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) GenerateDataExportToken(ctx context.Context, export *DataExport) (string, error) {
+	ret := _mock.Called(ctx, export)
 
 	if len(ret) == 0 {
-		panic("no return value specified for HashPassword")
+		panic("no return value specified for GenerateDataExportToken")
 	}
 
 	var r0 string
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
-		return returnFunc(ctx, password)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *DataExport) (string, error)); ok {
+		return returnFunc(ctx, export)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) string); ok {
-		r0 = returnFunc(ctx, password)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *DataExport) string); ok {
+		r0 = returnFunc(ctx, export)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = returnFunc(ctx, password)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *DataExport) error); ok {
+		r1 = returnFunc(ctx, export)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockAccountService_HashPassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HashPassword'
-type MockAccountService_HashPassword_Call struct {
+// MockAccountService_GenerateDataExportToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GenerateDataExportToken'
+type MockAccountService_GenerateDataExportToken_Call struct {
 	*mock.Call
 }
 
-// HashPassword is a helper method to define mock.On call
+// GenerateDataExportToken is a helper method to define mock.On call
 //   - ctx context.Context
-//   - password string
-func (_e *MockAccountService_Expecter) HashPassword(ctx interface{}, password interface{}) *MockAccountService_HashPassword_Call {
-	return &MockAccountService_HashPassword_Call{Call: _e.mock.On("HashPassword", ctx, password)}
+//   - export *DataExport
+func (_e *MockAccountService_Expecter) GenerateDataExportToken(ctx interface{}, export interface{}) *MockAccountService_GenerateDataExportToken_Call {
+	return &MockAccountService_GenerateDataExportToken_Call{Call: _e.mock.On("GenerateDataExportToken", ctx, export)}
 }
 
-func (_c *MockAccountService_HashPassword_Call) Run(run func(ctx context.Context, password string)) *MockAccountService_HashPassword_Call {
+func (_c *MockAccountService_GenerateDataExportToken_Call) Run(run func(ctx context.Context, export *DataExport)) *MockAccountService_GenerateDataExportToken_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 string
+		var arg1 *DataExport
 		if args[1] != nil {
-			arg1 = args[1].(string)
+			arg1 = args[1].(*DataExport)
 		}
 		run(
 			arg0,
@@ -297,192 +318,213 @@ func (_c *MockAccountService_HashPassword_Call) Run(run func(ctx context.Context
 	return _c
 }
 
-func (_c *MockAccountService_HashPassword_Call) Return(s string, err error) *MockAccountService_HashPassword_Call {
+func (_c *MockAccountService_GenerateDataExportToken_Call) Return(s string, err error) *MockAccountService_GenerateDataExportToken_Call {
 	_c.Call.Return(s, err)
 	return _c
 }
 
-func (_c *MockAccountService_HashPassword_Call) RunAndReturn(run func(ctx context.Context, password string) (string, error)) *MockAccountService_HashPassword_Call {
+func (_c *MockAccountService_GenerateDataExportToken_Call) RunAndReturn(run func(ctx context.Context, export *DataExport) (string, error)) *MockAccountService_GenerateDataExportToken_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// SendPasswordResetEmail provides a mock function for the type MockAccountService
-func (_mock *MockAccountService) SendPasswordResetEmail(ctx context.Context, email string, token string) error {
-	ret := _mock.Called(ctx, email, token)
+// GenerateEmailVerificationToken provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) GenerateEmailVerificationToken(ctx context.Context, account *Account) (string, error) {
+	ret := _mock.Called(ctx, account)
 
 	if len(ret) == 0 {
-		panic("no return value specified for SendPasswordResetEmail")
+		panic("no return value specified for GenerateEmailVerificationToken")
 	}
 
-	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
-		r0 = returnFunc(ctx, email, token)
+	var r0 string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account) (string, error)); ok {
+		return returnFunc(ctx, account)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account) string); ok {
+		r0 = returnFunc(ctx, account)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(string)
 	}
-	return r0
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *Account) error); ok {
+		r1 = returnFunc(ctx, account)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
 }
 
-// MockAccountService_SendPasswordResetEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendPasswordResetEmail'
-type MockAccountService_SendPasswordResetEmail_Call struct {
+// MockAccountService_GenerateEmailVerificationToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GenerateEmailVerificationToken'
+type MockAccountService_GenerateEmailVerificationToken_Call struct {
 	*mock.Call
 }
 
-// SendPasswordResetEmail is a helper method to define mock.On call
+// GenerateEmailVerificationToken is a helper method to define mock.On call
 //   - ctx context.Context
-//   - email string
-//   - token string
-func (_e *MockAccountService_Expecter) SendPasswordResetEmail(ctx interface{}, email interface{}, token interface{}) *MockAccountService_SendPasswordResetEmail_Call {
-	return &MockAccountService_SendPasswordResetEmail_Call{Call: _e.mock.On("SendPasswordResetEmail", ctx, email, token)}
+//   - account *Account
+func (_e *MockAccountService_Expecter) GenerateEmailVerificationToken(ctx interface{}, account interface{}) *MockAccountService_GenerateEmailVerificationToken_Call {
+	return &MockAccountService_GenerateEmailVerificationToken_Call{Call: _e.mock.On("GenerateEmailVerificationToken", ctx, account)}
 }
 
-func (_c *MockAccountService_SendPasswordResetEmail_Call) Run(run func(ctx context.Context, email string, token string)) *MockAccountService_SendPasswordResetEmail_Call {
+func (_c *MockAccountService_GenerateEmailVerificationToken_Call) Run(run func(ctx context.Context, account *Account)) *MockAccountService_GenerateEmailVerificationToken_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 string
+		var arg1 *Account
 		if args[1] != nil {
-			arg1 = args[1].(string)
-		}
-		var arg2 string
-		if args[2] != nil {
-			arg2 = args[2].(string)
+			arg1 = args[1].(*Account)
 		}
 		run(
 			arg0,
 			arg1,
-			arg2,
 		)
 	})
 	return _c
 }
 
-func (_c *MockAccountService_SendPasswordResetEmail_Call) Return(err error) *MockAccountService_SendPasswordResetEmail_Call {
-	_c.Call.Return(err)
+func (_c *MockAccountService_GenerateEmailVerificationToken_Call) Return(s string, err error) *MockAccountService_GenerateEmailVerificationToken_Call {
+	_c.Call.Return(s, err)
 	return _c
 }
 
-func (_c *MockAccountService_SendPasswordResetEmail_Call) RunAndReturn(run func(ctx context.Context, email string, token string) error) *MockAccountService_SendPasswordResetEmail_Call {
+func (_c *MockAccountService_GenerateEmailVerificationToken_Call) RunAndReturn(run func(ctx context.Context, account *Account) (string, error)) *MockAccountService_GenerateEmailVerificationToken_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ValidateAuthToken provides a mock function for the type MockAccountService
-func (_mock *MockAccountService) ValidateAuthToken(ctx context.Context, token string) (uint, error) {
-	ret := _mock.Called(ctx, token)
+// GenerateIDToken provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) GenerateIDToken(ctx context.Context, account *Account, clientID string, nonce string, scopes []string) (string, error) {
+	ret := _mock.Called(ctx, account, clientID, nonce, scopes)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ValidateAuthToken")
+		panic("no return value specified for GenerateIDToken")
 	}
 
-	var r0 uint
+	var r0 string
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (uint, error)); ok {
-		return returnFunc(ctx, token)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account, string, string, []string) (string, error)); ok {
+		return returnFunc(ctx, account, clientID, nonce, scopes)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) uint); ok {
-		r0 = returnFunc(ctx, token)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account, string, string, []string) string); ok {
+		r0 = returnFunc(ctx, account, clientID, nonce, scopes)
 	} else {
-		r0 = ret.Get(0).(uint)
+		r0 = ret.Get(0).(string)
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = returnFunc(ctx, token)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *Account, string, string, []string) error); ok {
+		r1 = returnFunc(ctx, account, clientID, nonce, scopes)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockAccountService_ValidateAuthToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ValidateAuthToken'
-type MockAccountService_ValidateAuthToken_Call struct {
+// MockAccountService_GenerateIDToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GenerateIDToken'
+type MockAccountService_GenerateIDToken_Call struct {
 	*mock.Call
 }
 
-// ValidateAuthToken is a helper method to define mock.On call
+// GenerateIDToken is a helper method to define mock.On call
 //   - ctx context.Context
-//   - token string
-func (_e *MockAccountService_Expecter) ValidateAuthToken(ctx interface{}, token interface{}) *MockAccountService_ValidateAuthToken_Call {
-	return &MockAccountService_ValidateAuthToken_Call{Call: _e.mock.On("ValidateAuthToken", ctx, token)}
+//   - account *Account
+//   - clientID string
+//   - nonce string
+//   - scopes []string
+func (_e *MockAccountService_Expecter) GenerateIDToken(ctx interface{}, account interface{}, clientID interface{}, nonce interface{}, scopes interface{}) *MockAccountService_GenerateIDToken_Call {
+	return &MockAccountService_GenerateIDToken_Call{Call: _e.mock.On("GenerateIDToken", ctx, account, clientID, nonce, scopes)}
 }
 
-func (_c *MockAccountService_ValidateAuthToken_Call) Run(run func(ctx context.Context, token string)) *MockAccountService_ValidateAuthToken_Call {
+func (_c *MockAccountService_GenerateIDToken_Call) Run(run func(ctx context.Context, account *Account, clientID string, nonce string, scopes []string)) *MockAccountService_GenerateIDToken_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 string
+		var arg1 *Account
 		if args[1] != nil {
-			arg1 = args[1].(string)
+			arg1 = args[1].(*Account)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		var arg4 []string
+		if args[4] != nil {
+			arg4 = args[4].([]string)
 		}
 		run(
 			arg0,
 			arg1,
+			arg2,
+			arg3,
+			arg4,
 		)
 	})
 	return _c
 }
 
-func (_c *MockAccountService_ValidateAuthToken_Call) Return(v uint, err error) *MockAccountService_ValidateAuthToken_Call {
-	_c.Call.Return(v, err)
+func (_c *MockAccountService_GenerateIDToken_Call) Return(s string, err error) *MockAccountService_GenerateIDToken_Call {
+	_c.Call.Return(s, err)
 	return _c
 }
 
-func (_c *MockAccountService_ValidateAuthToken_Call) RunAndReturn(run func(ctx context.Context, token string) (uint, error)) *MockAccountService_ValidateAuthToken_Call {
+func (_c *MockAccountService_GenerateIDToken_Call) RunAndReturn(run func(ctx context.Context, account *Account, clientID string, nonce string, scopes []string) (string, error)) *MockAccountService_GenerateIDToken_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ValidatePasswordResetToken provides a mock function for the type MockAccountService
-func (_mock *MockAccountService) ValidatePasswordResetToken(ctx context.Context, token string) (uint, error) {
-	ret := _mock.Called(ctx, token)
+// GenerateInvitationToken provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) GenerateInvitationToken(ctx context.Context, invitation *Invitation) (string, error) {
+	ret := _mock.Called(ctx, invitation)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ValidatePasswordResetToken")
+		panic("no return value specified for GenerateInvitationToken")
 	}
 
-	var r0 uint
+	var r0 string
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (uint, error)); ok {
-		return returnFunc(ctx, token)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Invitation) (string, error)); ok {
+		return returnFunc(ctx, invitation)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) uint); ok {
-		r0 = returnFunc(ctx, token)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Invitation) string); ok {
+		r0 = returnFunc(ctx, invitation)
 	} else {
-		r0 = ret.Get(0).(uint)
+		r0 = ret.Get(0).(string)
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = returnFunc(ctx, token)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *Invitation) error); ok {
+		r1 = returnFunc(ctx, invitation)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockAccountService_ValidatePasswordResetToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ValidatePasswordResetToken'
-type MockAccountService_ValidatePasswordResetToken_Call struct {
+// MockAccountService_GenerateInvitationToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GenerateInvitationToken'
+type MockAccountService_GenerateInvitationToken_Call struct {
 	*mock.Call
 }
 
-// ValidatePasswordResetToken is a helper method to define mock.On call
+// GenerateInvitationToken is a helper method to define mock.On call
 //   - ctx context.Context
-//   - token string
-func (_e *MockAccountService_Expecter) ValidatePasswordResetToken(ctx interface{}, token interface{}) *MockAccountService_ValidatePasswordResetToken_Call {
-	return &MockAccountService_ValidatePasswordResetToken_Call{Call: _e.mock.On("ValidatePasswordResetToken", ctx, token)}
+//   - invitation *Invitation
+func (_e *MockAccountService_Expecter) GenerateInvitationToken(ctx interface{}, invitation interface{}) *MockAccountService_GenerateInvitationToken_Call {
+	return &MockAccountService_GenerateInvitationToken_Call{Call: _e.mock.On("GenerateInvitationToken", ctx, invitation)}
 }
 
-func (_c *MockAccountService_ValidatePasswordResetToken_Call) Run(run func(ctx context.Context, token string)) *MockAccountService_ValidatePasswordResetToken_Call {
+func (_c *MockAccountService_GenerateInvitationToken_Call) Run(run func(ctx context.Context, invitation *Invitation)) *MockAccountService_GenerateInvitationToken_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 string
+		var arg1 *Invitation
 		if args[1] != nil {
-			arg1 = args[1].(string)
+			arg1 = args[1].(*Invitation)
 		}
 		run(
 			arg0,
@@ -492,62 +534,33 @@ func (_c *MockAccountService_ValidatePasswordResetToken_Call) Run(run func(ctx c
 	return _c
 }
 
-func (_c *MockAccountService_ValidatePasswordResetToken_Call) Return(v uint, err error) *MockAccountService_ValidatePasswordResetToken_Call {
-	_c.Call.Return(v, err)
+func (_c *MockAccountService_GenerateInvitationToken_Call) Return(s string, err error) *MockAccountService_GenerateInvitationToken_Call {
+	_c.Call.Return(s, err)
 	return _c
 }
 
-func (_c *MockAccountService_ValidatePasswordResetToken_Call) RunAndReturn(run func(ctx context.Context, token string) (uint, error)) *MockAccountService_ValidatePasswordResetToken_Call {
+func (_c *MockAccountService_GenerateInvitationToken_Call) RunAndReturn(run func(ctx context.Context, invitation *Invitation) (string, error)) *MockAccountService_GenerateInvitationToken_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// NewMockAccountRepository creates a new instance of MockAccountRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
-// The first argument is typically a *testing.T value.
-func NewMockAccountRepository(t interface {
-	mock.TestingT
-	Cleanup(func())
-}) *MockAccountRepository {
-	mock := &MockAccountRepository{}
-	mock.Mock.Test(t)
-
-	t.Cleanup(func() { mock.AssertExpectations(t) })
-
-	return mock
-}
-
-// MockAccountRepository is an autogenerated mock type for the AccountRepository type
-type MockAccountRepository struct {
-	mock.Mock
-}
-
-type MockAccountRepository_Expecter struct {
-	mock *mock.Mock
-}
-
-func (_m *MockAccountRepository) EXPECT() *MockAccountRepository_Expecter {
-	return &MockAccountRepository_Expecter{mock: &_m.Mock}
-}
-
-// CreateAccount provides a mock function for the type MockAccountRepository
-func (_mock *MockAccountRepository) CreateAccount(ctx context.Context, account *Account) (*Account, error) {
+// GenerateMFAToken provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) GenerateMFAToken(ctx context.Context, account *Account) (string, error) {
 	ret := _mock.Called(ctx, account)
 
 	if len(ret) == 0 {
-		panic("no return value specified for CreateAccount")
+		panic("no return value specified for GenerateMFAToken")
 	}
 
-	var r0 *Account
+	var r0 string
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account) (*Account, error)); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account) (string, error)); ok {
 		return returnFunc(ctx, account)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account) *Account); ok {
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account) string); ok {
 		r0 = returnFunc(ctx, account)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*Account)
-		}
+		r0 = ret.Get(0).(string)
 	}
 	if returnFunc, ok := ret.Get(1).(func(context.Context, *Account) error); ok {
 		r1 = returnFunc(ctx, account)
@@ -557,19 +570,19 @@ func (_mock *MockAccountRepository) CreateAccount(ctx context.Context, account *
 	return r0, r1
 }
 
-// MockAccountRepository_CreateAccount_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateAccount'
-type MockAccountRepository_CreateAccount_Call struct {
+// MockAccountService_GenerateMFAToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GenerateMFAToken'
+type MockAccountService_GenerateMFAToken_Call struct {
 	*mock.Call
 }
 
-// CreateAccount is a helper method to define mock.On call
+// GenerateMFAToken is a helper method to define mock.On call
 //   - ctx context.Context
 //   - account *Account
-func (_e *MockAccountRepository_Expecter) CreateAccount(ctx interface{}, account interface{}) *MockAccountRepository_CreateAccount_Call {
-	return &MockAccountRepository_CreateAccount_Call{Call: _e.mock.On("CreateAccount", ctx, account)}
+func (_e *MockAccountService_Expecter) GenerateMFAToken(ctx interface{}, account interface{}) *MockAccountService_GenerateMFAToken_Call {
+	return &MockAccountService_GenerateMFAToken_Call{Call: _e.mock.On("GenerateMFAToken", ctx, account)}
 }
 
-func (_c *MockAccountRepository_CreateAccount_Call) Run(run func(ctx context.Context, account *Account)) *MockAccountRepository_CreateAccount_Call {
+func (_c *MockAccountService_GenerateMFAToken_Call) Run(run func(ctx context.Context, account *Account)) *MockAccountService_GenerateMFAToken_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -587,114 +600,8679 @@ func (_c *MockAccountRepository_CreateAccount_Call) Run(run func(ctx context.Con
 	return _c
 }
 
-func (_c *MockAccountRepository_CreateAccount_Call) Return(account1 *Account, err error) *MockAccountRepository_CreateAccount_Call {
-	_c.Call.Return(account1, err)
+func (_c *MockAccountService_GenerateMFAToken_Call) Return(s string, err error) *MockAccountService_GenerateMFAToken_Call {
+	_c.Call.Return(s, err)
 	return _c
 }
 
-func (_c *MockAccountRepository_CreateAccount_Call) RunAndReturn(run func(ctx context.Context, account *Account) (*Account, error)) *MockAccountRepository_CreateAccount_Call {
+func (_c *MockAccountService_GenerateMFAToken_Call) RunAndReturn(run func(ctx context.Context, account *Account) (string, error)) *MockAccountService_GenerateMFAToken_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DeleteAccount provides a mock function for the type MockAccountRepository
-func (_mock *MockAccountRepository) DeleteAccount(ctx context.Context, id uint) error {
-	ret := _mock.Called(ctx, id)
+// GenerateOAuthAccessToken provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) GenerateOAuthAccessToken(ctx context.Context, subject string, clientID string, scopes []string) (string, error) {
+	ret := _mock.Called(ctx, subject, clientID, scopes)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteAccount")
+		panic("no return value specified for GenerateOAuthAccessToken")
 	}
 
-	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) error); ok {
-		r0 = returnFunc(ctx, id)
+	var r0 string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, []string) (string, error)); ok {
+		return returnFunc(ctx, subject, clientID, scopes)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, []string) string); ok {
+		r0 = returnFunc(ctx, subject, clientID, scopes)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(string)
 	}
-	return r0
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string, []string) error); ok {
+		r1 = returnFunc(ctx, subject, clientID, scopes)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
 }
 
-// MockAccountRepository_DeleteAccount_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteAccount'
-type MockAccountRepository_DeleteAccount_Call struct {
+// MockAccountService_GenerateOAuthAccessToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GenerateOAuthAccessToken'
+type MockAccountService_GenerateOAuthAccessToken_Call struct {
 	*mock.Call
 }
 
-// DeleteAccount is a helper method to define mock.On call
+// GenerateOAuthAccessToken is a helper method to define mock.On call
 //   - ctx context.Context
-//   - id uint
-func (_e *MockAccountRepository_Expecter) DeleteAccount(ctx interface{}, id interface{}) *MockAccountRepository_DeleteAccount_Call {
-	return &MockAccountRepository_DeleteAccount_Call{Call: _e.mock.On("DeleteAccount", ctx, id)}
+//   - subject string
+//   - clientID string
+//   - scopes []string
+func (_e *MockAccountService_Expecter) GenerateOAuthAccessToken(ctx interface{}, subject interface{}, clientID interface{}, scopes interface{}) *MockAccountService_GenerateOAuthAccessToken_Call {
+	return &MockAccountService_GenerateOAuthAccessToken_Call{Call: _e.mock.On("GenerateOAuthAccessToken", ctx, subject, clientID, scopes)}
 }
 
-func (_c *MockAccountRepository_DeleteAccount_Call) Run(run func(ctx context.Context, id uint)) *MockAccountRepository_DeleteAccount_Call {
+func (_c *MockAccountService_GenerateOAuthAccessToken_Call) Run(run func(ctx context.Context, subject string, clientID string, scopes []string)) *MockAccountService_GenerateOAuthAccessToken_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 uint
+		var arg1 string
 		if args[1] != nil {
-			arg1 = args[1].(uint)
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 []string
+		if args[3] != nil {
+			arg3 = args[3].([]string)
 		}
 		run(
 			arg0,
 			arg1,
+			arg2,
+			arg3,
 		)
 	})
 	return _c
 }
 
-func (_c *MockAccountRepository_DeleteAccount_Call) Return(err error) *MockAccountRepository_DeleteAccount_Call {
-	_c.Call.Return(err)
+func (_c *MockAccountService_GenerateOAuthAccessToken_Call) Return(s string, err error) *MockAccountService_GenerateOAuthAccessToken_Call {
+	_c.Call.Return(s, err)
 	return _c
 }
 
-func (_c *MockAccountRepository_DeleteAccount_Call) RunAndReturn(run func(ctx context.Context, id uint) error) *MockAccountRepository_DeleteAccount_Call {
+func (_c *MockAccountService_GenerateOAuthAccessToken_Call) RunAndReturn(run func(ctx context.Context, subject string, clientID string, scopes []string) (string, error)) *MockAccountService_GenerateOAuthAccessToken_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetAccountByEmail provides a mock function for the type MockAccountRepository
-func (_mock *MockAccountRepository) GetAccountByEmail(ctx context.Context, email string) (*Account, error) {
-	ret := _mock.Called(ctx, email)
+// GenerateOAuthClient provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) GenerateOAuthClient(ctx context.Context, public bool) (string, *OAuthClient, error) {
+	ret := _mock.Called(ctx, public)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetAccountByEmail")
+		panic("no return value specified for GenerateOAuthClient")
 	}
 
-	var r0 *Account
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*Account, error)); ok {
-		return returnFunc(ctx, email)
+	var r0 string
+	var r1 *OAuthClient
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, bool) (string, *OAuthClient, error)); ok {
+		return returnFunc(ctx, public)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *Account); ok {
-		r0 = returnFunc(ctx, email)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, bool) string); ok {
+		r0 = returnFunc(ctx, public)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*Account)
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, bool) *OAuthClient); ok {
+		r1 = returnFunc(ctx, public)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*OAuthClient)
 		}
 	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, bool) error); ok {
+		r2 = returnFunc(ctx, public)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockAccountService_GenerateOAuthClient_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GenerateOAuthClient'
+type MockAccountService_GenerateOAuthClient_Call struct {
+	*mock.Call
+}
+
+// GenerateOAuthClient is a helper method to define mock.On call
+//   - ctx context.Context
+//   - public bool
+func (_e *MockAccountService_Expecter) GenerateOAuthClient(ctx interface{}, public interface{}) *MockAccountService_GenerateOAuthClient_Call {
+	return &MockAccountService_GenerateOAuthClient_Call{Call: _e.mock.On("GenerateOAuthClient", ctx, public)}
+}
+
+func (_c *MockAccountService_GenerateOAuthClient_Call) Run(run func(ctx context.Context, public bool)) *MockAccountService_GenerateOAuthClient_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 bool
+		if args[1] != nil {
+			arg1 = args[1].(bool)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_GenerateOAuthClient_Call) Return(s string, oAuthClient *OAuthClient, err error) *MockAccountService_GenerateOAuthClient_Call {
+	_c.Call.Return(s, oAuthClient, err)
+	return _c
+}
+
+func (_c *MockAccountService_GenerateOAuthClient_Call) RunAndReturn(run func(ctx context.Context, public bool) (string, *OAuthClient, error)) *MockAccountService_GenerateOAuthClient_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GenerateOneTimeToken provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) GenerateOneTimeToken(ctx context.Context, account *Account, purpose string) (string, *OneTimeToken, error) {
+	ret := _mock.Called(ctx, account, purpose)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GenerateOneTimeToken")
+	}
+
+	var r0 string
+	var r1 *OneTimeToken
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account, string) (string, *OneTimeToken, error)); ok {
+		return returnFunc(ctx, account, purpose)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account, string) string); ok {
+		r0 = returnFunc(ctx, account, purpose)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *Account, string) *OneTimeToken); ok {
+		r1 = returnFunc(ctx, account, purpose)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*OneTimeToken)
+		}
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, *Account, string) error); ok {
+		r2 = returnFunc(ctx, account, purpose)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockAccountService_GenerateOneTimeToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GenerateOneTimeToken'
+type MockAccountService_GenerateOneTimeToken_Call struct {
+	*mock.Call
+}
+
+// GenerateOneTimeToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - account *Account
+//   - purpose string
+func (_e *MockAccountService_Expecter) GenerateOneTimeToken(ctx interface{}, account interface{}, purpose interface{}) *MockAccountService_GenerateOneTimeToken_Call {
+	return &MockAccountService_GenerateOneTimeToken_Call{Call: _e.mock.On("GenerateOneTimeToken", ctx, account, purpose)}
+}
+
+func (_c *MockAccountService_GenerateOneTimeToken_Call) Run(run func(ctx context.Context, account *Account, purpose string)) *MockAccountService_GenerateOneTimeToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *Account
+		if args[1] != nil {
+			arg1 = args[1].(*Account)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_GenerateOneTimeToken_Call) Return(s string, oneTimeToken *OneTimeToken, err error) *MockAccountService_GenerateOneTimeToken_Call {
+	_c.Call.Return(s, oneTimeToken, err)
+	return _c
+}
+
+func (_c *MockAccountService_GenerateOneTimeToken_Call) RunAndReturn(run func(ctx context.Context, account *Account, purpose string) (string, *OneTimeToken, error)) *MockAccountService_GenerateOneTimeToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GenerateRecoveryCodes provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) GenerateRecoveryCodes(ctx context.Context) ([]string, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GenerateRecoveryCodes")
+	}
+
+	var r0 []string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]string, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []string); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountService_GenerateRecoveryCodes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GenerateRecoveryCodes'
+type MockAccountService_GenerateRecoveryCodes_Call struct {
+	*mock.Call
+}
+
+// GenerateRecoveryCodes is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockAccountService_Expecter) GenerateRecoveryCodes(ctx interface{}) *MockAccountService_GenerateRecoveryCodes_Call {
+	return &MockAccountService_GenerateRecoveryCodes_Call{Call: _e.mock.On("GenerateRecoveryCodes", ctx)}
+}
+
+func (_c *MockAccountService_GenerateRecoveryCodes_Call) Run(run func(ctx context.Context)) *MockAccountService_GenerateRecoveryCodes_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_GenerateRecoveryCodes_Call) Return(strings []string, err error) *MockAccountService_GenerateRecoveryCodes_Call {
+	_c.Call.Return(strings, err)
+	return _c
+}
+
+func (_c *MockAccountService_GenerateRecoveryCodes_Call) RunAndReturn(run func(ctx context.Context) ([]string, error)) *MockAccountService_GenerateRecoveryCodes_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GenerateRefreshToken provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) GenerateRefreshToken(ctx context.Context, account *Account, familyID string) (string, *RefreshToken, error) {
+	ret := _mock.Called(ctx, account, familyID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GenerateRefreshToken")
+	}
+
+	var r0 string
+	var r1 *RefreshToken
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account, string) (string, *RefreshToken, error)); ok {
+		return returnFunc(ctx, account, familyID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account, string) string); ok {
+		r0 = returnFunc(ctx, account, familyID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *Account, string) *RefreshToken); ok {
+		r1 = returnFunc(ctx, account, familyID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*RefreshToken)
+		}
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, *Account, string) error); ok {
+		r2 = returnFunc(ctx, account, familyID)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockAccountService_GenerateRefreshToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GenerateRefreshToken'
+type MockAccountService_GenerateRefreshToken_Call struct {
+	*mock.Call
+}
+
+// GenerateRefreshToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - account *Account
+//   - familyID string
+func (_e *MockAccountService_Expecter) GenerateRefreshToken(ctx interface{}, account interface{}, familyID interface{}) *MockAccountService_GenerateRefreshToken_Call {
+	return &MockAccountService_GenerateRefreshToken_Call{Call: _e.mock.On("GenerateRefreshToken", ctx, account, familyID)}
+}
+
+func (_c *MockAccountService_GenerateRefreshToken_Call) Run(run func(ctx context.Context, account *Account, familyID string)) *MockAccountService_GenerateRefreshToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *Account
+		if args[1] != nil {
+			arg1 = args[1].(*Account)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_GenerateRefreshToken_Call) Return(s string, refreshToken *RefreshToken, err error) *MockAccountService_GenerateRefreshToken_Call {
+	_c.Call.Return(s, refreshToken, err)
+	return _c
+}
+
+func (_c *MockAccountService_GenerateRefreshToken_Call) RunAndReturn(run func(ctx context.Context, account *Account, familyID string) (string, *RefreshToken, error)) *MockAccountService_GenerateRefreshToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GenerateTOTPSecret provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) GenerateTOTPSecret(ctx context.Context) (string, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GenerateTOTPSecret")
+	}
+
+	var r0 string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (string, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) string); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountService_GenerateTOTPSecret_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GenerateTOTPSecret'
+type MockAccountService_GenerateTOTPSecret_Call struct {
+	*mock.Call
+}
+
+// GenerateTOTPSecret is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockAccountService_Expecter) GenerateTOTPSecret(ctx interface{}) *MockAccountService_GenerateTOTPSecret_Call {
+	return &MockAccountService_GenerateTOTPSecret_Call{Call: _e.mock.On("GenerateTOTPSecret", ctx)}
+}
+
+func (_c *MockAccountService_GenerateTOTPSecret_Call) Run(run func(ctx context.Context)) *MockAccountService_GenerateTOTPSecret_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_GenerateTOTPSecret_Call) Return(s string, err error) *MockAccountService_GenerateTOTPSecret_Call {
+	_c.Call.Return(s, err)
+	return _c
+}
+
+func (_c *MockAccountService_GenerateTOTPSecret_Call) RunAndReturn(run func(ctx context.Context) (string, error)) *MockAccountService_GenerateTOTPSecret_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// HashPassword provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) HashPassword(ctx context.Context, password string) (string, error) {
+	ret := _mock.Called(ctx, password)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HashPassword")
+	}
+
+	var r0 string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
+		return returnFunc(ctx, password)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = returnFunc(ctx, password)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
 	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = returnFunc(ctx, email)
+		r1 = returnFunc(ctx, password)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountService_HashPassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HashPassword'
+type MockAccountService_HashPassword_Call struct {
+	*mock.Call
+}
+
+// HashPassword is a helper method to define mock.On call
+//   - ctx context.Context
+//   - password string
+func (_e *MockAccountService_Expecter) HashPassword(ctx interface{}, password interface{}) *MockAccountService_HashPassword_Call {
+	return &MockAccountService_HashPassword_Call{Call: _e.mock.On("HashPassword", ctx, password)}
+}
+
+func (_c *MockAccountService_HashPassword_Call) Run(run func(ctx context.Context, password string)) *MockAccountService_HashPassword_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_HashPassword_Call) Return(s string, err error) *MockAccountService_HashPassword_Call {
+	_c.Call.Return(s, err)
+	return _c
+}
+
+func (_c *MockAccountService_HashPassword_Call) RunAndReturn(run func(ctx context.Context, password string) (string, error)) *MockAccountService_HashPassword_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// HashToken provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) HashToken(ctx context.Context, token string) string {
+	ret := _mock.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HashToken")
+	}
+
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = returnFunc(ctx, token)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	return r0
+}
+
+// MockAccountService_HashToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HashToken'
+type MockAccountService_HashToken_Call struct {
+	*mock.Call
+}
+
+// HashToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - token string
+func (_e *MockAccountService_Expecter) HashToken(ctx interface{}, token interface{}) *MockAccountService_HashToken_Call {
+	return &MockAccountService_HashToken_Call{Call: _e.mock.On("HashToken", ctx, token)}
+}
+
+func (_c *MockAccountService_HashToken_Call) Run(run func(ctx context.Context, token string)) *MockAccountService_HashToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_HashToken_Call) Return(s string) *MockAccountService_HashToken_Call {
+	_c.Call.Return(s)
+	return _c
+}
+
+func (_c *MockAccountService_HashToken_Call) RunAndReturn(run func(ctx context.Context, token string) string) *MockAccountService_HashToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ParseAuthToken provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) ParseAuthToken(ctx context.Context, token string) (*AuthClaims, error) {
+	ret := _mock.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ParseAuthToken")
+	}
+
+	var r0 *AuthClaims
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*AuthClaims, error)); ok {
+		return returnFunc(ctx, token)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *AuthClaims); ok {
+		r0 = returnFunc(ctx, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*AuthClaims)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountService_ParseAuthToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ParseAuthToken'
+type MockAccountService_ParseAuthToken_Call struct {
+	*mock.Call
+}
+
+// ParseAuthToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - token string
+func (_e *MockAccountService_Expecter) ParseAuthToken(ctx interface{}, token interface{}) *MockAccountService_ParseAuthToken_Call {
+	return &MockAccountService_ParseAuthToken_Call{Call: _e.mock.On("ParseAuthToken", ctx, token)}
+}
+
+func (_c *MockAccountService_ParseAuthToken_Call) Run(run func(ctx context.Context, token string)) *MockAccountService_ParseAuthToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_ParseAuthToken_Call) Return(authClaims *AuthClaims, err error) *MockAccountService_ParseAuthToken_Call {
+	_c.Call.Return(authClaims, err)
+	return _c
+}
+
+func (_c *MockAccountService_ParseAuthToken_Call) RunAndReturn(run func(ctx context.Context, token string) (*AuthClaims, error)) *MockAccountService_ParseAuthToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ParseOAuthAccessToken provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) ParseOAuthAccessToken(ctx context.Context, token string) (*OAuthAccessClaims, error) {
+	ret := _mock.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ParseOAuthAccessToken")
+	}
+
+	var r0 *OAuthAccessClaims
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*OAuthAccessClaims, error)); ok {
+		return returnFunc(ctx, token)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *OAuthAccessClaims); ok {
+		r0 = returnFunc(ctx, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*OAuthAccessClaims)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountService_ParseOAuthAccessToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ParseOAuthAccessToken'
+type MockAccountService_ParseOAuthAccessToken_Call struct {
+	*mock.Call
+}
+
+// ParseOAuthAccessToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - token string
+func (_e *MockAccountService_Expecter) ParseOAuthAccessToken(ctx interface{}, token interface{}) *MockAccountService_ParseOAuthAccessToken_Call {
+	return &MockAccountService_ParseOAuthAccessToken_Call{Call: _e.mock.On("ParseOAuthAccessToken", ctx, token)}
+}
+
+func (_c *MockAccountService_ParseOAuthAccessToken_Call) Run(run func(ctx context.Context, token string)) *MockAccountService_ParseOAuthAccessToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_ParseOAuthAccessToken_Call) Return(oAuthAccessClaims *OAuthAccessClaims, err error) *MockAccountService_ParseOAuthAccessToken_Call {
+	_c.Call.Return(oAuthAccessClaims, err)
+	return _c
+}
+
+func (_c *MockAccountService_ParseOAuthAccessToken_Call) RunAndReturn(run func(ctx context.Context, token string) (*OAuthAccessClaims, error)) *MockAccountService_ParseOAuthAccessToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SendAccountDeletedEmail provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) SendAccountDeletedEmail(ctx context.Context, email string, purgeAt time.Time, token string) error {
+	ret := _mock.Called(ctx, email, purgeAt, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendAccountDeletedEmail")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Time, string) error); ok {
+		r0 = returnFunc(ctx, email, purgeAt, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountService_SendAccountDeletedEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendAccountDeletedEmail'
+type MockAccountService_SendAccountDeletedEmail_Call struct {
+	*mock.Call
+}
+
+// SendAccountDeletedEmail is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+//   - purgeAt time.Time
+//   - token string
+func (_e *MockAccountService_Expecter) SendAccountDeletedEmail(ctx interface{}, email interface{}, purgeAt interface{}, token interface{}) *MockAccountService_SendAccountDeletedEmail_Call {
+	return &MockAccountService_SendAccountDeletedEmail_Call{Call: _e.mock.On("SendAccountDeletedEmail", ctx, email, purgeAt, token)}
+}
+
+func (_c *MockAccountService_SendAccountDeletedEmail_Call) Run(run func(ctx context.Context, email string, purgeAt time.Time, token string)) *MockAccountService_SendAccountDeletedEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_SendAccountDeletedEmail_Call) Return(err error) *MockAccountService_SendAccountDeletedEmail_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountService_SendAccountDeletedEmail_Call) RunAndReturn(run func(ctx context.Context, email string, purgeAt time.Time, token string) error) *MockAccountService_SendAccountDeletedEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SendAccountLockedEmail provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) SendAccountLockedEmail(ctx context.Context, email string, until time.Time) error {
+	ret := _mock.Called(ctx, email, until)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendAccountLockedEmail")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = returnFunc(ctx, email, until)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountService_SendAccountLockedEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendAccountLockedEmail'
+type MockAccountService_SendAccountLockedEmail_Call struct {
+	*mock.Call
+}
+
+// SendAccountLockedEmail is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+//   - until time.Time
+func (_e *MockAccountService_Expecter) SendAccountLockedEmail(ctx interface{}, email interface{}, until interface{}) *MockAccountService_SendAccountLockedEmail_Call {
+	return &MockAccountService_SendAccountLockedEmail_Call{Call: _e.mock.On("SendAccountLockedEmail", ctx, email, until)}
+}
+
+func (_c *MockAccountService_SendAccountLockedEmail_Call) Run(run func(ctx context.Context, email string, until time.Time)) *MockAccountService_SendAccountLockedEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_SendAccountLockedEmail_Call) Return(err error) *MockAccountService_SendAccountLockedEmail_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountService_SendAccountLockedEmail_Call) RunAndReturn(run func(ctx context.Context, email string, until time.Time) error) *MockAccountService_SendAccountLockedEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SendDataExportReadyEmail provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) SendDataExportReadyEmail(ctx context.Context, email string, expiresAt time.Time, token string) error {
+	ret := _mock.Called(ctx, email, expiresAt, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendDataExportReadyEmail")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Time, string) error); ok {
+		r0 = returnFunc(ctx, email, expiresAt, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountService_SendDataExportReadyEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendDataExportReadyEmail'
+type MockAccountService_SendDataExportReadyEmail_Call struct {
+	*mock.Call
+}
+
+// SendDataExportReadyEmail is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+//   - expiresAt time.Time
+//   - token string
+func (_e *MockAccountService_Expecter) SendDataExportReadyEmail(ctx interface{}, email interface{}, expiresAt interface{}, token interface{}) *MockAccountService_SendDataExportReadyEmail_Call {
+	return &MockAccountService_SendDataExportReadyEmail_Call{Call: _e.mock.On("SendDataExportReadyEmail", ctx, email, expiresAt, token)}
+}
+
+func (_c *MockAccountService_SendDataExportReadyEmail_Call) Run(run func(ctx context.Context, email string, expiresAt time.Time, token string)) *MockAccountService_SendDataExportReadyEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_SendDataExportReadyEmail_Call) Return(err error) *MockAccountService_SendDataExportReadyEmail_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountService_SendDataExportReadyEmail_Call) RunAndReturn(run func(ctx context.Context, email string, expiresAt time.Time, token string) error) *MockAccountService_SendDataExportReadyEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SendEmailChangeEmail provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) SendEmailChangeEmail(ctx context.Context, email string, token string) error {
+	ret := _mock.Called(ctx, email, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendEmailChangeEmail")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = returnFunc(ctx, email, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountService_SendEmailChangeEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendEmailChangeEmail'
+type MockAccountService_SendEmailChangeEmail_Call struct {
+	*mock.Call
+}
+
+// SendEmailChangeEmail is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+//   - token string
+func (_e *MockAccountService_Expecter) SendEmailChangeEmail(ctx interface{}, email interface{}, token interface{}) *MockAccountService_SendEmailChangeEmail_Call {
+	return &MockAccountService_SendEmailChangeEmail_Call{Call: _e.mock.On("SendEmailChangeEmail", ctx, email, token)}
+}
+
+func (_c *MockAccountService_SendEmailChangeEmail_Call) Run(run func(ctx context.Context, email string, token string)) *MockAccountService_SendEmailChangeEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_SendEmailChangeEmail_Call) Return(err error) *MockAccountService_SendEmailChangeEmail_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountService_SendEmailChangeEmail_Call) RunAndReturn(run func(ctx context.Context, email string, token string) error) *MockAccountService_SendEmailChangeEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SendEmailChangeNoticeEmail provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) SendEmailChangeNoticeEmail(ctx context.Context, email string, newEmail string, token string) error {
+	ret := _mock.Called(ctx, email, newEmail, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendEmailChangeNoticeEmail")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = returnFunc(ctx, email, newEmail, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountService_SendEmailChangeNoticeEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendEmailChangeNoticeEmail'
+type MockAccountService_SendEmailChangeNoticeEmail_Call struct {
+	*mock.Call
+}
+
+// SendEmailChangeNoticeEmail is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+//   - newEmail string
+//   - token string
+func (_e *MockAccountService_Expecter) SendEmailChangeNoticeEmail(ctx interface{}, email interface{}, newEmail interface{}, token interface{}) *MockAccountService_SendEmailChangeNoticeEmail_Call {
+	return &MockAccountService_SendEmailChangeNoticeEmail_Call{Call: _e.mock.On("SendEmailChangeNoticeEmail", ctx, email, newEmail, token)}
+}
+
+func (_c *MockAccountService_SendEmailChangeNoticeEmail_Call) Run(run func(ctx context.Context, email string, newEmail string, token string)) *MockAccountService_SendEmailChangeNoticeEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_SendEmailChangeNoticeEmail_Call) Return(err error) *MockAccountService_SendEmailChangeNoticeEmail_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountService_SendEmailChangeNoticeEmail_Call) RunAndReturn(run func(ctx context.Context, email string, newEmail string, token string) error) *MockAccountService_SendEmailChangeNoticeEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SendInvitationEmail provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) SendInvitationEmail(ctx context.Context, email string, organizationName string, token string) error {
+	ret := _mock.Called(ctx, email, organizationName, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendInvitationEmail")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = returnFunc(ctx, email, organizationName, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountService_SendInvitationEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendInvitationEmail'
+type MockAccountService_SendInvitationEmail_Call struct {
+	*mock.Call
+}
+
+// SendInvitationEmail is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+//   - organizationName string
+//   - token string
+func (_e *MockAccountService_Expecter) SendInvitationEmail(ctx interface{}, email interface{}, organizationName interface{}, token interface{}) *MockAccountService_SendInvitationEmail_Call {
+	return &MockAccountService_SendInvitationEmail_Call{Call: _e.mock.On("SendInvitationEmail", ctx, email, organizationName, token)}
+}
+
+func (_c *MockAccountService_SendInvitationEmail_Call) Run(run func(ctx context.Context, email string, organizationName string, token string)) *MockAccountService_SendInvitationEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_SendInvitationEmail_Call) Return(err error) *MockAccountService_SendInvitationEmail_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountService_SendInvitationEmail_Call) RunAndReturn(run func(ctx context.Context, email string, organizationName string, token string) error) *MockAccountService_SendInvitationEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SendMagicLinkEmail provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) SendMagicLinkEmail(ctx context.Context, email string, token string) error {
+	ret := _mock.Called(ctx, email, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendMagicLinkEmail")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = returnFunc(ctx, email, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountService_SendMagicLinkEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendMagicLinkEmail'
+type MockAccountService_SendMagicLinkEmail_Call struct {
+	*mock.Call
+}
+
+// SendMagicLinkEmail is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+//   - token string
+func (_e *MockAccountService_Expecter) SendMagicLinkEmail(ctx interface{}, email interface{}, token interface{}) *MockAccountService_SendMagicLinkEmail_Call {
+	return &MockAccountService_SendMagicLinkEmail_Call{Call: _e.mock.On("SendMagicLinkEmail", ctx, email, token)}
+}
+
+func (_c *MockAccountService_SendMagicLinkEmail_Call) Run(run func(ctx context.Context, email string, token string)) *MockAccountService_SendMagicLinkEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_SendMagicLinkEmail_Call) Return(err error) *MockAccountService_SendMagicLinkEmail_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountService_SendMagicLinkEmail_Call) RunAndReturn(run func(ctx context.Context, email string, token string) error) *MockAccountService_SendMagicLinkEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SendNewSignInEmail provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) SendNewSignInEmail(ctx context.Context, email string, session *Session, token string) error {
+	ret := _mock.Called(ctx, email, session, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendNewSignInEmail")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, *Session, string) error); ok {
+		r0 = returnFunc(ctx, email, session, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountService_SendNewSignInEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendNewSignInEmail'
+type MockAccountService_SendNewSignInEmail_Call struct {
+	*mock.Call
+}
+
+// SendNewSignInEmail is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+//   - session *Session
+//   - token string
+func (_e *MockAccountService_Expecter) SendNewSignInEmail(ctx interface{}, email interface{}, session interface{}, token interface{}) *MockAccountService_SendNewSignInEmail_Call {
+	return &MockAccountService_SendNewSignInEmail_Call{Call: _e.mock.On("SendNewSignInEmail", ctx, email, session, token)}
+}
+
+func (_c *MockAccountService_SendNewSignInEmail_Call) Run(run func(ctx context.Context, email string, session *Session, token string)) *MockAccountService_SendNewSignInEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 *Session
+		if args[2] != nil {
+			arg2 = args[2].(*Session)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_SendNewSignInEmail_Call) Return(err error) *MockAccountService_SendNewSignInEmail_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountService_SendNewSignInEmail_Call) RunAndReturn(run func(ctx context.Context, email string, session *Session, token string) error) *MockAccountService_SendNewSignInEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SendPasswordResetEmail provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) SendPasswordResetEmail(ctx context.Context, email string, token string) error {
+	ret := _mock.Called(ctx, email, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendPasswordResetEmail")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = returnFunc(ctx, email, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountService_SendPasswordResetEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendPasswordResetEmail'
+type MockAccountService_SendPasswordResetEmail_Call struct {
+	*mock.Call
+}
+
+// SendPasswordResetEmail is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+//   - token string
+func (_e *MockAccountService_Expecter) SendPasswordResetEmail(ctx interface{}, email interface{}, token interface{}) *MockAccountService_SendPasswordResetEmail_Call {
+	return &MockAccountService_SendPasswordResetEmail_Call{Call: _e.mock.On("SendPasswordResetEmail", ctx, email, token)}
+}
+
+func (_c *MockAccountService_SendPasswordResetEmail_Call) Run(run func(ctx context.Context, email string, token string)) *MockAccountService_SendPasswordResetEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_SendPasswordResetEmail_Call) Return(err error) *MockAccountService_SendPasswordResetEmail_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountService_SendPasswordResetEmail_Call) RunAndReturn(run func(ctx context.Context, email string, token string) error) *MockAccountService_SendPasswordResetEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SendVerificationEmail provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) SendVerificationEmail(ctx context.Context, email string, token string) error {
+	ret := _mock.Called(ctx, email, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendVerificationEmail")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = returnFunc(ctx, email, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountService_SendVerificationEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendVerificationEmail'
+type MockAccountService_SendVerificationEmail_Call struct {
+	*mock.Call
+}
+
+// SendVerificationEmail is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+//   - token string
+func (_e *MockAccountService_Expecter) SendVerificationEmail(ctx interface{}, email interface{}, token interface{}) *MockAccountService_SendVerificationEmail_Call {
+	return &MockAccountService_SendVerificationEmail_Call{Call: _e.mock.On("SendVerificationEmail", ctx, email, token)}
+}
+
+func (_c *MockAccountService_SendVerificationEmail_Call) Run(run func(ctx context.Context, email string, token string)) *MockAccountService_SendVerificationEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_SendVerificationEmail_Call) Return(err error) *MockAccountService_SendVerificationEmail_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountService_SendVerificationEmail_Call) RunAndReturn(run func(ctx context.Context, email string, token string) error) *MockAccountService_SendVerificationEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TOTPURI provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) TOTPURI(ctx context.Context, account *Account, secret string) string {
+	ret := _mock.Called(ctx, account, secret)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TOTPURI")
+	}
+
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account, string) string); ok {
+		r0 = returnFunc(ctx, account, secret)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	return r0
+}
+
+// MockAccountService_TOTPURI_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TOTPURI'
+type MockAccountService_TOTPURI_Call struct {
+	*mock.Call
+}
+
+// TOTPURI is a helper method to define mock.On call
+//   - ctx context.Context
+//   - account *Account
+//   - secret string
+func (_e *MockAccountService_Expecter) TOTPURI(ctx interface{}, account interface{}, secret interface{}) *MockAccountService_TOTPURI_Call {
+	return &MockAccountService_TOTPURI_Call{Call: _e.mock.On("TOTPURI", ctx, account, secret)}
+}
+
+func (_c *MockAccountService_TOTPURI_Call) Run(run func(ctx context.Context, account *Account, secret string)) *MockAccountService_TOTPURI_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *Account
+		if args[1] != nil {
+			arg1 = args[1].(*Account)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_TOTPURI_Call) Return(s string) *MockAccountService_TOTPURI_Call {
+	_c.Call.Return(s)
+	return _c
+}
+
+func (_c *MockAccountService_TOTPURI_Call) RunAndReturn(run func(ctx context.Context, account *Account, secret string) string) *MockAccountService_TOTPURI_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ValidateAuthToken provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) ValidateAuthToken(ctx context.Context, token string) (uint, error) {
+	ret := _mock.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ValidateAuthToken")
+	}
+
+	var r0 uint
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (uint, error)); ok {
+		return returnFunc(ctx, token)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) uint); ok {
+		r0 = returnFunc(ctx, token)
+	} else {
+		r0 = ret.Get(0).(uint)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountService_ValidateAuthToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ValidateAuthToken'
+type MockAccountService_ValidateAuthToken_Call struct {
+	*mock.Call
+}
+
+// ValidateAuthToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - token string
+func (_e *MockAccountService_Expecter) ValidateAuthToken(ctx interface{}, token interface{}) *MockAccountService_ValidateAuthToken_Call {
+	return &MockAccountService_ValidateAuthToken_Call{Call: _e.mock.On("ValidateAuthToken", ctx, token)}
+}
+
+func (_c *MockAccountService_ValidateAuthToken_Call) Run(run func(ctx context.Context, token string)) *MockAccountService_ValidateAuthToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_ValidateAuthToken_Call) Return(v uint, err error) *MockAccountService_ValidateAuthToken_Call {
+	_c.Call.Return(v, err)
+	return _c
+}
+
+func (_c *MockAccountService_ValidateAuthToken_Call) RunAndReturn(run func(ctx context.Context, token string) (uint, error)) *MockAccountService_ValidateAuthToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ValidateDataExportToken provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) ValidateDataExportToken(ctx context.Context, token string) (uint, error) {
+	ret := _mock.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ValidateDataExportToken")
+	}
+
+	var r0 uint
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (uint, error)); ok {
+		return returnFunc(ctx, token)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) uint); ok {
+		r0 = returnFunc(ctx, token)
+	} else {
+		r0 = ret.Get(0).(uint)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountService_ValidateDataExportToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ValidateDataExportToken'
+type MockAccountService_ValidateDataExportToken_Call struct {
+	*mock.Call
+}
+
+// ValidateDataExportToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - token string
+func (_e *MockAccountService_Expecter) ValidateDataExportToken(ctx interface{}, token interface{}) *MockAccountService_ValidateDataExportToken_Call {
+	return &MockAccountService_ValidateDataExportToken_Call{Call: _e.mock.On("ValidateDataExportToken", ctx, token)}
+}
+
+func (_c *MockAccountService_ValidateDataExportToken_Call) Run(run func(ctx context.Context, token string)) *MockAccountService_ValidateDataExportToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_ValidateDataExportToken_Call) Return(v uint, err error) *MockAccountService_ValidateDataExportToken_Call {
+	_c.Call.Return(v, err)
+	return _c
+}
+
+func (_c *MockAccountService_ValidateDataExportToken_Call) RunAndReturn(run func(ctx context.Context, token string) (uint, error)) *MockAccountService_ValidateDataExportToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ValidateEmailVerificationToken provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) ValidateEmailVerificationToken(ctx context.Context, token string) (uint, string, error) {
+	ret := _mock.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ValidateEmailVerificationToken")
+	}
+
+	var r0 uint
+	var r1 string
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (uint, string, error)); ok {
+		return returnFunc(ctx, token)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) uint); ok {
+		r0 = returnFunc(ctx, token)
+	} else {
+		r0 = ret.Get(0).(uint)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) string); ok {
+		r1 = returnFunc(ctx, token)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = returnFunc(ctx, token)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockAccountService_ValidateEmailVerificationToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ValidateEmailVerificationToken'
+type MockAccountService_ValidateEmailVerificationToken_Call struct {
+	*mock.Call
+}
+
+// ValidateEmailVerificationToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - token string
+func (_e *MockAccountService_Expecter) ValidateEmailVerificationToken(ctx interface{}, token interface{}) *MockAccountService_ValidateEmailVerificationToken_Call {
+	return &MockAccountService_ValidateEmailVerificationToken_Call{Call: _e.mock.On("ValidateEmailVerificationToken", ctx, token)}
+}
+
+func (_c *MockAccountService_ValidateEmailVerificationToken_Call) Run(run func(ctx context.Context, token string)) *MockAccountService_ValidateEmailVerificationToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_ValidateEmailVerificationToken_Call) Return(v uint, s string, err error) *MockAccountService_ValidateEmailVerificationToken_Call {
+	_c.Call.Return(v, s, err)
+	return _c
+}
+
+func (_c *MockAccountService_ValidateEmailVerificationToken_Call) RunAndReturn(run func(ctx context.Context, token string) (uint, string, error)) *MockAccountService_ValidateEmailVerificationToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ValidateInvitationToken provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) ValidateInvitationToken(ctx context.Context, token string) (uint, string, error) {
+	ret := _mock.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ValidateInvitationToken")
+	}
+
+	var r0 uint
+	var r1 string
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (uint, string, error)); ok {
+		return returnFunc(ctx, token)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) uint); ok {
+		r0 = returnFunc(ctx, token)
+	} else {
+		r0 = ret.Get(0).(uint)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) string); ok {
+		r1 = returnFunc(ctx, token)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = returnFunc(ctx, token)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockAccountService_ValidateInvitationToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ValidateInvitationToken'
+type MockAccountService_ValidateInvitationToken_Call struct {
+	*mock.Call
+}
+
+// ValidateInvitationToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - token string
+func (_e *MockAccountService_Expecter) ValidateInvitationToken(ctx interface{}, token interface{}) *MockAccountService_ValidateInvitationToken_Call {
+	return &MockAccountService_ValidateInvitationToken_Call{Call: _e.mock.On("ValidateInvitationToken", ctx, token)}
+}
+
+func (_c *MockAccountService_ValidateInvitationToken_Call) Run(run func(ctx context.Context, token string)) *MockAccountService_ValidateInvitationToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_ValidateInvitationToken_Call) Return(v uint, s string, err error) *MockAccountService_ValidateInvitationToken_Call {
+	_c.Call.Return(v, s, err)
+	return _c
+}
+
+func (_c *MockAccountService_ValidateInvitationToken_Call) RunAndReturn(run func(ctx context.Context, token string) (uint, string, error)) *MockAccountService_ValidateInvitationToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ValidateMFAToken provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) ValidateMFAToken(ctx context.Context, token string) (*AuthClaims, error) {
+	ret := _mock.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ValidateMFAToken")
+	}
+
+	var r0 *AuthClaims
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*AuthClaims, error)); ok {
+		return returnFunc(ctx, token)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *AuthClaims); ok {
+		r0 = returnFunc(ctx, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*AuthClaims)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountService_ValidateMFAToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ValidateMFAToken'
+type MockAccountService_ValidateMFAToken_Call struct {
+	*mock.Call
+}
+
+// ValidateMFAToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - token string
+func (_e *MockAccountService_Expecter) ValidateMFAToken(ctx interface{}, token interface{}) *MockAccountService_ValidateMFAToken_Call {
+	return &MockAccountService_ValidateMFAToken_Call{Call: _e.mock.On("ValidateMFAToken", ctx, token)}
+}
+
+func (_c *MockAccountService_ValidateMFAToken_Call) Run(run func(ctx context.Context, token string)) *MockAccountService_ValidateMFAToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_ValidateMFAToken_Call) Return(authClaims *AuthClaims, err error) *MockAccountService_ValidateMFAToken_Call {
+	_c.Call.Return(authClaims, err)
+	return _c
+}
+
+func (_c *MockAccountService_ValidateMFAToken_Call) RunAndReturn(run func(ctx context.Context, token string) (*AuthClaims, error)) *MockAccountService_ValidateMFAToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// VerifyTOTPCode provides a mock function for the type MockAccountService
+func (_mock *MockAccountService) VerifyTOTPCode(ctx context.Context, secret string, code string) (int64, error) {
+	ret := _mock.Called(ctx, secret, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifyTOTPCode")
+	}
+
+	var r0 int64
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) (int64, error)); ok {
+		return returnFunc(ctx, secret, code)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) int64); ok {
+		r0 = returnFunc(ctx, secret, code)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = returnFunc(ctx, secret, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountService_VerifyTOTPCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'VerifyTOTPCode'
+type MockAccountService_VerifyTOTPCode_Call struct {
+	*mock.Call
+}
+
+// VerifyTOTPCode is a helper method to define mock.On call
+//   - ctx context.Context
+//   - secret string
+//   - code string
+func (_e *MockAccountService_Expecter) VerifyTOTPCode(ctx interface{}, secret interface{}, code interface{}) *MockAccountService_VerifyTOTPCode_Call {
+	return &MockAccountService_VerifyTOTPCode_Call{Call: _e.mock.On("VerifyTOTPCode", ctx, secret, code)}
+}
+
+func (_c *MockAccountService_VerifyTOTPCode_Call) Run(run func(ctx context.Context, secret string, code string)) *MockAccountService_VerifyTOTPCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountService_VerifyTOTPCode_Call) Return(n int64, err error) *MockAccountService_VerifyTOTPCode_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockAccountService_VerifyTOTPCode_Call) RunAndReturn(run func(ctx context.Context, secret string, code string) (int64, error)) *MockAccountService_VerifyTOTPCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockAccountRepository creates a new instance of MockAccountRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockAccountRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockAccountRepository {
+	mock := &MockAccountRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockAccountRepository is an autogenerated mock type for the AccountRepository type
+type MockAccountRepository struct {
+	mock.Mock
+}
+
+type MockAccountRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockAccountRepository) EXPECT() *MockAccountRepository_Expecter {
+	return &MockAccountRepository_Expecter{mock: &_m.Mock}
+}
+
+// ChangeAccountEmail provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) ChangeAccountEmail(ctx context.Context, accountID uint, email string) error {
+	ret := _mock.Called(ctx, accountID, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChangeAccountEmail")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, string) error); ok {
+		r0 = returnFunc(ctx, accountID, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_ChangeAccountEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ChangeAccountEmail'
+type MockAccountRepository_ChangeAccountEmail_Call struct {
+	*mock.Call
+}
+
+// ChangeAccountEmail is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+//   - email string
+func (_e *MockAccountRepository_Expecter) ChangeAccountEmail(ctx interface{}, accountID interface{}, email interface{}) *MockAccountRepository_ChangeAccountEmail_Call {
+	return &MockAccountRepository_ChangeAccountEmail_Call{Call: _e.mock.On("ChangeAccountEmail", ctx, accountID, email)}
+}
+
+func (_c *MockAccountRepository_ChangeAccountEmail_Call) Run(run func(ctx context.Context, accountID uint, email string)) *MockAccountRepository_ChangeAccountEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_ChangeAccountEmail_Call) Return(err error) *MockAccountRepository_ChangeAccountEmail_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_ChangeAccountEmail_Call) RunAndReturn(run func(ctx context.Context, accountID uint, email string) error) *MockAccountRepository_ChangeAccountEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ConsumeAuthorizationCode provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) ConsumeAuthorizationCode(ctx context.Context, hash string) (*AuthorizationCode, error) {
+	ret := _mock.Called(ctx, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumeAuthorizationCode")
+	}
+
+	var r0 *AuthorizationCode
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*AuthorizationCode, error)); ok {
+		return returnFunc(ctx, hash)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *AuthorizationCode); ok {
+		r0 = returnFunc(ctx, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*AuthorizationCode)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_ConsumeAuthorizationCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConsumeAuthorizationCode'
+type MockAccountRepository_ConsumeAuthorizationCode_Call struct {
+	*mock.Call
+}
+
+// ConsumeAuthorizationCode is a helper method to define mock.On call
+//   - ctx context.Context
+//   - hash string
+func (_e *MockAccountRepository_Expecter) ConsumeAuthorizationCode(ctx interface{}, hash interface{}) *MockAccountRepository_ConsumeAuthorizationCode_Call {
+	return &MockAccountRepository_ConsumeAuthorizationCode_Call{Call: _e.mock.On("ConsumeAuthorizationCode", ctx, hash)}
+}
+
+func (_c *MockAccountRepository_ConsumeAuthorizationCode_Call) Run(run func(ctx context.Context, hash string)) *MockAccountRepository_ConsumeAuthorizationCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_ConsumeAuthorizationCode_Call) Return(authorizationCode *AuthorizationCode, err error) *MockAccountRepository_ConsumeAuthorizationCode_Call {
+	_c.Call.Return(authorizationCode, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_ConsumeAuthorizationCode_Call) RunAndReturn(run func(ctx context.Context, hash string) (*AuthorizationCode, error)) *MockAccountRepository_ConsumeAuthorizationCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ConsumeOAuthState provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) ConsumeOAuthState(ctx context.Context, provider string, hash string) (*OAuthState, error) {
+	ret := _mock.Called(ctx, provider, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumeOAuthState")
+	}
+
+	var r0 *OAuthState
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) (*OAuthState, error)); ok {
+		return returnFunc(ctx, provider, hash)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) *OAuthState); ok {
+		r0 = returnFunc(ctx, provider, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*OAuthState)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = returnFunc(ctx, provider, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_ConsumeOAuthState_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConsumeOAuthState'
+type MockAccountRepository_ConsumeOAuthState_Call struct {
+	*mock.Call
+}
+
+// ConsumeOAuthState is a helper method to define mock.On call
+//   - ctx context.Context
+//   - provider string
+//   - hash string
+func (_e *MockAccountRepository_Expecter) ConsumeOAuthState(ctx interface{}, provider interface{}, hash interface{}) *MockAccountRepository_ConsumeOAuthState_Call {
+	return &MockAccountRepository_ConsumeOAuthState_Call{Call: _e.mock.On("ConsumeOAuthState", ctx, provider, hash)}
+}
+
+func (_c *MockAccountRepository_ConsumeOAuthState_Call) Run(run func(ctx context.Context, provider string, hash string)) *MockAccountRepository_ConsumeOAuthState_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_ConsumeOAuthState_Call) Return(oAuthState *OAuthState, err error) *MockAccountRepository_ConsumeOAuthState_Call {
+	_c.Call.Return(oAuthState, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_ConsumeOAuthState_Call) RunAndReturn(run func(ctx context.Context, provider string, hash string) (*OAuthState, error)) *MockAccountRepository_ConsumeOAuthState_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ConsumeOneTimeToken provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) ConsumeOneTimeToken(ctx context.Context, purpose string, hash string) (*OneTimeToken, error) {
+	ret := _mock.Called(ctx, purpose, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumeOneTimeToken")
+	}
+
+	var r0 *OneTimeToken
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) (*OneTimeToken, error)); ok {
+		return returnFunc(ctx, purpose, hash)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) *OneTimeToken); ok {
+		r0 = returnFunc(ctx, purpose, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*OneTimeToken)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = returnFunc(ctx, purpose, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_ConsumeOneTimeToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConsumeOneTimeToken'
+type MockAccountRepository_ConsumeOneTimeToken_Call struct {
+	*mock.Call
+}
+
+// ConsumeOneTimeToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - purpose string
+//   - hash string
+func (_e *MockAccountRepository_Expecter) ConsumeOneTimeToken(ctx interface{}, purpose interface{}, hash interface{}) *MockAccountRepository_ConsumeOneTimeToken_Call {
+	return &MockAccountRepository_ConsumeOneTimeToken_Call{Call: _e.mock.On("ConsumeOneTimeToken", ctx, purpose, hash)}
+}
+
+func (_c *MockAccountRepository_ConsumeOneTimeToken_Call) Run(run func(ctx context.Context, purpose string, hash string)) *MockAccountRepository_ConsumeOneTimeToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_ConsumeOneTimeToken_Call) Return(oneTimeToken *OneTimeToken, err error) *MockAccountRepository_ConsumeOneTimeToken_Call {
+	_c.Call.Return(oneTimeToken, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_ConsumeOneTimeToken_Call) RunAndReturn(run func(ctx context.Context, purpose string, hash string) (*OneTimeToken, error)) *MockAccountRepository_ConsumeOneTimeToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ConsumeTOTPStep provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) ConsumeTOTPStep(ctx context.Context, accountID uint, step int64) error {
+	ret := _mock.Called(ctx, accountID, step)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumeTOTPStep")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, int64) error); ok {
+		r0 = returnFunc(ctx, accountID, step)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_ConsumeTOTPStep_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConsumeTOTPStep'
+type MockAccountRepository_ConsumeTOTPStep_Call struct {
+	*mock.Call
+}
+
+// ConsumeTOTPStep is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+//   - step int64
+func (_e *MockAccountRepository_Expecter) ConsumeTOTPStep(ctx interface{}, accountID interface{}, step interface{}) *MockAccountRepository_ConsumeTOTPStep_Call {
+	return &MockAccountRepository_ConsumeTOTPStep_Call{Call: _e.mock.On("ConsumeTOTPStep", ctx, accountID, step)}
+}
+
+func (_c *MockAccountRepository_ConsumeTOTPStep_Call) Run(run func(ctx context.Context, accountID uint, step int64)) *MockAccountRepository_ConsumeTOTPStep_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 int64
+		if args[2] != nil {
+			arg2 = args[2].(int64)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_ConsumeTOTPStep_Call) Return(err error) *MockAccountRepository_ConsumeTOTPStep_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_ConsumeTOTPStep_Call) RunAndReturn(run func(ctx context.Context, accountID uint, step int64) error) *MockAccountRepository_ConsumeTOTPStep_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ConsumeWebAuthnChallenge provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) ConsumeWebAuthnChallenge(ctx context.Context, purpose string, hash string) (*WebAuthnChallenge, error) {
+	ret := _mock.Called(ctx, purpose, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumeWebAuthnChallenge")
+	}
+
+	var r0 *WebAuthnChallenge
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) (*WebAuthnChallenge, error)); ok {
+		return returnFunc(ctx, purpose, hash)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) *WebAuthnChallenge); ok {
+		r0 = returnFunc(ctx, purpose, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*WebAuthnChallenge)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = returnFunc(ctx, purpose, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_ConsumeWebAuthnChallenge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConsumeWebAuthnChallenge'
+type MockAccountRepository_ConsumeWebAuthnChallenge_Call struct {
+	*mock.Call
+}
+
+// ConsumeWebAuthnChallenge is a helper method to define mock.On call
+//   - ctx context.Context
+//   - purpose string
+//   - hash string
+func (_e *MockAccountRepository_Expecter) ConsumeWebAuthnChallenge(ctx interface{}, purpose interface{}, hash interface{}) *MockAccountRepository_ConsumeWebAuthnChallenge_Call {
+	return &MockAccountRepository_ConsumeWebAuthnChallenge_Call{Call: _e.mock.On("ConsumeWebAuthnChallenge", ctx, purpose, hash)}
+}
+
+func (_c *MockAccountRepository_ConsumeWebAuthnChallenge_Call) Run(run func(ctx context.Context, purpose string, hash string)) *MockAccountRepository_ConsumeWebAuthnChallenge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_ConsumeWebAuthnChallenge_Call) Return(webAuthnChallenge *WebAuthnChallenge, err error) *MockAccountRepository_ConsumeWebAuthnChallenge_Call {
+	_c.Call.Return(webAuthnChallenge, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_ConsumeWebAuthnChallenge_Call) RunAndReturn(run func(ctx context.Context, purpose string, hash string) (*WebAuthnChallenge, error)) *MockAccountRepository_ConsumeWebAuthnChallenge_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CountLoginFailuresByIP provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) CountLoginFailuresByIP(ctx context.Context, ipAddress string, since time.Time) (int64, error) {
+	ret := _mock.Called(ctx, ipAddress, since)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountLoginFailuresByIP")
+	}
+
+	var r0 int64
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Time) (int64, error)); ok {
+		return returnFunc(ctx, ipAddress, since)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Time) int64); ok {
+		r0 = returnFunc(ctx, ipAddress, since)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = returnFunc(ctx, ipAddress, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_CountLoginFailuresByIP_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CountLoginFailuresByIP'
+type MockAccountRepository_CountLoginFailuresByIP_Call struct {
+	*mock.Call
+}
+
+// CountLoginFailuresByIP is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ipAddress string
+//   - since time.Time
+func (_e *MockAccountRepository_Expecter) CountLoginFailuresByIP(ctx interface{}, ipAddress interface{}, since interface{}) *MockAccountRepository_CountLoginFailuresByIP_Call {
+	return &MockAccountRepository_CountLoginFailuresByIP_Call{Call: _e.mock.On("CountLoginFailuresByIP", ctx, ipAddress, since)}
+}
+
+func (_c *MockAccountRepository_CountLoginFailuresByIP_Call) Run(run func(ctx context.Context, ipAddress string, since time.Time)) *MockAccountRepository_CountLoginFailuresByIP_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_CountLoginFailuresByIP_Call) Return(n int64, err error) *MockAccountRepository_CountLoginFailuresByIP_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_CountLoginFailuresByIP_Call) RunAndReturn(run func(ctx context.Context, ipAddress string, since time.Time) (int64, error)) *MockAccountRepository_CountLoginFailuresByIP_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CountOneTimeTokensSince provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) CountOneTimeTokensSince(ctx context.Context, accountID uint, purpose string, since time.Time) (int64, error) {
+	ret := _mock.Called(ctx, accountID, purpose, since)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountOneTimeTokensSince")
+	}
+
+	var r0 int64
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, string, time.Time) (int64, error)); ok {
+		return returnFunc(ctx, accountID, purpose, since)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, string, time.Time) int64); ok {
+		r0 = returnFunc(ctx, accountID, purpose, since)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint, string, time.Time) error); ok {
+		r1 = returnFunc(ctx, accountID, purpose, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_CountOneTimeTokensSince_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CountOneTimeTokensSince'
+type MockAccountRepository_CountOneTimeTokensSince_Call struct {
+	*mock.Call
+}
+
+// CountOneTimeTokensSince is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+//   - purpose string
+//   - since time.Time
+func (_e *MockAccountRepository_Expecter) CountOneTimeTokensSince(ctx interface{}, accountID interface{}, purpose interface{}, since interface{}) *MockAccountRepository_CountOneTimeTokensSince_Call {
+	return &MockAccountRepository_CountOneTimeTokensSince_Call{Call: _e.mock.On("CountOneTimeTokensSince", ctx, accountID, purpose, since)}
+}
+
+func (_c *MockAccountRepository_CountOneTimeTokensSince_Call) Run(run func(ctx context.Context, accountID uint, purpose string, since time.Time)) *MockAccountRepository_CountOneTimeTokensSince_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 time.Time
+		if args[3] != nil {
+			arg3 = args[3].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_CountOneTimeTokensSince_Call) Return(n int64, err error) *MockAccountRepository_CountOneTimeTokensSince_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_CountOneTimeTokensSince_Call) RunAndReturn(run func(ctx context.Context, accountID uint, purpose string, since time.Time) (int64, error)) *MockAccountRepository_CountOneTimeTokensSince_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateAPIKey provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) CreateAPIKey(ctx context.Context, key *APIKey) (*APIKey, error) {
+	ret := _mock.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAPIKey")
+	}
+
+	var r0 *APIKey
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *APIKey) (*APIKey, error)); ok {
+		return returnFunc(ctx, key)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *APIKey) *APIKey); ok {
+		r0 = returnFunc(ctx, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*APIKey)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *APIKey) error); ok {
+		r1 = returnFunc(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_CreateAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateAPIKey'
+type MockAccountRepository_CreateAPIKey_Call struct {
+	*mock.Call
+}
+
+// CreateAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key *APIKey
+func (_e *MockAccountRepository_Expecter) CreateAPIKey(ctx interface{}, key interface{}) *MockAccountRepository_CreateAPIKey_Call {
+	return &MockAccountRepository_CreateAPIKey_Call{Call: _e.mock.On("CreateAPIKey", ctx, key)}
+}
+
+func (_c *MockAccountRepository_CreateAPIKey_Call) Run(run func(ctx context.Context, key *APIKey)) *MockAccountRepository_CreateAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *APIKey
+		if args[1] != nil {
+			arg1 = args[1].(*APIKey)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_CreateAPIKey_Call) Return(aPIKey *APIKey, err error) *MockAccountRepository_CreateAPIKey_Call {
+	_c.Call.Return(aPIKey, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_CreateAPIKey_Call) RunAndReturn(run func(ctx context.Context, key *APIKey) (*APIKey, error)) *MockAccountRepository_CreateAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateAccount provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) CreateAccount(ctx context.Context, account *Account) (*Account, error) {
+	ret := _mock.Called(ctx, account)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAccount")
+	}
+
+	var r0 *Account
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account) (*Account, error)); ok {
+		return returnFunc(ctx, account)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account) *Account); ok {
+		r0 = returnFunc(ctx, account)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Account)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *Account) error); ok {
+		r1 = returnFunc(ctx, account)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_CreateAccount_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateAccount'
+type MockAccountRepository_CreateAccount_Call struct {
+	*mock.Call
+}
+
+// CreateAccount is a helper method to define mock.On call
+//   - ctx context.Context
+//   - account *Account
+func (_e *MockAccountRepository_Expecter) CreateAccount(ctx interface{}, account interface{}) *MockAccountRepository_CreateAccount_Call {
+	return &MockAccountRepository_CreateAccount_Call{Call: _e.mock.On("CreateAccount", ctx, account)}
+}
+
+func (_c *MockAccountRepository_CreateAccount_Call) Run(run func(ctx context.Context, account *Account)) *MockAccountRepository_CreateAccount_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *Account
+		if args[1] != nil {
+			arg1 = args[1].(*Account)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_CreateAccount_Call) Return(account1 *Account, err error) *MockAccountRepository_CreateAccount_Call {
+	_c.Call.Return(account1, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_CreateAccount_Call) RunAndReturn(run func(ctx context.Context, account *Account) (*Account, error)) *MockAccountRepository_CreateAccount_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateAuthorizationCode provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) CreateAuthorizationCode(ctx context.Context, code *AuthorizationCode) (*AuthorizationCode, error) {
+	ret := _mock.Called(ctx, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAuthorizationCode")
+	}
+
+	var r0 *AuthorizationCode
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *AuthorizationCode) (*AuthorizationCode, error)); ok {
+		return returnFunc(ctx, code)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *AuthorizationCode) *AuthorizationCode); ok {
+		r0 = returnFunc(ctx, code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*AuthorizationCode)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *AuthorizationCode) error); ok {
+		r1 = returnFunc(ctx, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_CreateAuthorizationCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateAuthorizationCode'
+type MockAccountRepository_CreateAuthorizationCode_Call struct {
+	*mock.Call
+}
+
+// CreateAuthorizationCode is a helper method to define mock.On call
+//   - ctx context.Context
+//   - code *AuthorizationCode
+func (_e *MockAccountRepository_Expecter) CreateAuthorizationCode(ctx interface{}, code interface{}) *MockAccountRepository_CreateAuthorizationCode_Call {
+	return &MockAccountRepository_CreateAuthorizationCode_Call{Call: _e.mock.On("CreateAuthorizationCode", ctx, code)}
+}
+
+func (_c *MockAccountRepository_CreateAuthorizationCode_Call) Run(run func(ctx context.Context, code *AuthorizationCode)) *MockAccountRepository_CreateAuthorizationCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *AuthorizationCode
+		if args[1] != nil {
+			arg1 = args[1].(*AuthorizationCode)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_CreateAuthorizationCode_Call) Return(authorizationCode *AuthorizationCode, err error) *MockAccountRepository_CreateAuthorizationCode_Call {
+	_c.Call.Return(authorizationCode, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_CreateAuthorizationCode_Call) RunAndReturn(run func(ctx context.Context, code *AuthorizationCode) (*AuthorizationCode, error)) *MockAccountRepository_CreateAuthorizationCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateIdentity provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) CreateIdentity(ctx context.Context, identity *Identity) (*Identity, error) {
+	ret := _mock.Called(ctx, identity)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateIdentity")
+	}
+
+	var r0 *Identity
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Identity) (*Identity, error)); ok {
+		return returnFunc(ctx, identity)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Identity) *Identity); ok {
+		r0 = returnFunc(ctx, identity)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Identity)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *Identity) error); ok {
+		r1 = returnFunc(ctx, identity)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_CreateIdentity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateIdentity'
+type MockAccountRepository_CreateIdentity_Call struct {
+	*mock.Call
+}
+
+// CreateIdentity is a helper method to define mock.On call
+//   - ctx context.Context
+//   - identity *Identity
+func (_e *MockAccountRepository_Expecter) CreateIdentity(ctx interface{}, identity interface{}) *MockAccountRepository_CreateIdentity_Call {
+	return &MockAccountRepository_CreateIdentity_Call{Call: _e.mock.On("CreateIdentity", ctx, identity)}
+}
+
+func (_c *MockAccountRepository_CreateIdentity_Call) Run(run func(ctx context.Context, identity *Identity)) *MockAccountRepository_CreateIdentity_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *Identity
+		if args[1] != nil {
+			arg1 = args[1].(*Identity)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_CreateIdentity_Call) Return(identity1 *Identity, err error) *MockAccountRepository_CreateIdentity_Call {
+	_c.Call.Return(identity1, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_CreateIdentity_Call) RunAndReturn(run func(ctx context.Context, identity *Identity) (*Identity, error)) *MockAccountRepository_CreateIdentity_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateOAuthClient provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) CreateOAuthClient(ctx context.Context, client *OAuthClient) (*OAuthClient, error) {
+	ret := _mock.Called(ctx, client)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateOAuthClient")
+	}
+
+	var r0 *OAuthClient
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *OAuthClient) (*OAuthClient, error)); ok {
+		return returnFunc(ctx, client)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *OAuthClient) *OAuthClient); ok {
+		r0 = returnFunc(ctx, client)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*OAuthClient)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *OAuthClient) error); ok {
+		r1 = returnFunc(ctx, client)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_CreateOAuthClient_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateOAuthClient'
+type MockAccountRepository_CreateOAuthClient_Call struct {
+	*mock.Call
+}
+
+// CreateOAuthClient is a helper method to define mock.On call
+//   - ctx context.Context
+//   - client *OAuthClient
+func (_e *MockAccountRepository_Expecter) CreateOAuthClient(ctx interface{}, client interface{}) *MockAccountRepository_CreateOAuthClient_Call {
+	return &MockAccountRepository_CreateOAuthClient_Call{Call: _e.mock.On("CreateOAuthClient", ctx, client)}
+}
+
+func (_c *MockAccountRepository_CreateOAuthClient_Call) Run(run func(ctx context.Context, client *OAuthClient)) *MockAccountRepository_CreateOAuthClient_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *OAuthClient
+		if args[1] != nil {
+			arg1 = args[1].(*OAuthClient)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_CreateOAuthClient_Call) Return(oAuthClient *OAuthClient, err error) *MockAccountRepository_CreateOAuthClient_Call {
+	_c.Call.Return(oAuthClient, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_CreateOAuthClient_Call) RunAndReturn(run func(ctx context.Context, client *OAuthClient) (*OAuthClient, error)) *MockAccountRepository_CreateOAuthClient_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateOAuthState provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) CreateOAuthState(ctx context.Context, state *OAuthState) (*OAuthState, error) {
+	ret := _mock.Called(ctx, state)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateOAuthState")
+	}
+
+	var r0 *OAuthState
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *OAuthState) (*OAuthState, error)); ok {
+		return returnFunc(ctx, state)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *OAuthState) *OAuthState); ok {
+		r0 = returnFunc(ctx, state)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*OAuthState)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *OAuthState) error); ok {
+		r1 = returnFunc(ctx, state)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_CreateOAuthState_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateOAuthState'
+type MockAccountRepository_CreateOAuthState_Call struct {
+	*mock.Call
+}
+
+// CreateOAuthState is a helper method to define mock.On call
+//   - ctx context.Context
+//   - state *OAuthState
+func (_e *MockAccountRepository_Expecter) CreateOAuthState(ctx interface{}, state interface{}) *MockAccountRepository_CreateOAuthState_Call {
+	return &MockAccountRepository_CreateOAuthState_Call{Call: _e.mock.On("CreateOAuthState", ctx, state)}
+}
+
+func (_c *MockAccountRepository_CreateOAuthState_Call) Run(run func(ctx context.Context, state *OAuthState)) *MockAccountRepository_CreateOAuthState_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *OAuthState
+		if args[1] != nil {
+			arg1 = args[1].(*OAuthState)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_CreateOAuthState_Call) Return(oAuthState *OAuthState, err error) *MockAccountRepository_CreateOAuthState_Call {
+	_c.Call.Return(oAuthState, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_CreateOAuthState_Call) RunAndReturn(run func(ctx context.Context, state *OAuthState) (*OAuthState, error)) *MockAccountRepository_CreateOAuthState_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateOneTimeToken provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) CreateOneTimeToken(ctx context.Context, token *OneTimeToken) (*OneTimeToken, error) {
+	ret := _mock.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateOneTimeToken")
+	}
+
+	var r0 *OneTimeToken
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *OneTimeToken) (*OneTimeToken, error)); ok {
+		return returnFunc(ctx, token)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *OneTimeToken) *OneTimeToken); ok {
+		r0 = returnFunc(ctx, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*OneTimeToken)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *OneTimeToken) error); ok {
+		r1 = returnFunc(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_CreateOneTimeToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateOneTimeToken'
+type MockAccountRepository_CreateOneTimeToken_Call struct {
+	*mock.Call
+}
+
+// CreateOneTimeToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - token *OneTimeToken
+func (_e *MockAccountRepository_Expecter) CreateOneTimeToken(ctx interface{}, token interface{}) *MockAccountRepository_CreateOneTimeToken_Call {
+	return &MockAccountRepository_CreateOneTimeToken_Call{Call: _e.mock.On("CreateOneTimeToken", ctx, token)}
+}
+
+func (_c *MockAccountRepository_CreateOneTimeToken_Call) Run(run func(ctx context.Context, token *OneTimeToken)) *MockAccountRepository_CreateOneTimeToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *OneTimeToken
+		if args[1] != nil {
+			arg1 = args[1].(*OneTimeToken)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_CreateOneTimeToken_Call) Return(oneTimeToken *OneTimeToken, err error) *MockAccountRepository_CreateOneTimeToken_Call {
+	_c.Call.Return(oneTimeToken, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_CreateOneTimeToken_Call) RunAndReturn(run func(ctx context.Context, token *OneTimeToken) (*OneTimeToken, error)) *MockAccountRepository_CreateOneTimeToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateRefreshToken provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) CreateRefreshToken(ctx context.Context, token *RefreshToken) (*RefreshToken, error) {
+	ret := _mock.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateRefreshToken")
+	}
+
+	var r0 *RefreshToken
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *RefreshToken) (*RefreshToken, error)); ok {
+		return returnFunc(ctx, token)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *RefreshToken) *RefreshToken); ok {
+		r0 = returnFunc(ctx, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*RefreshToken)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *RefreshToken) error); ok {
+		r1 = returnFunc(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_CreateRefreshToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateRefreshToken'
+type MockAccountRepository_CreateRefreshToken_Call struct {
+	*mock.Call
+}
+
+// CreateRefreshToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - token *RefreshToken
+func (_e *MockAccountRepository_Expecter) CreateRefreshToken(ctx interface{}, token interface{}) *MockAccountRepository_CreateRefreshToken_Call {
+	return &MockAccountRepository_CreateRefreshToken_Call{Call: _e.mock.On("CreateRefreshToken", ctx, token)}
+}
+
+func (_c *MockAccountRepository_CreateRefreshToken_Call) Run(run func(ctx context.Context, token *RefreshToken)) *MockAccountRepository_CreateRefreshToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *RefreshToken
+		if args[1] != nil {
+			arg1 = args[1].(*RefreshToken)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_CreateRefreshToken_Call) Return(refreshToken *RefreshToken, err error) *MockAccountRepository_CreateRefreshToken_Call {
+	_c.Call.Return(refreshToken, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_CreateRefreshToken_Call) RunAndReturn(run func(ctx context.Context, token *RefreshToken) (*RefreshToken, error)) *MockAccountRepository_CreateRefreshToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateSession provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) CreateSession(ctx context.Context, session *Session) (*Session, error) {
+	ret := _mock.Called(ctx, session)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSession")
+	}
+
+	var r0 *Session
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Session) (*Session, error)); ok {
+		return returnFunc(ctx, session)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Session) *Session); ok {
+		r0 = returnFunc(ctx, session)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Session)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *Session) error); ok {
+		r1 = returnFunc(ctx, session)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_CreateSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateSession'
+type MockAccountRepository_CreateSession_Call struct {
+	*mock.Call
+}
+
+// CreateSession is a helper method to define mock.On call
+//   - ctx context.Context
+//   - session *Session
+func (_e *MockAccountRepository_Expecter) CreateSession(ctx interface{}, session interface{}) *MockAccountRepository_CreateSession_Call {
+	return &MockAccountRepository_CreateSession_Call{Call: _e.mock.On("CreateSession", ctx, session)}
+}
+
+func (_c *MockAccountRepository_CreateSession_Call) Run(run func(ctx context.Context, session *Session)) *MockAccountRepository_CreateSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *Session
+		if args[1] != nil {
+			arg1 = args[1].(*Session)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_CreateSession_Call) Return(session1 *Session, err error) *MockAccountRepository_CreateSession_Call {
+	_c.Call.Return(session1, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_CreateSession_Call) RunAndReturn(run func(ctx context.Context, session *Session) (*Session, error)) *MockAccountRepository_CreateSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateWebAuthnChallenge provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) CreateWebAuthnChallenge(ctx context.Context, challenge *WebAuthnChallenge) (*WebAuthnChallenge, error) {
+	ret := _mock.Called(ctx, challenge)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateWebAuthnChallenge")
+	}
+
+	var r0 *WebAuthnChallenge
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *WebAuthnChallenge) (*WebAuthnChallenge, error)); ok {
+		return returnFunc(ctx, challenge)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *WebAuthnChallenge) *WebAuthnChallenge); ok {
+		r0 = returnFunc(ctx, challenge)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*WebAuthnChallenge)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *WebAuthnChallenge) error); ok {
+		r1 = returnFunc(ctx, challenge)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_CreateWebAuthnChallenge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateWebAuthnChallenge'
+type MockAccountRepository_CreateWebAuthnChallenge_Call struct {
+	*mock.Call
+}
+
+// CreateWebAuthnChallenge is a helper method to define mock.On call
+//   - ctx context.Context
+//   - challenge *WebAuthnChallenge
+func (_e *MockAccountRepository_Expecter) CreateWebAuthnChallenge(ctx interface{}, challenge interface{}) *MockAccountRepository_CreateWebAuthnChallenge_Call {
+	return &MockAccountRepository_CreateWebAuthnChallenge_Call{Call: _e.mock.On("CreateWebAuthnChallenge", ctx, challenge)}
+}
+
+func (_c *MockAccountRepository_CreateWebAuthnChallenge_Call) Run(run func(ctx context.Context, challenge *WebAuthnChallenge)) *MockAccountRepository_CreateWebAuthnChallenge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *WebAuthnChallenge
+		if args[1] != nil {
+			arg1 = args[1].(*WebAuthnChallenge)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_CreateWebAuthnChallenge_Call) Return(webAuthnChallenge *WebAuthnChallenge, err error) *MockAccountRepository_CreateWebAuthnChallenge_Call {
+	_c.Call.Return(webAuthnChallenge, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_CreateWebAuthnChallenge_Call) RunAndReturn(run func(ctx context.Context, challenge *WebAuthnChallenge) (*WebAuthnChallenge, error)) *MockAccountRepository_CreateWebAuthnChallenge_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateWebAuthnCredential provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) CreateWebAuthnCredential(ctx context.Context, credential *WebAuthnCredential) (*WebAuthnCredential, error) {
+	ret := _mock.Called(ctx, credential)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateWebAuthnCredential")
+	}
+
+	var r0 *WebAuthnCredential
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *WebAuthnCredential) (*WebAuthnCredential, error)); ok {
+		return returnFunc(ctx, credential)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *WebAuthnCredential) *WebAuthnCredential); ok {
+		r0 = returnFunc(ctx, credential)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*WebAuthnCredential)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *WebAuthnCredential) error); ok {
+		r1 = returnFunc(ctx, credential)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_CreateWebAuthnCredential_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateWebAuthnCredential'
+type MockAccountRepository_CreateWebAuthnCredential_Call struct {
+	*mock.Call
+}
+
+// CreateWebAuthnCredential is a helper method to define mock.On call
+//   - ctx context.Context
+//   - credential *WebAuthnCredential
+func (_e *MockAccountRepository_Expecter) CreateWebAuthnCredential(ctx interface{}, credential interface{}) *MockAccountRepository_CreateWebAuthnCredential_Call {
+	return &MockAccountRepository_CreateWebAuthnCredential_Call{Call: _e.mock.On("CreateWebAuthnCredential", ctx, credential)}
+}
+
+func (_c *MockAccountRepository_CreateWebAuthnCredential_Call) Run(run func(ctx context.Context, credential *WebAuthnCredential)) *MockAccountRepository_CreateWebAuthnCredential_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *WebAuthnCredential
+		if args[1] != nil {
+			arg1 = args[1].(*WebAuthnCredential)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_CreateWebAuthnCredential_Call) Return(webAuthnCredential *WebAuthnCredential, err error) *MockAccountRepository_CreateWebAuthnCredential_Call {
+	_c.Call.Return(webAuthnCredential, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_CreateWebAuthnCredential_Call) RunAndReturn(run func(ctx context.Context, credential *WebAuthnCredential) (*WebAuthnCredential, error)) *MockAccountRepository_CreateWebAuthnCredential_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteAccount provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) DeleteAccount(ctx context.Context, id uint) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAccount")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_DeleteAccount_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteAccount'
+type MockAccountRepository_DeleteAccount_Call struct {
+	*mock.Call
+}
+
+// DeleteAccount is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+func (_e *MockAccountRepository_Expecter) DeleteAccount(ctx interface{}, id interface{}) *MockAccountRepository_DeleteAccount_Call {
+	return &MockAccountRepository_DeleteAccount_Call{Call: _e.mock.On("DeleteAccount", ctx, id)}
+}
+
+func (_c *MockAccountRepository_DeleteAccount_Call) Run(run func(ctx context.Context, id uint)) *MockAccountRepository_DeleteAccount_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_DeleteAccount_Call) Return(err error) *MockAccountRepository_DeleteAccount_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_DeleteAccount_Call) RunAndReturn(run func(ctx context.Context, id uint) error) *MockAccountRepository_DeleteAccount_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteIdentity provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) DeleteIdentity(ctx context.Context, accountID uint, id uint) error {
+	ret := _mock.Called(ctx, accountID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteIdentity")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
+		r0 = returnFunc(ctx, accountID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_DeleteIdentity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteIdentity'
+type MockAccountRepository_DeleteIdentity_Call struct {
+	*mock.Call
+}
+
+// DeleteIdentity is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+//   - id uint
+func (_e *MockAccountRepository_Expecter) DeleteIdentity(ctx interface{}, accountID interface{}, id interface{}) *MockAccountRepository_DeleteIdentity_Call {
+	return &MockAccountRepository_DeleteIdentity_Call{Call: _e.mock.On("DeleteIdentity", ctx, accountID, id)}
+}
+
+func (_c *MockAccountRepository_DeleteIdentity_Call) Run(run func(ctx context.Context, accountID uint, id uint)) *MockAccountRepository_DeleteIdentity_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 uint
+		if args[2] != nil {
+			arg2 = args[2].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_DeleteIdentity_Call) Return(err error) *MockAccountRepository_DeleteIdentity_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_DeleteIdentity_Call) RunAndReturn(run func(ctx context.Context, accountID uint, id uint) error) *MockAccountRepository_DeleteIdentity_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteOAuthClient provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) DeleteOAuthClient(ctx context.Context, clientID string) error {
+	ret := _mock.Called(ctx, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteOAuthClient")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, clientID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_DeleteOAuthClient_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteOAuthClient'
+type MockAccountRepository_DeleteOAuthClient_Call struct {
+	*mock.Call
+}
+
+// DeleteOAuthClient is a helper method to define mock.On call
+//   - ctx context.Context
+//   - clientID string
+func (_e *MockAccountRepository_Expecter) DeleteOAuthClient(ctx interface{}, clientID interface{}) *MockAccountRepository_DeleteOAuthClient_Call {
+	return &MockAccountRepository_DeleteOAuthClient_Call{Call: _e.mock.On("DeleteOAuthClient", ctx, clientID)}
+}
+
+func (_c *MockAccountRepository_DeleteOAuthClient_Call) Run(run func(ctx context.Context, clientID string)) *MockAccountRepository_DeleteOAuthClient_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_DeleteOAuthClient_Call) Return(err error) *MockAccountRepository_DeleteOAuthClient_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_DeleteOAuthClient_Call) RunAndReturn(run func(ctx context.Context, clientID string) error) *MockAccountRepository_DeleteOAuthClient_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteRecoveryCodes provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) DeleteRecoveryCodes(ctx context.Context, accountID uint) error {
+	ret := _mock.Called(ctx, accountID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteRecoveryCodes")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = returnFunc(ctx, accountID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_DeleteRecoveryCodes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteRecoveryCodes'
+type MockAccountRepository_DeleteRecoveryCodes_Call struct {
+	*mock.Call
+}
+
+// DeleteRecoveryCodes is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+func (_e *MockAccountRepository_Expecter) DeleteRecoveryCodes(ctx interface{}, accountID interface{}) *MockAccountRepository_DeleteRecoveryCodes_Call {
+	return &MockAccountRepository_DeleteRecoveryCodes_Call{Call: _e.mock.On("DeleteRecoveryCodes", ctx, accountID)}
+}
+
+func (_c *MockAccountRepository_DeleteRecoveryCodes_Call) Run(run func(ctx context.Context, accountID uint)) *MockAccountRepository_DeleteRecoveryCodes_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_DeleteRecoveryCodes_Call) Return(err error) *MockAccountRepository_DeleteRecoveryCodes_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_DeleteRecoveryCodes_Call) RunAndReturn(run func(ctx context.Context, accountID uint) error) *MockAccountRepository_DeleteRecoveryCodes_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteWebAuthnCredential provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) DeleteWebAuthnCredential(ctx context.Context, accountID uint, id uint) error {
+	ret := _mock.Called(ctx, accountID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteWebAuthnCredential")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
+		r0 = returnFunc(ctx, accountID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_DeleteWebAuthnCredential_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteWebAuthnCredential'
+type MockAccountRepository_DeleteWebAuthnCredential_Call struct {
+	*mock.Call
+}
+
+// DeleteWebAuthnCredential is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+//   - id uint
+func (_e *MockAccountRepository_Expecter) DeleteWebAuthnCredential(ctx interface{}, accountID interface{}, id interface{}) *MockAccountRepository_DeleteWebAuthnCredential_Call {
+	return &MockAccountRepository_DeleteWebAuthnCredential_Call{Call: _e.mock.On("DeleteWebAuthnCredential", ctx, accountID, id)}
+}
+
+func (_c *MockAccountRepository_DeleteWebAuthnCredential_Call) Run(run func(ctx context.Context, accountID uint, id uint)) *MockAccountRepository_DeleteWebAuthnCredential_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 uint
+		if args[2] != nil {
+			arg2 = args[2].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_DeleteWebAuthnCredential_Call) Return(err error) *MockAccountRepository_DeleteWebAuthnCredential_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_DeleteWebAuthnCredential_Call) RunAndReturn(run func(ctx context.Context, accountID uint, id uint) error) *MockAccountRepository_DeleteWebAuthnCredential_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ForgetSession provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) ForgetSession(ctx context.Context, id uint) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ForgetSession")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_ForgetSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ForgetSession'
+type MockAccountRepository_ForgetSession_Call struct {
+	*mock.Call
+}
+
+// ForgetSession is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+func (_e *MockAccountRepository_Expecter) ForgetSession(ctx interface{}, id interface{}) *MockAccountRepository_ForgetSession_Call {
+	return &MockAccountRepository_ForgetSession_Call{Call: _e.mock.On("ForgetSession", ctx, id)}
+}
+
+func (_c *MockAccountRepository_ForgetSession_Call) Run(run func(ctx context.Context, id uint)) *MockAccountRepository_ForgetSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_ForgetSession_Call) Return(err error) *MockAccountRepository_ForgetSession_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_ForgetSession_Call) RunAndReturn(run func(ctx context.Context, id uint) error) *MockAccountRepository_ForgetSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAPIKeyByPrefix provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*APIKey, error) {
+	ret := _mock.Called(ctx, prefix)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAPIKeyByPrefix")
+	}
+
+	var r0 *APIKey
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*APIKey, error)); ok {
+		return returnFunc(ctx, prefix)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *APIKey); ok {
+		r0 = returnFunc(ctx, prefix)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*APIKey)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, prefix)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_GetAPIKeyByPrefix_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAPIKeyByPrefix'
+type MockAccountRepository_GetAPIKeyByPrefix_Call struct {
+	*mock.Call
+}
+
+// GetAPIKeyByPrefix is a helper method to define mock.On call
+//   - ctx context.Context
+//   - prefix string
+func (_e *MockAccountRepository_Expecter) GetAPIKeyByPrefix(ctx interface{}, prefix interface{}) *MockAccountRepository_GetAPIKeyByPrefix_Call {
+	return &MockAccountRepository_GetAPIKeyByPrefix_Call{Call: _e.mock.On("GetAPIKeyByPrefix", ctx, prefix)}
+}
+
+func (_c *MockAccountRepository_GetAPIKeyByPrefix_Call) Run(run func(ctx context.Context, prefix string)) *MockAccountRepository_GetAPIKeyByPrefix_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_GetAPIKeyByPrefix_Call) Return(aPIKey *APIKey, err error) *MockAccountRepository_GetAPIKeyByPrefix_Call {
+	_c.Call.Return(aPIKey, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_GetAPIKeyByPrefix_Call) RunAndReturn(run func(ctx context.Context, prefix string) (*APIKey, error)) *MockAccountRepository_GetAPIKeyByPrefix_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAccountByEmail provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) GetAccountByEmail(ctx context.Context, email string) (*Account, error) {
+	ret := _mock.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAccountByEmail")
+	}
+
+	var r0 *Account
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*Account, error)); ok {
+		return returnFunc(ctx, email)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *Account); ok {
+		r0 = returnFunc(ctx, email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Account)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_GetAccountByEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAccountByEmail'
+type MockAccountRepository_GetAccountByEmail_Call struct {
+	*mock.Call
+}
+
+// GetAccountByEmail is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+func (_e *MockAccountRepository_Expecter) GetAccountByEmail(ctx interface{}, email interface{}) *MockAccountRepository_GetAccountByEmail_Call {
+	return &MockAccountRepository_GetAccountByEmail_Call{Call: _e.mock.On("GetAccountByEmail", ctx, email)}
+}
+
+func (_c *MockAccountRepository_GetAccountByEmail_Call) Run(run func(ctx context.Context, email string)) *MockAccountRepository_GetAccountByEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_GetAccountByEmail_Call) Return(account *Account, err error) *MockAccountRepository_GetAccountByEmail_Call {
+	_c.Call.Return(account, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_GetAccountByEmail_Call) RunAndReturn(run func(ctx context.Context, email string) (*Account, error)) *MockAccountRepository_GetAccountByEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAccountByID provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) GetAccountByID(ctx context.Context, id uint) (*Account, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAccountByID")
+	}
+
+	var r0 *Account
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) (*Account, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) *Account); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Account)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_GetAccountByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAccountByID'
+type MockAccountRepository_GetAccountByID_Call struct {
+	*mock.Call
+}
+
+// GetAccountByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+func (_e *MockAccountRepository_Expecter) GetAccountByID(ctx interface{}, id interface{}) *MockAccountRepository_GetAccountByID_Call {
+	return &MockAccountRepository_GetAccountByID_Call{Call: _e.mock.On("GetAccountByID", ctx, id)}
+}
+
+func (_c *MockAccountRepository_GetAccountByID_Call) Run(run func(ctx context.Context, id uint)) *MockAccountRepository_GetAccountByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_GetAccountByID_Call) Return(account *Account, err error) *MockAccountRepository_GetAccountByID_Call {
+	_c.Call.Return(account, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_GetAccountByID_Call) RunAndReturn(run func(ctx context.Context, id uint) (*Account, error)) *MockAccountRepository_GetAccountByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAccountByIDWithDeleted provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) GetAccountByIDWithDeleted(ctx context.Context, id uint) (*Account, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAccountByIDWithDeleted")
+	}
+
+	var r0 *Account
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) (*Account, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) *Account); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Account)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_GetAccountByIDWithDeleted_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAccountByIDWithDeleted'
+type MockAccountRepository_GetAccountByIDWithDeleted_Call struct {
+	*mock.Call
+}
+
+// GetAccountByIDWithDeleted is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+func (_e *MockAccountRepository_Expecter) GetAccountByIDWithDeleted(ctx interface{}, id interface{}) *MockAccountRepository_GetAccountByIDWithDeleted_Call {
+	return &MockAccountRepository_GetAccountByIDWithDeleted_Call{Call: _e.mock.On("GetAccountByIDWithDeleted", ctx, id)}
+}
+
+func (_c *MockAccountRepository_GetAccountByIDWithDeleted_Call) Run(run func(ctx context.Context, id uint)) *MockAccountRepository_GetAccountByIDWithDeleted_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_GetAccountByIDWithDeleted_Call) Return(account *Account, err error) *MockAccountRepository_GetAccountByIDWithDeleted_Call {
+	_c.Call.Return(account, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_GetAccountByIDWithDeleted_Call) RunAndReturn(run func(ctx context.Context, id uint) (*Account, error)) *MockAccountRepository_GetAccountByIDWithDeleted_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetIdentity provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) GetIdentity(ctx context.Context, provider string, subject string) (*Identity, error) {
+	ret := _mock.Called(ctx, provider, subject)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetIdentity")
+	}
+
+	var r0 *Identity
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) (*Identity, error)); ok {
+		return returnFunc(ctx, provider, subject)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) *Identity); ok {
+		r0 = returnFunc(ctx, provider, subject)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Identity)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = returnFunc(ctx, provider, subject)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_GetIdentity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetIdentity'
+type MockAccountRepository_GetIdentity_Call struct {
+	*mock.Call
+}
+
+// GetIdentity is a helper method to define mock.On call
+//   - ctx context.Context
+//   - provider string
+//   - subject string
+func (_e *MockAccountRepository_Expecter) GetIdentity(ctx interface{}, provider interface{}, subject interface{}) *MockAccountRepository_GetIdentity_Call {
+	return &MockAccountRepository_GetIdentity_Call{Call: _e.mock.On("GetIdentity", ctx, provider, subject)}
+}
+
+func (_c *MockAccountRepository_GetIdentity_Call) Run(run func(ctx context.Context, provider string, subject string)) *MockAccountRepository_GetIdentity_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_GetIdentity_Call) Return(identity *Identity, err error) *MockAccountRepository_GetIdentity_Call {
+	_c.Call.Return(identity, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_GetIdentity_Call) RunAndReturn(run func(ctx context.Context, provider string, subject string) (*Identity, error)) *MockAccountRepository_GetIdentity_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetOAuthClient provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) GetOAuthClient(ctx context.Context, clientID string) (*OAuthClient, error) {
+	ret := _mock.Called(ctx, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOAuthClient")
+	}
+
+	var r0 *OAuthClient
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*OAuthClient, error)); ok {
+		return returnFunc(ctx, clientID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *OAuthClient); ok {
+		r0 = returnFunc(ctx, clientID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*OAuthClient)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, clientID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_GetOAuthClient_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOAuthClient'
+type MockAccountRepository_GetOAuthClient_Call struct {
+	*mock.Call
+}
+
+// GetOAuthClient is a helper method to define mock.On call
+//   - ctx context.Context
+//   - clientID string
+func (_e *MockAccountRepository_Expecter) GetOAuthClient(ctx interface{}, clientID interface{}) *MockAccountRepository_GetOAuthClient_Call {
+	return &MockAccountRepository_GetOAuthClient_Call{Call: _e.mock.On("GetOAuthClient", ctx, clientID)}
+}
+
+func (_c *MockAccountRepository_GetOAuthClient_Call) Run(run func(ctx context.Context, clientID string)) *MockAccountRepository_GetOAuthClient_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_GetOAuthClient_Call) Return(oAuthClient *OAuthClient, err error) *MockAccountRepository_GetOAuthClient_Call {
+	_c.Call.Return(oAuthClient, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_GetOAuthClient_Call) RunAndReturn(run func(ctx context.Context, clientID string) (*OAuthClient, error)) *MockAccountRepository_GetOAuthClient_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetOAuthConsent provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) GetOAuthConsent(ctx context.Context, accountID uint, clientID string) (*OAuthConsent, error) {
+	ret := _mock.Called(ctx, accountID, clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOAuthConsent")
+	}
+
+	var r0 *OAuthConsent
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, string) (*OAuthConsent, error)); ok {
+		return returnFunc(ctx, accountID, clientID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, string) *OAuthConsent); ok {
+		r0 = returnFunc(ctx, accountID, clientID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*OAuthConsent)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint, string) error); ok {
+		r1 = returnFunc(ctx, accountID, clientID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_GetOAuthConsent_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOAuthConsent'
+type MockAccountRepository_GetOAuthConsent_Call struct {
+	*mock.Call
+}
+
+// GetOAuthConsent is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+//   - clientID string
+func (_e *MockAccountRepository_Expecter) GetOAuthConsent(ctx interface{}, accountID interface{}, clientID interface{}) *MockAccountRepository_GetOAuthConsent_Call {
+	return &MockAccountRepository_GetOAuthConsent_Call{Call: _e.mock.On("GetOAuthConsent", ctx, accountID, clientID)}
+}
+
+func (_c *MockAccountRepository_GetOAuthConsent_Call) Run(run func(ctx context.Context, accountID uint, clientID string)) *MockAccountRepository_GetOAuthConsent_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_GetOAuthConsent_Call) Return(oAuthConsent *OAuthConsent, err error) *MockAccountRepository_GetOAuthConsent_Call {
+	_c.Call.Return(oAuthConsent, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_GetOAuthConsent_Call) RunAndReturn(run func(ctx context.Context, accountID uint, clientID string) (*OAuthConsent, error)) *MockAccountRepository_GetOAuthConsent_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetRefreshTokenByHash provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) GetRefreshTokenByHash(ctx context.Context, hash string) (*RefreshToken, error) {
+	ret := _mock.Called(ctx, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRefreshTokenByHash")
+	}
+
+	var r0 *RefreshToken
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*RefreshToken, error)); ok {
+		return returnFunc(ctx, hash)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *RefreshToken); ok {
+		r0 = returnFunc(ctx, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*RefreshToken)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_GetRefreshTokenByHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRefreshTokenByHash'
+type MockAccountRepository_GetRefreshTokenByHash_Call struct {
+	*mock.Call
+}
+
+// GetRefreshTokenByHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - hash string
+func (_e *MockAccountRepository_Expecter) GetRefreshTokenByHash(ctx interface{}, hash interface{}) *MockAccountRepository_GetRefreshTokenByHash_Call {
+	return &MockAccountRepository_GetRefreshTokenByHash_Call{Call: _e.mock.On("GetRefreshTokenByHash", ctx, hash)}
+}
+
+func (_c *MockAccountRepository_GetRefreshTokenByHash_Call) Run(run func(ctx context.Context, hash string)) *MockAccountRepository_GetRefreshTokenByHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_GetRefreshTokenByHash_Call) Return(refreshToken *RefreshToken, err error) *MockAccountRepository_GetRefreshTokenByHash_Call {
+	_c.Call.Return(refreshToken, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_GetRefreshTokenByHash_Call) RunAndReturn(run func(ctx context.Context, hash string) (*RefreshToken, error)) *MockAccountRepository_GetRefreshTokenByHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSessionByID provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) GetSessionByID(ctx context.Context, id uint) (*Session, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSessionByID")
+	}
+
+	var r0 *Session
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) (*Session, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) *Session); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Session)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_GetSessionByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSessionByID'
+type MockAccountRepository_GetSessionByID_Call struct {
+	*mock.Call
+}
+
+// GetSessionByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+func (_e *MockAccountRepository_Expecter) GetSessionByID(ctx interface{}, id interface{}) *MockAccountRepository_GetSessionByID_Call {
+	return &MockAccountRepository_GetSessionByID_Call{Call: _e.mock.On("GetSessionByID", ctx, id)}
+}
+
+func (_c *MockAccountRepository_GetSessionByID_Call) Run(run func(ctx context.Context, id uint)) *MockAccountRepository_GetSessionByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_GetSessionByID_Call) Return(session *Session, err error) *MockAccountRepository_GetSessionByID_Call {
+	_c.Call.Return(session, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_GetSessionByID_Call) RunAndReturn(run func(ctx context.Context, id uint) (*Session, error)) *MockAccountRepository_GetSessionByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetWebAuthnCredential provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) GetWebAuthnCredential(ctx context.Context, credentialID string) (*WebAuthnCredential, error) {
+	ret := _mock.Called(ctx, credentialID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWebAuthnCredential")
+	}
+
+	var r0 *WebAuthnCredential
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*WebAuthnCredential, error)); ok {
+		return returnFunc(ctx, credentialID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *WebAuthnCredential); ok {
+		r0 = returnFunc(ctx, credentialID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*WebAuthnCredential)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, credentialID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_GetWebAuthnCredential_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetWebAuthnCredential'
+type MockAccountRepository_GetWebAuthnCredential_Call struct {
+	*mock.Call
+}
+
+// GetWebAuthnCredential is a helper method to define mock.On call
+//   - ctx context.Context
+//   - credentialID string
+func (_e *MockAccountRepository_Expecter) GetWebAuthnCredential(ctx interface{}, credentialID interface{}) *MockAccountRepository_GetWebAuthnCredential_Call {
+	return &MockAccountRepository_GetWebAuthnCredential_Call{Call: _e.mock.On("GetWebAuthnCredential", ctx, credentialID)}
+}
+
+func (_c *MockAccountRepository_GetWebAuthnCredential_Call) Run(run func(ctx context.Context, credentialID string)) *MockAccountRepository_GetWebAuthnCredential_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_GetWebAuthnCredential_Call) Return(webAuthnCredential *WebAuthnCredential, err error) *MockAccountRepository_GetWebAuthnCredential_Call {
+	_c.Call.Return(webAuthnCredential, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_GetWebAuthnCredential_Call) RunAndReturn(run func(ctx context.Context, credentialID string) (*WebAuthnCredential, error)) *MockAccountRepository_GetWebAuthnCredential_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IsNewDevice provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) IsNewDevice(ctx context.Context, accountID uint, ipAddress string, userAgent string) (bool, error) {
+	ret := _mock.Called(ctx, accountID, ipAddress, userAgent)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsNewDevice")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, string, string) (bool, error)); ok {
+		return returnFunc(ctx, accountID, ipAddress, userAgent)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, string, string) bool); ok {
+		r0 = returnFunc(ctx, accountID, ipAddress, userAgent)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint, string, string) error); ok {
+		r1 = returnFunc(ctx, accountID, ipAddress, userAgent)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_IsNewDevice_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IsNewDevice'
+type MockAccountRepository_IsNewDevice_Call struct {
+	*mock.Call
+}
+
+// IsNewDevice is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+//   - ipAddress string
+//   - userAgent string
+func (_e *MockAccountRepository_Expecter) IsNewDevice(ctx interface{}, accountID interface{}, ipAddress interface{}, userAgent interface{}) *MockAccountRepository_IsNewDevice_Call {
+	return &MockAccountRepository_IsNewDevice_Call{Call: _e.mock.On("IsNewDevice", ctx, accountID, ipAddress, userAgent)}
+}
+
+func (_c *MockAccountRepository_IsNewDevice_Call) Run(run func(ctx context.Context, accountID uint, ipAddress string, userAgent string)) *MockAccountRepository_IsNewDevice_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_IsNewDevice_Call) Return(b bool, err error) *MockAccountRepository_IsNewDevice_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_IsNewDevice_Call) RunAndReturn(run func(ctx context.Context, accountID uint, ipAddress string, userAgent string) (bool, error)) *MockAccountRepository_IsNewDevice_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListAPIKeys provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) ListAPIKeys(ctx context.Context, accountID uint) ([]APIKey, error) {
+	ret := _mock.Called(ctx, accountID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAPIKeys")
+	}
+
+	var r0 []APIKey
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) ([]APIKey, error)); ok {
+		return returnFunc(ctx, accountID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) []APIKey); ok {
+		r0 = returnFunc(ctx, accountID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]APIKey)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = returnFunc(ctx, accountID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_ListAPIKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAPIKeys'
+type MockAccountRepository_ListAPIKeys_Call struct {
+	*mock.Call
+}
+
+// ListAPIKeys is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+func (_e *MockAccountRepository_Expecter) ListAPIKeys(ctx interface{}, accountID interface{}) *MockAccountRepository_ListAPIKeys_Call {
+	return &MockAccountRepository_ListAPIKeys_Call{Call: _e.mock.On("ListAPIKeys", ctx, accountID)}
+}
+
+func (_c *MockAccountRepository_ListAPIKeys_Call) Run(run func(ctx context.Context, accountID uint)) *MockAccountRepository_ListAPIKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_ListAPIKeys_Call) Return(aPIKeys []APIKey, err error) *MockAccountRepository_ListAPIKeys_Call {
+	_c.Call.Return(aPIKeys, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_ListAPIKeys_Call) RunAndReturn(run func(ctx context.Context, accountID uint) ([]APIKey, error)) *MockAccountRepository_ListAPIKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListAccountActivities provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) ListAccountActivities(ctx context.Context, accountID uint, limit int) ([]AccountActivity, error) {
+	ret := _mock.Called(ctx, accountID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAccountActivities")
+	}
+
+	var r0 []AccountActivity
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, int) ([]AccountActivity, error)); ok {
+		return returnFunc(ctx, accountID, limit)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, int) []AccountActivity); ok {
+		r0 = returnFunc(ctx, accountID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]AccountActivity)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint, int) error); ok {
+		r1 = returnFunc(ctx, accountID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_ListAccountActivities_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAccountActivities'
+type MockAccountRepository_ListAccountActivities_Call struct {
+	*mock.Call
+}
+
+// ListAccountActivities is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+//   - limit int
+func (_e *MockAccountRepository_Expecter) ListAccountActivities(ctx interface{}, accountID interface{}, limit interface{}) *MockAccountRepository_ListAccountActivities_Call {
+	return &MockAccountRepository_ListAccountActivities_Call{Call: _e.mock.On("ListAccountActivities", ctx, accountID, limit)}
+}
+
+func (_c *MockAccountRepository_ListAccountActivities_Call) Run(run func(ctx context.Context, accountID uint, limit int)) *MockAccountRepository_ListAccountActivities_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_ListAccountActivities_Call) Return(accountActivitys []AccountActivity, err error) *MockAccountRepository_ListAccountActivities_Call {
+	_c.Call.Return(accountActivitys, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_ListAccountActivities_Call) RunAndReturn(run func(ctx context.Context, accountID uint, limit int) ([]AccountActivity, error)) *MockAccountRepository_ListAccountActivities_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListAccounts provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) ListAccounts(ctx context.Context, filter AccountFilter) ([]Account, int64, error) {
+	ret := _mock.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAccounts")
+	}
+
+	var r0 []Account
+	var r1 int64
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, AccountFilter) ([]Account, int64, error)); ok {
+		return returnFunc(ctx, filter)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, AccountFilter) []Account); ok {
+		r0 = returnFunc(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Account)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, AccountFilter) int64); ok {
+		r1 = returnFunc(ctx, filter)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, AccountFilter) error); ok {
+		r2 = returnFunc(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockAccountRepository_ListAccounts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAccounts'
+type MockAccountRepository_ListAccounts_Call struct {
+	*mock.Call
+}
+
+// ListAccounts is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter AccountFilter
+func (_e *MockAccountRepository_Expecter) ListAccounts(ctx interface{}, filter interface{}) *MockAccountRepository_ListAccounts_Call {
+	return &MockAccountRepository_ListAccounts_Call{Call: _e.mock.On("ListAccounts", ctx, filter)}
+}
+
+func (_c *MockAccountRepository_ListAccounts_Call) Run(run func(ctx context.Context, filter AccountFilter)) *MockAccountRepository_ListAccounts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 AccountFilter
+		if args[1] != nil {
+			arg1 = args[1].(AccountFilter)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_ListAccounts_Call) Return(accounts []Account, n int64, err error) *MockAccountRepository_ListAccounts_Call {
+	_c.Call.Return(accounts, n, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_ListAccounts_Call) RunAndReturn(run func(ctx context.Context, filter AccountFilter) ([]Account, int64, error)) *MockAccountRepository_ListAccounts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListAccountsDueForPurge provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) ListAccountsDueForPurge(ctx context.Context, now time.Time, limit int) ([]uint, error) {
+	ret := _mock.Called(ctx, now, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAccountsDueForPurge")
+	}
+
+	var r0 []uint
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time, int) ([]uint, error)); ok {
+		return returnFunc(ctx, now, limit)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time, int) []uint); ok {
+		r0 = returnFunc(ctx, now, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uint)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, time.Time, int) error); ok {
+		r1 = returnFunc(ctx, now, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_ListAccountsDueForPurge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAccountsDueForPurge'
+type MockAccountRepository_ListAccountsDueForPurge_Call struct {
+	*mock.Call
+}
+
+// ListAccountsDueForPurge is a helper method to define mock.On call
+//   - ctx context.Context
+//   - now time.Time
+//   - limit int
+func (_e *MockAccountRepository_Expecter) ListAccountsDueForPurge(ctx interface{}, now interface{}, limit interface{}) *MockAccountRepository_ListAccountsDueForPurge_Call {
+	return &MockAccountRepository_ListAccountsDueForPurge_Call{Call: _e.mock.On("ListAccountsDueForPurge", ctx, now, limit)}
+}
+
+func (_c *MockAccountRepository_ListAccountsDueForPurge_Call) Run(run func(ctx context.Context, now time.Time, limit int)) *MockAccountRepository_ListAccountsDueForPurge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 time.Time
+		if args[1] != nil {
+			arg1 = args[1].(time.Time)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_ListAccountsDueForPurge_Call) Return(uints []uint, err error) *MockAccountRepository_ListAccountsDueForPurge_Call {
+	_c.Call.Return(uints, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_ListAccountsDueForPurge_Call) RunAndReturn(run func(ctx context.Context, now time.Time, limit int) ([]uint, error)) *MockAccountRepository_ListAccountsDueForPurge_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListActiveSessions provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) ListActiveSessions(ctx context.Context, accountID uint) ([]Session, error) {
+	ret := _mock.Called(ctx, accountID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListActiveSessions")
+	}
+
+	var r0 []Session
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) ([]Session, error)); ok {
+		return returnFunc(ctx, accountID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) []Session); ok {
+		r0 = returnFunc(ctx, accountID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Session)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = returnFunc(ctx, accountID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_ListActiveSessions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListActiveSessions'
+type MockAccountRepository_ListActiveSessions_Call struct {
+	*mock.Call
+}
+
+// ListActiveSessions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+func (_e *MockAccountRepository_Expecter) ListActiveSessions(ctx interface{}, accountID interface{}) *MockAccountRepository_ListActiveSessions_Call {
+	return &MockAccountRepository_ListActiveSessions_Call{Call: _e.mock.On("ListActiveSessions", ctx, accountID)}
+}
+
+func (_c *MockAccountRepository_ListActiveSessions_Call) Run(run func(ctx context.Context, accountID uint)) *MockAccountRepository_ListActiveSessions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_ListActiveSessions_Call) Return(sessions []Session, err error) *MockAccountRepository_ListActiveSessions_Call {
+	_c.Call.Return(sessions, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_ListActiveSessions_Call) RunAndReturn(run func(ctx context.Context, accountID uint) ([]Session, error)) *MockAccountRepository_ListActiveSessions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListActivities provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) ListActivities(ctx context.Context, filter ActivityFilter) ([]AccountActivity, int64, error) {
+	ret := _mock.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListActivities")
+	}
+
+	var r0 []AccountActivity
+	var r1 int64
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, ActivityFilter) ([]AccountActivity, int64, error)); ok {
+		return returnFunc(ctx, filter)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, ActivityFilter) []AccountActivity); ok {
+		r0 = returnFunc(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]AccountActivity)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, ActivityFilter) int64); ok {
+		r1 = returnFunc(ctx, filter)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, ActivityFilter) error); ok {
+		r2 = returnFunc(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockAccountRepository_ListActivities_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListActivities'
+type MockAccountRepository_ListActivities_Call struct {
+	*mock.Call
+}
+
+// ListActivities is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter ActivityFilter
+func (_e *MockAccountRepository_Expecter) ListActivities(ctx interface{}, filter interface{}) *MockAccountRepository_ListActivities_Call {
+	return &MockAccountRepository_ListActivities_Call{Call: _e.mock.On("ListActivities", ctx, filter)}
+}
+
+func (_c *MockAccountRepository_ListActivities_Call) Run(run func(ctx context.Context, filter ActivityFilter)) *MockAccountRepository_ListActivities_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 ActivityFilter
+		if args[1] != nil {
+			arg1 = args[1].(ActivityFilter)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_ListActivities_Call) Return(accountActivitys []AccountActivity, n int64, err error) *MockAccountRepository_ListActivities_Call {
+	_c.Call.Return(accountActivitys, n, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_ListActivities_Call) RunAndReturn(run func(ctx context.Context, filter ActivityFilter) ([]AccountActivity, int64, error)) *MockAccountRepository_ListActivities_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListIdentities provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) ListIdentities(ctx context.Context, accountID uint) ([]Identity, error) {
+	ret := _mock.Called(ctx, accountID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListIdentities")
+	}
+
+	var r0 []Identity
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) ([]Identity, error)); ok {
+		return returnFunc(ctx, accountID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) []Identity); ok {
+		r0 = returnFunc(ctx, accountID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Identity)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = returnFunc(ctx, accountID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_ListIdentities_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListIdentities'
+type MockAccountRepository_ListIdentities_Call struct {
+	*mock.Call
+}
+
+// ListIdentities is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+func (_e *MockAccountRepository_Expecter) ListIdentities(ctx interface{}, accountID interface{}) *MockAccountRepository_ListIdentities_Call {
+	return &MockAccountRepository_ListIdentities_Call{Call: _e.mock.On("ListIdentities", ctx, accountID)}
+}
+
+func (_c *MockAccountRepository_ListIdentities_Call) Run(run func(ctx context.Context, accountID uint)) *MockAccountRepository_ListIdentities_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_ListIdentities_Call) Return(identitys []Identity, err error) *MockAccountRepository_ListIdentities_Call {
+	_c.Call.Return(identitys, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_ListIdentities_Call) RunAndReturn(run func(ctx context.Context, accountID uint) ([]Identity, error)) *MockAccountRepository_ListIdentities_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListOAuthClients provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) ListOAuthClients(ctx context.Context) ([]OAuthClient, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListOAuthClients")
+	}
+
+	var r0 []OAuthClient
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]OAuthClient, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []OAuthClient); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]OAuthClient)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_ListOAuthClients_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListOAuthClients'
+type MockAccountRepository_ListOAuthClients_Call struct {
+	*mock.Call
+}
+
+// ListOAuthClients is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockAccountRepository_Expecter) ListOAuthClients(ctx interface{}) *MockAccountRepository_ListOAuthClients_Call {
+	return &MockAccountRepository_ListOAuthClients_Call{Call: _e.mock.On("ListOAuthClients", ctx)}
+}
+
+func (_c *MockAccountRepository_ListOAuthClients_Call) Run(run func(ctx context.Context)) *MockAccountRepository_ListOAuthClients_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_ListOAuthClients_Call) Return(oAuthClients []OAuthClient, err error) *MockAccountRepository_ListOAuthClients_Call {
+	_c.Call.Return(oAuthClients, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_ListOAuthClients_Call) RunAndReturn(run func(ctx context.Context) ([]OAuthClient, error)) *MockAccountRepository_ListOAuthClients_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListSessions provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) ListSessions(ctx context.Context, accountID uint) ([]Session, error) {
+	ret := _mock.Called(ctx, accountID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSessions")
+	}
+
+	var r0 []Session
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) ([]Session, error)); ok {
+		return returnFunc(ctx, accountID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) []Session); ok {
+		r0 = returnFunc(ctx, accountID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Session)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = returnFunc(ctx, accountID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_ListSessions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListSessions'
+type MockAccountRepository_ListSessions_Call struct {
+	*mock.Call
+}
+
+// ListSessions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+func (_e *MockAccountRepository_Expecter) ListSessions(ctx interface{}, accountID interface{}) *MockAccountRepository_ListSessions_Call {
+	return &MockAccountRepository_ListSessions_Call{Call: _e.mock.On("ListSessions", ctx, accountID)}
+}
+
+func (_c *MockAccountRepository_ListSessions_Call) Run(run func(ctx context.Context, accountID uint)) *MockAccountRepository_ListSessions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_ListSessions_Call) Return(sessions []Session, err error) *MockAccountRepository_ListSessions_Call {
+	_c.Call.Return(sessions, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_ListSessions_Call) RunAndReturn(run func(ctx context.Context, accountID uint) ([]Session, error)) *MockAccountRepository_ListSessions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListWebAuthnCredentials provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) ListWebAuthnCredentials(ctx context.Context, accountID uint) ([]WebAuthnCredential, error) {
+	ret := _mock.Called(ctx, accountID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListWebAuthnCredentials")
+	}
+
+	var r0 []WebAuthnCredential
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) ([]WebAuthnCredential, error)); ok {
+		return returnFunc(ctx, accountID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) []WebAuthnCredential); ok {
+		r0 = returnFunc(ctx, accountID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]WebAuthnCredential)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = returnFunc(ctx, accountID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_ListWebAuthnCredentials_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListWebAuthnCredentials'
+type MockAccountRepository_ListWebAuthnCredentials_Call struct {
+	*mock.Call
+}
+
+// ListWebAuthnCredentials is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+func (_e *MockAccountRepository_Expecter) ListWebAuthnCredentials(ctx interface{}, accountID interface{}) *MockAccountRepository_ListWebAuthnCredentials_Call {
+	return &MockAccountRepository_ListWebAuthnCredentials_Call{Call: _e.mock.On("ListWebAuthnCredentials", ctx, accountID)}
+}
+
+func (_c *MockAccountRepository_ListWebAuthnCredentials_Call) Run(run func(ctx context.Context, accountID uint)) *MockAccountRepository_ListWebAuthnCredentials_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_ListWebAuthnCredentials_Call) Return(webAuthnCredentials []WebAuthnCredential, err error) *MockAccountRepository_ListWebAuthnCredentials_Call {
+	_c.Call.Return(webAuthnCredentials, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_ListWebAuthnCredentials_Call) RunAndReturn(run func(ctx context.Context, accountID uint) ([]WebAuthnCredential, error)) *MockAccountRepository_ListWebAuthnCredentials_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LockAccount provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) LockAccount(ctx context.Context, accountID uint, until time.Time) error {
+	ret := _mock.Called(ctx, accountID, until)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LockAccount")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, time.Time) error); ok {
+		r0 = returnFunc(ctx, accountID, until)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_LockAccount_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LockAccount'
+type MockAccountRepository_LockAccount_Call struct {
+	*mock.Call
+}
+
+// LockAccount is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+//   - until time.Time
+func (_e *MockAccountRepository_Expecter) LockAccount(ctx interface{}, accountID interface{}, until interface{}) *MockAccountRepository_LockAccount_Call {
+	return &MockAccountRepository_LockAccount_Call{Call: _e.mock.On("LockAccount", ctx, accountID, until)}
+}
+
+func (_c *MockAccountRepository_LockAccount_Call) Run(run func(ctx context.Context, accountID uint, until time.Time)) *MockAccountRepository_LockAccount_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_LockAccount_Call) Return(err error) *MockAccountRepository_LockAccount_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_LockAccount_Call) RunAndReturn(run func(ctx context.Context, accountID uint, until time.Time) error) *MockAccountRepository_LockAccount_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LogAccountActivity provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) LogAccountActivity(ctx context.Context, accountID uint, activity string) error {
+	ret := _mock.Called(ctx, accountID, activity)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LogAccountActivity")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, string) error); ok {
+		r0 = returnFunc(ctx, accountID, activity)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_LogAccountActivity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LogAccountActivity'
+type MockAccountRepository_LogAccountActivity_Call struct {
+	*mock.Call
+}
+
+// LogAccountActivity is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+//   - activity string
+func (_e *MockAccountRepository_Expecter) LogAccountActivity(ctx interface{}, accountID interface{}, activity interface{}) *MockAccountRepository_LogAccountActivity_Call {
+	return &MockAccountRepository_LogAccountActivity_Call{Call: _e.mock.On("LogAccountActivity", ctx, accountID, activity)}
+}
+
+func (_c *MockAccountRepository_LogAccountActivity_Call) Run(run func(ctx context.Context, accountID uint, activity string)) *MockAccountRepository_LogAccountActivity_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_LogAccountActivity_Call) Return(err error) *MockAccountRepository_LogAccountActivity_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_LogAccountActivity_Call) RunAndReturn(run func(ctx context.Context, accountID uint, activity string) error) *MockAccountRepository_LogAccountActivity_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LogAccountActivityDetails provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) LogAccountActivityDetails(ctx context.Context, accountID uint, activity string, details map[string]any) error {
+	ret := _mock.Called(ctx, accountID, activity, details)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LogAccountActivityDetails")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, string, map[string]any) error); ok {
+		r0 = returnFunc(ctx, accountID, activity, details)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_LogAccountActivityDetails_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LogAccountActivityDetails'
+type MockAccountRepository_LogAccountActivityDetails_Call struct {
+	*mock.Call
+}
+
+// LogAccountActivityDetails is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+//   - activity string
+//   - details map[string]any
+func (_e *MockAccountRepository_Expecter) LogAccountActivityDetails(ctx interface{}, accountID interface{}, activity interface{}, details interface{}) *MockAccountRepository_LogAccountActivityDetails_Call {
+	return &MockAccountRepository_LogAccountActivityDetails_Call{Call: _e.mock.On("LogAccountActivityDetails", ctx, accountID, activity, details)}
+}
+
+func (_c *MockAccountRepository_LogAccountActivityDetails_Call) Run(run func(ctx context.Context, accountID uint, activity string, details map[string]any)) *MockAccountRepository_LogAccountActivityDetails_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 map[string]any
+		if args[3] != nil {
+			arg3 = args[3].(map[string]any)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_LogAccountActivityDetails_Call) Return(err error) *MockAccountRepository_LogAccountActivityDetails_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_LogAccountActivityDetails_Call) RunAndReturn(run func(ctx context.Context, accountID uint, activity string, details map[string]any) error) *MockAccountRepository_LogAccountActivityDetails_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PurgeAccount provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) PurgeAccount(ctx context.Context, id uint, now time.Time) error {
+	ret := _mock.Called(ctx, id, now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeAccount")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, time.Time) error); ok {
+		r0 = returnFunc(ctx, id, now)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_PurgeAccount_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PurgeAccount'
+type MockAccountRepository_PurgeAccount_Call struct {
+	*mock.Call
+}
+
+// PurgeAccount is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+//   - now time.Time
+func (_e *MockAccountRepository_Expecter) PurgeAccount(ctx interface{}, id interface{}, now interface{}) *MockAccountRepository_PurgeAccount_Call {
+	return &MockAccountRepository_PurgeAccount_Call{Call: _e.mock.On("PurgeAccount", ctx, id, now)}
+}
+
+func (_c *MockAccountRepository_PurgeAccount_Call) Run(run func(ctx context.Context, id uint, now time.Time)) *MockAccountRepository_PurgeAccount_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_PurgeAccount_Call) Return(err error) *MockAccountRepository_PurgeAccount_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_PurgeAccount_Call) RunAndReturn(run func(ctx context.Context, id uint, now time.Time) error) *MockAccountRepository_PurgeAccount_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RecordIdentityLogin provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) RecordIdentityLogin(ctx context.Context, id uint, loginAt time.Time) error {
+	ret := _mock.Called(ctx, id, loginAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordIdentityLogin")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, time.Time) error); ok {
+		r0 = returnFunc(ctx, id, loginAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_RecordIdentityLogin_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordIdentityLogin'
+type MockAccountRepository_RecordIdentityLogin_Call struct {
+	*mock.Call
+}
+
+// RecordIdentityLogin is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+//   - loginAt time.Time
+func (_e *MockAccountRepository_Expecter) RecordIdentityLogin(ctx interface{}, id interface{}, loginAt interface{}) *MockAccountRepository_RecordIdentityLogin_Call {
+	return &MockAccountRepository_RecordIdentityLogin_Call{Call: _e.mock.On("RecordIdentityLogin", ctx, id, loginAt)}
+}
+
+func (_c *MockAccountRepository_RecordIdentityLogin_Call) Run(run func(ctx context.Context, id uint, loginAt time.Time)) *MockAccountRepository_RecordIdentityLogin_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_RecordIdentityLogin_Call) Return(err error) *MockAccountRepository_RecordIdentityLogin_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_RecordIdentityLogin_Call) RunAndReturn(run func(ctx context.Context, id uint, loginAt time.Time) error) *MockAccountRepository_RecordIdentityLogin_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RecordLoginFailure provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) RecordLoginFailure(ctx context.Context, failure *LoginFailure) (int, error) {
+	ret := _mock.Called(ctx, failure)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordLoginFailure")
+	}
+
+	var r0 int
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *LoginFailure) (int, error)); ok {
+		return returnFunc(ctx, failure)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *LoginFailure) int); ok {
+		r0 = returnFunc(ctx, failure)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *LoginFailure) error); ok {
+		r1 = returnFunc(ctx, failure)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_RecordLoginFailure_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordLoginFailure'
+type MockAccountRepository_RecordLoginFailure_Call struct {
+	*mock.Call
+}
+
+// RecordLoginFailure is a helper method to define mock.On call
+//   - ctx context.Context
+//   - failure *LoginFailure
+func (_e *MockAccountRepository_Expecter) RecordLoginFailure(ctx interface{}, failure interface{}) *MockAccountRepository_RecordLoginFailure_Call {
+	return &MockAccountRepository_RecordLoginFailure_Call{Call: _e.mock.On("RecordLoginFailure", ctx, failure)}
+}
+
+func (_c *MockAccountRepository_RecordLoginFailure_Call) Run(run func(ctx context.Context, failure *LoginFailure)) *MockAccountRepository_RecordLoginFailure_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *LoginFailure
+		if args[1] != nil {
+			arg1 = args[1].(*LoginFailure)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_RecordLoginFailure_Call) Return(n int, err error) *MockAccountRepository_RecordLoginFailure_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_RecordLoginFailure_Call) RunAndReturn(run func(ctx context.Context, failure *LoginFailure) (int, error)) *MockAccountRepository_RecordLoginFailure_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RecordWebAuthnCredentialUse provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) RecordWebAuthnCredentialUse(ctx context.Context, id uint, signCount uint32, usedAt time.Time) error {
+	ret := _mock.Called(ctx, id, signCount, usedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordWebAuthnCredentialUse")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, uint32, time.Time) error); ok {
+		r0 = returnFunc(ctx, id, signCount, usedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_RecordWebAuthnCredentialUse_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordWebAuthnCredentialUse'
+type MockAccountRepository_RecordWebAuthnCredentialUse_Call struct {
+	*mock.Call
+}
+
+// RecordWebAuthnCredentialUse is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+//   - signCount uint32
+//   - usedAt time.Time
+func (_e *MockAccountRepository_Expecter) RecordWebAuthnCredentialUse(ctx interface{}, id interface{}, signCount interface{}, usedAt interface{}) *MockAccountRepository_RecordWebAuthnCredentialUse_Call {
+	return &MockAccountRepository_RecordWebAuthnCredentialUse_Call{Call: _e.mock.On("RecordWebAuthnCredentialUse", ctx, id, signCount, usedAt)}
+}
+
+func (_c *MockAccountRepository_RecordWebAuthnCredentialUse_Call) Run(run func(ctx context.Context, id uint, signCount uint32, usedAt time.Time)) *MockAccountRepository_RecordWebAuthnCredentialUse_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 uint32
+		if args[2] != nil {
+			arg2 = args[2].(uint32)
+		}
+		var arg3 time.Time
+		if args[3] != nil {
+			arg3 = args[3].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_RecordWebAuthnCredentialUse_Call) Return(err error) *MockAccountRepository_RecordWebAuthnCredentialUse_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_RecordWebAuthnCredentialUse_Call) RunAndReturn(run func(ctx context.Context, id uint, signCount uint32, usedAt time.Time) error) *MockAccountRepository_RecordWebAuthnCredentialUse_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReplaceRecoveryCodes provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) ReplaceRecoveryCodes(ctx context.Context, accountID uint, codes []RecoveryCode) error {
+	ret := _mock.Called(ctx, accountID, codes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplaceRecoveryCodes")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, []RecoveryCode) error); ok {
+		r0 = returnFunc(ctx, accountID, codes)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_ReplaceRecoveryCodes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReplaceRecoveryCodes'
+type MockAccountRepository_ReplaceRecoveryCodes_Call struct {
+	*mock.Call
+}
+
+// ReplaceRecoveryCodes is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+//   - codes []RecoveryCode
+func (_e *MockAccountRepository_Expecter) ReplaceRecoveryCodes(ctx interface{}, accountID interface{}, codes interface{}) *MockAccountRepository_ReplaceRecoveryCodes_Call {
+	return &MockAccountRepository_ReplaceRecoveryCodes_Call{Call: _e.mock.On("ReplaceRecoveryCodes", ctx, accountID, codes)}
+}
+
+func (_c *MockAccountRepository_ReplaceRecoveryCodes_Call) Run(run func(ctx context.Context, accountID uint, codes []RecoveryCode)) *MockAccountRepository_ReplaceRecoveryCodes_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 []RecoveryCode
+		if args[2] != nil {
+			arg2 = args[2].([]RecoveryCode)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_ReplaceRecoveryCodes_Call) Return(err error) *MockAccountRepository_ReplaceRecoveryCodes_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_ReplaceRecoveryCodes_Call) RunAndReturn(run func(ctx context.Context, accountID uint, codes []RecoveryCode) error) *MockAccountRepository_ReplaceRecoveryCodes_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RequirePasswordReset provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) RequirePasswordReset(ctx context.Context, id uint) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RequirePasswordReset")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_RequirePasswordReset_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RequirePasswordReset'
+type MockAccountRepository_RequirePasswordReset_Call struct {
+	*mock.Call
+}
+
+// RequirePasswordReset is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+func (_e *MockAccountRepository_Expecter) RequirePasswordReset(ctx interface{}, id interface{}) *MockAccountRepository_RequirePasswordReset_Call {
+	return &MockAccountRepository_RequirePasswordReset_Call{Call: _e.mock.On("RequirePasswordReset", ctx, id)}
+}
+
+func (_c *MockAccountRepository_RequirePasswordReset_Call) Run(run func(ctx context.Context, id uint)) *MockAccountRepository_RequirePasswordReset_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_RequirePasswordReset_Call) Return(err error) *MockAccountRepository_RequirePasswordReset_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_RequirePasswordReset_Call) RunAndReturn(run func(ctx context.Context, id uint) error) *MockAccountRepository_RequirePasswordReset_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RestoreAccount provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) RestoreAccount(ctx context.Context, id uint) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreAccount")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_RestoreAccount_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RestoreAccount'
+type MockAccountRepository_RestoreAccount_Call struct {
+	*mock.Call
+}
+
+// RestoreAccount is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+func (_e *MockAccountRepository_Expecter) RestoreAccount(ctx interface{}, id interface{}) *MockAccountRepository_RestoreAccount_Call {
+	return &MockAccountRepository_RestoreAccount_Call{Call: _e.mock.On("RestoreAccount", ctx, id)}
+}
+
+func (_c *MockAccountRepository_RestoreAccount_Call) Run(run func(ctx context.Context, id uint)) *MockAccountRepository_RestoreAccount_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_RestoreAccount_Call) Return(err error) *MockAccountRepository_RestoreAccount_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_RestoreAccount_Call) RunAndReturn(run func(ctx context.Context, id uint) error) *MockAccountRepository_RestoreAccount_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeAPIKey provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) RevokeAPIKey(ctx context.Context, accountID uint, id uint) error {
+	ret := _mock.Called(ctx, accountID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAPIKey")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
+		r0 = returnFunc(ctx, accountID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_RevokeAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeAPIKey'
+type MockAccountRepository_RevokeAPIKey_Call struct {
+	*mock.Call
+}
+
+// RevokeAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+//   - id uint
+func (_e *MockAccountRepository_Expecter) RevokeAPIKey(ctx interface{}, accountID interface{}, id interface{}) *MockAccountRepository_RevokeAPIKey_Call {
+	return &MockAccountRepository_RevokeAPIKey_Call{Call: _e.mock.On("RevokeAPIKey", ctx, accountID, id)}
+}
+
+func (_c *MockAccountRepository_RevokeAPIKey_Call) Run(run func(ctx context.Context, accountID uint, id uint)) *MockAccountRepository_RevokeAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 uint
+		if args[2] != nil {
+			arg2 = args[2].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_RevokeAPIKey_Call) Return(err error) *MockAccountRepository_RevokeAPIKey_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_RevokeAPIKey_Call) RunAndReturn(run func(ctx context.Context, accountID uint, id uint) error) *MockAccountRepository_RevokeAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeAllSessions provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) RevokeAllSessions(ctx context.Context, accountID uint) error {
+	ret := _mock.Called(ctx, accountID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAllSessions")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = returnFunc(ctx, accountID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_RevokeAllSessions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeAllSessions'
+type MockAccountRepository_RevokeAllSessions_Call struct {
+	*mock.Call
+}
+
+// RevokeAllSessions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+func (_e *MockAccountRepository_Expecter) RevokeAllSessions(ctx interface{}, accountID interface{}) *MockAccountRepository_RevokeAllSessions_Call {
+	return &MockAccountRepository_RevokeAllSessions_Call{Call: _e.mock.On("RevokeAllSessions", ctx, accountID)}
+}
+
+func (_c *MockAccountRepository_RevokeAllSessions_Call) Run(run func(ctx context.Context, accountID uint)) *MockAccountRepository_RevokeAllSessions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_RevokeAllSessions_Call) Return(err error) *MockAccountRepository_RevokeAllSessions_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_RevokeAllSessions_Call) RunAndReturn(run func(ctx context.Context, accountID uint) error) *MockAccountRepository_RevokeAllSessions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeOneTimeTokens provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) RevokeOneTimeTokens(ctx context.Context, accountID uint, purpose string) error {
+	ret := _mock.Called(ctx, accountID, purpose)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeOneTimeTokens")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, string) error); ok {
+		r0 = returnFunc(ctx, accountID, purpose)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_RevokeOneTimeTokens_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeOneTimeTokens'
+type MockAccountRepository_RevokeOneTimeTokens_Call struct {
+	*mock.Call
+}
+
+// RevokeOneTimeTokens is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+//   - purpose string
+func (_e *MockAccountRepository_Expecter) RevokeOneTimeTokens(ctx interface{}, accountID interface{}, purpose interface{}) *MockAccountRepository_RevokeOneTimeTokens_Call {
+	return &MockAccountRepository_RevokeOneTimeTokens_Call{Call: _e.mock.On("RevokeOneTimeTokens", ctx, accountID, purpose)}
+}
+
+func (_c *MockAccountRepository_RevokeOneTimeTokens_Call) Run(run func(ctx context.Context, accountID uint, purpose string)) *MockAccountRepository_RevokeOneTimeTokens_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_RevokeOneTimeTokens_Call) Return(err error) *MockAccountRepository_RevokeOneTimeTokens_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_RevokeOneTimeTokens_Call) RunAndReturn(run func(ctx context.Context, accountID uint, purpose string) error) *MockAccountRepository_RevokeOneTimeTokens_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeRefreshTokenFamily provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	ret := _mock.Called(ctx, familyID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeRefreshTokenFamily")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, familyID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_RevokeRefreshTokenFamily_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeRefreshTokenFamily'
+type MockAccountRepository_RevokeRefreshTokenFamily_Call struct {
+	*mock.Call
+}
+
+// RevokeRefreshTokenFamily is a helper method to define mock.On call
+//   - ctx context.Context
+//   - familyID string
+func (_e *MockAccountRepository_Expecter) RevokeRefreshTokenFamily(ctx interface{}, familyID interface{}) *MockAccountRepository_RevokeRefreshTokenFamily_Call {
+	return &MockAccountRepository_RevokeRefreshTokenFamily_Call{Call: _e.mock.On("RevokeRefreshTokenFamily", ctx, familyID)}
+}
+
+func (_c *MockAccountRepository_RevokeRefreshTokenFamily_Call) Run(run func(ctx context.Context, familyID string)) *MockAccountRepository_RevokeRefreshTokenFamily_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_RevokeRefreshTokenFamily_Call) Return(err error) *MockAccountRepository_RevokeRefreshTokenFamily_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_RevokeRefreshTokenFamily_Call) RunAndReturn(run func(ctx context.Context, familyID string) error) *MockAccountRepository_RevokeRefreshTokenFamily_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeSession provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) RevokeSession(ctx context.Context, id uint) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeSession")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_RevokeSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeSession'
+type MockAccountRepository_RevokeSession_Call struct {
+	*mock.Call
+}
+
+// RevokeSession is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+func (_e *MockAccountRepository_Expecter) RevokeSession(ctx interface{}, id interface{}) *MockAccountRepository_RevokeSession_Call {
+	return &MockAccountRepository_RevokeSession_Call{Call: _e.mock.On("RevokeSession", ctx, id)}
+}
+
+func (_c *MockAccountRepository_RevokeSession_Call) Run(run func(ctx context.Context, id uint)) *MockAccountRepository_RevokeSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_RevokeSession_Call) Return(err error) *MockAccountRepository_RevokeSession_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_RevokeSession_Call) RunAndReturn(run func(ctx context.Context, id uint) error) *MockAccountRepository_RevokeSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RotateRefreshToken provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) RotateRefreshToken(ctx context.Context, current *RefreshToken, next *RefreshToken) (*RefreshToken, error) {
+	ret := _mock.Called(ctx, current, next)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RotateRefreshToken")
+	}
+
+	var r0 *RefreshToken
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *RefreshToken, *RefreshToken) (*RefreshToken, error)); ok {
+		return returnFunc(ctx, current, next)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *RefreshToken, *RefreshToken) *RefreshToken); ok {
+		r0 = returnFunc(ctx, current, next)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*RefreshToken)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *RefreshToken, *RefreshToken) error); ok {
+		r1 = returnFunc(ctx, current, next)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_RotateRefreshToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RotateRefreshToken'
+type MockAccountRepository_RotateRefreshToken_Call struct {
+	*mock.Call
+}
+
+// RotateRefreshToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - current *RefreshToken
+//   - next *RefreshToken
+func (_e *MockAccountRepository_Expecter) RotateRefreshToken(ctx interface{}, current interface{}, next interface{}) *MockAccountRepository_RotateRefreshToken_Call {
+	return &MockAccountRepository_RotateRefreshToken_Call{Call: _e.mock.On("RotateRefreshToken", ctx, current, next)}
+}
+
+func (_c *MockAccountRepository_RotateRefreshToken_Call) Run(run func(ctx context.Context, current *RefreshToken, next *RefreshToken)) *MockAccountRepository_RotateRefreshToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *RefreshToken
+		if args[1] != nil {
+			arg1 = args[1].(*RefreshToken)
+		}
+		var arg2 *RefreshToken
+		if args[2] != nil {
+			arg2 = args[2].(*RefreshToken)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_RotateRefreshToken_Call) Return(refreshToken *RefreshToken, err error) *MockAccountRepository_RotateRefreshToken_Call {
+	_c.Call.Return(refreshToken, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_RotateRefreshToken_Call) RunAndReturn(run func(ctx context.Context, current *RefreshToken, next *RefreshToken) (*RefreshToken, error)) *MockAccountRepository_RotateRefreshToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveOAuthConsent provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) SaveOAuthConsent(ctx context.Context, consent *OAuthConsent) error {
+	ret := _mock.Called(ctx, consent)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveOAuthConsent")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *OAuthConsent) error); ok {
+		r0 = returnFunc(ctx, consent)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_SaveOAuthConsent_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveOAuthConsent'
+type MockAccountRepository_SaveOAuthConsent_Call struct {
+	*mock.Call
+}
+
+// SaveOAuthConsent is a helper method to define mock.On call
+//   - ctx context.Context
+//   - consent *OAuthConsent
+func (_e *MockAccountRepository_Expecter) SaveOAuthConsent(ctx interface{}, consent interface{}) *MockAccountRepository_SaveOAuthConsent_Call {
+	return &MockAccountRepository_SaveOAuthConsent_Call{Call: _e.mock.On("SaveOAuthConsent", ctx, consent)}
+}
+
+func (_c *MockAccountRepository_SaveOAuthConsent_Call) Run(run func(ctx context.Context, consent *OAuthConsent)) *MockAccountRepository_SaveOAuthConsent_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *OAuthConsent
+		if args[1] != nil {
+			arg1 = args[1].(*OAuthConsent)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_SaveOAuthConsent_Call) Return(err error) *MockAccountRepository_SaveOAuthConsent_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_SaveOAuthConsent_Call) RunAndReturn(run func(ctx context.Context, consent *OAuthConsent) error) *MockAccountRepository_SaveOAuthConsent_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ScheduleAccountDeletion provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) ScheduleAccountDeletion(ctx context.Context, id uint, purgeAt time.Time) error {
+	ret := _mock.Called(ctx, id, purgeAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ScheduleAccountDeletion")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, time.Time) error); ok {
+		r0 = returnFunc(ctx, id, purgeAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_ScheduleAccountDeletion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ScheduleAccountDeletion'
+type MockAccountRepository_ScheduleAccountDeletion_Call struct {
+	*mock.Call
+}
+
+// ScheduleAccountDeletion is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+//   - purgeAt time.Time
+func (_e *MockAccountRepository_Expecter) ScheduleAccountDeletion(ctx interface{}, id interface{}, purgeAt interface{}) *MockAccountRepository_ScheduleAccountDeletion_Call {
+	return &MockAccountRepository_ScheduleAccountDeletion_Call{Call: _e.mock.On("ScheduleAccountDeletion", ctx, id, purgeAt)}
+}
+
+func (_c *MockAccountRepository_ScheduleAccountDeletion_Call) Run(run func(ctx context.Context, id uint, purgeAt time.Time)) *MockAccountRepository_ScheduleAccountDeletion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_ScheduleAccountDeletion_Call) Return(err error) *MockAccountRepository_ScheduleAccountDeletion_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_ScheduleAccountDeletion_Call) RunAndReturn(run func(ctx context.Context, id uint, purgeAt time.Time) error) *MockAccountRepository_ScheduleAccountDeletion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetAccountAvatar provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) SetAccountAvatar(ctx context.Context, accountID uint, key string, avatarURL string) error {
+	ret := _mock.Called(ctx, accountID, key, avatarURL)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetAccountAvatar")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, string, string) error); ok {
+		r0 = returnFunc(ctx, accountID, key, avatarURL)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_SetAccountAvatar_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetAccountAvatar'
+type MockAccountRepository_SetAccountAvatar_Call struct {
+	*mock.Call
+}
+
+// SetAccountAvatar is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+//   - key string
+//   - avatarURL string
+func (_e *MockAccountRepository_Expecter) SetAccountAvatar(ctx interface{}, accountID interface{}, key interface{}, avatarURL interface{}) *MockAccountRepository_SetAccountAvatar_Call {
+	return &MockAccountRepository_SetAccountAvatar_Call{Call: _e.mock.On("SetAccountAvatar", ctx, accountID, key, avatarURL)}
+}
+
+func (_c *MockAccountRepository_SetAccountAvatar_Call) Run(run func(ctx context.Context, accountID uint, key string, avatarURL string)) *MockAccountRepository_SetAccountAvatar_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_SetAccountAvatar_Call) Return(err error) *MockAccountRepository_SetAccountAvatar_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_SetAccountAvatar_Call) RunAndReturn(run func(ctx context.Context, accountID uint, key string, avatarURL string) error) *MockAccountRepository_SetAccountAvatar_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetAccountDisabled provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) SetAccountDisabled(ctx context.Context, id uint, disabledAt *time.Time) error {
+	ret := _mock.Called(ctx, id, disabledAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetAccountDisabled")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, *time.Time) error); ok {
+		r0 = returnFunc(ctx, id, disabledAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_SetAccountDisabled_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetAccountDisabled'
+type MockAccountRepository_SetAccountDisabled_Call struct {
+	*mock.Call
+}
+
+// SetAccountDisabled is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+//   - disabledAt *time.Time
+func (_e *MockAccountRepository_Expecter) SetAccountDisabled(ctx interface{}, id interface{}, disabledAt interface{}) *MockAccountRepository_SetAccountDisabled_Call {
+	return &MockAccountRepository_SetAccountDisabled_Call{Call: _e.mock.On("SetAccountDisabled", ctx, id, disabledAt)}
+}
+
+func (_c *MockAccountRepository_SetAccountDisabled_Call) Run(run func(ctx context.Context, id uint, disabledAt *time.Time)) *MockAccountRepository_SetAccountDisabled_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 *time.Time
+		if args[2] != nil {
+			arg2 = args[2].(*time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_SetAccountDisabled_Call) Return(err error) *MockAccountRepository_SetAccountDisabled_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_SetAccountDisabled_Call) RunAndReturn(run func(ctx context.Context, id uint, disabledAt *time.Time) error) *MockAccountRepository_SetAccountDisabled_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetSessionOrganization provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) SetSessionOrganization(ctx context.Context, id uint, organizationID uint) error {
+	ret := _mock.Called(ctx, id, organizationID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetSessionOrganization")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
+		r0 = returnFunc(ctx, id, organizationID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_SetSessionOrganization_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetSessionOrganization'
+type MockAccountRepository_SetSessionOrganization_Call struct {
+	*mock.Call
+}
+
+// SetSessionOrganization is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+//   - organizationID uint
+func (_e *MockAccountRepository_Expecter) SetSessionOrganization(ctx interface{}, id interface{}, organizationID interface{}) *MockAccountRepository_SetSessionOrganization_Call {
+	return &MockAccountRepository_SetSessionOrganization_Call{Call: _e.mock.On("SetSessionOrganization", ctx, id, organizationID)}
+}
+
+func (_c *MockAccountRepository_SetSessionOrganization_Call) Run(run func(ctx context.Context, id uint, organizationID uint)) *MockAccountRepository_SetSessionOrganization_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 uint
+		if args[2] != nil {
+			arg2 = args[2].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_SetSessionOrganization_Call) Return(err error) *MockAccountRepository_SetSessionOrganization_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_SetSessionOrganization_Call) RunAndReturn(run func(ctx context.Context, id uint, organizationID uint) error) *MockAccountRepository_SetSessionOrganization_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TouchAPIKey provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) TouchAPIKey(ctx context.Context, id uint, usedAt time.Time) error {
+	ret := _mock.Called(ctx, id, usedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TouchAPIKey")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, time.Time) error); ok {
+		r0 = returnFunc(ctx, id, usedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_TouchAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TouchAPIKey'
+type MockAccountRepository_TouchAPIKey_Call struct {
+	*mock.Call
+}
+
+// TouchAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+//   - usedAt time.Time
+func (_e *MockAccountRepository_Expecter) TouchAPIKey(ctx interface{}, id interface{}, usedAt interface{}) *MockAccountRepository_TouchAPIKey_Call {
+	return &MockAccountRepository_TouchAPIKey_Call{Call: _e.mock.On("TouchAPIKey", ctx, id, usedAt)}
+}
+
+func (_c *MockAccountRepository_TouchAPIKey_Call) Run(run func(ctx context.Context, id uint, usedAt time.Time)) *MockAccountRepository_TouchAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_TouchAPIKey_Call) Return(err error) *MockAccountRepository_TouchAPIKey_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_TouchAPIKey_Call) RunAndReturn(run func(ctx context.Context, id uint, usedAt time.Time) error) *MockAccountRepository_TouchAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TouchSession provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) TouchSession(ctx context.Context, id uint, seenAt time.Time) error {
+	ret := _mock.Called(ctx, id, seenAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TouchSession")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, time.Time) error); ok {
+		r0 = returnFunc(ctx, id, seenAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_TouchSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TouchSession'
+type MockAccountRepository_TouchSession_Call struct {
+	*mock.Call
+}
+
+// TouchSession is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+//   - seenAt time.Time
+func (_e *MockAccountRepository_Expecter) TouchSession(ctx interface{}, id interface{}, seenAt interface{}) *MockAccountRepository_TouchSession_Call {
+	return &MockAccountRepository_TouchSession_Call{Call: _e.mock.On("TouchSession", ctx, id, seenAt)}
+}
+
+func (_c *MockAccountRepository_TouchSession_Call) Run(run func(ctx context.Context, id uint, seenAt time.Time)) *MockAccountRepository_TouchSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_TouchSession_Call) Return(err error) *MockAccountRepository_TouchSession_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_TouchSession_Call) RunAndReturn(run func(ctx context.Context, id uint, seenAt time.Time) error) *MockAccountRepository_TouchSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UnlockAccount provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) UnlockAccount(ctx context.Context, accountID uint) error {
+	ret := _mock.Called(ctx, accountID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UnlockAccount")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = returnFunc(ctx, accountID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_UnlockAccount_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UnlockAccount'
+type MockAccountRepository_UnlockAccount_Call struct {
+	*mock.Call
+}
+
+// UnlockAccount is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+func (_e *MockAccountRepository_Expecter) UnlockAccount(ctx interface{}, accountID interface{}) *MockAccountRepository_UnlockAccount_Call {
+	return &MockAccountRepository_UnlockAccount_Call{Call: _e.mock.On("UnlockAccount", ctx, accountID)}
+}
+
+func (_c *MockAccountRepository_UnlockAccount_Call) Run(run func(ctx context.Context, accountID uint)) *MockAccountRepository_UnlockAccount_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_UnlockAccount_Call) Return(err error) *MockAccountRepository_UnlockAccount_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_UnlockAccount_Call) RunAndReturn(run func(ctx context.Context, accountID uint) error) *MockAccountRepository_UnlockAccount_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateAccount provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) UpdateAccount(ctx context.Context, account *Account) (*Account, error) {
+	ret := _mock.Called(ctx, account)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateAccount")
+	}
+
+	var r0 *Account
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account) (*Account, error)); ok {
+		return returnFunc(ctx, account)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account) *Account); ok {
+		r0 = returnFunc(ctx, account)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Account)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *Account) error); ok {
+		r1 = returnFunc(ctx, account)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockAccountRepository_UpdateAccount_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateAccount'
+type MockAccountRepository_UpdateAccount_Call struct {
+	*mock.Call
+}
+
+// UpdateAccount is a helper method to define mock.On call
+//   - ctx context.Context
+//   - account *Account
+func (_e *MockAccountRepository_Expecter) UpdateAccount(ctx interface{}, account interface{}) *MockAccountRepository_UpdateAccount_Call {
+	return &MockAccountRepository_UpdateAccount_Call{Call: _e.mock.On("UpdateAccount", ctx, account)}
+}
+
+func (_c *MockAccountRepository_UpdateAccount_Call) Run(run func(ctx context.Context, account *Account)) *MockAccountRepository_UpdateAccount_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *Account
+		if args[1] != nil {
+			arg1 = args[1].(*Account)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_UpdateAccount_Call) Return(account1 *Account, err error) *MockAccountRepository_UpdateAccount_Call {
+	_c.Call.Return(account1, err)
+	return _c
+}
+
+func (_c *MockAccountRepository_UpdateAccount_Call) RunAndReturn(run func(ctx context.Context, account *Account) (*Account, error)) *MockAccountRepository_UpdateAccount_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateAccountPassword provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) UpdateAccountPassword(ctx context.Context, accountID uint, passwordHash string) error {
+	ret := _mock.Called(ctx, accountID, passwordHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateAccountPassword")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, string) error); ok {
+		r0 = returnFunc(ctx, accountID, passwordHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_UpdateAccountPassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateAccountPassword'
+type MockAccountRepository_UpdateAccountPassword_Call struct {
+	*mock.Call
+}
+
+// UpdateAccountPassword is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+//   - passwordHash string
+func (_e *MockAccountRepository_Expecter) UpdateAccountPassword(ctx interface{}, accountID interface{}, passwordHash interface{}) *MockAccountRepository_UpdateAccountPassword_Call {
+	return &MockAccountRepository_UpdateAccountPassword_Call{Call: _e.mock.On("UpdateAccountPassword", ctx, accountID, passwordHash)}
+}
+
+func (_c *MockAccountRepository_UpdateAccountPassword_Call) Run(run func(ctx context.Context, accountID uint, passwordHash string)) *MockAccountRepository_UpdateAccountPassword_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_UpdateAccountPassword_Call) Return(err error) *MockAccountRepository_UpdateAccountPassword_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_UpdateAccountPassword_Call) RunAndReturn(run func(ctx context.Context, accountID uint, passwordHash string) error) *MockAccountRepository_UpdateAccountPassword_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateAccountProfile provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) UpdateAccountProfile(ctx context.Context, accountID uint, profile AccountProfile) error {
+	ret := _mock.Called(ctx, accountID, profile)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateAccountProfile")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, AccountProfile) error); ok {
+		r0 = returnFunc(ctx, accountID, profile)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_UpdateAccountProfile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateAccountProfile'
+type MockAccountRepository_UpdateAccountProfile_Call struct {
+	*mock.Call
+}
+
+// UpdateAccountProfile is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+//   - profile AccountProfile
+func (_e *MockAccountRepository_Expecter) UpdateAccountProfile(ctx interface{}, accountID interface{}, profile interface{}) *MockAccountRepository_UpdateAccountProfile_Call {
+	return &MockAccountRepository_UpdateAccountProfile_Call{Call: _e.mock.On("UpdateAccountProfile", ctx, accountID, profile)}
+}
+
+func (_c *MockAccountRepository_UpdateAccountProfile_Call) Run(run func(ctx context.Context, accountID uint, profile AccountProfile)) *MockAccountRepository_UpdateAccountProfile_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 AccountProfile
+		if args[2] != nil {
+			arg2 = args[2].(AccountProfile)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_UpdateAccountProfile_Call) Return(err error) *MockAccountRepository_UpdateAccountProfile_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_UpdateAccountProfile_Call) RunAndReturn(run func(ctx context.Context, accountID uint, profile AccountProfile) error) *MockAccountRepository_UpdateAccountProfile_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UseRecoveryCode provides a mock function for the type MockAccountRepository
+func (_mock *MockAccountRepository) UseRecoveryCode(ctx context.Context, accountID uint, hash string) error {
+	ret := _mock.Called(ctx, accountID, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UseRecoveryCode")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, string) error); ok {
+		r0 = returnFunc(ctx, accountID, hash)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAccountRepository_UseRecoveryCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UseRecoveryCode'
+type MockAccountRepository_UseRecoveryCode_Call struct {
+	*mock.Call
+}
+
+// UseRecoveryCode is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+//   - hash string
+func (_e *MockAccountRepository_Expecter) UseRecoveryCode(ctx interface{}, accountID interface{}, hash interface{}) *MockAccountRepository_UseRecoveryCode_Call {
+	return &MockAccountRepository_UseRecoveryCode_Call{Call: _e.mock.On("UseRecoveryCode", ctx, accountID, hash)}
+}
+
+func (_c *MockAccountRepository_UseRecoveryCode_Call) Run(run func(ctx context.Context, accountID uint, hash string)) *MockAccountRepository_UseRecoveryCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAccountRepository_UseRecoveryCode_Call) Return(err error) *MockAccountRepository_UseRecoveryCode_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAccountRepository_UseRecoveryCode_Call) RunAndReturn(run func(ctx context.Context, accountID uint, hash string) error) *MockAccountRepository_UseRecoveryCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockTokenRevocationRepository creates a new instance of MockTokenRevocationRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockTokenRevocationRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockTokenRevocationRepository {
+	mock := &MockTokenRevocationRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockTokenRevocationRepository is an autogenerated mock type for the TokenRevocationRepository type
+type MockTokenRevocationRepository struct {
+	mock.Mock
+}
+
+type MockTokenRevocationRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockTokenRevocationRepository) EXPECT() *MockTokenRevocationRepository_Expecter {
+	return &MockTokenRevocationRepository_Expecter{mock: &_m.Mock}
+}
+
+// IsTokenRevoked provides a mock function for the type MockTokenRevocationRepository
+func (_mock *MockTokenRevocationRepository) IsTokenRevoked(ctx context.Context, claims *AuthClaims) (bool, error) {
+	ret := _mock.Called(ctx, claims)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsTokenRevoked")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *AuthClaims) (bool, error)); ok {
+		return returnFunc(ctx, claims)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *AuthClaims) bool); ok {
+		r0 = returnFunc(ctx, claims)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *AuthClaims) error); ok {
+		r1 = returnFunc(ctx, claims)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockTokenRevocationRepository_IsTokenRevoked_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IsTokenRevoked'
+type MockTokenRevocationRepository_IsTokenRevoked_Call struct {
+	*mock.Call
+}
+
+// IsTokenRevoked is a helper method to define mock.On call
+//   - ctx context.Context
+//   - claims *AuthClaims
+func (_e *MockTokenRevocationRepository_Expecter) IsTokenRevoked(ctx interface{}, claims interface{}) *MockTokenRevocationRepository_IsTokenRevoked_Call {
+	return &MockTokenRevocationRepository_IsTokenRevoked_Call{Call: _e.mock.On("IsTokenRevoked", ctx, claims)}
+}
+
+func (_c *MockTokenRevocationRepository_IsTokenRevoked_Call) Run(run func(ctx context.Context, claims *AuthClaims)) *MockTokenRevocationRepository_IsTokenRevoked_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *AuthClaims
+		if args[1] != nil {
+			arg1 = args[1].(*AuthClaims)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTokenRevocationRepository_IsTokenRevoked_Call) Return(b bool, err error) *MockTokenRevocationRepository_IsTokenRevoked_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockTokenRevocationRepository_IsTokenRevoked_Call) RunAndReturn(run func(ctx context.Context, claims *AuthClaims) (bool, error)) *MockTokenRevocationRepository_IsTokenRevoked_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeAllTokens provides a mock function for the type MockTokenRevocationRepository
+func (_mock *MockTokenRevocationRepository) RevokeAllTokens(ctx context.Context, accountID uint, before time.Time) error {
+	ret := _mock.Called(ctx, accountID, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAllTokens")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, time.Time) error); ok {
+		r0 = returnFunc(ctx, accountID, before)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockTokenRevocationRepository_RevokeAllTokens_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeAllTokens'
+type MockTokenRevocationRepository_RevokeAllTokens_Call struct {
+	*mock.Call
+}
+
+// RevokeAllTokens is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+//   - before time.Time
+func (_e *MockTokenRevocationRepository_Expecter) RevokeAllTokens(ctx interface{}, accountID interface{}, before interface{}) *MockTokenRevocationRepository_RevokeAllTokens_Call {
+	return &MockTokenRevocationRepository_RevokeAllTokens_Call{Call: _e.mock.On("RevokeAllTokens", ctx, accountID, before)}
+}
+
+func (_c *MockTokenRevocationRepository_RevokeAllTokens_Call) Run(run func(ctx context.Context, accountID uint, before time.Time)) *MockTokenRevocationRepository_RevokeAllTokens_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTokenRevocationRepository_RevokeAllTokens_Call) Return(err error) *MockTokenRevocationRepository_RevokeAllTokens_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockTokenRevocationRepository_RevokeAllTokens_Call) RunAndReturn(run func(ctx context.Context, accountID uint, before time.Time) error) *MockTokenRevocationRepository_RevokeAllTokens_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeToken provides a mock function for the type MockTokenRevocationRepository
+func (_mock *MockTokenRevocationRepository) RevokeToken(ctx context.Context, tokenID string, accountID uint, expiresAt time.Time) error {
+	ret := _mock.Called(ctx, tokenID, accountID, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeToken")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, uint, time.Time) error); ok {
+		r0 = returnFunc(ctx, tokenID, accountID, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockTokenRevocationRepository_RevokeToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeToken'
+type MockTokenRevocationRepository_RevokeToken_Call struct {
+	*mock.Call
+}
+
+// RevokeToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - tokenID string
+//   - accountID uint
+//   - expiresAt time.Time
+func (_e *MockTokenRevocationRepository_Expecter) RevokeToken(ctx interface{}, tokenID interface{}, accountID interface{}, expiresAt interface{}) *MockTokenRevocationRepository_RevokeToken_Call {
+	return &MockTokenRevocationRepository_RevokeToken_Call{Call: _e.mock.On("RevokeToken", ctx, tokenID, accountID, expiresAt)}
+}
+
+func (_c *MockTokenRevocationRepository_RevokeToken_Call) Run(run func(ctx context.Context, tokenID string, accountID uint, expiresAt time.Time)) *MockTokenRevocationRepository_RevokeToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 uint
+		if args[2] != nil {
+			arg2 = args[2].(uint)
+		}
+		var arg3 time.Time
+		if args[3] != nil {
+			arg3 = args[3].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTokenRevocationRepository_RevokeToken_Call) Return(err error) *MockTokenRevocationRepository_RevokeToken_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockTokenRevocationRepository_RevokeToken_Call) RunAndReturn(run func(ctx context.Context, tokenID string, accountID uint, expiresAt time.Time) error) *MockTokenRevocationRepository_RevokeToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockExporter creates a new instance of MockExporter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockExporter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockExporter {
+	mock := &MockExporter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockExporter is an autogenerated mock type for the Exporter type
+type MockExporter struct {
+	mock.Mock
+}
+
+type MockExporter_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockExporter) EXPECT() *MockExporter_Expecter {
+	return &MockExporter_Expecter{mock: &_m.Mock}
+}
+
+// Export provides a mock function for the type MockExporter
+func (_mock *MockExporter) Export(ctx context.Context, accountID uint) (any, error) {
+	ret := _mock.Called(ctx, accountID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Export")
+	}
+
+	var r0 any
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) (any, error)); ok {
+		return returnFunc(ctx, accountID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) any); ok {
+		r0 = returnFunc(ctx, accountID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(any)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = returnFunc(ctx, accountID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockExporter_Export_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Export'
+type MockExporter_Export_Call struct {
+	*mock.Call
+}
+
+// Export is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+func (_e *MockExporter_Expecter) Export(ctx interface{}, accountID interface{}) *MockExporter_Export_Call {
+	return &MockExporter_Export_Call{Call: _e.mock.On("Export", ctx, accountID)}
+}
+
+func (_c *MockExporter_Export_Call) Run(run func(ctx context.Context, accountID uint)) *MockExporter_Export_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockExporter_Export_Call) Return(ifaceVal any, err error) *MockExporter_Export_Call {
+	_c.Call.Return(ifaceVal, err)
+	return _c
+}
+
+func (_c *MockExporter_Export_Call) RunAndReturn(run func(ctx context.Context, accountID uint) (any, error)) *MockExporter_Export_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Name provides a mock function for the type MockExporter
+func (_mock *MockExporter) Name() string {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Name")
+	}
+
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	return r0
+}
+
+// MockExporter_Name_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Name'
+type MockExporter_Name_Call struct {
+	*mock.Call
+}
+
+// Name is a helper method to define mock.On call
+func (_e *MockExporter_Expecter) Name() *MockExporter_Name_Call {
+	return &MockExporter_Name_Call{Call: _e.mock.On("Name")}
+}
+
+func (_c *MockExporter_Name_Call) Run(run func()) *MockExporter_Name_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockExporter_Name_Call) Return(s string) *MockExporter_Name_Call {
+	_c.Call.Return(s)
+	return _c
+}
+
+func (_c *MockExporter_Name_Call) RunAndReturn(run func() string) *MockExporter_Name_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockExportRepository creates a new instance of MockExportRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockExportRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockExportRepository {
+	mock := &MockExportRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockExportRepository is an autogenerated mock type for the ExportRepository type
+type MockExportRepository struct {
+	mock.Mock
+}
+
+type MockExportRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockExportRepository) EXPECT() *MockExportRepository_Expecter {
+	return &MockExportRepository_Expecter{mock: &_m.Mock}
+}
+
+// ClaimDataExport provides a mock function for the type MockExportRepository
+func (_mock *MockExportRepository) ClaimDataExport(ctx context.Context, id uint, staleBefore time.Time) (*DataExport, error) {
+	ret := _mock.Called(ctx, id, staleBefore)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimDataExport")
+	}
+
+	var r0 *DataExport
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, time.Time) (*DataExport, error)); ok {
+		return returnFunc(ctx, id, staleBefore)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, time.Time) *DataExport); ok {
+		r0 = returnFunc(ctx, id, staleBefore)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DataExport)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint, time.Time) error); ok {
+		r1 = returnFunc(ctx, id, staleBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockExportRepository_ClaimDataExport_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ClaimDataExport'
+type MockExportRepository_ClaimDataExport_Call struct {
+	*mock.Call
+}
+
+// ClaimDataExport is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+//   - staleBefore time.Time
+func (_e *MockExportRepository_Expecter) ClaimDataExport(ctx interface{}, id interface{}, staleBefore interface{}) *MockExportRepository_ClaimDataExport_Call {
+	return &MockExportRepository_ClaimDataExport_Call{Call: _e.mock.On("ClaimDataExport", ctx, id, staleBefore)}
+}
+
+func (_c *MockExportRepository_ClaimDataExport_Call) Run(run func(ctx context.Context, id uint, staleBefore time.Time)) *MockExportRepository_ClaimDataExport_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockExportRepository_ClaimDataExport_Call) Return(dataExport *DataExport, err error) *MockExportRepository_ClaimDataExport_Call {
+	_c.Call.Return(dataExport, err)
+	return _c
+}
+
+func (_c *MockExportRepository_ClaimDataExport_Call) RunAndReturn(run func(ctx context.Context, id uint, staleBefore time.Time) (*DataExport, error)) *MockExportRepository_ClaimDataExport_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CompleteDataExport provides a mock function for the type MockExportRepository
+func (_mock *MockExportRepository) CompleteDataExport(ctx context.Context, id uint, archive []byte, completedAt time.Time, expiresAt time.Time) error {
+	ret := _mock.Called(ctx, id, archive, completedAt, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CompleteDataExport")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, []byte, time.Time, time.Time) error); ok {
+		r0 = returnFunc(ctx, id, archive, completedAt, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockExportRepository_CompleteDataExport_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CompleteDataExport'
+type MockExportRepository_CompleteDataExport_Call struct {
+	*mock.Call
+}
+
+// CompleteDataExport is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+//   - archive []byte
+//   - completedAt time.Time
+//   - expiresAt time.Time
+func (_e *MockExportRepository_Expecter) CompleteDataExport(ctx interface{}, id interface{}, archive interface{}, completedAt interface{}, expiresAt interface{}) *MockExportRepository_CompleteDataExport_Call {
+	return &MockExportRepository_CompleteDataExport_Call{Call: _e.mock.On("CompleteDataExport", ctx, id, archive, completedAt, expiresAt)}
+}
+
+func (_c *MockExportRepository_CompleteDataExport_Call) Run(run func(ctx context.Context, id uint, archive []byte, completedAt time.Time, expiresAt time.Time)) *MockExportRepository_CompleteDataExport_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 []byte
+		if args[2] != nil {
+			arg2 = args[2].([]byte)
+		}
+		var arg3 time.Time
+		if args[3] != nil {
+			arg3 = args[3].(time.Time)
+		}
+		var arg4 time.Time
+		if args[4] != nil {
+			arg4 = args[4].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+			arg4,
+		)
+	})
+	return _c
+}
+
+func (_c *MockExportRepository_CompleteDataExport_Call) Return(err error) *MockExportRepository_CompleteDataExport_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockExportRepository_CompleteDataExport_Call) RunAndReturn(run func(ctx context.Context, id uint, archive []byte, completedAt time.Time, expiresAt time.Time) error) *MockExportRepository_CompleteDataExport_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateDataExport provides a mock function for the type MockExportRepository
+func (_mock *MockExportRepository) CreateDataExport(ctx context.Context, export *DataExport) (*DataExport, error) {
+	ret := _mock.Called(ctx, export)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateDataExport")
+	}
+
+	var r0 *DataExport
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *DataExport) (*DataExport, error)); ok {
+		return returnFunc(ctx, export)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *DataExport) *DataExport); ok {
+		r0 = returnFunc(ctx, export)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DataExport)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *DataExport) error); ok {
+		r1 = returnFunc(ctx, export)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockExportRepository_CreateDataExport_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateDataExport'
+type MockExportRepository_CreateDataExport_Call struct {
+	*mock.Call
+}
+
+// CreateDataExport is a helper method to define mock.On call
+//   - ctx context.Context
+//   - export *DataExport
+func (_e *MockExportRepository_Expecter) CreateDataExport(ctx interface{}, export interface{}) *MockExportRepository_CreateDataExport_Call {
+	return &MockExportRepository_CreateDataExport_Call{Call: _e.mock.On("CreateDataExport", ctx, export)}
+}
+
+func (_c *MockExportRepository_CreateDataExport_Call) Run(run func(ctx context.Context, export *DataExport)) *MockExportRepository_CreateDataExport_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *DataExport
+		if args[1] != nil {
+			arg1 = args[1].(*DataExport)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockExportRepository_CreateDataExport_Call) Return(dataExport *DataExport, err error) *MockExportRepository_CreateDataExport_Call {
+	_c.Call.Return(dataExport, err)
+	return _c
+}
+
+func (_c *MockExportRepository_CreateDataExport_Call) RunAndReturn(run func(ctx context.Context, export *DataExport) (*DataExport, error)) *MockExportRepository_CreateDataExport_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteExpiredDataExports provides a mock function for the type MockExportRepository
+func (_mock *MockExportRepository) DeleteExpiredDataExports(ctx context.Context, now time.Time) (int64, error) {
+	ret := _mock.Called(ctx, now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpiredDataExports")
+	}
+
+	var r0 int64
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return returnFunc(ctx, now)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = returnFunc(ctx, now)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = returnFunc(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockExportRepository_DeleteExpiredDataExports_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteExpiredDataExports'
+type MockExportRepository_DeleteExpiredDataExports_Call struct {
+	*mock.Call
+}
+
+// DeleteExpiredDataExports is a helper method to define mock.On call
+//   - ctx context.Context
+//   - now time.Time
+func (_e *MockExportRepository_Expecter) DeleteExpiredDataExports(ctx interface{}, now interface{}) *MockExportRepository_DeleteExpiredDataExports_Call {
+	return &MockExportRepository_DeleteExpiredDataExports_Call{Call: _e.mock.On("DeleteExpiredDataExports", ctx, now)}
+}
+
+func (_c *MockExportRepository_DeleteExpiredDataExports_Call) Run(run func(ctx context.Context, now time.Time)) *MockExportRepository_DeleteExpiredDataExports_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 time.Time
+		if args[1] != nil {
+			arg1 = args[1].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockExportRepository_DeleteExpiredDataExports_Call) Return(n int64, err error) *MockExportRepository_DeleteExpiredDataExports_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockExportRepository_DeleteExpiredDataExports_Call) RunAndReturn(run func(ctx context.Context, now time.Time) (int64, error)) *MockExportRepository_DeleteExpiredDataExports_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FailDataExport provides a mock function for the type MockExportRepository
+func (_mock *MockExportRepository) FailDataExport(ctx context.Context, id uint, completedAt time.Time, expiresAt time.Time) error {
+	ret := _mock.Called(ctx, id, completedAt, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FailDataExport")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, time.Time, time.Time) error); ok {
+		r0 = returnFunc(ctx, id, completedAt, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockExportRepository_FailDataExport_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FailDataExport'
+type MockExportRepository_FailDataExport_Call struct {
+	*mock.Call
+}
+
+// FailDataExport is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+//   - completedAt time.Time
+//   - expiresAt time.Time
+func (_e *MockExportRepository_Expecter) FailDataExport(ctx interface{}, id interface{}, completedAt interface{}, expiresAt interface{}) *MockExportRepository_FailDataExport_Call {
+	return &MockExportRepository_FailDataExport_Call{Call: _e.mock.On("FailDataExport", ctx, id, completedAt, expiresAt)}
+}
+
+func (_c *MockExportRepository_FailDataExport_Call) Run(run func(ctx context.Context, id uint, completedAt time.Time, expiresAt time.Time)) *MockExportRepository_FailDataExport_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 time.Time
+		if args[2] != nil {
+			arg2 = args[2].(time.Time)
+		}
+		var arg3 time.Time
+		if args[3] != nil {
+			arg3 = args[3].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockExportRepository_FailDataExport_Call) Return(err error) *MockExportRepository_FailDataExport_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockExportRepository_FailDataExport_Call) RunAndReturn(run func(ctx context.Context, id uint, completedAt time.Time, expiresAt time.Time) error) *MockExportRepository_FailDataExport_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAccountDataExport provides a mock function for the type MockExportRepository
+func (_mock *MockExportRepository) GetAccountDataExport(ctx context.Context, accountID uint, id uint) (*DataExport, error) {
+	ret := _mock.Called(ctx, accountID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAccountDataExport")
+	}
+
+	var r0 *DataExport
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, uint) (*DataExport, error)); ok {
+		return returnFunc(ctx, accountID, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, uint) *DataExport); ok {
+		r0 = returnFunc(ctx, accountID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DataExport)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint, uint) error); ok {
+		r1 = returnFunc(ctx, accountID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockExportRepository_GetAccountDataExport_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAccountDataExport'
+type MockExportRepository_GetAccountDataExport_Call struct {
+	*mock.Call
+}
+
+// GetAccountDataExport is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+//   - id uint
+func (_e *MockExportRepository_Expecter) GetAccountDataExport(ctx interface{}, accountID interface{}, id interface{}) *MockExportRepository_GetAccountDataExport_Call {
+	return &MockExportRepository_GetAccountDataExport_Call{Call: _e.mock.On("GetAccountDataExport", ctx, accountID, id)}
+}
+
+func (_c *MockExportRepository_GetAccountDataExport_Call) Run(run func(ctx context.Context, accountID uint, id uint)) *MockExportRepository_GetAccountDataExport_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 uint
+		if args[2] != nil {
+			arg2 = args[2].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockExportRepository_GetAccountDataExport_Call) Return(dataExport *DataExport, err error) *MockExportRepository_GetAccountDataExport_Call {
+	_c.Call.Return(dataExport, err)
+	return _c
+}
+
+func (_c *MockExportRepository_GetAccountDataExport_Call) RunAndReturn(run func(ctx context.Context, accountID uint, id uint) (*DataExport, error)) *MockExportRepository_GetAccountDataExport_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetDataExport provides a mock function for the type MockExportRepository
+func (_mock *MockExportRepository) GetDataExport(ctx context.Context, id uint) (*DataExport, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDataExport")
+	}
+
+	var r0 *DataExport
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) (*DataExport, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) *DataExport); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DataExport)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockExportRepository_GetDataExport_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetDataExport'
+type MockExportRepository_GetDataExport_Call struct {
+	*mock.Call
+}
+
+// GetDataExport is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+func (_e *MockExportRepository_Expecter) GetDataExport(ctx interface{}, id interface{}) *MockExportRepository_GetDataExport_Call {
+	return &MockExportRepository_GetDataExport_Call{Call: _e.mock.On("GetDataExport", ctx, id)}
+}
+
+func (_c *MockExportRepository_GetDataExport_Call) Run(run func(ctx context.Context, id uint)) *MockExportRepository_GetDataExport_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockExportRepository_GetDataExport_Call) Return(dataExport *DataExport, err error) *MockExportRepository_GetDataExport_Call {
+	_c.Call.Return(dataExport, err)
+	return _c
+}
+
+func (_c *MockExportRepository_GetDataExport_Call) RunAndReturn(run func(ctx context.Context, id uint) (*DataExport, error)) *MockExportRepository_GetDataExport_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListClaimableDataExports provides a mock function for the type MockExportRepository
+func (_mock *MockExportRepository) ListClaimableDataExports(ctx context.Context, staleBefore time.Time, limit int) ([]uint, error) {
+	ret := _mock.Called(ctx, staleBefore, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListClaimableDataExports")
+	}
+
+	var r0 []uint
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time, int) ([]uint, error)); ok {
+		return returnFunc(ctx, staleBefore, limit)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time, int) []uint); ok {
+		r0 = returnFunc(ctx, staleBefore, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uint)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, time.Time, int) error); ok {
+		r1 = returnFunc(ctx, staleBefore, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockExportRepository_ListClaimableDataExports_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListClaimableDataExports'
+type MockExportRepository_ListClaimableDataExports_Call struct {
+	*mock.Call
+}
+
+// ListClaimableDataExports is a helper method to define mock.On call
+//   - ctx context.Context
+//   - staleBefore time.Time
+//   - limit int
+func (_e *MockExportRepository_Expecter) ListClaimableDataExports(ctx interface{}, staleBefore interface{}, limit interface{}) *MockExportRepository_ListClaimableDataExports_Call {
+	return &MockExportRepository_ListClaimableDataExports_Call{Call: _e.mock.On("ListClaimableDataExports", ctx, staleBefore, limit)}
+}
+
+func (_c *MockExportRepository_ListClaimableDataExports_Call) Run(run func(ctx context.Context, staleBefore time.Time, limit int)) *MockExportRepository_ListClaimableDataExports_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 time.Time
+		if args[1] != nil {
+			arg1 = args[1].(time.Time)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockExportRepository_ListClaimableDataExports_Call) Return(uints []uint, err error) *MockExportRepository_ListClaimableDataExports_Call {
+	_c.Call.Return(uints, err)
+	return _c
+}
+
+func (_c *MockExportRepository_ListClaimableDataExports_Call) RunAndReturn(run func(ctx context.Context, staleBefore time.Time, limit int) ([]uint, error)) *MockExportRepository_ListClaimableDataExports_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockOrganizationRepository creates a new instance of MockOrganizationRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockOrganizationRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockOrganizationRepository {
+	mock := &MockOrganizationRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockOrganizationRepository is an autogenerated mock type for the OrganizationRepository type
+type MockOrganizationRepository struct {
+	mock.Mock
+}
+
+type MockOrganizationRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockOrganizationRepository) EXPECT() *MockOrganizationRepository_Expecter {
+	return &MockOrganizationRepository_Expecter{mock: &_m.Mock}
+}
+
+// AcceptInvitation provides a mock function for the type MockOrganizationRepository
+func (_mock *MockOrganizationRepository) AcceptInvitation(ctx context.Context, invitation *Invitation, accountID uint) error {
+	ret := _mock.Called(ctx, invitation, accountID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AcceptInvitation")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Invitation, uint) error); ok {
+		r0 = returnFunc(ctx, invitation, accountID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockOrganizationRepository_AcceptInvitation_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AcceptInvitation'
+type MockOrganizationRepository_AcceptInvitation_Call struct {
+	*mock.Call
+}
+
+// AcceptInvitation is a helper method to define mock.On call
+//   - ctx context.Context
+//   - invitation *Invitation
+//   - accountID uint
+func (_e *MockOrganizationRepository_Expecter) AcceptInvitation(ctx interface{}, invitation interface{}, accountID interface{}) *MockOrganizationRepository_AcceptInvitation_Call {
+	return &MockOrganizationRepository_AcceptInvitation_Call{Call: _e.mock.On("AcceptInvitation", ctx, invitation, accountID)}
+}
+
+func (_c *MockOrganizationRepository_AcceptInvitation_Call) Run(run func(ctx context.Context, invitation *Invitation, accountID uint)) *MockOrganizationRepository_AcceptInvitation_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *Invitation
+		if args[1] != nil {
+			arg1 = args[1].(*Invitation)
+		}
+		var arg2 uint
+		if args[2] != nil {
+			arg2 = args[2].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrganizationRepository_AcceptInvitation_Call) Return(err error) *MockOrganizationRepository_AcceptInvitation_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockOrganizationRepository_AcceptInvitation_Call) RunAndReturn(run func(ctx context.Context, invitation *Invitation, accountID uint) error) *MockOrganizationRepository_AcceptInvitation_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateInvitation provides a mock function for the type MockOrganizationRepository
+func (_mock *MockOrganizationRepository) CreateInvitation(ctx context.Context, invitation *Invitation) (*Invitation, error) {
+	ret := _mock.Called(ctx, invitation)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateInvitation")
+	}
+
+	var r0 *Invitation
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Invitation) (*Invitation, error)); ok {
+		return returnFunc(ctx, invitation)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Invitation) *Invitation); ok {
+		r0 = returnFunc(ctx, invitation)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Invitation)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *Invitation) error); ok {
+		r1 = returnFunc(ctx, invitation)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrganizationRepository_CreateInvitation_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateInvitation'
+type MockOrganizationRepository_CreateInvitation_Call struct {
+	*mock.Call
+}
+
+// CreateInvitation is a helper method to define mock.On call
+//   - ctx context.Context
+//   - invitation *Invitation
+func (_e *MockOrganizationRepository_Expecter) CreateInvitation(ctx interface{}, invitation interface{}) *MockOrganizationRepository_CreateInvitation_Call {
+	return &MockOrganizationRepository_CreateInvitation_Call{Call: _e.mock.On("CreateInvitation", ctx, invitation)}
+}
+
+func (_c *MockOrganizationRepository_CreateInvitation_Call) Run(run func(ctx context.Context, invitation *Invitation)) *MockOrganizationRepository_CreateInvitation_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *Invitation
+		if args[1] != nil {
+			arg1 = args[1].(*Invitation)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrganizationRepository_CreateInvitation_Call) Return(invitation1 *Invitation, err error) *MockOrganizationRepository_CreateInvitation_Call {
+	_c.Call.Return(invitation1, err)
+	return _c
+}
+
+func (_c *MockOrganizationRepository_CreateInvitation_Call) RunAndReturn(run func(ctx context.Context, invitation *Invitation) (*Invitation, error)) *MockOrganizationRepository_CreateInvitation_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateOrganization provides a mock function for the type MockOrganizationRepository
+func (_mock *MockOrganizationRepository) CreateOrganization(ctx context.Context, organization *Organization, ownerID uint) (*Organization, error) {
+	ret := _mock.Called(ctx, organization, ownerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateOrganization")
+	}
+
+	var r0 *Organization
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Organization, uint) (*Organization, error)); ok {
+		return returnFunc(ctx, organization, ownerID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *Organization, uint) *Organization); ok {
+		r0 = returnFunc(ctx, organization, ownerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Organization)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *Organization, uint) error); ok {
+		r1 = returnFunc(ctx, organization, ownerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrganizationRepository_CreateOrganization_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateOrganization'
+type MockOrganizationRepository_CreateOrganization_Call struct {
+	*mock.Call
+}
+
+// CreateOrganization is a helper method to define mock.On call
+//   - ctx context.Context
+//   - organization *Organization
+//   - ownerID uint
+func (_e *MockOrganizationRepository_Expecter) CreateOrganization(ctx interface{}, organization interface{}, ownerID interface{}) *MockOrganizationRepository_CreateOrganization_Call {
+	return &MockOrganizationRepository_CreateOrganization_Call{Call: _e.mock.On("CreateOrganization", ctx, organization, ownerID)}
+}
+
+func (_c *MockOrganizationRepository_CreateOrganization_Call) Run(run func(ctx context.Context, organization *Organization, ownerID uint)) *MockOrganizationRepository_CreateOrganization_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 *Organization
+		if args[1] != nil {
+			arg1 = args[1].(*Organization)
+		}
+		var arg2 uint
+		if args[2] != nil {
+			arg2 = args[2].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrganizationRepository_CreateOrganization_Call) Return(organization1 *Organization, err error) *MockOrganizationRepository_CreateOrganization_Call {
+	_c.Call.Return(organization1, err)
+	return _c
+}
+
+func (_c *MockOrganizationRepository_CreateOrganization_Call) RunAndReturn(run func(ctx context.Context, organization *Organization, ownerID uint) (*Organization, error)) *MockOrganizationRepository_CreateOrganization_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeclineInvitation provides a mock function for the type MockOrganizationRepository
+func (_mock *MockOrganizationRepository) DeclineInvitation(ctx context.Context, id uint) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeclineInvitation")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockOrganizationRepository_DeclineInvitation_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeclineInvitation'
+type MockOrganizationRepository_DeclineInvitation_Call struct {
+	*mock.Call
+}
+
+// DeclineInvitation is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+func (_e *MockOrganizationRepository_Expecter) DeclineInvitation(ctx interface{}, id interface{}) *MockOrganizationRepository_DeclineInvitation_Call {
+	return &MockOrganizationRepository_DeclineInvitation_Call{Call: _e.mock.On("DeclineInvitation", ctx, id)}
+}
+
+func (_c *MockOrganizationRepository_DeclineInvitation_Call) Run(run func(ctx context.Context, id uint)) *MockOrganizationRepository_DeclineInvitation_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrganizationRepository_DeclineInvitation_Call) Return(err error) *MockOrganizationRepository_DeclineInvitation_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockOrganizationRepository_DeclineInvitation_Call) RunAndReturn(run func(ctx context.Context, id uint) error) *MockOrganizationRepository_DeclineInvitation_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetInvitationByID provides a mock function for the type MockOrganizationRepository
+func (_mock *MockOrganizationRepository) GetInvitationByID(ctx context.Context, id uint) (*Invitation, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetInvitationByID")
+	}
+
+	var r0 *Invitation
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) (*Invitation, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) *Invitation); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Invitation)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrganizationRepository_GetInvitationByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetInvitationByID'
+type MockOrganizationRepository_GetInvitationByID_Call struct {
+	*mock.Call
+}
+
+// GetInvitationByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+func (_e *MockOrganizationRepository_Expecter) GetInvitationByID(ctx interface{}, id interface{}) *MockOrganizationRepository_GetInvitationByID_Call {
+	return &MockOrganizationRepository_GetInvitationByID_Call{Call: _e.mock.On("GetInvitationByID", ctx, id)}
+}
+
+func (_c *MockOrganizationRepository_GetInvitationByID_Call) Run(run func(ctx context.Context, id uint)) *MockOrganizationRepository_GetInvitationByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrganizationRepository_GetInvitationByID_Call) Return(invitation *Invitation, err error) *MockOrganizationRepository_GetInvitationByID_Call {
+	_c.Call.Return(invitation, err)
+	return _c
+}
+
+func (_c *MockOrganizationRepository_GetInvitationByID_Call) RunAndReturn(run func(ctx context.Context, id uint) (*Invitation, error)) *MockOrganizationRepository_GetInvitationByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetMembership provides a mock function for the type MockOrganizationRepository
+func (_mock *MockOrganizationRepository) GetMembership(ctx context.Context, organizationID uint, accountID uint) (*Membership, error) {
+	ret := _mock.Called(ctx, organizationID, accountID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMembership")
+	}
+
+	var r0 *Membership
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, uint) (*Membership, error)); ok {
+		return returnFunc(ctx, organizationID, accountID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, uint) *Membership); ok {
+		r0 = returnFunc(ctx, organizationID, accountID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Membership)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint, uint) error); ok {
+		r1 = returnFunc(ctx, organizationID, accountID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrganizationRepository_GetMembership_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetMembership'
+type MockOrganizationRepository_GetMembership_Call struct {
+	*mock.Call
+}
+
+// GetMembership is a helper method to define mock.On call
+//   - ctx context.Context
+//   - organizationID uint
+//   - accountID uint
+func (_e *MockOrganizationRepository_Expecter) GetMembership(ctx interface{}, organizationID interface{}, accountID interface{}) *MockOrganizationRepository_GetMembership_Call {
+	return &MockOrganizationRepository_GetMembership_Call{Call: _e.mock.On("GetMembership", ctx, organizationID, accountID)}
+}
+
+func (_c *MockOrganizationRepository_GetMembership_Call) Run(run func(ctx context.Context, organizationID uint, accountID uint)) *MockOrganizationRepository_GetMembership_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 uint
+		if args[2] != nil {
+			arg2 = args[2].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrganizationRepository_GetMembership_Call) Return(membership *Membership, err error) *MockOrganizationRepository_GetMembership_Call {
+	_c.Call.Return(membership, err)
+	return _c
+}
+
+func (_c *MockOrganizationRepository_GetMembership_Call) RunAndReturn(run func(ctx context.Context, organizationID uint, accountID uint) (*Membership, error)) *MockOrganizationRepository_GetMembership_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetOrganizationByID provides a mock function for the type MockOrganizationRepository
+func (_mock *MockOrganizationRepository) GetOrganizationByID(ctx context.Context, id uint) (*Organization, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrganizationByID")
+	}
+
+	var r0 *Organization
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) (*Organization, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) *Organization); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Organization)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrganizationRepository_GetOrganizationByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetOrganizationByID'
+type MockOrganizationRepository_GetOrganizationByID_Call struct {
+	*mock.Call
+}
+
+// GetOrganizationByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+func (_e *MockOrganizationRepository_Expecter) GetOrganizationByID(ctx interface{}, id interface{}) *MockOrganizationRepository_GetOrganizationByID_Call {
+	return &MockOrganizationRepository_GetOrganizationByID_Call{Call: _e.mock.On("GetOrganizationByID", ctx, id)}
+}
+
+func (_c *MockOrganizationRepository_GetOrganizationByID_Call) Run(run func(ctx context.Context, id uint)) *MockOrganizationRepository_GetOrganizationByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrganizationRepository_GetOrganizationByID_Call) Return(organization *Organization, err error) *MockOrganizationRepository_GetOrganizationByID_Call {
+	_c.Call.Return(organization, err)
+	return _c
+}
+
+func (_c *MockOrganizationRepository_GetOrganizationByID_Call) RunAndReturn(run func(ctx context.Context, id uint) (*Organization, error)) *MockOrganizationRepository_GetOrganizationByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListAccountMemberships provides a mock function for the type MockOrganizationRepository
+func (_mock *MockOrganizationRepository) ListAccountMemberships(ctx context.Context, accountID uint) ([]Membership, error) {
+	ret := _mock.Called(ctx, accountID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAccountMemberships")
+	}
+
+	var r0 []Membership
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) ([]Membership, error)); ok {
+		return returnFunc(ctx, accountID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) []Membership); ok {
+		r0 = returnFunc(ctx, accountID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Membership)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = returnFunc(ctx, accountID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrganizationRepository_ListAccountMemberships_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAccountMemberships'
+type MockOrganizationRepository_ListAccountMemberships_Call struct {
+	*mock.Call
+}
+
+// ListAccountMemberships is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+func (_e *MockOrganizationRepository_Expecter) ListAccountMemberships(ctx interface{}, accountID interface{}) *MockOrganizationRepository_ListAccountMemberships_Call {
+	return &MockOrganizationRepository_ListAccountMemberships_Call{Call: _e.mock.On("ListAccountMemberships", ctx, accountID)}
+}
+
+func (_c *MockOrganizationRepository_ListAccountMemberships_Call) Run(run func(ctx context.Context, accountID uint)) *MockOrganizationRepository_ListAccountMemberships_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrganizationRepository_ListAccountMemberships_Call) Return(memberships []Membership, err error) *MockOrganizationRepository_ListAccountMemberships_Call {
+	_c.Call.Return(memberships, err)
+	return _c
+}
+
+func (_c *MockOrganizationRepository_ListAccountMemberships_Call) RunAndReturn(run func(ctx context.Context, accountID uint) ([]Membership, error)) *MockOrganizationRepository_ListAccountMemberships_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListMembers provides a mock function for the type MockOrganizationRepository
+func (_mock *MockOrganizationRepository) ListMembers(ctx context.Context, organizationID uint) ([]Membership, error) {
+	ret := _mock.Called(ctx, organizationID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListMembers")
+	}
+
+	var r0 []Membership
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) ([]Membership, error)); ok {
+		return returnFunc(ctx, organizationID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) []Membership); ok {
+		r0 = returnFunc(ctx, organizationID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Membership)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = returnFunc(ctx, organizationID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrganizationRepository_ListMembers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListMembers'
+type MockOrganizationRepository_ListMembers_Call struct {
+	*mock.Call
+}
+
+// ListMembers is a helper method to define mock.On call
+//   - ctx context.Context
+//   - organizationID uint
+func (_e *MockOrganizationRepository_Expecter) ListMembers(ctx interface{}, organizationID interface{}) *MockOrganizationRepository_ListMembers_Call {
+	return &MockOrganizationRepository_ListMembers_Call{Call: _e.mock.On("ListMembers", ctx, organizationID)}
+}
+
+func (_c *MockOrganizationRepository_ListMembers_Call) Run(run func(ctx context.Context, organizationID uint)) *MockOrganizationRepository_ListMembers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrganizationRepository_ListMembers_Call) Return(memberships []Membership, err error) *MockOrganizationRepository_ListMembers_Call {
+	_c.Call.Return(memberships, err)
+	return _c
+}
+
+func (_c *MockOrganizationRepository_ListMembers_Call) RunAndReturn(run func(ctx context.Context, organizationID uint) ([]Membership, error)) *MockOrganizationRepository_ListMembers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListPendingInvitations provides a mock function for the type MockOrganizationRepository
+func (_mock *MockOrganizationRepository) ListPendingInvitations(ctx context.Context) ([]Invitation, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListPendingInvitations")
+	}
+
+	var r0 []Invitation
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]Invitation, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []Invitation); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Invitation)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrganizationRepository_ListPendingInvitations_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListPendingInvitations'
+type MockOrganizationRepository_ListPendingInvitations_Call struct {
+	*mock.Call
+}
+
+// ListPendingInvitations is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockOrganizationRepository_Expecter) ListPendingInvitations(ctx interface{}) *MockOrganizationRepository_ListPendingInvitations_Call {
+	return &MockOrganizationRepository_ListPendingInvitations_Call{Call: _e.mock.On("ListPendingInvitations", ctx)}
+}
+
+func (_c *MockOrganizationRepository_ListPendingInvitations_Call) Run(run func(ctx context.Context)) *MockOrganizationRepository_ListPendingInvitations_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrganizationRepository_ListPendingInvitations_Call) Return(invitations []Invitation, err error) *MockOrganizationRepository_ListPendingInvitations_Call {
+	_c.Call.Return(invitations, err)
+	return _c
+}
+
+func (_c *MockOrganizationRepository_ListPendingInvitations_Call) RunAndReturn(run func(ctx context.Context) ([]Invitation, error)) *MockOrganizationRepository_ListPendingInvitations_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RemoveMember provides a mock function for the type MockOrganizationRepository
+func (_mock *MockOrganizationRepository) RemoveMember(ctx context.Context, organizationID uint, accountID uint) error {
+	ret := _mock.Called(ctx, organizationID, accountID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveMember")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
+		r0 = returnFunc(ctx, organizationID, accountID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockOrganizationRepository_RemoveMember_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveMember'
+type MockOrganizationRepository_RemoveMember_Call struct {
+	*mock.Call
+}
+
+// RemoveMember is a helper method to define mock.On call
+//   - ctx context.Context
+//   - organizationID uint
+//   - accountID uint
+func (_e *MockOrganizationRepository_Expecter) RemoveMember(ctx interface{}, organizationID interface{}, accountID interface{}) *MockOrganizationRepository_RemoveMember_Call {
+	return &MockOrganizationRepository_RemoveMember_Call{Call: _e.mock.On("RemoveMember", ctx, organizationID, accountID)}
+}
+
+func (_c *MockOrganizationRepository_RemoveMember_Call) Run(run func(ctx context.Context, organizationID uint, accountID uint)) *MockOrganizationRepository_RemoveMember_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 uint
+		if args[2] != nil {
+			arg2 = args[2].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrganizationRepository_RemoveMember_Call) Return(err error) *MockOrganizationRepository_RemoveMember_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockOrganizationRepository_RemoveMember_Call) RunAndReturn(run func(ctx context.Context, organizationID uint, accountID uint) error) *MockOrganizationRepository_RemoveMember_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeInvitation provides a mock function for the type MockOrganizationRepository
+func (_mock *MockOrganizationRepository) RevokeInvitation(ctx context.Context, id uint) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeInvitation")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockOrganizationRepository_RevokeInvitation_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeInvitation'
+type MockOrganizationRepository_RevokeInvitation_Call struct {
+	*mock.Call
+}
+
+// RevokeInvitation is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id uint
+func (_e *MockOrganizationRepository_Expecter) RevokeInvitation(ctx interface{}, id interface{}) *MockOrganizationRepository_RevokeInvitation_Call {
+	return &MockOrganizationRepository_RevokeInvitation_Call{Call: _e.mock.On("RevokeInvitation", ctx, id)}
+}
+
+func (_c *MockOrganizationRepository_RevokeInvitation_Call) Run(run func(ctx context.Context, id uint)) *MockOrganizationRepository_RevokeInvitation_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockOrganizationRepository_RevokeInvitation_Call) Return(err error) *MockOrganizationRepository_RevokeInvitation_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockOrganizationRepository_RevokeInvitation_Call) RunAndReturn(run func(ctx context.Context, id uint) error) *MockOrganizationRepository_RevokeInvitation_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRoleRepository creates a new instance of MockRoleRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRoleRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRoleRepository {
+	mock := &MockRoleRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockRoleRepository is an autogenerated mock type for the RoleRepository type
+type MockRoleRepository struct {
+	mock.Mock
+}
+
+type MockRoleRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRoleRepository) EXPECT() *MockRoleRepository_Expecter {
+	return &MockRoleRepository_Expecter{mock: &_m.Mock}
+}
+
+// AssignRole provides a mock function for the type MockRoleRepository
+func (_mock *MockRoleRepository) AssignRole(ctx context.Context, accountID uint, roleID uint) error {
+	ret := _mock.Called(ctx, accountID, roleID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AssignRole")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
+		r0 = returnFunc(ctx, accountID, roleID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRoleRepository_AssignRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AssignRole'
+type MockRoleRepository_AssignRole_Call struct {
+	*mock.Call
+}
+
+// AssignRole is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+//   - roleID uint
+func (_e *MockRoleRepository_Expecter) AssignRole(ctx interface{}, accountID interface{}, roleID interface{}) *MockRoleRepository_AssignRole_Call {
+	return &MockRoleRepository_AssignRole_Call{Call: _e.mock.On("AssignRole", ctx, accountID, roleID)}
+}
+
+func (_c *MockRoleRepository_AssignRole_Call) Run(run func(ctx context.Context, accountID uint, roleID uint)) *MockRoleRepository_AssignRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
+		if args[1] != nil {
+			arg1 = args[1].(uint)
+		}
+		var arg2 uint
+		if args[2] != nil {
+			arg2 = args[2].(uint)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRoleRepository_AssignRole_Call) Return(err error) *MockRoleRepository_AssignRole_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRoleRepository_AssignRole_Call) RunAndReturn(run func(ctx context.Context, accountID uint, roleID uint) error) *MockRoleRepository_AssignRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EnsureRole provides a mock function for the type MockRoleRepository
+func (_mock *MockRoleRepository) EnsureRole(ctx context.Context, name string, description string, permissions []string) (*Role, error) {
+	ret := _mock.Called(ctx, name, description, permissions)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EnsureRole")
+	}
+
+	var r0 *Role
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, []string) (*Role, error)); ok {
+		return returnFunc(ctx, name, description, permissions)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, []string) *Role); ok {
+		r0 = returnFunc(ctx, name, description, permissions)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Role)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string, []string) error); ok {
+		r1 = returnFunc(ctx, name, description, permissions)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockAccountRepository_GetAccountByEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAccountByEmail'
-type MockAccountRepository_GetAccountByEmail_Call struct {
+// MockRoleRepository_EnsureRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EnsureRole'
+type MockRoleRepository_EnsureRole_Call struct {
 	*mock.Call
 }
 
-// GetAccountByEmail is a helper method to define mock.On call
+// EnsureRole is a helper method to define mock.On call
 //   - ctx context.Context
-//   - email string
-func (_e *MockAccountRepository_Expecter) GetAccountByEmail(ctx interface{}, email interface{}) *MockAccountRepository_GetAccountByEmail_Call {
-	return &MockAccountRepository_GetAccountByEmail_Call{Call: _e.mock.On("GetAccountByEmail", ctx, email)}
+//   - name string
+//   - description string
+//   - permissions []string
+func (_e *MockRoleRepository_Expecter) EnsureRole(ctx interface{}, name interface{}, description interface{}, permissions interface{}) *MockRoleRepository_EnsureRole_Call {
+	return &MockRoleRepository_EnsureRole_Call{Call: _e.mock.On("EnsureRole", ctx, name, description, permissions)}
 }
 
-func (_c *MockAccountRepository_GetAccountByEmail_Call) Run(run func(ctx context.Context, email string)) *MockAccountRepository_GetAccountByEmail_Call {
+func (_c *MockRoleRepository_EnsureRole_Call) Run(run func(ctx context.Context, name string, description string, permissions []string)) *MockRoleRepository_EnsureRole_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -704,65 +9282,75 @@ func (_c *MockAccountRepository_GetAccountByEmail_Call) Run(run func(ctx context
 		if args[1] != nil {
 			arg1 = args[1].(string)
 		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 []string
+		if args[3] != nil {
+			arg3 = args[3].([]string)
+		}
 		run(
 			arg0,
 			arg1,
+			arg2,
+			arg3,
 		)
 	})
 	return _c
 }
 
-func (_c *MockAccountRepository_GetAccountByEmail_Call) Return(account *Account, err error) *MockAccountRepository_GetAccountByEmail_Call {
-	_c.Call.Return(account, err)
+func (_c *MockRoleRepository_EnsureRole_Call) Return(role *Role, err error) *MockRoleRepository_EnsureRole_Call {
+	_c.Call.Return(role, err)
 	return _c
 }
 
-func (_c *MockAccountRepository_GetAccountByEmail_Call) RunAndReturn(run func(ctx context.Context, email string) (*Account, error)) *MockAccountRepository_GetAccountByEmail_Call {
+func (_c *MockRoleRepository_EnsureRole_Call) RunAndReturn(run func(ctx context.Context, name string, description string, permissions []string) (*Role, error)) *MockRoleRepository_EnsureRole_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetAccountByID provides a mock function for the type MockAccountRepository
-func (_mock *MockAccountRepository) GetAccountByID(ctx context.Context, id uint) (*Account, error) {
-	ret := _mock.Called(ctx, id)
+// GetAccountPermissions provides a mock function for the type MockRoleRepository
+func (_mock *MockRoleRepository) GetAccountPermissions(ctx context.Context, accountID uint) ([]string, error) {
+	ret := _mock.Called(ctx, accountID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetAccountByID")
+		panic("no return value specified for GetAccountPermissions")
 	}
 
-	var r0 *Account
+	var r0 []string
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) (*Account, error)); ok {
-		return returnFunc(ctx, id)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) ([]string, error)); ok {
+		return returnFunc(ctx, accountID)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) *Account); ok {
-		r0 = returnFunc(ctx, id)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) []string); ok {
+		r0 = returnFunc(ctx, accountID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*Account)
+			r0 = ret.Get(0).([]string)
 		}
 	}
 	if returnFunc, ok := ret.Get(1).(func(context.Context, uint) error); ok {
-		r1 = returnFunc(ctx, id)
+		r1 = returnFunc(ctx, accountID)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockAccountRepository_GetAccountByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAccountByID'
-type MockAccountRepository_GetAccountByID_Call struct {
+// MockRoleRepository_GetAccountPermissions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAccountPermissions'
+type MockRoleRepository_GetAccountPermissions_Call struct {
 	*mock.Call
 }
 
-// GetAccountByID is a helper method to define mock.On call
+// GetAccountPermissions is a helper method to define mock.On call
 //   - ctx context.Context
-//   - id uint
-func (_e *MockAccountRepository_Expecter) GetAccountByID(ctx interface{}, id interface{}) *MockAccountRepository_GetAccountByID_Call {
-	return &MockAccountRepository_GetAccountByID_Call{Call: _e.mock.On("GetAccountByID", ctx, id)}
+//   - accountID uint
+func (_e *MockRoleRepository_Expecter) GetAccountPermissions(ctx interface{}, accountID interface{}) *MockRoleRepository_GetAccountPermissions_Call {
+	return &MockRoleRepository_GetAccountPermissions_Call{Call: _e.mock.On("GetAccountPermissions", ctx, accountID)}
 }
 
-func (_c *MockAccountRepository_GetAccountByID_Call) Run(run func(ctx context.Context, id uint)) *MockAccountRepository_GetAccountByID_Call {
+func (_c *MockRoleRepository_GetAccountPermissions_Call) Run(run func(ctx context.Context, accountID uint)) *MockRoleRepository_GetAccountPermissions_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -780,47 +9368,125 @@ func (_c *MockAccountRepository_GetAccountByID_Call) Run(run func(ctx context.Co
 	return _c
 }
 
-func (_c *MockAccountRepository_GetAccountByID_Call) Return(account *Account, err error) *MockAccountRepository_GetAccountByID_Call {
-	_c.Call.Return(account, err)
+func (_c *MockRoleRepository_GetAccountPermissions_Call) Return(strings []string, err error) *MockRoleRepository_GetAccountPermissions_Call {
+	_c.Call.Return(strings, err)
 	return _c
 }
 
-func (_c *MockAccountRepository_GetAccountByID_Call) RunAndReturn(run func(ctx context.Context, id uint) (*Account, error)) *MockAccountRepository_GetAccountByID_Call {
+func (_c *MockRoleRepository_GetAccountPermissions_Call) RunAndReturn(run func(ctx context.Context, accountID uint) ([]string, error)) *MockRoleRepository_GetAccountPermissions_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// LogAccountActivity provides a mock function for the type MockAccountRepository
-func (_mock *MockAccountRepository) LogAccountActivity(ctx context.Context, accountID uint, activity string) error {
-	ret := _mock.Called(ctx, accountID, activity)
+// GetRoleByName provides a mock function for the type MockRoleRepository
+func (_mock *MockRoleRepository) GetRoleByName(ctx context.Context, name string) (*Role, error) {
+	ret := _mock.Called(ctx, name)
 
 	if len(ret) == 0 {
-		panic("no return value specified for LogAccountActivity")
+		panic("no return value specified for GetRoleByName")
 	}
 
-	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, string) error); ok {
-		r0 = returnFunc(ctx, accountID, activity)
+	var r0 *Role
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*Role, error)); ok {
+		return returnFunc(ctx, name)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *Role); ok {
+		r0 = returnFunc(ctx, name)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Role)
+		}
 	}
-	return r0
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
 }
 
-// MockAccountRepository_LogAccountActivity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LogAccountActivity'
-type MockAccountRepository_LogAccountActivity_Call struct {
+// MockRoleRepository_GetRoleByName_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRoleByName'
+type MockRoleRepository_GetRoleByName_Call struct {
 	*mock.Call
 }
 
-// LogAccountActivity is a helper method to define mock.On call
+// GetRoleByName is a helper method to define mock.On call
+//   - ctx context.Context
+//   - name string
+func (_e *MockRoleRepository_Expecter) GetRoleByName(ctx interface{}, name interface{}) *MockRoleRepository_GetRoleByName_Call {
+	return &MockRoleRepository_GetRoleByName_Call{Call: _e.mock.On("GetRoleByName", ctx, name)}
+}
+
+func (_c *MockRoleRepository_GetRoleByName_Call) Run(run func(ctx context.Context, name string)) *MockRoleRepository_GetRoleByName_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRoleRepository_GetRoleByName_Call) Return(role *Role, err error) *MockRoleRepository_GetRoleByName_Call {
+	_c.Call.Return(role, err)
+	return _c
+}
+
+func (_c *MockRoleRepository_GetRoleByName_Call) RunAndReturn(run func(ctx context.Context, name string) (*Role, error)) *MockRoleRepository_GetRoleByName_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListAccountRoles provides a mock function for the type MockRoleRepository
+func (_mock *MockRoleRepository) ListAccountRoles(ctx context.Context, accountID uint) ([]Role, error) {
+	ret := _mock.Called(ctx, accountID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAccountRoles")
+	}
+
+	var r0 []Role
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) ([]Role, error)); ok {
+		return returnFunc(ctx, accountID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) []Role); ok {
+		r0 = returnFunc(ctx, accountID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]Role)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = returnFunc(ctx, accountID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRoleRepository_ListAccountRoles_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAccountRoles'
+type MockRoleRepository_ListAccountRoles_Call struct {
+	*mock.Call
+}
+
+// ListAccountRoles is a helper method to define mock.On call
 //   - ctx context.Context
 //   - accountID uint
-//   - activity string
-func (_e *MockAccountRepository_Expecter) LogAccountActivity(ctx interface{}, accountID interface{}, activity interface{}) *MockAccountRepository_LogAccountActivity_Call {
-	return &MockAccountRepository_LogAccountActivity_Call{Call: _e.mock.On("LogAccountActivity", ctx, accountID, activity)}
+func (_e *MockRoleRepository_Expecter) ListAccountRoles(ctx interface{}, accountID interface{}) *MockRoleRepository_ListAccountRoles_Call {
+	return &MockRoleRepository_ListAccountRoles_Call{Call: _e.mock.On("ListAccountRoles", ctx, accountID)}
 }
 
-func (_c *MockAccountRepository_LogAccountActivity_Call) Run(run func(ctx context.Context, accountID uint, activity string)) *MockAccountRepository_LogAccountActivity_Call {
+func (_c *MockRoleRepository_ListAccountRoles_Call) Run(run func(ctx context.Context, accountID uint)) *MockRoleRepository_ListAccountRoles_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -830,93 +9496,145 @@ func (_c *MockAccountRepository_LogAccountActivity_Call) Run(run func(ctx contex
 		if args[1] != nil {
 			arg1 = args[1].(uint)
 		}
-		var arg2 string
-		if args[2] != nil {
-			arg2 = args[2].(string)
-		}
 		run(
 			arg0,
 			arg1,
-			arg2,
 		)
 	})
 	return _c
 }
 
-func (_c *MockAccountRepository_LogAccountActivity_Call) Return(err error) *MockAccountRepository_LogAccountActivity_Call {
-	_c.Call.Return(err)
+func (_c *MockRoleRepository_ListAccountRoles_Call) Return(roles []Role, err error) *MockRoleRepository_ListAccountRoles_Call {
+	_c.Call.Return(roles, err)
 	return _c
 }
 
-func (_c *MockAccountRepository_LogAccountActivity_Call) RunAndReturn(run func(ctx context.Context, accountID uint, activity string) error) *MockAccountRepository_LogAccountActivity_Call {
+func (_c *MockRoleRepository_ListAccountRoles_Call) RunAndReturn(run func(ctx context.Context, accountID uint) ([]Role, error)) *MockRoleRepository_ListAccountRoles_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// UpdateAccount provides a mock function for the type MockAccountRepository
-func (_mock *MockAccountRepository) UpdateAccount(ctx context.Context, account *Account) (*Account, error) {
-	ret := _mock.Called(ctx, account)
+// ListRoles provides a mock function for the type MockRoleRepository
+func (_mock *MockRoleRepository) ListRoles(ctx context.Context) ([]Role, error) {
+	ret := _mock.Called(ctx)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdateAccount")
+		panic("no return value specified for ListRoles")
 	}
 
-	var r0 *Account
+	var r0 []Role
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account) (*Account, error)); ok {
-		return returnFunc(ctx, account)
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]Role, error)); ok {
+		return returnFunc(ctx)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, *Account) *Account); ok {
-		r0 = returnFunc(ctx, account)
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []Role); ok {
+		r0 = returnFunc(ctx)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*Account)
+			r0 = ret.Get(0).([]Role)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, *Account) error); ok {
-		r1 = returnFunc(ctx, account)
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockAccountRepository_UpdateAccount_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateAccount'
-type MockAccountRepository_UpdateAccount_Call struct {
+// MockRoleRepository_ListRoles_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListRoles'
+type MockRoleRepository_ListRoles_Call struct {
 	*mock.Call
 }
 
-// UpdateAccount is a helper method to define mock.On call
+// ListRoles is a helper method to define mock.On call
 //   - ctx context.Context
-//   - account *Account
-func (_e *MockAccountRepository_Expecter) UpdateAccount(ctx interface{}, account interface{}) *MockAccountRepository_UpdateAccount_Call {
-	return &MockAccountRepository_UpdateAccount_Call{Call: _e.mock.On("UpdateAccount", ctx, account)}
+func (_e *MockRoleRepository_Expecter) ListRoles(ctx interface{}) *MockRoleRepository_ListRoles_Call {
+	return &MockRoleRepository_ListRoles_Call{Call: _e.mock.On("ListRoles", ctx)}
 }
 
-func (_c *MockAccountRepository_UpdateAccount_Call) Run(run func(ctx context.Context, account *Account)) *MockAccountRepository_UpdateAccount_Call {
+func (_c *MockRoleRepository_ListRoles_Call) Run(run func(ctx context.Context)) *MockRoleRepository_ListRoles_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
 			arg0 = args[0].(context.Context)
 		}
-		var arg1 *Account
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRoleRepository_ListRoles_Call) Return(roles []Role, err error) *MockRoleRepository_ListRoles_Call {
+	_c.Call.Return(roles, err)
+	return _c
+}
+
+func (_c *MockRoleRepository_ListRoles_Call) RunAndReturn(run func(ctx context.Context) ([]Role, error)) *MockRoleRepository_ListRoles_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UnassignRole provides a mock function for the type MockRoleRepository
+func (_mock *MockRoleRepository) UnassignRole(ctx context.Context, accountID uint, roleID uint) error {
+	ret := _mock.Called(ctx, accountID, roleID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UnassignRole")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
+		r0 = returnFunc(ctx, accountID, roleID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRoleRepository_UnassignRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UnassignRole'
+type MockRoleRepository_UnassignRole_Call struct {
+	*mock.Call
+}
+
+// UnassignRole is a helper method to define mock.On call
+//   - ctx context.Context
+//   - accountID uint
+//   - roleID uint
+func (_e *MockRoleRepository_Expecter) UnassignRole(ctx interface{}, accountID interface{}, roleID interface{}) *MockRoleRepository_UnassignRole_Call {
+	return &MockRoleRepository_UnassignRole_Call{Call: _e.mock.On("UnassignRole", ctx, accountID, roleID)}
+}
+
+func (_c *MockRoleRepository_UnassignRole_Call) Run(run func(ctx context.Context, accountID uint, roleID uint)) *MockRoleRepository_UnassignRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 uint
 		if args[1] != nil {
-			arg1 = args[1].(*Account)
+			arg1 = args[1].(uint)
+		}
+		var arg2 uint
+		if args[2] != nil {
+			arg2 = args[2].(uint)
 		}
 		run(
 			arg0,
 			arg1,
+			arg2,
 		)
 	})
 	return _c
 }
 
-func (_c *MockAccountRepository_UpdateAccount_Call) Return(account1 *Account, err error) *MockAccountRepository_UpdateAccount_Call {
-	_c.Call.Return(account1, err)
+func (_c *MockRoleRepository_UnassignRole_Call) Return(err error) *MockRoleRepository_UnassignRole_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockAccountRepository_UpdateAccount_Call) RunAndReturn(run func(ctx context.Context, account *Account) (*Account, error)) *MockAccountRepository_UpdateAccount_Call {
+func (_c *MockRoleRepository_UnassignRole_Call) RunAndReturn(run func(ctx context.Context, accountID uint, roleID uint) error) *MockRoleRepository_UnassignRole_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -0,0 +1,268 @@
+package keyring
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+	AlgorithmEdDSA = "EdDSA"
+
+	// name of the file holding the id of the key used for signing
+	activeKeyFile = "active"
+	keyFileExt    = ".pem"
+	rsaKeyBits    = 2048
+)
+
+var (
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+	ErrUnsupportedKeyType   = errors.New("unsupported key type")
+	ErrKeyNotFound          = errors.New("key not found")
+	ErrNoSigningKey         = errors.New("no active signing key")
+	ErrActiveKeyRemoval     = errors.New("the active signing key cannot be removed")
+)
+
+// Key is a private signing key, the public half is used for verification and published in the JWKS
+type Key struct {
+	ID        string
+	Algorithm string
+	Private   crypto.Signer
+}
+
+func (k *Key) Public() crypto.PublicKey {
+	return k.Private.Public()
+}
+
+func (k *Key) SigningMethod() jwt.SigningMethod {
+	return jwt.GetSigningMethod(k.Algorithm)
+}
+
+type KeyRing interface {
+	// SigningKey returns the key new tokens are signed with
+	SigningKey() (*Key, error)
+	// VerificationKey returns any key that tokens may still be signed with
+	VerificationKey(kid string) (*Key, error)
+	JWKS() JSONWebKeySet
+}
+
+// FileKeyRing holds keys loaded from a directory of PEM encoded private keys named <kid>.pem
+type FileKeyRing struct {
+	keys     map[string]*Key
+	activeID string
+}
+
+// LoadDir loads every key in dir. The signing key is activeID if given,
+// otherwise the one recorded by SetActiveKey, otherwise the only key in dir.
+func LoadDir(dir string, activeID string) (*FileKeyRing, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+keyFileExt))
+	if err != nil {
+		return nil, err
+	}
+
+	ring := &FileKeyRing{keys: map[string]*Key{}}
+	for _, path := range paths {
+		key, err := readKeyFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load key %s: %w", path, err)
+		}
+		ring.keys[key.ID] = key
+	}
+
+	if activeID == "" {
+		activeID, err = ActiveKeyID(dir)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if activeID == "" && len(ring.keys) == 1 {
+		for id := range ring.keys {
+			activeID = id
+		}
+	}
+	if _, ok := ring.keys[activeID]; !ok {
+		return nil, fmt.Errorf("%w: %q", ErrNoSigningKey, activeID)
+	}
+	ring.activeID = activeID
+
+	return ring, nil
+}
+
+func (r *FileKeyRing) SigningKey() (*Key, error) {
+	key, ok := r.keys[r.activeID]
+	if !ok {
+		return nil, ErrNoSigningKey
+	}
+	return key, nil
+}
+
+func (r *FileKeyRing) VerificationKey(kid string) (*Key, error) {
+	key, ok := r.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, kid)
+	}
+	return key, nil
+}
+
+// Keys returns every key sorted by id, which sorts them by creation time
+func (r *FileKeyRing) Keys() []*Key {
+	keys := make([]*Key, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys
+}
+
+func (r *FileKeyRing) ActiveKeyID() string {
+	return r.activeID
+}
+
+func (r *FileKeyRing) JWKS() JSONWebKeySet {
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, key := range r.Keys() {
+		set.Keys = append(set.Keys, NewJSONWebKey(key))
+	}
+	return set
+}
+
+// GenerateKey creates a new key with an id that sorts after every key generated before it
+func GenerateKey(algorithm string) (*Key, error) {
+	var signer crypto.Signer
+	var err error
+
+	switch algorithm {
+	case AlgorithmRS256:
+		signer, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgorithmES256:
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgorithmEdDSA:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+
+	return &Key{
+		ID:        time.Now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(suffix),
+		Algorithm: algorithm,
+		Private:   signer,
+	}, nil
+}
+
+// SaveKey writes the key to dir as <kid>.pem, readable by the owner only
+func SaveKey(dir string, key *Key) error {
+	der, err := x509.MarshalPKCS8PrivateKey(key.Private)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	return os.WriteFile(filepath.Join(dir, key.ID+keyFileExt), data, 0o600)
+}
+
+// SetActiveKey records the key new tokens are signed with
+func SetActiveKey(dir string, kid string) error {
+	if _, err := os.Stat(filepath.Join(dir, kid+keyFileExt)); err != nil {
+		return fmt.Errorf("%w: %q", ErrKeyNotFound, kid)
+	}
+	return os.WriteFile(filepath.Join(dir, activeKeyFile), []byte(kid+"\n"), 0o600)
+}
+
+// ActiveKeyID returns the id recorded by SetActiveKey, or an empty string if there is none
+func ActiveKeyID(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, activeKeyFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// RemoveKey deletes a retired key, tokens signed with it no longer verify afterwards
+func RemoveKey(dir string, kid string) error {
+	activeID, err := ActiveKeyID(dir)
+	if err != nil {
+		return err
+	}
+	if activeID == kid {
+		return ErrActiveKeyRemoval
+	}
+
+	err = os.Remove(filepath.Join(dir, kid+keyFileExt))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %q", ErrKeyNotFound, kid)
+	}
+	return err
+}
+
+func readKeyFile(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{ID: strings.TrimSuffix(filepath.Base(path), keyFileExt)}
+
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		if private.N.BitLen() < rsaKeyBits {
+			return nil, fmt.Errorf("rsa keys must be at least %d bits", rsaKeyBits)
+		}
+		key.Algorithm = AlgorithmRS256
+		key.Private = private
+	case *ecdsa.PrivateKey:
+		if private.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%w: only P-256 ecdsa keys are supported", ErrUnsupportedKeyType)
+		}
+		key.Algorithm = AlgorithmES256
+		key.Private = private
+	case ed25519.PrivateKey:
+		key.Algorithm = AlgorithmEdDSA
+		key.Private = private
+	default:
+		return nil, ErrUnsupportedKeyType
+	}
+
+	return key, nil
+}
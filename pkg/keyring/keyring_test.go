@@ -0,0 +1,159 @@
+package keyring_test
+
+import (
+	"crypto"
+	"go_starter_api/pkg/keyring"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyRing_LoadDir(t *testing.T) {
+	t.Run("should load generated keys of every algorithm", func(t *testing.T) {
+		dir := t.TempDir()
+
+		var ids []string
+		for _, algorithm := range []string{keyring.AlgorithmRS256, keyring.AlgorithmES256, keyring.AlgorithmEdDSA} {
+			key, err := keyring.GenerateKey(algorithm)
+			require.NoError(t, err)
+			require.NoError(t, keyring.SaveKey(dir, key))
+			ids = append(ids, key.ID)
+		}
+		require.NoError(t, keyring.SetActiveKey(dir, ids[1]))
+
+		ring, err := keyring.LoadDir(dir, "")
+		require.NoError(t, err)
+
+		signingKey, err := ring.SigningKey()
+		assert.NoError(t, err)
+		assert.Equal(t, ids[1], signingKey.ID)
+		assert.Equal(t, keyring.AlgorithmES256, signingKey.Algorithm)
+
+		for _, id := range ids {
+			key, err := ring.VerificationKey(id)
+			assert.NoError(t, err)
+			assert.Equal(t, id, key.ID)
+		}
+
+		_, err = ring.VerificationKey("unknown")
+		assert.ErrorIs(t, err, keyring.ErrKeyNotFound)
+	})
+
+	t.Run("should prefer the given active key id", func(t *testing.T) {
+		dir := t.TempDir()
+
+		first, _ := keyring.GenerateKey(keyring.AlgorithmES256)
+		second, _ := keyring.GenerateKey(keyring.AlgorithmES256)
+		require.NoError(t, keyring.SaveKey(dir, first))
+		require.NoError(t, keyring.SaveKey(dir, second))
+		require.NoError(t, keyring.SetActiveKey(dir, first.ID))
+
+		ring, err := keyring.LoadDir(dir, second.ID)
+		require.NoError(t, err)
+		assert.Equal(t, second.ID, ring.ActiveKeyID())
+	})
+
+	t.Run("should use the only key when none is marked active", func(t *testing.T) {
+		dir := t.TempDir()
+
+		key, _ := keyring.GenerateKey(keyring.AlgorithmEdDSA)
+		require.NoError(t, keyring.SaveKey(dir, key))
+
+		ring, err := keyring.LoadDir(dir, "")
+		require.NoError(t, err)
+		assert.Equal(t, key.ID, ring.ActiveKeyID())
+	})
+
+	t.Run("should fail without a signing key", func(t *testing.T) {
+		_, err := keyring.LoadDir(t.TempDir(), "")
+		assert.ErrorIs(t, err, keyring.ErrNoSigningKey)
+	})
+
+	t.Run("should fail on an invalid key file", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.pem"), []byte("not a key"), 0o600))
+
+		_, err := keyring.LoadDir(dir, "")
+		assert.Error(t, err)
+	})
+}
+
+func TestKeyRing_RemoveKey(t *testing.T) {
+	dir := t.TempDir()
+
+	active, _ := keyring.GenerateKey(keyring.AlgorithmES256)
+	retired, _ := keyring.GenerateKey(keyring.AlgorithmES256)
+	require.NoError(t, keyring.SaveKey(dir, active))
+	require.NoError(t, keyring.SaveKey(dir, retired))
+	require.NoError(t, keyring.SetActiveKey(dir, active.ID))
+
+	assert.ErrorIs(t, keyring.RemoveKey(dir, active.ID), keyring.ErrActiveKeyRemoval)
+	assert.NoError(t, keyring.RemoveKey(dir, retired.ID))
+	assert.ErrorIs(t, keyring.RemoveKey(dir, retired.ID), keyring.ErrKeyNotFound)
+
+	ring, err := keyring.LoadDir(dir, "")
+	require.NoError(t, err)
+	assert.Len(t, ring.Keys(), 1)
+}
+
+func TestKeyRing_JWKS(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, algorithm := range []string{keyring.AlgorithmRS256, keyring.AlgorithmES256, keyring.AlgorithmEdDSA} {
+		key, err := keyring.GenerateKey(algorithm)
+		require.NoError(t, err)
+		require.NoError(t, keyring.SaveKey(dir, key))
+		require.NoError(t, keyring.SetActiveKey(dir, key.ID))
+	}
+
+	ring, err := keyring.LoadDir(dir, "")
+	require.NoError(t, err)
+
+	jwks := ring.JWKS()
+	require.Len(t, jwks.Keys, 3)
+
+	byAlg := map[string]keyring.JSONWebKey{}
+	for _, jwk := range jwks.Keys {
+		assert.Equal(t, "sig", jwk.Use)
+		assert.NotEmpty(t, jwk.Kid)
+		byAlg[jwk.Alg] = jwk
+	}
+
+	assert.Equal(t, "RSA", byAlg[keyring.AlgorithmRS256].Kty)
+	assert.Equal(t, "AQAB", byAlg[keyring.AlgorithmRS256].E)
+	assert.NotEmpty(t, byAlg[keyring.AlgorithmRS256].N)
+
+	assert.Equal(t, "EC", byAlg[keyring.AlgorithmES256].Kty)
+	assert.Equal(t, "P-256", byAlg[keyring.AlgorithmES256].Crv)
+	assert.Len(t, byAlg[keyring.AlgorithmES256].X, 43)
+	assert.Len(t, byAlg[keyring.AlgorithmES256].Y, 43)
+
+	assert.Equal(t, "OKP", byAlg[keyring.AlgorithmEdDSA].Kty)
+	assert.Equal(t, "Ed25519", byAlg[keyring.AlgorithmEdDSA].Crv)
+	assert.Len(t, byAlg[keyring.AlgorithmEdDSA].X, 43)
+}
+
+func TestJSONWebKey_PublicKey(t *testing.T) {
+	for _, algorithm := range []string{keyring.AlgorithmRS256, keyring.AlgorithmES256, keyring.AlgorithmEdDSA} {
+		key, err := keyring.GenerateKey(algorithm)
+		require.NoError(t, err)
+
+		jwks := keyring.JSONWebKeySet{Keys: []keyring.JSONWebKey{keyring.NewJSONWebKey(key)}}
+
+		jwk, err := jwks.Key(key.ID)
+		require.NoError(t, err)
+
+		public, err := jwk.PublicKey()
+		require.NoError(t, err, algorithm)
+		assert.True(t, public.(interface{ Equal(crypto.PublicKey) bool }).Equal(key.Public()), algorithm)
+	}
+
+	_, err := keyring.JSONWebKeySet{}.Key("missing")
+	assert.ErrorIs(t, err, keyring.ErrKeyNotFound)
+
+	_, err = keyring.JSONWebKey{Kty: "EC", Crv: "P-256", X: "AQ", Y: "AQ"}.PublicKey()
+	assert.Error(t, err)
+}
@@ -0,0 +1,103 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	githubURL    = "https://github.com"
+	githubAPIURL = "https://api.github.com"
+)
+
+var defaultGitHubScopes = []string{"read:user", "user:email"}
+
+// GitHubProvider signs users in with GitHub, which is not an openid provider, the user is
+// read from its api. The nonce is not supported by GitHub and ignored.
+type GitHubProvider struct {
+	config       Config
+	authorizeURL string
+	tokenURL     string
+	apiURL       string
+}
+
+func NewGitHubProvider(config Config) *GitHubProvider {
+	if len(config.Scopes) == 0 {
+		config.Scopes = defaultGitHubScopes
+	}
+
+	baseURL, apiURL := githubURL, githubAPIURL
+	// enterprise servers serve the api under the same host
+	if config.Issuer != "" {
+		baseURL = strings.TrimSuffix(config.Issuer, "/")
+		apiURL = baseURL + "/api/v3"
+	}
+
+	return &GitHubProvider{
+		config:       config,
+		authorizeURL: baseURL + "/login/oauth/authorize",
+		tokenURL:     baseURL + "/login/oauth/access_token",
+		apiURL:       apiURL,
+	}
+}
+
+func (p *GitHubProvider) Name() string {
+	return p.config.Name
+}
+
+func (p *GitHubProvider) AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	return authCodeURL(p.authorizeURL, p.config, state, "", codeChallenge)
+}
+
+type githubUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+	Name  string `json:"name"`
+}
+
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// Exchange redeems the code and reads the user, its email is the primary one when verified
+func (p *GitHubProvider) Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (*UserInfo, error) {
+	client := httpClient(p.config)
+
+	token, err := exchangeCode(ctx, client, p.tokenURL, p.config, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	var user githubUser
+	if err := getJSON(ctx, client, p.apiURL+"/user", token.AccessToken, &user); err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, fmt.Errorf("%w: missing user id", ErrExchangeFailed)
+	}
+
+	var emails []githubEmail
+	if err := getJSON(ctx, client, p.apiURL+"/user/emails", token.AccessToken, &emails); err != nil {
+		return nil, err
+	}
+
+	info := &UserInfo{
+		Subject: strconv.FormatInt(user.ID, 10),
+		Name:    user.Name,
+	}
+	if info.Name == "" {
+		info.Name = user.Login
+	}
+	for _, email := range emails {
+		if email.Primary && email.Verified {
+			info.Email = email.Email
+			info.EmailVerified = true
+		}
+	}
+
+	return info, nil
+}
@@ -0,0 +1,87 @@
+package oauth_test
+
+import (
+	"context"
+	"go_starter_api/pkg/oauth"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubProvider(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// a github enterprise server, serving the oauth endpoints and the api under /api/v3
+	newServer := func(t *testing.T, emails []gin.H) *httptest.Server {
+		router := gin.New()
+		router.POST("/login/oauth/access_token", func(c *gin.Context) {
+			if c.PostForm("code") != "code" || c.PostForm("code_verifier") != "verifier" {
+				// github reports errors with a 200
+				c.JSON(http.StatusOK, gin.H{"error": "bad_verification_code"})
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"access_token": "access", "token_type": "bearer"})
+		})
+		authenticated := router.Group("/api/v3", func(c *gin.Context) {
+			if c.GetHeader("Authorization") != "Bearer access" {
+				c.AbortWithStatus(http.StatusUnauthorized)
+			}
+		})
+		authenticated.GET("/user", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"id": 42, "login": "octocat", "name": ""})
+		})
+		authenticated.GET("/user/emails", func(c *gin.Context) {
+			c.JSON(http.StatusOK, emails)
+		})
+		server := httptest.NewServer(router)
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	config := func(server *httptest.Server) oauth.Config {
+		return oauth.Config{Name: "github", ClientID: "client", ClientSecret: "secret", RedirectURL: "http://localhost/callback", Issuer: server.URL}
+	}
+
+	t.Run("should read the user and its primary verified email", func(t *testing.T) {
+		server := newServer(t, []gin.H{
+			{"email": "other@example.com", "primary": false, "verified": true},
+			{"email": "octocat@example.com", "primary": true, "verified": true},
+		})
+		provider := oauth.NewGitHubProvider(config(server))
+
+		authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", oauth.CodeChallenge("verifier"))
+		require.NoError(t, err)
+		u, err := url.Parse(authURL)
+		require.NoError(t, err)
+		assert.Equal(t, "/login/oauth/authorize", u.Path)
+		assert.Equal(t, "read:user user:email", u.Query().Get("scope"))
+		assert.Empty(t, u.Query().Get("nonce"))
+
+		info, err := provider.Exchange(context.Background(), "code", "verifier", "nonce")
+		require.NoError(t, err)
+		assert.Equal(t, &oauth.UserInfo{Subject: "42", Email: "octocat@example.com", EmailVerified: true, Name: "octocat"}, info)
+	})
+
+	t.Run("should not return an unverified email", func(t *testing.T) {
+		server := newServer(t, []gin.H{{"email": "octocat@example.com", "primary": true, "verified": false}})
+		provider := oauth.NewGitHubProvider(config(server))
+
+		info, err := provider.Exchange(context.Background(), "code", "verifier", "")
+		require.NoError(t, err)
+		assert.Empty(t, info.Email)
+		assert.False(t, info.EmailVerified)
+	})
+
+	t.Run("should fail on an error answered with a 200", func(t *testing.T) {
+		server := newServer(t, nil)
+		provider := oauth.NewGitHubProvider(config(server))
+
+		_, err := provider.Exchange(context.Background(), "code", "wrong", "")
+		assert.ErrorIs(t, err, oauth.ErrExchangeFailed)
+	})
+}
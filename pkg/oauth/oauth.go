@@ -0,0 +1,191 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	ProviderTypeOIDC   = "oidc"
+	ProviderTypeGitHub = "github"
+
+	defaultHTTPTimeout = 10 * time.Second
+	// responses of identity providers are small, anything larger is refused
+	maxResponseSize = 1 << 20
+)
+
+var (
+	ErrUnsupportedProviderType = errors.New("unsupported provider type")
+	ErrExchangeFailed          = errors.New("authorization code exchange failed")
+	ErrInvalidIDToken          = errors.New("invalid id token")
+	ErrDiscoveryFailed         = errors.New("openid discovery failed")
+)
+
+// Provider signs users in with an external identity provider, using the authorization code flow with PKCE
+type Provider interface {
+	Name() string
+	// AuthCodeURL returns the consent page of the provider, which sends the user back to the
+	// redirect url with the state and an authorization code
+	AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error)
+	// Exchange trades the authorization code for the user, the code verifier and nonce
+	// must be those of the request that returned the code
+	Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (*UserInfo, error)
+}
+
+// UserInfo is the user as known to the provider, the subject never changes for a user
+type UserInfo struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type Config struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is registered with the provider, it receives the state and the code
+	RedirectURL string
+	Scopes      []string
+	// Issuer is the openid issuer whose endpoints are discovered, for github the url of
+	// an enterprise server and empty for github.com
+	Issuer string
+	// HTTPClient defaults to a client with a timeout
+	HTTPClient *http.Client
+}
+
+// NewProvider returns the provider of the type, one of ProviderTypeOIDC and ProviderTypeGitHub
+func NewProvider(providerType string, config Config) (Provider, error) {
+	switch providerType {
+	case "", ProviderTypeOIDC:
+		return NewOIDCProvider(config), nil
+	case ProviderTypeGitHub:
+		return NewGitHubProvider(config), nil
+	default:
+		return nil, ErrUnsupportedProviderType
+	}
+}
+
+// GenerateCodeVerifier returns a random PKCE code verifier, it is kept by the client and
+// only its challenge is sent to the consent page
+func GenerateCodeVerifier() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CodeChallenge returns the S256 challenge of the code verifier
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func httpClient(config Config) *http.Client {
+	if config.HTTPClient != nil {
+		return config.HTTPClient
+	}
+	return &http.Client{Timeout: defaultHTTPTimeout}
+}
+
+// authCodeURL adds the parameters of an authorization code request with PKCE to the endpoint
+func authCodeURL(endpoint string, config Config, state string, nonce string, codeChallenge string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", config.ClientID)
+	query.Set("redirect_uri", config.RedirectURL)
+	query.Set("scope", strings.Join(config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	if nonce != "" {
+		query.Set("nonce", nonce)
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// exchangeCode redeems the authorization code at the token endpoint, the client authenticates
+// with its secret in the form as every supported provider accepts it
+func exchangeCode(ctx context.Context, client *http.Client, endpoint string, config Config, code string, codeVerifier string) (*tokenResponse, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", config.RedirectURL)
+	form.Set("client_id", config.ClientID)
+	form.Set("client_secret", config.ClientSecret)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var token tokenResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&token); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrExchangeFailed, resp.Status)
+	}
+	// github answers errors with a 200
+	if resp.StatusCode != http.StatusOK || token.Error != "" || token.AccessToken == "" {
+		return nil, fmt.Errorf("%w: %s %s", ErrExchangeFailed, token.Error, token.ErrorDescription)
+	}
+
+	return &token, nil
+}
+
+// getJSON decodes the response of a get request, authenticated with the access token when one is given
+func getJSON(ctx context.Context, client *http.Client, endpoint string, accessToken string, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("get %s: %s", endpoint, resp.Status)
+	}
+
+	return json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(target)
+}
@@ -0,0 +1,224 @@
+// Package oauthtest runs a fake OpenID Connect issuer for tests of the sign in flow
+package oauthtest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"go_starter_api/pkg/keyring"
+	"go_starter_api/pkg/oauth"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	ClientID     = "test-client"
+	ClientSecret = "test-secret"
+	RedirectURL  = "http://localhost:3000/oauth/callback"
+)
+
+// Issuer is an openid provider serving discovery, its keys, the token and the userinfo
+// endpoints. The consent page is played by Authorize.
+type Issuer struct {
+	URL string
+
+	// Claims, when set, adjusts the claims of the id tokens before they are signed
+	Claims func(claims jwt.MapClaims)
+
+	key *keyring.Key
+
+	mu     sync.Mutex
+	grants map[string]grant
+	users  map[string]oauth.UserInfo
+}
+
+// grant is an authorization code waiting to be redeemed
+type grant struct {
+	user          oauth.UserInfo
+	nonce         string
+	codeChallenge string
+	redirectURI   string
+}
+
+// NewIssuer starts an issuer, it is stopped when the test ends
+func NewIssuer(t testing.TB) *Issuer {
+	key, err := keyring.GenerateKey(keyring.AlgorithmES256)
+	if err != nil {
+		t.Fatalf("failed to generate issuer key: %v", err)
+	}
+
+	issuer := &Issuer{
+		key:    key,
+		grants: map[string]grant{},
+		users:  map[string]oauth.UserInfo{},
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/.well-known/openid-configuration", issuer.discovery)
+	router.GET("/jwks", func(c *gin.Context) {
+		c.JSON(http.StatusOK, keyring.JSONWebKeySet{Keys: []keyring.JSONWebKey{keyring.NewJSONWebKey(issuer.key)}})
+	})
+	router.POST("/token", issuer.token)
+	router.GET("/userinfo", issuer.userinfo)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	issuer.URL = server.URL
+
+	return issuer
+}
+
+// Config returns the configuration of a provider registered with the issuer
+func (i *Issuer) Config(name string) oauth.Config {
+	return oauth.Config{
+		Name:         name,
+		ClientID:     ClientID,
+		ClientSecret: ClientSecret,
+		RedirectURL:  RedirectURL,
+		Issuer:       i.URL,
+	}
+}
+
+// Authorize plays the consent page: the user approves the authorization request of the url
+// and the code and state sent back to the redirect url are returned
+func (i *Issuer) Authorize(t testing.TB, authCodeURL string, user oauth.UserInfo) (string, string) {
+	u, err := url.Parse(authCodeURL)
+	if err != nil {
+		t.Fatalf("invalid authorization url: %v", err)
+	}
+	query := u.Query()
+	if query.Get("client_id") != ClientID || query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		t.Fatalf("invalid authorization request: %s", authCodeURL)
+	}
+
+	code := randomString()
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.grants[code] = grant{
+		user:          user,
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+		redirectURI:   query.Get("redirect_uri"),
+	}
+
+	return code, query.Get("state")
+}
+
+func (i *Issuer) discovery(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"issuer":                 i.URL,
+		"authorization_endpoint": i.URL + "/authorize",
+		"token_endpoint":         i.URL + "/token",
+		"userinfo_endpoint":      i.URL + "/userinfo",
+		"jwks_uri":               i.URL + "/jwks",
+	})
+}
+
+func (i *Issuer) token(c *gin.Context) {
+	invalid := func(reason string) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_grant", "error_description": reason})
+	}
+
+	if c.PostForm("grant_type") != "authorization_code" {
+		invalid("unsupported grant type")
+		return
+	}
+	if c.PostForm("client_id") != ClientID || c.PostForm("client_secret") != ClientSecret {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_client"})
+		return
+	}
+
+	// codes are single use
+	i.mu.Lock()
+	g, ok := i.grants[c.PostForm("code")]
+	delete(i.grants, c.PostForm("code"))
+	i.mu.Unlock()
+
+	if !ok {
+		invalid("unknown code")
+		return
+	}
+	if c.PostForm("redirect_uri") != g.redirectURI {
+		invalid("redirect uri mismatch")
+		return
+	}
+	if oauth.CodeChallenge(c.PostForm("code_verifier")) != g.codeChallenge {
+		invalid("code verifier mismatch")
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            i.URL,
+		"aud":            ClientID,
+		"sub":            g.user.Subject,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"email":          g.user.Email,
+		"email_verified": g.user.EmailVerified,
+		"name":           g.user.Name,
+	}
+	if g.nonce != "" {
+		claims["nonce"] = g.nonce
+	}
+	if i.Claims != nil {
+		i.Claims(claims)
+	}
+
+	token := jwt.NewWithClaims(i.key.SigningMethod(), claims)
+	token.Header["kid"] = i.key.ID
+	idToken, err := token.SignedString(i.key.Private)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		return
+	}
+
+	accessToken := randomString()
+	i.mu.Lock()
+	i.users[accessToken] = g.user
+	i.mu.Unlock()
+
+	c.JSON(http.StatusOK, gin.H{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+func (i *Issuer) userinfo(c *gin.Context) {
+	accessToken := c.GetHeader("Authorization")
+	if len(accessToken) > len("Bearer ") {
+		accessToken = accessToken[len("Bearer "):]
+	}
+
+	i.mu.Lock()
+	user, ok := i.users[accessToken]
+	i.mu.Unlock()
+
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sub":            user.Subject,
+		"email":          user.Email,
+		"email_verified": user.EmailVerified,
+		"name":           user.Name,
+	})
+}
+
+func randomString() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
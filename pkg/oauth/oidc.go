@@ -0,0 +1,220 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"go_starter_api/pkg/keyring"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// the signing keys of the issuer are fetched again at most this often, when a token is
+// signed with a key that is not known yet
+const jwksRefreshInterval = time.Minute
+
+var defaultOIDCScopes = []string{"openid", "email", "profile"}
+
+// OIDCProvider signs users in with any OpenID Connect provider, its endpoints are discovered
+// from the issuer on first use
+type OIDCProvider struct {
+	config Config
+	client *http.Client
+
+	mu            sync.Mutex
+	metadata      *providerMetadata
+	jwks          keyring.JSONWebKeySet
+	jwksFetchedAt time.Time
+}
+
+// providerMetadata is the part of the discovery document the authorization code flow uses
+type providerMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+func NewOIDCProvider(config Config) *OIDCProvider {
+	if len(config.Scopes) == 0 {
+		config.Scopes = defaultOIDCScopes
+	}
+	config.Issuer = strings.TrimSuffix(config.Issuer, "/")
+	return &OIDCProvider{
+		config: config,
+		client: httpClient(config),
+	}
+}
+
+func (p *OIDCProvider) Name() string {
+	return p.config.Name
+}
+
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return authCodeURL(metadata.AuthorizationEndpoint, p.config, state, nonce, codeChallenge)
+}
+
+// Exchange redeems the code and verifies the id token, the user is read from its claims
+// and from the userinfo endpoint when the token does not carry the email
+func (p *OIDCProvider) Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (*UserInfo, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := exchangeCode(ctx, p.client, metadata.TokenEndpoint, p.config, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("%w: missing", ErrInvalidIDToken)
+	}
+
+	claims, err := p.verifyIDToken(ctx, metadata, token.IDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	user := userInfoFromClaims(claims)
+	if user.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+
+	if user.Email == "" && metadata.UserinfoEndpoint != "" {
+		var userinfo jwt.MapClaims
+		if err := getJSON(ctx, p.client, metadata.UserinfoEndpoint, token.AccessToken, &userinfo); err != nil {
+			return nil, err
+		}
+		// the userinfo response is only trusted for the subject of the verified id token
+		if fromUserinfo := userInfoFromClaims(userinfo); fromUserinfo.Subject == user.Subject {
+			user.Email = fromUserinfo.Email
+			user.EmailVerified = fromUserinfo.EmailVerified
+			if user.Name == "" {
+				user.Name = fromUserinfo.Name
+			}
+		}
+	}
+
+	return user, nil
+}
+
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, metadata *providerMetadata, idToken string, nonce string) (jwt.MapClaims, error) {
+	parsed, err := jwt.Parse(idToken, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := p.signingKey(ctx, metadata, kid)
+		if err != nil {
+			return nil, err
+		}
+		if key.Alg != "" && key.Alg != token.Method.Alg() {
+			return nil, jwt.ErrTokenSignatureInvalid
+		}
+		return key.PublicKey()
+	},
+		jwt.WithValidMethods([]string{keyring.AlgorithmRS256, keyring.AlgorithmES256, keyring.AlgorithmEdDSA}),
+		jwt.WithIssuer(metadata.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	claims := parsed.Claims.(jwt.MapClaims)
+
+	// the nonce ties the token to the authorization request, a token issued for another request is refused
+	if tokenNonce, _ := claims["nonce"].(string); nonce != "" && tokenNonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+
+	// a token issued to several clients must name this one as the authorized party
+	if audience, _ := claims.GetAudience(); len(audience) > 1 {
+		if azp, _ := claims["azp"].(string); azp != p.config.ClientID {
+			return nil, fmt.Errorf("%w: authorized party mismatch", ErrInvalidIDToken)
+		}
+	}
+
+	return claims, nil
+}
+
+// discover fetches the discovery document of the issuer once, a failure is retried on the next call
+func (p *OIDCProvider) discover(ctx context.Context) (*providerMetadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	var metadata providerMetadata
+	err := getJSON(ctx, p.client, p.config.Issuer+"/.well-known/openid-configuration", "", &metadata)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDiscoveryFailed, err)
+	}
+	if strings.TrimSuffix(metadata.Issuer, "/") != p.config.Issuer {
+		return nil, fmt.Errorf("%w: issuer %q does not match %q", ErrDiscoveryFailed, metadata.Issuer, p.config.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: missing endpoints", ErrDiscoveryFailed)
+	}
+
+	p.metadata = &metadata
+	return p.metadata, nil
+}
+
+// signingKey returns the key of the issuer with the id, the keys are fetched again when
+// it is unknown so that rotated keys are picked up
+func (p *OIDCProvider) signingKey(ctx context.Context, metadata *providerMetadata, kid string) (keyring.JSONWebKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key, err := p.findKey(kid)
+	if err == nil || time.Since(p.jwksFetchedAt) < jwksRefreshInterval {
+		return key, err
+	}
+
+	var jwks keyring.JSONWebKeySet
+	if err := getJSON(ctx, p.client, metadata.JWKSURI, "", &jwks); err != nil {
+		return keyring.JSONWebKey{}, err
+	}
+	p.jwks = jwks
+	p.jwksFetchedAt = time.Now()
+
+	return p.findKey(kid)
+}
+
+// findKey looks the key up by id, a token without one is accepted when the issuer has a single key
+func (p *OIDCProvider) findKey(kid string) (keyring.JSONWebKey, error) {
+	if kid == "" {
+		if len(p.jwks.Keys) == 1 {
+			return p.jwks.Keys[0], nil
+		}
+		return keyring.JSONWebKey{}, keyring.ErrKeyNotFound
+	}
+	return p.jwks.Key(kid)
+}
+
+// userInfoFromClaims reads the standard claims, some providers send email_verified as a string
+func userInfoFromClaims(claims jwt.MapClaims) *UserInfo {
+	user := &UserInfo{}
+	user.Subject, _ = claims["sub"].(string)
+	user.Email, _ = claims["email"].(string)
+	user.Name, _ = claims["name"].(string)
+
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		user.EmailVerified = verified
+	case string:
+		user.EmailVerified = verified == "true"
+	}
+
+	return user
+}
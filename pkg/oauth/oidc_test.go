@@ -0,0 +1,139 @@
+package oauth_test
+
+import (
+	"context"
+	"go_starter_api/pkg/oauth"
+	"go_starter_api/pkg/oauth/oauthtest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeChallenge(t *testing.T) {
+	// the example of RFC 7636 appendix B
+	assert.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", oauth.CodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+
+	first, err := oauth.GenerateCodeVerifier()
+	require.NoError(t, err)
+	second, err := oauth.GenerateCodeVerifier()
+	require.NoError(t, err)
+	assert.Len(t, first, 43)
+	assert.NotEqual(t, first, second)
+}
+
+func TestOIDCProvider(t *testing.T) {
+	user := oauth.UserInfo{Subject: "user-1", Email: "user@example.com", EmailVerified: true, Name: "User"}
+
+	// authorize runs the consent page and returns the code with the verifier and nonce of its request
+	authorize := func(t *testing.T, issuer *oauthtest.Issuer, provider oauth.Provider) (string, string, string) {
+		verifier, err := oauth.GenerateCodeVerifier()
+		require.NoError(t, err)
+
+		authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", oauth.CodeChallenge(verifier))
+		require.NoError(t, err)
+
+		u, err := url.Parse(authURL)
+		require.NoError(t, err)
+		assert.Equal(t, issuer.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+		assert.Equal(t, "openid email profile", u.Query().Get("scope"))
+		assert.Equal(t, oauthtest.RedirectURL, u.Query().Get("redirect_uri"))
+
+		code, state := issuer.Authorize(t, authURL, user)
+		assert.Equal(t, "state", state)
+		return code, verifier, "nonce"
+	}
+
+	t.Run("should sign a user in with the authorization code", func(t *testing.T) {
+		issuer := oauthtest.NewIssuer(t)
+		provider := oauth.NewOIDCProvider(issuer.Config("test"))
+
+		code, verifier, nonce := authorize(t, issuer, provider)
+
+		info, err := provider.Exchange(context.Background(), code, verifier, nonce)
+		require.NoError(t, err)
+		assert.Equal(t, &user, info)
+	})
+
+	t.Run("should refuse a code redeemed twice", func(t *testing.T) {
+		issuer := oauthtest.NewIssuer(t)
+		provider := oauth.NewOIDCProvider(issuer.Config("test"))
+
+		code, verifier, nonce := authorize(t, issuer, provider)
+
+		_, err := provider.Exchange(context.Background(), code, verifier, nonce)
+		require.NoError(t, err)
+		_, err = provider.Exchange(context.Background(), code, verifier, nonce)
+		assert.ErrorIs(t, err, oauth.ErrExchangeFailed)
+	})
+
+	t.Run("should refuse the wrong code verifier", func(t *testing.T) {
+		issuer := oauthtest.NewIssuer(t)
+		provider := oauth.NewOIDCProvider(issuer.Config("test"))
+
+		code, _, nonce := authorize(t, issuer, provider)
+
+		other, err := oauth.GenerateCodeVerifier()
+		require.NoError(t, err)
+		_, err = provider.Exchange(context.Background(), code, other, nonce)
+		assert.ErrorIs(t, err, oauth.ErrExchangeFailed)
+	})
+
+	t.Run("should refuse an id token of another request", func(t *testing.T) {
+		issuer := oauthtest.NewIssuer(t)
+		provider := oauth.NewOIDCProvider(issuer.Config("test"))
+
+		code, verifier, _ := authorize(t, issuer, provider)
+
+		_, err := provider.Exchange(context.Background(), code, verifier, "other nonce")
+		assert.ErrorIs(t, err, oauth.ErrInvalidIDToken)
+	})
+
+	t.Run("should refuse id tokens with invalid claims", func(t *testing.T) {
+		for name, adjust := range map[string]func(jwt.MapClaims){
+			"expired":        func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Minute).Unix() },
+			"other audience": func(claims jwt.MapClaims) { claims["aud"] = "other-client" },
+			"other issuer":   func(claims jwt.MapClaims) { claims["iss"] = "https://attacker.example.com" },
+			"other party":    func(claims jwt.MapClaims) { claims["aud"] = []string{oauthtest.ClientID, "other-client"} },
+			"no subject":     func(claims jwt.MapClaims) { delete(claims, "sub") },
+		} {
+			issuer := oauthtest.NewIssuer(t)
+			issuer.Claims = adjust
+			provider := oauth.NewOIDCProvider(issuer.Config("test"))
+
+			code, verifier, nonce := authorize(t, issuer, provider)
+
+			_, err := provider.Exchange(context.Background(), code, verifier, nonce)
+			assert.ErrorIs(t, err, oauth.ErrInvalidIDToken, name)
+		}
+	})
+
+	t.Run("should read the email from userinfo when the id token has none", func(t *testing.T) {
+		issuer := oauthtest.NewIssuer(t)
+		issuer.Claims = func(claims jwt.MapClaims) {
+			delete(claims, "email")
+			delete(claims, "email_verified")
+		}
+		provider := oauth.NewOIDCProvider(issuer.Config("test"))
+
+		code, verifier, nonce := authorize(t, issuer, provider)
+
+		info, err := provider.Exchange(context.Background(), code, verifier, nonce)
+		require.NoError(t, err)
+		assert.Equal(t, "user@example.com", info.Email)
+		assert.True(t, info.EmailVerified)
+	})
+
+	t.Run("should fail when the issuer cannot be discovered", func(t *testing.T) {
+		issuer := oauthtest.NewIssuer(t)
+		config := issuer.Config("test")
+		config.Issuer = issuer.URL + "/other"
+		provider := oauth.NewOIDCProvider(config)
+
+		_, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "challenge")
+		assert.ErrorIs(t, err, oauth.ErrDiscoveryFailed)
+	})
+}
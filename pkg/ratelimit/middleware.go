@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"go_starter_api/pkg/utils"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KeyFunc returns who a request is counted against
+type KeyFunc func(c *gin.Context) string
+
+// ByIP counts requests per client ip address
+func ByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// ByAccountID counts requests per authenticated account, and per ip address before authentication
+func ByAccountID(c *gin.Context) string {
+	accountID := c.GetUint(utils.AccountIdContextKey)
+	if accountID == 0 {
+		return ByIP(c)
+	}
+	return "account:" + strconv.FormatUint(uint64(accountID), 10)
+}
+
+// ByAPIKey counts requests per api key sent in the header, and per ip address without one.
+// The key is hashed so the store never holds a usable credential.
+func ByAPIKey(header string) KeyFunc {
+	return func(c *gin.Context) string {
+		apiKey := c.GetHeader(header)
+		if apiKey == "" {
+			return ByIP(c)
+		}
+		sum := sha256.Sum256([]byte(apiKey))
+		return "apikey:" + hex.EncodeToString(sum[:])
+	}
+}
+
+// Middleware refuses requests over the limit with 429 and reports the limit in RateLimit-* headers.
+// The name keeps the counters of different route groups apart. When the store fails the request
+// is let through, an unavailable store should not take the api down.
+func Middleware(name string, limiter Limiter, key KeyFunc) gin.HandlerFunc {
+	limit := limiter.Limit()
+	policy := strconv.FormatInt(limit.Requests, 10) + ";w=" + strconv.FormatInt(int64(limit.Window/time.Second), 10)
+
+	return func(c *gin.Context) {
+		result, err := limiter.Allow(c.Request.Context(), name+":"+key(c))
+		if err != nil {
+			_ = c.Error(err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Policy", policy)
+		c.Header("RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
+		c.Header("RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+		c.Header("RateLimit-Reset", seconds(result.ResetAfter))
+
+		if !result.Allowed {
+			c.Header("Retry-After", seconds(result.RetryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "too many requests"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// seconds rounds up, a client waiting the advertised time must not be refused again
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
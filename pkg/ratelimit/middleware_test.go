@@ -0,0 +1,83 @@
+package ratelimit_test
+
+import (
+	"go_starter_api/pkg/ratelimit"
+	"go_starter_api/pkg/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(key ratelimit.KeyFunc, before ...gin.HandlerFunc) *gin.Engine {
+		limiter := ratelimit.NewTokenBucket(ratelimit.NewMemoryStore(), ratelimit.Limit{Requests: 2, Window: time.Minute})
+
+		router := gin.New()
+		handlers := append(before, ratelimit.Middleware("test", limiter, key), func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"message": "ok"})
+		})
+		router.GET("/", handlers...)
+		return router
+	}
+
+	request := func(router *gin.Engine, header http.Header) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("should refuse requests over the limit with rate limit headers", func(t *testing.T) {
+		router := newRouter(ratelimit.ByIP)
+
+		w := request(router, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "2;w=60", w.Header().Get("RateLimit-Policy"))
+
+		w = request(router, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+
+		w = request(router, nil)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "30", w.Header().Get("Retry-After"))
+		assert.Equal(t, "60", w.Header().Get("RateLimit-Reset"))
+	})
+
+	t.Run("should count each account apart", func(t *testing.T) {
+		var accountID uint
+		router := newRouter(ratelimit.ByAccountID, func(c *gin.Context) {
+			c.Set(utils.AccountIdContextKey, accountID)
+		})
+
+		accountID = 1
+		request(router, nil)
+		request(router, nil)
+		assert.Equal(t, http.StatusTooManyRequests, request(router, nil).Code)
+
+		accountID = 2
+		assert.Equal(t, http.StatusOK, request(router, nil).Code)
+	})
+
+	t.Run("should count each api key apart", func(t *testing.T) {
+		router := newRouter(ratelimit.ByAPIKey("X-API-Key"))
+
+		first := http.Header{"X-Api-Key": []string{"first"}}
+		request(router, first)
+		request(router, first)
+		assert.Equal(t, http.StatusTooManyRequests, request(router, first).Code)
+
+		assert.Equal(t, http.StatusOK, request(router, http.Header{"X-Api-Key": []string{"second"}}).Code)
+	})
+}
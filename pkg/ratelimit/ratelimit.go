@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	AlgorithmTokenBucket   = "token_bucket"
+	AlgorithmSlidingWindow = "sliding_window"
+)
+
+var (
+	ErrUnsupportedAlgorithm = errors.New("unsupported rate limit algorithm")
+	ErrInvalidLimit         = errors.New("invalid rate limit, expected <requests>/<window> such as 5/1m")
+	// returned when the store keeps changing under a token bucket, the request should not be refused for it
+	ErrContention = errors.New("rate limit state changed concurrently too many times")
+)
+
+// Limit allows Requests requests per Window
+type Limit struct {
+	Requests int64
+	Window   time.Duration
+}
+
+// ParseLimit parses limits such as "100/1m" or "5/15m", the window count may be omitted as in "10/s"
+func ParseLimit(s string) (Limit, error) {
+	requests, window, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return Limit{}, ErrInvalidLimit
+	}
+
+	n, err := strconv.ParseInt(requests, 10, 64)
+	if err != nil || n <= 0 {
+		return Limit{}, ErrInvalidLimit
+	}
+
+	if window != "" && (window[0] < '0' || window[0] > '9') {
+		window = "1" + window
+	}
+	d, err := time.ParseDuration(window)
+	if err != nil || d <= 0 {
+		return Limit{}, ErrInvalidLimit
+	}
+
+	return Limit{Requests: n, Window: d}, nil
+}
+
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.Requests, l.Window)
+}
+
+// Result is the outcome of a single request against a limit
+type Result struct {
+	Allowed   bool
+	Limit     int64
+	Remaining int64
+	// time until the limit is fully available again
+	ResetAfter time.Duration
+	// time until the next request is allowed, zero when the request was allowed
+	RetryAfter time.Duration
+}
+
+type Limiter interface {
+	// Allow counts a request for the key and reports whether it is within the limit
+	Allow(ctx context.Context, key string) (*Result, error)
+	Limit() Limit
+}
+
+// New returns a limiter using the algorithm, token bucket when it is empty
+func New(algorithm string, store Store, limit Limit) (Limiter, error) {
+	switch algorithm {
+	case "", AlgorithmTokenBucket:
+		return NewTokenBucket(store, limit), nil
+	case AlgorithmSlidingWindow:
+		return NewSlidingWindow(store, limit), nil
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+}
@@ -0,0 +1,172 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clock is a manually advanced time source shared by a limiter and its store
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	return c.t
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+func newClock() *clock {
+	return &clock{t: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func TestParseLimit(t *testing.T) {
+	limit, err := ParseLimit("5/1m")
+	require.NoError(t, err)
+	assert.Equal(t, Limit{Requests: 5, Window: time.Minute}, limit)
+
+	limit, err = ParseLimit("10/s")
+	require.NoError(t, err)
+	assert.Equal(t, Limit{Requests: 10, Window: time.Second}, limit)
+
+	for _, invalid := range []string{"", "5", "0/1m", "-1/1m", "x/1m", "5/", "5/0s", "5/soon"} {
+		_, err := ParseLimit(invalid)
+		assert.ErrorIs(t, err, ErrInvalidLimit, invalid)
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should allow a burst up to the limit and refill over the window", func(t *testing.T) {
+		c := newClock()
+		store := NewMemoryStore()
+		store.now = c.now
+		bucket := NewTokenBucket(store, Limit{Requests: 3, Window: 3 * time.Second})
+		bucket.now = c.now
+
+		for remaining := int64(2); remaining >= 0; remaining-- {
+			result, err := bucket.Allow(ctx, "key")
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, remaining, result.Remaining)
+		}
+
+		result, err := bucket.Allow(ctx, "key")
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, time.Second, result.RetryAfter)
+		assert.Equal(t, 3*time.Second, result.ResetAfter)
+
+		// one token is back after a third of the window
+		c.advance(time.Second)
+		result, err = bucket.Allow(ctx, "key")
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, int64(0), result.Remaining)
+	})
+
+	t.Run("should count keys apart", func(t *testing.T) {
+		c := newClock()
+		store := NewMemoryStore()
+		store.now = c.now
+		bucket := NewTokenBucket(store, Limit{Requests: 1, Window: time.Minute})
+		bucket.now = c.now
+
+		result, err := bucket.Allow(ctx, "first")
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+
+		result, err = bucket.Allow(ctx, "second")
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+
+		result, err = bucket.Allow(ctx, "first")
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+	})
+}
+
+func TestSlidingWindow(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should refuse requests over the limit within the window", func(t *testing.T) {
+		c := newClock()
+		store := NewMemoryStore()
+		store.now = c.now
+		window := NewSlidingWindow(store, Limit{Requests: 2, Window: time.Minute})
+		window.now = c.now
+
+		for _, allowed := range []bool{true, true, false} {
+			result, err := window.Allow(ctx, "key")
+			require.NoError(t, err)
+			assert.Equal(t, allowed, result.Allowed)
+		}
+	})
+
+	t.Run("should weigh the previous window by its overlap", func(t *testing.T) {
+		c := newClock()
+		store := NewMemoryStore()
+		store.now = c.now
+		window := NewSlidingWindow(store, Limit{Requests: 4, Window: time.Minute})
+		window.now = c.now
+
+		for i := 0; i < 4; i++ {
+			result, err := window.Allow(ctx, "key")
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+		}
+
+		// halfway through the next window half of the previous 4 requests still count
+		c.advance(90 * time.Second)
+		for _, allowed := range []bool{true, true, false} {
+			result, err := window.Allow(ctx, "key")
+			require.NoError(t, err)
+			assert.Equal(t, allowed, result.Allowed)
+			if !allowed {
+				assert.Greater(t, result.RetryAfter, time.Duration(0))
+			}
+		}
+
+		// two windows later nothing counts anymore
+		c.advance(2 * time.Minute)
+		result, err := window.Allow(ctx, "key")
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, int64(3), result.Remaining)
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	c := newClock()
+	store := NewMemoryStore()
+	store.now = c.now
+
+	value, err := store.Increment(ctx, "key", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), value)
+
+	ok, err := store.CompareAndSwap(ctx, "key", 0, 5, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = store.CompareAndSwap(ctx, "key", 1, 5, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	value, err = store.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), value)
+
+	c.advance(time.Minute)
+	value, err = store.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), value)
+}
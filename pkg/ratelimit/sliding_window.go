@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+)
+
+// SlidingWindow counts requests in fixed windows and weighs the previous window by how much of
+// it still overlaps the sliding one, which smooths the bursts a fixed window allows at its edges.
+// Refused requests are counted too, a client has to back off for its count to drop.
+type SlidingWindow struct {
+	store Store
+	limit Limit
+	now   func() time.Time
+}
+
+func NewSlidingWindow(store Store, limit Limit) *SlidingWindow {
+	return &SlidingWindow{store: store, limit: limit, now: time.Now}
+}
+
+func (w *SlidingWindow) Limit() Limit {
+	return w.limit
+}
+
+func (w *SlidingWindow) Allow(ctx context.Context, key string) (*Result, error) {
+	now := w.now()
+	window := w.limit.Window
+	index := now.UnixNano() / int64(window)
+	elapsed := time.Duration(now.UnixNano() - index*int64(window))
+
+	// the current counter is still read as the previous one during the next window
+	current, err := w.store.Increment(ctx, key+":"+strconv.FormatInt(index, 10), 2*window)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := w.store.Get(ctx, key+":"+strconv.FormatInt(index-1, 10))
+	if err != nil {
+		return nil, err
+	}
+
+	limit := float64(w.limit.Requests)
+	weight := float64(window-elapsed) / float64(window)
+	count := float64(previous)*weight + float64(current)
+	reset := window - elapsed
+
+	if count <= limit {
+		return &Result{
+			Allowed:    true,
+			Limit:      w.limit.Requests,
+			Remaining:  int64(math.Floor(limit - count)),
+			ResetAfter: reset,
+		}, nil
+	}
+
+	var retry time.Duration
+	if float64(current) <= limit && previous > 0 {
+		// the count drops below the limit as the previous window slides out
+		overlap := (limit - float64(current)) / float64(previous)
+		retry = time.Duration(float64(window)*(1-overlap)) - elapsed
+	} else {
+		// the current window alone is over the limit, it has to slide out in the next one
+		retry = reset + time.Duration(float64(window)*(1-limit/float64(current)))
+	}
+
+	return &Result{
+		Allowed:    false,
+		Limit:      w.limit.Requests,
+		Remaining:  0,
+		ResetAfter: reset + window,
+		RetryAfter: max(retry, time.Millisecond),
+	}, nil
+}
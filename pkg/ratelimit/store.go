@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// how often the memory store drops expired keys
+const memorySweepInterval = time.Minute
+
+// Store holds the state of the limiters. Missing and expired keys read as zero, a shared
+// backend lets every instance of the api enforce the same limits.
+type Store interface {
+	Get(ctx context.Context, key string) (int64, error)
+	// Increment adds one to the key and returns the new value, a new key expires after ttl
+	Increment(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	// CompareAndSwap sets the key to new and its expiry to ttl only if it still holds old
+	CompareAndSwap(ctx context.Context, key string, old, new int64, ttl time.Duration) (bool, error)
+}
+
+type memoryEntry struct {
+	value     int64
+	expiresAt time.Time
+}
+
+// MemoryStore keeps the state in process, limits are enforced per instance
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	nextSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]memoryEntry),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, _ := s.entry(key)
+	return entry.value, nil
+}
+
+func (s *MemoryStore) Increment(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entry(key)
+	if !ok {
+		entry.expiresAt = s.now().Add(ttl)
+	}
+	entry.value++
+	s.entries[key] = entry
+
+	return entry.value, nil
+}
+
+func (s *MemoryStore) CompareAndSwap(ctx context.Context, key string, old, new int64, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, _ := s.entry(key)
+	if entry.value != old {
+		return false, nil
+	}
+	s.entries[key] = memoryEntry{value: new, expiresAt: s.now().Add(ttl)}
+
+	return true, nil
+}
+
+// entry returns the live entry of the key, the caller holds the lock
+func (s *MemoryStore) entry(key string) (memoryEntry, bool) {
+	now := s.now()
+	if now.After(s.nextSweep) {
+		for k, e := range s.entries {
+			if !now.Before(e.expiresAt) {
+				delete(s.entries, k)
+			}
+		}
+		s.nextSweep = now.Add(memorySweepInterval)
+	}
+
+	entry, ok := s.entries[key]
+	if !ok || !now.Before(entry.expiresAt) {
+		return memoryEntry{}, false
+	}
+	return entry, true
+}
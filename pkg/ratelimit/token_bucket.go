@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// attempts at updating the bucket before giving up with ErrContention
+const maxSwapAttempts = 5
+
+// TokenBucket holds Requests tokens refilled evenly over the Window, allowing bursts up to the
+// full bucket. It is implemented as GCRA: the store keeps a single theoretical arrival time
+// per key, in unix nanoseconds, instead of a token count and a refill time.
+type TokenBucket struct {
+	store Store
+	limit Limit
+	now   func() time.Time
+}
+
+func NewTokenBucket(store Store, limit Limit) *TokenBucket {
+	return &TokenBucket{store: store, limit: limit, now: time.Now}
+}
+
+func (b *TokenBucket) Limit() Limit {
+	return b.limit
+}
+
+func (b *TokenBucket) Allow(ctx context.Context, key string) (*Result, error) {
+	interval := int64(b.limit.Window) / b.limit.Requests
+	burst := int64(b.limit.Window)
+
+	for attempt := 0; attempt < maxSwapAttempts; attempt++ {
+		now := b.now().UnixNano()
+
+		stored, err := b.store.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+
+		// the bucket is full when the arrival time is in the past
+		arrival := max(stored, now)
+		next := arrival + interval
+		allowAt := next - burst
+
+		if now < allowAt {
+			return &Result{
+				Allowed:    false,
+				Limit:      b.limit.Requests,
+				Remaining:  0,
+				ResetAfter: time.Duration(arrival - now),
+				RetryAfter: time.Duration(allowAt - now),
+			}, nil
+		}
+
+		ok, err := b.store.CompareAndSwap(ctx, key, stored, next, time.Duration(next-now))
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		return &Result{
+			Allowed:    true,
+			Limit:      b.limit.Requests,
+			Remaining:  (burst - (next - now)) / interval,
+			ResetAfter: time.Duration(next - now),
+		}, nil
+	}
+
+	return nil, ErrContention
+}
@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type FileSystemConfig struct {
+	// Dir is the directory the objects are stored in, it is created when missing
+	Dir string
+	// URL is where the FileSystem is served as an http.Handler, the presigned urls point to it
+	URL string
+	// Secret signs the presigned urls
+	Secret string
+}
+
+// FileSystem stores the objects as files on the local disk, for development and single
+// instance deployments. It serves its own presigned urls as an http.Handler, the content
+// type of a download is derived from the key's extension or the content.
+type FileSystem struct {
+	dir    string
+	url    string
+	secret []byte
+	now    func() time.Time
+}
+
+func NewFileSystem(config FileSystemConfig) (*FileSystem, error) {
+	if config.Dir == "" {
+		return nil, errors.New("storage directory is not set")
+	}
+	if config.Secret == "" {
+		return nil, errors.New("storage secret is not set")
+	}
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &FileSystem{
+		dir:    config.Dir,
+		url:    strings.TrimSuffix(config.URL, "/"),
+		secret: []byte(config.Secret),
+		now:    time.Now,
+	}, nil
+}
+
+func (f *FileSystem) path(key string) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(f.dir, filepath.FromSlash(key)), nil
+}
+
+func (f *FileSystem) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// written aside and renamed, a reader never sees a partial object
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if written != size {
+		return fmt.Errorf("object is %d bytes, %d were declared", written, size)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (f *FileSystem) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := f.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func (f *FileSystem) Delete(ctx context.Context, key string) error {
+	path, err := f.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (f *FileSystem) PresignPut(ctx context.Context, key string, contentType string, size int64, expires time.Duration) (string, error) {
+	return f.presign(http.MethodPut, key, contentType, size, expires)
+}
+
+func (f *FileSystem) PresignGet(ctx context.Context, key string, expires time.Duration) (string, error) {
+	return f.presign(http.MethodGet, key, "", 0, expires)
+}
+
+func (f *FileSystem) presign(method string, key string, contentType string, size int64, expires time.Duration) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+
+	expiresAt := f.now().Add(expires).Unix()
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expiresAt, 10))
+	query.Set("signature", f.signature(method, key, expiresAt, contentType, size))
+
+	return f.url + "/" + key + "?" + query.Encode(), nil
+}
+
+// signature covers the content type and size of an upload, they are taken from the headers
+// of the request so an upload declaring others is refused
+func (f *FileSystem) signature(method string, key string, expiresAt int64, contentType string, size int64) string {
+	mac := hmac.New(sha256.New, f.secret)
+	fmt.Fprintf(mac, "%s\n%s\n%d\n%s\n%d", method, key, expiresAt, contentType, size)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ServeHTTP serves the presigned urls, the path of the request is the key of the object
+// so the handler is mounted with http.StripPrefix
+func (f *FileSystem) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	if !ValidKey(key) {
+		http.NotFound(w, r)
+		return
+	}
+
+	expiresAt, err := strconv.ParseInt(r.URL.Query().Get("expires"), 10, 64)
+	if err != nil || !f.now().Before(time.Unix(expiresAt, 0)) {
+		http.Error(w, "request has expired", http.StatusForbidden)
+		return
+	}
+
+	method := r.Method
+	contentType := ""
+	size := int64(0)
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		method = http.MethodGet
+	case http.MethodPut:
+		contentType = r.Header.Get("Content-Type")
+		size = r.ContentLength
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	signature := f.signature(method, key, expiresAt, contentType, size)
+	if !hmac.Equal([]byte(signature), []byte(r.URL.Query().Get("signature"))) {
+		http.Error(w, "signature does not match", http.StatusForbidden)
+		return
+	}
+
+	if method == http.MethodPut {
+		err = f.Put(r.Context(), key, http.MaxBytesReader(w, r.Body, size), size, contentType)
+		if err != nil {
+			http.Error(w, "failed to store object", http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	path, _ := f.path(key)
+	file, err := os.Open(path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	http.ServeContent(w, r, filepath.Base(path), info.ModTime(), file)
+}
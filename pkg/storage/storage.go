@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// keys are relative slash separated paths, so they map to a file as well as to an object
+var keyPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+(/[A-Za-z0-9._-]+)*$`)
+
+// Storage keeps the files uploaded to the api by key. Presigned urls let clients upload and
+// download an object directly, without the api in between, until they expire.
+type Storage interface {
+	// Put stores the object, replacing the one with the same key
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Get returns the content of the object, failing with ErrNotFound when there is none
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object, a missing object is not an error
+	Delete(ctx context.Context, key string) error
+	// PresignPut returns a url the object is uploaded to with a PUT request, the upload must
+	// declare the given content type and exactly size bytes
+	PresignPut(ctx context.Context, key string, contentType string, size int64, expires time.Duration) (string, error)
+	// PresignGet returns a url the object is downloaded from with a GET request
+	PresignGet(ctx context.Context, key string, expires time.Duration) (string, error)
+}
+
+// ValidKey reports whether the key can be stored by every driver, it refuses the keys
+// escaping their directory such as "../secret"
+func ValidKey(key string) bool {
+	if len(key) > 1024 || !keyPattern.MatchString(key) {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,188 @@
+// Package tenant isolates the records of one organization from the others.
+//
+// Records embedding Model belong to an organization. Once the Plugin is registered on the
+// database every query, update and delete on them is restricted to the organization carried
+// by the statement's context, and creates are assigned to it. A statement without an
+// organization in its context fails with ErrNoTenant, so a missing scope cannot leak rows.
+// Raw SQL is not rewritten.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	ErrNoTenant       = errors.New("no organization in context")
+	ErrTenantMismatch = errors.New("record belongs to another organization")
+)
+
+const skipKey = "tenant:skip"
+
+type contextKey struct{}
+
+// WithOrganizationID returns a context scoping tenant owned records to the organization
+func WithOrganizationID(ctx context.Context, organizationID uint) context.Context {
+	return context.WithValue(ctx, contextKey{}, organizationID)
+}
+
+// OrganizationID returns the organization the context is scoped to
+func OrganizationID(ctx context.Context) (uint, bool) {
+	organizationID, ok := ctx.Value(contextKey{}).(uint)
+	return organizationID, ok && organizationID != 0
+}
+
+// Model is embedded by records that belong to an organization
+type Model struct {
+	OrganizationID uint `json:"organization_id" gorm:"index;not null"`
+}
+
+func (Model) tenantOwned() {}
+
+type owned interface {
+	tenantOwned()
+}
+
+// WithoutTenant lifts the scope for jobs working across organizations, it should never
+// be used while serving a request
+func WithoutTenant(db *gorm.DB) *gorm.DB {
+	return db.Set(skipKey, true)
+}
+
+// Plugin scopes the statements on tenant owned records, register it with db.Use
+type Plugin struct{}
+
+func (Plugin) Name() string {
+	return "tenant"
+}
+
+func (Plugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("gorm:create").Register("tenant:create", assign); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("gorm:query").Register("tenant:query", scope); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("tenant:row", scope); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("tenant:update", scopeWrite); err != nil {
+		return err
+	}
+	return callbacks.Delete().Before("gorm:delete").Register("tenant:delete", scopeWrite)
+}
+
+// applies reports whether the statement is on tenant owned records and the scope is not lifted
+func applies(db *gorm.DB) bool {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return false
+	}
+	if skip, ok := db.Get(skipKey); ok && skip.(bool) {
+		return false
+	}
+	_, ok := reflect.New(db.Statement.Schema.ModelType).Interface().(owned)
+	return ok
+}
+
+func scope(db *gorm.DB) {
+	if !applies(db) {
+		return
+	}
+
+	organizationID, ok := OrganizationID(db.Statement.Context)
+	if !ok {
+		_ = db.AddError(ErrNoTenant)
+		return
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "organization_id"}, Value: organizationID},
+	}})
+}
+
+// scopeWrite keeps gorm's refusal of updates and deletes without conditions, the tenant
+// condition must not count as one or a careless delete would empty the whole organization
+func scopeWrite(db *gorm.DB) {
+	if !applies(db) {
+		return
+	}
+
+	if missingConditions(db) {
+		_ = db.AddError(gorm.ErrMissingWhereClause)
+		return
+	}
+
+	scope(db)
+}
+
+func missingConditions(db *gorm.DB) bool {
+	if db.AllowGlobalUpdate {
+		return false
+	}
+	if _, ok := db.Statement.Clauses["WHERE"]; ok {
+		return false
+	}
+
+	value := reflect.Indirect(db.Statement.ReflectValue)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		return value.Len() == 0
+	case reflect.Struct:
+		field := db.Statement.Schema.PrioritizedPrimaryField
+		if field == nil {
+			return true
+		}
+		_, zero := field.ValueOf(db.Statement.Context, value)
+		return zero
+	}
+	return true
+}
+
+func assign(db *gorm.DB) {
+	if !applies(db) {
+		return
+	}
+
+	organizationID, ok := OrganizationID(db.Statement.Context)
+	if !ok {
+		_ = db.AddError(ErrNoTenant)
+		return
+	}
+
+	field := db.Statement.Schema.LookUpField("OrganizationID")
+	if field == nil {
+		return
+	}
+
+	value := reflect.Indirect(db.Statement.ReflectValue)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := assignRecord(db, field, reflect.Indirect(value.Index(i)), organizationID); err != nil {
+				_ = db.AddError(err)
+				return
+			}
+		}
+	case reflect.Struct:
+		if err := assignRecord(db, field, value, organizationID); err != nil {
+			_ = db.AddError(err)
+		}
+	}
+}
+
+func assignRecord(db *gorm.DB, field *schema.Field, record reflect.Value, organizationID uint) error {
+	current, zero := field.ValueOf(db.Statement.Context, record)
+	if zero {
+		return field.Set(db.Statement.Context, record, organizationID)
+	}
+	if current.(uint) != organizationID {
+		return ErrTenantMismatch
+	}
+	return nil
+}
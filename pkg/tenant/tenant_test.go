@@ -0,0 +1,113 @@
+package tenant_test
+
+import (
+	"context"
+	"go_starter_api/pkg/tenant"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type note struct {
+	ID uint `gorm:"primarykey"`
+	tenant.Model
+
+	Text string
+}
+
+func setup(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(tenant.Plugin{}))
+	require.NoError(t, db.AutoMigrate(&note{}))
+	return db
+}
+
+func TestPlugin(t *testing.T) {
+	first := tenant.WithOrganizationID(context.Background(), 1)
+	second := tenant.WithOrganizationID(context.Background(), 2)
+
+	t.Run("should assign created records to the organization of the context", func(t *testing.T) {
+		db := setup(t)
+
+		record := note{Text: "one"}
+		require.NoError(t, db.WithContext(first).Create(&record).Error)
+		assert.Equal(t, uint(1), record.OrganizationID)
+
+		records := []note{{Text: "two"}, {Text: "three"}}
+		require.NoError(t, db.WithContext(second).Create(&records).Error)
+		assert.Equal(t, uint(2), records[0].OrganizationID)
+		assert.Equal(t, uint(2), records[1].OrganizationID)
+	})
+
+	t.Run("should refuse a record of another organization", func(t *testing.T) {
+		db := setup(t)
+
+		record := note{Model: tenant.Model{OrganizationID: 2}}
+		assert.ErrorIs(t, db.WithContext(first).Create(&record).Error, tenant.ErrTenantMismatch)
+	})
+
+	t.Run("should only read the records of the organization", func(t *testing.T) {
+		db := setup(t)
+		require.NoError(t, db.WithContext(first).Create(&note{Text: "one"}).Error)
+		require.NoError(t, db.WithContext(second).Create(&note{Text: "two"}).Error)
+
+		var notes []note
+		require.NoError(t, db.WithContext(first).Find(&notes).Error)
+		assert.Len(t, notes, 1)
+		assert.Equal(t, "one", notes[0].Text)
+
+		var count int64
+		require.NoError(t, db.WithContext(second).Model(&note{}).Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+
+		var other note
+		assert.ErrorIs(t, db.WithContext(first).First(&other, notes[0].ID+1).Error, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("should only write the records of the organization", func(t *testing.T) {
+		db := setup(t)
+		record := note{Text: "two"}
+		require.NoError(t, db.WithContext(second).Create(&record).Error)
+
+		result := db.WithContext(first).Model(&note{}).Where("id = ?", record.ID).Update("text", "changed")
+		require.NoError(t, result.Error)
+		assert.Equal(t, int64(0), result.RowsAffected)
+
+		result = db.WithContext(first).Delete(&note{}, record.ID)
+		require.NoError(t, result.Error)
+		assert.Equal(t, int64(0), result.RowsAffected)
+
+		result = db.WithContext(second).Delete(&record)
+		require.NoError(t, result.Error)
+		assert.Equal(t, int64(1), result.RowsAffected)
+	})
+
+	t.Run("should keep refusing writes without conditions", func(t *testing.T) {
+		db := setup(t)
+		require.NoError(t, db.WithContext(first).Create(&note{Text: "one"}).Error)
+
+		assert.ErrorIs(t, db.WithContext(first).Delete(&note{}).Error, gorm.ErrMissingWhereClause)
+	})
+
+	t.Run("should fail without an organization in the context", func(t *testing.T) {
+		db := setup(t)
+
+		var notes []note
+		assert.ErrorIs(t, db.Find(&notes).Error, tenant.ErrNoTenant)
+		assert.ErrorIs(t, db.Create(&note{Text: "one"}).Error, tenant.ErrNoTenant)
+	})
+
+	t.Run("should read every organization when the scope is lifted", func(t *testing.T) {
+		db := setup(t)
+		require.NoError(t, db.WithContext(first).Create(&note{Text: "one"}).Error)
+		require.NoError(t, db.WithContext(second).Create(&note{Text: "two"}).Error)
+
+		var notes []note
+		require.NoError(t, tenant.WithoutTenant(db).Find(&notes).Error)
+		assert.Len(t, notes, 2)
+	})
+}
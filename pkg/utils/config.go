@@ -1,5 +1,25 @@
 package utils
 
+import (
+	"go_starter_api/pkg/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
 const (
-	AccountIdContextKey = "account_id"
+	AccountIdContextKey        = "account_id"
+	AuthClaimsContextKey       = "auth_claims"
+	PermissionsContextKey      = "permissions"
+	OrganizationIdContextKey   = "organization_id"
+	OrganizationRoleContextKey = "organization_role"
 )
+
+// AuthClaims returns the claims the auth middleware stored for the request
+func AuthClaims(c *gin.Context) (*domain.AuthClaims, bool) {
+	value, ok := c.Get(AuthClaimsContextKey)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := value.(*domain.AuthClaims)
+	return claims, ok
+}
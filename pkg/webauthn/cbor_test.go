@@ -0,0 +1,62 @@
+package webauthn
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeCBOR(t *testing.T) {
+	// examples of RFC 8949 appendix A
+	for encoded, expected := range map[string]any{
+		"00":                 int64(0),
+		"1903e8":             int64(1000),
+		"3903e7":             int64(-1000),
+		"20":                 int64(-1),
+		"4401020304":         []byte{1, 2, 3, 4},
+		"6449455446":         "IETF",
+		"f5":                 true,
+		"f6":                 nil,
+		"83010203":           []any{int64(1), int64(2), int64(3)},
+		"a201020304":         map[any]any{int64(1): int64(2), int64(3): int64(4)},
+		"a26161016162820203": map[any]any{"a": int64(1), "b": []any{int64(2), int64(3)}},
+	} {
+		data, err := hex.DecodeString(encoded)
+		require.NoError(t, err)
+
+		item, rest, err := decodeCBOR(data)
+		require.NoError(t, err, encoded)
+		assert.Empty(t, rest, encoded)
+		assert.Equal(t, expected, item, encoded)
+	}
+
+	t.Run("should return the bytes after the item", func(t *testing.T) {
+		item, rest, err := decodeCBOR([]byte{0x01, 0xff})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), item)
+		assert.Equal(t, []byte{0xff}, rest)
+	})
+
+	t.Run("should refuse malformed items", func(t *testing.T) {
+		for name, encoded := range map[string]string{
+			"empty":               "",
+			"truncated bytes":     "4401",
+			"truncated argument":  "19",
+			"indefinite array":    "9f01ff",
+			"tag":                 "c11a514b67b0",
+			"duplicate key":       "a201020103",
+			"array key":           "a1800102",
+			"oversized array":     "9bffffffffffffffff",
+			"too deep":            "818181818181818181818101",
+			"reserved additional": "1c",
+		} {
+			data, err := hex.DecodeString(encoded)
+			require.NoError(t, err)
+
+			_, _, err = decodeCBOR(data)
+			assert.ErrorIs(t, err, ErrInvalidCBOR, name)
+		}
+	})
+}
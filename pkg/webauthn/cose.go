@@ -0,0 +1,128 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// cose algorithms of the credential keys, the ones every authenticator supports between them
+const (
+	AlgorithmES256 = -7
+	AlgorithmEdDSA = -8
+	AlgorithmRS256 = -257
+)
+
+// SupportedAlgorithms are offered to authenticators in order of preference
+var SupportedAlgorithms = []int{AlgorithmES256, AlgorithmEdDSA, AlgorithmRS256}
+
+// cose key parameters, RFC 9053
+const (
+	coseKeyType      = 1
+	coseKeyAlgorithm = 3
+	coseKeyCurve     = -1
+	coseKeyX         = -2
+	coseKeyY         = -3
+	coseKeyModulus   = -1
+	coseKeyExponent  = -2
+
+	coseKeyTypeOKP = 1
+	coseKeyTypeEC2 = 2
+	coseKeyTypeRSA = 3
+
+	coseCurveP256    = 1
+	coseCurveEd25519 = 6
+)
+
+var (
+	ErrUnsupportedAlgorithm = errors.New("unsupported credential algorithm")
+	ErrInvalidPublicKey     = errors.New("invalid credential public key")
+	ErrInvalidSignature     = errors.New("invalid signature")
+)
+
+// publicKey is a credential public key decoded from its cose encoding
+type publicKey struct {
+	algorithm int
+	key       crypto.PublicKey
+}
+
+// parsePublicKey decodes a cose key of one of the SupportedAlgorithms
+func parsePublicKey(data []byte) (*publicKey, error) {
+	item, rest, err := decodeCBOR(data)
+	if err != nil || len(rest) != 0 {
+		return nil, ErrInvalidPublicKey
+	}
+	params, ok := item.(map[any]any)
+	if !ok {
+		return nil, ErrInvalidPublicKey
+	}
+
+	keyType, _ := params[int64(coseKeyType)].(int64)
+	algorithm, ok := params[int64(coseKeyAlgorithm)].(int64)
+	if !ok {
+		return nil, ErrInvalidPublicKey
+	}
+
+	switch {
+	case algorithm == AlgorithmES256 && keyType == coseKeyTypeEC2:
+		curve, _ := params[int64(coseKeyCurve)].(int64)
+		x, _ := params[int64(coseKeyX)].([]byte)
+		y, _ := params[int64(coseKeyY)].([]byte)
+		if curve != coseCurveP256 || len(x) != 32 || len(y) != 32 {
+			return nil, ErrInvalidPublicKey
+		}
+		// the uncompressed point form checks the point is on the curve
+		point := append([]byte{4}, append(x, y...)...)
+		key, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), point)
+		if err != nil {
+			return nil, ErrInvalidPublicKey
+		}
+		return &publicKey{algorithm: AlgorithmES256, key: key}, nil
+	case algorithm == AlgorithmEdDSA && keyType == coseKeyTypeOKP:
+		curve, _ := params[int64(coseKeyCurve)].(int64)
+		x, _ := params[int64(coseKeyX)].([]byte)
+		if curve != coseCurveEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, ErrInvalidPublicKey
+		}
+		return &publicKey{algorithm: AlgorithmEdDSA, key: ed25519.PublicKey(x)}, nil
+	case algorithm == AlgorithmRS256 && keyType == coseKeyTypeRSA:
+		n, _ := params[int64(coseKeyModulus)].([]byte)
+		e, _ := params[int64(coseKeyExponent)].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, ErrInvalidPublicKey
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if exponent.Int64() < 3 {
+			return nil, ErrInvalidPublicKey
+		}
+		return &publicKey{algorithm: AlgorithmRS256, key: &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}}, nil
+	case algorithm == AlgorithmES256 || algorithm == AlgorithmEdDSA || algorithm == AlgorithmRS256:
+		return nil, ErrInvalidPublicKey
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+}
+
+// verify checks the signature of the data with the key
+func (k *publicKey) verify(data []byte, signature []byte) error {
+	var ok bool
+	switch key := k.key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(data)
+		ok = ecdsa.VerifyASN1(key, digest[:], signature)
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(key, data, signature)
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(data)
+		ok = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	}
+	if !ok {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,500 @@
+// Package webauthn runs the registration and authentication ceremonies of passkeys on the
+// relying party side. Attestation is not requested, authenticators are trusted to be what
+// they claim, so the attestation statement of a registration is not verified.
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// authenticator data flags
+	flagUserPresent            = 0x01
+	flagUserVerified           = 0x04
+	flagBackupEligible         = 0x08
+	flagBackupState            = 0x10
+	flagAttestedCredentialData = 0x40
+
+	clientDataTypeCreate = "webauthn.create"
+	clientDataTypeGet    = "webauthn.get"
+
+	challengeSize = 32
+	// browsers generate ids of 16 to 64 bytes, the spec caps them at 1023
+	maxCredentialIDSize = 1023
+)
+
+// user verification requirements sent to the browser
+const (
+	UserVerificationRequired  = "required"
+	UserVerificationPreferred = "preferred"
+)
+
+var (
+	ErrInvalidResponse       = errors.New("invalid webauthn response")
+	ErrChallengeMismatch     = errors.New("challenge does not match")
+	ErrOriginMismatch        = errors.New("origin is not allowed")
+	ErrRPIDMismatch          = errors.New("relying party id does not match")
+	ErrUserNotPresent        = errors.New("user presence was not asserted")
+	ErrUserNotVerified       = errors.New("user verification was required")
+	ErrSignCountNotIncreased = errors.New("sign count did not increase, the authenticator may be cloned")
+)
+
+type Config struct {
+	// RPID is the domain the passkeys are scoped to, the origins must be on it or its subdomains
+	RPID string
+	// RPName is shown by the browser while creating a passkey
+	RPName string
+	// Origins are the frontends allowed to run the ceremonies, such as https://app.example.com
+	Origins []string
+	// Timeout is given to the browser for the user to answer, defaults to five minutes
+	Timeout time.Duration
+}
+
+// RelyingParty builds the options given to navigator.credentials and verifies the
+// credentials the browser returns
+type RelyingParty struct {
+	config Config
+}
+
+func NewRelyingParty(config Config) *RelyingParty {
+	if config.Timeout == 0 {
+		config.Timeout = 5 * time.Minute
+	}
+	return &RelyingParty{config: config}
+}
+
+// User is the account a passkey is created for, ID must not contain personal information
+type User struct {
+	ID          []byte
+	Name        string
+	DisplayName string
+}
+
+// Credential is a passkey as stored by the relying party
+type Credential struct {
+	ID []byte
+	// PublicKey is the cose encoded key
+	PublicKey []byte
+	SignCount uint32
+	// AAGUID identifies the model of the authenticator, zero for most passkey providers
+	AAGUID     []byte
+	Transports []string
+	// BackupEligible is set for passkeys synced between devices
+	BackupEligible bool
+}
+
+// GenerateChallenge returns a random challenge, it is stored until the response comes back
+func GenerateChallenge() (string, error) {
+	b := make([]byte, challengeSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+type RelyingPartyEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type UserEntity struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+type CredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+type AuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// CreationOptions are the publicKey options of navigator.credentials.create in their json
+// form, binary values are base64url encoded as read by PublicKeyCredential.parseCreationOptionsFromJSON
+type CreationOptions struct {
+	Challenge              string                 `json:"challenge"`
+	RP                     RelyingPartyEntity     `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions are the publicKey options of navigator.credentials.get in their json form,
+// as read by PublicKeyCredential.parseRequestOptionsFromJSON
+type RequestOptions struct {
+	Challenge        string                 `json:"challenge"`
+	Timeout          int64                  `json:"timeout"`
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// RegistrationResponse is the credential returned by navigator.credentials.create, as
+// serialized by PublicKeyCredential.toJSON
+type RegistrationResponse struct {
+	ID       string `json:"id"`
+	RawID    string `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string   `json:"clientDataJSON"`
+		AttestationObject string   `json:"attestationObject"`
+		Transports        []string `json:"transports"`
+	} `json:"response"`
+}
+
+// AssertionResponse is the credential returned by navigator.credentials.get, as serialized
+// by PublicKeyCredential.toJSON
+type AssertionResponse struct {
+	ID       string `json:"id"`
+	RawID    string `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON"`
+		AuthenticatorData string `json:"authenticatorData"`
+		Signature         string `json:"signature"`
+		UserHandle        string `json:"userHandle"`
+	} `json:"response"`
+}
+
+// Assertion is a verified authentication with a passkey
+type Assertion struct {
+	SignCount    uint32
+	UserVerified bool
+	BackupState  bool
+}
+
+// BeginRegistration returns the options creating a passkey for the user, the excluded
+// credentials are those the user already registered
+func (rp *RelyingParty) BeginRegistration(challenge string, user User, exclude []Credential, userVerification string) *CreationOptions {
+	params := make([]CredentialParameter, 0, len(SupportedAlgorithms))
+	for _, alg := range SupportedAlgorithms {
+		params = append(params, CredentialParameter{Type: "public-key", Alg: alg})
+	}
+
+	return &CreationOptions{
+		Challenge: challenge,
+		RP:        RelyingPartyEntity{ID: rp.config.RPID, Name: rp.config.RPName},
+		User: UserEntity{
+			ID:          base64.RawURLEncoding.EncodeToString(user.ID),
+			Name:        user.Name,
+			DisplayName: user.DisplayName,
+		},
+		PubKeyCredParams:   params,
+		Timeout:            rp.config.Timeout.Milliseconds(),
+		ExcludeCredentials: descriptors(exclude),
+		AuthenticatorSelection: AuthenticatorSelection{
+			// a discoverable credential is what lets the user log in without an email
+			ResidentKey:      "required",
+			UserVerification: userVerification,
+		},
+		Attestation: "none",
+	}
+}
+
+// BeginLogin returns the options asserting one of the allowed credentials, with none allowed
+// the user picks any passkey of the relying party
+func (rp *RelyingParty) BeginLogin(challenge string, allow []Credential, userVerification string) *RequestOptions {
+	return &RequestOptions{
+		Challenge:        challenge,
+		Timeout:          rp.config.Timeout.Milliseconds(),
+		RPID:             rp.config.RPID,
+		AllowCredentials: descriptors(allow),
+		UserVerification: userVerification,
+	}
+}
+
+func descriptors(credentials []Credential) []CredentialDescriptor {
+	result := make([]CredentialDescriptor, 0, len(credentials))
+	for _, credential := range credentials {
+		result = append(result, CredentialDescriptor{
+			Type:       "public-key",
+			ID:         base64.RawURLEncoding.EncodeToString(credential.ID),
+			Transports: credential.Transports,
+		})
+	}
+	return result
+}
+
+// clientData is the part of clientDataJSON the relying party checks
+type clientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+func parseClientData(encoded string) (*clientData, []byte, error) {
+	raw, err := decodeBase64URL(encoded)
+	if err != nil {
+		return nil, nil, ErrInvalidResponse
+	}
+	var data clientData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, nil, ErrInvalidResponse
+	}
+	return &data, raw, nil
+}
+
+// Challenge returns the challenge the response answers, it finds the stored ceremony
+func (r *RegistrationResponse) Challenge() (string, error) {
+	data, _, err := parseClientData(r.Response.ClientDataJSON)
+	if err != nil {
+		return "", err
+	}
+	return data.Challenge, nil
+}
+
+// Challenge returns the challenge the response answers, it finds the stored ceremony
+func (r *AssertionResponse) Challenge() (string, error) {
+	data, _, err := parseClientData(r.Response.ClientDataJSON)
+	if err != nil {
+		return "", err
+	}
+	return data.Challenge, nil
+}
+
+// CredentialID returns the decoded id of the asserted credential
+func (r *AssertionResponse) CredentialID() ([]byte, error) {
+	id, err := decodeBase64URL(r.RawID)
+	if err != nil || len(id) == 0 || len(id) > maxCredentialIDSize {
+		return nil, ErrInvalidResponse
+	}
+	return id, nil
+}
+
+// UserHandle returns the decoded user id the authenticator stored with a discoverable credential,
+// nil when the authenticator did not return it
+func (r *AssertionResponse) UserHandle() ([]byte, error) {
+	if r.Response.UserHandle == "" {
+		return nil, nil
+	}
+	handle, err := decodeBase64URL(r.Response.UserHandle)
+	if err != nil {
+		return nil, ErrInvalidResponse
+	}
+	return handle, nil
+}
+
+// FinishRegistration verifies the response to the options of BeginRegistration and returns the new credential
+func (rp *RelyingParty) FinishRegistration(challenge string, response RegistrationResponse, requireUserVerification bool) (*Credential, error) {
+	if response.Type != "public-key" {
+		return nil, ErrInvalidResponse
+	}
+
+	data, _, err := parseClientData(response.Response.ClientDataJSON)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyClientData(data, clientDataTypeCreate, challenge); err != nil {
+		return nil, err
+	}
+
+	rawAttestation, err := decodeBase64URL(response.Response.AttestationObject)
+	if err != nil {
+		return nil, ErrInvalidResponse
+	}
+	item, rest, err := decodeCBOR(rawAttestation)
+	if err != nil || len(rest) != 0 {
+		return nil, ErrInvalidResponse
+	}
+	attestation, ok := item.(map[any]any)
+	if !ok {
+		return nil, ErrInvalidResponse
+	}
+	rawAuthData, ok := attestation["authData"].([]byte)
+	if !ok {
+		return nil, ErrInvalidResponse
+	}
+
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyAuthenticatorData(authData, requireUserVerification); err != nil {
+		return nil, err
+	}
+	if authData.credentialID == nil {
+		return nil, ErrInvalidResponse
+	}
+
+	rawID, err := decodeBase64URL(response.RawID)
+	if err != nil || !bytes.Equal(rawID, authData.credentialID) {
+		return nil, ErrInvalidResponse
+	}
+
+	if _, err := parsePublicKey(authData.publicKey); err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:             authData.credentialID,
+		PublicKey:      authData.publicKey,
+		SignCount:      authData.signCount,
+		AAGUID:         authData.aaguid,
+		Transports:     response.Response.Transports,
+		BackupEligible: authData.flags&flagBackupEligible != 0,
+	}, nil
+}
+
+// FinishLogin verifies the response to the options of BeginLogin was signed by the credential.
+// A sign count that did not increase fails with ErrSignCountNotIncreased, authenticators
+// that do not count always return zero.
+func (rp *RelyingParty) FinishLogin(challenge string, credential Credential, response AssertionResponse, requireUserVerification bool) (*Assertion, error) {
+	if response.Type != "public-key" {
+		return nil, ErrInvalidResponse
+	}
+
+	id, err := response.CredentialID()
+	if err != nil || !bytes.Equal(id, credential.ID) {
+		return nil, ErrInvalidResponse
+	}
+
+	data, rawClientData, err := parseClientData(response.Response.ClientDataJSON)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyClientData(data, clientDataTypeGet, challenge); err != nil {
+		return nil, err
+	}
+
+	rawAuthData, err := decodeBase64URL(response.Response.AuthenticatorData)
+	if err != nil {
+		return nil, ErrInvalidResponse
+	}
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyAuthenticatorData(authData, requireUserVerification); err != nil {
+		return nil, err
+	}
+
+	key, err := parsePublicKey(credential.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := decodeBase64URL(response.Response.Signature)
+	if err != nil {
+		return nil, ErrInvalidResponse
+	}
+	// the authenticator signs its data followed by the hash of the client data
+	clientDataHash := sha256.Sum256(rawClientData)
+	signed := append(append([]byte{}, rawAuthData...), clientDataHash[:]...)
+	if err := key.verify(signed, signature); err != nil {
+		return nil, err
+	}
+
+	if (authData.signCount != 0 || credential.SignCount != 0) && authData.signCount <= credential.SignCount {
+		return nil, ErrSignCountNotIncreased
+	}
+
+	return &Assertion{
+		SignCount:    authData.signCount,
+		UserVerified: authData.flags&flagUserVerified != 0,
+		BackupState:  authData.flags&flagBackupState != 0,
+	}, nil
+}
+
+func (rp *RelyingParty) verifyClientData(data *clientData, ceremony string, challenge string) error {
+	if data.Type != ceremony {
+		return ErrInvalidResponse
+	}
+	if subtle.ConstantTimeCompare([]byte(data.Challenge), []byte(challenge)) != 1 {
+		return ErrChallengeMismatch
+	}
+	// the ceremonies run in our frontends, never in a frame of another site
+	if data.CrossOrigin || !slices.Contains(rp.config.Origins, strings.TrimSuffix(data.Origin, "/")) {
+		return ErrOriginMismatch
+	}
+	return nil
+}
+
+func (rp *RelyingParty) verifyAuthenticatorData(authData *authenticatorData, requireUserVerification bool) error {
+	rpIDHash := sha256.Sum256([]byte(rp.config.RPID))
+	if subtle.ConstantTimeCompare(authData.rpIDHash, rpIDHash[:]) != 1 {
+		return ErrRPIDMismatch
+	}
+	if authData.flags&flagUserPresent == 0 {
+		return ErrUserNotPresent
+	}
+	if requireUserVerification && authData.flags&flagUserVerified == 0 {
+		return ErrUserNotVerified
+	}
+	return nil
+}
+
+type authenticatorData struct {
+	rpIDHash  []byte
+	flags     byte
+	signCount uint32
+
+	// set when the attested credential data flag is, on registration
+	aaguid       []byte
+	credentialID []byte
+	publicKey    []byte
+}
+
+// parseAuthenticatorData splits the authenticator data, extensions following the
+// credential are ignored
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, ErrInvalidResponse
+	}
+	authData := &authenticatorData{
+		rpIDHash:  data[:32],
+		flags:     data[32],
+		signCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	if authData.flags&flagAttestedCredentialData == 0 {
+		return authData, nil
+	}
+
+	rest := data[37:]
+	if len(rest) < 18 {
+		return nil, ErrInvalidResponse
+	}
+	authData.aaguid = rest[:16]
+	idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if idLength == 0 || idLength > maxCredentialIDSize || len(rest) < idLength {
+		return nil, ErrInvalidResponse
+	}
+	authData.credentialID = rest[:idLength]
+	rest = rest[idLength:]
+
+	_, after, err := decodeCBOR(rest)
+	if err != nil {
+		return nil, ErrInvalidResponse
+	}
+	authData.publicKey = rest[:len(rest)-len(after)]
+
+	return authData, nil
+}
+
+// decodeBase64URL accepts base64url with or without padding, browsers differ
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
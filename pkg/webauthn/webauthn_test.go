@@ -0,0 +1,168 @@
+package webauthn_test
+
+import (
+	"encoding/base64"
+	"go_starter_api/pkg/webauthn"
+	"go_starter_api/pkg/webauthn/webauthntest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelyingParty(t *testing.T) {
+	const origin = "https://app.example.com"
+
+	rp := webauthn.NewRelyingParty(webauthn.Config{
+		RPID:    "example.com",
+		RPName:  "Example",
+		Origins: []string{origin},
+	})
+	user := webauthn.User{ID: []byte("1"), Name: "user@example.com", DisplayName: "user@example.com"}
+
+	// register creates a passkey on the authenticator and returns it as verified by the relying party
+	register := func(t *testing.T, authenticator *webauthntest.Authenticator) *webauthn.Credential {
+		challenge, err := webauthn.GenerateChallenge()
+		require.NoError(t, err)
+
+		options := rp.BeginRegistration(challenge, user, nil, webauthn.UserVerificationPreferred)
+		response := authenticator.Register(t, options)
+
+		responseChallenge, err := response.Challenge()
+		require.NoError(t, err)
+		assert.Equal(t, challenge, responseChallenge)
+
+		credential, err := rp.FinishRegistration(challenge, response, false)
+		require.NoError(t, err)
+		return credential
+	}
+
+	login := func(t *testing.T, authenticator *webauthntest.Authenticator, allow []webauthn.Credential) (string, webauthn.AssertionResponse) {
+		challenge, err := webauthn.GenerateChallenge()
+		require.NoError(t, err)
+		return challenge, authenticator.Login(t, rp.BeginLogin(challenge, allow, webauthn.UserVerificationRequired))
+	}
+
+	t.Run("should register and log in with a passkey", func(t *testing.T) {
+		authenticator := webauthntest.NewAuthenticator(origin)
+		credential := register(t, authenticator)
+		assert.Len(t, credential.ID, 16)
+		assert.Equal(t, uint32(0), credential.SignCount)
+		assert.True(t, credential.BackupEligible)
+		assert.Equal(t, []string{"internal"}, credential.Transports)
+
+		challenge, response := login(t, authenticator, nil)
+
+		id, err := response.CredentialID()
+		require.NoError(t, err)
+		assert.Equal(t, credential.ID, id)
+		handle, err := response.UserHandle()
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, handle)
+
+		assertion, err := rp.FinishLogin(challenge, *credential, response, true)
+		require.NoError(t, err)
+		assert.Equal(t, uint32(1), assertion.SignCount)
+		assert.True(t, assertion.UserVerified)
+	})
+
+	t.Run("should refuse a response to another challenge", func(t *testing.T) {
+		authenticator := webauthntest.NewAuthenticator(origin)
+		credential := register(t, authenticator)
+
+		_, response := login(t, authenticator, []webauthn.Credential{*credential})
+		other, err := webauthn.GenerateChallenge()
+		require.NoError(t, err)
+
+		_, err = rp.FinishLogin(other, *credential, response, false)
+		assert.ErrorIs(t, err, webauthn.ErrChallengeMismatch)
+	})
+
+	t.Run("should refuse another origin", func(t *testing.T) {
+		authenticator := webauthntest.NewAuthenticator(origin)
+		credential := register(t, authenticator)
+
+		authenticator.Origin = "https://evil.example.net"
+		challenge, response := login(t, authenticator, nil)
+
+		_, err := rp.FinishLogin(challenge, *credential, response, false)
+		assert.ErrorIs(t, err, webauthn.ErrOriginMismatch)
+	})
+
+	t.Run("should refuse a passkey of another relying party", func(t *testing.T) {
+		authenticator := webauthntest.NewAuthenticator(origin)
+		other := webauthn.NewRelyingParty(webauthn.Config{RPID: "example.net", Origins: []string{origin}})
+
+		challenge, err := webauthn.GenerateChallenge()
+		require.NoError(t, err)
+		response := authenticator.Register(t, other.BeginRegistration(challenge, user, nil, webauthn.UserVerificationPreferred))
+
+		_, err = rp.FinishRegistration(challenge, response, false)
+		assert.ErrorIs(t, err, webauthn.ErrRPIDMismatch)
+	})
+
+	t.Run("should require user verification when asked", func(t *testing.T) {
+		authenticator := webauthntest.NewAuthenticator(origin)
+		credential := register(t, authenticator)
+
+		authenticator.SkipUserVerification = true
+		challenge, response := login(t, authenticator, nil)
+
+		_, err := rp.FinishLogin(challenge, *credential, response, true)
+		assert.ErrorIs(t, err, webauthn.ErrUserNotVerified)
+
+		assertion, err := rp.FinishLogin(challenge, *credential, response, false)
+		require.NoError(t, err)
+		assert.False(t, assertion.UserVerified)
+	})
+
+	t.Run("should refuse a signature of another key", func(t *testing.T) {
+		authenticator := webauthntest.NewAuthenticator(origin)
+		credential := register(t, authenticator)
+		other := register(t, webauthntest.NewAuthenticator(origin))
+
+		challenge, response := login(t, authenticator, nil)
+
+		forged := *credential
+		forged.PublicKey = other.PublicKey
+		_, err := rp.FinishLogin(challenge, forged, response, false)
+		assert.ErrorIs(t, err, webauthn.ErrInvalidSignature)
+	})
+
+	t.Run("should detect a cloned authenticator with the sign count", func(t *testing.T) {
+		authenticator := webauthntest.NewAuthenticator(origin)
+		credential := register(t, authenticator)
+		clone := authenticator.Clone()
+
+		challenge, response := login(t, authenticator, nil)
+		assertion, err := rp.FinishLogin(challenge, *credential, response, false)
+		require.NoError(t, err)
+		credential.SignCount = assertion.SignCount
+
+		challenge, response = login(t, clone, nil)
+		_, err = rp.FinishLogin(challenge, *credential, response, false)
+		assert.ErrorIs(t, err, webauthn.ErrSignCountNotIncreased)
+	})
+
+	t.Run("should refuse malformed responses", func(t *testing.T) {
+		authenticator := webauthntest.NewAuthenticator(origin)
+		challenge, err := webauthn.GenerateChallenge()
+		require.NoError(t, err)
+		response := authenticator.Register(t, rp.BeginRegistration(challenge, user, nil, webauthn.UserVerificationPreferred))
+
+		truncated := response
+		attestation, err := base64.RawURLEncoding.DecodeString(response.Response.AttestationObject)
+		require.NoError(t, err)
+		truncated.Response.AttestationObject = base64.RawURLEncoding.EncodeToString(attestation[:len(attestation)-10])
+		_, err = rp.FinishRegistration(challenge, truncated, false)
+		assert.ErrorIs(t, err, webauthn.ErrInvalidResponse)
+
+		mismatched := response
+		mismatched.RawID = base64.RawURLEncoding.EncodeToString([]byte("another id"))
+		_, err = rp.FinishRegistration(challenge, mismatched, false)
+		assert.ErrorIs(t, err, webauthn.ErrInvalidResponse)
+
+		_, err = rp.FinishRegistration(challenge, response, false)
+		assert.NoError(t, err)
+	})
+}
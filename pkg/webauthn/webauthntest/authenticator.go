@@ -0,0 +1,249 @@
+// Package webauthntest plays a browser with a software authenticator for tests of the passkey ceremonies
+package webauthntest
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"go_starter_api/pkg/webauthn"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// Authenticator holds ES256 passkeys and answers the options of the relying party like a
+// browser running on Origin would
+type Authenticator struct {
+	Origin string
+	// SkipUserVerification answers as an authenticator without a pin or biometrics
+	SkipUserVerification bool
+
+	mu          sync.Mutex
+	credentials []*credential
+}
+
+type credential struct {
+	id         []byte
+	rpID       string
+	userHandle []byte
+	key        *ecdsa.PrivateKey
+	signCount  uint32
+}
+
+func NewAuthenticator(origin string) *Authenticator {
+	return &Authenticator{Origin: origin}
+}
+
+// Clone returns an authenticator holding copies of the passkeys, as an attacker who
+// extracted the keys would
+func (a *Authenticator) Clone() *Authenticator {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	clone := &Authenticator{Origin: a.Origin, SkipUserVerification: a.SkipUserVerification}
+	for _, cred := range a.credentials {
+		copied := *cred
+		clone.credentials = append(clone.credentials, &copied)
+	}
+	return clone
+}
+
+// Register creates a passkey for the options and returns the response of navigator.credentials.create
+func (a *Authenticator) Register(t testing.TB, options *webauthn.CreationOptions) webauthn.RegistrationResponse {
+	t.Helper()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, excluded := range options.ExcludeCredentials {
+		if a.find(options.RP.ID, excluded.ID) != nil {
+			t.Fatalf("authenticator already holds an excluded credential")
+		}
+	}
+	if !slices.ContainsFunc(options.PubKeyCredParams, func(p webauthn.CredentialParameter) bool {
+		return p.Alg == webauthn.AlgorithmES256
+	}) {
+		t.Fatalf("es256 is not offered")
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate credential key: %v", err)
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		t.Fatalf("failed to generate credential id: %v", err)
+	}
+	userHandle, err := base64.RawURLEncoding.DecodeString(options.User.ID)
+	if err != nil {
+		t.Fatalf("invalid user id: %v", err)
+	}
+
+	cred := &credential{id: id, rpID: options.RP.ID, userHandle: userHandle, key: key}
+	a.credentials = append(a.credentials, cred)
+
+	point, err := key.PublicKey.Bytes()
+	if err != nil {
+		t.Fatalf("failed to encode credential key: %v", err)
+	}
+	publicKey := encodeCBOR(cborMap{
+		{1, 2},
+		{3, webauthn.AlgorithmES256},
+		{-1, 1},
+		{-2, point[1:33]},
+		{-3, point[33:]},
+	})
+
+	authData := a.authenticatorData(cred, 0x40)
+	authData = append(authData, make([]byte, 16)...)
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(id)))
+	authData = append(authData, id...)
+	authData = append(authData, publicKey...)
+
+	attestation := encodeCBOR(cborMap{
+		{"fmt", "none"},
+		{"attStmt", cborMap{}},
+		{"authData", authData},
+	})
+
+	var response webauthn.RegistrationResponse
+	response.ID = base64.RawURLEncoding.EncodeToString(id)
+	response.RawID = response.ID
+	response.Type = "public-key"
+	response.Response.ClientDataJSON = a.clientData(t, "webauthn.create", options.Challenge)
+	response.Response.AttestationObject = base64.RawURLEncoding.EncodeToString(attestation)
+	response.Response.Transports = []string{"internal"}
+	return response
+}
+
+// Login asserts the first passkey of the relying party among the allowed ones, or any
+// passkey of the relying party when none are listed, and returns the response of navigator.credentials.get
+func (a *Authenticator) Login(t testing.TB, options *webauthn.RequestOptions) webauthn.AssertionResponse {
+	t.Helper()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var cred *credential
+	if len(options.AllowCredentials) == 0 {
+		for _, candidate := range a.credentials {
+			if candidate.rpID == options.RPID {
+				cred = candidate
+				break
+			}
+		}
+	}
+	for _, allowed := range options.AllowCredentials {
+		if cred = a.find(options.RPID, allowed.ID); cred != nil {
+			break
+		}
+	}
+	if cred == nil {
+		t.Fatalf("authenticator holds no passkey for %s", options.RPID)
+	}
+
+	cred.signCount++
+	authData := a.authenticatorData(cred, 0)
+	clientData := a.clientData(t, "webauthn.get", options.Challenge)
+
+	rawClientData, _ := base64.RawURLEncoding.DecodeString(clientData)
+	clientDataHash := sha256.Sum256(rawClientData)
+	digest := sha256.Sum256(append(slices.Clone(authData), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, cred.key, digest[:])
+	if err != nil {
+		t.Fatalf("failed to sign assertion: %v", err)
+	}
+
+	var response webauthn.AssertionResponse
+	response.ID = base64.RawURLEncoding.EncodeToString(cred.id)
+	response.RawID = response.ID
+	response.Type = "public-key"
+	response.Response.ClientDataJSON = clientData
+	response.Response.AuthenticatorData = base64.RawURLEncoding.EncodeToString(authData)
+	response.Response.Signature = base64.RawURLEncoding.EncodeToString(signature)
+	response.Response.UserHandle = base64.RawURLEncoding.EncodeToString(cred.userHandle)
+	return response
+}
+
+func (a *Authenticator) find(rpID string, encodedID string) *credential {
+	id, err := base64.RawURLEncoding.DecodeString(encodedID)
+	if err != nil {
+		return nil
+	}
+	for _, cred := range a.credentials {
+		if cred.rpID == rpID && bytes.Equal(cred.id, id) {
+			return cred
+		}
+	}
+	return nil
+}
+
+func (a *Authenticator) authenticatorData(cred *credential, flags byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(cred.rpID))
+	// user present, backup eligible and backed up like a synced passkey
+	flags |= 0x01 | 0x08 | 0x10
+	if !a.SkipUserVerification {
+		flags |= 0x04
+	}
+
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, flags)
+	return binary.BigEndian.AppendUint32(data, cred.signCount)
+}
+
+func (a *Authenticator) clientData(t testing.TB, ceremony string, challenge string) string {
+	data, err := json.Marshal(map[string]any{
+		"type":        ceremony,
+		"challenge":   challenge,
+		"origin":      a.Origin,
+		"crossOrigin": false,
+	})
+	if err != nil {
+		t.Fatalf("failed to encode client data: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// cborMap keeps the order of its pairs, as authenticators encode their maps canonically
+type cborMap [][2]any
+
+func encodeCBOR(item any) []byte {
+	switch v := item.(type) {
+	case int:
+		if v < 0 {
+			return cborHead(1, uint64(-1-v))
+		}
+		return cborHead(0, uint64(v))
+	case []byte:
+		return append(cborHead(2, uint64(len(v))), v...)
+	case string:
+		return append(cborHead(3, uint64(len(v))), v...)
+	case cborMap:
+		data := cborHead(5, uint64(len(v)))
+		for _, pair := range v {
+			data = append(data, encodeCBOR(pair[0])...)
+			data = append(data, encodeCBOR(pair[1])...)
+		}
+		return data
+	default:
+		panic("unsupported cbor item")
+	}
+}
+
+func cborHead(major byte, argument uint64) []byte {
+	switch {
+	case argument < 24:
+		return []byte{major<<5 | byte(argument)}
+	case argument <= 0xff:
+		return []byte{major<<5 | 24, byte(argument)}
+	case argument <= 0xffff:
+		return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(argument))
+	default:
+		return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(argument))
+	}
+}